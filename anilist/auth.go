@@ -0,0 +1,222 @@
+package anilist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/secrets"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/url"
+)
+
+// redirectURI shows the code of the authorization to paste into mangal.
+// The Anilist client should be registered with it
+const redirectURI = "https://anilist.co/api/v2/oauth/pin"
+
+var (
+	authorizeEndpoint = "https://anilist.co/api/v2/oauth/authorize"
+	tokenEndpoint     = "https://anilist.co/api/v2/oauth/token"
+)
+
+var (
+	// ErrNotLoggedIn is returned by the requests on behalf of the user before mangal anilist login
+	ErrNotLoggedIn = errors.New("not logged in to Anilist, run mangal anilist login")
+	// ErrUnauthorized is returned when Anilist rejects the stored token, e.g. after it was revoked
+	ErrUnauthorized = errors.New("the Anilist token is invalid or expired, run mangal anilist login again")
+)
+
+// AuthURL returns the URL to authorize the client with the given ID to access the account
+func AuthURL(clientID string) string {
+	query := url.Values{
+		"client_id":     {clientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+	}
+
+	return authorizeEndpoint + "?" + query.Encode()
+}
+
+// Login exchanges the code of the authorization for the access token and stores it with the secrets.
+// Returns the name of the user
+func Login(clientID, clientSecret, code string) (string, error) {
+	switch {
+	case clientID == "":
+		return "", fmt.Errorf("no Anilist client ID set")
+	case clientSecret == "":
+		return "", fmt.Errorf("no Anilist client secret set")
+	case code == "":
+		return "", fmt.Errorf("no Anilist code set")
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     clientID,
+		"client_secret": clientSecret,
+		"redirect_uri":  redirectURI,
+		"code":          code,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Info("Exchanging the Anilist code for the token")
+	req, err := http.NewRequest(http.MethodPost, tokenEndpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := network.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer util.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("anilist rejected the code with status code %d, it can be used only once", resp.StatusCode)
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", err
+	}
+
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("anilist returned no token")
+	}
+
+	if err = secrets.Set(secrets.AnilistToken, response.AccessToken); err != nil {
+		return "", err
+	}
+
+	return Viewer()
+}
+
+// Logout removes the stored token
+func Logout() error {
+	return secrets.Delete(secrets.AnilistToken)
+}
+
+// Secret returns the secret of the Anilist client.
+// The one in the config is used if it is not stored, it was kept there by the older versions
+func Secret() string {
+	if secret, err := secrets.Get(secrets.AnilistSecret); err == nil && secret != "" {
+		return secret
+	}
+
+	return viper.GetString(key.AnilistSecret)
+}
+
+// IsLoggedIn reports whether the token is stored
+func IsLoggedIn() bool {
+	token, err := secrets.Get(secrets.AnilistToken)
+	return err == nil && token != ""
+}
+
+// authorized sends the GraphQL query on behalf of the logged-in user and decodes the response into v.
+// Unlike request, the identical requests are not coalesced, since they may change the account
+func authorized(query string, variables map[string]any, v any) error {
+	token, err := secrets.Get(secrets.AnilistToken)
+	if err != nil {
+		return err
+	}
+
+	if token == "" {
+		return ErrNotLoggedIn
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	response, err := send(body, token)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(response, v)
+}
+
+// Viewer returns the name of the logged-in user
+func Viewer() (string, error) {
+	var response struct {
+		Data struct {
+			Viewer struct {
+				Name string `json:"name"`
+			} `json:"Viewer"`
+		} `json:"data"`
+	}
+
+	if err := authorized(viewerQuery, nil, &response); err != nil {
+		return "", err
+	}
+
+	return response.Data.Viewer.Name, nil
+}
+
+// SaveProgress sets the number of the read chapters of the manga on the list of the user.
+// The progress is never lowered, e.g. when an older chapter is read again.
+// Reports whether it was changed
+func SaveProgress(id, progress int) (bool, error) {
+	var entry struct {
+		Data struct {
+			Media struct {
+				MediaListEntry *struct {
+					Progress int    `json:"progress"`
+					Status   string `json:"status"`
+				} `json:"mediaListEntry"`
+			} `json:"Media"`
+		} `json:"data"`
+	}
+
+	if err := authorized(progressQuery, map[string]any{"id": id}, &entry); err != nil {
+		return false, err
+	}
+
+	// rereading keeps its status, the rest of the mangas are being read now
+	status := "CURRENT"
+	if current := entry.Data.Media.MediaListEntry; current != nil {
+		if progress <= current.Progress {
+			return false, nil
+		}
+
+		if current.Status == "REPEATING" {
+			status = current.Status
+		}
+	}
+
+	var saved struct {
+		Data struct {
+			SaveMediaListEntry struct {
+				Progress int `json:"progress"`
+			} `json:"SaveMediaListEntry"`
+		} `json:"data"`
+	}
+
+	err := authorized(saveProgressMutation, map[string]any{
+		"id":       id,
+		"progress": progress,
+		"status":   status,
+	}, &saved)
+	if err != nil {
+		return false, err
+	}
+
+	log.Infof("Anilist progress of %d is %d now", id, saved.Data.SaveMediaListEntry.Progress)
+	return true, nil
+}
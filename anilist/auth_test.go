@@ -0,0 +1,137 @@
+package anilist
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/secrets"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogin(t *testing.T) {
+	Convey("Given the Anilist with the reading list of the user", t, func() {
+		filesystem.SetMemMapFs()
+
+		var (
+			progress = 10
+			status   = "REPEATING"
+			saved    []map[string]any
+		)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				var body map[string]string
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				if body["code"] != "pin" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"access_token":"token"}`))
+				return
+			}
+
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			var body struct {
+				Query     string         `json:"query"`
+				Variables map[string]any `json:"variables"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+
+			switch {
+			case strings.Contains(body.Query, "Viewer"):
+				_, _ = w.Write([]byte(`{"data":{"Viewer":{"name":"Guts"}}}`))
+			case strings.Contains(body.Query, "mutation"):
+				saved = append(saved, body.Variables)
+				_, _ = w.Write([]byte(`{"data":{"SaveMediaListEntry":{"progress":11}}}`))
+			default:
+				_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"Media": map[string]any{
+					"mediaListEntry": map[string]any{"progress": progress, "status": status},
+				}}})
+			}
+		}))
+		defer server.Close()
+
+		defer func(old, oldToken string) { endpoint, tokenEndpoint = old, oldToken }(endpoint, tokenEndpoint)
+		endpoint, tokenEndpoint = server.URL, server.URL+"/token"
+		rateLimiter = network.NewLimiter(requestsPerMinute)
+
+		Convey("When the progress is saved before logging in", func() {
+			_, err := SaveProgress(1, 11)
+
+			Convey("Then it should ask to log in", func() {
+				So(err, ShouldEqual, ErrNotLoggedIn)
+			})
+		})
+
+		Convey("When the wrong code is given", func() {
+			_, err := Login("id", "secret", "wrong")
+
+			Convey("Then no token should be stored", func() {
+				So(err, ShouldNotBeNil)
+				So(IsLoggedIn(), ShouldBeFalse)
+			})
+		})
+
+		Convey("When logged in with the code", func() {
+			name, err := Login("id", "secret", "pin")
+			So(err, ShouldBeNil)
+
+			Convey("Then the token should be stored", func() {
+				So(name, ShouldEqual, "Guts")
+				So(IsLoggedIn(), ShouldBeTrue)
+			})
+
+			Convey("Then the newer chapter should raise the progress, keeping the status", func() {
+				So(lo.Must(SaveProgress(1, 11)), ShouldBeTrue)
+				So(saved, ShouldHaveLength, 1)
+				So(saved[0]["progress"], ShouldEqual, 11)
+				So(saved[0]["status"], ShouldEqual, "REPEATING")
+			})
+
+			Convey("Then the older chapter should not lower it", func() {
+				So(lo.Must(SaveProgress(1, 3)), ShouldBeFalse)
+				So(saved, ShouldBeEmpty)
+			})
+
+			Convey("When logged out", func() {
+				So(Logout(), ShouldBeNil)
+
+				Convey("Then the token should be removed", func() {
+					So(IsLoggedIn(), ShouldBeFalse)
+				})
+			})
+		})
+	})
+}
+
+func TestSecret(t *testing.T) {
+	Convey("Given the client secret left in the config by the older versions", t, func() {
+		filesystem.SetMemMapFs()
+		viper.Set(key.AnilistSecret, "config-secret")
+		defer viper.Set(key.AnilistSecret, "")
+
+		Convey("Then it should be used while none is stored", func() {
+			So(Secret(), ShouldEqual, "config-secret")
+		})
+
+		Convey("When the secret is stored", func() {
+			So(secrets.Set(secrets.AnilistSecret, "stored-secret"), ShouldBeNil)
+
+			Convey("Then it should take precedence over the config", func() {
+				So(Secret(), ShouldEqual, "stored-secret")
+			})
+		})
+	})
+}
@@ -0,0 +1,136 @@
+package anilist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/util"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// endpoint of the Anilist GraphQL API
+var endpoint = "https://graphql.anilist.co"
+
+const (
+	// requestsPerMinute is the rate limit enforced by Anilist
+	requestsPerMinute = 90
+	// maxRetries is how many times a rate limited request will be retried
+	maxRetries = 3
+)
+
+// call is an in-flight or completed request
+type call struct {
+	wg   sync.WaitGroup
+	body []byte
+	err  error
+}
+
+var (
+	rateLimiter = network.NewLimiter(requestsPerMinute)
+
+	// calls coalesces identical requests made at the same time,
+	// e.g. when fetching metadata for multiple chapters of the same manga
+	calls   = make(map[string]*call)
+	callsMu sync.Mutex
+)
+
+// request sends the GraphQL query with given variables and decodes the response into v.
+// Identical concurrent requests are sent only once.
+func request(query string, variables map[string]any, v any) error {
+	jsonBody, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	callsMu.Lock()
+	c, ok := calls[string(jsonBody)]
+	if !ok {
+		c = &call{}
+		c.wg.Add(1)
+		calls[string(jsonBody)] = c
+	}
+	callsMu.Unlock()
+
+	if ok {
+		log.Info("Waiting for the same Anilist request in flight")
+	} else {
+		c.body, c.err = send(jsonBody, "")
+		c.wg.Done()
+
+		callsMu.Lock()
+		delete(calls, string(jsonBody))
+		callsMu.Unlock()
+	}
+
+	c.wg.Wait()
+	if c.err != nil {
+		return c.err
+	}
+
+	return json.Unmarshal(c.body, v)
+}
+
+// send posts the body to the Anilist respecting the rate limit, on behalf of the user of the token if it is given.
+// Rate limited requests are retried after the time Anilist asks to wait.
+func send(body []byte, token string) ([]byte, error) {
+	for try := 0; ; try++ {
+		rateLimiter.Wait()
+
+		log.Info("Sending request to Anilist")
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(body))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := network.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(resp.Body)
+		util.Ignore(resp.Body.Close)
+		if err != nil {
+			return nil, err
+		}
+
+		// we are about to hit the limit, wait for the reset
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				rateLimiter.Pause(time.Until(time.Unix(reset, 0)))
+			}
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			return buf.Bytes(), nil
+		case resp.StatusCode == http.StatusTooManyRequests && try < maxRetries:
+			retryAfter := time.Minute / 2
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				retryAfter = time.Duration(seconds) * time.Second
+			}
+
+			log.Warnf("Anilist rate limit exceeded, retrying after %s", retryAfter)
+			rateLimiter.Pause(retryAfter)
+		case resp.StatusCode == http.StatusUnauthorized && token != "":
+			return nil, ErrUnauthorized
+		default:
+			log.Error("Anilist returned status code " + strconv.Itoa(resp.StatusCode))
+			return nil, fmt.Errorf("invalid response code %d", resp.StatusCode)
+		}
+	}
+}
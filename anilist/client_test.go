@@ -0,0 +1,59 @@
+package anilist
+
+import (
+	"github.com/metafates/mangal/network"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	Convey("Given a rate limited server", t, func() {
+		var hits int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&hits, 1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			// give other requests time to coalesce
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"data":{"media":{"id":1}}}`))
+		}))
+		defer server.Close()
+
+		defer func(old string) { endpoint = old }(endpoint)
+		endpoint = server.URL
+		rateLimiter = network.NewLimiter(requestsPerMinute)
+
+		Convey("When sending the same request concurrently", func() {
+			var (
+				wg        sync.WaitGroup
+				responses = make([]searchByIDResponse, 5)
+				errs      = make([]error, 5)
+			)
+
+			for i := range responses {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = request(searchByIDQuery, map[string]any{"id": 1}, &responses[i])
+				}(i)
+			}
+			wg.Wait()
+
+			Convey("Then it should be retried after the rate limit and sent only once", func() {
+				So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+				for i := range responses {
+					So(errs[i], ShouldBeNil)
+					So(responses[i].Data.Media.ID, ShouldEqual, 1)
+				}
+			})
+		})
+	})
+}
@@ -28,6 +28,38 @@ func SetRelation(name string, to *Manga) error {
 	return nil
 }
 
+// IsBound reports whether the manga name has a relation with an anilist manga
+func IsBound(name string) bool {
+	id, ok := relationCacher.Get(name).Get()
+	return ok && id != -1
+}
+
+// Closest returns the manga from the list with the title closest to the given name.
+// Second value is true if any of its titles matches the name exactly.
+func Closest(name string, mangas []*Manga) (*Manga, bool) {
+	name = normalizedName(name)
+	closest := lo.MinBy(mangas, func(a, b *Manga) bool {
+		return levenshtein.Distance(
+			name,
+			normalizedName(a.Name()),
+		) < levenshtein.Distance(
+			name,
+			normalizedName(b.Name()),
+		)
+	})
+
+	if closest == nil {
+		return nil, false
+	}
+
+	titles := []string{closest.Title.English, closest.Title.Romaji, closest.Title.Native}
+	exact := lo.ContainsBy(titles, func(title string) bool {
+		return normalizedName(title) == name
+	})
+
+	return closest, exact
+}
+
 // FindClosest returns the closest manga to the given name.
 // It will levenshtein compare the given name with all the manga names in the cache.
 func FindClosest(name string) (*Manga, error) {
@@ -97,15 +129,7 @@ func findClosest(name, originalName string, try, limit int) (*Manga, error) {
 	}
 
 	// find the closest match
-	closest := lo.MinBy(mangas, func(a, b *Manga) bool {
-		return levenshtein.Distance(
-			name,
-			normalizedName(a.Name()),
-		) < levenshtein.Distance(
-			name,
-			normalizedName(b.Name()),
-		)
-	})
+	closest, _ := Closest(name, mangas)
 
 	log.Info("Found closest match: " + closest.Name())
 
@@ -20,3 +20,28 @@ func TestFindClosest(t *testing.T) {
 		})
 	})
 }
+
+func TestClosest(t *testing.T) {
+	Convey("Given a list of mangas", t, func() {
+		deathNote, bakuman := &Manga{}, &Manga{}
+		deathNote.Title.English = "Death Note"
+		bakuman.Title.Romaji = "Bakuman."
+		mangas := []*Manga{bakuman, deathNote}
+
+		Convey("When finding the closest to the exact title", func() {
+			closest, exact := Closest("death note", mangas)
+			Convey("Then it should be an exact match", func() {
+				So(closest, ShouldEqual, deathNote)
+				So(exact, ShouldBeTrue)
+			})
+		})
+
+		Convey("When finding the closest to the similar title", func() {
+			closest, exact := Closest("Bakuman", mangas)
+			Convey("Then it should not be an exact match", func() {
+				So(closest, ShouldEqual, bakuman)
+				So(exact, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -82,3 +82,30 @@ query ($id: Int) {
 		%s
 	}
 }`, mangaSubquery)
+
+// viewerQuery query used for getting the logged-in user
+var viewerQuery = `
+query {
+	Viewer {
+		name
+	}
+}`
+
+// progressQuery query used for getting the list entry of the manga of the logged-in user
+var progressQuery = `
+query ($id: Int) {
+	Media (id: $id, type: MANGA) {
+		mediaListEntry {
+			progress
+			status
+		}
+	}
+}`
+
+// saveProgressMutation mutation used for setting the progress of the manga on the list of the logged-in user
+var saveProgressMutation = `
+mutation ($id: Int, $progress: Int, $status: MediaListStatus) {
+	SaveMediaListEntry (mediaId: $id, progress: $progress, status: $status) {
+		progress
+	}
+}`
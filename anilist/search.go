@@ -1,15 +1,10 @@
 package anilist
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"github.com/metafates/mangal/log"
-	"github.com/metafates/mangal/network"
 	"github.com/metafates/mangal/query"
 	"github.com/samber/lo"
-	"net/http"
-	"strconv"
 )
 
 type searchByNameResponse struct {
@@ -33,48 +28,11 @@ func GetByID(id int) (*Manga, error) {
 		return manga.MustGet(), nil
 	}
 
-	// prepare body
 	log.Infof("Searching anilist for manga with id: %d", id)
-	body := map[string]interface{}{
-		"query": searchByIDQuery,
-		"variables": map[string]interface{}{
-			"id": id,
-		},
-	}
-
-	// parse body to json
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-
-	// send request
-	log.Info("Sending request to Anilist")
-	req, err := http.NewRequest(http.MethodPost, "https://graphql.anilist.co", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := network.Client.Do(req)
-
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Error("Anilist returned status code " + strconv.Itoa(resp.StatusCode))
-		return nil, fmt.Errorf("invalid response code %d", resp.StatusCode)
-	}
-
-	// decode response
 	var response searchByIDResponse
-
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	err := request(searchByIDQuery, map[string]any{"id": id}, &response)
+	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
@@ -107,54 +65,16 @@ func SearchByName(name string) ([]*Manga, error) {
 		return mangas, nil
 	}
 
-	// prepare body
 	log.Infof("Searching anilist for manga %s", name)
-	body := map[string]any{
-		"query": searchByNameQuery,
-		"variables": map[string]any{
-			"query": name,
-		},
-	}
-
-	// parse body to json
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-
-	// send request
-	log.Info("Sending request to Anilist")
-	req, err := http.NewRequest(http.MethodPost, "https://graphql.anilist.co", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := network.Client.Do(req)
 
+	var response searchByNameResponse
+	err := request(searchByNameQuery, map[string]any{"query": name}, &response)
 	if err != nil {
 		log.Error(err)
 		_ = failCacher.Set(name, true)
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Error("Anilist returned status code " + strconv.Itoa(resp.StatusCode))
-		_ = failCacher.Set(name, true)
-		return nil, fmt.Errorf("invalid response code %d", resp.StatusCode)
-	}
-
-	// decode response
-	var response searchByNameResponse
-
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		log.Error(err)
-		return nil, err
-	}
-
 	mangas := response.Data.Page.Media
 	log.Infof("Got response from Anilist, found %d results", len(mangas))
 	ids := make([]int, len(mangas))
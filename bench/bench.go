@@ -0,0 +1,274 @@
+// Package bench measures the stages of the download pipeline on the synthetic chapter:
+// downloading its pages from the local server, processing the images and converting it to each format.
+// The chapter and the server are the same for every run, so the results of the runs can be compared,
+// e.g. before and after a change or with the different config, see Compare.
+//
+// The cases are run by mangal bench and by go test -bench
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/decoder"
+	"github.com/metafates/mangal/filesystem"
+	"image"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// DefaultPages is the number of pages of the synthetic chapter
+const DefaultPages = 200
+
+// Case is the benchmark of one stage of the pipeline, an op processes the whole chapter
+type Case struct {
+	Name        string
+	Description string
+	// Budget is the time the case may take per page, exceeding it is a regression
+	Budget time.Duration
+
+	run func(b *testing.B, f *fixture) error
+}
+
+// Cases returns the benchmarks of the pipeline, in the order of its stages
+func Cases() []*Case {
+	cases := []*Case{
+		{
+			Name:        "download",
+			Description: "download the pages from the local server, see downloader.async",
+			Budget:      2 * time.Millisecond,
+			run:         runDownload,
+		},
+		{
+			Name:        "images/transcode",
+			Description: "detect the exotic formats of the pages and transcode them, as epub does",
+			Budget:      100 * time.Microsecond,
+			run: func(b *testing.B, f *fixture) error {
+				f.fill()
+				for i := 0; i < b.N; i++ {
+					for _, page := range f.chapter.Pages {
+						if _, _, err := decoder.Transcode(page.Contents.Bytes(), page.Index); err != nil {
+							return err
+						}
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:        "images/decode",
+			Description: "decode the pages, as the converters that re-encode them do",
+			Budget:      50 * time.Millisecond,
+			run: func(b *testing.B, f *fixture) error {
+				f.fill()
+				for i := 0; i < b.N; i++ {
+					for _, page := range f.chapter.Pages {
+						if _, _, err := image.Decode(bytes.NewReader(page.Contents.Bytes())); err != nil {
+							return err
+						}
+					}
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for _, format := range []string{constant.FormatCBZ, constant.FormatZIP, constant.FormatPlain, constant.FormatEPUB, constant.FormatPDF} {
+		format := format
+		cases = append(cases, &Case{
+			Name:        "convert/" + format,
+			Description: "save the downloaded chapter as " + format,
+			Budget:      budgets[format],
+			run: func(b *testing.B, f *fixture) error {
+				return runConvert(b, f, format)
+			},
+		})
+	}
+
+	return cases
+}
+
+// budgets of the converters per page
+var budgets = map[string]time.Duration{
+	constant.FormatPDF:   10 * time.Millisecond,
+	constant.FormatEPUB:  5 * time.Millisecond,
+	constant.FormatCBZ:   5 * time.Millisecond,
+	constant.FormatZIP:   5 * time.Millisecond,
+	constant.FormatPlain: 2 * time.Millisecond,
+}
+
+func runDownload(b *testing.B, f *fixture) error {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		f.empty()
+		b.StartTimer()
+
+		if err := f.chapter.DownloadPagesContext(context.Background(), true, func(string) {}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runConvert(b *testing.B, f *fixture, format string) error {
+	conv, err := converter.Get(format)
+	if err != nil {
+		return err
+	}
+
+	chapter := f.chapter.As(format)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		f.fill()
+		b.StartTimer()
+
+		path, err := conv.SaveTemp(chapter)
+		if err != nil {
+			return err
+		}
+
+		// plain chapters can't be saved over the previous ones
+		b.StopTimer()
+		if err = filesystem.Api().RemoveAll(path); err != nil {
+			return err
+		}
+
+		b.StartTimer()
+	}
+
+	return nil
+}
+
+// Options of the run
+type Options struct {
+	// Pages of the synthetic chapter, DefaultPages if not set
+	Pages int
+	// Filter of the names of the cases to run, all of them if nil
+	Filter *regexp.Regexp
+}
+
+// Result of the case
+type Result struct {
+	Name  string `json:"name"`
+	Pages int    `json:"pages"`
+	// Runs is the number of times the chapter was processed
+	Runs        int           `json:"runs"`
+	PerOp       time.Duration `json:"per_op"`
+	PerPage     time.Duration `json:"per_page"`
+	MBPerSec    float64       `json:"mb_per_sec"`
+	BytesPerOp  int64         `json:"bytes_per_op"`
+	AllocsPerOp int64         `json:"allocs_per_op"`
+	// Budget per page of the case
+	Budget time.Duration `json:"budget"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// OverBudget reports whether the case took longer per page than its budget
+func (r *Result) OverBudget() bool {
+	return r.Error == "" && r.Budget > 0 && r.PerPage > r.Budget
+}
+
+// Run runs the cases matching the filter, progress is told the name of each one before it starts.
+// Converters write to the in-memory filesystem, so that the results don't depend on the disk
+func Run(options Options, progress func(string)) ([]*Result, error) {
+	if options.Pages <= 0 {
+		options.Pages = DefaultPages
+	}
+
+	f, err := newFixture(options.Pages)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	if filesystem.Api().Name() == "OsFs" {
+		filesystem.SetMemMapFs()
+		defer filesystem.SetOsFs()
+	}
+
+	var results []*Result
+	for _, c := range Cases() {
+		if options.Filter != nil && !options.Filter.MatchString(c.Name) {
+			continue
+		}
+
+		progress(c.Name)
+		results = append(results, c.measure(f))
+	}
+
+	return results, nil
+}
+
+// measure runs the case with testing.Benchmark, errors of the case stop it
+func (c *Case) measure(f *fixture) *Result {
+	var failed error
+	benchmark := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(f.size)
+
+		if err := c.run(b, f); err != nil {
+			failed = err
+			b.SkipNow()
+		}
+	})
+
+	result := &Result{
+		Name:   c.Name,
+		Pages:  len(f.chapter.Pages),
+		Budget: c.Budget,
+	}
+
+	if failed != nil {
+		result.Error = failed.Error()
+		return result
+	}
+
+	result.Runs = benchmark.N
+	result.PerOp = time.Duration(benchmark.NsPerOp())
+	result.PerPage = result.PerOp / time.Duration(result.Pages)
+	result.BytesPerOp = benchmark.AllocedBytesPerOp()
+	result.AllocsPerOp = benchmark.AllocsPerOp()
+
+	if seconds := benchmark.T.Seconds(); seconds > 0 {
+		result.MBPerSec = float64(f.size) * float64(benchmark.N) / 1e6 / seconds
+	}
+
+	return result
+}
+
+// Compare returns the changes of the time per page of the results since the baseline by the names of the cases,
+// e.g. 0.1 if the case got 10% slower. Cases missing from the baseline are left out
+func Compare(baseline, results []*Result) map[string]float64 {
+	previous := make(map[string]*Result, len(baseline))
+	for _, result := range baseline {
+		previous[result.Name] = result
+	}
+
+	changes := make(map[string]float64)
+	for _, result := range results {
+		before, ok := previous[result.Name]
+		if !ok || before.PerPage <= 0 || result.Error != "" {
+			continue
+		}
+
+		changes[result.Name] = float64(result.PerPage-before.PerPage) / float64(before.PerPage)
+	}
+
+	return changes
+}
+
+// String of the result, e.g. "convert/cbz: 1.2ms/op, 6µs/page, 5400 MB/s"
+func (r *Result) String() string {
+	if r.Error != "" {
+		return fmt.Sprintf("%s: %s", r.Name, r.Error)
+	}
+
+	return fmt.Sprintf("%s: %s/op, %s/page, %.0f MB/s", r.Name, r.PerOp, r.PerPage, r.MBPerSec)
+}
@@ -0,0 +1,73 @@
+package bench
+
+import (
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+// BenchmarkPipeline runs the cases of mangal bench, e.g. go test ./bench -bench Pipeline/convert
+func BenchmarkPipeline(b *testing.B) {
+	f, err := newFixture(DefaultPages)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer f.Close()
+
+	for _, c := range Cases() {
+		c := c
+		b.Run(c.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(f.size)
+
+			if err := c.run(b, f); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	Convey("When running the cases on the small chapter", t, func() {
+		results, err := Run(Options{Pages: 3, Filter: regexp.MustCompile(`^(download|convert/cbz)$`)}, func(string) {})
+		So(err, ShouldBeNil)
+
+		Convey("Then each case should be measured", func() {
+			So(len(results), ShouldEqual, 2)
+			for _, result := range results {
+				So(result.Error, ShouldBeEmpty)
+				So(result.Pages, ShouldEqual, 3)
+				So(result.Runs, ShouldBeGreaterThan, 0)
+				So(result.PerPage, ShouldBeGreaterThan, 0)
+			}
+		})
+	})
+}
+
+func TestCompare(t *testing.T) {
+	Convey("Given the baseline and the results of the slower run", t, func() {
+		baseline := []*Result{{Name: "convert/cbz", PerPage: time.Millisecond}}
+		results := []*Result{
+			{Name: "convert/cbz", PerPage: 1500 * time.Microsecond},
+			{Name: "convert/pdf", PerPage: time.Millisecond},
+		}
+
+		Convey("When comparing them", func() {
+			changes := Compare(baseline, results)
+
+			Convey("Then only the cases of the baseline should be compared", func() {
+				So(changes, ShouldResemble, map[string]float64{"convert/cbz": 0.5})
+			})
+		})
+	})
+}
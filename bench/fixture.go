@@ -0,0 +1,178 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/metafates/mangal/source"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+const (
+	pageWidth, pageHeight = 800, 1200
+	// distinctPages are the images the pages of the chapter cycle through, encoding each page is too slow
+	distinctPages = 8
+)
+
+const sourceName = "Bench"
+
+// benchSource is the source of the synthetic chapter, only its name is used, see source.Page
+type benchSource struct{}
+
+func (benchSource) Name() string {
+	return sourceName
+}
+
+func (benchSource) ID() string {
+	return sourceName + " synthetic"
+}
+
+func (benchSource) Search(string) ([]*source.Manga, error) {
+	return nil, nil
+}
+
+func (benchSource) ChaptersOf(*source.Manga) ([]*source.Chapter, error) {
+	return nil, nil
+}
+
+func (benchSource) PagesOf(*source.Chapter) ([]*source.Page, error) {
+	return nil, nil
+}
+
+// fixture is the synthetic chapter served by the local server
+type fixture struct {
+	chapter *source.Chapter
+	images  [][]byte
+	server  *httptest.Server
+	// size of all the pages in bytes
+	size int64
+}
+
+// newFixture generates the chapter with the given number of pages, the same for every run
+func newFixture(pages int) (*fixture, error) {
+	f := &fixture{images: make([][]byte, distinctPages)}
+
+	r := rand.New(rand.NewSource(1))
+	for i := range f.images {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, synthetic(r), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+
+		f.images[i] = buf.Bytes()
+	}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+
+	manga := &source.Manga{
+		Name:   "Bench",
+		URL:    f.server.URL,
+		ID:     "bench",
+		Source: benchSource{},
+	}
+
+	f.chapter = &source.Chapter{
+		Name:  "Chapter 1",
+		URL:   f.server.URL + "/chapter/1",
+		Index: 1,
+		ID:    "bench-1",
+		Manga: manga,
+		Pages: make([]*source.Page, pages),
+	}
+
+	manga.Chapters = []*source.Chapter{f.chapter}
+
+	for i := range f.chapter.Pages {
+		f.chapter.Pages[i] = &source.Page{
+			URL:       fmt.Sprintf("%s/pages/%d.jpg", f.server.URL, i),
+			Index:     i + 1,
+			Extension: ".jpg",
+			Chapter:   f.chapter,
+		}
+
+		f.size += int64(len(f.imageOf(i)))
+	}
+
+	return f, nil
+}
+
+func (f *fixture) imageOf(page int) []byte {
+	return f.images[page%len(f.images)]
+}
+
+// serve answers with the images of the pages, /pages/<index>.jpg
+func (f *fixture) serve(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/pages/"), ".jpg"))
+	if err != nil || index < 0 || index >= len(f.chapter.Pages) {
+		http.NotFound(w, r)
+		return
+	}
+
+	contents := f.imageOf(index)
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Content-Length", strconv.Itoa(len(contents)))
+	_, _ = w.Write(contents)
+}
+
+// fill sets the contents of the pages as if they were downloaded,
+// the converters read them, so they are set again before each run
+func (f *fixture) fill() {
+	for i, page := range f.chapter.Pages {
+		contents := f.imageOf(i)
+		page.Contents = bytes.NewBuffer(contents)
+		page.Size = uint64(len(contents))
+	}
+}
+
+// empty forgets the contents of the pages, so that they are downloaded again
+func (f *fixture) empty() {
+	for _, page := range f.chapter.Pages {
+		page.Contents = nil
+		page.Size = 0
+	}
+}
+
+func (f *fixture) Close() {
+	f.server.Close()
+}
+
+// synthetic returns the image that resembles the scanned page:
+// the paper with the noise, the dark panels and the text lines in them
+func synthetic(r *rand.Rand) image.Image {
+	img := image.NewGray(image.Rect(0, 0, pageWidth, pageHeight))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(235 + r.Intn(20))
+	}
+
+	for panel := 0; panel < 4; panel++ {
+		var (
+			x0 = r.Intn(pageWidth / 2)
+			y0 = panel * pageHeight / 4
+			x1 = x0 + pageWidth/3 + r.Intn(pageWidth/2)
+			y1 = y0 + pageHeight/5
+			c  = uint8(40 + r.Intn(120))
+		)
+
+		for y := y0; y < y1 && y < pageHeight; y++ {
+			for x := x0; x < x1 && x < pageWidth; x++ {
+				img.SetGray(x, y, color.Gray{Y: c + uint8(r.Intn(30))})
+			}
+		}
+
+		// lines of the speech bubbles
+		for line := 0; line < 3; line++ {
+			y := y0 + 20 + line*14
+			for x := x0 + 20; x < x0+20+r.Intn(200) && x < pageWidth; x++ {
+				img.SetGray(x, y, color.Gray{Y: 250})
+			}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,216 @@
+// Package bookmarks keeps the bookmarked chapters, e.g. the memorable fights or the reference pages, with the optional notes.
+package bookmarks
+
+import (
+	"fmt"
+	"github.com/metafates/gache"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	mutex  sync.Mutex
+	cacher = gache.New[map[string]*Bookmark](
+		&gache.Options{
+			Path:       where.Bookmarks(),
+			FileSystem: &filesystem.GacheFs{},
+		},
+	)
+)
+
+// Bookmark of the chapter
+type Bookmark struct {
+	MangaName string `json:"manga_name"`
+	MangaURL  string `json:"manga_url"`
+	MangaID   string `json:"manga_id"`
+	SourceID  string `json:"source_id"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	ID        string `json:"id"`
+	Index     int    `json:"index"`
+	// Page of the chapter, starting from 1. Zero bookmarks the whole chapter
+	Page int `json:"page,omitempty"`
+	// Note about the bookmark
+	Note  string    `json:"note,omitempty"`
+	Added time.Time `json:"added"`
+}
+
+// New returns the bookmark of the page of the chapter, zero page bookmarks the whole chapter
+func New(chapter *source.Chapter, page int, note string) *Bookmark {
+	bookmark := &Bookmark{
+		MangaName: chapter.Manga.Name,
+		MangaURL:  chapter.Manga.URL,
+		MangaID:   chapter.Manga.ID,
+		Name:      chapter.Name,
+		URL:       chapter.URL,
+		ID:        chapter.ID,
+		Index:     chapter.Index,
+		Page:      page,
+		Note:      note,
+		Added:     time.Now().UTC(),
+	}
+
+	if src := chapter.Source(); src != nil {
+		bookmark.SourceID = src.ID()
+	}
+
+	return bookmark
+}
+
+func (b *Bookmark) encode() string {
+	return fmt.Sprintf("%s (%s) %s #%d", b.MangaName, b.SourceID, b.URL, b.Page)
+}
+
+func (b *Bookmark) String() string {
+	if b.Page == 0 {
+		return fmt.Sprintf("%s : %s", b.MangaName, b.Name)
+	}
+
+	return fmt.Sprintf("%s : %s, page %d", b.MangaName, b.Name, b.Page)
+}
+
+// Of reports whether the bookmark is of the chapter, of any of its pages
+func (b *Bookmark) Of(chapter *source.Chapter) bool {
+	return b.URL == chapter.URL && strings.EqualFold(b.MangaName, chapter.Manga.Name)
+}
+
+func get() (map[string]*Bookmark, error) {
+	cached, expired, err := cacher.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if expired || cached == nil {
+		return make(map[string]*Bookmark), nil
+	}
+
+	return cached, nil
+}
+
+// change changes the copy of the saved bookmarks and saves it
+func change(apply func(map[string]*Bookmark)) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	saved, err := get()
+	if err != nil {
+		return err
+	}
+
+	// the map is shared with the cache
+	changed := make(map[string]*Bookmark, len(saved))
+	for k, bookmark := range saved {
+		changed[k] = bookmark
+	}
+
+	apply(changed)
+	return cacher.Set(changed)
+}
+
+// List returns the bookmarks by manga, in the order of the chapters and the pages
+func List() ([]*Bookmark, error) {
+	mutex.Lock()
+	saved, err := get()
+	mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Bookmark, 0, len(saved))
+	for _, bookmark := range saved {
+		list = append(list, bookmark)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch {
+		case a.MangaName != b.MangaName:
+			return util.NaturalLess(a.MangaName, b.MangaName)
+		case a.Index != b.Index:
+			return a.Index < b.Index
+		default:
+			return a.Page < b.Page
+		}
+	})
+
+	return list, nil
+}
+
+// Find returns the bookmarks of the manga with the given name, case-insensitive
+func Find(manga string) ([]*Bookmark, error) {
+	list, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*Bookmark
+	for _, bookmark := range list {
+		if strings.EqualFold(bookmark.MangaName, manga) {
+			found = append(found, bookmark)
+		}
+	}
+
+	return found, nil
+}
+
+// Add saves the bookmark.
+// Bookmarking the same page again replaces its note
+func Add(bookmark *Bookmark) error {
+	return change(func(saved map[string]*Bookmark) {
+		saved[bookmark.encode()] = bookmark
+	})
+}
+
+// Remove removes the bookmark
+func Remove(bookmark *Bookmark) error {
+	return change(func(saved map[string]*Bookmark) {
+		delete(saved, bookmark.encode())
+	})
+}
+
+// Toggle bookmarks the whole chapter or removes its bookmarks, of all the pages.
+// Reports whether the chapter is bookmarked now
+func Toggle(chapter *source.Chapter) (bookmarked bool, err error) {
+	err = change(func(saved map[string]*Bookmark) {
+		bookmarked = true
+		for k, bookmark := range saved {
+			if bookmark.Of(chapter) {
+				delete(saved, k)
+				bookmarked = false
+			}
+		}
+
+		if bookmarked {
+			bookmark := New(chapter, 0, "")
+			saved[bookmark.encode()] = bookmark
+		}
+	})
+
+	return
+}
+
+// IsBookmarked returns a function that reports whether any page of the chapter is bookmarked.
+// Bookmarks are read once, when the function is created
+func IsBookmarked() func(chapter *source.Chapter) bool {
+	list, err := List()
+	if err != nil {
+		log.Warn(err)
+	}
+
+	urls := make(map[string]struct{}, len(list))
+	for _, bookmark := range list {
+		urls[strings.ToLower(bookmark.MangaName)+" "+bookmark.URL] = struct{}{}
+	}
+
+	return func(chapter *source.Chapter) bool {
+		_, ok := urls[strings.ToLower(chapter.Manga.Name)+" "+chapter.URL]
+		return ok
+	}
+}
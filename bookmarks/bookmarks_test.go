@@ -0,0 +1,87 @@
+package bookmarks
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func chaptersOf(manga string, count int) []*source.Chapter {
+	m := &source.Manga{Name: manga, URL: "https://example.com/" + manga}
+	m.Chapters = lo.Times(count, func(i int) *source.Chapter {
+		return &source.Chapter{
+			Name:  fmt.Sprintf("Chapter %d", i+1),
+			URL:   fmt.Sprintf("https://example.com/%s/%d", manga, i+1),
+			Index: i + 1,
+			Manga: m,
+		}
+	})
+
+	return m.Chapters
+}
+
+func TestBookmarks(t *testing.T) {
+	Convey("Given the bookmarked chapters and pages", t, func() {
+		filesystem.SetMemMapFs()
+		lo.Must0(cacher.Set(map[string]*Bookmark{}))
+
+		berserk, vagabond := chaptersOf("Berserk", 3), chaptersOf("Vagabond", 1)
+
+		So(Add(New(berserk[2], 0, "")), ShouldBeNil)
+		So(Add(New(berserk[0], 12, "the black swordsman")), ShouldBeNil)
+		So(Add(New(vagabond[0], 0, "")), ShouldBeNil)
+
+		Convey("When they are listed", func() {
+			list := lo.Must(List())
+
+			Convey("Then they should be in the order of the mangas and the chapters", func() {
+				So(lo.Map(list, func(b *Bookmark, _ int) string { return b.String() }), ShouldResemble, []string{
+					"Berserk : Chapter 1, page 12",
+					"Berserk : Chapter 3",
+					"Vagabond : Chapter 1",
+				})
+				So(list[0].Note, ShouldEqual, "the black swordsman")
+			})
+		})
+
+		Convey("When the same page is bookmarked again", func() {
+			So(Add(New(berserk[0], 12, "the eclipse")), ShouldBeNil)
+
+			Convey("Then its note should be replaced", func() {
+				found := lo.Must(Find("berserk"))
+				So(found, ShouldHaveLength, 2)
+				So(found[0].Note, ShouldEqual, "the eclipse")
+			})
+		})
+
+		Convey("When the bookmarked chapter is toggled", func() {
+			bookmarked, err := Toggle(berserk[0])
+
+			Convey("Then its bookmarks should be removed", func() {
+				So(err, ShouldBeNil)
+				So(bookmarked, ShouldBeFalse)
+				So(IsBookmarked()(berserk[0]), ShouldBeFalse)
+				So(IsBookmarked()(berserk[2]), ShouldBeTrue)
+			})
+
+			Convey("Then toggling it again should bookmark the whole chapter", func() {
+				bookmarked, err = Toggle(berserk[0])
+				So(err, ShouldBeNil)
+				So(bookmarked, ShouldBeTrue)
+				So(IsBookmarked()(berserk[0]), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the bookmark is removed", func() {
+			So(Remove(lo.Must(Find("Vagabond"))[0]), ShouldBeNil)
+
+			Convey("Then it should not be listed", func() {
+				So(lo.Must(Find("Vagabond")), ShouldBeEmpty)
+				So(IsBookmarked()(berserk[1]), ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/anilist"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/open"
+	"github.com/metafates/mangal/secrets"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(anilistCmd)
+
+	anilistCmd.AddCommand(anilistBindCmd)
+	anilistBindCmd.Flags().BoolP("all", "a", false, "bind every unbound manga in the library")
+	anilistBindCmd.Flags().BoolP("yes", "y", false, "pick the closest match for ambiguous cases instead of asking")
+	anilistBindCmd.Flags().BoolP("rebind", "r", false, "also bind mangas that are already bound")
+
+	anilistCmd.AddCommand(anilistLoginCmd)
+	anilistLoginCmd.Flags().String("id", "", "client ID of the Anilist API client")
+	anilistLoginCmd.Flags().String("secret", "", "client secret of the Anilist API client")
+	anilistLoginCmd.Flags().String("code", "", "code of the authorization, asked interactively if not given")
+
+	anilistCmd.AddCommand(anilistLogoutCmd)
+}
+
+var anilistCmd = &cobra.Command{
+	Use:   "anilist",
+	Short: "Anilist related commands",
+}
+
+var anilistBindCmd = &cobra.Command{
+	Use:   "bind [manga names...]",
+	Short: "Bind mangas to Anilist",
+	Long: `Bind mangas to Anilist entries, used for metadata and progress sync.
+Exact matches are bound automatically, ambiguous ones are asked interactively.`,
+	Example: "mangal anilist bind --all --yes",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			all    = lo.Must(cmd.Flags().GetBool("all"))
+			yes    = lo.Must(cmd.Flags().GetBool("yes"))
+			rebind = lo.Must(cmd.Flags().GetBool("rebind"))
+			names  = args
+		)
+
+		if all {
+			mangas, err := library.Mangas()
+			handleErr(err)
+
+			for _, manga := range mangas {
+				names = append(names, manga.Name)
+			}
+		}
+
+		if len(names) == 0 {
+			handleErr(fmt.Errorf("no mangas to bind, pass their names or use --all"))
+		}
+
+		var bound, skipped int
+		for _, name := range names {
+			if !rebind && anilist.IsBound(name) {
+				continue
+			}
+
+			manga, err := pickAnilistManga(name, yes)
+			if err != nil {
+				fmt.Printf("%s %s: %s\n", icon.Get(icon.Fail), name, err)
+				skipped++
+				continue
+			}
+
+			if manga == nil {
+				skipped++
+				continue
+			}
+
+			handleErr(anilist.SetRelation(name, manga))
+			fmt.Printf("%s %s → %s\n", icon.Get(icon.Success), name, style.Faint(manga.Name()))
+			bound++
+		}
+
+		fmt.Printf("\nBound %d, skipped %d\n", bound, skipped)
+	},
+}
+
+// pickAnilistManga finds the anilist manga for the name.
+// Returns nil if user decided to skip it.
+func pickAnilistManga(name string, yes bool) (*anilist.Manga, error) {
+	mangas, err := anilist.SearchByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mangas) == 0 {
+		return nil, fmt.Errorf("no results found")
+	}
+
+	closest, exact := anilist.Closest(name, mangas)
+	if exact || yes || viper.GetBool(key.CliHeadless) {
+		return closest, nil
+	}
+
+	const skip = "Skip"
+	options := lo.Map(mangas, func(manga *anilist.Manga, _ int) string {
+		return fmt.Sprintf("%s (%d) #%d", manga.Name(), manga.StartDate.Year, manga.ID)
+	})
+
+	var index int
+	err = ask(&survey.Select{
+		Message: fmt.Sprintf("Which one is %q?", name),
+		Options: append(options, skip),
+		Default: options[lo.IndexOf(mangas, closest)],
+	}, &index)
+	if err != nil {
+		return nil, err
+	}
+
+	if index == len(mangas) {
+		return nil, nil
+	}
+
+	return mangas[index], nil
+}
+
+var anilistLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to Anilist to sync the reading progress",
+	Long: `Log in to Anilist with the API client created at https://anilist.co/settings/developer,
+with https://anilist.co/api/v2/oauth/pin as its redirect URL.
+The token is stored with the secrets, then the chapters read with mangal mark the progress on the Anilist list.
+The mangas are matched with the bound Anilist entries, see mangal anilist bind.`,
+	Example: "mangal anilist login --id 12345 --secret abcdef",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			id     = lo.Must(cmd.Flags().GetString("id"))
+			secret = lo.Must(cmd.Flags().GetString("secret"))
+			code   = lo.Must(cmd.Flags().GetString("code"))
+		)
+
+		if id == "" {
+			id = viper.GetString(key.AnilistID)
+		}
+
+		if id == "" {
+			handleErr(ask(&survey.Input{Message: "Anilist client ID:"}, &id))
+		}
+
+		if secret == "" {
+			secret = anilist.Secret()
+		}
+
+		if secret == "" {
+			handleErr(ask(&survey.Password{Message: "Anilist client secret:"}, &secret))
+		}
+
+		if code == "" {
+			authURL := anilist.AuthURL(id)
+
+			var openInBrowser bool
+			err := ask(&survey.Confirm{Message: "Open browser to authorize mangal on Anilist?", Default: true}, &openInBrowser)
+			if err == nil && openInBrowser {
+				err = open.Start(authURL)
+			}
+
+			if err != nil || !openInBrowser {
+				fmt.Println("Open the following URL in your browser:")
+				fmt.Println(authURL)
+			}
+
+			handleErr(ask(&survey.Password{Message: "Paste the code shown by Anilist:"}, &code))
+		}
+
+		name, err := anilist.Login(id, secret, code)
+		handleErr(err)
+
+		// the secret is stored with the token, not in the config that may be shared
+		handleErr(secrets.Set(secrets.AnilistSecret, secret))
+
+		viper.Set(key.AnilistID, id)
+		viper.Set(key.AnilistSecret, "")
+		viper.Set(key.AnilistEnable, true)
+		// the code is used once, the stored token replaces it
+		viper.Set(key.AnilistCode, "")
+		switch err := viper.WriteConfig(); err.(type) {
+		case viper.ConfigFileNotFoundError:
+			handleErr(viper.SafeWriteConfig())
+		default:
+			handleErr(err)
+		}
+
+		fmt.Printf("%s Logged in to Anilist as %s\n", icon.Get(icon.Success), style.Bold(name))
+	},
+}
+
+var anilistLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out of Anilist and stop syncing the reading progress",
+	Run: func(cmd *cobra.Command, args []string) {
+		handleErr(anilist.Logout())
+
+		viper.Set(key.AnilistEnable, false)
+		switch err := viper.WriteConfig(); err.(type) {
+		case viper.ConfigFileNotFoundError:
+			handleErr(viper.SafeWriteConfig())
+		default:
+			handleErr(err)
+		}
+
+		fmt.Printf("%s Logged out of Anilist\n", icon.Get(icon.Success))
+	},
+}
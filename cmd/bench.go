@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/metafates/mangal/bench"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"os"
+	"regexp"
+	"time"
+)
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().IntP("pages", "p", bench.DefaultPages, "pages of the synthetic chapter")
+	benchCmd.Flags().StringP("run", "r", "", "only run the cases matching this regular expression, e.g. convert/")
+	benchCmd.Flags().BoolP("json", "j", false, "output the results as JSON, e.g. to save them as the baseline")
+	benchCmd.Flags().StringP("baseline", "b", "", "compare the results with the ones saved by --json")
+	benchCmd.Flags().Bool("budget", false, "exit with an error if any case is over its budget")
+	benchCmd.Flags().Bool("list", false, "list the cases without running them")
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure the performance of the download pipeline",
+	Long: `Measure the stages of the download pipeline on the synthetic chapter:
+downloading its pages from the local server, processing the images and converting it to each format.
+Nothing is sent to the network or written to the disk. The current config is used,
+so the options such as downloader.async can be tuned by comparing the runs with --baseline.
+Each case has the budget of the time per page, --budget fails the run if any case exceeds it.`,
+	Example: "mangal bench --json > baseline.json && mangal bench --baseline baseline.json",
+	Run: func(cmd *cobra.Command, args []string) {
+		if lo.Must(cmd.Flags().GetBool("list")) {
+			for _, c := range bench.Cases() {
+				fmt.Printf("%s %s %s\n", c.Name, c.Description, style.Faint(fmt.Sprintf("(budget %s/page)", c.Budget)))
+			}
+
+			return
+		}
+
+		var options bench.Options
+		options.Pages = lo.Must(cmd.Flags().GetInt("pages"))
+		if run := lo.Must(cmd.Flags().GetString("run")); run != "" {
+			filter, err := regexp.Compile(run)
+			handleErr(err)
+			options.Filter = filter
+		}
+
+		var baseline []*bench.Result
+		if path := lo.Must(cmd.Flags().GetString("baseline")); path != "" {
+			contents, err := filesystem.Api().ReadFile(path)
+			handleErr(err)
+			handleErr(json.Unmarshal(contents, &baseline))
+		}
+
+		asJson := lo.Must(cmd.Flags().GetBool("json"))
+
+		var erase = func() {}
+		results, err := bench.Run(options, func(name string) {
+			erase()
+			if !asJson {
+				erase = util.PrintErasable(style.Faint("Running " + name))
+			}
+		})
+
+		erase()
+		handleErr(err)
+
+		if asJson {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			handleErr(encoder.Encode(results))
+		} else {
+			printResults(results, bench.Compare(baseline, results))
+		}
+
+		if !lo.Must(cmd.Flags().GetBool("budget")) {
+			return
+		}
+
+		for _, result := range results {
+			if result.Error != "" || result.OverBudget() {
+				exit(1)
+			}
+		}
+	},
+}
+
+func printResults(results []*bench.Result, changes map[string]float64) {
+	width := lo.Max(lo.Map(results, func(result *bench.Result, _ int) int {
+		return len(result.Name)
+	}))
+
+	for _, result := range results {
+		if result.Error != "" {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), pad(result.Name, width), result.Error)
+			continue
+		}
+
+		mark := icon.Get(icon.Success)
+		if result.OverBudget() {
+			mark = icon.Get(icon.Fail)
+		}
+
+		line := fmt.Sprintf(
+			"%s %s %10s/page %10s/op %8.1f MB/s %s",
+			mark,
+			pad(result.Name, width),
+			round(result.PerPage),
+			round(result.PerOp),
+			result.MBPerSec,
+			style.Faint(fmt.Sprintf("%s/op, %d allocs/op, budget %s/page", humanize.Bytes(uint64(result.BytesPerOp)), result.AllocsPerOp, result.Budget)),
+		)
+
+		if change, ok := changes[result.Name]; ok {
+			line += " " + changeOf(change)
+		}
+
+		fmt.Println(line)
+	}
+}
+
+// round keeps the three significant digits of the duration at most, e.g. 1.35ms
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond)
+	default:
+		return d
+	}
+}
+
+// changeOf shows the change since the baseline, slower runs are worse
+func changeOf(change float64) string {
+	text := fmt.Sprintf("%+.1f%%", change*100)
+	switch {
+	case change > 0.05:
+		return style.Fg(color.Red)(text)
+	case change < -0.05:
+		return style.Fg(color.Green)(text)
+	default:
+		return style.Faint(text)
+	}
+}
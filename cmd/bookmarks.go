@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/bookmarks"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(bookmarksCmd)
+	bookmarksCmd.Flags().BoolP("json", "j", false, "JSON output")
+	bookmarksCmd.Flags().StringP("manga", "m", "", "list the bookmarks of this manga only")
+
+	bookmarksCmd.AddCommand(bookmarksAddCmd)
+	bookmarksAddCmd.Flags().StringP("chapter", "c", "", "chapters to bookmark, same as in inline mode")
+	bookmarksAddCmd.Flags().IntP("page", "p", 0, "page of the chapter to bookmark, starting from 1. The whole chapter by default")
+	bookmarksAddCmd.Flags().StringP("note", "n", "", "note about the bookmark")
+	lo.Must0(bookmarksAddCmd.MarkFlagRequired("chapter"))
+
+	bookmarksCmd.AddCommand(bookmarksRemoveCmd)
+	bookmarksRemoveCmd.Flags().BoolP("all", "a", false, "remove all the bookmarks of the manga")
+}
+
+var bookmarksCmd = &cobra.Command{
+	Use:   "bookmarks",
+	Short: "List the bookmarked chapters",
+	Long: `List the bookmarked chapters and pages with their notes, by manga.
+Chapters are bookmarked with "mangal bookmarks add" or with the b key in the TUI.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			list []*bookmarks.Bookmark
+			err  error
+		)
+
+		if manga := lo.Must(cmd.Flags().GetString("manga")); manga != "" {
+			list, err = bookmarks.Find(manga)
+		} else {
+			list, err = bookmarks.List()
+		}
+		handleErr(err)
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			handleErr(json.NewEncoder(os.Stdout).Encode(list))
+			return
+		}
+
+		if len(list) == 0 {
+			fmt.Println(i18n.T("No bookmarks"))
+			return
+		}
+
+		var manga string
+		for _, bookmark := range list {
+			if bookmark.MangaName != manga {
+				manga = bookmark.MangaName
+				fmt.Printf("%s %s\n", style.Bold(manga), style.Faint(bookmark.SourceID))
+			}
+
+			line := fmt.Sprintf("  %s %s", icon.Get(icon.Bookmark), bookmark.Name)
+			if bookmark.Page != 0 {
+				line += i18n.T(", page %d", bookmark.Page)
+			}
+
+			if bookmark.Note != "" {
+				line += " " + style.Faint(bookmark.Note)
+			}
+
+			fmt.Println(line)
+		}
+	},
+}
+
+var bookmarksAddCmd = &cobra.Command{
+	Use:   "add [title]",
+	Short: "Bookmark the chapters of the manga",
+	Long: `Bookmark the chapters of the manga, or one of their pages, with the optional note.
+Tracked mangas of the library are found on their source, others are searched with the default sources.
+Bookmarking the same page again replaces its note.`,
+	Example: `  mangal bookmarks add "Berserk" --chapter 95 --page 12 --note "the eclipse"`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := inline.ParseChaptersFilter(lo.Must(cmd.Flags().GetString("chapter")))
+		handleErr(err)
+
+		manga, err := findManga(args[0])
+		handleErr(err)
+
+		chapters, err := manga.Source.ChaptersOf(manga)
+		handleErr(err)
+
+		chapters, err = filter(chapters)
+		handleErr(err)
+
+		if len(chapters) == 0 {
+			handleErr(fmt.Errorf("no chapters of %s match %s", manga.Name, lo.Must(cmd.Flags().GetString("chapter"))))
+		}
+
+		var (
+			page = lo.Must(cmd.Flags().GetInt("page"))
+			note = lo.Must(cmd.Flags().GetString("note"))
+		)
+
+		if page < 0 {
+			handleErr(fmt.Errorf("invalid page %d, pages start from 1", page))
+		}
+
+		for _, chapter := range chapters {
+			bookmark := bookmarks.New(chapter, page, note)
+			handleErr(bookmarks.Add(bookmark))
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Bookmark), bookmark, style.Faint(i18n.T("bookmarked")))
+		}
+	},
+}
+
+var bookmarksRemoveCmd = &cobra.Command{
+	Use:     "remove [manga name]",
+	Short:   "Remove the bookmark of the manga",
+	Long:    "Remove the bookmark of the manga, asking which one if there are several.",
+	Example: `  mangal bookmarks remove "Berserk" --all`,
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.Join(args, " ")
+
+		found, err := bookmarks.Find(name)
+		handleErr(err)
+
+		if len(found) == 0 {
+			handleErr(fmt.Errorf("%s has no bookmarks", name))
+		}
+
+		if !lo.Must(cmd.Flags().GetBool("all")) && len(found) > 1 {
+			var index int
+			handleErr(ask(&survey.Select{
+				Message: i18n.T("Which one?"),
+				Options: lo.Map(found, func(bookmark *bookmarks.Bookmark, _ int) string {
+					return bookmark.String()
+				}),
+			}, &index))
+
+			found = found[index : index+1]
+		}
+
+		for _, bookmark := range found {
+			handleErr(bookmarks.Remove(bookmark))
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), bookmark, style.Faint(i18n.T("removed")))
+		}
+	},
+}
@@ -100,6 +100,29 @@ var configInfoCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+	configSchemaCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+
+	configSchemaCmd.SetOut(os.Stdout)
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Show the schema of the config fields",
+	Long: `Show the schema of the config fields in markdown, or in JSON with the json flag.
+Every field has its type, default, description, allowed values, env variable and whether it requires a restart.
+Extensible fields take other values too, e.g. the URL of a DoH endpoint or a custom converter.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			handleErr(config.WriteSchema(cmd.OutOrStdout()))
+			return
+		}
+
+		handleErr(config.WriteSchemaDocs(cmd.OutOrStdout()))
+	},
+}
+
 func init() {
 	configCmd.AddCommand(configSetCmd)
 	configSetCmd.Flags().StringP("key", "k", "", "The key to set the value for")
@@ -149,6 +172,8 @@ var configSetCmd = &cobra.Command{
 			v = value
 		}
 
+		handleErr(config.Validate(key, v))
+
 		viper.Set(key, v)
 		switch err := viper.WriteConfig(); err.(type) {
 		case viper.ConfigFileNotFoundError:
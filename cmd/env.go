@@ -3,29 +3,35 @@ package cmd
 import (
 	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/config"
-	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/where"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
 	"os"
-	"strings"
 )
 
 func init() {
 	rootCmd.AddCommand(envCmd)
 	envCmd.Flags().BoolP("set-only", "s", false, "only show variables that are set")
 	envCmd.Flags().BoolP("unset-only", "u", false, "only show variables that are unset")
+	envCmd.Flags().BoolP("markdown", "m", false, "print documentation of all variables in markdown")
 
-	envCmd.MarkFlagsMutuallyExclusive("set-only", "unset-only")
+	envCmd.MarkFlagsMutuallyExclusive("set-only", "unset-only", "markdown")
 }
 
 var envCmd = &cobra.Command{
 	Use:   "env",
 	Short: "Show available environment variables",
-	Long:  `Show available environment variables.`,
+	Long: `Show available environment variables.
+Every config key can be overridden with an environment variable,
+e.g. downloader.path with MANGAL_DOWNLOADER_PATH.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if lo.Must(cmd.Flags().GetBool("markdown")) {
+			handleErr(config.WriteEnvDocs(cmd.OutOrStdout()))
+			return
+		}
+
 		setOnly := lo.Must(cmd.Flags().GetBool("set-only"))
 		unsetOnly := lo.Must(cmd.Flags().GetBool("unset-only"))
 
@@ -33,7 +39,7 @@ var envCmd = &cobra.Command{
 		slices.Sort(config.EnvExposed)
 		for _, env := range config.EnvExposed {
 			if env != where.EnvConfigPath {
-				env = strings.ToUpper(constant.Mangal + "_" + config.EnvKeyReplacer.Replace(env))
+				env = config.EnvName(env)
 			}
 			value := os.Getenv(env)
 			present := value != ""
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/users"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.AddCommand(historyPruneCmd)
+	historyPruneCmd.Flags().IntP("months", "m", 0, "drop the mangas not read for this many months, history.retention_months by default")
+	historyPruneCmd.Flags().IntP("keep", "k", 0, "keep this many most recently read mangas, history.max_entries by default")
+	historyPruneCmd.Flags().BoolP("dry-run", "n", false, "show what would be dropped without changing the history")
+	historyPruneCmd.Flags().BoolP("users", "u", false, "prune the histories of the server accounts as well")
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Manage the reading history",
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop the old entries of the history and compact it",
+	Long: `Drop the history of the mangas not read for a long time, or beyond the most recently read ones,
+and compact the rest: the duplicate entries saved by the older versions are merged and the malformed ones removed.
+The policy is taken from history.retention_months and history.max_entries unless the flags are given.
+History is pruned by the same policy each time it is saved.`,
+	Example: "mangal history prune --months 6 --dry-run",
+	Run: func(cmd *cobra.Command, args []string) {
+		policy := history.DefaultPolicy()
+		if cmd.Flags().Changed("months") {
+			policy.MaxAge = history.Months(lo.Must(cmd.Flags().GetInt("months")))
+		}
+
+		if cmd.Flags().Changed("keep") {
+			policy.MaxEntries = lo.Must(cmd.Flags().GetInt("keep"))
+		}
+
+		dryRun := lo.Must(cmd.Flags().GetBool("dry-run"))
+
+		pruneHistory("History", history.Default(), policy, dryRun)
+
+		if !lo.Must(cmd.Flags().GetBool("users")) {
+			return
+		}
+
+		accounts, err := users.List()
+		handleErr(err)
+
+		for _, user := range accounts {
+			pruneHistory("History of "+user.Name, user.History(), policy, dryRun)
+		}
+	},
+}
+
+func pruneHistory(name string, store *history.Store, policy history.Policy, dryRun bool) {
+	result, err := store.Prune(policy, dryRun)
+	handleErr(err)
+
+	if dryRun {
+		for _, chapter := range result.Dropped {
+			fmt.Printf("%s %s\n", style.Faint(i18n.T("would drop")), chapter.MangaName)
+		}
+	}
+
+	fmt.Printf(
+		"%s %s\n",
+		icon.Get(icon.Success),
+		i18n.T(
+			"%s: %s dropped, %s compacted, %s kept",
+			name,
+			i18n.N(len(result.Dropped), "entry", "entries"),
+			i18n.N(result.Compacted, "entry", "entries"),
+			i18n.N(result.Kept, "entry", "entries"),
+		),
+	)
+
+	if policy.IsEmpty() {
+		fmt.Println(style.Faint(i18n.T("Nothing is dropped by the policy, see --months and --keep")))
+	}
+}
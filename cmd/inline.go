@@ -37,8 +37,12 @@ func init() {
 	inlineCmd.Flags().BoolP("fetch-metadata", "f", false, "Populate manga metadata")
 	inlineCmd.Flags().BoolP("include-anilist-manga", "a", false, "Include anilist manga in the output")
 	lo.Must0(viper.BindPFlag(key.MetadataFetchAnilist, inlineCmd.Flags().Lookup("fetch-metadata")))
+	inlineCmd.Flags().BoolP("estimate-size", "e", false, "Estimate the sizes of the chapters with HEAD requests of their pages")
+	lo.Must0(viper.BindPFlag(key.DownloaderEstimateSize, inlineCmd.Flags().Lookup("estimate-size")))
 
 	inlineCmd.Flags().StringP("output", "o", "", "output file")
+	inlineCmd.Flags().String("summary", "", "file to write the JSON summary of the download to")
+	inlineCmd.Flags().StringP("language", "L", "", "language of the mangas, for the sources that support choosing it")
 
 	lo.Must0(inlineCmd.MarkFlagRequired("query"))
 	inlineCmd.MarkFlagsMutuallyExclusive("download", "json")
@@ -67,7 +71,15 @@ Chapter selectors:
   [from]-[to] - select chapters by range
   @[substring]@ - select chapters by name substring
 
-When using the json flag manga selector could be omitted. That way, it will select all mangas`,
+When using the json flag manga selector could be omitted. That way, it will select all mangas
+With sources.priority set, its sources are searched in order instead of all the default ones,
+the next one only if the manga is not found or the source fails
+With a chapter selector, the JSON output includes the progress of each manga:
+the read and unread counts from the history and the read and downloaded status of the selected chapters
+
+When downloading, failed chapters don't stop the others and are retried at the end.
+Send SIGUSR1 to skip the chapter being downloaded.
+Exit code is 1 if all the chapters have failed and 2 if only some of them`,
 
 	Example: "https://github.com/metafates/mangal/wiki/Inline-mode",
 	PreRun: func(cmd *cobra.Command, args []string) {
@@ -77,11 +89,15 @@ When using the json flag manga selector could be omitted. That way, it will sele
 			lo.Must0(cmd.MarkFlagRequired("manga"))
 		}
 
+		if cmd.Flags().Changed("summary") {
+			lo.Must0(cmd.MarkFlagRequired("download"))
+		}
+
 		if lo.Must(cmd.Flags().GetBool("populate-pages")) {
 			lo.Must0(cmd.MarkFlagRequired("json"))
 		}
 
-		if _, err := converter.Get(viper.GetString(key.FormatsUse)); err != nil {
+		if _, err := converter.GetAll(source.Formats()); err != nil {
 			handleErr(err)
 		}
 	},
@@ -91,14 +107,39 @@ When using the json flag manga selector could be omitted. That way, it will sele
 			err     error
 		)
 
-		for _, name := range viper.GetStringSlice(key.DownloaderDefaultSources) {
-			if name == "" {
-				handleErr(errors.New("source not set"))
+		// the sources of the priority are searched in order, instead of all the default ones
+		providers, err := provider.Priority()
+		handleErr(err)
+
+		fallback := len(providers) > 0
+		if !fallback {
+			for _, name := range viper.GetStringSlice(key.DownloaderDefaultSources) {
+				if name == "" {
+					handleErr(errors.New("source not set"))
+				}
+
+				p, ok := provider.Get(name)
+				if !ok {
+					handleErr(fmt.Errorf("source not found: %s", name))
+				}
+
+				providers = append(providers, p)
 			}
+		}
+
+		for _, p := range providers {
+			name := p.Name
 
-			p, ok := provider.Get(name)
-			if !ok {
-				handleErr(fmt.Errorf("source not found: %s", name))
+			if language := lo.Must(cmd.Flags().GetString("language")); language != "" {
+				if !p.Capabilities.HasLanguages() {
+					handleErr(fmt.Errorf("source %s does not support choosing the language", name))
+				}
+
+				if !p.Capabilities.SupportsLanguage(language) {
+					handleErr(fmt.Errorf("source %s does not support language %s, supported: %s", name, language, strings.Join(p.Capabilities.Languages, ", ")))
+				}
+
+				viper.Set(p.Capabilities.LanguageKey, strings.ToLower(language))
 			}
 
 			src, err := p.CreateSource()
@@ -118,6 +159,12 @@ When using the json flag manga selector could be omitted. That way, it will sele
 			writer = os.Stdout
 		}
 
+		var summary io.Writer
+		if path := lo.Must(cmd.Flags().GetString("summary")); path != "" {
+			summary, err = filesystem.Api().Create(path)
+			handleErr(err)
+		}
+
 		mangaFlag := lo.Must(cmd.Flags().GetString("manga"))
 		mangaPicker := mo.None[inline.MangaPicker]()
 		if mangaFlag != "" {
@@ -136,6 +183,7 @@ When using the json flag manga selector could be omitted. That way, it will sele
 
 		options := &inline.Options{
 			Sources:             sources,
+			Fallback:            fallback,
 			Download:            lo.Must(cmd.Flags().GetBool("download")),
 			Json:                lo.Must(cmd.Flags().GetBool("json")),
 			Query:               query,
@@ -144,6 +192,11 @@ When using the json flag manga selector could be omitted. That way, it will sele
 			MangaPicker:         mangaPicker,
 			ChaptersFilter:      chapterFilter,
 			Out:                 writer,
+			Summary:             summary,
+		}
+
+		if options.Download {
+			skipOnSignal()
 		}
 
 		handleErr(inline.Run(options))
@@ -261,7 +314,8 @@ var inlineAnilistUpdateCmd = &cobra.Command{
 	Short: "Update old manga metadata according to the current anilist bind",
 	Run: func(cmd *cobra.Command, args []string) {
 		path := lo.Must(cmd.Flags().GetString("path"))
-		handleErr(update.Metadata(path))
+		_, err := update.Metadata(path)
+		handleErr(err)
 	},
 }
 
@@ -3,11 +3,13 @@ package cmd
 import (
 	"fmt"
 	"github.com/AlecAivazis/survey/v2"
+	anilistapi "github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/icon"
 	"github.com/metafates/mangal/integration/anilist"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/open"
+	"github.com/metafates/mangal/secrets"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,6 +38,8 @@ See https://github.com/metafates/mangal/wiki/Anilist-Integration for more inform
 			viper.Set(key.AnilistCode, "")
 			viper.Set(key.AnilistSecret, "")
 			viper.Set(key.AnilistID, "")
+			handleErr(anilistapi.Logout())
+			handleErr(secrets.Delete(secrets.AnilistSecret))
 			log.Info("Anilist integration disabled")
 			handleErr(viper.WriteConfig())
 		}
@@ -46,7 +50,7 @@ See https://github.com/metafates/mangal/wiki/Anilist-Integration for more inform
 				Default: false,
 			}
 			var response bool
-			err := survey.AskOne(&confirm, &response)
+			err := ask(&confirm, &response)
 			handleErr(err)
 
 			if !response {
@@ -73,7 +77,7 @@ See https://github.com/metafates/mangal/wiki/Anilist-Integration for more inform
 				Help:    "",
 			}
 			var response string
-			err := survey.AskOne(&input, &response)
+			err := ask(&input, &response)
 			handleErr(err)
 
 			if response == "" {
@@ -85,22 +89,20 @@ See https://github.com/metafates/mangal/wiki/Anilist-Integration for more inform
 			handleErr(err)
 		}
 
-		if viper.GetString(key.AnilistSecret) == "" {
+		if anilistapi.Secret() == "" {
 			input := survey.Input{
 				Message: "Anilist client secret is not set. Please enter it:",
 				Help:    "",
 			}
 			var response string
-			err := survey.AskOne(&input, &response)
+			err := ask(&input, &response)
 			handleErr(err)
 
 			if response == "" {
 				return
 			}
 
-			viper.Set(key.AnilistSecret, response)
-			err = viper.WriteConfig()
-			handleErr(err)
+			handleErr(secrets.Set(secrets.AnilistSecret, response))
 		}
 
 		if viper.GetString(key.AnilistCode) == "" {
@@ -111,7 +113,7 @@ See https://github.com/metafates/mangal/wiki/Anilist-Integration for more inform
 			}
 
 			var openInBrowser bool
-			err := survey.AskOne(&confirmOpenInBrowser, &openInBrowser)
+			err := ask(&confirmOpenInBrowser, &openInBrowser)
 			if err == nil && openInBrowser {
 				err = open.Start(authURL)
 			}
@@ -127,7 +129,7 @@ See https://github.com/metafates/mangal/wiki/Anilist-Integration for more inform
 			}
 
 			var response string
-			err = survey.AskOne(&input, &response)
+			err = ask(&input, &response)
 			handleErr(err)
 
 			if response == "" {
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/metafates/mangal/federation"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/remote"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"sort"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(libraryCmd)
+	libraryCmd.PersistentFlags().StringSliceP("remote", "r", nil, "address of the server to merge, in addition to library.remotes")
+	libraryCmd.Flags().BoolP("json", "j", false, "JSON output")
+
+	libraryCmd.AddCommand(libraryPullCmd)
+	libraryPullCmd.Flags().StringP("chapter", "c", "", "chapters to pull, same as in inline mode. The ones missing locally by default")
+}
+
+var libraryCmd = &cobra.Command{
+	Use:   "library [manga names...]",
+	Short: "Show the local library merged with the libraries of the servers",
+	Long: `Show the local library merged with the libraries of the servers started with "mangal serve",
+e.g. a NAS and a laptop, with the locations of each manga.
+The servers are taken from library.remotes and --remote.
+Given the manga names, their chapters are listed with the locations that have them.
+Chapters are copied to the local library with "mangal library pull".`,
+	Example: `  mangal library --remote alice@nas:6969 "Berserk"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l := loadFederation(lo.Must(cmd.Flags().GetStringSlice("remote")))
+
+		mangas := l.Mangas
+		if len(args) > 0 {
+			mangas = federatedNamed(l, args)
+		}
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			handleErr(json.NewEncoder(os.Stdout).Encode(mangas))
+			return
+		}
+
+		if len(mangas) == 0 {
+			fmt.Println(i18n.T("No mangas"))
+			return
+		}
+
+		for _, manga := range mangas {
+			line := fmt.Sprintf("%s %s", style.Bold(manga.Name), style.Faint(strings.Join(manga.Locations(), ", ")))
+			if missing := manga.Missing(); missing > 0 {
+				line += " " + style.Faint("("+i18n.T("%d not local", missing)+")")
+			}
+
+			fmt.Println(line)
+
+			if len(args) == 0 {
+				continue
+			}
+
+			for _, chapter := range manga.Chapters {
+				mark := icon.Get(icon.Downloaded)
+				if !chapter.IsLocal() {
+					mark = icon.Get(icon.Link)
+				}
+
+				fmt.Printf("  %s %s %s\n", mark, chapter.Name, style.Faint(strings.Join(chapter.Locations(), ", ")))
+			}
+		}
+	},
+}
+
+var libraryPullCmd = &cobra.Command{
+	Use:     "pull [manga name]",
+	Short:   "Copy the chapters from the servers to the local library",
+	Long:    "Copy the downloaded chapters of the manga from the servers to the local library, into the directory of the manga.",
+	Example: `  mangal library pull "Berserk" --chapter "@Chapter 95@"`,
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		l := loadFederation(lo.Must(cmd.Flags().GetStringSlice("remote")))
+
+		name := strings.Join(args, " ")
+		found := federatedNamed(l, []string{name})
+		if len(found) == 0 {
+			handleErr(fmt.Errorf("%s is not in any library", name))
+		}
+
+		manga := found[0]
+		chapters := lo.Filter(manga.Chapters, func(chapter *federation.Chapter, _ int) bool {
+			return !chapter.IsLocal()
+		})
+
+		if description := lo.Must(cmd.Flags().GetString("chapter")); description != "" {
+			filter, err := inline.ParseChaptersFilter(description)
+			handleErr(err)
+
+			// the chapters are filtered as if they were on a source
+			byChapter := make(map[*source.Chapter]*federation.Chapter, len(manga.Chapters))
+			sourceChapters := lo.Map(manga.Chapters, func(chapter *federation.Chapter, _ int) *source.Chapter {
+				sourceChapter := &source.Chapter{Name: chapter.Name, Index: chapter.Index, Manga: &source.Manga{Name: manga.Name}}
+				byChapter[sourceChapter] = chapter
+				return sourceChapter
+			})
+
+			filtered, err := filter(sourceChapters)
+			handleErr(err)
+
+			chapters = lo.Map(filtered, func(chapter *source.Chapter, _ int) *federation.Chapter {
+				return byChapter[chapter]
+			})
+		}
+
+		if len(chapters) == 0 {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, i18n.T("has nothing to pull"))
+			return
+		}
+
+		var failed int
+		for _, chapter := range chapters {
+			if chapter.IsLocal() {
+				continue
+			}
+
+			fmt.Println(style.Faint(i18n.T("Pulling %s %s", chapter.Name, humanize.Bytes(uint64(chapter.Size)))))
+			path, err := l.Pull(manga, chapter)
+			if err != nil {
+				fmt.Printf("%s %s: %s\n", icon.Get(icon.Fail), chapter.Name, err)
+				failed++
+				continue
+			}
+
+			manga.Pulled(chapter, path)
+			fmt.Printf("%s %s\n", icon.Get(icon.Success), path)
+		}
+
+		if failed > 0 {
+			handleErr(fmt.Errorf("failed to pull %d of %d chapters", failed, len(chapters)))
+		}
+	},
+}
+
+// loadFederation merges the local library with the libraries of library.remotes and the given servers,
+// reporting the servers that could not be reached
+func loadFederation(addresses []string) *federation.Library {
+	addresses = lo.Uniq(append(viper.GetStringSlice(key.LibraryRemotes), addresses...))
+	clients := lo.Map(addresses, func(address string, _ int) *remote.Client {
+		return newRemote(address)
+	})
+
+	l, err := federation.Load(clients)
+	handleErr(err)
+
+	locations := lo.Keys(l.Errors)
+	sort.Strings(locations)
+	for _, location := range locations {
+		fmt.Fprintf(os.Stderr, "%s %s\n", icon.Get(icon.Fail), l.Errors[location])
+	}
+
+	return l
+}
+
+// federatedNamed returns the mangas of the merged library matching any of the given names, case-insensitive.
+// Both the names and the directories of the mangas are matched
+func federatedNamed(l *federation.Library, names []string) []*federation.Manga {
+	return lo.Filter(l.Mangas, func(manga *federation.Manga, _ int) bool {
+		return lo.ContainsBy(names, func(name string) bool {
+			if strings.EqualFold(name, manga.Name) {
+				return true
+			}
+
+			return lo.ContainsBy(lo.Values(manga.Directories), func(directory string) bool {
+				return strings.EqualFold(name, directory)
+			})
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/update"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(metadataCmd)
+	metadataCmd.AddCommand(metadataRefreshCmd)
+	metadataCmd.AddCommand(metadataEmbedCmd)
+}
+
+var metadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "Manage metadata of downloaded mangas",
+}
+
+var metadataRefreshCmd = &cobra.Command{
+	Use:   "refresh [manga names...]",
+	Short: "Refetch metadata of downloaded mangas",
+	Long: `Refetch metadata from Anilist for the library entries
+and regenerate series.json and ComicInfo.xml inside existing archives in place.
+If no names are given, the whole library is refreshed.`,
+	Example: "mangal metadata refresh \"Chainsaw Man\"",
+	Run: func(cmd *cobra.Command, args []string) {
+		runMetadataUpdate(args, func(manga *library.Manga) (*update.Report, error) {
+			return update.Metadata(manga.Path)
+		})
+	},
+}
+
+var metadataEmbedCmd = &cobra.Command{
+	Use:   "embed [manga names...]",
+	Short: "Embed metadata into archives that don't have it",
+	Long: `Inject ComicInfo.xml into the cbz chapters that were downloaded without it.
+Mangas are matched to the library entries by their directory names,
+chapter names and numbers are guessed from the file names.
+If no names are given, the whole library is processed.`,
+	Example: "mangal metadata embed \"Chainsaw Man\"",
+	Run: func(cmd *cobra.Command, args []string) {
+		runMetadataUpdate(args, func(manga *library.Manga) (*update.Report, error) {
+			return update.Embed(manga.Path, manga.Name)
+		})
+	},
+}
+
+// runMetadataUpdate runs the update for the library mangas matching the given names
+// (or for all of them, if none are given) and prints what was changed.
+func runMetadataUpdate(names []string, run func(*library.Manga) (*update.Report, error)) {
+	mangas, err := library.Named(names...)
+	handleErr(err)
+
+	if len(mangas) == 0 {
+		handleErr(fmt.Errorf("no mangas found in the library"))
+	}
+
+	var failed int
+	for _, manga := range mangas {
+		report, err := run(manga)
+		if err != nil {
+			fmt.Printf("%s %s: %s\n", icon.Get(icon.Fail), manga.Name, err)
+			failed++
+			continue
+		}
+
+		if !report.Changed() {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, style.Faint("up to date"))
+			continue
+		}
+
+		fmt.Printf("%s %s\n", icon.Get(icon.Success), manga.Name)
+		if len(report.SeriesFields) > 0 {
+			fmt.Printf("  series.json: %s\n", style.Faint(strings.Join(report.SeriesFields, ", ")))
+		}
+
+		if len(report.Chapters) > 0 {
+			fmt.Printf("  ComicInfo.xml: %s\n", style.Faint(util.Quantify(len(report.Chapters), "chapter", "chapters")))
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%s failed\n", util.Quantify(failed, "manga", "mangas"))
+	}
+}
@@ -2,11 +2,10 @@ package cmd
 
 import (
 	"github.com/metafates/mangal/converter"
-	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/mini"
+	"github.com/metafates/mangal/source"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 func init() {
@@ -24,11 +23,13 @@ var miniCmd = &cobra.Command{
 	Long: `Launch mangal in the mini mode.
 Will try to mimic ani-cli.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
-		if _, err := converter.Get(viper.GetString(key.FormatsUse)); err != nil {
+		if _, err := converter.GetAll(source.Formats()); err != nil {
 			handleErr(err)
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		requireTerminal("Mini mode")
+
 		options := mini.Options{
 			Download: lo.Must(cmd.Flags().GetBool("download")),
 			Continue: lo.Must(cmd.Flags().GetBool("continue")),
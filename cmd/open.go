@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/open"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().StringP("chapter", "c", "", "chapter selector, see mangal inline --help")
+	openCmd.Flags().BoolP("print", "p", false, "print the URLs instead of opening them")
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open [title]",
+	Short: "Open the web page of the manga or its chapter",
+	Long: `Open the web page of the manga or its chapter with the configured browser.
+Tracked mangas of the library are opened on their source,
+others are searched with the default sources, preferring the exact title over the first result.`,
+	Args:    cobra.ExactArgs(1),
+	Example: `  mangal open "Death Note" --chapter last`,
+	Run: func(cmd *cobra.Command, args []string) {
+		manga, err := findManga(args[0])
+		handleErr(err)
+
+		urls := []string{manga.URL}
+		if selector := lo.Must(cmd.Flags().GetString("chapter")); selector != "" {
+			filter, err := inline.ParseChaptersFilter(selector)
+			handleErr(err)
+
+			chapters, err := manga.Source.ChaptersOf(manga)
+			handleErr(err)
+
+			chapters, err = filter(chapters)
+			handleErr(err)
+
+			if len(chapters) == 0 {
+				handleErr(fmt.Errorf("no chapters of %s match %s", manga.Name, selector))
+			}
+
+			urls = lo.Map(chapters, func(chapter *source.Chapter, _ int) string {
+				return chapter.URL
+			})
+		}
+
+		for _, url := range urls {
+			if lo.Must(cmd.Flags().GetBool("print")) {
+				fmt.Println(url)
+				continue
+			}
+
+			handleErr(open.StartWith(url, viper.GetString(key.ReaderBrowser)))
+		}
+	},
+}
+
+// findManga returns the tracked manga of the library with the title,
+// or the one found by the default sources
+func findManga(title string) (*source.Manga, error) {
+	tracked, err := library.Named(title)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, manga := range tracked {
+		entry, err := manga.Entry()
+		if err != nil || entry == nil {
+			continue
+		}
+
+		p, ok := provider.GetByID(entry.SourceID)
+		if !ok {
+			return nil, fmt.Errorf("source %s not found", entry.SourceID)
+		}
+
+		src, err := p.CreateSource()
+		if err != nil {
+			return nil, err
+		}
+
+		return &source.Manga{
+			Name:   entry.Name,
+			URL:    entry.URL,
+			ID:     entry.ID,
+			Source: src,
+		}, nil
+	}
+
+	var found []*source.Manga
+	for _, name := range viper.GetStringSlice(key.DownloaderDefaultSources) {
+		if name == "" {
+			return nil, errors.New("source not set")
+		}
+
+		p, ok := provider.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("source not found: %s", name)
+		}
+
+		src, err := p.CreateSource()
+		if err != nil {
+			return nil, err
+		}
+
+		mangas, err := src.Search(title)
+		if err != nil {
+			return nil, err
+		}
+
+		if manga, ok := lo.Find(mangas, func(manga *source.Manga) bool {
+			return manga.HasTitle(title)
+		}); ok {
+			return manga, nil
+		}
+
+		found = append(found, mangas...)
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("manga %s not found", title)
+	}
+
+	return found[0], nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(overrideCmd)
+	overrideCmd.Flags().String("formats", "", "formats to download the chapters in, e.g. cbz or cbz,pdf")
+	overrideCmd.Flags().String("device-format", "", "format to send the chapters to the devices in")
+	overrideCmd.Flags().Bool("raw-passthrough", false, "same as formats.raw_passthrough")
+	overrideCmd.Flags().Bool("skip-unsupported-images", false, "same as formats.skip_unsupported_images")
+	overrideCmd.Flags().Bool("normalize-extensions", false, "same as formats.normalize_extensions")
+	overrideCmd.Flags().Int("page-padding", 0, "same as formats.page_padding")
+	overrideCmd.Flags().Bool("reset", false, "remove the overrides, the config is used again")
+}
+
+var overrideCmd = &cobra.Command{
+	Use:   "override [manga names...]",
+	Short: "Change the settings of the tracked mangas",
+	Long: `Override the config for the tracked mangas, e.g. to convert the webtoons differently from the paged mangas.
+The overrides are used when the chapters of the manga are downloaded, updated and synced with the devices.
+Without flags, current overrides are shown.`,
+	Example: "mangal override \"Tower of God\" --formats epub --device-format epub",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+
+		for _, name := range []string{"formats", "device-format"} {
+			formats := lo.Must(flags.GetString(name))
+			for _, format := range strings.Split(formats, ",") {
+				if format = strings.TrimSpace(format); format == "" {
+					continue
+				}
+
+				_, err := converter.Get(format)
+				handleErr(err)
+			}
+		}
+
+		mangas, err := library.Named(args...)
+		handleErr(err)
+
+		if len(mangas) == 0 {
+			handleErr(fmt.Errorf("no mangas found in the library"))
+		}
+
+		for _, manga := range mangas {
+			entry, err := manga.Entry()
+			handleErr(err)
+
+			if entry == nil {
+				fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), manga.Name, style.Faint(i18n.T("is not tracked")))
+				continue
+			}
+
+			overrides := &entry.Overrides
+			if lo.Must(flags.GetBool("reset")) {
+				*overrides = library.Overrides{}
+			}
+
+			if flags.Changed("formats") {
+				overrides.Formats = lo.Must(flags.GetString("formats"))
+			}
+
+			if flags.Changed("device-format") {
+				overrides.DeviceFormat = lo.Must(flags.GetString("device-format"))
+			}
+
+			for name, field := range map[string]**bool{
+				"raw-passthrough":         &overrides.RawPassthrough,
+				"skip-unsupported-images": &overrides.SkipUnsupportedImages,
+				"normalize-extensions":    &overrides.NormalizeExtensions,
+			} {
+				if flags.Changed(name) {
+					*field = lo.ToPtr(lo.Must(flags.GetBool(name)))
+				}
+			}
+
+			if flags.Changed("page-padding") {
+				overrides.PagePadding = lo.ToPtr(lo.Must(flags.GetInt("page-padding")))
+			}
+
+			if flags.NFlag() > 0 {
+				handleErr(manga.SaveEntry(entry))
+			}
+
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, style.Faint(entry.Overrides.String()))
+		}
+	},
+}
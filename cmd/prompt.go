@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+)
+
+// errHeadless is returned by prompts in headless mode
+var errHeadless = fmt.Errorf("can't ask for input in headless mode")
+
+// ask prompts the user for input, unless mangal is running headless
+func ask(prompt survey.Prompt, response any) error {
+	if viper.GetBool(key.CliHeadless) {
+		return errHeadless
+	}
+
+	return survey.AskOne(prompt, response)
+}
+
+// requireTerminal exits with an error if mangal is running headless
+func requireTerminal(what string) {
+	if viper.GetBool(key.CliHeadless) {
+		handleErr(fmt.Errorf("%s is not available in headless mode, use inline mode instead", what))
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/reading"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(queueCmd)
+
+	queueCmd.AddCommand(queueReadingCmd)
+	queueReadingCmd.Flags().BoolP("json", "j", false, "JSON output")
+
+	queueCmd.AddCommand(queueAddCmd)
+	queueAddCmd.Flags().StringP("query", "q", "", "query to search for")
+	queueAddCmd.Flags().StringP("manga", "m", "first", "manga selector, same as in inline mode")
+	lo.Must0(queueAddCmd.MarkFlagRequired("query"))
+
+	queueCmd.AddCommand(queuePromoteCmd)
+	queueCmd.AddCommand(queueRemoveCmd)
+}
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Plan to read queue",
+	Long: `Keep mangas you plan to read separate from the library.
+Queued mangas can be promoted to tracked library entries later, nothing is downloaded.`,
+}
+
+var queueReadingCmd = &cobra.Command{
+	Use:   "reading",
+	Short: "List queued mangas",
+	Run: func(cmd *cobra.Command, args []string) {
+		items, err := reading.List()
+		handleErr(err)
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			handleErr(json.NewEncoder(os.Stdout).Encode(items))
+			return
+		}
+
+		if len(items) == 0 {
+			fmt.Println("Queue is empty")
+			return
+		}
+
+		for _, item := range items {
+			fmt.Printf("%s %s\n", item.Name, style.Faint(item.SourceID))
+		}
+	},
+}
+
+var queueAddCmd = &cobra.Command{
+	Use:     "add",
+	Short:   "Search manga and add it to the queue",
+	Example: "mangal queue add --source Mangadex --query \"chainsaw man\"",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			query    = lo.Must(cmd.Flags().GetString("query"))
+			selector = lo.Must(cmd.Flags().GetString("manga"))
+		)
+
+		picker, err := inline.ParseMangaPicker(query, selector)
+		handleErr(err)
+
+		for _, name := range viper.GetStringSlice(key.DownloaderDefaultSources) {
+			if name == "" {
+				handleErr(errors.New("source not set"))
+			}
+
+			p, ok := provider.Get(name)
+			if !ok {
+				handleErr(fmt.Errorf("source not found: %s", name))
+			}
+
+			src, err := p.CreateSource()
+			handleErr(err)
+
+			mangas, err := src.Search(query)
+			handleErr(err)
+
+			manga := picker(mangas)
+			if manga == nil {
+				fmt.Printf("%s nothing found on %s\n", icon.Get(icon.Fail), src.Name())
+				continue
+			}
+
+			handleErr(reading.Add(reading.NewItem(manga)))
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, style.Faint("added to the queue"))
+		}
+	},
+}
+
+// pickQueued finds the queued manga by name, asking which one to choose if there are several
+func pickQueued(name string) *reading.Item {
+	items, err := reading.Find(name)
+	handleErr(err)
+
+	switch len(items) {
+	case 0:
+		handleErr(fmt.Errorf("%s is not in the queue", name))
+	case 1:
+		return items[0]
+	}
+
+	var index int
+	handleErr(ask(&survey.Select{
+		Message: "Which one?",
+		Options: lo.Map(items, func(item *reading.Item, _ int) string {
+			return item.String()
+		}),
+	}, &index))
+
+	return items[index]
+}
+
+var queuePromoteCmd = &cobra.Command{
+	Use:     "promote [manga name]",
+	Short:   "Move queued manga to the library",
+	Long:    "Move queued manga to the library as a tracked entry, without downloading anything.",
+	Example: "mangal queue promote \"Chainsaw Man\"",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		item := pickQueued(strings.Join(args, " "))
+
+		manga, err := reading.Promote(item)
+		handleErr(err)
+
+		fmt.Printf("%s %s %s\n", icon.Get(icon.Success), item.Name, style.Faint("tracked at "+manga.Path))
+	},
+}
+
+var queueRemoveCmd = &cobra.Command{
+	Use:   "remove [manga name]",
+	Short: "Remove manga from the queue",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		item := pickQueued(strings.Join(args, " "))
+		handleErr(reading.Remove(item))
+		fmt.Printf("%s %s %s\n", icon.Get(icon.Success), item.Name, style.Faint("removed from the queue"))
+	},
+}
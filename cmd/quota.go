@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/quota"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"path/filepath"
+)
+
+func init() {
+	rootCmd.AddCommand(quotaCmd)
+	quotaCmd.Flags().BoolP("evict", "e", false, "remove the chapters instead of only showing them")
+}
+
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Show what exceeds the library quota",
+	Long: fmt.Sprintf(`Show the size of the library and the chapters that would be removed to fit it into %s.
+Only read chapters are removed, the ones read the longest time ago first.
+Quota is enforced automatically after updates and server downloads.`, key.LibraryQuota),
+	Example: "mangal quota --evict",
+	Run: func(cmd *cobra.Command, args []string) {
+		plan, err := quota.Make(where.Downloads(), history.Default())
+		handleErr(err)
+
+		if plan.Limit == 0 {
+			fmt.Printf("Library takes %s, quota is not set\n", humanize.Bytes(plan.Size))
+			return
+		}
+
+		fmt.Printf("Library takes %s of %s\n", humanize.Bytes(plan.Size), humanize.Bytes(plan.Limit))
+
+		if len(plan.Evictions) == 0 {
+			fmt.Printf("%s Nothing to remove\n", icon.Get(icon.Success))
+		}
+
+		for _, eviction := range plan.Evictions {
+			fmt.Printf(
+				"%s %s\n",
+				filepath.Join(filepath.Base(eviction.Manga.Path), eviction.Chapter.Path),
+				style.Faint(humanize.Bytes(eviction.Size)),
+			)
+		}
+
+		if plan.Exceeded() {
+			fmt.Printf("%s Quota would still be exceeded, there are no more read chapters\n", icon.Get(icon.Fail))
+		}
+
+		if !lo.Must(cmd.Flags().GetBool("evict")) || len(plan.Evictions) == 0 {
+			return
+		}
+
+		handleErr(plan.Apply(func(string) {}))
+		fmt.Printf(
+			"\n%s Removed %s, freed %s\n",
+			icon.Get(icon.Success),
+			util.Quantify(len(plan.Evictions), "chapter", "chapters"),
+			humanize.Bytes(plan.Freed()),
+		)
+	},
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/subscription"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+	reconcileCmd.Flags().BoolP("list", "l", false, "only show the conflicts")
+	reconcileCmd.Flags().StringP("action", "a", "", "resolve every conflict with this action without asking: relink, ignore or redownload")
+}
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile [manga names...]",
+	Short: "Resolve the downloaded chapters that differ from the source",
+	Long: `Compare the downloaded chapters of the tracked mangas with the chapters on their sources,
+e.g. after the source has renumbered or removed some of them, and resolve the differences.
+Each conflicting chapter can be relinked to the chapter of the source it is now, ignored, or downloaded again.
+If no names are given, the whole library is checked.`,
+	Example: "mangal reconcile \"Chainsaw Man\"",
+	Run: func(cmd *cobra.Command, args []string) {
+		list := lo.Must(cmd.Flags().GetBool("list"))
+		action := lo.Must(cmd.Flags().GetString("action"))
+		if action != "" && !lo.Contains([]string{subscription.ActionRelink, subscription.ActionIgnore, subscription.ActionRedownload}, action) {
+			handleErr(fmt.Errorf("unknown action %q, expected relink, ignore or redownload", action))
+		}
+
+		mangas, err := library.Named(args...)
+		handleErr(err)
+
+		if len(mangas) == 0 {
+			handleErr(fmt.Errorf("no mangas found in the library"))
+		}
+
+		for _, manga := range mangas {
+			entry, err := manga.Entry()
+			handleErr(err)
+
+			if entry == nil {
+				if len(args) > 0 {
+					fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), manga.Name, style.Faint(i18n.T("is not tracked")))
+				}
+
+				continue
+			}
+
+			fmt.Println(style.Faint("Checking " + manga.Name))
+			remote, err := subscription.Remote(entry)
+			if err != nil {
+				fmt.Printf("%s %s: %s\n", icon.Get(icon.Fail), manga.Name, err)
+				continue
+			}
+
+			conflicts, err := subscription.Conflicts(manga, entry, remote)
+			handleErr(err)
+
+			if len(conflicts) == 0 {
+				fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, style.Faint(i18n.T("matches the source")))
+				continue
+			}
+
+			printConflicts(manga.Name, conflicts)
+			if list {
+				continue
+			}
+
+			resolutions := make([]*subscription.Resolution, 0, len(conflicts))
+			for _, conflict := range conflicts {
+				chosen := action
+				if chosen == "" {
+					chosen = askResolution(conflict)
+				}
+
+				if lo.Contains(conflict.Actions(), chosen) {
+					resolutions = append(resolutions, &subscription.Resolution{Conflict: conflict, Action: chosen})
+				}
+			}
+
+			handleErr(subscription.Resolve(manga, entry, resolutions))
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, style.Faint(i18n.T("resolved %s", i18n.N(len(resolutions), "conflict", "conflicts"))))
+		}
+	},
+}
+
+// printConflicts shows the local chapters side by side with the ones of the source
+func printConflicts(manga string, conflicts []*subscription.Conflict) {
+	width := util.Max(lipgloss.Width(i18n.T("Local")), lo.Max(lo.Map(conflicts, func(conflict *subscription.Conflict, _ int) int {
+		return lipgloss.Width(conflict.Local.Path)
+	})))
+
+	fmt.Printf("%s %s %s\n", icon.Get(icon.Mark), manga, style.Faint(i18n.T("has %s", i18n.N(len(conflicts), "conflict", "conflicts"))))
+	fmt.Printf("  %s  %s\n", style.Faint(pad(i18n.T("Local"), width)), style.Faint(i18n.T("Source")))
+
+	for _, conflict := range conflicts {
+		remote := "-"
+		if conflict.Remote != nil {
+			remote = fmt.Sprintf("[%d] %s", conflict.Remote.Index, conflict.Remote.Name)
+		}
+
+		fmt.Printf("  %s  %s %s\n", pad(conflict.Local.Path, width), remote, style.Faint(conflict.Kind))
+	}
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", util.Max(0, width-lipgloss.Width(s)))
+}
+
+// askResolution asks how to resolve the conflict, empty action skips it
+func askResolution(conflict *subscription.Conflict) string {
+	options := append(conflict.Actions(), "skip")
+
+	var action string
+	handleErr(ask(&survey.Select{
+		Message: fmt.Sprintf("%s (%s)", conflict.Local.Path, conflict.Kind),
+		Options: options,
+		Default: options[0],
+	}, &action))
+
+	if action == "skip" {
+		return ""
+	}
+
+	return action
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+)
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+	resumeCmd.Flags().BoolP("list", "l", false, "list the queued chapters without downloading them")
+	resumeCmd.Flags().BoolP("json", "j", false, "JSON output of the list")
+	resumeCmd.Flags().Bool("forget", false, "empty the queue without downloading the chapters")
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the interrupted downloads",
+	Long: `Download the chapters left in the queue by the interrupted downloads of the inline mode and the TUI.
+Pages fetched before are not downloaded again, the partially written files are converted again.
+Chapters leave the queue once they are downloaded or skipped.`,
+	Example: "mangal resume --list",
+	Run: func(cmd *cobra.Command, args []string) {
+		if lo.Must(cmd.Flags().GetBool("forget")) {
+			handleErr(downloader.Forget())
+			fmt.Printf("%s %s\n", icon.Get(icon.Success), i18n.T("Download queue is emptied"))
+			return
+		}
+
+		queued, err := downloader.Queue()
+		handleErr(err)
+
+		if lo.Must(cmd.Flags().GetBool("list")) {
+			if lo.Must(cmd.Flags().GetBool("json")) {
+				handleErr(json.NewEncoder(os.Stdout).Encode(queued))
+				return
+			}
+
+			printQueued(queued)
+			return
+		}
+
+		if len(queued) == 0 {
+			fmt.Println(i18n.T("Nothing to resume"))
+			return
+		}
+
+		var (
+			sources  = make(map[string]source.Source)
+			chapters []*source.Chapter
+		)
+
+		for _, entry := range queued {
+			src, ok := sources[entry.SourceID]
+			if !ok {
+				src, err = sourceByID(entry.SourceID)
+				if err != nil {
+					// chapters of the missing sources stay in the queue
+					fmt.Printf("%s %s\n", icon.Get(icon.Fail), err)
+				}
+
+				sources[entry.SourceID] = src
+			}
+
+			if src != nil {
+				chapters = append(chapters, entry.Chapter(src))
+			}
+		}
+
+		if len(chapters) == 0 {
+			return
+		}
+
+		fmt.Printf("%s %s\n", icon.Get(icon.Progress), i18n.T("Resuming %s", i18n.N(len(chapters), "chapter", "chapters")))
+
+		skipOnSignal()
+		summary, err := downloader.Batch(chapters, downloader.BatchOptions{
+			Retries:     viper.GetInt(key.DownloaderRetryFailed),
+			StopOnError: viper.GetBool(key.DownloaderStopOnError),
+			Downloaded: func(_ *source.Chapter, path string) {
+				fmt.Println(path)
+			},
+		})
+		handleErr(err)
+		handleErr(summary.Err())
+	},
+}
+
+func sourceByID(id string) (source.Source, error) {
+	p, ok := provider.GetByID(id)
+	if !ok {
+		return nil, fmt.Errorf("source %s is not found", id)
+	}
+
+	return p.CreateSource()
+}
+
+func printQueued(queued []*downloader.Queued) {
+	if len(queued) == 0 {
+		fmt.Println(i18n.T("Nothing to resume"))
+		return
+	}
+
+	for _, entry := range queued {
+		line := fmt.Sprintf("%s %s %s", entry.Manga.Name, entry.Name, style.Faint(entry.SourceID))
+		if entry.Error != "" {
+			line += " " + style.Fg(color.Red)(entry.Error)
+		}
+
+		fmt.Println(line)
+	}
+}
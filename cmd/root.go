@@ -1,20 +1,28 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"github.com/charmbracelet/lipgloss"
 	cc "github.com/ivanpirog/coloredcobra"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/config"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/downloader"
 	"github.com/metafates/mangal/icon"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/profiling"
 	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/tui"
 	"github.com/metafates/mangal/util"
 	"github.com/metafates/mangal/version"
 	"github.com/metafates/mangal/where"
+	"github.com/muesli/termenv"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -25,7 +33,7 @@ import (
 func init() {
 	rootCmd.Flags().BoolP("version", "v", false, "Print version")
 
-	rootCmd.PersistentFlags().StringP("format", "F", "", "output format")
+	rootCmd.PersistentFlags().StringP("format", "F", "", "output format, or several comma separated ones, e.g. cbz,pdf")
 	lo.Must0(rootCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return converter.Available(), cobra.ShellCompDirectiveDefault
 	}))
@@ -37,9 +45,15 @@ func init() {
 	}))
 	lo.Must0(viper.BindPFlag(key.IconsVariant, rootCmd.PersistentFlags().Lookup("icons")))
 
+	rootCmd.PersistentFlags().Bool("accessible", false, "screen reader friendly tui, without animations and with announcements of the changes")
+	lo.Must0(viper.BindPFlag(key.TUIAccessible, rootCmd.PersistentFlags().Lookup("accessible")))
+
 	rootCmd.PersistentFlags().BoolP("write-history", "H", true, "write history of the read chapters")
 	lo.Must0(viper.BindPFlag(key.HistorySaveOnRead, rootCmd.PersistentFlags().Lookup("write-history")))
 
+	rootCmd.PersistentFlags().Bool("translate", false, "translate pages with an external tool (experimental)")
+	lo.Must0(viper.BindPFlag(key.TranslatorEnable, rootCmd.PersistentFlags().Lookup("translate")))
+
 	rootCmd.PersistentFlags().StringSliceP("source", "S", []string{}, "default source to use")
 	lo.Must0(rootCmd.RegisterFlagCompletionFunc("source", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		var sources []string
@@ -56,6 +70,39 @@ func init() {
 	}))
 	lo.Must0(viper.BindPFlag(key.DownloaderDefaultSources, rootCmd.PersistentFlags().Lookup("source")))
 
+	rootCmd.PersistentFlags().Bool("headless", false, "run without a terminal: no tui, prompts or screen clearing, plain logs to stderr")
+	lo.Must0(viper.BindPFlag(key.CliHeadless, rootCmd.PersistentFlags().Lookup("headless")))
+
+	rootCmd.PersistentFlags().Bool("submit-crash", false, "submit the crash report to the crash.endpoint, if mangal crashes")
+	lo.Must0(viper.BindPFlag(key.CrashSubmit, rootCmd.PersistentFlags().Lookup("submit-crash")))
+
+	rootCmd.PersistentFlags().String("record-session", "", "record the requests and responses of this run to the file, to attach it to a bug report")
+	rootCmd.PersistentFlags().String("replay-session", "", "respond to the requests with the ones recorded by --record-session")
+	lo.Must0(rootCmd.MarkPersistentFlagFilename("record-session", "jsonl"))
+	lo.Must0(rootCmd.MarkPersistentFlagFilename("replay-session", "jsonl"))
+
+	rootCmd.PersistentFlags().Bool("profile-cpu", false, "write the cpu profile of this run to the logs directory")
+	rootCmd.PersistentFlags().Bool("profile-mem", false, "write the memory profiles of this run to the logs directory, at the end and at the peak")
+
+	// logs are set up before the flags are parsed
+	cobra.OnInitialize(func() {
+		if viper.GetBool(key.CliHeadless) {
+			lipgloss.SetColorProfile(termenv.Ascii)
+			handleErr(log.SetupHeadless())
+		} else {
+			color.SetAppearance(viper.GetString(key.CliAppearance))
+		}
+
+		handleErr(network.Setup())
+		handleErr(setupSession())
+		handleErr(startProfiling())
+
+		// the explicit format is used for every destination
+		if rootCmd.PersistentFlags().Changed("format") {
+			viper.Set(key.FormatsRules, []string{})
+		}
+	})
+
 	rootCmd.Flags().BoolP("continue", "c", false, "continue reading")
 
 	helpFunc := rootCmd.HelpFunc()
@@ -77,7 +124,7 @@ var rootCmd = &cobra.Command{
 	Long: constant.AsciiArtLogo + "\n" +
 		style.New().Italic(true).Foreground(color.HiRed).Render("    - The ultimate cli manga downloader"),
 	PreRun: func(cmd *cobra.Command, args []string) {
-		if _, err := converter.Get(viper.GetString(key.FormatsUse)); err != nil {
+		if _, err := converter.GetAll(source.Formats()); err != nil {
 			handleErr(err)
 		}
 	},
@@ -87,6 +134,11 @@ var rootCmd = &cobra.Command{
 			return
 		}
 
+		requireTerminal("TUI")
+
+		// apply the reloadable config changes while tui is running, the others on restart
+		config.Watch(nil)
+
 		options := tui.Options{
 			Continue: lo.Must(cmd.Flags().GetBool("continue")),
 		}
@@ -94,9 +146,56 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// setupSession starts recording or replaying the requests, if requested by the flags
+func setupSession() error {
+	record := lo.Must(rootCmd.PersistentFlags().GetString("record-session"))
+	replay := lo.Must(rootCmd.PersistentFlags().GetString("replay-session"))
+
+	switch {
+	case record != "" && replay != "":
+		return errors.New("can't record and replay the session at once")
+	case record != "":
+		return network.RecordSession(record)
+	case replay != "":
+		return network.ReplaySession(replay)
+	default:
+		return nil
+	}
+}
+
+// startProfiling starts the profiles requested by the flags, see stopProfiling
+func startProfiling() error {
+	_, err := profiling.Start(
+		lo.Must(rootCmd.PersistentFlags().GetBool("profile-cpu")),
+		lo.Must(rootCmd.PersistentFlags().GetBool("profile-mem")),
+	)
+
+	return err
+}
+
+// stopProfiling writes the started profiles and shows where they are
+func stopProfiling() {
+	profiles := profiling.Stop()
+	if profiles == nil {
+		return
+	}
+
+	for _, path := range []string{profiles.CPU, profiles.Mem, profiles.MemPeak} {
+		if path != "" {
+			_, _ = fmt.Fprintln(os.Stderr, style.Faint("Profile is written to "+path))
+		}
+	}
+}
+
+// exit stops the profiles before exiting, since the deferred functions are not run
+func exit(code int) {
+	stopProfiling()
+	os.Exit(code)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if viper.GetBool(key.CliColored) {
+	if viper.GetBool(key.CliColored) && !viper.GetBool(key.CliHeadless) {
 		// colored cobra injection
 		cc.Init(&cc.Config{
 			RootCmd:       rootCmd,
@@ -111,14 +210,25 @@ func Execute() {
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		exit(1)
 	}
+
+	stopProfiling()
 }
 
+// exitPartial is the exit code of a batch download where only some chapters have failed
+const exitPartial = 2
+
 func handleErr(err error) {
 	if err != nil {
 		log.Error(err)
 		_, _ = fmt.Fprintf(os.Stderr, "%s %s\n", icon.Get(icon.Fail), strings.Trim(err.Error(), " \n"))
-		os.Exit(1)
+
+		var batchErr *downloader.BatchError
+		if errors.As(err, &batchErr) && batchErr.Summary.Partial() {
+			exit(exitPartial)
+		}
+
+		exit(1)
 	}
 }
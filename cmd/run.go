@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"github.com/metafates/mangal/provider/custom"
+	"github.com/metafates/mangal/script"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
@@ -14,15 +14,21 @@ func init() {
 var runCmd = &cobra.Command{
 	Use:   "run [file]",
 	Short: "Run lua file",
-	Long: `Runs Lua5.1 VM. Useful for debugging.
-Or you can use mangal as a standalone lua interpreter.`,
+	Long: `Runs Lua5.1 VM with the mangal module, to automate mangal with scripts.
+The module searches sources, lists chapters, downloads and converts them and reads the library:
+
+	local mangal = require("mangal")
+	for _, manga in ipairs(mangal.library()) do
+		if manga.source then
+			local chapters = mangal.manga(manga.source, manga.url, manga.name):chapters()
+			mangal.download(chapters[#chapters])
+		end
+	end
+
+Custom sources can be debugged with it too, their missing functions are reported.`,
 	Args:    cobra.ExactArgs(1),
-	Example: "  mangal run ./test.lua",
+	Example: "  mangal run ./weekly.lua",
 	Run: func(cmd *cobra.Command, args []string) {
-		sourcePath := args[0]
-
-		// LoadSource runs file when it's loaded
-		_, err := custom.LoadSource(sourcePath, !lo.Must(cmd.Flags().GetBool("lenient")))
-		handleErr(err)
+		handleErr(script.Run(args[0], lo.Must(cmd.Flags().GetBool("lenient"))))
 	},
 }
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/secrets"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretUnsetCmd)
+}
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets",
+	Long: `Manage secrets, such as passwords, that are kept outside the config file.
+Environment variables with the ` + secrets.EnvPrefix + ` prefix take precedence over the stored values.`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:       "set [name]",
+	Short:     "Set a secret",
+	Example:   "mangal secret set " + secrets.ArchivePassword,
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: secrets.Names,
+	Run: func(cmd *cobra.Command, args []string) {
+		var value string
+		handleErr(ask(&survey.Password{
+			Message: fmt.Sprintf("Enter %s:", args[0]),
+		}, &value))
+
+		handleErr(secrets.Set(args[0], value))
+		fmt.Printf("%s %s set\n", icon.Get(icon.Success), args[0])
+	},
+}
+
+var secretUnsetCmd = &cobra.Command{
+	Use:       "unset [name]",
+	Short:     "Remove a secret",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: secrets.Names,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleErr(secrets.Delete(args[0]))
+		fmt.Printf("%s %s removed\n", icon.Get(icon.Success), args[0])
+	},
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/server"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringP("address", "a", "", "address to listen on")
+	serveCmd.Flags().String("pid-file", "", "file to write the process ID to")
+	serveCmd.Flags().Bool("pprof", false, "expose the profiles at /debug/pprof/")
+	lo.Must0(viper.BindPFlag(key.ServerAddress, serveCmd.Flags().Lookup("address")))
+	lo.Must0(viper.BindPFlag(key.ServerPidFile, serveCmd.Flags().Lookup("pid-file")))
+	lo.Must0(viper.BindPFlag(key.ServerPprof, serveCmd.Flags().Lookup("pprof")))
+	lo.Must0(serveCmd.MarkFlagFilename("pid-file"))
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the http server",
+	Long: `Start the http server.
+Supports systemd socket activation, the address is ignored in that case.
+Health can be checked with /healthz and readiness with /readyz.
+Mangas are searched with /api/search, which responds with the JSON of the inline mode,
+downloaded with /api/downloads and listed with /api/library.
+Downloaded chapter files are listed with /api/library?chapters=true and served by /api/files.
+With --pprof the profiles are served at /debug/pprof/, e.g. to diagnose the memory usage.
+
+Several users can share the server, see "mangal serve user".
+Set server.auth to basic or header to enable it.`,
+	Example: "mangal serve --address 0.0.0.0:6969",
+	Run: func(cmd *cobra.Command, args []string) {
+		skipOnSignal()
+
+		listener, activated, err := server.Listen(viper.GetString(key.ServerAddress))
+		handleErr(err)
+
+		if activated {
+			log.Info("using the socket passed by systemd")
+		}
+
+		if pidFile := viper.GetString(key.ServerPidFile); pidFile != "" {
+			err = filesystem.Api().WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+			handleErr(err)
+			defer filesystem.Api().Remove(pidFile)
+		}
+
+		s, err := server.New()
+		handleErr(err)
+
+		downloads := where.Downloads()
+		s.AddCheck("downloads", func() error {
+			return where.Writable(downloads)
+		})
+
+		go func() {
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			<-signals
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := s.Shutdown(ctx); err != nil {
+				log.Error(err)
+			}
+		}()
+
+		handleErr(s.Serve(listener))
+	},
+}
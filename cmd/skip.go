@@ -0,0 +1,26 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// skipOnSignal skips the chapters being downloaded on SIGUSR1,
+// e.g. when one of them hangs on a dead image server
+func skipOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	go func() {
+		for range signals {
+			if downloader.Skip() {
+				log.Info("skipping the current chapter on SIGUSR1")
+			}
+		}
+	}()
+}
@@ -0,0 +1,4 @@
+package cmd
+
+// skipOnSignal does nothing, there is no SIGUSR1 on windows
+func skipOnSignal() {}
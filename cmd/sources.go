@@ -38,6 +38,7 @@ func init() {
 	sourcesListCmd.Flags().BoolP("raw", "r", false, "do not print headers")
 	sourcesListCmd.Flags().BoolP("custom", "c", false, "show only custom sources")
 	sourcesListCmd.Flags().BoolP("builtin", "b", false, "show only builtin sources")
+	sourcesListCmd.Flags().BoolP("details", "d", false, "show the capabilities of the sources")
 
 	sourcesListCmd.MarkFlagsMutuallyExclusive("custom", "builtin")
 	sourcesListCmd.SetOut(os.Stdout)
@@ -55,17 +56,26 @@ var sourcesListCmd = &cobra.Command{
 			}
 		}
 
+		details := lo.Must(cmd.Flags().GetBool("details"))
+		p := func(p *provider.Provider) {
+			if capabilities := p.Capabilities.String(); details && capabilities != "" {
+				cmd.Printf("%s\t%s\n", p.Name, capabilities)
+			} else {
+				cmd.Println(p.Name)
+			}
+		}
+
 		printBuiltin := func() {
 			h("Builtin:")
-			for _, p := range provider.Builtins() {
-				cmd.Println(p.Name)
+			for _, builtin := range provider.Builtins() {
+				p(builtin)
 			}
 		}
 
 		printCustom := func() {
 			h("Custom:")
-			for _, p := range provider.Customs() {
-				cmd.Println(p.Name)
+			for _, custom := range provider.Customs() {
+				p(custom)
 			}
 		}
 
@@ -127,6 +137,7 @@ var sourcesInstallCmd = &cobra.Command{
 	Long: `Browse and install custom scrapers from official GitHub repo.
 https://github.com/metafates/mangal-scrapers`,
 	Run: func(cmd *cobra.Command, args []string) {
+		requireTerminal("Scrapers browser")
 		handleErr(tui.Run(&tui.Options{Install: true}))
 	},
 }
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/stats"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.AddCommand(statsReadingCmd)
+	statsReadingCmd.Flags().IntP("days", "d", 30, "number of the days to show, ending today")
+	statsReadingCmd.Flags().BoolP("json", "j", false, "JSON output")
+
+	statsCmd.AddCommand(statsDigestCmd)
+	statsDigestCmd.Flags().BoolP("json", "j", false, "JSON output")
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the statistics",
+}
+
+var statsReadingCmd = &cobra.Command{
+	Use:   "reading",
+	Short: "Show the chapters read and downloaded by day",
+	Long: `Show the chapters and the pages read and the chapters downloaded by day, as the charts.
+Chapters are counted as read once they are saved to the history.`,
+	Example: "mangal stats reading --days 90",
+	Run: func(cmd *cobra.Command, args []string) {
+		days, err := stats.Last(util.Max(lo.Must(cmd.Flags().GetInt("days")), 1))
+		handleErr(err)
+
+		digest := stats.Summarize(days)
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			handleErr(json.NewEncoder(os.Stdout).Encode(digest))
+			return
+		}
+
+		fmt.Printf("%s %s - %s\n\n", style.Bold(i18n.T("Reading")), digest.From, digest.To)
+
+		for _, chart := range []struct {
+			name  string
+			total int
+			value func(*stats.Day) int
+		}{
+			{i18n.T("Chapters"), digest.Chapters, func(d *stats.Day) int { return d.Chapters }},
+			{i18n.T("Pages"), digest.Pages, func(d *stats.Day) int { return d.Pages }},
+			{i18n.T("Downloads"), digest.Downloaded, func(d *stats.Day) int { return d.Downloaded }},
+		} {
+			values := lo.Map(days, func(d *stats.Day, _ int) int { return chart.value(d) })
+			fmt.Printf("%-10s %s %d\n", chart.name, stats.Sparkline(values), chart.total)
+		}
+
+		printCounts(i18n.T("Most read"), digest.Read)
+		printCounts(i18n.T("Most downloaded"), digest.Downloads)
+	},
+}
+
+var statsDigestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Show the digest of the past week",
+	Long: fmt.Sprintf(`Show the digest of the reading and the downloads of the past week.
+The same digest is posted to %s once a week by the library update, if it is set.`, key.HistoryDigestWebhook),
+	Run: func(cmd *cobra.Command, args []string) {
+		days, err := stats.Last(7)
+		handleErr(err)
+
+		digest := stats.Summarize(days)
+
+		if lo.Must(cmd.Flags().GetBool("json")) {
+			handleErr(json.NewEncoder(os.Stdout).Encode(digest))
+			return
+		}
+
+		fmt.Println(digest)
+		printCounts(i18n.T("Most read"), digest.Read)
+		printCounts(i18n.T("Most downloaded"), digest.Downloads)
+	},
+}
+
+// printCounts prints the first mangas of the counts
+func printCounts(title string, counts []*stats.MangaCount) {
+	if len(counts) == 0 {
+		return
+	}
+
+	names := lo.Map(counts[:util.Min(len(counts), 5)], func(c *stats.MangaCount, _ int) string {
+		return fmt.Sprintf("%s %s", c.Manga, style.Faint(fmt.Sprint(c.Chapters)))
+	})
+
+	fmt.Printf("\n%s\n%s\n", style.Bold(title), strings.Join(names, "\n"))
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/support"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func init() {
+	rootCmd.AddCommand(supportBundleCmd)
+	supportBundleCmd.Flags().StringP("output", "o", "", "path of the bundle, mangal-support-<date>.zip in the current directory by default")
+	lo.Must0(supportBundleCmd.MarkFlagFilename("output", "zip"))
+}
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collect the logs, the config and the diagnostics to attach to a bug report",
+	Long: `Write a zip with the diagnostics of the environment, the config with the secrets redacted,
+the sources with their versions, and the newest logs and crash reports.
+The home directory is replaced with ~. Review the bundle before sharing it.`,
+	Example: "mangal support-bundle -o bug.zip",
+	Run: func(cmd *cobra.Command, args []string) {
+		path := lo.Must(cmd.Flags().GetString("output"))
+		if path == "" {
+			path = fmt.Sprintf("mangal-support-%s.zip", time.Now().Format("2006-01-02T15-04-05"))
+		}
+
+		checks, err := support.Bundle(path)
+		handleErr(err)
+
+		for _, check := range checks {
+			if !check.OK {
+				fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), check.Name, style.Faint(check.Details))
+			}
+		}
+
+		fmt.Printf("%s Support bundle is saved to %s\n", icon.Get(icon.Success), path)
+	},
+}
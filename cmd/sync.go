@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/device"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringP("target", "t", "", "path where the device is mounted")
+	syncCmd.Flags().StringP("profile", "p", "", "device profile, detected automatically if not set. Available options are "+strings.Join(device.Profiles(), ", "))
+	syncCmd.Flags().BoolP("remove-read", "r", false, "remove read chapters from the device")
+	syncCmd.Flags().BoolP("dry-run", "d", false, "show what would be done without touching the device")
+
+	lo.Must0(syncCmd.MarkFlagRequired("target"))
+	lo.Must0(syncCmd.MarkFlagDirname("target"))
+	lo.Must0(syncCmd.RegisterFlagCompletionFunc("profile", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return device.Profiles(), cobra.ShellCompDirectiveDefault
+	}))
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [manga names...]",
+	Short: "Sync downloaded mangas with an e-reader",
+	Long: `Copy new chapters to an e-reader mounted as a folder (over USB or MTP).
+Chapters are converted to the format preferred by the device profile, unless --format is given.
+Sent chapters are tracked on the device, so chapters removed from it are not sent again.
+If no names are given, the whole library is synced.`,
+	Example: "mangal sync --target /media/kobo --remove-read",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			target      = lo.Must(cmd.Flags().GetString("target"))
+			profileName = lo.Must(cmd.Flags().GetString("profile"))
+			removeRead  = lo.Must(cmd.Flags().GetBool("remove-read"))
+			dryRun      = lo.Must(cmd.Flags().GetBool("dry-run"))
+		)
+
+		isDir, err := filesystem.Api().IsDir(target)
+		if err != nil || !isDir {
+			handleErr(fmt.Errorf("target %s is not a directory, is the device mounted?", target))
+		}
+
+		var profile *device.Profile
+		if profileName == "" {
+			profile = device.Detect(target)
+		} else {
+			profile, err = device.GetProfile(profileName)
+			handleErr(err)
+		}
+
+		var format string
+		if cmd.Flags().Changed("format") {
+			// devices get the primary format only
+			format = source.PrimaryFormat()
+		}
+
+		mangas, err := library.Named(args...)
+		handleErr(err)
+
+		fmt.Printf("Syncing %s with %s device\n\n", util.Quantify(len(mangas), "manga", "mangas"), style.Fg(color.Purple)(profile.Name))
+
+		report, err := device.Sync(mangas, &device.Options{
+			Target:     target,
+			Profile:    profile,
+			Format:     format,
+			RemoveRead: removeRead,
+			DryRun:     dryRun,
+			Progress: func(status string) {
+				fmt.Println(style.Faint(status))
+			},
+		})
+		handleErr(err)
+
+		for _, path := range report.Skipped {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), path, style.Faint("can't be converted"))
+		}
+
+		fmt.Printf(
+			"\n%s Sent %s, removed %s\n",
+			icon.Get(icon.Success),
+			util.Quantify(len(report.Sent), "chapter", "chapters"),
+			util.Quantify(len(report.Removed), "chapter", "chapters"),
+		)
+	},
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/remote"
+	"github.com/metafates/mangal/secrets"
+	"github.com/metafates/mangal/tui"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+	tuiCmd.Flags().BoolP("continue", "c", false, "continue reading")
+	tuiCmd.Flags().StringP("remote", "r", "", "address of the server to control, e.g. alice@nas:6969")
+	tuiCmd.Flags().BoolP("federated", "f", false, "show the local library merged with the libraries of library.remotes and --remote")
+}
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Run the TUI",
+	Long: `Run the TUI, same as running mangal without a command.
+With --remote the TUI shows the library and the download queue of a server started with "mangal serve"
+and chapters are downloaded by the server.
+Password of the user is taken from the ` + secrets.RemotePassword + ` secret or asked for.
+With --federated the TUI shows the local library merged with the libraries of the servers,
+with the locations of each chapter, and the selected chapters are pulled to the local library.`,
+	Example: "mangal tui --remote alice@nas:6969",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		rootCmd.PreRun(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		requireTerminal("TUI")
+
+		options := tui.Options{
+			Continue: lo.Must(cmd.Flags().GetBool("continue")),
+		}
+
+		address := lo.Must(cmd.Flags().GetString("remote"))
+		if lo.Must(cmd.Flags().GetBool("federated")) {
+			var addresses []string
+			if address != "" {
+				addresses = append(addresses, address)
+			}
+
+			options.Federation = loadFederation(addresses)
+		} else if address != "" {
+			options.Remote = connectRemote(address)
+		} else {
+			config.Watch(nil)
+		}
+
+		handleErr(tui.Run(&options))
+	},
+}
+
+// connectRemote returns the client of the server, asking for the password if it is not saved.
+// It fails if the server is unreachable
+func connectRemote(address string) *remote.Client {
+	client := newRemote(address)
+
+	_, err := client.Me()
+	handleErr(err)
+
+	return client
+}
+
+// newRemote returns the client of the server, asking for the password if it is not saved
+func newRemote(address string) *remote.Client {
+	client, err := remote.New(address)
+	handleErr(err)
+
+	if client.NeedsPassword() {
+		password, err := secrets.Get(secrets.RemotePassword)
+		handleErr(err)
+
+		if password == "" {
+			handleErr(ask(&survey.Password{
+				Message: fmt.Sprintf("Password of %s:", client.User()),
+			}, &password))
+		}
+
+		client.SetPassword(password)
+	}
+
+	return client
+}
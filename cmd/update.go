@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/subscription"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"os"
+	"sort"
+)
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+	updateCmd.Flags().BoolP("dry-run", "d", false, "show what would be done without downloading or removing anything")
+	updateCmd.Flags().BoolP("reuploads", "r", false, "check if the downloaded chapters were re-uploaded with fixed pages and offer to download them again")
+	updateCmd.Flags().BoolP("completed", "c", false, "check the completed mangas too")
+	updateCmd.Flags().BoolP("json", "j", false, "output the new chapters and the results as JSON")
+	updateCmd.Flags().Bool("download", false, "download the new chapters of the notify-only mangas too")
+
+	rootCmd.AddCommand(subscribeCmd)
+	subscribeCmd.Flags().IntP("latest", "l", 0, "keep only the latest n chapters on disk")
+	subscribeCmd.Flags().BoolP("all", "a", false, "download and keep all the chapters")
+	subscribeCmd.Flags().BoolP("notify", "n", false, "only report the new chapters, without downloading them")
+	subscribeCmd.MarkFlagsMutuallyExclusive("latest", "all", "notify")
+	subscribeCmd.Flags().StringSlice("pin", nil, "update only from these sources")
+	subscribeCmd.Flags().StringSlice("block", nil, "never update from these sources")
+	subscribeCmd.Flags().Bool("any-source", false, "remove the pinned and the blocked sources")
+}
+
+var updateCmd = &cobra.Command{
+	Use:   "update [manga names...]",
+	Short: "Download new chapters of the tracked mangas",
+	Long: `Check sources of the tracked library mangas for new chapters and download them.
+Mangas subscribed to the latest chapters only keep the newest ones on disk,
+older chapters are removed once they are read.
+With --reuploads, pages of the downloaded chapters are compared with the ones they were downloaded from,
+to find the chapters the source has fixed since then.
+Mangas that are finished and fully downloaded are marked completed and are not checked again, unless --completed is given.
+The chapters that appeared since the previous update are shown, with --json they are printed as JSON.
+Nothing is downloaded for the notify-only mangas, unless --download is given.
+Mangas that are not found on their source are looked for on the sources of sources.priority, in order.
+If no names are given, the whole library is updated.`,
+	Example: "mangal update \"Chainsaw Man\"",
+	Run: func(cmd *cobra.Command, args []string) {
+		skipOnSignal()
+
+		mangas, err := library.Named(args...)
+		handleErr(err)
+
+		if len(mangas) == 0 {
+			handleErr(fmt.Errorf("no mangas found in the library"))
+		}
+
+		asJson := lo.Must(cmd.Flags().GetBool("json"))
+
+		options := &subscription.Options{
+			DryRun: lo.Must(cmd.Flags().GetBool("dry-run")),
+			Progress: func(status string) {
+				fmt.Println(style.Faint(status))
+			},
+			Reuploads: lo.Must(cmd.Flags().GetBool("reuploads")),
+			Completed: lo.Must(cmd.Flags().GetBool("completed")),
+			Download:  lo.Must(cmd.Flags().GetBool("download")),
+			Redownload: func(paths []string) bool {
+				for _, path := range paths {
+					fmt.Printf("%s %s %s\n", icon.Get(icon.Progress), path, style.Faint(i18n.T("was re-uploaded by the source")))
+				}
+
+				var redownload bool
+				err := ask(&survey.Confirm{
+					Message: i18n.T("Download %s again?", i18n.N(len(paths), "chapter", "chapters")),
+					Default: true,
+				}, &redownload)
+
+				return err == nil && redownload
+			},
+		}
+
+		// stdout is for the JSON only, re-uploads are reported without asking
+		if asJson {
+			options.Progress = nil
+			options.Redownload = nil
+		}
+
+		report, err := subscription.Update(mangas, options)
+		handleErr(err)
+
+		if asJson {
+			errs := make(map[string]string, len(report.Errors))
+			for name, err := range report.Errors {
+				errs[name] = err.Error()
+			}
+
+			handleErr(json.NewEncoder(os.Stdout).Encode(map[string]any{
+				"new":        report.New,
+				"downloaded": report.Downloaded,
+				"failed":     report.Failed,
+				"removed":    report.Removed,
+				"reuploaded": report.Reuploaded,
+				"completed":  report.Completed,
+				"conflicts":  report.Conflicts,
+				"fallbacks":  report.Fallbacks,
+				"served":     report.Served,
+				"errors":     errs,
+			}))
+			return
+		}
+
+		for _, delta := range report.New {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Mark), delta.Manga, style.Faint(i18n.T("%s new", i18n.N(len(delta.Chapters), "chapter", "chapters"))))
+			for _, chapter := range delta.Chapters {
+				fmt.Printf("  %s\n", chapter.Name)
+			}
+		}
+
+		fallbacks := lo.Keys(report.Fallbacks)
+		sort.Strings(fallbacks)
+		for _, name := range fallbacks {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Mark), name, style.Faint(i18n.T("is served by %s", report.Fallbacks[name])))
+		}
+
+		for _, path := range report.Failed {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), path, style.Faint(i18n.T("failed to download")))
+		}
+
+		names := lo.Keys(report.Errors)
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s %s: %s\n", icon.Get(icon.Fail), name, report.Errors[name])
+		}
+
+		// untracked mangas are only worth mentioning if they were asked for
+		if len(args) > 0 {
+			for _, name := range report.Untracked {
+				fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), name, style.Faint(i18n.T("is not tracked")))
+			}
+
+			for _, name := range report.Skipped {
+				fmt.Printf("%s %s %s\n", icon.Get(icon.Mark), name, style.Faint(i18n.T("is completed, use --completed to check it")))
+			}
+		}
+
+		for _, name := range report.Conflicts {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Mark), name, style.Faint(i18n.T("has chapters that differ from the source, run mangal reconcile")))
+		}
+
+		for _, name := range report.Completed {
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), name, style.Faint(i18n.T("is completed and won't be checked again")))
+		}
+
+		fmt.Printf(
+			"\n%s %s\n",
+			icon.Get(icon.Success),
+			i18n.T(
+				"Downloaded %s, removed %s",
+				i18n.N(len(report.Downloaded), "chapter", "chapters"),
+				i18n.N(len(report.Removed), "chapter", "chapters"),
+			),
+		)
+
+		if len(report.Reuploaded) > 0 {
+			fmt.Printf(
+				"%s %s\n",
+				icon.Get(icon.Success),
+				i18n.T(
+					"Found %s re-uploaded by the source, downloaded %d again",
+					i18n.N(len(report.Reuploaded), "chapter", "chapters"),
+					len(report.Redownloaded),
+				),
+			)
+		}
+	},
+}
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe [manga names...]",
+	Short: "Change how tracked mangas are updated",
+	Long: `Change the subscription of the tracked mangas.
+With --latest, only the newest chapters are kept on disk and older ones are removed once they are read,
+which suits weekly series on small storage devices.
+With --notify, update only reports the new chapters and notifies about them, for the mangas read online.
+With --pin and --block, the manga is updated only from the pinned sources and never from the blocked ones.
+Without flags, current subscriptions are shown.`,
+	Example: "mangal subscribe \"One Piece\" --latest 3",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		latest := lo.Must(cmd.Flags().GetInt("latest"))
+		if latest < 0 {
+			handleErr(fmt.Errorf("latest must be positive, got %d", latest))
+		}
+
+		change := cmd.Flags().Changed("latest") || cmd.Flags().Changed("all") || cmd.Flags().Changed("notify")
+		notify := lo.Must(cmd.Flags().GetBool("notify"))
+
+		pin := sourceIDs(lo.Must(cmd.Flags().GetStringSlice("pin")))
+		block := sourceIDs(lo.Must(cmd.Flags().GetStringSlice("block")))
+		anySource := lo.Must(cmd.Flags().GetBool("any-source"))
+
+		mangas, err := library.Named(args...)
+		handleErr(err)
+
+		if len(mangas) == 0 {
+			handleErr(fmt.Errorf("no mangas found in the library"))
+		}
+
+		for _, manga := range mangas {
+			entry, err := manga.Entry()
+			handleErr(err)
+
+			if entry == nil {
+				fmt.Printf("%s %s %s\n", icon.Get(icon.Fail), manga.Name, style.Faint(i18n.T("is not tracked")))
+				continue
+			}
+
+			if anySource || len(pin) > 0 || len(block) > 0 {
+				if anySource {
+					entry.Sources = library.Sources{}
+				}
+
+				entry.Sources.Pin(pin...)
+				entry.Sources.Block(block...)
+				handleErr(manga.SaveEntry(entry))
+			}
+
+			if change {
+				entry.Subscription = library.Subscription{}
+				switch {
+				case latest > 0:
+					entry.Subscription = library.Subscription{Mode: library.SubscriptionLatestOnly, Keep: latest}
+				case notify:
+					entry.Subscription = library.Subscription{Mode: library.SubscriptionNotifyOnly}
+				}
+
+				handleErr(manga.SaveEntry(entry))
+			}
+
+			description := entry.Subscription.String()
+			if entry.Completed {
+				description += ", " + i18n.T("completed")
+			}
+
+			if !entry.Sources.IsEmpty() {
+				description += ", " + entry.Sources.String()
+			}
+
+			fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, style.Faint(description))
+		}
+	},
+}
+
+// sourceIDs returns the IDs of the sources given by their names or IDs
+func sourceIDs(names []string) []string {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		p, ok := provider.Get(name)
+		if !ok {
+			p, ok = provider.GetByID(name)
+		}
+
+		if !ok {
+			handleErr(fmt.Errorf("source %s not found", name))
+		}
+
+		ids = append(ids, p.ID)
+	}
+
+	return ids
+}
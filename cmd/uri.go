@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/deeplink"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/server"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+)
+
+func init() {
+	rootCmd.AddCommand(uriCmd)
+
+	uriCmd.AddCommand(uriRegisterCmd)
+	uriRegisterCmd.Flags().StringP("remote", "r", "", "queue the downloads on the server instead, e.g. alice@nas:6969")
+
+	uriCmd.AddCommand(uriUnregisterCmd)
+
+	uriCmd.AddCommand(uriHandleCmd)
+	uriHandleCmd.Flags().StringP("remote", "r", "", "queue the downloads on the server instead, e.g. alice@nas:6969")
+}
+
+var uriCmd = &cobra.Command{
+	Use:   "uri",
+	Short: "Handle mangal:// links",
+	Long: `Handle mangal:// links, e.g. from a userscript, to download chapters without searching them:
+
+	mangal://download?src=Manganelo&url=https://ww5.manganelo.tv/manga/berserk&chapters=last
+
+Parameters:
+  src - name or ID of the source
+  url - URL of the manga page
+  name - name of the manga, searched by the URL if omitted
+  chapters - chapter selector, same as in inline mode, all chapters are downloaded only if confirmed when omitted
+  chapter - URL of the chapter to download instead, can be repeated
+
+Links are supported on Linux (with xdg-utils) and Windows.`,
+}
+
+var uriRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Register mangal as the handler of mangal:// links",
+	Run: func(cmd *cobra.Command, args []string) {
+		executable, err := os.Executable()
+		handleErr(err)
+
+		command := []string{executable, "uri", "handle"}
+		if address := lo.Must(cmd.Flags().GetString("remote")); address != "" {
+			command = append(command, "--remote", address)
+		}
+
+		handleErr(deeplink.Register(command))
+		fmt.Printf("%s registered %s\n", icon.Get(icon.Success), style.Fg(color.Purple)(deeplink.Scheme+"://"))
+	},
+}
+
+var uriUnregisterCmd = &cobra.Command{
+	Use:   "unregister",
+	Short: "Remove the handler of mangal:// links",
+	Run: func(cmd *cobra.Command, args []string) {
+		handleErr(deeplink.Unregister())
+		fmt.Printf("%s unregistered %s\n", icon.Get(icon.Success), style.Fg(color.Purple)(deeplink.Scheme+"://"))
+	},
+}
+
+var uriHandleCmd = &cobra.Command{
+	Use:     "handle [link]",
+	Short:   "Download the chapters of the mangal:// link",
+	Args:    cobra.ExactArgs(1),
+	Example: `  mangal uri handle "mangal://download?src=Mangapill&url=https://mangapill.com/manga/2/berserk&chapters=last"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		link, err := deeplink.Parse(args[0])
+		handleErr(err)
+
+		p, ok := provider.Get(link.Source)
+		if !ok {
+			if p, ok = provider.GetByID(link.Source); !ok {
+				handleErr(fmt.Errorf("source %s not found", link.Source))
+			}
+		}
+
+		src, err := p.CreateSource()
+		handleErr(err)
+
+		manga := &source.Manga{Name: link.Name, URL: link.URL, Source: src}
+		if manga.Name == "" {
+			manga, err = provider.FindByLink(src, link.URL)
+			handleErr(err)
+		}
+
+		chapters, err := src.ChaptersOf(manga)
+		handleErr(err)
+
+		if len(link.ChapterURLs) > 0 {
+			chapters = lo.Filter(chapters, func(chapter *source.Chapter, _ int) bool {
+				return lo.ContainsBy(link.ChapterURLs, func(url string) bool {
+					return provider.SameLink(chapter.URL, url)
+				})
+			})
+		} else if link.Chapters != "" {
+			filter, err := inline.ParseChaptersFilter(link.Chapters)
+			handleErr(err)
+
+			chapters, err = filter(chapters)
+			handleErr(err)
+		}
+
+		if len(chapters) == 0 {
+			handleErr(fmt.Errorf("no chapters of %s to download", manga.Name))
+		}
+
+		// the link may come from any page, so the whole manga is not downloaded without asking
+		if !link.SelectsChapters() {
+			var confirmed bool
+			handleErr(ask(&survey.Confirm{
+				Message: fmt.Sprintf("Download all %s of %s?", util.Quantify(len(chapters), "chapter", "chapters"), manga.Name),
+				Default: false,
+			}, &confirmed))
+
+			if !confirmed {
+				return
+			}
+		}
+
+		if address := lo.Must(cmd.Flags().GetString("remote")); address != "" {
+			jobs, err := connectRemote(address).Download(&server.DownloadRequest{
+				Manga: server.MangaRef{
+					SourceID: src.ID(),
+					Name:     manga.Name,
+					URL:      manga.URL,
+					ID:       manga.ID,
+				},
+				Chapters: lo.Map(chapters, func(chapter *source.Chapter, _ int) string {
+					return chapter.URL
+				}),
+			})
+			handleErr(err)
+
+			fmt.Printf("%s queued %s of %s\n", icon.Get(icon.Success), util.Quantify(len(jobs), "chapter", "chapters"), manga.Name)
+			return
+		}
+
+		skipOnSignal()
+		summary, err := downloader.Batch(chapters, downloader.BatchOptions{
+			Retries:     viper.GetInt(key.DownloaderRetryFailed),
+			StopOnError: viper.GetBool(key.DownloaderStopOnError),
+			Downloaded: func(_ *source.Chapter, path string) {
+				fmt.Println(path)
+			},
+		})
+		handleErr(err)
+		handleErr(summary.Err())
+	},
+}
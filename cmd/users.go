@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/users"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	serveCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userAddCmd)
+	userCmd.AddCommand(userPasswdCmd)
+	userCmd.AddCommand(userRemoveCmd)
+	userCmd.AddCommand(userListCmd)
+}
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage server users",
+	Long: `Manage the users of the server.
+Each user has their own reading progress and, with the personal library, their own downloads.`,
+}
+
+// askPassword prompts for the new password twice
+func askPassword() (string, error) {
+	var password, confirmation string
+
+	if err := ask(&survey.Password{Message: "Password:"}, &password); err != nil {
+		return "", err
+	}
+
+	if err := ask(&survey.Password{Message: "Confirm password:"}, &confirmation); err != nil {
+		return "", err
+	}
+
+	if password != confirmation {
+		return "", fmt.Errorf("passwords do not match")
+	}
+
+	return password, nil
+}
+
+func completeUsers(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	list, err := users.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return lo.Map(list, func(user *users.User, _ int) string {
+		return user.Name
+	}), cobra.ShellCompDirectiveNoFileComp
+}
+
+var userAddCmd = &cobra.Command{
+	Use:     "add [name]",
+	Short:   "Add a user",
+	Example: "mangal serve user add alice",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		password, err := askPassword()
+		handleErr(err)
+
+		handleErr(users.Add(args[0], password))
+		fmt.Printf("%s %s added\n", icon.Get(icon.Success), args[0])
+	},
+}
+
+var userPasswdCmd = &cobra.Command{
+	Use:               "passwd [name]",
+	Short:             "Change the password of a user",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeUsers,
+	Run: func(cmd *cobra.Command, args []string) {
+		password, err := askPassword()
+		handleErr(err)
+
+		handleErr(users.SetPassword(args[0], password))
+		fmt.Printf("%s password of %s changed\n", icon.Get(icon.Success), args[0])
+	},
+}
+
+var userRemoveCmd = &cobra.Command{
+	Use:               "remove [name]",
+	Short:             "Remove a user",
+	Long:              "Remove a user. Their reading progress and personal library are kept.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeUsers,
+	Run: func(cmd *cobra.Command, args []string) {
+		handleErr(users.Remove(args[0]))
+		fmt.Printf("%s %s removed\n", icon.Get(icon.Success), args[0])
+	},
+}
+
+var userListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List users",
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := users.List()
+		handleErr(err)
+
+		for _, user := range list {
+			fmt.Printf("%s %s\n", user.Name, style.Faint(user.Created.Format("2006-01-02")))
+		}
+	},
+}
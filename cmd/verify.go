@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().BoolP("quick", "q", false, "compare sizes only, without computing checksums")
+	verifyCmd.Flags().BoolP("rebuild", "r", false, "rebuild manifests from the files on disk instead of verifying")
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [manga names...]",
+	Short: "Verify downloaded mangas against their manifests",
+	Long: `Verify downloaded files against manifest.json of each manga.
+Reports missing, modified and untracked files.
+If no names are given, the whole library is verified.`,
+	Example: "mangal verify --quick",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			quick   = lo.Must(cmd.Flags().GetBool("quick"))
+			rebuild = lo.Must(cmd.Flags().GetBool("rebuild"))
+		)
+
+		mangas, err := library.Named(args...)
+		handleErr(err)
+
+		if len(mangas) == 0 {
+			handleErr(fmt.Errorf("no mangas found in the library"))
+		}
+
+		var drifted int
+		for _, manga := range mangas {
+			if rebuild {
+				m, err := manifest.Rebuild(manga.Path)
+				handleErr(err)
+				fmt.Printf("%s %s %s\n", icon.Get(icon.Success), manga.Name, style.Faint(util.Quantify(len(m.Files), "file", "files")))
+				continue
+			}
+
+			report, err := manifest.Verify(manga.Path, quick)
+			handleErr(err)
+
+			if report.OK() {
+				fmt.Printf("%s %s\n", icon.Get(icon.Success), manga.Name)
+				continue
+			}
+
+			drifted++
+			fmt.Printf("%s %s\n", icon.Get(icon.Fail), manga.Name)
+			for _, group := range []struct {
+				title string
+				files []string
+			}{
+				{"missing", report.Missing},
+				{"modified", report.Changed},
+				{"untracked", report.Untracked},
+			} {
+				for _, file := range group.files {
+					fmt.Printf("  %s %s\n", style.Faint(group.title), file)
+				}
+			}
+		}
+
+		if drifted > 0 {
+			fmt.Printf("\n%s drifted from the manifest\n", util.Quantify(drifted, "manga", "mangas"))
+			exit(1)
+		}
+	},
+}
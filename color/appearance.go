@@ -0,0 +1,29 @@
+package color
+
+import "github.com/charmbracelet/lipgloss"
+
+// Appearances of the terminal, see SetAppearance
+const (
+	AppearanceAuto  = "auto"
+	AppearanceDark  = "dark"
+	AppearanceLight = "light"
+)
+
+// Appearances returns the available appearances
+func Appearances() []string {
+	return []string{AppearanceAuto, AppearanceDark, AppearanceLight}
+}
+
+// SetAppearance picks the variants of the adaptive colors for the background of the terminal.
+// Auto asks the terminal for its background color, it is done right away
+// so that the answer is not mixed with the input of the TUI
+func SetAppearance(appearance string) {
+	switch appearance {
+	case AppearanceDark:
+		lipgloss.SetHasDarkBackground(true)
+	case AppearanceLight:
+		lipgloss.SetHasDarkBackground(false)
+	default:
+		_ = lipgloss.HasDarkBackground()
+	}
+}
@@ -2,28 +2,36 @@ package color
 
 import "github.com/charmbracelet/lipgloss"
 
+// The palette adapts to the background of the terminal, see SetAppearance.
+// Dark variants are the ANSI colors, light ones are darker where the ANSI ones are hard to read on white
+
 var (
-	Red    = New("1")
-	Green  = New("2")
-	Yellow = New("3")
-	Blue   = New("4")
-	Purple = New("5")
-	Cyan   = New("6")
-	White  = New("7")
-	Black  = New("8")
+	Red    = Adaptive("1", "1")
+	Green  = Adaptive("2", "2")
+	Yellow = Adaptive("#9a6700", "3")
+	Blue   = Adaptive("4", "4")
+	Purple = Adaptive("5", "5")
+	Cyan   = Adaptive("6", "6")
+	White  = Adaptive("0", "7")
+	Black  = Adaptive("8", "8")
 )
 
 var (
-	HiRed    = New("9")
-	HiGreen  = New("10")
-	HiYellow = New("11")
-	HiBlue   = New("12")
-	HiPurple = New("13")
-	HiCyan   = New("14")
-	HiWhite  = New("15")
-	HiBlack  = New("16")
+	HiRed    = Adaptive("1", "9")
+	HiGreen  = Adaptive("2", "10")
+	HiYellow = Adaptive("#9a6700", "11")
+	HiBlue   = Adaptive("4", "12")
+	HiPurple = Adaptive("5", "13")
+	HiCyan   = Adaptive("6", "14")
+	HiWhite  = Adaptive("0", "15")
+	HiBlack  = Adaptive("16", "16")
 )
 
 func New(color string) lipgloss.Color {
 	return lipgloss.Color(color)
 }
+
+// Adaptive returns the color with the variants for the light and the dark backgrounds
+func Adaptive(light, dark string) lipgloss.AdaptiveColor {
+	return lipgloss.AdaptiveColor{Light: light, Dark: dark}
+}
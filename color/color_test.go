@@ -0,0 +1,51 @@
+package color
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSetAppearance(t *testing.T) {
+	Convey("Given the light appearance", t, func() {
+		SetAppearance(AppearanceLight)
+		defer SetAppearance(AppearanceDark)
+
+		Convey("Then the light variants of the colors should be used", func() {
+			So(lipgloss.HasDarkBackground(), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given the dark appearance", t, func() {
+		SetAppearance(AppearanceDark)
+
+		Convey("Then the dark variants of the colors should be used", func() {
+			So(lipgloss.HasDarkBackground(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestParse(t *testing.T) {
+	Convey("Given the colors from the config", t, func() {
+		Convey("Then the names, hex codes and ANSI numbers should be parsed", func() {
+			c, ok := Parse("Red")
+			So(ok, ShouldBeTrue)
+			So(c, ShouldResemble, Red)
+
+			c, ok = Parse("#ffb703")
+			So(ok, ShouldBeTrue)
+			So(c, ShouldEqual, New("#ffb703"))
+
+			c, ok = Parse("200")
+			So(ok, ShouldBeTrue)
+			So(c, ShouldEqual, New("200"))
+		})
+
+		Convey("Then the unknown colors should not be parsed", func() {
+			for _, color := range []string{"", "rainbow", "#ff", "256"} {
+				_, ok := Parse(color)
+				So(ok, ShouldBeFalse)
+			}
+		})
+	})
+}
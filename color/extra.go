@@ -1,5 +1,5 @@
 package color
 
 var (
-	Orange = New("#ffb703")
+	Orange = Adaptive("#b45309", "#ffb703")
 )
@@ -0,0 +1,50 @@
+package color
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// names of the colors, as they are given in the config
+var names = map[string]lipgloss.TerminalColor{
+	"red":      Red,
+	"green":    Green,
+	"yellow":   Yellow,
+	"blue":     Blue,
+	"purple":   Purple,
+	"cyan":     Cyan,
+	"white":    White,
+	"black":    Black,
+	"hired":    HiRed,
+	"higreen":  HiGreen,
+	"hiyellow": HiYellow,
+	"hiblue":   HiBlue,
+	"hipurple": HiPurple,
+	"hicyan":   HiCyan,
+	"hiwhite":  HiWhite,
+	"hiblack":  HiBlack,
+	"orange":   Orange,
+}
+
+var hex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Parse returns the color by its name (e.g. red, hiblue, orange), hex code (#ffb703) or ANSI number (0-255)
+func Parse(color string) (lipgloss.TerminalColor, bool) {
+	color = strings.TrimSpace(color)
+
+	if c, ok := names[strings.ToLower(color)]; ok {
+		return c, true
+	}
+
+	if hex.MatchString(color) {
+		return New(color), true
+	}
+
+	if n, err := strconv.Atoi(color); err == nil && n >= 0 && n <= 255 {
+		return New(color), true
+	}
+
+	return nil, false
+}
@@ -29,14 +29,14 @@ func Setup() error {
 		switch err.(type) {
 		case viper.ConfigFileNotFoundError:
 			// Use defaults then
-			return nil
+			return validate()
 		default:
 			return err
 		}
 	}
 
 	resolveAliases()
-	return nil
+	return validate()
 }
 
 func setName() {
@@ -75,15 +75,18 @@ func setDefaults() {
 // resolveAliases resolves the aliases for the paths
 func resolveAliases() {
 	home := lo.Must(os.UserHomeDir())
-	path := viper.GetString(key.DownloaderPath)
 
-	if path == "~" {
-		path = home
-	} else if strings.HasPrefix(path, fmt.Sprintf("%c%c", '~', os.PathSeparator)) {
-		path = filepath.Join(home, path[2:])
-	}
+	for _, k := range []string{key.DownloaderPath, key.DownloaderFallbackPath} {
+		path := viper.GetString(k)
+
+		if path == "~" {
+			path = home
+		} else if strings.HasPrefix(path, fmt.Sprintf("%c%c", '~', os.PathSeparator)) {
+			path = filepath.Join(home, path[2:])
+		}
 
-	path = os.ExpandEnv(path)
+		path = os.ExpandEnv(path)
 
-	viper.Set(key.DownloaderPath, path)
+		viper.Set(k, path)
+	}
 }
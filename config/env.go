@@ -1,3 +1,62 @@
 package config
 
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"io"
+	"strings"
+)
+
+// EnvExposed are the config keys that can be set with environment variables
 var EnvExposed []string
+
+// EnvName returns the environment variable that overrides the config key,
+// e.g. MANGAL_DOWNLOADER_PATH for downloader.path
+func EnvName(key string) string {
+	return strings.ToUpper(constant.Mangal + "_" + EnvKeyReplacer.Replace(key))
+}
+
+// WriteEnvDocs writes markdown documentation of the environment variables
+// generated from the config fields, in the order they are defined.
+//
+// Precedence, from highest to lowest, is:
+// command line flags, environment variables, config file, defaults
+func WriteEnvDocs(w io.Writer) error {
+	lines := []string{
+		"| Variable | Key | Type | Default | Description |",
+		"|----------|-----|------|---------|-------------|",
+	}
+
+	for _, field := range defaults {
+		lines = append(lines, fmt.Sprintf(
+			"| `%s` | `%s` | %s | `%v` | %s |",
+			EnvName(field.Key),
+			field.Key,
+			field.typeName(),
+			envValue(field.Value),
+			markdownCell.Replace(strings.TrimSpace(field.Description)),
+		))
+	}
+
+	_, err := fmt.Fprintf(
+		w,
+		"Environment variables override the config file, command line flags override both.\n"+
+			"List values are separated by spaces, e.g. `%s=\"mangadex manganato\"`.\n\n%s\n",
+		EnvName(key.DownloaderDefaultSources),
+		strings.Join(lines, "\n"),
+	)
+	return err
+}
+
+// markdownCell escapes text to fit in a single markdown table cell
+var markdownCell = strings.NewReplacer("\n", "<br>", "|", "\\|")
+
+// envValue formats the value as it should be set in the environment variable
+func envValue(value any) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, " ")
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+func TestEnvName(t *testing.T) {
+	Convey("Given a config key", t, func() {
+		Convey("Then env name should be prefixed and upper cased", func() {
+			So(EnvName(key.DownloaderPath), ShouldEqual, "MANGAL_DOWNLOADER_PATH")
+			So(EnvName(key.MetadataComicInfoXMLAddDate), ShouldEqual, "MANGAL_METADATA_COMIC_INFO_XML_ADD_DATE")
+		})
+	})
+
+	Convey("Given every config key", t, func() {
+		Convey("Then it should be exposed as env", func() {
+			So(EnvExposed, ShouldHaveLength, key.DefinedFieldsCount)
+		})
+	})
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	Convey("Given a config file", t, func() {
+		viper.Reset()
+		lo.Must0(filesystem.Api().WriteFile(
+			filepath.Join(where.Config(), constant.Mangal+".toml"),
+			[]byte("[formats]\nuse = \"cbz\"\n[downloader]\ndefault_sources = [\"mangadex\"]\n"),
+			os.ModePerm,
+		))
+
+		defer func() {
+			_ = filesystem.Api().Remove(filepath.Join(where.Config(), constant.Mangal+".toml"))
+			viper.Reset()
+		}()
+
+		Convey("When nothing overrides it", func() {
+			lo.Must0(Setup())
+
+			Convey("Then config file value should be used instead of default", func() {
+				So(viper.GetString(key.FormatsUse), ShouldEqual, "cbz")
+				So(viper.GetBool(key.MetadataSeriesJSON), ShouldBeTrue)
+			})
+		})
+
+		Convey("When env variables are set", func() {
+			lo.Must0(os.Setenv(EnvName(key.FormatsUse), "zip"))
+			lo.Must0(os.Setenv(EnvName(key.DownloaderDefaultSources), "manganato mangapill"))
+			lo.Must0(os.Setenv(EnvName(key.MetadataSeriesJSON), "false"))
+			defer func() {
+				_ = os.Unsetenv(EnvName(key.FormatsUse))
+				_ = os.Unsetenv(EnvName(key.DownloaderDefaultSources))
+				_ = os.Unsetenv(EnvName(key.MetadataSeriesJSON))
+			}()
+
+			lo.Must0(Setup())
+
+			Convey("Then they should take precedence over the config file and defaults", func() {
+				So(viper.GetString(key.FormatsUse), ShouldEqual, "zip")
+				So(viper.GetStringSlice(key.DownloaderDefaultSources), ShouldResemble, []string{"manganato", "mangapill"})
+				So(viper.GetBool(key.MetadataSeriesJSON), ShouldBeFalse)
+			})
+
+			Convey("When a flag is set", func() {
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				flags.String("format", "", "")
+				lo.Must0(viper.BindPFlag(key.FormatsUse, flags.Lookup("format")))
+				lo.Must0(flags.Parse([]string{"--format", "epub"}))
+
+				Convey("Then it should take precedence over env", func() {
+					So(viper.GetString(key.FormatsUse), ShouldEqual, "epub")
+				})
+			})
+		})
+
+		Convey("When downloader path is set with env", func() {
+			lo.Must0(os.Setenv(EnvName(key.DownloaderPath), "~/manga"))
+			defer func() {
+				_ = os.Unsetenv(EnvName(key.DownloaderPath))
+			}()
+
+			lo.Must0(Setup())
+
+			Convey("Then aliases should be resolved", func() {
+				So(viper.GetString(key.DownloaderPath), ShouldEqual, filepath.Join(lo.Must(os.UserHomeDir()), "manga"))
+			})
+		})
+	})
+}
+
+func TestWriteEnvDocs(t *testing.T) {
+	Convey("When writing env docs", t, func() {
+		var buf bytes.Buffer
+		So(WriteEnvDocs(&buf), ShouldBeNil)
+
+		Convey("Then every key should be documented", func() {
+			for _, field := range defaults {
+				So(buf.String(), ShouldContainSubstring, "`"+EnvName(field.Key)+"`")
+			}
+		})
+	})
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/samber/lo"
+	"io"
+	"strings"
+)
+
+// Allowed are the values of the fields that take one of the options, by their keys.
+// The options defined by other packages, e.g. the languages of the sources, are added with Allow
+var Allowed = map[string][]string{
+	key.DownloaderChapterTitle: {"full", "none", "number"},
+	key.NetworkDoH:             {"", "cloudflare", "google", "quad9"},
+	key.NetworkIPVersion:       {"any", "ipv4", "ipv6", "prefer_ipv4", "prefer_ipv6"},
+	key.FormatsUse: {
+		constant.FormatPDF,
+		constant.FormatZIP,
+		constant.FormatCBZ,
+		constant.FormatEPUB,
+		constant.FormatPlain,
+	},
+	key.IconsVariant:    icon.AvailableVariants(),
+	key.CliAppearance:   color.Appearances(),
+	key.I18nLocale:      append([]string{""}, i18n.Available()...),
+	key.ServerAuth:      {"none", "basic", "header"},
+	key.ServerLibrary:   {"shared", "personal"},
+	key.LogsLevel:       {"panic", "fatal", "error", "warn", "info", "debug", "trace"},
+	key.TUISearchFilter: {"fuzzy", "subsequence", "substring"},
+}
+
+// Allow sets the values that the field takes, for the fields whose options are defined outside the config
+func Allow(name string, values []string) {
+	Allowed[name] = values
+}
+
+// extensible are the fields that take other values than the allowed ones,
+// e.g. the URL of a DoH endpoint or the custom converters
+var extensible = []string{
+	key.NetworkDoH,
+	key.FormatsUse,
+}
+
+// Schema of the config field, for the external tools and docs
+type Schema struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Default     any      `json:"default"`
+	Description string   `json:"description"`
+	Values      []string `json:"values,omitempty"`
+	Extensible  bool     `json:"extensible"`
+	Env         string   `json:"env"`
+}
+
+// Schemas returns the schemas of the config fields in the order they are defined
+func Schemas() []*Schema {
+	schemas := make([]*Schema, len(defaults))
+	for i, field := range defaults {
+		schemas[i] = &Schema{
+			Key:         field.Key,
+			Type:        field.typeName(),
+			Default:     field.Value,
+			Description: strings.TrimSpace(field.Description),
+			Values:      Allowed[field.Key],
+			Extensible:  lo.Contains(extensible, field.Key),
+			Env:         EnvName(field.Key),
+		}
+	}
+
+	return schemas
+}
+
+// WriteSchema writes the schemas of the config fields as JSON
+func WriteSchema(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Schemas())
+}
+
+// WriteSchemaDocs writes markdown documentation of the config fields generated from their schemas
+func WriteSchemaDocs(w io.Writer) error {
+	lines := []string{
+		"| Key | Type | Default | Values | Description |",
+		"|-----|------|---------|--------|-------------|",
+	}
+
+	for _, schema := range Schemas() {
+		values := lo.Map(lo.Without(schema.Values, ""), func(value string, _ int) string {
+			return "`" + value + "`"
+		})
+
+		if schema.Extensible {
+			values = append(values, "...")
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"| `%s` | %s | `%v` | %s | %s |",
+			schema.Key,
+			schema.Type,
+			envValue(schema.Default),
+			strings.Join(values, ", "),
+			markdownCell.Replace(schema.Description),
+		))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
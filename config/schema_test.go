@@ -0,0 +1,44 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/metafates/mangal/key"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSchemas(t *testing.T) {
+	Convey("Given the allowed values", t, func() {
+		Convey("Then they should belong to the defined string fields", func() {
+			for name, values := range Allowed {
+				field, ok := Default[name]
+				So(ok, ShouldBeTrue)
+				So(field.typeName(), ShouldEqual, "string")
+				So(values, ShouldContain, field.Value)
+			}
+		})
+	})
+
+	Convey("When writing the schema", t, func() {
+		var buf bytes.Buffer
+		So(WriteSchema(&buf), ShouldBeNil)
+
+		var schemas []*Schema
+		So(json.Unmarshal(buf.Bytes(), &schemas), ShouldBeNil)
+
+		Convey("Then every key should be described", func() {
+			So(schemas, ShouldHaveLength, key.DefinedFieldsCount)
+
+			schema, ok := lo.Find(schemas, func(schema *Schema) bool {
+				return schema.Key == key.LogsLevel
+			})
+			So(ok, ShouldBeTrue)
+			So(schema.Type, ShouldEqual, "string")
+			So(schema.Default, ShouldEqual, "info")
+			So(schema.Values, ShouldContain, "debug")
+			So(schema.Env, ShouldEqual, "MANGAL_LOGS_LEVEL")
+		})
+	})
+}
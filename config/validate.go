@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// validators check the values that have the right type but can't be used
+var validators = map[string]func(value any) error{
+	key.FormatsPageNameTemplate: validatePageNameTemplate,
+}
+
+// Validate checks the value of the key, the keys without validators accept any value
+func Validate(name string, value any) error {
+	validator, ok := validators[name]
+	if !ok {
+		return nil
+	}
+
+	return validator(value)
+}
+
+// validate checks the values in use
+func validate() error {
+	for name, validator := range validators {
+		if err := validator(viper.Get(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePageNameTemplate checks that the pages get distinct names, otherwise they would overwrite each other
+func validatePageNameTemplate(value any) error {
+	template := fmt.Sprint(value)
+	if template == "" || strings.Contains(template, "{index}") || strings.Contains(template, "{padded-index}") {
+		return nil
+	}
+
+	return fmt.Errorf("%s must contain {index} or {padded-index}, pages would have the same names: %q", key.FormatsPageNameTemplate, template)
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	Convey("Given page name templates", t, func() {
+		Convey("Then the ones with the page index should be valid", func() {
+			So(Validate(key.FormatsPageNameTemplate, "{padded-chapter-index}-{padded-index}"), ShouldBeNil)
+			So(Validate(key.FormatsPageNameTemplate, "{manga} {index}"), ShouldBeNil)
+		})
+
+		Convey("And the empty one should be valid, since it falls back to the index", func() {
+			So(Validate(key.FormatsPageNameTemplate, ""), ShouldBeNil)
+		})
+
+		Convey("And the ones without it should be rejected", func() {
+			So(Validate(key.FormatsPageNameTemplate, "{chapter}"), ShouldNotBeNil)
+			So(Validate(key.FormatsPageNameTemplate, "{chapter-index}"), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a key without a validator", t, func() {
+		Convey("Then any value should be valid", func() {
+			So(Validate(key.LogsLevel, "anything"), ShouldBeNil)
+		})
+	})
+}
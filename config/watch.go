@@ -0,0 +1,166 @@
+package config
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Reloadable are the keys that are read whenever they are used, so their changes are applied without a restart.
+// Changes of every other key are reported as requiring a restart
+var Reloadable = []string{
+	// rate limits
+	key.LibraryUpdateRate,
+	key.LibraryUpdateConcurrency,
+
+	// formats are read per chapter
+	key.FormatsUse,
+	key.FormatsRules,
+	key.FormatsFallback,
+	key.FormatsSkipUnsupportedImages,
+	key.FormatsRawPassthrough,
+	key.FormatsEncrypt,
+	key.FormatsVolumeBundles,
+	key.FormatsPageNameTemplate,
+	key.FormatsPagePadding,
+	key.FormatsNormalizeExtensions,
+	key.FormatsEPUBRightToLeft,
+	key.FormatsEPUBCover,
+
+	// notification targets
+	key.LibraryUpdateWebhook,
+	key.HistoryDigestWebhook,
+}
+
+// read reads the config file into a new viper, so that the one in use is never written concurrently
+func read(path string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetFs(filesystem.Api())
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+	v.SetTypeByDefaultValue(true)
+
+	for name, field := range Default {
+		v.SetDefault(name, field.Value)
+	}
+
+	return v, v.ReadInConfig()
+}
+
+// snapshot returns the values of all the known keys
+func snapshot(v *viper.Viper) map[string]any {
+	values := make(map[string]any, len(Default))
+	for name := range Default {
+		values[name] = v.Get(name)
+	}
+
+	return values
+}
+
+// diff returns the changed keys split into the reloadable ones and the ones that require a restart, see Reloadable
+func diff(before, after map[string]any) (reloaded, restart []string) {
+	for name, value := range after {
+		if reflect.DeepEqual(before[name], value) {
+			continue
+		}
+
+		if lo.Contains(Reloadable, name) {
+			reloaded = append(reloaded, name)
+		} else {
+			restart = append(restart, name)
+		}
+	}
+
+	sort.Strings(reloaded)
+	sort.Strings(restart)
+	return
+}
+
+// apply sets the reloaded keys in the config in use and returns the ones that were applied.
+// Invalid values are kept out and logged, see Validate
+func apply(reloaded []string, values map[string]any) (applied []string) {
+	for _, name := range reloaded {
+		if err := Validate(name, values[name]); err != nil {
+			log.Warnf("config change of %s is not applied: %s", name, err)
+			continue
+		}
+
+		viper.Set(name, values[name])
+		applied = append(applied, name)
+	}
+
+	return
+}
+
+// Watch applies the changes of the config file to the reloadable keys and reports the others, see Reloadable.
+// The config file is read into a separate viper, only the changed reloadable keys are set in the one in use.
+// onChange is called with the applied keys and the ones that require a restart, it may be nil
+func Watch(onChange func(reloaded, restart []string)) {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return
+	}
+
+	v, err := read(path)
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+
+	values := snapshot(v)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+
+	// editors may replace the file instead of writing it, so its directory is watched
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		log.Warn(err)
+		_ = watcher.Close()
+		return
+	}
+
+	go func() {
+		for event := range watcher.Events {
+			if filepath.Clean(event.Name) != filepath.Clean(path) || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			v, err := read(path)
+			if err != nil {
+				log.Warn(err)
+				continue
+			}
+
+			current := snapshot(v)
+			reloaded, restart := diff(values, current)
+			values = current
+
+			if len(reloaded) == 0 && len(restart) == 0 {
+				continue
+			}
+
+			reloaded = apply(reloaded, current)
+			if len(reloaded) > 0 {
+				log.Infof("config reloaded %s", strings.Join(reloaded, ", "))
+			}
+
+			if len(restart) > 0 {
+				log.Warnf("config changed, restart is required to apply %s", strings.Join(restart, ", "))
+			}
+
+			if onChange != nil {
+				onChange(reloaded, restart)
+			}
+		}
+	}()
+}
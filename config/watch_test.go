@@ -0,0 +1,83 @@
+package config
+
+import (
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	Convey("Given config values before and after the change", t, func() {
+		before := map[string]any{
+			key.FormatsUse:     "pdf",
+			key.LogsLevel:      "info",
+			key.IconsVariant:   "plain",
+			key.DownloaderPath: ".",
+		}
+
+		after := map[string]any{
+			key.FormatsUse:     "cbz",
+			key.LogsLevel:      "debug",
+			key.IconsVariant:   "plain",
+			key.DownloaderPath: ".",
+		}
+
+		Convey("When comparing them", func() {
+			reloaded, restart := diff(before, after)
+
+			Convey("Then only the changed keys should be reported", func() {
+				So(reloaded, ShouldResemble, []string{key.FormatsUse})
+				So(restart, ShouldResemble, []string{key.LogsLevel})
+			})
+		})
+	})
+}
+
+func TestApply(t *testing.T) {
+	Convey("Given the changed reloadable keys", t, func() {
+		defer viper.Set(key.FormatsUse, Default[key.FormatsUse].Value)
+		defer viper.Set(key.FormatsPageNameTemplate, Default[key.FormatsPageNameTemplate].Value)
+
+		values := map[string]any{
+			key.FormatsUse:              "zip",
+			key.FormatsPageNameTemplate: "page",
+		}
+
+		Convey("When applying them", func() {
+			applied := apply([]string{key.FormatsPageNameTemplate, key.FormatsUse}, values)
+
+			Convey("Then the valid ones should be set in the config in use", func() {
+				So(applied, ShouldResemble, []string{key.FormatsUse})
+				So(viper.GetString(key.FormatsUse), ShouldEqual, "zip")
+			})
+
+			Convey("And the invalid ones should be kept out", func() {
+				So(viper.Get(key.FormatsPageNameTemplate), ShouldEqual, Default[key.FormatsPageNameTemplate].Value)
+			})
+		})
+	})
+}
+
+func TestRead(t *testing.T) {
+	Convey("Given a config file", t, func() {
+		path := "/watch/mangal.toml"
+		lo.Must0(filesystem.Api().MkdirAll("/watch", 0755))
+		lo.Must0(filesystem.Api().WriteFile(path, []byte("[logs]\nlevel = \"debug\"\n"), 0644))
+
+		Convey("When reading it", func() {
+			v, err := read(path)
+			So(err, ShouldBeNil)
+
+			Convey("Then its values should be read", func() {
+				So(v.GetString(key.LogsLevel), ShouldEqual, "debug")
+			})
+
+			Convey("And the missing keys should have defaults", func() {
+				So(v.Get(key.FormatsUse), ShouldEqual, Default[key.FormatsUse].Value)
+			})
+		})
+	})
+}
@@ -0,0 +1,4 @@
+package constant
+
+// LanguageRaw marks untranslated (raw) manga, usually in Japanese
+const LanguageRaw = "raw"
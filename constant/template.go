@@ -6,17 +6,22 @@ const (
 	ChapterPagesFn  = "ChapterPages"
 )
 
+// ConvertChapterFn is the function of the Lua converters
+const ConvertChapterFn = "ConvertChapter"
+
 const SourceTemplate = `{{ $divider := repeat "-" (plus (max (len .URL) (len .Name) (len .Author) 3) 12) }}{{ $divider }}
 -- @name    {{ .Name }} 
 -- @url     {{ .URL }}
 -- @author  {{ .Author }} 
 -- @license MIT
+-- @api     2
 {{ $divider }}
 
 
----@alias manga { name: string, url: string, author: string|nil, genres: string|nil, summary: string|nil }
----@alias chapter { name: string, url: string, volume: string|nil, manga_summary: string|nil, manga_author: string|nil, manga_genres: string|nil }
----@alias page { url: string, index: number }
+---@alias manga { name: string, url: string, id: string|nil, author: string|nil, artist: string|nil, title: string|nil, native_title: string|nil, alt_titles: string|nil, genres: string|nil, tags: string|nil, status: "finished"|"releasing"|"not_yet_released"|"cancelled"|"hiatus"|nil, summary: string|nil, cover: string|nil }
+---@alias chapter { name: string, url: string, id: string|nil, volume: string|nil, title: string|nil, notes: string|nil, manga_summary: string|nil, manga_genres: string|nil, manga_cover: string|nil }
+---@alias page { url: string, index: number, extension: string|nil }
+---@alias text { text: string, format: "html"|"markdown"|nil }
 
 
 ----- IMPORTS -----
@@ -41,17 +46,18 @@ end
 
 
 --- Gets the list of all manga chapters.
--- @param mangaURL string URL of the manga
+-- @param manga manga Manga from the search results
 -- @return chapter[] Table of chapters
-function {{ .MangaChaptersFn }}(mangaURL)
+function {{ .MangaChaptersFn }}(manga)
 	return {}
 end
 
 
 --- Gets the list of all pages of a chapter.
--- @param chapterURL string URL of the chapter
+-- @param chapter chapter Chapter of the manga, with the manga in chapter.manga
+-- Text sources (e.g. light novels) should return text[] instead
 -- @return page[]
-function {{ .ChapterPagesFn }}(chapterURL)
+function {{ .ChapterPagesFn }}(chapter)
 	return {}
 end
 
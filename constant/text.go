@@ -0,0 +1,7 @@
+package constant
+
+// Markup of the text chapters contents
+const (
+	TextHTML     = "html"
+	TextMarkdown = "markdown"
+)
@@ -0,0 +1,113 @@
+package cbz
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/metafates/mangal/converter/zipcrypt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"path"
+)
+
+// Bundle writes the cbz of the volume to the given path from the cbz archives of its chapters, in the given order.
+// Files of the archives are copied as they are stored, even encrypted, so the pages are not extracted to temp copies.
+// Pages are prefixed with the position of their chapter, since every chapter names them the same.
+// ComicInfo.xml of the chapters are replaced with the given one, it is left out if nil
+func Bundle(to string, archives []string, comicInfo *source.ComicInfo) error {
+	partial := to + ".part"
+	if err := bundle(partial, archives, comicInfo); err != nil {
+		_ = filesystem.Api().Remove(partial)
+		return err
+	}
+
+	return filesystem.Api().Rename(partial, to)
+}
+
+func bundle(to string, archives []string, comicInfo *source.ComicInfo) error {
+	file, err := filesystem.Api().Create(to)
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(file.Close)
+
+	writer := zip.NewWriter(file)
+
+	var pages int
+	for i, archive := range archives {
+		copied, err := copyArchive(writer, archive, fmt.Sprintf("%04d", i+1))
+		if err != nil {
+			return err
+		}
+
+		pages += copied
+	}
+
+	if comicInfo != nil {
+		if err = addComicInfo(writer, comicInfo, pages); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// copyArchive copies the pages of the archive to the writer with the prefix and returns how many of them were copied
+func copyArchive(writer *zip.Writer, archive, prefix string) (int, error) {
+	file, err := filesystem.Api().Open(archive)
+	if err != nil {
+		return 0, err
+	}
+
+	defer util.Ignore(file.Close)
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	reader, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", archive, err)
+	}
+
+	var copied int
+	for _, page := range reader.File {
+		if page.FileInfo().IsDir() || path.Base(page.Name) == "ComicInfo.xml" {
+			continue
+		}
+
+		// Copy writes the header of the file, so the renamed copy of it is passed
+		renamed := *page
+		renamed.Name = prefix + "-" + path.Base(page.Name)
+		if err = writer.Copy(&renamed); err != nil {
+			return 0, err
+		}
+
+		copied++
+	}
+
+	return copied, nil
+}
+
+func addComicInfo(writer *zip.Writer, comicInfo *source.ComicInfo, pages int) error {
+	info := *comicInfo
+	info.PageCount = pages
+
+	marshalled, err := xml.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	password, err := zipcrypt.Password()
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewBufferString(xml.Header)
+	buf.Write(marshalled)
+	return addToZip(writer, buf, "ComicInfo.xml", password)
+}
@@ -0,0 +1,72 @@
+package cbz
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	cbz := New()
+
+	Convey("Given the cbz archives of two chapters", t, func() {
+		first, second := SampleChapter(t), SampleChapter(t)
+		second.Name, second.Index = "another chapter name", first.Index+1
+
+		archives := []string{lo.Must(cbz.Save(first)), lo.Must(cbz.Save(second))}
+		to := filepath.Join(filepath.Dir(archives[0]), "volume.cbz")
+
+		Convey("When bundling them", func() {
+			err := Bundle(to, archives, &source.ComicInfo{Title: "Volume 1"})
+			So(err, ShouldBeNil)
+
+			file := lo.Must(filesystem.Api().Open(to))
+			reader := lo.Must(zip.NewReader(file, lo.Must(file.Stat()).Size()))
+
+			Convey("Then the pages of both chapters should be copied with their prefixes", func() {
+				pages := lo.Filter(reader.File, func(f *zip.File, _ int) bool {
+					return f.Name != "ComicInfo.xml"
+				})
+
+				So(len(pages), ShouldEqual, len(first.Pages)+len(second.Pages))
+				So(pages[0].Name, ShouldStartWith, "0001-")
+				So(pages[len(pages)-1].Name, ShouldStartWith, "0002-")
+			})
+
+			Convey("And ComicInfo.xml of the chapters should be replaced with the given one", func() {
+				infos := lo.Filter(reader.File, func(f *zip.File, _ int) bool {
+					return strings.HasSuffix(f.Name, "ComicInfo.xml")
+				})
+				So(len(infos), ShouldEqual, 1)
+
+				var info source.ComicInfo
+				So(xml.Unmarshal(lo.Must(io.ReadAll(lo.Must(infos[0].Open()))), &info), ShouldBeNil)
+				So(info.Title, ShouldEqual, "Volume 1")
+				So(info.PageCount, ShouldEqual, len(first.Pages)+len(second.Pages))
+			})
+
+			Convey("And no partial file should be left", func() {
+				exists := lo.Must(filesystem.Api().Exists(to + ".part"))
+				So(exists, ShouldBeFalse)
+			})
+		})
+
+		Convey("When one of the archives is missing", func() {
+			to := filepath.Join(filepath.Dir(archives[0]), "incomplete volume.cbz")
+			err := Bundle(to, append(archives, "missing.cbz"), nil)
+
+			Convey("Then the error should not be nil and nothing should be written", func() {
+				So(err, ShouldNotBeNil)
+				So(lo.Must(filesystem.Api().Exists(to)), ShouldBeFalse)
+				So(lo.Must(filesystem.Api().Exists(to+".part")), ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -4,8 +4,12 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/xml"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter/content"
+	"github.com/metafates/mangal/converter/zipcrypt"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
 	"github.com/spf13/viper"
@@ -41,6 +45,19 @@ func save(chapter *source.Chapter, temp bool) (path string, err error) {
 }
 
 func SaveTo(chapter *source.Chapter, to string) error {
+	if content.IsTextOnly(chapter) {
+		return content.ErrTextOnly(chapter, constant.FormatCBZ)
+	}
+
+	if chapter.IsText() {
+		log.Warnf("chapter %s has text which is not supported by cbz, saving images only", chapter.Name)
+	}
+
+	password, err := zipcrypt.Password()
+	if err != nil {
+		return err
+	}
+
 	cbzFile, err := filesystem.Api().Create(to)
 	if err != nil {
 		return err
@@ -52,7 +69,7 @@ func SaveTo(chapter *source.Chapter, to string) error {
 	defer util.Ignore(zipWriter.Close)
 
 	for _, page := range chapter.Pages {
-		if err = addToZip(zipWriter, page.Contents, page.Filename()); err != nil {
+		if err = addToZip(zipWriter, page.Contents, page.Filename(), password); err != nil {
 			return err
 		}
 	}
@@ -61,20 +78,25 @@ func SaveTo(chapter *source.Chapter, to string) error {
 		comicInfo := chapter.ComicInfo()
 		marshalled, err := xml.MarshalIndent(comicInfo, "", "  ")
 		if err == nil {
-			buf := bytes.NewBuffer(marshalled)
-			err = addToZip(zipWriter, buf, "ComicInfo.xml")
+			buf := bytes.NewBufferString(xml.Header)
+			buf.Write(marshalled)
+			err = addToZip(zipWriter, buf, "ComicInfo.xml", password)
 		}
 	}
 
 	return err
 }
 
-func addToZip(writer *zip.Writer, file io.Reader, name string) error {
+func addToZip(writer *zip.Writer, file io.Reader, name, password string) error {
 	header := &zip.FileHeader{
 		Name:   name,
 		Method: zip.Store,
 	}
 
+	if password != "" {
+		return zipcrypt.Add(writer, header, file, password)
+	}
+
 	headerWriter, err := writer.CreateHeader(header)
 	if err != nil {
 		return err
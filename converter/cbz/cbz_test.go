@@ -3,15 +3,20 @@ package cbz
 import (
 	"archive/zip"
 	"bytes"
+	"encoding/xml"
 	"github.com/metafates/mangal/config"
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter/zipcrypt"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/secrets"
 	"github.com/metafates/mangal/source"
 	"github.com/samber/lo"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/spf13/viper"
+	"io"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -52,6 +57,20 @@ func TestCBZ(t *testing.T) {
 							So(ok, ShouldBeTrue)
 						})
 
+						Convey("And ComicInfo.xml should be the xml document of the chapter metadata", func() {
+							f, _ := lo.Find(zipReader.File, func(f *zip.File) bool {
+								return f.Name == "ComicInfo.xml"
+							})
+
+							contents := lo.Must(io.ReadAll(lo.Must(f.Open())))
+							So(string(contents), ShouldStartWith, xml.Header)
+
+							var info source.ComicInfo
+							So(xml.Unmarshal(contents, &info), ShouldBeNil)
+							So(info.Series, ShouldEqual, chapter.Manga.Title())
+							So(info.Number, ShouldEqual, chapter.Index)
+						})
+
 						Convey("And the number of files should be equal to the number of pages + 1", func() {
 							So(len(zipReader.File), ShouldEqual, len(chapter.Pages)+1)
 						})
@@ -59,6 +78,51 @@ func TestCBZ(t *testing.T) {
 				})
 			})
 		})
+
+		Convey("When saving a chapter with encryption enabled", func() {
+			viper.Set(key.FormatsEncrypt, true)
+			lo.Must0(os.Setenv(secrets.EnvPrefix+"ARCHIVE_PASSWORD", "hunter2"))
+			defer func() {
+				viper.Set(key.FormatsEncrypt, false)
+				_ = os.Unsetenv(secrets.EnvPrefix + "ARCHIVE_PASSWORD")
+			}()
+
+			chapter := SampleChapter(t)
+			result, err := cbz.Save(chapter)
+			So(err, ShouldBeNil)
+
+			Convey("Then every file should be encrypted", func() {
+				file := lo.Must(filesystem.Api().Open(result))
+				zipReader := lo.Must(zip.NewReader(file, lo.Must(file.Stat()).Size()))
+
+				So(len(zipReader.File), ShouldEqual, len(chapter.Pages)+1)
+				for _, f := range zipReader.File {
+					So(zipcrypt.IsEncrypted(f), ShouldBeTrue)
+				}
+
+				Convey("And it should be readable with the password", func() {
+					comicInfo, ok := lo.Find(zipReader.File, func(f *zip.File) bool {
+						return f.Name == "ComicInfo.xml"
+					})
+					So(ok, ShouldBeTrue)
+
+					contents, err := zipcrypt.ReadFile(comicInfo, "hunter2")
+					So(err, ShouldBeNil)
+					So(string(contents), ShouldContainSubstring, chapter.Name)
+				})
+			})
+		})
+
+		Convey("When saving a text chapter", func() {
+			chapter := SampleChapter(t)
+			chapter.Pages = nil
+			chapter.Text = "<p>text</p>"
+			_, err := cbz.Save(chapter)
+			Convey("Then the error should not be nil", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "contains only text")
+			})
+		})
 	})
 
 	_ = cbz
@@ -0,0 +1,85 @@
+package content
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/source"
+)
+
+// Kind of the chapter content
+type Kind int
+
+const (
+	// Image is a single page image
+	Image Kind = iota
+	// Text is a block of html or markdown text
+	Text
+)
+
+// Content is a single piece of the chapter, either an image page or a text block.
+// Converters operate on contents instead of pages, so that text and mixed chapters
+// (e.g. light novels with illustrations) can be handled the same way as manga.
+type Content struct {
+	Kind Kind
+	// Page is set for the Image contents
+	Page *source.Page
+	// Text and Format are set for the Text contents
+	Text, Format string
+}
+
+// Of returns contents of the chapter in the reading order.
+// Illustrations of the mixed chapters come before the text.
+func Of(chapter *source.Chapter) []*Content {
+	contents := make([]*Content, 0, len(chapter.Pages)+1)
+
+	for _, page := range chapter.Pages {
+		contents = append(contents, &Content{Kind: Image, Page: page})
+	}
+
+	if chapter.IsText() {
+		contents = append(contents, &Content{Kind: Text, Text: chapter.Text, Format: chapter.TextFormat})
+	}
+
+	return contents
+}
+
+// IsTextOnly reports whether the chapter has no images at all.
+// Such chapters can not be saved to the image-only formats.
+func IsTextOnly(chapter *source.Chapter) bool {
+	return chapter.IsText() && len(chapter.Pages) == 0
+}
+
+// ErrTextOnly returns an error for the formats that can not store text chapters
+func ErrTextOnly(chapter *source.Chapter, format string) error {
+	return fmt.Errorf(
+		`chapter "%s" contains only text and can not be saved as %s, use %s or %s instead`,
+		chapter.Name,
+		format,
+		constant.FormatEPUB,
+		constant.FormatPDF,
+	)
+}
+
+// XHTML returns the text as a well-formed xhtml markup
+func (c *Content) XHTML() (string, error) {
+	switch c.Format {
+	case constant.TextMarkdown:
+		return markdownToXHTML(c.Text), nil
+	case constant.TextHTML, "":
+		return htmlToXHTML(c.Text)
+	default:
+		return "", fmt.Errorf("unknown text format %q", c.Format)
+	}
+}
+
+// Paragraphs returns the text as a list of plain text paragraphs without any markup
+func (c *Content) Paragraphs() ([]string, error) {
+	switch c.Format {
+	case constant.TextMarkdown:
+		return markdownParagraphs(c.Text), nil
+	case constant.TextHTML, "":
+		return htmlParagraphs(c.Text)
+	default:
+		return nil, fmt.Errorf("unknown text format %q", c.Format)
+	}
+}
@@ -0,0 +1,68 @@
+package content
+
+import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	Convey("Given a chapter with pages and text", t, func() {
+		chapter := &source.Chapter{
+			Pages: []*source.Page{{Index: 0}, {Index: 1}},
+			Text:  "text",
+		}
+
+		Convey("When getting its contents", func() {
+			contents := Of(chapter)
+			Convey("Then images should come before the text", func() {
+				So(len(contents), ShouldEqual, 3)
+				So(contents[0].Kind, ShouldEqual, Image)
+				So(contents[1].Kind, ShouldEqual, Image)
+				So(contents[2].Kind, ShouldEqual, Text)
+			})
+
+			Convey("And the chapter should not be text only", func() {
+				So(IsTextOnly(chapter), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestHTMLToXHTML(t *testing.T) {
+	Convey("Given a malformed html", t, func() {
+		text := `<p>one<br>two<p>three<script>alert(1)</script>`
+		Convey("When converting it to xhtml", func() {
+			result, err := htmlToXHTML(text)
+			Convey("Then tags should be closed and scripts removed", func() {
+				So(err, ShouldBeNil)
+				So(result, ShouldEqual, `<p>one<br/>two</p><p>three</p>`)
+			})
+		})
+	})
+}
+
+func TestParagraphs(t *testing.T) {
+	Convey("Given a html text", t, func() {
+		c := &Content{Kind: Text, Format: constant.TextHTML, Text: `<h1>Title</h1><p>one <b>bold</b></p><div>two<br>three</div>`}
+		Convey("When getting paragraphs", func() {
+			paragraphs, err := c.Paragraphs()
+			Convey("Then each block should be a separate paragraph", func() {
+				So(err, ShouldBeNil)
+				So(paragraphs, ShouldResemble, []string{"Title", "one bold", "two", "three"})
+			})
+		})
+	})
+
+	Convey("Given a markdown text", t, func() {
+		c := &Content{Kind: Text, Format: constant.TextMarkdown, Text: "# Title\n\nsome *italic*\ntext\n\n---\n\n**end**"}
+		Convey("When getting paragraphs", func() {
+			paragraphs, err := c.Paragraphs()
+			Convey("Then markup should be removed", func() {
+				So(err, ShouldBeNil)
+				So(paragraphs, ShouldResemble, []string{"Title", "some italic text", "end"})
+			})
+		})
+	})
+}
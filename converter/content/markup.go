@@ -0,0 +1,202 @@
+package content
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	stdhtml "html"
+	"regexp"
+	"strings"
+)
+
+func parseFragment(text string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(text), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+}
+
+// htmlToXHTML parses (possibly malformed) html fragment and renders it back as a well-formed markup
+func htmlToXHTML(text string) (string, error) {
+	nodes, err := parseFragment(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, node := range nodes {
+		// scripts are not allowed in the epub without declaring them
+		if node.DataAtom == atom.Script {
+			continue
+		}
+
+		removeScripts(node)
+		if err = html.Render(&sb, node); err != nil {
+			return "", err
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func removeScripts(node *html.Node) {
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.DataAtom == atom.Script {
+			node.RemoveChild(child)
+		} else {
+			removeScripts(child)
+		}
+
+		child = next
+	}
+}
+
+// blockElements start a new paragraph
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Hr: true, atom.Li: true, atom.Blockquote: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// htmlParagraphs extracts plain text paragraphs from the html fragment
+func htmlParagraphs(text string) ([]string, error) {
+	nodes, err := parseFragment(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		paragraphs []string
+		current    strings.Builder
+	)
+
+	flush := func() {
+		if p := strings.Join(strings.Fields(current.String()), " "); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+
+		current.Reset()
+	}
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch {
+		case node.Type == html.TextNode:
+			current.WriteString(node.Data)
+		case node.DataAtom == atom.Script || node.DataAtom == atom.Style:
+			return
+		case blockElements[node.DataAtom]:
+			flush()
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+			flush()
+		default:
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		walk(node)
+	}
+
+	flush()
+	return paragraphs, nil
+}
+
+var (
+	headingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	ruleRegex    = regexp.MustCompile(`^(\*{3,}|-{3,}|_{3,})$`)
+	strongRegex  = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	emRegex      = regexp.MustCompile(`\*(.+?)\*|_(.+?)_`)
+)
+
+// markdownToXHTML converts a subset of markdown commonly used by novel sources:
+// headings, paragraphs, horizontal rules, bold and italic text
+func markdownToXHTML(text string) string {
+	var (
+		sb        strings.Builder
+		paragraph []string
+	)
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+
+		sb.WriteString("<p>")
+		sb.WriteString(inlineMarkdown(strings.Join(paragraph, " ")))
+		sb.WriteString("</p>\n")
+		paragraph = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			flush()
+		case ruleRegex.MatchString(line):
+			flush()
+			sb.WriteString("<hr/>\n")
+		case headingRegex.MatchString(line):
+			flush()
+			groups := headingRegex.FindStringSubmatch(line)
+			level := string(rune('0' + len(groups[1])))
+			sb.WriteString("<h" + level + ">" + inlineMarkdown(groups[2]) + "</h" + level + ">\n")
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+
+	flush()
+	return sb.String()
+}
+
+func inlineMarkdown(text string) string {
+	text = stdhtml.EscapeString(text)
+	text = strongRegex.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emRegex.ReplaceAllString(text, "<em>$1$2</em>")
+	return text
+}
+
+// markdownParagraphs extracts plain text paragraphs from the markdown
+func markdownParagraphs(text string) []string {
+	var (
+		paragraphs []string
+		paragraph  []string
+	)
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			paragraphs = append(paragraphs, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "", ruleRegex.MatchString(line):
+			flush()
+		case headingRegex.MatchString(line):
+			flush()
+			paragraph = append(paragraph, headingRegex.FindStringSubmatch(line)[2])
+			flush()
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+
+	flush()
+
+	for i, p := range paragraphs {
+		p = strongRegex.ReplaceAllString(p, "$1$2")
+		paragraphs[i] = emRegex.ReplaceAllString(p, "$1$2")
+	}
+
+	return paragraphs
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/converter/cbz"
+	"github.com/metafates/mangal/converter/custom"
+	"github.com/metafates/mangal/converter/epub"
 	"github.com/metafates/mangal/converter/pdf"
 	"github.com/metafates/mangal/converter/plain"
 	"github.com/metafates/mangal/converter/zip"
+	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/source"
 	"github.com/samber/lo"
 	"strings"
+	"sync"
 )
 
 // Converter is the interface that all converters must implement.
@@ -23,19 +27,71 @@ var converters = map[string]Converter{
 	constant.FormatCBZ:   cbz.New(),
 	constant.FormatPDF:   pdf.New(),
 	constant.FormatZIP:   zip.New(),
+	constant.FormatEPUB:  epub.New(),
 }
 
-// Available returns a list of available converters.
+// customs are the loaded Lua converters by their names
+var customs = struct {
+	mutex      sync.Mutex
+	converters map[string]Converter
+}{
+	converters: make(map[string]Converter),
+}
+
+// Available returns a list of available converters, including the Lua ones.
 func Available() []string {
-	return lo.Keys(converters)
+	return lo.Uniq(append(lo.Keys(converters), custom.Available()...))
 }
 
 // Get returns a converter by name.
+// Lua converters are looked up in the converters directory if there is no built-in one with the name.
 // If the converter is not available, an error is returned.
 func Get(name string) (Converter, error) {
 	if converter, ok := converters[name]; ok {
 		return converter, nil
 	}
 
+	customs.mutex.Lock()
+	defer customs.mutex.Unlock()
+
+	if converter, ok := customs.converters[name]; ok {
+		return converter, nil
+	}
+
+	// names of the formats come from the config, so they must not point outside the converters directory
+	if strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("unkown format \"%s\", available options are %s", name, strings.Join(Available(), ", "))
+	}
+
+	if exists, _ := filesystem.Api().Exists(custom.Path(name)); exists {
+		converter, err := custom.Load(custom.Path(name))
+		if err != nil {
+			return nil, fmt.Errorf("loading %s converter: %w", name, err)
+		}
+
+		customs.converters[name] = converter
+		return converter, nil
+	}
+
 	return nil, fmt.Errorf("unkown format \"%s\", available options are %s", name, strings.Join(Available(), ", "))
 }
+
+// GetAll returns the converters of the given formats, see source.Formats.
+// If any of them is not available or there are no formats, an error is returned.
+func GetAll(names []string) ([]Converter, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no format given, available options are %s", strings.Join(Available(), ", "))
+	}
+
+	converters := make([]Converter, len(names))
+	for i, name := range names {
+		converter, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		converters[i] = converter
+	}
+
+	return converters, nil
+}
@@ -22,12 +22,36 @@ func TestGet(t *testing.T) {
 	})
 }
 
+func TestGetAll(t *testing.T) {
+	Convey("When trying to get several valid converters", t, func() {
+		converters, err := GetAll([]string{constant.FormatCBZ, constant.FormatPDF})
+		Convey("Then all of them should be returned", func() {
+			So(err, ShouldBeNil)
+			So(converters, ShouldHaveLength, 2)
+		})
+	})
+
+	Convey("When one of the converters is invalid", t, func() {
+		_, err := GetAll([]string{constant.FormatCBZ, "kek"})
+		Convey("Then an error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("When no converters are given", t, func() {
+		_, err := GetAll(nil)
+		Convey("Then an error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
 func TestAvailable(t *testing.T) {
 	Convey("When getting the available converters", t, func() {
 		converters := Available()
 		Convey("Then the available converters should be returned", func() {
 			So(converters, ShouldNotBeNil)
-			So(len(converters), ShouldEqual, 4)
+			So(len(converters), ShouldEqual, 5)
 		})
 	})
 }
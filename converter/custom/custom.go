@@ -0,0 +1,194 @@
+// Package custom runs the converters written in Lua.
+// The script defines the ConvertChapter(pages, chapter, output) function, where pages are the paths
+// of the page images in the reading order, chapter is the table with the metadata and output is the suggested path.
+// It must write the converted chapter and return its path.
+package custom
+
+import (
+	"fmt"
+	libs "github.com/metafates/mangal-lua-libs"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
+	luaprovider "github.com/metafates/mangal/provider/custom"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	lua "github.com/yuin/gopher-lua"
+	"path/filepath"
+	"sync"
+)
+
+// Extension of the Lua converters
+const Extension = ".lua"
+
+// Converter converts chapters with the Lua script.
+// The name of the script is the name of the format
+type Converter struct {
+	name string
+	// mutex guards the state, Lua VM can't be used from several goroutines at once
+	mutex sync.Mutex
+	state *lua.LState
+}
+
+// Available returns the names of the Lua converters
+func Available() []string {
+	files, err := filesystem.Api().ReadDir(where.Converters())
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == Extension {
+			names = append(names, util.FileStem(file.Name()))
+		}
+	}
+
+	return names
+}
+
+// Path returns the path of the Lua converter with the given name
+func Path(name string) string {
+	return filepath.Join(where.Converters(), name+Extension)
+}
+
+// Load runs the Lua script and returns the converter defined by it
+func Load(path string) (*Converter, error) {
+	proto, err := luaprovider.Compile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := lua.NewState()
+	libs.Preload(state)
+
+	state.Push(state.NewFunctionFromProto(proto))
+	if err = state.PCall(0, lua.MultRet, nil); err != nil {
+		return nil, err
+	}
+
+	name := util.FileStem(path)
+	if state.GetGlobal(constant.ConvertChapterFn).Type() != lua.LTFunction {
+		return nil, fmt.Errorf("required function %s is not defined in the converter %s", constant.ConvertChapterFn, name)
+	}
+
+	return &Converter{name: name, state: state}, nil
+}
+
+func (c *Converter) Save(chapter *source.Chapter) (string, error) {
+	return c.save(chapter, false)
+}
+
+func (c *Converter) SaveTemp(chapter *source.Chapter) (string, error) {
+	return c.save(chapter, true)
+}
+
+func (c *Converter) save(chapter *source.Chapter, temp bool) (string, error) {
+	output, err := chapter.Path(temp)
+	if err != nil {
+		return "", err
+	}
+
+	// scripts get the pages as files
+	dir, err := filesystem.Api().TempDir(where.Temp(), "pages")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = filesystem.Api().RemoveAll(dir)
+	}()
+
+	pages := make([]string, len(chapter.Pages))
+	for i, page := range chapter.Pages {
+		if pages[i], err = savePage(page, dir); err != nil {
+			return "", err
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	log.Infof("converting %s with the %s Lua converter", chapter.Name, c.name)
+	err = c.state.CallByParam(lua.P{
+		Fn:      c.state.GetGlobal(constant.ConvertChapterFn),
+		NRet:    1,
+		Protect: true,
+	}, c.pagesTable(pages), c.chapterTable(chapter), lua.LString(output))
+	if err != nil {
+		return "", err
+	}
+
+	path := c.state.Get(-1)
+	c.state.Pop(1)
+
+	if path.Type() != lua.LTString {
+		return "", fmt.Errorf("%s was expected to return a string, got %s", constant.ConvertChapterFn, path.Type())
+	}
+
+	if exists, _ := filesystem.Api().Exists(path.String()); !exists {
+		return "", fmt.Errorf("%s converter returned %s which does not exist", c.name, path.String())
+	}
+
+	return path.String(), nil
+}
+
+func savePage(page *source.Page, dir string) (string, error) {
+	path := filepath.Join(dir, page.Filename())
+	file, err := filesystem.Api().Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer util.Ignore(file.Close)
+
+	if page.Contents != nil {
+		if _, err = file.Write(page.Contents.Bytes()); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+func (c *Converter) pagesTable(pages []string) *lua.LTable {
+	table := c.state.NewTable()
+	for _, page := range pages {
+		table.Append(lua.LString(page))
+	}
+
+	return table
+}
+
+func (c *Converter) chapterTable(chapter *source.Chapter) *lua.LTable {
+	table := c.state.NewTable()
+	table.RawSetString("name", lua.LString(chapter.Name))
+	table.RawSetString("url", lua.LString(chapter.URL))
+	table.RawSetString("index", lua.LNumber(chapter.Index))
+	table.RawSetString("volume", lua.LString(chapter.Volume))
+	table.RawSetString("title", lua.LString(chapter.Title))
+	table.RawSetString("text", lua.LString(chapter.Text))
+	table.RawSetString("text_format", lua.LString(chapter.TextFormat))
+
+	if chapter.Manga != nil {
+		manga := c.state.NewTable()
+		manga.RawSetString("name", lua.LString(chapter.Manga.Name))
+		manga.RawSetString("url", lua.LString(chapter.Manga.URL))
+		manga.RawSetString("summary", lua.LString(chapter.Manga.Metadata.Summary))
+
+		genres := c.state.NewTable()
+		for _, genre := range chapter.Manga.Metadata.Genres {
+			genres.Append(lua.LString(genre))
+		}
+
+		manga.RawSetString("genres", genres)
+		table.RawSetString("manga", manga)
+
+		if chapter.Source() != nil {
+			table.RawSetString("source", lua.LString(chapter.Source().Name()))
+		}
+	}
+
+	return table
+}
@@ -0,0 +1,77 @@
+package custom
+
+import (
+	"bytes"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testScript lists the page files and the chapter name in the output
+const testScript = `
+function ConvertChapter(pages, chapter, output)
+	local file = io.open(output, "w")
+	file:write(chapter.manga.name .. " " .. chapter.name .. "\n")
+	for _, page in ipairs(pages) do
+		local image = io.open(page, "r")
+		file:write(image:read("*a") .. "\n")
+		image:close()
+	end
+	file:close()
+	return output
+end
+`
+
+func TestConverter(t *testing.T) {
+	Convey("Given a Lua converter", t, func() {
+		dir := t.TempDir()
+		script := filepath.Join(dir, "txt.lua")
+		lo.Must0(filesystem.Api().WriteFile(script, []byte(testScript), os.ModePerm))
+
+		converter, err := Load(script)
+		So(err, ShouldBeNil)
+
+		Convey("When a chapter is saved with it", func() {
+			viper.Set(key.DownloaderPath, dir)
+			viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+			defer viper.Set(key.DownloaderPath, "")
+
+			manga := &source.Manga{Name: "Berserk"}
+			chapter := &source.Chapter{Name: "Prologue", Index: 1, Manga: manga, Format: "txt"}
+			manga.Chapters = []*source.Chapter{chapter}
+			chapter.Pages = lo.Map([]string{"first", "second"}, func(contents string, i int) *source.Page {
+				return &source.Page{Index: i + 1, Extension: ".png", Contents: bytes.NewBufferString(contents), Chapter: chapter}
+			})
+
+			path, err := converter.Save(chapter)
+
+			Convey("Then the script should get the pages in order with the metadata", func() {
+				So(err, ShouldBeNil)
+				So(path, ShouldEqual, filepath.Join(dir, "Prologue.txt"))
+
+				saved, err := filesystem.Api().ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(saved), ShouldEqual, "Berserk Prologue\nfirst\nsecond\n")
+			})
+		})
+	})
+
+	Convey("Given a Lua script without the converter function", t, func() {
+		script := filepath.Join(t.TempDir(), "broken.lua")
+		lo.Must0(filesystem.Api().WriteFile(script, []byte(`print("hi")`), os.ModePerm))
+
+		Convey("When it is loaded", func() {
+			_, err := Load(script)
+
+			Convey("Then an error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,255 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"github.com/metafates/mangal/converter/content"
+	"github.com/metafates/mangal/decoder"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type EPUB struct{}
+
+func New() *EPUB {
+	return &EPUB{}
+}
+
+func (*EPUB) Save(chapter *source.Chapter) (string, error) {
+	return save(chapter, false)
+}
+
+func (*EPUB) SaveTemp(chapter *source.Chapter) (string, error) {
+	return save(chapter, true)
+}
+
+func save(chapter *source.Chapter, temp bool) (path string, err error) {
+	path, err = chapter.Path(temp)
+	if err != nil {
+		return
+	}
+
+	err = SaveTo(chapter, path)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// SaveTo writes the chapter as an epub book to the given path.
+// Images and text blocks are placed into a single document in the reading order.
+func SaveTo(chapter *source.Chapter, to string) error {
+	file, err := filesystem.Api().Create(to)
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(file.Close)
+
+	zipWriter := zip.NewWriter(file)
+	defer util.Ignore(zipWriter.Close)
+
+	// mimetype must be the first entry and must not be compressed
+	if err = addToZip(zipWriter, strings.NewReader("application/epub+zip"), "mimetype", zip.Store); err != nil {
+		return err
+	}
+
+	var (
+		body   strings.Builder
+		images []image
+	)
+
+	for _, c := range content.Of(chapter) {
+		switch c.Kind {
+		case content.Image:
+			img := image{
+				ID:        fmt.Sprintf("image%d", len(images)),
+				Href:      "images/" + c.Page.Filename(),
+				MediaType: mediaType(c.Page.Extension),
+			}
+
+			// readers don't support the exotic formats, so they are converted to png
+			contents, transcoded, err := decoder.Transcode(c.Page.Contents.Bytes(), c.Page.Index)
+			if err != nil {
+				if chapter.SkipUnsupportedImages() {
+					continue
+				}
+
+				return err
+			}
+
+			if transcoded {
+				img.Href = "images/" + util.FileStem(c.Page.Filename()) + ".png"
+				img.MediaType = mediaType(".png")
+			}
+
+			// images are already compressed
+			if err = addToZip(zipWriter, bytes.NewReader(contents), "OEBPS/"+img.Href, zip.Store); err != nil {
+				return err
+			}
+
+			body.WriteString(fmt.Sprintf(`<div class="page"><img src="%s" alt=""/></div>`+"\n", img.Href))
+			images = append(images, img)
+		case content.Text:
+			text, err := c.XHTML()
+			if err != nil {
+				return err
+			}
+
+			body.WriteString(text)
+		}
+	}
+
+	var cover *image
+	if viper.GetBool(key.FormatsEPUBCover) {
+		if cover, err = addCover(zipWriter, chapter.Manga); err != nil {
+			return err
+		}
+	}
+
+	meta := struct {
+		ID, Title, Series, Language, Description, Modified, Body string
+		RightToLeft                                              bool
+		Cover                                                    *image
+		Images                                                   []image
+		Creators                                                 []creator
+		Subjects                                                 []string
+	}{
+		ID:          fmt.Sprintf("mangal:%s:%s:%d", chapter.Manga.ID, chapter.ID, chapter.Index),
+		Title:       chapter.Name,
+		Series:      chapter.Manga.Title(),
+		Language:    language(chapter),
+		Description: chapter.Manga.Metadata.Summary,
+		RightToLeft: viper.GetBool(key.FormatsEPUBRightToLeft),
+		Cover:       cover,
+		Creators:    creators(chapter.Manga),
+		Subjects:    chapter.Manga.Metadata.Genres,
+		Modified:    time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		Body:        body.String(),
+		Images:      images,
+	}
+
+	for name, tmpl := range map[string]*template.Template{
+		"META-INF/container.xml": containerTemplate,
+		"OEBPS/content.opf":      packageTemplate,
+		"OEBPS/nav.xhtml":        navTemplate,
+		"OEBPS/chapter.xhtml":    chapterTemplate,
+	} {
+		var sb strings.Builder
+		if err = tmpl.Execute(&sb, meta); err != nil {
+			return err
+		}
+
+		if err = addToZip(zipWriter, strings.NewReader(sb.String()), name, zip.Deflate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type image struct {
+	ID, Href, MediaType string
+}
+
+// addCover adds the cover of the manga to the book.
+// The book is saved without it if the manga has no cover or it can't be fetched
+func addCover(zipWriter *zip.Writer, manga *source.Manga) (*image, error) {
+	contents, extension, err := manga.CoverContents()
+	if err != nil {
+		log.Warnf("epub of %s is saved without the cover: %s", manga.Name, err)
+		return nil, nil
+	}
+
+	cover := &image{
+		ID:        "cover",
+		Href:      "images/cover" + extension,
+		MediaType: mediaType(extension),
+	}
+
+	contents, transcoded, err := decoder.Transcode(contents, 0)
+	if err != nil {
+		log.Warnf("epub of %s is saved without the cover: %s", manga.Name, err)
+		return nil, nil
+	}
+
+	if transcoded {
+		cover.Href = "images/cover.png"
+		cover.MediaType = mediaType(".png")
+	}
+
+	if err = addToZip(zipWriter, bytes.NewReader(contents), "OEBPS/"+cover.Href, zip.Store); err != nil {
+		return nil, err
+	}
+
+	return cover, nil
+}
+
+// language returns the language tag of the book, the same as ComicInfo.xml has
+func language(chapter *source.Chapter) string {
+	if lang := chapter.ContentLanguage(); lang != "" {
+		return lang
+	}
+
+	return "en"
+}
+
+// creator of the book, Role is the MARC relator code
+type creator struct {
+	Name, Role string
+}
+
+// creators returns the authors and then the artists of the manga
+func creators(manga *source.Manga) []creator {
+	var list []creator
+	for _, name := range manga.Authors() {
+		list = append(list, creator{Name: name, Role: "aut"})
+	}
+
+	for _, name := range manga.Artists() {
+		list = append(list, creator{Name: name, Role: "art"})
+	}
+
+	return list
+}
+
+func mediaType(extension string) string {
+	switch strings.ToLower(extension) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func addToZip(writer *zip.Writer, file io.Reader, name string, method uint16) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: time.Now(),
+	}
+
+	headerWriter, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(headerWriter, file)
+	return err
+}
@@ -0,0 +1,205 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+	viper.Set(key.FormatsUse, constant.FormatEPUB)
+}
+
+func TestEPUB(t *testing.T) {
+	epub := New()
+
+	Convey("Given a FormatEPUB converter", t, func() {
+		Convey("When saving a text chapter", func() {
+			chapter := SampleChapter(t)
+			result, err := epub.Save(chapter)
+			Convey("Then the error should be nil", func() {
+				So(err, ShouldBeNil)
+				Convey("And the result should be a path with .epub extension", func() {
+					So(result, ShouldNotBeEmpty)
+					So(filepath.Ext(result), ShouldEqual, ".epub")
+
+					file, err := filesystem.Api().Open(result)
+					So(err, ShouldBeNil)
+
+					info := lo.Must(file.Stat())
+					zipReader := lo.Must(zip.NewReader(file, info.Size()))
+
+					Convey("And the first file should be an uncompressed mimetype", func() {
+						So(zipReader.File[0].Name, ShouldEqual, "mimetype")
+						So(zipReader.File[0].Method, ShouldEqual, zip.Store)
+					})
+
+					Convey("And the chapter document should contain the converted text", func() {
+						f, ok := lo.Find(zipReader.File, func(f *zip.File) bool {
+							return f.Name == "OEBPS/chapter.xhtml"
+						})
+						So(ok, ShouldBeTrue)
+
+						contents := string(lo.Must(io.ReadAll(lo.Must(f.Open()))))
+						So(contents, ShouldContainSubstring, "<h2>Prologue</h2>")
+						So(contents, ShouldContainSubstring, "<p>It was a <em>dark</em> &amp; stormy night.</p>")
+					})
+				})
+			})
+		})
+
+		Convey("When saving a chapter of a manga with the authors and the artists", func() {
+			chapter := SampleChapter(t)
+			chapter.Manga.Metadata.Staff.Story = []string{"Kentaro Miura"}
+			chapter.Manga.Metadata.Staff.Art = []string{"Studio Gaga"}
+
+			result, err := epub.Save(chapter)
+			So(err, ShouldBeNil)
+
+			file := lo.Must(filesystem.Api().Open(result))
+			info := lo.Must(file.Stat())
+			zipReader := lo.Must(zip.NewReader(file, info.Size()))
+
+			Convey("Then the package should list them as the creators with their roles", func() {
+				f, ok := lo.Find(zipReader.File, func(f *zip.File) bool {
+					return f.Name == "OEBPS/content.opf"
+				})
+				So(ok, ShouldBeTrue)
+
+				contents := string(lo.Must(io.ReadAll(lo.Must(f.Open()))))
+				So(contents, ShouldContainSubstring, `<dc:creator id="creator0">Kentaro Miura</dc:creator>`)
+				So(contents, ShouldContainSubstring, `<meta refines="#creator0" property="role" scheme="marc:relators">aut</meta>`)
+				So(contents, ShouldContainSubstring, `<meta refines="#creator1" property="role" scheme="marc:relators">art</meta>`)
+			})
+		})
+
+		Convey("When saving a chapter of a manga with the cover", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("cover"))
+			}))
+			defer server.Close()
+
+			chapter := SampleChapter(t)
+			chapter.Manga.Metadata.Cover.Large = server.URL + "/cover.jpg?size=large"
+			chapter.Manga.Metadata.Language = "fr"
+			chapter.Manga.Metadata.Summary = "Guts & Griffith"
+
+			result, err := epub.Save(chapter)
+			So(err, ShouldBeNil)
+
+			contents := readFromBook(result, "OEBPS/content.opf")
+
+			Convey("Then the cover should be embedded and marked as the cover image", func() {
+				So(readFromBook(result, "OEBPS/images/cover.jpg"), ShouldEqual, "cover")
+				So(contents, ShouldContainSubstring, `<meta name="cover" content="cover"/>`)
+				So(contents, ShouldContainSubstring, `<item id="cover" href="images/cover.jpg" media-type="image/jpeg" properties="cover-image"/>`)
+			})
+
+			Convey("Then the package should have the language and the description of the manga", func() {
+				So(contents, ShouldContainSubstring, `<dc:language>fr</dc:language>`)
+				So(contents, ShouldContainSubstring, `<dc:description>Guts &amp; Griffith</dc:description>`)
+			})
+		})
+
+		Convey("When saving a chapter with the pages turned from right to left", func() {
+			viper.Set(key.FormatsEPUBRightToLeft, true)
+			defer viper.Set(key.FormatsEPUBRightToLeft, false)
+
+			chapter := SampleChapter(t)
+			chapter.Manga.Metadata.Language = constant.LanguageRaw
+
+			result, err := epub.Save(chapter)
+			So(err, ShouldBeNil)
+
+			contents := readFromBook(result, "OEBPS/content.opf")
+
+			Convey("Then the spine should progress from right to left", func() {
+				So(contents, ShouldContainSubstring, `<spine page-progression-direction="rtl">`)
+			})
+
+			Convey("Then the raw manga should be in Japanese, like in ComicInfo.xml", func() {
+				So(contents, ShouldContainSubstring, `<dc:language>ja</dc:language>`)
+			})
+
+			Convey("Then no cover should be embedded", func() {
+				So(contents, ShouldNotContainSubstring, "cover-image")
+			})
+		})
+
+		Convey("When saving a chapter with illustrations", func() {
+			chapter := SampleChapter(t)
+			chapter.Pages = append(chapter.Pages, &source.Page{
+				Index:     1,
+				Extension: ".jpg",
+				Chapter:   chapter,
+				Contents:  bytes.NewBufferString("image"),
+			})
+
+			result, err := epub.Save(chapter)
+			Convey("Then the error should be nil", func() {
+				So(err, ShouldBeNil)
+
+				file := lo.Must(filesystem.Api().Open(result))
+				info := lo.Must(file.Stat())
+				zipReader := lo.Must(zip.NewReader(file, info.Size()))
+
+				Convey("And the image should be included in the book", func() {
+					_, ok := lo.Find(zipReader.File, func(f *zip.File) bool {
+						return f.Name == "OEBPS/images/"+chapter.Pages[0].Filename()
+					})
+					So(ok, ShouldBeTrue)
+				})
+			})
+		})
+	})
+}
+
+// readFromBook returns the contents of the file inside the epub book
+func readFromBook(path, name string) string {
+	file := lo.Must(filesystem.Api().Open(path))
+	info := lo.Must(file.Stat())
+	zipReader := lo.Must(zip.NewReader(file, info.Size()))
+
+	f, ok := lo.Find(zipReader.File, func(f *zip.File) bool {
+		return f.Name == name
+	})
+	So(ok, ShouldBeTrue)
+
+	return string(lo.Must(io.ReadAll(lo.Must(f.Open()))))
+}
+
+func SampleChapter(t *testing.T) *source.Chapter {
+	t.Helper()
+	chapter := source.Chapter{
+		Name:       "chapter name",
+		URL:        "chapter url",
+		Index:      42069,
+		ID:         "fawfa",
+		Pages:      []*source.Page{},
+		Text:       "## Prologue\n\nIt was a *dark* & stormy\nnight.",
+		TextFormat: constant.TextMarkdown,
+	}
+	manga := source.Manga{
+		Name:     "manga name",
+		URL:      "manga url",
+		Index:    1337,
+		ID:       "wjakfkawgjj",
+		Chapters: []*source.Chapter{&chapter},
+	}
+	chapter.Manga = &manga
+	return &chapter
+}
@@ -0,0 +1,79 @@
+package epub
+
+import (
+	"html"
+	"text/template"
+)
+
+var funcs = template.FuncMap{"escape": html.EscapeString}
+
+var containerTemplate = template.Must(template.New("container").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`))
+
+var packageTemplate = template.Must(template.New("package").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="id">{{ escape .ID }}</dc:identifier>
+    <dc:title>{{ escape .Title }}</dc:title>
+    <dc:language>{{ escape .Language }}</dc:language>
+    <meta property="belongs-to-collection">{{ escape .Series }}</meta>
+{{- if .Description }}
+    <dc:description>{{ escape .Description }}</dc:description>
+{{- end }}
+{{- range .Subjects }}
+    <dc:subject>{{ escape . }}</dc:subject>
+{{- end }}
+{{- range $i, $creator := .Creators }}
+    <dc:creator id="creator{{ $i }}">{{ escape $creator.Name }}</dc:creator>
+    <meta refines="#creator{{ $i }}" property="role" scheme="marc:relators">{{ $creator.Role }}</meta>
+{{- end }}
+    <meta property="dcterms:modified">{{ .Modified }}</meta>
+{{- with .Cover }}
+    <meta name="cover" content="{{ .ID }}"/>
+{{- end }}
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter" href="chapter.xhtml" media-type="application/xhtml+xml"/>
+{{- with .Cover }}
+    <item id="{{ .ID }}" href="{{ escape .Href }}" media-type="{{ .MediaType }}" properties="cover-image"/>
+{{- end }}
+{{- range .Images }}
+    <item id="{{ .ID }}" href="{{ escape .Href }}" media-type="{{ .MediaType }}"/>
+{{- end }}
+  </manifest>
+  <spine{{ if .RightToLeft }} page-progression-direction="rtl"{{ end }}>
+    <itemref idref="chapter"/>
+  </spine>
+</package>
+`))
+
+var navTemplate = template.Must(template.New("nav").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>{{ escape .Title }}</title></head>
+<body>
+  <nav epub:type="toc">
+    <ol>
+      <li><a href="chapter.xhtml">{{ escape .Title }}</a></li>
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+var chapterTemplate = template.Must(template.New("chapter").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>{{ escape .Title }}</title></head>
+<body>
+<h1>{{ escape .Title }}</h1>
+{{ .Body }}
+</body>
+</html>
+`))
@@ -1,6 +1,10 @@
 package pdf
 
 import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter/content"
+	"github.com/metafates/mangal/decoder"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/source"
@@ -8,8 +12,8 @@ import (
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
-	"github.com/spf13/viper"
 	"io"
+	"strings"
 )
 
 type PDF struct{}
@@ -39,12 +43,15 @@ func save(chapter *source.Chapter, temp bool) (path string, err error) {
 
 	defer util.Ignore(file.Close)
 
-	err = pagesToPDF(file, chapter.Pages)
+	err = contentsToPDF(file, content.Of(chapter), chapter.Passthrough(), chapter.SkipUnsupportedImages())
 	return
 }
 
-// pagesToPDF will convert images to PDF and write to w
-func pagesToPDF(w io.Writer, pages []*source.Page) error {
+// contentsToPDF will convert images and text to PDF and write to w.
+// If passthrough is true, only jpeg images are accepted since they are embedded as is,
+// other images would be decoded and re-encoded.
+// If skipUnsupported is true, the images that can not be decoded are left out.
+func contentsToPDF(w io.Writer, contents []*content.Content, passthrough, skipUnsupported bool) error {
 	conf := pdfcpu.NewDefaultConfiguration()
 	conf.Cmd = pdfcpu.IMPORTIMAGES
 	imp := pdfcpu.DefaultImportConfig()
@@ -70,22 +77,57 @@ func pagesToPDF(w io.Writer, pages []*source.Page) error {
 		return err
 	}
 
-	for _, r := range pages {
-		indRef, err := pdfcpu.NewPageForImage(ctx.XRefTable, r, pagesIndRef, imp)
+	for _, c := range contents {
+		var indRefs []*pdfcpu.IndirectRef
+
+		switch c.Kind {
+		case content.Image:
+			if passthrough && !isJPEG(c.Page) {
+				return fmt.Errorf(
+					"page #%d is not a jpeg and can not be stored in pdf without re-encoding, use %s or disable %s",
+					c.Page.Index,
+					constant.FormatCBZ,
+					key.FormatsRawPassthrough,
+				)
+			}
+
+			// exotic formats are decoded by image.Decode if mangal is built with their decoders
+			err := decoder.Check(c.Page.Contents.Bytes(), c.Page.Index)
 
-		if err != nil {
-			if viper.GetBool(key.FormatsSkipUnsupportedImages) {
-				continue
+			var indRef *pdfcpu.IndirectRef
+			if err == nil {
+				indRef, err = pdfcpu.NewPageForImage(ctx.XRefTable, c.Page, pagesIndRef, imp)
 			}
 
-			return err
-		}
+			if err != nil {
+				// raw pages are never skipped
+				if !passthrough && skipUnsupported {
+					continue
+				}
+
+				return err
+			}
+
+			indRefs = append(indRefs, indRef)
+		case content.Text:
+			paragraphs, err := c.Paragraphs()
+			if err != nil {
+				return err
+			}
 
-		if err = pdfcpu.AppendPageTree(indRef, 1, pagesDict); err != nil {
-			return err
+			indRefs, err = newPagesForText(ctx.XRefTable, paragraphs, pagesIndRef, imp.PageDim)
+			if err != nil {
+				return err
+			}
 		}
 
-		ctx.PageCount++
+		for _, indRef := range indRefs {
+			if err = pdfcpu.AppendPageTree(indRef, 1, pagesDict); err != nil {
+				return err
+			}
+
+			ctx.PageCount++
+		}
 	}
 
 	if err = api.WriteContext(ctx, w); err != nil {
@@ -96,3 +138,12 @@ func pagesToPDF(w io.Writer, pages []*source.Page) error {
 
 	return nil
 }
+
+func isJPEG(page *source.Page) bool {
+	switch strings.ToLower(page.Extension) {
+	case ".jpg", ".jpeg":
+		return true
+	default:
+		return false
+	}
+}
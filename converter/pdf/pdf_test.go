@@ -7,11 +7,13 @@ import (
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/source"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/samber/lo"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/spf13/viper"
 	"io/fs"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -47,6 +49,35 @@ func TestPDF(t *testing.T) {
 				})
 			})
 		})
+
+		Convey("When saving a raw chapter with non-jpeg pages", func() {
+			chapter := SampleChapter(t)
+			chapter.Manga.Metadata.Language = constant.LanguageRaw
+			chapter.Pages[0].Extension = ".png"
+			_, err := pdf.Save(chapter)
+			Convey("Then the error should not be nil", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "re-encoding")
+			})
+		})
+
+		Convey("When saving a text chapter", func() {
+			chapter := SampleChapter(t)
+			chapter.Pages = nil
+			chapter.Text = strings.Repeat("<p>All work and no play makes Jack a dull boy.</p>", 500)
+			chapter.TextFormat = constant.TextHTML
+			result, err := pdf.Save(chapter)
+			Convey("Then the error should be nil", func() {
+				So(err, ShouldBeNil)
+
+				Convey("And the text should span multiple pages", func() {
+					file := lo.Must(filesystem.Api().Open(result))
+					count, err := api.PageCount(file, nil)
+					So(err, ShouldBeNil)
+					So(count, ShouldBeGreaterThan, 1)
+				})
+			})
+		})
 	})
 }
 
@@ -0,0 +1,95 @@
+package pdf
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"strings"
+)
+
+const (
+	textFont     = "Times-Roman"
+	textFontSize = 12
+	textMargin   = 50
+)
+
+// newPagesForText lays out paragraphs into as many pages of the given dimensions as needed
+func newPagesForText(xRefTable *pdfcpu.XRefTable, paragraphs []string, parent *pdfcpu.IndirectRef, dim *pdfcpu.Dim) ([]*pdfcpu.IndirectRef, error) {
+	var (
+		mediaBox     = pdfcpu.RectForDim(dim.Width, dim.Height)
+		lineHeight   = font.LineHeight(textFont, textFontSize)
+		linesPerPage = int((dim.Height - 2*textMargin) / lineHeight)
+		lines        = wrapParagraphs(paragraphs, dim.Width-2*textMargin)
+		fonts        = make(map[string]pdfcpu.IndirectRef)
+		refs         []*pdfcpu.IndirectRef
+	)
+
+	for start := 0; start < len(lines); start += linesPerPage {
+		end := start + linesPerPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		page := pdfcpu.NewPage(mediaBox)
+		page.CropBox = mediaBox
+
+		pdfcpu.WriteMultiLine(page.Buf, mediaBox, nil, pdfcpu.TextDescriptor{
+			Text:      strings.Join(lines[start:end], "\n"),
+			FontName:  textFont,
+			FontKey:   page.Fm.EnsureKey(textFont),
+			FontSize:  textFontSize,
+			X:         textMargin,
+			Y:         dim.Height - textMargin,
+			HAlign:    pdfcpu.AlignLeft,
+			VAlign:    pdfcpu.AlignTop,
+			Scale:     1,
+			ScaleAbs:  true,
+			RMode:     pdfcpu.RMFill,
+			FillCol:   pdfcpu.Black,
+			StrokeCol: pdfcpu.Black,
+		})
+
+		ref, _, err := pdfcpu.CreatePage(xRefTable, *parent, page, fonts, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+// wrapParagraphs breaks paragraphs into lines that fit the given width.
+// Paragraphs are separated by an empty line.
+func wrapParagraphs(paragraphs []string, width float64) []string {
+	var lines []string
+
+	for i, paragraph := range paragraphs {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			// core fonts measure single byte encoded text
+			if line != "" && font.TextWidth(pdfcpu.DecodeUTF8ToByte(candidate), textFont, textFontSize) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+
+			line = candidate
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
@@ -1,6 +1,8 @@
 package plain
 
 import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter/content"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/source"
 	"io"
@@ -24,6 +26,10 @@ func (*Plain) SaveTemp(chapter *source.Chapter) (string, error) {
 }
 
 func save(chapter *source.Chapter, temp bool) (path string, err error) {
+	if content.IsTextOnly(chapter) {
+		return "", content.ErrTextOnly(chapter, constant.FormatPlain)
+	}
+
 	path, err = chapter.Path(temp)
 	if err != nil {
 		return
@@ -2,6 +2,9 @@ package zip
 
 import (
 	"archive/zip"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter/content"
+	"github.com/metafates/mangal/converter/zipcrypt"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
@@ -24,11 +27,20 @@ func (*ZIP) SaveTemp(chapter *source.Chapter) (string, error) {
 }
 
 func save(chapter *source.Chapter, temp bool) (path string, err error) {
+	if content.IsTextOnly(chapter) {
+		return "", content.ErrTextOnly(chapter, constant.FormatZIP)
+	}
+
 	path, err = chapter.Path(temp)
 	if err != nil {
 		return
 	}
 
+	password, err := zipcrypt.Password()
+	if err != nil {
+		return
+	}
+
 	zipFile, err := filesystem.Api().Create(path)
 	if err != nil {
 		return
@@ -40,7 +52,7 @@ func save(chapter *source.Chapter, temp bool) (path string, err error) {
 	defer util.Ignore(zipWriter.Close)
 
 	for _, page := range chapter.Pages {
-		if err = addToZip(zipWriter, page.Contents, page.Filename()); err != nil {
+		if err = addToZip(zipWriter, page.Contents, page.Filename(), password); err != nil {
 			return "", err
 		}
 	}
@@ -48,13 +60,17 @@ func save(chapter *source.Chapter, temp bool) (path string, err error) {
 	return
 }
 
-func addToZip(writer *zip.Writer, file io.Reader, name string) error {
+func addToZip(writer *zip.Writer, file io.Reader, name, password string) error {
 	header := &zip.FileHeader{
 		Name:     name,
 		Method:   zip.Deflate,
 		Modified: time.Now(),
 	}
 
+	if password != "" {
+		return zipcrypt.Add(writer, header, file, password)
+	}
+
 	headerWriter, err := writer.CreateHeader(header)
 	if err != nil {
 		return err
@@ -0,0 +1,272 @@
+// Package zipcrypt writes and reads zip entries encrypted with WinZip AES-256 (AE-2),
+// which is supported by most archivers, e.g. 7-Zip, WinRAR and Keka.
+package zipcrypt
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/pbkdf2"
+	"github.com/metafates/mangal/secrets"
+	"github.com/spf13/viper"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	methodAES = 99
+
+	extraID      = 0x9901
+	extraSize    = 7
+	extraVersion = 2 // AE-2, crc is not stored
+	strength256  = 3
+
+	saltSize     = 16
+	keySize      = 32
+	verifierSize = 2
+	macSize      = 10
+	iterations   = 1000
+
+	readerVersion = 51
+	flagEncrypted = 0x1
+	flagUTF8      = 0x800
+)
+
+var (
+	ErrPassword = errors.New("wrong password")
+	ErrCorrupt  = errors.New("encrypted entry is corrupted")
+)
+
+// Password returns password for the archives from the secrets store.
+// Empty string is returned if encryption is disabled
+func Password() (string, error) {
+	if !viper.GetBool(key.FormatsEncrypt) {
+		return "", nil
+	}
+
+	password, err := secrets.Get(secrets.ArchivePassword)
+	if err != nil {
+		return "", err
+	}
+
+	if password == "" {
+		return "", fmt.Errorf("archive encryption is enabled, but %s secret is not set", secrets.ArchivePassword)
+	}
+
+	return password, nil
+}
+
+// IsEncrypted reports whether the file is encrypted with AES
+func IsEncrypted(file *zip.File) bool {
+	return file.Method == methodAES
+}
+
+// Add writes contents of the reader to the zip as an encrypted entry.
+// Data is compressed with the header method before encryption,
+// only zip.Store and zip.Deflate are supported.
+func Add(writer *zip.Writer, header *zip.FileHeader, r io.Reader, password string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	size := len(data)
+
+	switch header.Method {
+	case zip.Store:
+	case zip.Deflate:
+		var buf bytes.Buffer
+		flateWriter, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			return err
+		}
+
+		if _, err = flateWriter.Write(data); err != nil {
+			return err
+		}
+
+		if err = flateWriter.Close(); err != nil {
+			return err
+		}
+
+		data = buf.Bytes()
+	default:
+		return zip.ErrAlgorithm
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return err
+	}
+
+	encryptionKey, macKey, verifier := deriveKeys(password, salt)
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return err
+	}
+
+	xorKeyStream(block, data)
+
+	mac := hmac.New(sha1.New, macKey)
+	mac.Write(data)
+
+	extra := make([]byte, 4+extraSize)
+	binary.LittleEndian.PutUint16(extra[0:], extraID)
+	binary.LittleEndian.PutUint16(extra[2:], extraSize)
+	binary.LittleEndian.PutUint16(extra[4:], extraVersion)
+	copy(extra[6:], "AE")
+	extra[8] = strength256
+	binary.LittleEndian.PutUint16(extra[9:], header.Method)
+
+	raw := *header
+	if !raw.Modified.IsZero() {
+		// CreateRaw, unlike CreateHeader, does not fill MS-DOS time from Modified
+		raw.SetModTime(raw.Modified)
+	}
+
+	raw.Method = methodAES
+	raw.Flags |= flagEncrypted
+	if !isASCII(raw.Name) && utf8.ValidString(raw.Name) {
+		raw.Flags |= flagUTF8
+	}
+
+	raw.ReaderVersion = readerVersion
+	raw.Extra = append(raw.Extra, extra...)
+	raw.CRC32 = 0
+	raw.CompressedSize64 = uint64(saltSize + verifierSize + len(data) + macSize)
+	raw.UncompressedSize64 = uint64(size)
+
+	entry, err := writer.CreateRaw(&raw)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range [][]byte{salt, verifier, data, mac.Sum(nil)[:macSize]} {
+		if _, err = entry.Write(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadFile decrypts and decompresses the encrypted entry
+func ReadFile(file *zip.File, password string) ([]byte, error) {
+	method, ok := actualMethod(file.Extra)
+	if !IsEncrypted(file) || !ok {
+		return nil, errors.New("file is not encrypted with AES-256")
+	}
+
+	rawReader, err := file.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(rawReader)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) < saltSize+verifierSize+macSize {
+		return nil, ErrCorrupt
+	}
+
+	var (
+		salt     = raw[:saltSize]
+		verifier = raw[saltSize : saltSize+verifierSize]
+		data     = raw[saltSize+verifierSize : len(raw)-macSize]
+		code     = raw[len(raw)-macSize:]
+	)
+
+	encryptionKey, macKey, expectedVerifier := deriveKeys(password, salt)
+	if !bytes.Equal(verifier, expectedVerifier) {
+		return nil, ErrPassword
+	}
+
+	mac := hmac.New(sha1.New, macKey)
+	mac.Write(data)
+	if !hmac.Equal(code, mac.Sum(nil)[:macSize]) {
+		return nil, ErrCorrupt
+	}
+
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	xorKeyStream(block, data)
+
+	switch method {
+	case zip.Store:
+		return data, nil
+	case zip.Deflate:
+		return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	default:
+		return nil, zip.ErrAlgorithm
+	}
+}
+
+// actualMethod returns the compression method stored in the AES extra field
+func actualMethod(extra []byte) (uint16, bool) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra[0:])
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+
+		if size > len(extra) {
+			break
+		}
+
+		if id == extraID && size == extraSize && extra[4] == strength256 {
+			return binary.LittleEndian.Uint16(extra[5:]), true
+		}
+
+		extra = extra[size:]
+	}
+
+	return 0, false
+}
+
+func deriveKeys(password string, salt []byte) (encryptionKey, macKey, verifier []byte) {
+	derived := pbkdf2.Key([]byte(password), salt, iterations, 2*keySize+verifierSize, sha1.New)
+	return derived[:keySize], derived[keySize : 2*keySize], derived[2*keySize:]
+}
+
+// xorKeyStream encrypts (or decrypts) data in place with AES in CTR mode.
+// Unlike cipher.NewCTR counter is little endian and starts at 1, as WinZip expects
+func xorKeyStream(block cipher.Block, data []byte) {
+	var counter, stream [aes.BlockSize]byte
+
+	for i := 0; i < len(data); i += aes.BlockSize {
+		for j := range counter {
+			counter[j]++
+			if counter[j] != 0 {
+				break
+			}
+		}
+
+		block.Encrypt(stream[:], counter[:])
+		for j := 0; j < aes.BlockSize && i+j < len(data); j++ {
+			data[i+j] ^= stream[j]
+		}
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
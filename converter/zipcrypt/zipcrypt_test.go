@@ -0,0 +1,54 @@
+package zipcrypt
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func TestAdd(t *testing.T) {
+	for _, method := range []uint16{zip.Store, zip.Deflate} {
+		Convey("Given an encrypted zip", t, func() {
+			contents := []byte(strings.Repeat("mangal ", 100))
+
+			var buf bytes.Buffer
+			writer := zip.NewWriter(&buf)
+			err := Add(writer, &zip.FileHeader{Name: "page.txt", Method: method}, bytes.NewReader(contents), "hunter2")
+			So(err, ShouldBeNil)
+			So(writer.Close(), ShouldBeNil)
+
+			reader := lo.Must(zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())))
+			So(reader.File, ShouldHaveLength, 1)
+
+			file := reader.File[0]
+
+			Convey("Then the entry should be encrypted", func() {
+				So(IsEncrypted(file), ShouldBeTrue)
+				So(bytes.Contains(buf.Bytes(), []byte("mangal mangal")), ShouldBeFalse)
+
+				_, err := file.Open()
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("When reading with the right password", func() {
+				decrypted, err := ReadFile(file, "hunter2")
+
+				Convey("Then original contents should be returned", func() {
+					So(err, ShouldBeNil)
+					So(decrypted, ShouldResemble, contents)
+				})
+			})
+
+			Convey("When reading with a wrong password", func() {
+				_, err := ReadFile(file, "hunter3")
+
+				Convey("Then an error should be returned", func() {
+					So(err, ShouldNotBeNil)
+				})
+			})
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package crash writes the reports of the panics, so that they can be attached to the bug reports.
+// Reports are anonymized, see redact
+package crash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/redact"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// exitCode is the exit code of the crash, the same as of the unrecovered panic
+const exitCode = 2
+
+// Report of the crash
+type Report struct {
+	Time     time.Time      `json:"time"`
+	Version  string         `json:"version"`
+	Revision string         `json:"revision,omitempty"`
+	OS       string         `json:"os"`
+	Arch     string         `json:"arch"`
+	Go       string         `json:"go"`
+	Panic    string         `json:"panic"`
+	Stack    string         `json:"stack"`
+	Config   map[string]any `json:"config"`
+}
+
+// New returns the report of the panic with its stack
+func New(value any, stack []byte) *Report {
+	return &Report{
+		Time:     time.Now(),
+		Version:  constant.Version,
+		Revision: constant.Revision,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Go:       runtime.Version(),
+		Panic:    redact.Home(fmt.Sprint(value)),
+		Stack:    redact.Home(string(stack)),
+		Config:   redact.Config(),
+	}
+}
+
+// Save writes the report to the logs directory and returns its path
+func (r *Report) Save() (string, error) {
+	contents, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(where.Logs(), fmt.Sprintf("crash-%s.json", r.Time.Format("2006-01-02T15-04-05")))
+	if err = filesystem.Api().WriteFile(path, contents, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Submit sends the report to the endpoint as JSON
+func (r *Report) Submit(endpoint string) error {
+	if endpoint == "" {
+		return fmt.Errorf("%s is not set", key.CrashEndpoint)
+	}
+
+	contents, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(contents))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", constant.Mangal+"/"+constant.Version)
+
+	resp, err := network.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("crash report was not accepted: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Recover handles the panic of the main goroutine, it must be deferred there.
+// The report is saved and its path is printed, then mangal exits.
+// The report is submitted only if it is enabled by the config
+func Recover() {
+	value := recover()
+	if value == nil {
+		return
+	}
+
+	log.Errorf("panic: %v", value)
+
+	report := New(value, debug.Stack())
+	_, _ = fmt.Fprintf(os.Stderr, "%s %s\n", icon.Get(icon.Fail), i18n.T("mangal has crashed: %s", report.Panic))
+
+	path, err := report.Save()
+	if err != nil {
+		// the stack is not lost, at least
+		_, _ = fmt.Fprintf(os.Stderr, "%s\n%s\n", i18n.T("The crash report could not be saved: %s", err), report.Stack)
+	} else {
+		_, _ = fmt.Fprintln(os.Stderr, i18n.T("The crash report is saved to %s, please attach it to the bug report", path))
+	}
+
+	if viper.GetBool(key.CrashSubmit) {
+		if err = report.Submit(viper.GetString(key.CrashEndpoint)); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, i18n.T("The crash report could not be submitted: %s", err))
+		} else {
+			_, _ = fmt.Fprintln(os.Stderr, i18n.T("The crash report is submitted"))
+		}
+	}
+
+	os.Exit(exitCode)
+}
@@ -0,0 +1,86 @@
+package crash
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+func TestNew(t *testing.T) {
+	Convey("Given a panic in the home directory", t, func() {
+		home := lo.Must(os.UserHomeDir())
+
+		Convey("When the report is made", func() {
+			report := New("boom in "+home, []byte("main.go"))
+
+			Convey("Then the home directory should be replaced", func() {
+				So(report.Panic, ShouldEqual, "boom in ~")
+			})
+
+			Convey("Then the version should be included", func() {
+				So(report.Version, ShouldEqual, constant.Version)
+			})
+		})
+	})
+}
+
+func TestSave(t *testing.T) {
+	Convey("Given a report", t, func() {
+		report := New("boom", []byte("main.go"))
+
+		Convey("When it is saved", func() {
+			path, err := report.Save()
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be written to the logs directory", func() {
+				contents := lo.Must(filesystem.Api().ReadFile(path))
+
+				var saved Report
+				So(json.Unmarshal(contents, &saved), ShouldBeNil)
+				So(saved.Panic, ShouldEqual, "boom")
+				So(saved.Stack, ShouldEqual, "main.go")
+			})
+		})
+	})
+}
+
+func TestSubmit(t *testing.T) {
+	Convey("Given an endpoint", t, func() {
+		var received Report
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := lo.Must(io.ReadAll(r.Body))
+			if json.Unmarshal(body, &received) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer server.Close()
+
+		Convey("When the report is submitted", func() {
+			err := New("boom", nil).Submit(server.URL)
+
+			Convey("Then it should be received", func() {
+				So(err, ShouldBeNil)
+				So(received.Panic, ShouldEqual, "boom")
+			})
+		})
+
+		Convey("When the endpoint is not set", func() {
+			err := New("boom", nil).Submit("")
+
+			Convey("Then an error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,151 @@
+// Package decoder registers the decoders of the exotic page formats, such as JPEG XL and HEIF,
+// so that the converters that decode or embed the pages can handle them.
+// The decoders depend on the C libraries of their formats, so they are only built with the tags of the formats:
+//
+//	go build -tags jxl,heif
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// Format of the page images that is not supported by the standard library
+type Format struct {
+	// Name of the format, as reported by image.Decode
+	Name string
+	// Extension of the pages in this format
+	Extension string
+	// Tag is the build tag of the decoder of the format
+	Tag string
+	// magic prefixes of the images, ? matches any byte
+	magic []string
+
+	decode       func(io.Reader) (image.Image, error)
+	decodeConfig func(io.Reader) (image.Config, error)
+}
+
+var formats = []*Format{
+	{
+		Name:      "jxl",
+		Extension: ".jxl",
+		Tag:       "jxl",
+		magic:     []string{"\xff\x0a", "\x00\x00\x00\x0cJXL \x0d\x0a\x87\x0a"},
+	},
+	{
+		Name:      "heif",
+		Extension: ".heic",
+		Tag:       "heif",
+		magic:     []string{"????ftypheic", "????ftypheix", "????ftypheim", "????ftypheis", "????ftypmif1", "????ftypmsf1"},
+	},
+	{
+		Name:      "avif",
+		Extension: ".avif",
+		Tag:       "heif",
+		magic:     []string{"????ftypavif", "????ftypavis"},
+	},
+}
+
+// register sets the decoder of the format and registers it for image.Decode,
+// called by the files built with the tag of the format
+func register(name string, decode func(io.Reader) (image.Image, error), decodeConfig func(io.Reader) (image.Config, error)) {
+	for _, format := range formats {
+		if format.Name != name {
+			continue
+		}
+
+		format.decode = decode
+		format.decodeConfig = decodeConfig
+
+		for _, magic := range format.magic {
+			image.RegisterFormat(name, magic, decode, decodeConfig)
+		}
+	}
+}
+
+// Supported returns the names of the formats whose decoders are built in
+func Supported() []string {
+	var names []string
+	for _, format := range formats {
+		if format.decode != nil {
+			names = append(names, format.Name)
+		}
+	}
+
+	return names
+}
+
+// matches reports whether the contents start with the magic prefix, see image.RegisterFormat
+func matches(magic string, contents []byte) bool {
+	if len(contents) < len(magic) {
+		return false
+	}
+
+	for i := 0; i < len(magic); i++ {
+		if magic[i] != '?' && magic[i] != contents[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Detect returns the exotic format of the image by its contents, regardless of the extension,
+// since the CDNs may serve these formats from the same links by the Accept header
+func Detect(contents []byte) (*Format, bool) {
+	for _, format := range formats {
+		for _, magic := range format.magic {
+			if matches(magic, contents) {
+				return format, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Check returns an error if the image is in the exotic format that mangal was built without
+func Check(contents []byte, index int) error {
+	format, ok := Detect(contents)
+	if !ok || format.decode != nil {
+		return nil
+	}
+
+	return fmt.Errorf("page #%d is %s, build mangal with -tags %s to convert it", index, format.Name, format.Tag)
+}
+
+// Transcode converts the image in the exotic format to png, so that it can be embedded by the converters
+// whose readers don't support the format, e.g. EPUB. Other images are returned as they are.
+// Converted reports whether the image was transcoded
+func Transcode(contents []byte, index int) (transcoded []byte, converted bool, err error) {
+	format, ok := Detect(contents)
+	if !ok {
+		return contents, false, nil
+	}
+
+	if err = Check(contents, index); err != nil {
+		return nil, false, err
+	}
+
+	img, err := format.decode(bytes.NewReader(contents))
+	if err != nil {
+		return nil, false, fmt.Errorf("page #%d: %w", index, err)
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return nil, false, err
+	}
+
+	return buf.Bytes(), true, nil
+}
+
+// newNRGBA returns the image of the 8-bit RGBA pixels decoded by the C libraries
+func newNRGBA(width, height int, pixels []byte) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	copy(img.Pix, pixels)
+	return img
+}
@@ -0,0 +1,88 @@
+package decoder
+
+import (
+	"bytes"
+	. "github.com/smartystreets/goconvey/convey"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+var (
+	jxlCodestream = []byte("\xff\x0a\xfa\x7f")
+	heicFile      = []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00")
+)
+
+func TestDetect(t *testing.T) {
+	Convey("Given the images of different formats", t, func() {
+		var buf bytes.Buffer
+		So(png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))), ShouldBeNil)
+
+		Convey("Then the exotic formats should be detected by their contents", func() {
+			format, ok := Detect(jxlCodestream)
+			So(ok, ShouldBeTrue)
+			So(format.Name, ShouldEqual, "jxl")
+
+			format, ok = Detect(heicFile)
+			So(ok, ShouldBeTrue)
+			So(format.Name, ShouldEqual, "heif")
+		})
+
+		Convey("Then the standard formats should be left to the standard library", func() {
+			_, ok := Detect(buf.Bytes())
+			So(ok, ShouldBeFalse)
+
+			transcoded, converted, err := Transcode(buf.Bytes(), 1)
+			So(err, ShouldBeNil)
+			So(converted, ShouldBeFalse)
+			So(transcoded, ShouldResemble, buf.Bytes())
+		})
+	})
+}
+
+func TestTranscode(t *testing.T) {
+	Convey("Given a page in the format without the decoder", t, func() {
+		format, _ := Detect(heicFile)
+		if format.decode != nil {
+			SkipConvey("mangal is built with the heif decoder", func() {})
+			return
+		}
+
+		Convey("Then the error should name the build tag", func() {
+			_, _, err := Transcode(heicFile, 3)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "-tags heif")
+			So(Check(heicFile, 3), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a registered decoder", t, func() {
+		format, _ := Detect(jxlCodestream)
+		decode, decodeConfig := format.decode, format.decodeConfig
+		Reset(func() {
+			format.decode, format.decodeConfig = decode, decodeConfig
+		})
+
+		register("jxl", func(io.Reader) (image.Image, error) {
+			return newNRGBA(2, 1, []byte{255, 0, 0, 255, 0, 0, 255, 128}), nil
+		}, func(io.Reader) (image.Config, error) {
+			return image.Config{ColorModel: color.NRGBAModel, Width: 2, Height: 1}, nil
+		})
+
+		Convey("Then the page should be converted to png", func() {
+			So(Supported(), ShouldContain, "jxl")
+			So(Check(jxlCodestream, 1), ShouldBeNil)
+
+			transcoded, converted, err := Transcode(jxlCodestream, 1)
+			So(err, ShouldBeNil)
+			So(converted, ShouldBeTrue)
+
+			img, err := png.Decode(bytes.NewReader(transcoded))
+			So(err, ShouldBeNil)
+			So(img.Bounds().Dx(), ShouldEqual, 2)
+			So(color.NRGBAModel.Convert(img.At(1, 0)), ShouldResemble, color.NRGBA{B: 255, A: 128})
+		})
+	})
+}
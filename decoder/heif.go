@@ -0,0 +1,144 @@
+//go:build heif
+
+package decoder
+
+/*
+#cgo pkg-config: libheif
+#include <stdlib.h>
+#include <string.h>
+#include <libheif/heif.h>
+
+// decode_heif decodes the primary image to 8-bit RGBA pixels allocated with malloc.
+// Only the size is read if info_only is set
+static int decode_heif(const uint8_t *data, size_t size, uint8_t **pixels, int *width, int *height, int info_only) {
+	struct heif_context *ctx = heif_context_alloc();
+	struct heif_image_handle *handle = NULL;
+	struct heif_image *img = NULL;
+	const uint8_t *plane;
+	int stride, y, result = -1;
+
+	*pixels = NULL;
+
+	if (ctx == NULL) {
+		return -1;
+	}
+
+	if (heif_context_read_from_memory_without_copy(ctx, data, size, NULL).code != heif_error_Ok) {
+		goto done;
+	}
+
+	if (heif_context_get_primary_image_handle(ctx, &handle).code != heif_error_Ok) {
+		goto done;
+	}
+
+	*width = heif_image_handle_get_width(handle);
+	*height = heif_image_handle_get_height(handle);
+
+	if (info_only) {
+		result = 0;
+		goto done;
+	}
+
+	if (heif_decode_image(handle, &img, heif_colorspace_RGB, heif_chroma_interleaved_RGBA, NULL).code != heif_error_Ok) {
+		goto done;
+	}
+
+	*width = heif_image_get_width(img, heif_channel_interleaved);
+	*height = heif_image_get_height(img, heif_channel_interleaved);
+
+	plane = heif_image_get_plane_readonly(img, heif_channel_interleaved, &stride);
+	if (plane == NULL || *width <= 0 || *height <= 0) {
+		goto done;
+	}
+
+	if ((*pixels = malloc((size_t)*width * (size_t)*height * 4)) == NULL) {
+		goto done;
+	}
+
+	for (y = 0; y < *height; y++) {
+		memcpy(*pixels + (size_t)y * (size_t)*width * 4, plane + (size_t)y * (size_t)stride, (size_t)*width * 4);
+	}
+
+	result = 0;
+
+done:
+	if (img != NULL) {
+		heif_image_release(img);
+	}
+
+	if (handle != NULL) {
+		heif_image_handle_release(handle);
+	}
+
+	heif_context_free(ctx);
+	return result;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"unsafe"
+)
+
+func init() {
+	// libheif decodes avif too, if it is built with an AV1 decoder
+	register("heif", decodeHEIF, decodeHEIFConfig)
+	register("avif", decodeHEIF, decodeHEIFConfig)
+}
+
+var errInvalidHEIF = errors.New("heif: invalid image")
+
+func decodeHEIFData(r io.Reader, infoOnly bool) (pixels []byte, width, height int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	if len(data) == 0 {
+		return nil, 0, 0, errInvalidHEIF
+	}
+
+	var (
+		cPixels         *C.uint8_t
+		cWidth, cHeight C.int
+		cInfoOnly       C.int
+	)
+
+	if infoOnly {
+		cInfoOnly = 1
+	}
+
+	if C.decode_heif((*C.uint8_t)(unsafe.Pointer(&data[0])), C.size_t(len(data)), &cPixels, &cWidth, &cHeight, cInfoOnly) != 0 {
+		return nil, 0, 0, errInvalidHEIF
+	}
+
+	width, height = int(cWidth), int(cHeight)
+	if cPixels != nil {
+		defer C.free(unsafe.Pointer(cPixels))
+		pixels = C.GoBytes(unsafe.Pointer(cPixels), C.int(width*height*4))
+	}
+
+	return pixels, width, height, nil
+}
+
+func decodeHEIF(r io.Reader) (image.Image, error) {
+	pixels, width, height, err := decodeHEIFData(r, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNRGBA(width, height, pixels), nil
+}
+
+func decodeHEIFConfig(r io.Reader) (image.Config, error) {
+	_, width, height, err := decodeHEIFData(r, true)
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	return image.Config{ColorModel: color.NRGBAModel, Width: width, Height: height}, nil
+}
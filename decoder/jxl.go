@@ -0,0 +1,153 @@
+//go:build jxl
+
+package decoder
+
+/*
+#cgo pkg-config: libjxl
+#include <stdlib.h>
+#include <jxl/decode.h>
+
+// decode_jxl decodes the first frame of the image to 8-bit RGBA pixels allocated with malloc.
+// Only the size is read if info_only is set
+static int decode_jxl(const uint8_t *data, size_t size, uint8_t **pixels, uint32_t *width, uint32_t *height, int info_only) {
+	JxlPixelFormat format = {4, JXL_TYPE_UINT8, JXL_NATIVE_ENDIAN, 0};
+	JxlBasicInfo info;
+	size_t buffer_size;
+	int events = JXL_DEC_BASIC_INFO;
+
+	*pixels = NULL;
+
+	JxlDecoder *dec = JxlDecoderCreate(NULL);
+	if (dec == NULL) {
+		return -1;
+	}
+
+	if (!info_only) {
+		events |= JXL_DEC_FULL_IMAGE;
+	}
+
+	if (JxlDecoderSubscribeEvents(dec, events) != JXL_DEC_SUCCESS) {
+		goto fail;
+	}
+
+	if (JxlDecoderSetInput(dec, data, size) != JXL_DEC_SUCCESS) {
+		goto fail;
+	}
+
+	JxlDecoderCloseInput(dec);
+
+	for (;;) {
+		switch (JxlDecoderProcessInput(dec)) {
+		case JXL_DEC_BASIC_INFO:
+			if (JxlDecoderGetBasicInfo(dec, &info) != JXL_DEC_SUCCESS) {
+				goto fail;
+			}
+
+			*width = info.xsize;
+			*height = info.ysize;
+
+			if (info_only) {
+				JxlDecoderDestroy(dec);
+				return 0;
+			}
+			break;
+		case JXL_DEC_NEED_IMAGE_OUT_BUFFER:
+			if (JxlDecoderImageOutBufferSize(dec, &format, &buffer_size) != JXL_DEC_SUCCESS) {
+				goto fail;
+			}
+
+			if ((*pixels = malloc(buffer_size)) == NULL) {
+				goto fail;
+			}
+
+			if (JxlDecoderSetImageOutBuffer(dec, &format, *pixels, buffer_size) != JXL_DEC_SUCCESS) {
+				goto fail;
+			}
+			break;
+		case JXL_DEC_FULL_IMAGE:
+		case JXL_DEC_SUCCESS:
+			// animations are not pages, the first frame is enough
+			if (*pixels == NULL) {
+				goto fail;
+			}
+
+			JxlDecoderDestroy(dec);
+			return 0;
+		default:
+			goto fail;
+		}
+	}
+
+fail:
+	free(*pixels);
+	*pixels = NULL;
+	JxlDecoderDestroy(dec);
+	return -1;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"unsafe"
+)
+
+func init() {
+	register("jxl", decodeJXL, decodeJXLConfig)
+}
+
+var errInvalidJXL = errors.New("jxl: invalid image")
+
+func decodeJXLData(r io.Reader, infoOnly bool) (pixels []byte, width, height int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	if len(data) == 0 {
+		return nil, 0, 0, errInvalidJXL
+	}
+
+	var (
+		cPixels         *C.uint8_t
+		cWidth, cHeight C.uint32_t
+		cInfoOnly       C.int
+	)
+
+	if infoOnly {
+		cInfoOnly = 1
+	}
+
+	if C.decode_jxl((*C.uint8_t)(unsafe.Pointer(&data[0])), C.size_t(len(data)), &cPixels, &cWidth, &cHeight, cInfoOnly) != 0 {
+		return nil, 0, 0, errInvalidJXL
+	}
+
+	width, height = int(cWidth), int(cHeight)
+	if cPixels != nil {
+		defer C.free(unsafe.Pointer(cPixels))
+		pixels = C.GoBytes(unsafe.Pointer(cPixels), C.int(width*height*4))
+	}
+
+	return pixels, width, height, nil
+}
+
+func decodeJXL(r io.Reader) (image.Image, error) {
+	pixels, width, height, err := decodeJXLData(r, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNRGBA(width, height, pixels), nil
+}
+
+func decodeJXLConfig(r io.Reader) (image.Config, error) {
+	_, width, height, err := decodeJXLData(r, true)
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	return image.Config{ColorModel: color.NRGBAModel, Width: width, Height: height}, nil
+}
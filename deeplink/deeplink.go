@@ -0,0 +1,80 @@
+// Package deeplink handles the mangal:// links that launch mangal from the other apps, e.g. from a userscript:
+//
+//	mangal://download?src=Manganelo&url=https://ww5.manganelo.tv/manga/berserk&chapters=last
+package deeplink
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Scheme of the links
+const Scheme = "mangal"
+
+// ActionDownload downloads the chapters of the manga
+const ActionDownload = "download"
+
+// Link is the parsed mangal:// link
+type Link struct {
+	// Action to perform, only ActionDownload is supported
+	Action string
+	// Source is the name or the ID of the source
+	Source string
+	// URL of the manga page
+	URL string
+	// Name of the manga, searched by the URL if empty
+	Name string
+	// Chapters selector, same as in inline mode.
+	// Empty if the link selects no chapters, then downloading all of them must be confirmed
+	Chapters string
+	// ChapterURLs are the pages of the chapters to download instead of the selector
+	ChapterURLs []string
+}
+
+// Parse parses the mangal:// link.
+// Both mangal://download?... and mangal:download?... forms are accepted
+func Parse(raw string) (*Link, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Scheme != Scheme {
+		return nil, fmt.Errorf("not a %s:// link: %s", Scheme, raw)
+	}
+
+	action := parsed.Host
+	if action == "" {
+		action = parsed.Opaque
+	}
+
+	if action == "" {
+		action = parsed.Path
+	}
+
+	query := parsed.Query()
+	link := &Link{
+		Action:      strings.ToLower(strings.Trim(action, "/")),
+		Source:      query.Get("src"),
+		URL:         query.Get("url"),
+		Name:        query.Get("name"),
+		Chapters:    query.Get("chapters"),
+		ChapterURLs: query["chapter"],
+	}
+
+	if link.Action != ActionDownload {
+		return nil, fmt.Errorf("unknown action %q, expected %s", link.Action, ActionDownload)
+	}
+
+	if link.Source == "" || link.URL == "" {
+		return nil, fmt.Errorf("src and url are required: %s", raw)
+	}
+
+	return link, nil
+}
+
+// SelectsChapters reports whether the link selects the chapters to download
+func (l *Link) SelectsChapters() bool {
+	return l.Chapters != "" || len(l.ChapterURLs) > 0
+}
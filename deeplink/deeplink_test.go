@@ -0,0 +1,88 @@
+package deeplink
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Given a download link", t, func() {
+		raw := "mangal://download?src=Mangapill&url=https%3A%2F%2Fmangapill.com%2Fmanga%2F2%2Fberserk&chapter=https://mangapill.com/chapters/2-10001000/berserk-chapter-1"
+
+		Convey("When it is parsed", func() {
+			link, err := Parse(raw)
+
+			Convey("Then the parameters should be set", func() {
+				So(err, ShouldBeNil)
+				So(link.Action, ShouldEqual, ActionDownload)
+				So(link.Source, ShouldEqual, "Mangapill")
+				So(link.URL, ShouldEqual, "https://mangapill.com/manga/2/berserk")
+				So(link.ChapterURLs, ShouldResemble, []string{"https://mangapill.com/chapters/2-10001000/berserk-chapter-1"})
+				So(link.Chapters, ShouldBeEmpty)
+				So(link.SelectsChapters(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When it has no slashes after the scheme", func() {
+			link, err := Parse("mangal:download?src=Mangapill&url=https://mangapill.com/manga/2/berserk&chapters=last")
+
+			Convey("Then it should be parsed the same", func() {
+				So(err, ShouldBeNil)
+				So(link.Action, ShouldEqual, ActionDownload)
+				So(link.Chapters, ShouldEqual, "last")
+			})
+		})
+
+		Convey("When it selects no chapters", func() {
+			link, err := Parse("mangal://download?src=Mangapill&url=https://mangapill.com/manga/2/berserk")
+
+			Convey("Then it should not select all of them", func() {
+				So(err, ShouldBeNil)
+				So(link.Chapters, ShouldBeEmpty)
+				So(link.SelectsChapters(), ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given invalid links", t, func() {
+		Convey("Then they should not be parsed", func() {
+			for _, raw := range []string{
+				"https://mangapill.com/manga/2/berserk",
+				"mangal://delete?src=Mangapill&url=https://mangapill.com/manga/2/berserk",
+				"mangal://download?src=Mangapill",
+			} {
+				_, err := Parse(raw)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
+
+func TestDesktopEntry(t *testing.T) {
+	Convey("When making the desktop entry", t, func() {
+		entry := DesktopEntry([]string{"/opt/my apps/mangal", "uri", "handle"})
+
+		Convey("Then it should handle the scheme with the quoted command", func() {
+			So(entry, ShouldContainSubstring, `Exec="/opt/my apps/mangal" "uri" "handle" %u`)
+			So(entry, ShouldContainSubstring, "MimeType=x-scheme-handler/mangal;")
+		})
+	})
+
+	Convey("When making the desktop entry of the command with the reserved characters", t, func() {
+		entry := DesktopEntry([]string{`/opt/$HOME "100%"/back\slash`, "uri"})
+
+		Convey("Then they should be escaped by the quoting and the string rules", func() {
+			So(entry, ShouldContainSubstring, `Exec="/opt/\\$HOME \\"100%%\\"/back\\\\slash" "uri" %u`)
+		})
+	})
+}
+
+func TestWindowsCommand(t *testing.T) {
+	Convey("When making the command of the registry key", t, func() {
+		command := WindowsCommand([]string{`C:\Program Files\mangal.exe`, `dir\`, `say "hi"`})
+
+		Convey("Then the arguments should be quoted for the windows command line", func() {
+			So(command, ShouldEqual, `"C:\Program Files\mangal.exe" "dir\\" "say \"hi\"" "%1"`)
+		})
+	})
+}
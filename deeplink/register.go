@@ -0,0 +1,171 @@
+package deeplink
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// desktopFilename is the name of the desktop entry of the handler on linux
+const desktopFilename = constant.Mangal + "-handler.desktop"
+
+// windowsKey is the registry key of the scheme on windows
+var windowsKey = `HKCU\Software\Classes\` + Scheme
+
+var errUnsupportedOS = fmt.Errorf("can't register %s:// links on this OS: %s", Scheme, runtime.GOOS)
+
+// Register registers the command as the handler of the mangal:// links.
+// The link is appended as the last argument of the command
+func Register(command []string) error {
+	switch runtime.GOOS {
+	case constant.Linux:
+		return registerLinux(command)
+	case constant.Windows:
+		return registerWindows(command)
+	default:
+		return errUnsupportedOS
+	}
+}
+
+// Unregister removes the handler of the mangal:// links
+func Unregister() error {
+	switch runtime.GOOS {
+	case constant.Linux:
+		path, err := desktopPath()
+		if err != nil {
+			return err
+		}
+
+		return filesystem.Api().Remove(path)
+	case constant.Windows:
+		return exec.Command("reg", "delete", windowsKey, "/f").Run()
+	default:
+		return errUnsupportedOS
+	}
+}
+
+// desktopPath returns the path of the desktop entry, following the XDG base directory specification
+func desktopPath() (string, error) {
+	data := os.Getenv("XDG_DATA_HOME")
+	if data == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		data = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(data, "applications", desktopFilename), nil
+}
+
+// DesktopEntry returns the desktop entry that handles the links with the command.
+// It runs in a terminal, so that the progress of the downloads is shown.
+// The field code of the link is left unquoted, as the spec requires, the launcher passes the link as a single argument
+func DesktopEntry(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = desktopQuote(arg)
+	}
+
+	return fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Mangal
+Comment=Handle %s:// links
+Exec=%s %%u
+MimeType=x-scheme-handler/%s;
+Terminal=true
+NoDisplay=true
+`, Scheme, strings.Join(quoted, " "), Scheme)
+}
+
+// desktopQuote quotes the argument of the Exec key, following the desktop entry specification.
+// The reserved characters are escaped by the quoting rule first, then the result is escaped as the string value,
+// and the percent signs are doubled, so that they are not taken as the field codes
+func desktopQuote(arg string) string {
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(arg)
+	arg = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "\r", `\r`, "%", "%%").Replace(arg)
+	return `"` + arg + `"`
+}
+
+// WindowsCommand returns the command of the registry key that handles the links with the command.
+// The link is substituted for the quoted %1
+func WindowsCommand(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = windowsQuote(arg)
+	}
+
+	return strings.Join(quoted, " ") + ` "%1"`
+}
+
+// windowsQuote quotes the argument the way the programs split their command line on windows:
+// the quotes are escaped and so are the backslashes before them, including the closing one
+func windowsQuote(arg string) string {
+	var (
+		builder strings.Builder
+		slashes int
+	)
+
+	builder.WriteByte('"')
+	for _, r := range arg {
+		switch r {
+		case '\\':
+			slashes++
+			continue
+		case '"':
+			builder.WriteString(strings.Repeat(`\`, 2*slashes+1))
+		default:
+			builder.WriteString(strings.Repeat(`\`, slashes))
+		}
+
+		slashes = 0
+		builder.WriteRune(r)
+	}
+
+	builder.WriteString(strings.Repeat(`\`, 2*slashes))
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+func registerLinux(command []string) error {
+	path, err := desktopPath()
+	if err != nil {
+		return err
+	}
+
+	if err = filesystem.Api().MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err = filesystem.Api().WriteFile(path, []byte(DesktopEntry(command)), 0644); err != nil {
+		return err
+	}
+
+	output, err := exec.Command("xdg-mime", "default", desktopFilename, "x-scheme-handler/"+Scheme).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("xdg-mime: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+func registerWindows(command []string) error {
+	for _, args := range [][]string{
+		{"add", windowsKey, "/ve", "/d", "URL:Mangal", "/f"},
+		{"add", windowsKey, "/v", "URL Protocol", "/d", "", "/f"},
+		{"add", windowsKey + `\shell\open\command`, "/ve", "/d", WindowsCommand(command), "/f"},
+	} {
+		output, err := exec.Command("reg", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("reg: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package device
+
+import (
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+func TestDetect(t *testing.T) {
+	Convey("Given a mounted kobo", t, func() {
+		target := filepath.Join("media", "kobo")
+		lo.Must0(filesystem.Api().MkdirAll(filepath.Join(target, ".kobo"), os.ModePerm))
+
+		Convey("Then kobo profile should be detected", func() {
+			So(Detect(target).Name, ShouldEqual, "kobo")
+		})
+	})
+
+	Convey("Given an unknown device", t, func() {
+		target := filepath.Join("media", "unknown")
+		lo.Must0(filesystem.Api().MkdirAll(target, os.ModePerm))
+
+		Convey("Then generic profile should be used", func() {
+			So(Detect(target).Name, ShouldEqual, "generic")
+		})
+	})
+}
+
+func TestSync(t *testing.T) {
+	Convey("Given a library with a manga", t, func() {
+		mangaPath := filepath.Join(where.Downloads(), "Berserk")
+		lo.Must0(filesystem.Api().MkdirAll(mangaPath, os.ModePerm))
+		for _, name := range []string{"[0001] Chapter 1.cbz", "[0002] Chapter 2.cbz", "[0003] Chapter 3.pdf"} {
+			lo.Must0(filesystem.Api().WriteFile(filepath.Join(mangaPath, name), []byte(name), os.ModePerm))
+		}
+
+		target := filepath.Join("media", "device")
+		lo.Must0(filesystem.Api().MkdirAll(target, os.ModePerm))
+
+		defer func() {
+			_ = filesystem.Api().RemoveAll(mangaPath)
+			_ = filesystem.Api().RemoveAll(target)
+		}()
+
+		mangas := []*library.Manga{{Name: "Berserk", Path: mangaPath}}
+		options := &Options{
+			Target:  target,
+			Profile: lo.Must(GetProfile("generic")),
+			IsRead: func(manga string, index int) bool {
+				return manga == "Berserk" && index <= 1
+			},
+		}
+
+		report, err := Sync(mangas, options)
+		So(err, ShouldBeNil)
+
+		Convey("Then every chapter should be copied as is", func() {
+			So(report.Sent, ShouldHaveLength, 3)
+
+			contents := lo.Must(filesystem.Api().ReadFile(filepath.Join(target, "Manga", "Berserk", "[0001] Chapter 1.cbz")))
+			So(string(contents), ShouldEqual, "[0001] Chapter 1.cbz")
+		})
+
+		Convey("When syncing again", func() {
+			report, err := Sync(mangas, options)
+
+			Convey("Then nothing should be sent", func() {
+				So(err, ShouldBeNil)
+				So(report.Sent, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the user removes a chapter from the device", func() {
+			lo.Must0(filesystem.Api().Remove(filepath.Join(target, "Manga", "Berserk", "[0002] Chapter 2.cbz")))
+			report, err := Sync(mangas, options)
+
+			Convey("Then it should not be sent again", func() {
+				So(err, ShouldBeNil)
+				So(report.Sent, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When syncing with read chapters removal", func() {
+			options.RemoveRead = true
+			report, err := Sync(mangas, options)
+
+			Convey("Then read chapters should be removed from the device", func() {
+				So(err, ShouldBeNil)
+				So(report.Removed, ShouldResemble, []string{"Manga/Berserk/[0001] Chapter 1.cbz"})
+
+				exists := lo.Must(filesystem.Api().Exists(filepath.Join(target, "Manga", "Berserk", "[0001] Chapter 1.cbz")))
+				So(exists, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given a device that prefers cbz", t, func() {
+		mangaPath := filepath.Join(where.Downloads(), "Vagabond")
+		lo.Must0(filesystem.Api().MkdirAll(mangaPath, os.ModePerm))
+		lo.Must0(filesystem.Api().WriteFile(filepath.Join(mangaPath, "[0001] Chapter 1.pdf"), []byte("pdf"), os.ModePerm))
+
+		target := filepath.Join("media", "kobo")
+		defer func() {
+			_ = filesystem.Api().RemoveAll(mangaPath)
+			_ = filesystem.Api().RemoveAll(target)
+		}()
+
+		Convey("When syncing a pdf chapter", func() {
+			report, err := Sync([]*library.Manga{{Name: "Vagabond", Path: mangaPath}}, &Options{
+				Target:  target,
+				Profile: lo.Must(GetProfile("kobo")),
+			})
+
+			Convey("Then it should be skipped since pdf can't be converted", func() {
+				So(err, ShouldBeNil)
+				So(report.Sent, ShouldBeEmpty)
+				So(report.Skipped, ShouldHaveLength, 1)
+			})
+		})
+	})
+}
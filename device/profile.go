@@ -0,0 +1,83 @@
+package device
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/samber/lo"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile describes how chapters are stored on the device
+type Profile struct {
+	// Name of the profile
+	Name string
+	// Format chapters are converted to.
+	// Empty format means that chapters are copied as they are
+	Format string
+	// Dir is the directory relative to the device root where mangas are copied
+	Dir string
+	// markers are the files or directories that identify the device
+	markers []string
+}
+
+var profiles = map[string]*Profile{
+	"kobo": {
+		Name:    "kobo",
+		Format:  constant.FormatCBZ,
+		Dir:     "Manga",
+		markers: []string{".kobo"},
+	},
+	"kindle": {
+		Name:    "kindle",
+		Format:  constant.FormatPDF,
+		Dir:     filepath.Join("documents", "Manga"),
+		markers: []string{filepath.Join("system", "thumbnails"), "documents"},
+	},
+	"pocketbook": {
+		Name:    "pocketbook",
+		Format:  constant.FormatCBZ,
+		Dir:     "Manga",
+		markers: []string{filepath.Join("system", "config", "pbreader")},
+	},
+	"generic": {
+		Name: "generic",
+		Dir:  "Manga",
+	},
+}
+
+// Profiles returns names of the available profiles
+func Profiles() []string {
+	names := lo.Keys(profiles)
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile returns a profile by name
+func GetProfile(name string) (*Profile, error) {
+	if profile, ok := profiles[strings.ToLower(name)]; ok {
+		return profile, nil
+	}
+
+	return nil, fmt.Errorf("unknown device profile \"%s\", available options are %s", name, strings.Join(Profiles(), ", "))
+}
+
+// Detect guesses the profile of the device mounted at the target path.
+// Generic profile is returned if device is not recognized
+func Detect(target string) *Profile {
+	for _, name := range []string{"kobo", "pocketbook", "kindle"} {
+		profile := profiles[name]
+		found := lo.EveryBy(profile.markers, func(marker string) bool {
+			exists, _ := filesystem.Api().Exists(filepath.Join(target, marker))
+			return exists
+		})
+
+		if found {
+			return profile
+		}
+	}
+
+	return profiles["generic"]
+}
@@ -0,0 +1,55 @@
+package device
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateFilename is the name of the file on the device root where sent chapters are tracked
+const StateFilename = ".mangal-sync.json"
+
+// Sent is a chapter that was copied to the device
+type Sent struct {
+	// Manga name
+	Manga string `json:"manga"`
+	// Index of the chapter
+	Index int `json:"index"`
+	// Path on the device relative to its root
+	Path string `json:"path"`
+	// Time chapter was sent
+	Time time.Time `json:"time"`
+	// Removed is true if the chapter was removed from the device,
+	// either by sync or by the user. Such chapters are not sent again
+	Removed bool `json:"removed,omitempty"`
+}
+
+// state of the device, sent chapters by their slash separated paths relative to the library
+type state map[string]*Sent
+
+func loadState(target string) (state, error) {
+	s := make(state)
+
+	contents, err := filesystem.Api().ReadFile(filepath.Join(target, StateFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, &s)
+	return s, err
+}
+
+func (s state) save(target string) error {
+	contents, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(filepath.Join(target, StateFilename), contents, os.ModePerm)
+}
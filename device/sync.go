@@ -0,0 +1,230 @@
+// Package device synchronizes the library with e-readers mounted as folders, e.g. over USB or MTP.
+package device
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/update"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Options of the sync
+type Options struct {
+	// Target is the path where device is mounted
+	Target string
+	// Profile of the device
+	Profile *Profile
+	// Format overrides the profile format
+	Format string
+	// RemoveRead removes chapters that were read from the device
+	RemoveRead bool
+	// DryRun reports what would be done without touching the device
+	DryRun bool
+	// IsRead reports whether the chapter of the manga was read.
+	// Defaults to checking the history
+	IsRead func(manga string, index int) bool
+	// Progress is called with the status messages
+	Progress func(string)
+}
+
+// Report of the sync, paths are relative to the device root
+type Report struct {
+	Sent    []string
+	Removed []string
+	// Skipped are chapters that could not be converted to the device format
+	Skipped []string
+}
+
+// convertable formats can be unpacked back to pages
+var convertable = []string{constant.FormatCBZ, constant.FormatZIP}
+
+// Sync copies new chapters of the given mangas to the device
+func Sync(mangas []*library.Manga, options *Options) (*Report, error) {
+	if options.IsRead == nil {
+		options.IsRead = history.IsRead()
+	}
+
+	if options.Progress == nil {
+		options.Progress = func(string) {}
+	}
+
+	format := options.Format
+	if format == "" {
+		format = options.Profile.Format
+
+		// devices get a single file per chapter, so only the primary format of the rule is used
+		if formats := source.FormatsFor(options.Profile.Name, options.Target); len(formats) > 0 {
+			format = formats[0]
+		}
+	}
+
+	sent, err := loadState(options.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+
+	// chapters removed by the user from the device are not sent again
+	for _, chapter := range sent {
+		if chapter.Removed {
+			continue
+		}
+
+		exists, err := filesystem.Api().Exists(filepath.Join(options.Target, filepath.FromSlash(chapter.Path)))
+		if err == nil && !exists {
+			log.Infof("%s was removed from the device", chapter.Path)
+			chapter.Removed = true
+		}
+	}
+
+	for _, manga := range mangas {
+		chapters, err := manga.Chapters()
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		mangaFormat := format
+		if options.Format == "" {
+			if entry, err := manga.Entry(); err == nil && entry != nil && entry.Overrides.DeviceFormat != "" {
+				mangaFormat = entry.Overrides.DeviceFormat
+			}
+		}
+
+		for _, chapter := range chapters {
+			relative := filepath.ToSlash(filepath.Join(filepath.Base(manga.Path), chapter.Path))
+
+			if s, ok := sent[relative]; ok {
+				if options.RemoveRead && !s.Removed && options.IsRead(manga.Name, s.Index) {
+					options.Progress("Removing " + s.Path)
+					if !options.DryRun {
+						err = filesystem.Api().Remove(filepath.Join(options.Target, filepath.FromSlash(s.Path)))
+						if err != nil && !os.IsNotExist(err) {
+							return nil, err
+						}
+
+						s.Removed = true
+					}
+
+					report.Removed = append(report.Removed, s.Path)
+				}
+
+				continue
+			}
+
+			if options.RemoveRead && options.IsRead(manga.Name, chapter.Index) {
+				continue
+			}
+
+			chapterFormat := strings.TrimPrefix(filepath.Ext(chapter.Path), ".")
+			targetFormat := mangaFormat
+			if targetFormat == "" {
+				targetFormat = chapterFormat
+			}
+
+			destination := filepath.Join(
+				options.Profile.Dir,
+				filepath.Base(manga.Path),
+				util.FileStem(chapter.Path)+"."+targetFormat,
+			)
+
+			if targetFormat != chapterFormat && !lo.Contains(convertable, chapterFormat) {
+				log.Warnf("can't convert %s to %s", chapter.Path, targetFormat)
+				report.Skipped = append(report.Skipped, filepath.ToSlash(destination))
+				continue
+			}
+
+			options.Progress("Sending " + filepath.ToSlash(destination))
+			if !options.DryRun {
+				from := filepath.Join(manga.Path, chapter.Path)
+				if targetFormat == chapterFormat {
+					err = copyFile(from, filepath.Join(options.Target, destination))
+				} else {
+					err = convert(manga, chapter, from, targetFormat, filepath.Join(options.Target, destination))
+				}
+
+				if err != nil {
+					return nil, err
+				}
+
+				sent[relative] = &Sent{
+					Manga: manga.Name,
+					Index: chapter.Index,
+					Path:  filepath.ToSlash(destination),
+					Time:  time.Now(),
+				}
+
+				// save after each chapter, so that nothing is sent twice if device is disconnected
+				if err = sent.save(options.Target); err != nil {
+					return nil, err
+				}
+			}
+
+			report.Sent = append(report.Sent, filepath.ToSlash(destination))
+		}
+	}
+
+	if options.DryRun {
+		return report, nil
+	}
+
+	return report, sent.save(options.Target)
+}
+
+func copyFile(from, to string) error {
+	contents, err := filesystem.Api().ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	err = filesystem.Api().MkdirAll(filepath.Dir(to), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(to, contents, os.ModePerm)
+}
+
+// convert unpacks the chapter pages and saves them to the device in the given format
+func convert(manga *library.Manga, chapter *library.Chapter, from, format, to string) error {
+	conv, err := converter.Get(format)
+	if err != nil {
+		return err
+	}
+
+	converted := &source.Chapter{
+		Name:   chapter.Name,
+		Index:  chapter.Index,
+		Manga:  &source.Manga{Name: manga.Name},
+		Format: format,
+	}
+	converted.Manga.Chapters = []*source.Chapter{converted}
+
+	err = update.ReadPages(converted, from)
+	if err != nil {
+		return err
+	}
+
+	temp, err := conv.SaveTemp(converted)
+	if err != nil {
+		return fmt.Errorf("converting %s: %w", from, err)
+	}
+
+	defer func() {
+		_ = filesystem.Api().Remove(temp)
+	}()
+
+	return copyFile(temp, to)
+}
@@ -0,0 +1,209 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+)
+
+// download is the function used to download each chapter of the batch, replaced in tests
+var download = downloadChapter
+
+// preflight checks the downloads path before the batch, replaced in tests
+var preflight = Preflight
+
+// BatchOptions of the batch download
+type BatchOptions struct {
+	// Retries is how many times the failed chapters are retried.
+	// Retries are made after all the other chapters are downloaded
+	Retries int
+	// StopOnError stops the batch on the first failure, without retries
+	StopOnError bool
+	// Progress reports the progress of the chapter being downloaded
+	Progress func(chapter *source.Chapter, status string)
+	// Downloaded is called for each chapter once it is downloaded
+	Downloaded func(chapter *source.Chapter, path string)
+}
+
+// ChapterSummary is the outcome of a single chapter of the batch
+type ChapterSummary struct {
+	Manga    string `json:"manga"`
+	Name     string `json:"name"`
+	Index    int    `json:"index"`
+	URL      string `json:"url"`
+	Path     string `json:"path,omitempty"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+	// Fallbacks are the formats that were substituted, since their converters have failed
+	Fallbacks []Fallback `json:"fallbacks,omitempty"`
+
+	chapter *source.Chapter
+	skipped bool
+}
+
+// Chapter of the summary
+func (c *ChapterSummary) Chapter() *source.Chapter {
+	return c.chapter
+}
+
+// Summary of the batch download
+type Summary struct {
+	Downloaded []*ChapterSummary `json:"downloaded"`
+	Failed     []*ChapterSummary `json:"failed"`
+	// Skipped chapters are neither retried nor considered failed
+	Skipped []*ChapterSummary `json:"skipped"`
+}
+
+// Partial reports whether some chapters were downloaded and others failed
+func (s *Summary) Partial() bool {
+	return len(s.Downloaded) > 0 && len(s.Failed) > 0
+}
+
+// Err returns the *BatchError if any chapter has failed to download
+func (s *Summary) Err() error {
+	if len(s.Failed) == 0 {
+		return nil
+	}
+
+	return &BatchError{Summary: s}
+}
+
+// BatchError is returned when some chapters of the batch have failed to download
+type BatchError struct {
+	Summary *Summary
+}
+
+func (e *BatchError) Error() string {
+	failed := e.Summary.Failed
+	if len(failed) == 1 {
+		return fmt.Sprintf("failed to download %s: %s", failed[0].Name, failed[0].Error)
+	}
+
+	return fmt.Sprintf("failed to download %d of %d chapters", len(failed), len(failed)+len(e.Summary.Downloaded)+len(e.Summary.Skipped))
+}
+
+// Batch downloads the chapters one by one.
+// Failure of a chapter, even a panic, does not stop the others unless options.StopOnError is set.
+// Failed chapters are retried at the end, their errors are collected in the summary.
+// Chapters skipped with Skip are not retried.
+// Error is returned only when the batch was stopped, see Summary.Err for the failed chapters
+func Batch(chapters []*source.Chapter, options BatchOptions) (*Summary, error) {
+	if err := preflight(); err != nil {
+		return nil, err
+	}
+
+	// the chapters that are not reached yet are resumed too, if the batch is interrupted
+	if err := Enqueue(chapters...); err != nil {
+		log.Warn(err)
+	}
+
+	var (
+		// the fallback path is used if the downloads path was found unavailable by the preflight
+		root      = where.Downloads()
+		summary   = &Summary{}
+		summaries = make([]*ChapterSummary, len(chapters))
+		failed    []int
+	)
+
+	attempt := func(i int) (ok bool) {
+		chapter := chapters[i]
+		s := summaries[i]
+		s.Attempts++
+
+		path, fallbacks, err := safeDownload(chapter, root, func(status string) {
+			if options.Progress != nil {
+				options.Progress(chapter, status)
+			}
+		})
+
+		if errors.Is(err, ErrSkipped) {
+			s.skipped = true
+			return true
+		}
+
+		if err != nil {
+			log.Errorf("failed to download %s (attempt %d): %s", chapter.Name, s.Attempts, err)
+			s.Error = err.Error()
+			return false
+		}
+
+		s.Path = path
+		s.Error = ""
+		s.Fallbacks = fallbacks
+		if options.Downloaded != nil {
+			options.Downloaded(chapter, path)
+		}
+
+		return true
+	}
+
+	for i, chapter := range chapters {
+		summaries[i] = &ChapterSummary{
+			Name:    chapter.Name,
+			Index:   chapter.Index,
+			URL:     chapter.URL,
+			chapter: chapter,
+		}
+
+		if chapter.Manga != nil {
+			summaries[i].Manga = chapter.Manga.Name
+		}
+
+		if !attempt(i) {
+			if options.StopOnError {
+				return summary.collect(summaries[:i+1]), fmt.Errorf("failed to download %s: %s", chapter.Name, summaries[i].Error)
+			}
+
+			failed = append(failed, i)
+		}
+	}
+
+	for retry := 0; retry < options.Retries && len(failed) > 0; retry++ {
+		log.Infof("retrying %d failed chapters", len(failed))
+
+		var stillFailed []int
+		for _, i := range failed {
+			if !attempt(i) {
+				stillFailed = append(stillFailed, i)
+			}
+		}
+
+		failed = stillFailed
+	}
+
+	return summary.collect(summaries), nil
+}
+
+// collect splits the summaries of the chapters into downloaded, failed and skipped ones, keeping their order
+func (s *Summary) collect(summaries []*ChapterSummary) *Summary {
+	s.Downloaded = make([]*ChapterSummary, 0)
+	s.Failed = make([]*ChapterSummary, 0)
+	s.Skipped = make([]*ChapterSummary, 0)
+
+	for _, summary := range summaries {
+		switch {
+		case summary.skipped:
+			s.Skipped = append(s.Skipped, summary)
+		case summary.Error == "":
+			s.Downloaded = append(s.Downloaded, summary)
+		default:
+			s.Failed = append(s.Failed, summary)
+		}
+	}
+
+	return s
+}
+
+// safeDownload downloads the chapter, turning a panic into an error,
+// so that a single broken chapter (or source) does not take down the whole batch
+func safeDownload(chapter *source.Chapter, root string, progress func(string)) (path string, fallbacks []Fallback, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return download(chapter, root, progress)
+}
@@ -0,0 +1,151 @@
+package downloader
+
+import (
+	"errors"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// stubDownload replaces the download of the chapters for the test.
+// Chapters fail the given number of attempts, panic if the number is -1 or are skipped if it is -2
+func stubDownload(t *testing.T, failures map[string]int) map[string]int {
+	attempts := make(map[string]int)
+
+	download = func(chapter *source.Chapter, _ string, _ func(string)) (string, []Fallback, error) {
+		attempts[chapter.Name]++
+
+		switch n := failures[chapter.Name]; {
+		case n == -1:
+			panic("broken source")
+		case n == -2:
+			return "", nil, ErrSkipped
+		case attempts[chapter.Name] <= n:
+			return "", nil, errors.New("network error")
+		default:
+			return chapter.Name + ".cbz", nil, nil
+		}
+	}
+
+	preflight = func() error { return nil }
+
+	t.Cleanup(func() {
+		download = downloadChapter
+		preflight = Preflight
+	})
+
+	return attempts
+}
+
+func names(summaries []*ChapterSummary) []string {
+	return lo.Map(summaries, func(s *ChapterSummary, _ int) string {
+		return s.Name
+	})
+}
+
+func TestBatch(t *testing.T) {
+	Convey("Given 4 chapters", t, func() {
+		manga := &source.Manga{Name: "Berserk"}
+		chapters := lo.Map([]string{"1", "2", "3", "4"}, func(name string, i int) *source.Chapter {
+			return &source.Chapter{Name: name, Index: i + 1, Manga: manga}
+		})
+
+		Convey("When one fails once and one panics", func() {
+			attempts := stubDownload(t, map[string]int{"2": 1, "3": -1})
+
+			var downloaded []string
+			summary, err := Batch(chapters, BatchOptions{
+				Retries: 2,
+				Downloaded: func(chapter *source.Chapter, path string) {
+					downloaded = append(downloaded, path)
+				},
+			})
+
+			Convey("Then other chapters should be downloaded", func() {
+				So(err, ShouldBeNil)
+				So(names(summary.Downloaded), ShouldResemble, []string{"1", "2", "4"})
+				So(downloaded, ShouldResemble, []string{"1.cbz", "4.cbz", "2.cbz"})
+				So(summary.Downloaded[0].Manga, ShouldEqual, "Berserk")
+			})
+
+			Convey("Then failed ones should be retried at the end", func() {
+				So(attempts["1"], ShouldEqual, 1)
+				So(attempts["2"], ShouldEqual, 2)
+				So(attempts["3"], ShouldEqual, 3)
+				So(summary.Downloaded[1].Attempts, ShouldEqual, 2)
+			})
+
+			Convey("Then the summary should report a partial success", func() {
+				So(names(summary.Failed), ShouldResemble, []string{"3"})
+				So(summary.Failed[0].Error, ShouldContainSubstring, "panic: broken source")
+				So(summary.Partial(), ShouldBeTrue)
+
+				var batchErr *BatchError
+				So(errors.As(summary.Err(), &batchErr), ShouldBeTrue)
+				So(batchErr.Summary, ShouldEqual, summary)
+			})
+		})
+
+		Convey("When all of them succeed", func() {
+			stubDownload(t, nil)
+			summary, err := Batch(chapters, BatchOptions{})
+
+			Convey("Then there should be no errors", func() {
+				So(err, ShouldBeNil)
+				So(summary.Err(), ShouldBeNil)
+				So(summary.Failed, ShouldBeEmpty)
+				So(summary.Downloaded[3].Path, ShouldEqual, "4.cbz")
+			})
+		})
+
+		Convey("When one is skipped", func() {
+			attempts := stubDownload(t, map[string]int{"2": -2})
+			summary, err := Batch(chapters, BatchOptions{StopOnError: true, Retries: 1})
+
+			Convey("Then it should neither stop the batch nor be retried", func() {
+				So(err, ShouldBeNil)
+				So(attempts["2"], ShouldEqual, 1)
+				So(names(summary.Skipped), ShouldResemble, []string{"2"})
+				So(names(summary.Downloaded), ShouldResemble, []string{"1", "3", "4"})
+				So(summary.Err(), ShouldBeNil)
+			})
+		})
+
+		Convey("When stopping on error", func() {
+			attempts := stubDownload(t, map[string]int{"2": 1})
+			summary, err := Batch(chapters, BatchOptions{StopOnError: true, Retries: 1})
+
+			Convey("Then the batch should stop on the first failure", func() {
+				So(err, ShouldNotBeNil)
+				So(attempts["2"], ShouldEqual, 1)
+				So(attempts["3"], ShouldEqual, 0)
+				So(names(summary.Downloaded), ShouldResemble, []string{"1"})
+				So(names(summary.Failed), ShouldResemble, []string{"2"})
+			})
+		})
+	})
+}
+
+func TestSkip(t *testing.T) {
+	Convey("Given two chapters being downloaded", t, func() {
+		first, doneFirst := skippable()
+		second, doneSecond := skippable()
+		defer doneSecond()
+
+		Convey("When the first one is finished and the rest are skipped", func() {
+			doneFirst()
+			So(first.Err(), ShouldNotBeNil)
+			So(second.Err(), ShouldBeNil)
+
+			Convey("Then only the second one should be canceled", func() {
+				So(Skip(), ShouldBeTrue)
+				So(second.Err(), ShouldNotBeNil)
+
+				Convey("And there should be nothing left to skip", func() {
+					So(Skip(), ShouldBeFalse)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter/cbz"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// bundleVolume bundles the volume of the downloaded chapter into one cbz, once all of its chapters are saved as cbz.
+// It is bundled again when more chapters of the volume are downloaded, failures are logged only
+func bundleVolume(chapter *source.Chapter, progress func(string)) {
+	if !viper.GetBool(key.FormatsVolumeBundles) || chapter.Volume == "" {
+		return
+	}
+
+	// the manga may have more chapters than the downloaded ones, so the volume is complete only if all of them are known
+	chapters := lo.Filter(chapter.Manga.Chapters, func(c *source.Chapter, _ int) bool {
+		return c.Volume == chapter.Volume
+	})
+	if len(chapters) == 0 {
+		return
+	}
+
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return source.ChapterLess(chapters[i], chapters[j])
+	})
+
+	archives := make([]string, len(chapters))
+	for i, c := range chapters {
+		archives[i] = c.As(constant.FormatCBZ).PeekPath()
+		if exists, _ := filesystem.Api().Exists(archives[i]); !exists {
+			return
+		}
+	}
+
+	to := chapter.Manga.BundlePath(chapter.Volume)
+	if err := filesystem.Api().MkdirAll(filepath.Dir(to), os.ModePerm); err != nil {
+		log.Warn(err)
+		return
+	}
+
+	var comicInfo *source.ComicInfo
+	if viper.GetBool(key.MetadataComicInfoXML) {
+		comicInfo = chapter.ComicInfo()
+		comicInfo.Title, comicInfo.Number, comicInfo.Web = chapter.Volume, 0, ""
+	}
+
+	progress(i18n.T("Bundling %s", chapter.Volume))
+	if err := cbz.Bundle(to, archives, comicInfo); err != nil {
+		log.Warnf("bundling %s of %s: %s", chapter.Volume, chapter.Manga.Name, err)
+		return
+	}
+
+	log.Infof("bundled %s of %s", chapter.Volume, chapter.Manga.Name)
+}
@@ -1,56 +1,179 @@
 package downloader
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/converter"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
 	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/manifest"
 	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/stats"
 	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/translator"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
 	"github.com/spf13/viper"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-// Download the chapter using given source.
+// Fallback is the substitution of the format whose converter has failed, see key.FormatsFallback
+type Fallback struct {
+	// Format that has failed
+	Format string `json:"format"`
+	// Fallback is the format that the chapter was saved in instead
+	Fallback string `json:"fallback"`
+	// Error of the converter of the format
+	Error string `json:"error"`
+}
+
+// Preflight checks that the chapters can be saved before the downloads start,
+// so that a read-only or missing library fails at once instead of after fetching the pages.
+// The fallback path is used from now on if the downloads path is unavailable, see where.CheckDownloads
+func Preflight() error {
+	path, fallback, err := where.CheckDownloads()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if fallback {
+		log.Warnf("%s is unavailable, saving chapters to %s", viper.GetString(key.DownloaderPath), path)
+	}
+
+	return nil
+}
+
+// Download the chapter using given source to the downloads path.
+// ErrSkipped is returned if the download was canceled with Skip
 func Download(chapter *source.Chapter, progress func(string)) (string, error) {
+	return DownloadTo(chapter, where.Downloads(), progress)
+}
+
+// DownloadTo downloads the chapter to the library at root, e.g. the personal library of a user of the server.
+// The config is left as it is, so the other downloads and requests are not affected
+func DownloadTo(chapter *source.Chapter, root string, progress func(string)) (string, error) {
+	path, _, err := downloadChapter(chapter, root, progress)
+	return path, err
+}
+
+// resolveSettings sets the settings of the manga of the chapter for the library at root,
+// with the overrides of the manga if it is tracked there, see library.Overrides.
+// They are resolved once for the manga, unless it is downloaded to another library
+func resolveSettings(chapter *source.Chapter, root string) {
+	if chapter.Manga == nil {
+		return
+	}
+
+	if settings := chapter.Manga.Settings; settings != nil && filepath.Clean(settings.Root) == filepath.Clean(root) {
+		return
+	}
+
+	chapter.Manga.Settings = library.OverridesIn(root, chapter.Manga).Settings(root)
+}
+
+// downloadChapter downloads the chapter to the library at root and returns the formats that were substituted.
+// The chapter stays in the download queue until it is downloaded or skipped, see Enqueue
+func downloadChapter(chapter *source.Chapter, root string, progress func(string)) (path string, fallbacks []Fallback, err error) {
 	log.Info("downloading " + chapter.Name)
+	resolveSettings(chapter, root)
+
+	if err = Enqueue(chapter); err != nil {
+		log.Warn(err)
+	}
+
+	defer func() {
+		switch {
+		case err == nil, errors.Is(err, ErrSkipped):
+			dequeue(chapter)
+		default:
+			updateQueued(chapter, func(entry *Queued) {
+				entry.Error = err.Error()
+			})
+		}
+	}()
+
+	ctx, done := skippable()
+	defer done()
 
-	path, err := chapter.Path(false)
+	path, err = chapter.Path(false)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	// chapter is converted to each of the formats from the same pages
+	formats := chapter.Formats()
+	if _, err = converter.GetAll(formats); err != nil {
+		log.Error(err)
+		return "", nil, err
+	}
+
+	// the file of the format being written when the download was interrupted is partial
+	if entry, ok := queuedEntry(chapter); ok && entry.Converting != "" {
+		log.Infof("%s of %s was interrupted, converting it again", entry.Converting, chapter.Name)
+		removePartial(chapter.As(entry.Converting))
 	}
 
 	if viper.GetBool(key.DownloaderRedownloadExisting) {
 		log.Info("chapter already downloaded, deleting and redownloading")
-		err = filesystem.Api().Remove(path)
-		if err != nil {
-			log.Warn(err)
+		for _, format := range formats {
+			formatted, err := chapter.As(format).Path(false)
+			if err == nil {
+				err = filesystem.Api().Remove(formatted)
+			}
+
+			if err != nil {
+				log.Warn(err)
+			}
 		}
 	} else {
 		log.Info("checking if chapter is already downloaded")
-		if chapter.IsDownloaded() {
+		formats = lo.Filter(formats, func(format string, _ int) bool {
+			return !chapter.As(format).IsDownloaded()
+		})
+
+		if len(formats) == 0 {
 			log.Info("chapter already downloaded, skipping")
-			return path, nil
+			return path, fallbacks, nil
 		}
 	}
 
-	progress("Getting pages")
+	progress(i18n.T("Getting pages"))
 	pages, err := chapter.Source().PagesOf(chapter)
 	if err != nil {
 		log.Error(err)
-		return "", err
+		return "", nil, err
 	}
 	log.Info("found " + fmt.Sprintf("%d", len(pages)) + " pages")
 
-	err = chapter.DownloadPages(false, progress)
+	// pages can't be canceled while they are being fetched by the source
+	if ctx.Err() != nil {
+		log.Info("skipped " + chapter.Name)
+		return "", nil, ErrSkipped
+	}
+
+	if restored := restorePages(chapter); restored > 0 {
+		log.Infof("restored %d pages downloaded before", restored)
+	}
+
+	err = chapter.DownloadPagesWith(ctx, false, progress, stagePage)
 	if err != nil {
+		if ctx.Err() != nil {
+			log.Info("skipped " + chapter.Name)
+			return "", nil, ErrSkipped
+		}
+
 		log.Error(err)
-		return "", err
+		return "", nil, err
 	}
 
 	if viper.GetBool(key.MetadataFetchAnilist) {
@@ -66,7 +189,7 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 			log.Warn(err)
 		} else {
 			path = filepath.Join(path, "series.json")
-			progress("Generating series.json")
+			progress(i18n.T("Generating series.json"))
 			seriesJSON := chapter.Manga.SeriesJSON()
 			buf, err := json.Marshal(seriesJSON)
 			if err != nil {
@@ -87,29 +210,58 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 		}
 	}
 
-	log.Info("getting " + viper.GetString(key.FormatsUse) + " converter")
-	progress(fmt.Sprintf(
-		"Converting %d pages to %s %s",
-		len(pages),
-		style.Fg(color.Yellow)(viper.GetString(key.FormatsUse)),
-		style.Faint(chapter.SizeHuman())),
-	)
-	conv, err := converter.Get(viper.GetString(key.FormatsUse))
+	log.Info("getting " + strings.Join(formats, ", ") + " converters")
+	converters, err := converter.GetAll(formats)
 	if err != nil {
 		log.Error(err)
-		return "", err
+		return "", nil, err
 	}
 
-	log.Info("converting " + viper.GetString(key.FormatsUse))
-	path, err = conv.Save(chapter)
+	// chapter to convert, history is still saved for the original one
+	converted := chapter
+	if viper.GetBool(key.TranslatorEnable) {
+		translated, err := translator.Chapter(chapter, progress)
+		if err != nil {
+			log.Error(err)
+			return "", nil, err
+		}
+
+		if viper.GetBool(key.TranslatorKeepOriginals) {
+			log.Info("converting original pages")
+			if _, _, err = save(chapter, formats, converters, progress); err != nil {
+				log.Error(err)
+				return "", nil, err
+			}
+		}
+
+		converted = translated
+	}
+
+	saved, fallbacks, err := save(converted, formats, converters, progress)
 	if err != nil {
 		log.Error(err)
-		return "", err
+		return "", nil, err
+	}
+
+	if err = chapter.RecordNumbering(); err != nil {
+		log.Warn(err)
+	}
+
+	if err = stats.RecordDownload(chapter); err != nil {
+		log.Warn(err)
+	}
+
+	bundleVolume(chapter, progress)
+
+	// the path of the primary format, unless it was downloaded before
+	if formats[0] == chapter.Formats()[0] {
+		path = saved
 	}
 
 	if viper.GetBool(key.HistorySaveOnDownload) {
 		go func() {
-			err = history.Save(chapter)
+			// not the result, which the deferred update of the queue reads
+			err := history.Save(chapter)
 			if err != nil {
 				log.Warn(err)
 			} else {
@@ -119,6 +271,133 @@ func Download(chapter *source.Chapter, progress func(string)) (string, error) {
 	}
 
 	log.Info("downloaded without errors")
-	progress("Downloaded")
-	return path, nil
+	progress(i18n.T("Downloaded"))
+	return path, fallbacks, nil
+}
+
+// save converts the chapter to each of the formats with their converters
+// and returns the path of the first one.
+// Formats whose converters fail are substituted with the fallback formats, see key.FormatsFallback
+func save(chapter *source.Chapter, formats []string, converters []converter.Converter, progress func(string)) (path string, fallbacks []Fallback, err error) {
+	// converters read the page contents, so each of them gets the contents from the start
+	contents := lo.Map(chapter.Pages, func(page *source.Page, _ int) []byte {
+		if page.Contents == nil {
+			return nil
+		}
+
+		return page.Contents.Bytes()
+	})
+
+	rewind := func() {
+		for j, page := range chapter.Pages {
+			if contents[j] != nil {
+				page.Contents = bytes.NewBuffer(contents[j])
+			}
+		}
+	}
+
+	convert := func(format, substitutes string, conv converter.Converter) (string, error) {
+		rewind()
+
+		log.Info("converting " + format)
+		progress(i18n.T(
+			"Converting %d pages to %s %s",
+			len(chapter.Pages),
+			style.Fg(color.Yellow)(format),
+			style.Faint(chapter.SizeHuman())),
+		)
+
+		formatted := chapter.As(format)
+		updateQueued(chapter, func(entry *Queued) {
+			entry.Converting = format
+		})
+
+		saved, err := conv.Save(formatted)
+		if err != nil {
+			removePartial(formatted)
+			return "", err
+		}
+
+		updateQueued(chapter, func(entry *Queued) {
+			entry.Converting = ""
+		})
+
+		// the manifest fingerprints the contents of the pages
+		rewind()
+		track(formatted, saved, substitutes)
+		return saved, nil
+	}
+
+	for i, format := range formats {
+		saved, err := convert(format, "", converters[i])
+		if err != nil {
+			var fallback Fallback
+			saved, fallback, err = fallBack(format, err, formats, convert)
+			if err != nil {
+				return "", nil, err
+			}
+
+			fallbacks = append(fallbacks, fallback)
+		}
+
+		if i == 0 {
+			path = saved
+		}
+	}
+
+	return path, fallbacks, nil
+}
+
+// fallBack saves the chapter in the first of the fallback formats that succeeds, skipping the ones it is saved in anyway.
+// The error of the failed format is returned if none of them succeeds
+func fallBack(
+	format string,
+	failure error,
+	formats []string,
+	convert func(format, substitutes string, conv converter.Converter) (string, error),
+) (string, Fallback, error) {
+	for _, fallback := range viper.GetStringSlice(key.FormatsFallback) {
+		if fallback == format || lo.Contains(formats, fallback) {
+			continue
+		}
+
+		conv, err := converter.Get(fallback)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		log.Warnf("converting to %s failed, falling back to %s: %s", format, fallback, failure)
+		saved, err := convert(fallback, format, conv)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		return saved, Fallback{Format: format, Fallback: fallback, Error: failure.Error()}, nil
+	}
+
+	return "", Fallback{}, failure
+}
+
+// removePartial removes what the failed converter has written, so that the chapter is not considered downloaded
+func removePartial(chapter *source.Chapter) {
+	path := chapter.PeekPath()
+	if exists, _ := filesystem.Api().Exists(path); exists {
+		if err := filesystem.Api().RemoveAll(path); err != nil {
+			log.Warn(err)
+		}
+	}
+}
+
+// track adds the saved chapter to the manifest of its manga.
+// Substitutes is the format it was saved instead of, empty if it is its own one
+func track(chapter *source.Chapter, path, substitutes string) {
+	if !viper.GetBool(key.MetadataManifest) {
+		return
+	}
+
+	if err := manifest.TrackSubstitute(chapter, path, substitutes); err != nil {
+		log.Warn(err)
+	}
 }
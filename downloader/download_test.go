@@ -0,0 +1,102 @@
+package downloader
+
+import (
+	"bytes"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	Convey("Given a downloaded chapter", t, func() {
+		filesystem.SetMemMapFs()
+		viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+
+		manga := &source.Manga{Name: "Berserk"}
+		chapter := &source.Chapter{Name: "1", Index: 1, Manga: manga}
+		manga.Chapters = []*source.Chapter{chapter}
+		chapter.Pages = []*source.Page{{
+			Index:     1,
+			Extension: ".png",
+			Contents:  bytes.NewBufferString("page"),
+			Chapter:   chapter,
+		}}
+
+		Convey("When it is saved in several formats", func() {
+			formats := []string{constant.FormatPlain, constant.FormatZIP}
+			converters := lo.Must(converter.GetAll(formats))
+			path, fallbacks, err := save(chapter, formats, converters, func(string) {})
+
+			Convey("Then each of them should get the page contents", func() {
+				So(err, ShouldBeNil)
+
+				saved, err := filesystem.Api().ReadFile(filepath.Join(path, chapter.Pages[0].Filename()))
+				So(err, ShouldBeNil)
+				So(string(saved), ShouldEqual, "page")
+
+				// pages are stored in zip archives as they are
+				archive, err := filesystem.Api().ReadFile(lo.Must(chapter.As(constant.FormatZIP).Path(false)))
+				So(err, ShouldBeNil)
+				So(bytes.Contains(archive, []byte("page")), ShouldBeTrue)
+				So(fallbacks, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the converter fails on a malformed image", func() {
+			formats := []string{constant.FormatPDF}
+			converters := lo.Must(converter.GetAll(formats))
+			viper.Set(key.FormatsFallback, []string{constant.FormatCBZ})
+			path, fallbacks, err := save(chapter, formats, converters, func(string) {})
+
+			Convey("Then the chapter should be saved in the fallback format", func() {
+				So(err, ShouldBeNil)
+				So(path, ShouldEqual, lo.Must(chapter.As(constant.FormatCBZ).Path(false)))
+				So(fallbacks, ShouldHaveLength, 1)
+				So(fallbacks[0].Format, ShouldEqual, constant.FormatPDF)
+				So(fallbacks[0].Fallback, ShouldEqual, constant.FormatCBZ)
+				So(fallbacks[0].Error, ShouldNotBeEmpty)
+
+				exists := lo.Must(filesystem.Api().Exists(chapter.As(constant.FormatPDF).PeekPath()))
+				So(exists, ShouldBeFalse)
+			})
+
+			Convey("Then it should fail without the fallback formats", func() {
+				viper.Set(key.FormatsFallback, []string{})
+				defer viper.Set(key.FormatsFallback, []string{constant.FormatCBZ})
+
+				_, _, err := save(chapter, formats, converters, func(string) {})
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When it is saved in the fallback format with the manifest", func() {
+			formats := []string{constant.FormatPDF}
+			converters := lo.Must(converter.GetAll(formats))
+			viper.Set(key.FormatsFallback, []string{constant.FormatCBZ})
+			viper.Set(key.MetadataManifest, true)
+			defer viper.Set(key.MetadataManifest, false)
+
+			_, _, err := save(chapter, formats, converters, func(string) {})
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be considered downloaded in the failed format", func() {
+				So(chapter.As(constant.FormatPDF).IsDownloaded(), ShouldBeTrue)
+				So(chapter.As(constant.FormatEPUB).IsDownloaded(), ShouldBeFalse)
+			})
+
+			Convey("Then the contents of its pages should be fingerprinted", func() {
+				tracked := lo.Must(manifest.Load(lo.Must(manga.Path(false))))
+				So(lo.Values(tracked.Files)[0].Chapter.Fingerprint, ShouldEqual, manifest.Fingerprint(chapter.Pages))
+				So(lo.Values(tracked.Files)[0].Chapter.Fingerprint, ShouldNotBeEmpty)
+			})
+		})
+	})
+}
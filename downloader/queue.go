@@ -0,0 +1,355 @@
+package downloader
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/metafates/gache"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// queue of the chapters being downloaded by their keys, see queueKey.
+// Chapters stay in it until they are downloaded or skipped, so that the interrupted downloads are resumed by mangal resume.
+// The pages of the queued chapters are staged as they are downloaded, see stagePage
+var queue = struct {
+	mutex sync.Mutex
+	store *gache.Cache[map[string]*Queued]
+}{
+	store: gache.New[map[string]*Queued](&gache.Options{
+		Path:       where.DownloadQueue(),
+		FileSystem: &filesystem.GacheFs{},
+	}),
+}
+
+// QueuedManga is the manga of the queued chapter
+type QueuedManga struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	ID   string `json:"id"`
+}
+
+// Queued is the chapter in the download queue, with what is needed to find it again in its source
+type Queued struct {
+	SourceID string      `json:"source_id"`
+	Manga    QueuedManga `json:"manga"`
+	Name     string      `json:"name"`
+	URL      string      `json:"url"`
+	ID       string      `json:"id"`
+	Index    int         `json:"index"`
+	Volume   string      `json:"volume,omitempty"`
+	// Converting is the format that was being written when the download was interrupted, its file is partial
+	Converting string `json:"converting,omitempty"`
+	// Error of the last attempt, if it has failed
+	Error string    `json:"error,omitempty"`
+	Added time.Time `json:"added"`
+}
+
+// Chapter returns the queued chapter of the given source, its pages are fetched again when it is downloaded
+func (q *Queued) Chapter(src source.Source) *source.Chapter {
+	manga := &source.Manga{
+		Name:   q.Manga.Name,
+		URL:    q.Manga.URL,
+		ID:     q.Manga.ID,
+		Source: src,
+	}
+
+	chapter := &source.Chapter{
+		Name:   q.Name,
+		URL:    q.URL,
+		ID:     q.ID,
+		Index:  q.Index,
+		Volume: q.Volume,
+		Manga:  manga,
+	}
+
+	manga.Chapters = []*source.Chapter{chapter}
+	return chapter
+}
+
+// queueKey of the chapter, empty if it can't be queued since its source is unknown
+func queueKey(chapter *source.Chapter) string {
+	if chapter.Manga == nil || chapter.Manga.Source == nil {
+		return ""
+	}
+
+	return memo.ChapterKey(chapter.Manga.Source.ID(), chapter)
+}
+
+func queued() (map[string]*Queued, error) {
+	cached, expired, err := queue.store.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if expired || cached == nil {
+		return make(map[string]*Queued), nil
+	}
+
+	return cached, nil
+}
+
+// Enqueue adds the chapters to the download queue, unless they are queued already.
+// Nothing is queued if the resuming is disabled, see key.DownloaderResume
+func Enqueue(chapters ...*source.Chapter) error {
+	if !viper.GetBool(key.DownloaderResume) {
+		return nil
+	}
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	chapters = filterQueueable(chapters)
+	if len(chapters) == 0 {
+		return nil
+	}
+
+	entries, err := queued()
+	if err != nil {
+		return err
+	}
+
+	var (
+		added bool
+		// chapters queued together keep their order, see Queue
+		now = time.Now().UTC()
+	)
+
+	for _, chapter := range chapters {
+		k := queueKey(chapter)
+		if _, ok := entries[k]; ok {
+			continue
+		}
+
+		entries[k] = &Queued{
+			SourceID: chapter.Manga.Source.ID(),
+			Manga: QueuedManga{
+				Name: chapter.Manga.Name,
+				URL:  chapter.Manga.URL,
+				ID:   chapter.Manga.ID,
+			},
+			Name:   chapter.Name,
+			URL:    chapter.URL,
+			ID:     chapter.ID,
+			Index:  chapter.Index,
+			Volume: chapter.Volume,
+			Added:  now,
+		}
+
+		added = true
+	}
+
+	if !added {
+		return nil
+	}
+
+	return queue.store.Set(entries)
+}
+
+func filterQueueable(chapters []*source.Chapter) []*source.Chapter {
+	var queueable []*source.Chapter
+	for _, chapter := range chapters {
+		if queueKey(chapter) != "" {
+			queueable = append(queueable, chapter)
+		}
+	}
+
+	return queueable
+}
+
+// Queue returns the queued chapters, oldest first and in the order of their mangas and indexes
+func Queue() ([]*Queued, error) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	entries, err := queued()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Queued, 0, len(entries))
+	// entries are shared with the store, so the copies are returned
+	for _, entry := range entries {
+		copied := *entry
+		list = append(list, &copied)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if !a.Added.Equal(b.Added) {
+			return a.Added.Before(b.Added)
+		}
+
+		if a.Manga.URL != b.Manga.URL {
+			return a.Manga.URL < b.Manga.URL
+		}
+
+		return a.Index < b.Index
+	})
+
+	return list, nil
+}
+
+// Forget empties the download queue and removes the staged pages
+func Forget() error {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if err := queue.store.Set(make(map[string]*Queued)); err != nil {
+		return err
+	}
+
+	return filesystem.Api().RemoveAll(where.Staging())
+}
+
+// updateQueued changes the queued entry of the chapter, if it is queued
+func updateQueued(chapter *source.Chapter, change func(entry *Queued)) {
+	k := queueKey(chapter)
+	if k == "" || !viper.GetBool(key.DownloaderResume) {
+		return
+	}
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	entries, err := queued()
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+
+	entry, ok := entries[k]
+	if !ok {
+		return
+	}
+
+	change(entry)
+	if err = queue.store.Set(entries); err != nil {
+		log.Warn(err)
+	}
+}
+
+// queuedEntry returns the copy of the queued entry of the chapter, if it is queued
+func queuedEntry(chapter *source.Chapter) (Queued, bool) {
+	k := queueKey(chapter)
+	if k == "" || !viper.GetBool(key.DownloaderResume) {
+		return Queued{}, false
+	}
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	entries, err := queued()
+	if err != nil {
+		log.Warn(err)
+		return Queued{}, false
+	}
+
+	entry, ok := entries[k]
+	if !ok {
+		return Queued{}, false
+	}
+
+	return *entry, true
+}
+
+// dequeue removes the chapter from the queue along with its staged pages
+func dequeue(chapter *source.Chapter) {
+	k := queueKey(chapter)
+	if k == "" || !viper.GetBool(key.DownloaderResume) {
+		return
+	}
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	entries, err := queued()
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+
+	if _, ok := entries[k]; ok {
+		delete(entries, k)
+		if err = queue.store.Set(entries); err != nil {
+			log.Warn(err)
+		}
+	}
+
+	if err = filesystem.Api().RemoveAll(stagingOf(k)); err != nil {
+		log.Warn(err)
+	}
+}
+
+// stagingOf returns the directory with the staged pages of the chapter by its key
+func stagingOf(k string) string {
+	sum := sha1.Sum([]byte(k))
+	return filepath.Join(where.Staging(), hex.EncodeToString(sum[:8]))
+}
+
+// stagePage saves the downloaded page of the queued chapter, so that it is not downloaded again if the download is resumed.
+// The page is written to the temporary file first, so that the interrupted write is not mistaken for the page
+func stagePage(page *source.Page) {
+	k := queueKey(page.Chapter)
+	if k == "" || page.Contents == nil || !viper.GetBool(key.DownloaderResume) {
+		return
+	}
+
+	dir := stagingOf(k)
+	if err := filesystem.Api().MkdirAll(dir, os.ModePerm); err != nil {
+		log.Warn(err)
+		return
+	}
+
+	var (
+		path    = filepath.Join(dir, strconv.Itoa(page.Index))
+		partial = path + ".part"
+	)
+
+	if err := filesystem.Api().WriteFile(partial, page.Contents.Bytes(), os.ModePerm); err != nil {
+		log.Warn(err)
+		return
+	}
+
+	if err := filesystem.Api().Rename(partial, path); err != nil {
+		log.Warn(err)
+	}
+}
+
+// restorePages sets the contents of the pages staged by the interrupted download of the chapter.
+// The number of the restored pages is returned
+func restorePages(chapter *source.Chapter) (restored int) {
+	k := queueKey(chapter)
+	if k == "" || !viper.GetBool(key.DownloaderResume) {
+		return 0
+	}
+
+	dir := stagingOf(k)
+	for _, page := range chapter.Pages {
+		if page == nil || page.Contents != nil {
+			continue
+		}
+
+		contents, err := filesystem.Api().ReadFile(filepath.Join(dir, strconv.Itoa(page.Index)))
+		if err != nil || len(contents) == 0 {
+			continue
+		}
+
+		page.Contents = bytes.NewBuffer(contents)
+		page.Size = uint64(len(contents))
+		restored++
+	}
+
+	return restored
+}
@@ -0,0 +1,215 @@
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// queueSource serves the pages of its chapters from the test server
+type queueSource struct {
+	server *httptest.Server
+	pages  int
+}
+
+func (*queueSource) Name() string {
+	return "Queue"
+}
+
+func (*queueSource) ID() string {
+	return "Queue test"
+}
+
+func (*queueSource) Search(string) ([]*source.Manga, error) {
+	return nil, nil
+}
+
+func (*queueSource) ChaptersOf(*source.Manga) ([]*source.Chapter, error) {
+	return nil, nil
+}
+
+func (s *queueSource) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	chapter.Pages = lo.Times(s.pages, func(i int) *source.Page {
+		return &source.Page{
+			URL:       fmt.Sprintf("%s/%s/%d.png", s.server.URL, chapter.ID, i+1),
+			Index:     i + 1,
+			Extension: ".png",
+			Chapter:   chapter,
+		}
+	})
+
+	return chapter.Pages, nil
+}
+
+func queuedChapters(src source.Source, count int) []*source.Chapter {
+	manga := &source.Manga{Name: "Berserk", URL: "https://example.com/berserk", ID: "berserk", Source: src}
+	manga.Chapters = lo.Times(count, func(i int) *source.Chapter {
+		return &source.Chapter{
+			Name:  fmt.Sprintf("Chapter %d", i+1),
+			URL:   fmt.Sprintf("https://example.com/berserk/%d", i+1),
+			ID:    fmt.Sprintf("%d", i+1),
+			Index: i + 1,
+			Manga: manga,
+		}
+	})
+
+	return manga.Chapters
+}
+
+func TestQueue(t *testing.T) {
+	Convey("Given an empty download queue", t, func() {
+		filesystem.SetMemMapFs()
+		viper.Set(key.DownloaderResume, true)
+		So(Forget(), ShouldBeNil)
+
+		src := &queueSource{}
+		chapters := queuedChapters(src, 3)
+
+		Convey("When the chapters are queued twice", func() {
+			So(Enqueue(chapters[2], chapters[0]), ShouldBeNil)
+			So(Enqueue(chapters...), ShouldBeNil)
+
+			Convey("Then each of them should be queued once, in the order they were queued", func() {
+				queued := lo.Must(Queue())
+				So(queued, ShouldHaveLength, 3)
+				So(queued[0].Name, ShouldEqual, "Chapter 1")
+				So(queued[1].Name, ShouldEqual, "Chapter 3")
+				So(queued[2].Name, ShouldEqual, "Chapter 2")
+			})
+
+			Convey("Then the chapters should be found again in their source", func() {
+				chapter := lo.Must(Queue())[0].Chapter(src)
+				So(chapter.URL, ShouldEqual, chapters[0].URL)
+				So(chapter.Manga.ID, ShouldEqual, "berserk")
+				So(chapter.Source(), ShouldEqual, src)
+			})
+		})
+
+		Convey("When the resuming is disabled", func() {
+			viper.Set(key.DownloaderResume, false)
+			defer viper.Set(key.DownloaderResume, true)
+
+			So(Enqueue(chapters...), ShouldBeNil)
+
+			Convey("Then nothing should be queued", func() {
+				viper.Set(key.DownloaderResume, true)
+				So(lo.Must(Queue()), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the pages of the queued chapter are staged", func() {
+			So(Enqueue(chapters[0]), ShouldBeNil)
+			stagePage(&source.Page{Index: 1, Contents: bytes.NewBufferString("first"), Chapter: chapters[0]})
+
+			fresh := queuedChapters(src, 1)[0]
+			fresh.Pages = []*source.Page{{Index: 1, Chapter: fresh}, {Index: 2, Chapter: fresh}}
+
+			Convey("Then they should be restored for the same chapter", func() {
+				So(restorePages(fresh), ShouldEqual, 1)
+				So(fresh.Pages[0].Contents.String(), ShouldEqual, "first")
+				So(fresh.Pages[1].Contents, ShouldBeNil)
+			})
+
+			Convey("Then they should be removed with the chapter", func() {
+				dequeue(chapters[0])
+				So(lo.Must(Queue()), ShouldBeEmpty)
+				So(restorePages(fresh), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestResume(t *testing.T) {
+	Convey("Given the chapter whose second page fails to download", t, func() {
+		filesystem.SetMemMapFs()
+		viper.Set(key.DownloaderResume, true)
+		viper.Set(key.FormatsUse, constant.FormatCBZ)
+		viper.Set(key.MetadataFetchAnilist, false)
+		viper.Set(key.DownloaderDownloadCover, false)
+		viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+		So(Forget(), ShouldBeNil)
+
+		var (
+			mutex    sync.Mutex
+			broken   = true
+			requests = make(map[string]int)
+		)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			requests[r.URL.Path]++
+			if broken && strings.HasSuffix(r.URL.Path, "/2.png") {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			_, _ = w.Write([]byte("page " + r.URL.Path))
+		}))
+		defer server.Close()
+
+		src := &queueSource{server: server, pages: 3}
+		chapter := queuedChapters(src, 1)[0]
+
+		_, _, err := downloadChapter(chapter, where.Downloads(), func(string) {})
+		So(err, ShouldNotBeNil)
+
+		Convey("Then the failed chapter should stay queued with its error", func() {
+			queued := lo.Must(Queue())
+			So(queued, ShouldHaveLength, 1)
+			So(queued[0].Error, ShouldContainSubstring, "502")
+		})
+
+		Convey("When the download is resumed", func() {
+			broken = false
+			resumed := lo.Must(Queue())[0].Chapter(src)
+			path, _, err := downloadChapter(resumed, where.Downloads(), func(string) {})
+
+			Convey("Then the pages downloaded before should not be downloaded again", func() {
+				So(err, ShouldBeNil)
+				So(requests["/1/1.png"], ShouldEqual, 1)
+				So(requests["/1/2.png"], ShouldEqual, 2)
+				So(lo.Must(filesystem.Api().Exists(path)), ShouldBeTrue)
+			})
+
+			Convey("Then the chapter should leave the queue", func() {
+				So(lo.Must(Queue()), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the conversion of the resumed chapter was interrupted", func() {
+			broken = false
+			resumed := lo.Must(Queue())[0].Chapter(src)
+
+			path := resumed.As(constant.FormatCBZ).PeekPath()
+			So(filesystem.Api().MkdirAll(filepath.Dir(path), 0755), ShouldBeNil)
+			So(filesystem.Api().WriteFile(path, []byte("partial"), 0644), ShouldBeNil)
+			updateQueued(resumed, func(entry *Queued) {
+				entry.Converting = constant.FormatCBZ
+			})
+
+			_, _, err := downloadChapter(resumed, where.Downloads(), func(string) {})
+
+			Convey("Then the partial archive should be converted again", func() {
+				So(err, ShouldBeNil)
+				archive := lo.Must(filesystem.Api().ReadFile(path))
+				So(string(archive), ShouldNotEqual, "partial")
+				So(bytes.HasPrefix(archive, []byte("PK")), ShouldBeTrue)
+			})
+		})
+	})
+}
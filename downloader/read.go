@@ -6,11 +6,13 @@ import (
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/converter"
 	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/open"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/translator"
 	"github.com/spf13/viper"
 )
 
@@ -33,7 +35,7 @@ func Read(chapter *source.Chapter, progress func(string)) error {
 
 	log.Infof("downloading %s for reading. Provider is %s", chapter.Name, chapter.Source().ID())
 	log.Infof("getting pages of %s", chapter.Name)
-	progress("Getting pages")
+	progress(i18n.T("Getting pages"))
 	pages, err := chapter.Source().PagesOf(chapter)
 	if err != nil {
 		log.Error(err)
@@ -46,21 +48,33 @@ func Read(chapter *source.Chapter, progress func(string)) error {
 		return err
 	}
 
-	log.Info("getting " + viper.GetString(key.FormatsUse) + " converter")
-	conv, err := converter.Get(viper.GetString(key.FormatsUse))
+	// chapters are read in the primary format only
+	format := source.PrimaryFormat()
+	log.Info("getting " + format + " converter")
+	conv, err := converter.Get(format)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	log.Info("converting " + viper.GetString(key.FormatsUse))
-	progress(fmt.Sprintf(
+	// chapter to convert, history is still saved for the original one
+	converted := chapter
+	if viper.GetBool(key.TranslatorEnable) {
+		converted, err = translator.Chapter(chapter, progress)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	log.Info("converting " + format)
+	progress(i18n.T(
 		"Converting %d pages to %s %s",
 		len(pages),
-		style.Fg(color.Yellow)(viper.GetString(key.FormatsUse)),
+		style.Fg(color.Yellow)(format),
 		style.Faint(chapter.SizeHuman())),
 	)
-	path, err := conv.SaveTemp(chapter)
+	path, err := conv.SaveTemp(converted)
 	if err != nil {
 		log.Error(err)
 		return err
@@ -72,7 +86,7 @@ func Read(chapter *source.Chapter, progress func(string)) error {
 		return err
 	}
 
-	progress("Done")
+	progress(i18n.T("Done"))
 	return nil
 }
 
@@ -93,23 +107,25 @@ func openRead(path string, chapter *source.Chapter, progress func(string)) error
 		err    error
 	)
 
-	switch viper.GetString(key.FormatsUse) {
+	switch source.PrimaryFormat() {
 	case constant.FormatPDF:
 		reader = viper.GetString(key.ReaderPDF)
 	case constant.FormatCBZ:
 		reader = viper.GetString(key.ReaderCBZ)
 	case constant.FormatZIP:
 		reader = viper.GetString(key.ReaderZIP)
+	case constant.FormatEPUB:
+		reader = viper.GetString(key.ReaderEPUB)
 	case constant.FormatPlain:
 		reader = viper.GetString(key.RaderPlain)
 	}
 
 	if reader != "" {
 		log.Info("opening with " + reader)
-		progress(fmt.Sprintf("Opening %s", reader))
+		progress(i18n.T("Opening %s", reader))
 	} else {
 		log.Info("no reader specified. opening with default")
-		progress("Opening")
+		progress(i18n.T("Opening"))
 	}
 
 	err = open.RunWith(path, reader)
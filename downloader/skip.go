@@ -0,0 +1,55 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrSkipped is returned when the download of the chapter was skipped with Skip
+var ErrSkipped = errors.New("skipped")
+
+// downloading are the cancel functions of the chapters being downloaded by their ids
+var downloading = struct {
+	mutex   sync.Mutex
+	next    int
+	cancels map[int]context.CancelFunc
+}{
+	cancels: make(map[int]context.CancelFunc),
+}
+
+// Skip cancels the chapters being downloaded, so that batches move on to their next chapters.
+// It reports whether there was anything to skip
+func Skip() bool {
+	downloading.mutex.Lock()
+	defer downloading.mutex.Unlock()
+
+	skipped := len(downloading.cancels) > 0
+	for id, cancel := range downloading.cancels {
+		cancel()
+		delete(downloading.cancels, id)
+	}
+
+	return skipped
+}
+
+// skippable returns the context of a chapter download that is canceled by Skip,
+// and the function to call once the download is finished
+func skippable() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	downloading.mutex.Lock()
+	defer downloading.mutex.Unlock()
+
+	id := downloading.next
+	downloading.next++
+	downloading.cancels[id] = cancel
+
+	return ctx, func() {
+		downloading.mutex.Lock()
+		defer downloading.mutex.Unlock()
+
+		delete(downloading.cancels, id)
+		cancel()
+	}
+}
@@ -0,0 +1,348 @@
+// Package federation merges the local library with the libraries of the mangal servers, e.g. a NAS and a laptop,
+// and pulls their downloaded chapters to the local one.
+package federation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/remote"
+	"github.com/metafates/mangal/server"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Local is the location of the local library
+const Local = "local"
+
+// Chapter of the merged library
+type Chapter struct {
+	Name  string `json:"name"`
+	Index int    `json:"index"`
+	// Paths of the chapter files relative to the manga directories, by location
+	Paths map[string]string `json:"paths"`
+	// Size of the file in bytes, as it is on the first location that has it
+	Size int64 `json:"size"`
+	// url of the chapter on its source, kept in the manifest of the pulled chapter
+	url string
+}
+
+// IsLocal reports whether the chapter is in the local library
+func (c *Chapter) IsLocal() bool {
+	_, ok := c.Paths[Local]
+	return ok
+}
+
+// Locations returns the locations that have the chapter, the local one first
+func (c *Chapter) Locations() []string {
+	return locationsOf(c.Paths)
+}
+
+// Manga of the merged library
+type Manga struct {
+	Name string `json:"name"`
+	// Directories of the manga by location
+	Directories map[string]string `json:"directories"`
+	// Chapters of all the locations, by index
+	Chapters []*Chapter `json:"chapters"`
+}
+
+// Locations returns the locations that have the manga, the local one first
+func (m *Manga) Locations() []string {
+	return locationsOf(m.Directories)
+}
+
+// Missing returns the number of the chapters that are not in the local library
+func (m *Manga) Missing() int {
+	var missing int
+	for _, chapter := range m.Chapters {
+		if !chapter.IsLocal() {
+			missing++
+		}
+	}
+
+	return missing
+}
+
+// Pulled records the chapter pulled to the path as local
+func (m *Manga) Pulled(chapter *Chapter, path string) {
+	relative, err := filepath.Rel(where.Downloads(), path)
+	if err != nil {
+		return
+	}
+
+	directory, file, _ := strings.Cut(filepath.ToSlash(relative), "/")
+	m.Directories[Local] = directory
+	chapter.Paths[Local] = file
+}
+
+func locationsOf[T any](byLocation map[string]T) []string {
+	locations := make([]string, 0, len(byLocation))
+	for location := range byLocation {
+		locations = append(locations, location)
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i] == Local || locations[j] == Local {
+			return locations[i] == Local
+		}
+
+		return locations[i] < locations[j]
+	})
+
+	return locations
+}
+
+// Library is the local library merged with the libraries of the servers
+type Library struct {
+	Mangas []*Manga `json:"mangas"`
+	// Errors of the servers that could not be listed, by location. Their mangas are left out
+	Errors  map[string]error `json:"-"`
+	clients map[string]*remote.Client
+}
+
+// Load lists the local library and the libraries of the servers, concurrently, and merges them.
+// Mangas and chapters are matched by their names, case-insensitive.
+// Unreachable servers don't fail it, see Library.Errors
+func Load(clients []*remote.Client) (*Library, error) {
+	l := &Library{
+		Errors:  make(map[string]error),
+		clients: make(map[string]*remote.Client, len(clients)),
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		remotes = make(map[string][]*server.LibraryManga, len(clients))
+	)
+
+	for _, client := range clients {
+		l.clients[client.String()] = client
+
+		wg.Add(1)
+		go func(client *remote.Client) {
+			defer wg.Done()
+
+			mangas, err := client.LibraryChapters()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				log.Warn(err)
+				l.Errors[client.String()] = err
+				return
+			}
+
+			remotes[client.String()] = mangas
+		}(client)
+	}
+
+	local, err := localLibrary()
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	l.Mangas = merge(local, remotes)
+	return l, nil
+}
+
+// localLibrary returns the local mangas as if they were listed by a server
+func localLibrary() ([]*server.LibraryManga, error) {
+	mangas, err := library.Mangas()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	local := make([]*server.LibraryManga, 0, len(mangas))
+	for _, manga := range mangas {
+		chapters, err := manga.Chapters()
+		if err != nil {
+			log.Warn(err)
+		}
+
+		libraryManga := &server.LibraryManga{
+			MangaRef:  server.MangaRef{Name: manga.Name},
+			Directory: filepath.Base(manga.Path),
+		}
+
+		for _, chapter := range chapters {
+			libraryManga.Chapters = append(libraryManga.Chapters, &server.LibraryChapter{
+				Name:  chapter.Name,
+				Index: chapter.Index,
+				Path:  filepath.ToSlash(chapter.Path),
+				URL:   chapter.URL,
+			})
+		}
+
+		local = append(local, libraryManga)
+	}
+
+	return local, nil
+}
+
+// merge merges the local mangas with the mangas of the servers, by location
+func merge(local []*server.LibraryManga, remotes map[string][]*server.LibraryManga) []*Manga {
+	var (
+		mangas = make(map[string]*Manga)
+		// chapters of the mangas by their names
+		chapters = make(map[string]map[string]*Chapter)
+		add      = func(location string, libraryManga *server.LibraryManga) {
+			name := strings.ToLower(libraryManga.Name)
+			manga, ok := mangas[name]
+			if !ok {
+				manga = &Manga{Name: libraryManga.Name, Directories: make(map[string]string)}
+				mangas[name] = manga
+				chapters[name] = make(map[string]*Chapter)
+			}
+
+			manga.Directories[location] = libraryManga.Directory
+
+			for _, libraryChapter := range libraryManga.Chapters {
+				chapter, ok := chapters[name][strings.ToLower(libraryChapter.Name)]
+				if !ok {
+					chapter = &Chapter{
+						Name:  libraryChapter.Name,
+						Index: libraryChapter.Index,
+						Size:  libraryChapter.Size,
+						Paths: make(map[string]string),
+						url:   libraryChapter.URL,
+					}
+					manga.Chapters = append(manga.Chapters, chapter)
+					chapters[name][strings.ToLower(libraryChapter.Name)] = chapter
+				}
+
+				chapter.Paths[location] = libraryChapter.Path
+			}
+		}
+	)
+
+	for _, manga := range local {
+		add(Local, manga)
+	}
+
+	// servers are merged in the same order every time, their chapters keep the indexes of the first one
+	locations := make([]string, 0, len(remotes))
+	for location := range remotes {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	for _, location := range locations {
+		for _, manga := range remotes[location] {
+			add(location, manga)
+		}
+	}
+
+	merged := make([]*Manga, 0, len(mangas))
+	for _, manga := range mangas {
+		sort.SliceStable(manga.Chapters, func(i, j int) bool {
+			return manga.Chapters[i].Index < manga.Chapters[j].Index
+		})
+
+		merged = append(merged, manga)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return util.NaturalLess(merged[i].Name, merged[j].Name)
+	})
+
+	return merged
+}
+
+// Pull copies the chapter from the first server that has it to the local library, into the directory of the manga.
+// Returns the path of the local chapter file, nothing is copied if the chapter is local already.
+// The library is not changed, so that the chapters can be pulled in the background, see Manga.Pulled
+func (l *Library) Pull(manga *Manga, chapter *Chapter) (string, error) {
+	downloads := where.Downloads()
+
+	if chapter.IsLocal() {
+		return filepath.Join(downloads, manga.Directories[Local], filepath.FromSlash(chapter.Paths[Local])), nil
+	}
+
+	// the error of the last server tried, if there were any
+	err := fmt.Errorf("%s of %s is not available on any server", chapter.Name, manga.Name)
+	for _, location := range chapter.Locations() {
+		client, ok := l.clients[location]
+		if !ok {
+			continue
+		}
+
+		// the manga keeps its local directory, if it has one
+		directory, ok := manga.Directories[Local]
+		if !ok {
+			directory = manga.Directories[location]
+		}
+
+		var path string
+		if path, err = pull(client, downloads, directory, manga.Directories[location], chapter.Paths[location]); err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		mangaPath := filepath.Join(downloads, directory)
+		err = manifest.TrackFile(mangaPath, path, &manifest.Chapter{
+			Manga: manga.Name,
+			Name:  chapter.Name,
+			Index: chapter.Index,
+			URL:   chapter.url,
+		})
+		if err != nil {
+			log.Warn(err)
+		}
+
+		return path, nil
+	}
+
+	return "", err
+}
+
+// pull copies the chapter file at the path of the remote manga directory to the local manga directory.
+// The file is written next to its destination first, so that the interrupted pull leaves no broken chapter
+func pull(client *remote.Client, downloads, directory, remoteDirectory, path string) (string, error) {
+	target := filepath.Join(downloads, directory, filepath.FromSlash(path))
+
+	// the paths come from the server, they must stay inside the manga directory
+	if directory == "" || directory == "." || directory == ".." || directory != filepath.Base(directory) {
+		return "", fmt.Errorf("%s: invalid manga directory %s", client, directory)
+	}
+
+	if relative, err := filepath.Rel(filepath.Join(downloads, directory), target); err != nil || strings.HasPrefix(relative, "..") {
+		return "", fmt.Errorf("%s: invalid chapter path %s", client, path)
+	}
+
+	if err := filesystem.Api().MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	partial := target + ".part"
+	file, err := filesystem.Api().Create(partial)
+	if err != nil {
+		return "", err
+	}
+
+	err = client.Pull(remoteDirectory, path, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = filesystem.Api().Remove(partial)
+		return "", err
+	}
+
+	return target, filesystem.Api().Rename(partial, target)
+}
@@ -0,0 +1,118 @@
+package federation
+
+import (
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/remote"
+	"github.com/metafates/mangal/server"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func libraryManga(name, directory string, chapters ...string) *server.LibraryManga {
+	return &server.LibraryManga{
+		MangaRef:  server.MangaRef{Name: name},
+		Directory: directory,
+		Chapters: lo.Map(chapters, func(chapter string, i int) *server.LibraryChapter {
+			return &server.LibraryChapter{Name: chapter, Index: i + 1, Path: "[" + chapter + "].cbz"}
+		}),
+	}
+}
+
+func TestMerge(t *testing.T) {
+	Convey("Given the local library and the libraries of two servers", t, func() {
+		local := []*server.LibraryManga{libraryManga("Berserk", "Berserk", "Chapter 1")}
+		remotes := map[string][]*server.LibraryManga{
+			"nas:6969":    {libraryManga("berserk", "berserk", "Chapter 1", "chapter 2"), libraryManga("Vagabond", "Vagabond", "Chapter 1")},
+			"laptop:6969": {libraryManga("Berserk", "Berserk", "Chapter 2", "Chapter 3")},
+		}
+
+		Convey("When they are merged", func() {
+			mangas := merge(local, remotes)
+
+			Convey("Then the mangas should be matched by their names", func() {
+				So(lo.Map(mangas, func(m *Manga, _ int) string { return m.Name }), ShouldResemble, []string{"Berserk", "Vagabond"})
+				So(mangas[0].Locations(), ShouldResemble, []string{Local, "laptop:6969", "nas:6969"})
+			})
+
+			Convey("Then each chapter should know where it is", func() {
+				berserk := mangas[0]
+				So(lo.Map(berserk.Chapters, func(c *Chapter, _ int) string { return c.Name }), ShouldResemble, []string{"Chapter 1", "Chapter 2", "Chapter 3"})
+				So(berserk.Chapters[0].Locations(), ShouldResemble, []string{Local, "nas:6969"})
+				So(berserk.Chapters[1].Locations(), ShouldResemble, []string{"laptop:6969", "nas:6969"})
+				So(berserk.Missing(), ShouldEqual, 2)
+				So(mangas[1].Missing(), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestPull(t *testing.T) {
+	Convey("Given a manga of the server that is not in the local library", t, func() {
+		filesystem.SetMemMapFs()
+
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/files" || r.URL.Query().Get("path") != "[Chapter 2].cbz" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			_, _ = w.Write([]byte("pages"))
+		}))
+		defer httpServer.Close()
+
+		address := strings.TrimPrefix(httpServer.URL, "http://")
+		client := lo.Must(remote.New(address))
+
+		l := &Library{
+			Mangas:  merge(nil, map[string][]*server.LibraryManga{address: {libraryManga("Pluto", "Pluto", "Chapter 1", "Chapter 2")}}),
+			clients: map[string]*remote.Client{address: client},
+		}
+		pluto := l.Mangas[0]
+
+		Convey("When its chapter is pulled", func() {
+			path, err := l.Pull(pluto, pluto.Chapters[1])
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be in the local library", func() {
+				So(path, ShouldEqual, filepath.Join(where.Downloads(), "Pluto", "[Chapter 2].cbz"))
+				So(string(lo.Must(filesystem.Api().ReadFile(path))), ShouldEqual, "pages")
+
+				chapters := lo.Must(lo.Must(library.Named("Pluto"))[0].Chapters())
+				So(chapters, ShouldHaveLength, 1)
+				So(chapters[0].Index, ShouldEqual, 2)
+			})
+
+			Convey("Then it should be recorded as local", func() {
+				pluto.Pulled(pluto.Chapters[1], path)
+				So(pluto.Chapters[1].IsLocal(), ShouldBeTrue)
+				So(pluto.Missing(), ShouldEqual, 1)
+				So(lo.Must(l.Pull(pluto, pluto.Chapters[1])), ShouldEqual, path)
+			})
+		})
+
+		Convey("When the server fails to send the chapter", func() {
+			_, err := l.Pull(pluto, pluto.Chapters[0])
+
+			Convey("Then nothing should be left in the local library", func() {
+				So(err, ShouldNotBeNil)
+				So(lo.Must(filesystem.Api().Exists(filepath.Join(where.Downloads(), "Pluto", "[Chapter 1].cbz.part"))), ShouldBeFalse)
+			})
+		})
+
+		Convey("When the server gives the path outside of the manga directory", func() {
+			pluto.Chapters[1].Paths[address] = "../../config.toml"
+			_, err := l.Pull(pluto, pluto.Chapters[1])
+
+			Convey("Then it should not be pulled", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -3,6 +3,7 @@ package history
 import (
 	"fmt"
 	"github.com/metafates/mangal/source"
+	"time"
 )
 
 type SavedChapter struct {
@@ -15,6 +16,8 @@ type SavedChapter struct {
 	ID                 string `json:"id"`
 	Index              int    `json:"index"`
 	MangaID            string `json:"manga_id"`
+	// Time when the chapter was read, in UTC
+	Time time.Time `json:"time"`
 }
 
 func (c *SavedChapter) encode() string {
@@ -35,6 +38,7 @@ func newSavedChapter(chapter *source.Chapter) *SavedChapter {
 		ID:                 chapter.ID,
 		MangaID:            chapter.Manga.ID,
 		MangaChaptersTotal: len(chapter.Manga.Chapters),
-		Index:              int(chapter.Index),
+		Index:              chapter.Index,
+		Time:               time.Now().UTC(),
 	}
-}
\ No newline at end of file
+}
@@ -7,20 +7,60 @@ import (
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/stats"
 	"github.com/metafates/mangal/where"
 	"github.com/spf13/viper"
+	"strings"
+	"sync"
+	"time"
 )
 
-var cacher = gache.New[map[string]*SavedChapter](
-	&gache.Options{
-		Path:       where.History(),
-		FileSystem: &filesystem.GacheFs{},
-	},
+// Store is a history file
+type Store struct {
+	cacher *gache.Cache[map[string]*SavedChapter]
+	// mutex guards the changes of the history, the server saves the progress of the user concurrently
+	mutex sync.Mutex
+}
+
+var (
+	// stores by the paths of their files, so that the concurrent changes of one file are serialized
+	stores      = make(map[string]*Store)
+	storesMutex sync.Mutex
 )
 
+// NewStore returns a history store backed by the file at the given path.
+// The same store is returned for the same path
+func NewStore(path string) *Store {
+	storesMutex.Lock()
+	defer storesMutex.Unlock()
+
+	if store, ok := stores[path]; ok {
+		return store
+	}
+
+	store := &Store{
+		cacher: gache.New[map[string]*SavedChapter](
+			&gache.Options{
+				Path:       path,
+				FileSystem: &filesystem.GacheFs{},
+			},
+		),
+	}
+
+	stores[path] = store
+	return store
+}
+
+var defaultStore = NewStore(where.History())
+
+// Default returns the history store of the local user
+func Default() *Store {
+	return defaultStore
+}
+
 // Get returns all chapters from the history file
-func Get() (chapters map[string]*SavedChapter, err error) {
-	cached, expired, err := cacher.Get()
+func (s *Store) Get() (chapters map[string]*SavedChapter, err error) {
+	cached, expired, err := s.cacher.Get()
 
 	if err != nil {
 		return nil, err
@@ -30,9 +70,55 @@ func Get() (chapters map[string]*SavedChapter, err error) {
 		return make(map[string]*SavedChapter), nil
 	}
 
+	// the older versions saved the local times
+	for _, chapter := range cached {
+		chapter.Time = chapter.Time.UTC()
+	}
+
 	return cached, nil
 }
 
+// Put saves the chapter to the history file
+func (s *Store) Put(chapter *SavedChapter) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	saved, err := s.Get()
+	if err != nil {
+		return err
+	}
+
+	chapter.Time = chapter.Time.UTC()
+	saved[chapter.encode()] = chapter
+
+	// history is kept within the policy as it is saved, so that it doesn't grow on the long-lived installs
+	if policy := DefaultPolicy(); !policy.IsEmpty() {
+		prune(saved, policy, time.Now().UTC())
+	}
+
+	return s.cacher.Set(saved)
+}
+
+// Remove removes the chapter from the history file
+func (s *Store) Remove(chapter *SavedChapter) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	saved, err := s.Get()
+	if err != nil {
+		return err
+	}
+
+	delete(saved, chapter.encode())
+
+	return s.cacher.Set(saved)
+}
+
+// Get returns all chapters from the history file
+func Get() (chapters map[string]*SavedChapter, err error) {
+	return defaultStore.Get()
+}
+
 // Save saves the chapter to the history file
 func Save(chapter *source.Chapter) error {
 	if viper.GetBool(key.AnilistEnable) {
@@ -45,25 +131,33 @@ func Save(chapter *source.Chapter) error {
 		}()
 	}
 
-	saved, err := Get()
-	if err != nil {
-		return err
+	if err := stats.RecordRead(chapter); err != nil {
+		log.Warn(err)
 	}
 
-	savedChapter := newSavedChapter(chapter)
-	saved[savedChapter.encode()] = savedChapter
-
-	return cacher.Set(saved)
+	return defaultStore.Put(newSavedChapter(chapter))
 }
 
 // Remove removes the chapter from the history file
 func Remove(chapter *SavedChapter) error {
+	return defaultStore.Remove(chapter)
+}
+
+// IsRead returns a function that reports whether the chapter is not newer than the last read one.
+// History is read once, when the function is created
+func IsRead() func(manga string, index int) bool {
 	saved, err := Get()
 	if err != nil {
-		return err
+		log.Warn(err)
 	}
 
-	delete(saved, chapter.encode())
+	return func(manga string, index int) bool {
+		for _, chapter := range saved {
+			if strings.EqualFold(chapter.MangaName, manga) && index <= chapter.Index {
+				return true
+			}
+		}
 
-	return cacher.Set(saved)
+		return false
+	}
 }
@@ -5,7 +5,9 @@ import (
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/source"
 	. "github.com/smartystreets/goconvey/convey"
+	"sync"
 	"testing"
+	"time"
 )
 
 type testSource struct{}
@@ -64,6 +66,38 @@ func TestHistory(t *testing.T) {
 					So(len(chapters), ShouldBeGreaterThan, 0)
 					So(chapters[fmt.Sprintf("%s (%s)", chapter.Manga.Name, chapter.Source().ID())].Name, ShouldEqual, chapter.Name)
 				})
+
+				Convey("And the time should be in UTC", func() {
+					chapters, err := Get()
+					So(err, ShouldBeNil)
+					So(chapters[fmt.Sprintf("%s (%s)", chapter.Manga.Name, chapter.Source().ID())].Time.Location(), ShouldEqual, time.UTC)
+				})
+			})
+		})
+	})
+}
+
+func TestStoreConcurrency(t *testing.T) {
+	Convey("Given the stores of one history file", t, func() {
+		first, second := NewStore("/concurrent/history.json"), NewStore("/concurrent/history.json")
+		So(first, ShouldEqual, second)
+
+		Convey("When the chapters are saved concurrently", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(index int) {
+					defer wg.Done()
+					_ = first.Put(&SavedChapter{MangaName: fmt.Sprintf("Manga %d", index), SourceID: "test", Index: index, Time: time.Now()})
+				}(i)
+			}
+
+			wg.Wait()
+
+			Convey("Then none of them should be lost", func() {
+				saved, err := second.Get()
+				So(err, ShouldBeNil)
+				So(saved, ShouldHaveLength, 20)
 			})
 		})
 	})
@@ -0,0 +1,124 @@
+package history
+
+import (
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+	"sort"
+	"time"
+)
+
+// month is the length of the month of the retention policy
+const month = 30 * 24 * time.Hour
+
+// Policy of the retention of the history
+type Policy struct {
+	// MaxAge of the entries, the mangas not read for longer are dropped. Zero keeps them regardless of the age
+	MaxAge time.Duration
+	// MaxEntries is how many of the most recently read mangas are kept. Zero keeps all of them
+	MaxEntries int
+}
+
+// DefaultPolicy returns the policy of the config, see key.HistoryRetentionMonths and key.HistoryMaxEntries
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAge:     Months(viper.GetInt(key.HistoryRetentionMonths)),
+		MaxEntries: viper.GetInt(key.HistoryMaxEntries),
+	}
+}
+
+// Months returns the age of the given number of months, see Policy.MaxAge
+func Months(months int) time.Duration {
+	return time.Duration(months) * month
+}
+
+// IsEmpty reports whether the policy keeps everything
+func (p Policy) IsEmpty() bool {
+	return p.MaxAge <= 0 && p.MaxEntries <= 0
+}
+
+// PruneResult of Prune
+type PruneResult struct {
+	// Dropped are the entries the policy doesn't keep, the least recently read first
+	Dropped []*SavedChapter
+	// Compacted is the number of the duplicate and the malformed entries removed
+	Compacted int
+	// Kept is the number of the entries left
+	Kept int
+}
+
+// Prune drops the entries the policy doesn't keep and compacts the rest:
+// the entries of the same manga saved under the outdated keys are merged, keeping the latest one,
+// and the malformed ones are removed. Nothing is written if dryRun is set
+func (s *Store) Prune(policy Policy, dryRun bool) (*PruneResult, error) {
+	saved, err := s.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	// the map is shared with the cache, so that the dry run doesn't change it
+	pruned := make(map[string]*SavedChapter, len(saved))
+	for k, chapter := range saved {
+		pruned[k] = chapter
+	}
+
+	result := prune(pruned, policy, time.Now().UTC())
+	if dryRun || (len(result.Dropped) == 0 && result.Compacted == 0) {
+		return result, nil
+	}
+
+	return result, s.cacher.Set(pruned)
+}
+
+// prune applies the policy to the saved chapters in place
+func prune(saved map[string]*SavedChapter, policy Policy, now time.Time) *PruneResult {
+	result := &PruneResult{}
+
+	for k, chapter := range saved {
+		if chapter == nil || chapter.MangaName == "" || chapter.SourceID == "" {
+			delete(saved, k)
+			result.Compacted++
+			continue
+		}
+
+		if encoded := chapter.encode(); encoded != k {
+			delete(saved, k)
+			if existing, ok := saved[encoded]; ok {
+				result.Compacted++
+				if existing.Time.After(chapter.Time) {
+					continue
+				}
+			}
+
+			saved[encoded] = chapter
+		}
+	}
+
+	chapters := make([]*SavedChapter, 0, len(saved))
+	for _, chapter := range saved {
+		chapters = append(chapters, chapter)
+	}
+
+	// the most recently read first
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].Time.After(chapters[j].Time)
+	})
+
+	for i, chapter := range chapters {
+		expired := policy.MaxAge > 0 && now.Sub(chapter.Time) > policy.MaxAge
+		overflow := policy.MaxEntries > 0 && i >= policy.MaxEntries
+		if !expired && !overflow {
+			continue
+		}
+
+		delete(saved, chapter.encode())
+		result.Dropped = append(result.Dropped, chapter)
+	}
+
+	// the least recently read first
+	for i, j := 0, len(result.Dropped)-1; i < j; i, j = i+1, j-1 {
+		result.Dropped[i], result.Dropped[j] = result.Dropped[j], result.Dropped[i]
+	}
+
+	result.Kept = len(saved)
+	return result
+}
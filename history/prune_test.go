@@ -0,0 +1,96 @@
+package history
+
+import (
+	"github.com/metafates/mangal/key"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readAgo(name string, age time.Duration) *SavedChapter {
+	return &SavedChapter{MangaName: name, SourceID: "test source", Time: time.Now().UTC().Add(-age)}
+}
+
+func TestPrune(t *testing.T) {
+	Convey("Given the history of the mangas read at the different times", t, func() {
+		store := NewStore(filepath.Join(t.TempDir(), "history.json"))
+		for _, chapter := range []*SavedChapter{
+			readAgo("Berserk", time.Hour),
+			readAgo("Vagabond", Months(2)),
+			readAgo("Monster", Months(8)),
+			readAgo("Pluto", Months(13)),
+		} {
+			So(store.Put(chapter), ShouldBeNil)
+		}
+
+		Convey("When pruning the mangas not read for 6 months", func() {
+			result, err := store.Prune(Policy{MaxAge: Months(6)}, false)
+
+			Convey("Then they should be dropped, the least recently read first", func() {
+				So(err, ShouldBeNil)
+				So(lo.Map(result.Dropped, func(c *SavedChapter, _ int) string { return c.MangaName }), ShouldResemble, []string{"Pluto", "Monster"})
+				So(result.Kept, ShouldEqual, 2)
+				So(len(lo.Must(store.Get())), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When keeping the most recently read manga only", func() {
+			result, err := store.Prune(Policy{MaxEntries: 1}, false)
+
+			Convey("Then the others should be dropped", func() {
+				So(err, ShouldBeNil)
+				So(result.Dropped, ShouldHaveLength, 3)
+				So(lo.Keys(lo.Must(store.Get())), ShouldResemble, []string{"Berserk (test source)"})
+			})
+		})
+
+		Convey("When pruning with the dry run", func() {
+			result, err := store.Prune(Policy{MaxEntries: 1}, true)
+
+			Convey("Then nothing should be changed", func() {
+				So(err, ShouldBeNil)
+				So(result.Dropped, ShouldHaveLength, 3)
+				So(len(lo.Must(store.Get())), ShouldEqual, 4)
+			})
+		})
+
+		Convey("When the policy is set in the config", func() {
+			viper.Set(key.HistoryMaxEntries, 2)
+			defer viper.Set(key.HistoryMaxEntries, 0)
+
+			So(store.Put(readAgo("Vinland Saga", 0)), ShouldBeNil)
+
+			Convey("Then it should be applied as the history is saved", func() {
+				saved := lo.Must(store.Get())
+				So(len(saved), ShouldEqual, 2)
+				So(saved, ShouldContainKey, "Vinland Saga (test source)")
+				So(saved, ShouldContainKey, "Berserk (test source)")
+			})
+		})
+	})
+
+	Convey("Given the history with the outdated keys and the malformed entries", t, func() {
+		saved := map[string]*SavedChapter{
+			"berserk":               readAgo("Berserk", Months(1)),
+			"Berserk (test source)": readAgo("Berserk", time.Hour),
+			"Vagabond (old source)": readAgo("Vagabond", time.Hour),
+			"":                      {MangaName: "", SourceID: "test source"},
+			"Monster (test source)": nil,
+		}
+
+		Convey("When compacting it", func() {
+			result := prune(saved, Policy{}, time.Now().UTC())
+
+			Convey("Then the entries should be merged under their keys, keeping the latest ones", func() {
+				So(result.Compacted, ShouldEqual, 3)
+				So(result.Dropped, ShouldBeEmpty)
+				So(len(saved), ShouldEqual, 2)
+				So(saved["Berserk (test source)"].Time, ShouldHappenAfter, time.Now().Add(-2*time.Hour))
+				So(saved, ShouldContainKey, "Vagabond (test source)")
+			})
+		})
+	})
+}
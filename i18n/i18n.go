@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+)
+
+// English is the language of the messages in the code, it needs no catalog
+const English = "en"
+
+// catalog of the translated messages of the locale
+type catalog struct {
+	// messages are the translations by the English messages
+	messages map[string]string
+	// plurals are the forms of the countable words by their English singular form,
+	// in the order of the plural function
+	plurals map[string][]string
+	// plural returns the index of the form of the word counted n times
+	plural func(n int) int
+}
+
+// catalogs by the locales
+var catalogs = map[string]*catalog{
+	Russian: russian,
+}
+
+// Available returns the supported locales, English first
+func Available() []string {
+	return []string{English, Russian}
+}
+
+// Locale returns the language of the interface.
+// It is the one set in the config, or the one of the environment (LC_ALL, LC_MESSAGES, LANG) if it is empty.
+// English is used for the unsupported languages
+func Locale() string {
+	locale := viper.GetString(key.I18nLocale)
+	if locale == "" {
+		locale = environment()
+	}
+
+	locale = normalize(locale)
+	if _, ok := catalogs[locale]; ok {
+		return locale
+	}
+
+	return English
+}
+
+// environment returns the locale of the environment, as the C library would
+func environment() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// normalize turns the locale like ru_RU.UTF-8 into the language code, ru
+func normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "_-.@"); i != -1 {
+		locale = locale[:i]
+	}
+
+	return locale
+}
+
+// T translates the message to the language of the interface and formats it with the arguments as fmt.Sprintf does.
+// The messages without a translation are shown in English
+func T(message string, args ...any) string {
+	if c, ok := catalogs[Locale()]; ok {
+		if translated, ok := c.messages[message]; ok {
+			message = translated
+		}
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+
+	return fmt.Sprintf(message, args...)
+}
+
+// N counts the word in the language of the interface, e.g. N(3, "chapter", "chapters") is "3 chapters"
+func N(count int, singular, plural string) string {
+	if c, ok := catalogs[Locale()]; ok {
+		if forms, ok := c.plurals[singular]; ok {
+			return fmt.Sprintf("%d %s", count, forms[c.plural(count)])
+		}
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, singular)
+	}
+
+	return fmt.Sprintf("%d %s", count, plural)
+}
+
+// Words returns the singular and the plural forms of the word in the language of the interface,
+// for the components that count the things themselves.
+// The plural form is the one of the many things, e.g. of 5 chapters, if the language has several
+func Words(singular, plural string) (string, string) {
+	if c, ok := catalogs[Locale()]; ok {
+		if forms, ok := c.plurals[singular]; ok {
+			return forms[0], forms[len(forms)-1]
+		}
+	}
+
+	return singular, plural
+}
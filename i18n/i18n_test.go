@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"regexp"
+	"testing"
+)
+
+func TestLocale(t *testing.T) {
+	Convey("Given the locale of the environment", t, func() {
+		t.Setenv("LC_ALL", "")
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", "ru_RU.UTF-8")
+		defer viper.Set(key.I18nLocale, "")
+
+		Convey("When the locale is not set in the config", func() {
+			viper.Set(key.I18nLocale, "")
+
+			Convey("Then the one of the environment should be used", func() {
+				So(Locale(), ShouldEqual, Russian)
+				So(T("Downloaded to %s", "/tmp"), ShouldEqual, "Сохранено в /tmp")
+			})
+		})
+
+		Convey("When the locale is set in the config", func() {
+			viper.Set(key.I18nLocale, English)
+
+			Convey("Then it should be preferred", func() {
+				So(Locale(), ShouldEqual, English)
+				So(T("Downloaded to %s", "/tmp"), ShouldEqual, "Downloaded to /tmp")
+			})
+		})
+
+		Convey("When the locale is not supported", func() {
+			viper.Set(key.I18nLocale, "xx_XX")
+
+			Convey("Then English should be used", func() {
+				So(Locale(), ShouldEqual, English)
+			})
+		})
+
+		Convey("When the message has no translation", func() {
+			viper.Set(key.I18nLocale, Russian)
+
+			Convey("Then it should be shown in English", func() {
+				So(T("Nothing %d", 1), ShouldEqual, "Nothing 1")
+			})
+		})
+	})
+}
+
+func TestN(t *testing.T) {
+	Convey("Given the Russian locale", t, func() {
+		viper.Set(key.I18nLocale, Russian)
+		defer viper.Set(key.I18nLocale, "")
+
+		Convey("When counting the chapters", func() {
+			Convey("Then each count should get its form", func() {
+				So(N(1, "chapter", "chapters"), ShouldEqual, "1 глава")
+				So(N(3, "chapter", "chapters"), ShouldEqual, "3 главы")
+				So(N(5, "chapter", "chapters"), ShouldEqual, "5 глав")
+				So(N(11, "chapter", "chapters"), ShouldEqual, "11 глав")
+				So(N(21, "chapter", "chapters"), ShouldEqual, "21 глава")
+				So(N(112, "chapter", "chapters"), ShouldEqual, "112 глав")
+			})
+		})
+
+		Convey("When counting the unknown words", func() {
+			Convey("Then they should be counted in English", func() {
+				So(N(2, "file", "files"), ShouldEqual, "2 files")
+			})
+		})
+	})
+}
+
+func TestCatalogs(t *testing.T) {
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+	Convey("Given the catalogs", t, func() {
+		for locale, c := range catalogs {
+			locale, c := locale, c
+			Convey("Then the translations to "+locale+" should take the same arguments", func() {
+				for message, translated := range c.messages {
+					So(verbs.FindAllString(translated, -1), ShouldResemble, verbs.FindAllString(message, -1))
+				}
+			})
+
+			Convey("Then the words in "+locale+" should have every plural form", func() {
+				var forms int
+				for n := 0; n < 200; n++ {
+					if form := c.plural(n) + 1; form > forms {
+						forms = form
+					}
+				}
+
+				for _, words := range c.plurals {
+					So(len(words), ShouldBeGreaterThanOrEqualTo, forms)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package i18n
+
+// Russian locale
+const Russian = "ru"
+
+var russian = &catalog{
+	plural: func(n int) int {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	},
+	plurals: map[string][]string{
+		"chapter":  {"глава", "главы", "глав"},
+		"conflict": {"конфликт", "конфликта", "конфликтов"},
+		"download": {"загрузка", "загрузки", "загрузок"},
+		"entry":    {"запись", "записи", "записей"},
+		"manga":    {"манга", "манги", "манг"},
+		"page":     {"страница", "страницы", "страниц"},
+		"scraper":  {"скрейпер", "скрейпера", "скрейперов"},
+		"source":   {"источник", "источника", "источников"},
+		// the relative times, e.g. 5 minutes ago
+		"minute": {"минуту", "минуты", "минут"},
+		"hour":   {"час", "часа", "часов"},
+		"day":    {"день", "дня", "дней"},
+		"month":  {"месяц", "месяца", "месяцев"},
+		"year":   {"год", "года", "лет"},
+	},
+	messages: map[string]string{
+		// titles
+		"Anilist Mangas":            "Манга на Anilist",
+		"Chapters":                  "Главы",
+		"Clipboard":                 "Буфер обмена",
+		"Confirm":                   "Подтверждение",
+		"Downloading":               "Скачивание",
+		"Downloads":                 "Загрузки",
+		"Error":                     "Ошибка",
+		"Finish":                    "Готово",
+		"History":                   "История",
+		"History Results >>":        "История >>",
+		"Install Scrapers":          "Установка скрейперов",
+		"Library":                   "Библиотека",
+		"Loading":                   "Загрузка",
+		"Logs: everything":          "Логи: все",
+		"Logs: warnings and errors": "Логи: предупреждения и ошибки",
+		"Mangas":                    "Манга",
+		"Query Results >>":          "Результаты поиска >>",
+		"Reading":                   "Чтение",
+		"Search":                    "Поиск",
+		"Search Manga":              "Поиск манги",
+		"Select Source":             "Выбор источника",
+
+		// prompts
+		"%d of %s from the previous search match": "Совпадений в предыдущем поиске: %d из %s",
+		"Download %s again?":                      "Скачать заново %s?",
+		"Download %s?":                            "Скачать %s?",
+		"Invalid choice entered":                  "Неверный выбор",
+		"Open the link from the clipboard?":       "Открыть ссылку из буфера обмена?",
+		"Press %s to accept":                      "Нажмите %s, чтобы принять",
+		"Press %s to search the sources again":    "Нажмите %s, чтобы снова искать в источниках",
+		"Search %s ?":                             "Искать %s ?",
+		"To specify a range, use: start_number end_number (Chapters: 1-%d)": "Чтобы выбрать диапазон, введите: начало конец (главы: 1-%d)",
+		"Which one?":                  "Какую?",
+		"and %d more":                 "и ещё %d",
+		"type the query and press %s": "введите запрос и нажмите %s",
+
+		// confirmation
+		"%s will be redownloaded":  "будут скачаны заново: %s",
+		"%s will be skipped":       "будут пропущены: %s",
+		"%s would be saved as %s":  "%s будут сохранены как %s",
+		"(counting %d/%d)":         "(подсчёт %d/%d)",
+		"(estimating %d/%d)":       "(оценка %d/%d)",
+		"(failed to count)":        "(не удалось посчитать)",
+		"(failed to estimate)":     "(не удалось оценить)",
+		"(some sizes are unknown)": "(размер части глав неизвестен)",
+		"Collision":                "Совпадение",
+		"Destination":              "Куда",
+		"Downloaded":               "Скачано",
+		"Format":                   "Формат",
+		"Pages":                    "Страниц",
+		"Size":                     "Размер",
+
+		// progress
+		"%d%% downloaded":                   "скачано %d%%",
+		"Bundling %s":                       "Сборка тома %s",
+		"Converting %d pages to %s %s":      "Конвертация страниц (%d) в %s %s",
+		"Currently downloading %s %s (%s)":  "Скачивается %s %s (%s)",
+		"Currently reading %s":              "Читается %s",
+		"Done":                              "Готово",
+		"Downloading %s":                    "Скачивается %s",
+		"Downloading %s %s":                 "Скачивание: %s %s",
+		"Downloading cover":                 "Скачивание обложки",
+		"Failed to fetch metadata":          "Не удалось получить метаданные",
+		"Fetching Chapters..":               "Получение глав..",
+		"Fetching anilist for %s":           "Поиск %s на Anilist",
+		"Fetching metadata from anilist":    "Получение метаданных с Anilist",
+		"From %s as %s":                     "Из %s в %s",
+		"Generating series.json":            "Создание series.json",
+		"Getting chapters":                  "Получение глав",
+		"Getting pages":                     "Получение страниц",
+		"Initializing Source..":             "Запуск источника..",
+		"Initializing source":               "Запуск источника",
+		"Installing %s":                     "Установка %s",
+		"Loading scrapers":                  "Загрузка скрейперов",
+		"Opening":                           "Открытие",
+		"Opening %s":                        "Открытие в %s",
+		"Pulling %s %s":                     "Копирование: %s %s",
+		"Resuming %s":                       "Продолжение: %s",
+		"Scrapers Loaded":                   "Скрейперы загружены",
+		"Searching %s on the other sources": "Поиск %s в других источниках",
+		"Searching Chapters..":              "Поиск глав..",
+		"Searching Query..":                 "Поиск..",
+		"Searching among %s":                "Поиск: %s",
+		"Translating page %d of %d":         "Перевод страницы %d из %d",
+
+		// results
+		"%d not local":                          "не локально: %d",
+		"%s ago":                                "%s назад",
+		"%s downloaded, %d failed.":             "Скачано: %s, с ошибкой: %d.",
+		"%s downloaded, %s failed":              "Скачано: %s, с ошибкой: %s",
+		"%s downloaded.":                        "Скачано: %s.",
+		"%s new":                                "новых: %s",
+		"%s was not found on the other sources": "%s не найдена в других источниках",
+		"%s: %s dropped, %s compacted, %s kept": "%s: удалено: %s, сжато: %s, оставлено: %s",
+		", page %d":                             ", страница %d",
+		"Added %s to the reading queue":         "%s в списке на чтение",
+		"Bookmarked %s":                         "%s в закладках",
+		"Could not reach %s":                    "Не удалось подключиться к %s",
+		"Download queue is emptied":             "Очередь загрузок очищена",
+		"Downloaded %s, removed %s":             "Скачано: %s, удалено: %s",
+		"Downloaded to %s":                      "Сохранено в %s",
+		"Found %s re-uploaded by the source, downloaded %d again": "Перезалито источником: %s, скачано заново: %d",
+		"Installed %s":            "%s установлен",
+		"Link":                    "Ссылка",
+		"Linked to %s %s":         "Связано с %s %s",
+		"Local":                   "Локально",
+		"Most downloaded":         "Больше всего скачано",
+		"Most read":               "Больше всего прочитано",
+		"No bookmarks":            "Закладок нет",
+		"No chapters found":       "Главы не найдены",
+		"No mangas":               "Манги нет",
+		"No search results found": "Ничего не найдено",
+		"Nothing is dropped by the policy, see --months and --keep": "Политика ничего не удаляет, см. --months и --keep",
+		"Nothing to pull":                     "Нечего копировать",
+		"Nothing to resume":                   "Нечего продолжать",
+		"Nothing yet":                         "Пока ничего",
+		"Pulled %s":                           "Скопировано: %s",
+		"Pulled %s, failed to pull %s":        "Скопировано: %s, не удалось скопировать: %s",
+		"Pulling %s":                          "Копирование: %s",
+		"Queued %s":                           "В очереди: %s",
+		"Queueing %s":                         "Добавление в очередь: %s",
+		"Removed the bookmark of %s":          "Закладка %s удалена",
+		"Source":                              "Источник",
+		"Switched to %s":                      "Переключено на %s",
+		"Switched to %s, not found there: %s": "Переключено на %s, там не найдено: %s",
+		"The crash report could not be saved: %s":                             "Не удалось сохранить отчёт об ошибке: %s",
+		"The crash report could not be submitted: %s":                         "Не удалось отправить отчёт об ошибке: %s",
+		"The crash report is saved to %s, please attach it to the bug report": "Отчёт об ошибке сохранён в %s, приложите его к сообщению об ошибке",
+		"The crash report is submitted":                                       "Отчёт об ошибке отправлен",
+		"The link was not found among the results":                            "Ссылка не найдена среди результатов",
+		"bookmarked":         "в закладках",
+		"completed":          "завершена",
+		"failed to download": "не удалось скачать",
+		"has %s":             "имеет %s",
+		"has chapters that differ from the source, run mangal reconcile": "имеет главы, которые отличаются от источника, запустите mangal reconcile",
+		"has nothing to pull":                       "нечего копировать",
+		"is completed and won't be checked again":   "завершена и больше не будет проверяться",
+		"is completed, use --completed to check it": "завершена, проверьте её с --completed",
+		"is not tracked":                            "не отслеживается",
+		"is served by %s":                           "загружается с %s",
+		"just now":                                  "только что",
+		"local":                                     "локально",
+		"mangal has crashed: %s":                    "mangal завершился с ошибкой: %s",
+		"matches the source":                        "совпадает с источником",
+		"removed":                                   "удалена",
+		"resolved %s":                               "решено: %s",
+		"was re-uploaded by the source":             "перезалита источником",
+		"would drop":                                "будет удалена",
+
+		// errors
+		"%w, and the fallback path is unavailable too: %s":                                                        "%w, а запасной путь тоже недоступен: %s",
+		"Fighting an endless army of errors and bugs Mangal died a hero. Their last words were:":                  "Сражаясь с бесконечной армией ошибок и багов, Mangal пал героем. Последними словами были:",
+		"I used to download stuff without any errors, then I took an arrow to the knee. By arrow I mean this:":    "Раньше я скачивал всё без ошибок, но потом мне прострелили колено. Вот этим:",
+		"The universe is a dangerous place. There are many things that can go wrong. This is one of them:":        "Вселенная опасна. Многое может пойти не так. Например, это:",
+		"Uggh, something went wrong. Maybe try again?":                                                            "Ой, что-то пошло не так. Может, попробовать ещё раз?",
+		"can't save chapters to %s (%s): %s, or set %s to another directory, or %s to use when it is unavailable": "не удаётся сохранить главы в %s (%s): %s, или укажите в %s другую папку, или в %s запасную на случай, когда она недоступна",
+		"check that it is mounted":                                 "проверьте, что она подключена",
+		"it does not exist, check that it is mounted or create it": "её нет, проверьте, что она подключена, или создайте её",
+		"it is mounted read-only, remount it read-write":           "она подключена только для чтения, переподключите её для записи",
+		"the current user has no permission to write there, change the owner or the permissions of the directory": "у пользователя нет прав на запись в неё, смените владельца или права папки",
+
+		// help
+		"accept search suggestion": "принять подсказку",
+		"back":                     "назад",
+		"bookmark":                 "в закладки",
+		"bottom":                   "в конец",
+		"clear selection":          "снять выбор",
+		"confirm":                  "подтвердить",
+		"down":                     "вниз",
+		"download selected":        "скачать выбранные",
+		"downloads":                "загрузки",
+		"filter":                   "фильтр",
+		"help":                     "помощь",
+		"install":                  "установить",
+		"left":                     "влево",
+		"logs":                     "логи",
+		"open":                     "открыть",
+		"open folder":              "открыть папку",
+		"open url":                 "открыть ссылку",
+		"plan to read":             "прочитать позже",
+		"pull selected":            "скопировать выбранные",
+		"quit":                     "выйти",
+		"read":                     "читать",
+		"redownload failed":        "перекачать неудачные",
+		"remove":                   "удалить",
+		"right":                    "вправо",
+		"search sources":           "искать в источниках",
+		"search with selected":     "искать в выбранных",
+		"select all":               "выбрать все",
+		"select anilist manga":     "выбрать мангу на Anilist",
+		"select one":               "выбрать",
+		"select volume":            "выбрать том",
+		"skip":                     "пропустить",
+		"skip chapter":             "пропустить главу",
+		"switch source":            "сменить источник",
+		"top":                      "в начало",
+		"up":                       "вверх",
+		"view source":              "исходный код",
+	},
+}
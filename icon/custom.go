@@ -0,0 +1,107 @@
+package icon
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/style"
+	"github.com/spf13/viper"
+	"strings"
+	"sync"
+)
+
+// names of the icons in the custom set
+var names = map[Icon]string{
+	Lua:        "lua",
+	Go:         "go",
+	Fail:       "fail",
+	Success:    "success",
+	Question:   "question",
+	Mark:       "mark",
+	Downloaded: "downloaded",
+	Progress:   "progress",
+	Search:     "search",
+	Link:       "link",
+	Bookmark:   "bookmark",
+}
+
+// Names returns the names of the icons that can be set in the custom set
+func Names() []string {
+	list := make([]string, 0, len(names))
+	for i := Lua; i <= Bookmark; i++ {
+		list = append(list, names[i])
+	}
+
+	return list
+}
+
+// ParseCustom parses the custom icon set given as "name=icon" or "name=icon:color" entries,
+// e.g. "fail=✗:red" or "mark=>:#ffb703". Color is a name, a hex code or an ANSI number, see color.Parse
+func ParseCustom(entries []string) (map[Icon]string, error) {
+	custom := make(map[Icon]string, len(entries))
+
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid custom icon %q, expected name=icon or name=icon:color", entry)
+		}
+
+		icon, ok := byName(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown icon %q, available icons are: %s", name, strings.Join(Names(), ", "))
+		}
+
+		// the icon itself may have colons, e.g. ":)", so the suffix is the color only if it is a valid one
+		if i := strings.LastIndex(value, ":"); i != -1 {
+			if c, ok := color.Parse(value[i+1:]); ok {
+				value = style.Fg(c)(value[:i])
+			}
+		}
+
+		custom[icon] = value
+	}
+
+	return custom, nil
+}
+
+func byName(name string) (Icon, bool) {
+	for icon, n := range names {
+		if strings.EqualFold(n, name) {
+			return icon, true
+		}
+	}
+
+	return 0, false
+}
+
+// customCache keeps the parsed custom set until the config is changed
+var customCache struct {
+	sync.Mutex
+	entries string
+	icons   map[Icon]string
+}
+
+// customIcons returns the custom set from the config.
+// The invalid set is reported once and the plain icons are used instead
+func customIcons() map[Icon]string {
+	entries := viper.GetStringSlice(key.IconsCustom)
+	joined := strings.Join(entries, "\n")
+
+	customCache.Lock()
+	defer customCache.Unlock()
+
+	if customCache.icons != nil && customCache.entries == joined {
+		return customCache.icons
+	}
+
+	custom, err := ParseCustom(entries)
+	if err != nil {
+		log.Warn(err)
+		custom = make(map[Icon]string)
+	}
+
+	customCache.entries = joined
+	customCache.icons = custom
+	return custom
+}
@@ -0,0 +1,85 @@
+package icon
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// getenv reads the environment in detect, replaced in tests
+var getenv = os.Getenv
+
+var (
+	detected     string
+	detectedOnce sync.Once
+)
+
+// Detected returns the icons variant that the terminal can show: nerd, emoji, plain, or ascii if it can't show Unicode
+func Detected() string {
+	detectedOnce.Do(func() {
+		detected = detect()
+	})
+
+	return detected
+}
+
+func detect() string {
+	if !unicodeTerminal() {
+		return ascii
+	}
+
+	if nerdFontTerminal() {
+		return nerd
+	}
+
+	if emojiTerminal() {
+		return emoji
+	}
+
+	return plain
+}
+
+// unicodeTerminal reports whether the terminal uses UTF-8 and has the glyphs beyond ASCII
+func unicodeTerminal() bool {
+	switch getenv("TERM") {
+	case "dumb", "linux", "vt100", "vt220":
+		return false
+	}
+
+	if runtime.GOOS == "windows" {
+		// the legacy console has no fallback fonts, Windows Terminal has
+		return getenv("WT_SESSION") != ""
+	}
+
+	for _, env := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(env); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+
+	return false
+}
+
+// nerdFontTerminal reports whether the terminal has the nerd font symbols built in,
+// the font of the other terminals can't be known
+func nerdFontTerminal() bool {
+	return getenv("TERM_PROGRAM") == "WezTerm" || getenv("TERM") == "xterm-kitty"
+}
+
+// emojiTerminal reports whether the terminal is known to show the colored emojis
+func emojiTerminal() bool {
+	switch getenv("TERM_PROGRAM") {
+	case "Apple_Terminal", "iTerm.app", "WezTerm", "vscode", "Hyper":
+		return true
+	}
+
+	for _, env := range []string{"WT_SESSION", "VTE_VERSION", "KONSOLE_VERSION", "KITTY_WINDOW_ID"} {
+		if getenv(env) != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -11,10 +11,13 @@ const (
 	plain   = "plain"
 	kaomoji = "kaomoji"
 	squares = "squares"
+	ascii   = "ascii"
+	auto    = "auto"
+	custom  = "custom"
 )
 
 func AvailableVariants() []string {
-	return []string{emoji, nerd, plain, kaomoji, squares}
+	return []string{auto, emoji, nerd, plain, kaomoji, squares, ascii, custom}
 }
 
 type iconDef struct {
@@ -23,10 +26,11 @@ type iconDef struct {
 	plain   string
 	kaomoji string
 	squares string
+	ascii   string
 }
 
 func (i *iconDef) Get() string {
-	switch viper.GetString(key.IconsVariant) {
+	switch Variant() {
 	case emoji:
 		return i.emoji
 	case nerd:
@@ -37,11 +41,38 @@ func (i *iconDef) Get() string {
 		return i.kaomoji
 	case squares:
 		return i.squares
+	case ascii:
+		return i.ascii
 	default:
 		return ""
 	}
 }
 
 func Get(icon Icon) string {
+	if Variant() == custom {
+		// the icons missing from the custom set are the plain ones
+		if i, ok := customIcons()[icon]; ok {
+			return i
+		}
+
+		return icons[icon].plain
+	}
+
 	return icons[icon].Get()
 }
+
+// Variant returns the icons variant in use, the detected one if it is auto
+func Variant() string {
+	variant := viper.GetString(key.IconsVariant)
+	if variant == auto {
+		return Detected()
+	}
+
+	return variant
+}
+
+// ASCII reports whether the interface should be drawn with ASCII only,
+// e.g. the spinners and the progress bars
+func ASCII() bool {
+	return Variant() == ascii
+}
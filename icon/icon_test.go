@@ -1,9 +1,12 @@
 package icon
 
 import (
+	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/style"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/spf13/viper"
+	"os"
 	"testing"
 )
 
@@ -42,6 +45,24 @@ func TestGet(t *testing.T) {
 			})
 		})
 
+		Convey("When getting the icon with ascii setting", func() {
+			viper.Set(key.IconsVariant, ascii)
+			result := Get(i)
+			Convey("Then the result should be ascii icon", func() {
+				So(result, ShouldEqual, icons[i].ascii)
+				So(ASCII(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When getting the icon with auto setting", func() {
+			viper.Set(key.IconsVariant, auto)
+			result := Get(i)
+			Convey("Then the result should be the icon of the detected variant", func() {
+				So(Variant(), ShouldEqual, Detected())
+				So(result, ShouldEqual, icons[i].Get())
+			})
+		})
+
 		Convey("When getting the icon with no setting", func() {
 			viper.Set(key.IconsVariant, "")
 			result := Get(i)
@@ -51,3 +72,88 @@ func TestGet(t *testing.T) {
 		})
 	})
 }
+
+func TestDetect(t *testing.T) {
+	Convey("Given the environment of the terminal", t, func() {
+		env := map[string]string{"LANG": "en_US.UTF-8"}
+		getenv = func(name string) string {
+			return env[name]
+		}
+		defer func() {
+			getenv = os.Getenv
+		}()
+
+		Convey("When the terminal is unknown", func() {
+			Convey("Then the plain icons should be used", func() {
+				So(detect(), ShouldEqual, plain)
+			})
+		})
+
+		Convey("When the terminal shows emojis", func() {
+			env["TERM_PROGRAM"] = "iTerm.app"
+			Convey("Then the emoji icons should be used", func() {
+				So(detect(), ShouldEqual, emoji)
+			})
+		})
+
+		Convey("When the terminal has the nerd font symbols", func() {
+			env["TERM_PROGRAM"] = "WezTerm"
+			Convey("Then the nerd icons should be used", func() {
+				So(detect(), ShouldEqual, nerd)
+			})
+		})
+
+		Convey("When the locale is not UTF-8", func() {
+			env["LANG"] = "C"
+			env["TERM_PROGRAM"] = "WezTerm"
+			Convey("Then the ascii icons should be used", func() {
+				So(detect(), ShouldEqual, ascii)
+			})
+		})
+
+		Convey("When the terminal is dumb", func() {
+			env["TERM"] = "dumb"
+			Convey("Then the ascii icons should be used", func() {
+				So(detect(), ShouldEqual, ascii)
+			})
+		})
+	})
+}
+
+func TestCustom(t *testing.T) {
+	Convey("Given a custom icon set", t, func() {
+		viper.Set(key.IconsVariant, custom)
+		viper.Set(key.IconsCustom, []string{"fail=:(", "success=OK:green", "mark=>:#ffb703"})
+		defer viper.Set(key.IconsCustom, []string{})
+
+		Convey("When getting the icons of the set", func() {
+			Convey("Then they should be the custom ones", func() {
+				So(Get(Fail), ShouldEqual, ":(")
+				So(Get(Success), ShouldEqual, style.Fg(color.Green)("OK"))
+				So(Get(Mark), ShouldEqual, style.Fg(color.New("#ffb703"))(">"))
+			})
+		})
+
+		Convey("When getting the icon missing from the set", func() {
+			Convey("Then it should be the plain one", func() {
+				So(Get(Lua), ShouldEqual, icons[Lua].plain)
+			})
+		})
+
+		Convey("When the set has an unknown icon", func() {
+			_, err := ParseCustom([]string{"rocket=^"})
+
+			Convey("Then it should be an error", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the set is invalid", func() {
+			viper.Set(key.IconsCustom, []string{"fail"})
+
+			Convey("Then the plain icons should be used", func() {
+				So(Get(Fail), ShouldEqual, icons[Fail].plain)
+			})
+		})
+	})
+}
@@ -18,6 +18,7 @@ const (
 	Progress
 	Search
 	Link
+	Bookmark
 )
 
 var icons = map[Icon]*iconDef{
@@ -27,6 +28,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Blue)("Lua"),
 		kaomoji: style.Fg(color.Blue)("(=^･ω･^=)"),
 		squares: style.Fg(color.Blue)("◧"),
+		ascii:   style.Fg(color.Blue)("Lua"),
 	},
 	Go: {
 		emoji:   "🐹",
@@ -34,6 +36,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Cyan)("Go"),
 		kaomoji: style.Fg(color.Cyan)("ʕ •ᴥ• ʔ"),
 		squares: style.Fg(color.Cyan)("◨"),
+		ascii:   style.Fg(color.Cyan)("Go"),
 	},
 	Fail: {
 		emoji:   "💀",
@@ -41,6 +44,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Red)("X"),
 		kaomoji: style.Fg(color.Red)("┐('～`;)┌"),
 		squares: style.Fg(color.Red)("▨"),
+		ascii:   style.Fg(color.Red)("X"),
 	},
 	Success: {
 		emoji:   "🎉",
@@ -48,6 +52,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Green)("✓"),
 		kaomoji: style.Fg(color.Green)("(ᵔ◡ᵔ)"),
 		squares: style.Fg(color.Green)("▣"),
+		ascii:   style.Fg(color.Green)("+"),
 	},
 	Mark: {
 		emoji:   "🦐",
@@ -55,6 +60,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.New().Bold(true).Foreground(color.Orange).Render("*"),
 		kaomoji: style.New().Bold(true).Foreground(color.Red).Render("炎"),
 		squares: style.New().Bold(true).Foreground(color.Orange).Render("■"),
+		ascii:   style.New().Bold(true).Foreground(color.Orange).Render("*"),
 	},
 	Question: {
 		emoji:   "🤨",
@@ -62,6 +68,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Yellow)("?"),
 		kaomoji: style.Fg(color.Yellow)("(￢ ￢)"),
 		squares: style.Fg(color.Yellow)("◲"),
+		ascii:   style.Fg(color.Yellow)("?"),
 	},
 	Progress: {
 		emoji:   "👾",
@@ -69,6 +76,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Blue)("@"),
 		kaomoji: style.Fg(color.Blue)("┌( >_<)┘"),
 		squares: style.Fg(color.Blue)("◫"),
+		ascii:   style.Fg(color.Blue)("@"),
 	},
 	Downloaded: {
 		emoji:   "📦",
@@ -76,6 +84,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.New().Bold(true).Faint(true).Render("D"),
 		kaomoji: style.Bold("⊂(◉‿◉)つ"),
 		squares: style.Bold("◬"),
+		ascii:   style.New().Bold(true).Faint(true).Render("D"),
 	},
 	Search: {
 		emoji:   "🔍",
@@ -83,6 +92,7 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Blue)("S"),
 		kaomoji: style.Fg(color.Blue)("⌐■-■"),
 		squares: style.Fg(color.Blue)("◪"),
+		ascii:   style.Fg(color.Blue)("S"),
 	},
 	Link: {
 		emoji:   "🔗",
@@ -90,5 +100,14 @@ var icons = map[Icon]*iconDef{
 		plain:   style.Fg(color.Blue)("L"),
 		kaomoji: style.Fg(color.Blue)("⌐■-■"),
 		squares: style.Fg(color.Blue)("◪"),
+		ascii:   style.Fg(color.Blue)("L"),
+	},
+	Bookmark: {
+		emoji:   "🔖",
+		nerd:    style.Fg(color.Yellow)("\uF02E"),
+		plain:   style.Fg(color.Yellow)("B"),
+		kaomoji: style.Fg(color.Yellow)("(๑•̀ㅂ•́)و✧"),
+		squares: style.Fg(color.Yellow)("◩"),
+		ascii:   style.Fg(color.Yellow)("B"),
 	},
 }
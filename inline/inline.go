@@ -1,7 +1,9 @@
 package inline
 
 import (
+	"encoding/json"
 	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/history"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/source"
@@ -14,14 +16,9 @@ func Run(options *Options) (err error) {
 		options.Out = os.Stdout
 	}
 
-	var mangas []*source.Manga
-	for _, src := range options.Sources {
-		m, err := src.Search(options.Query)
-		if err != nil {
-			return err
-		}
-
-		mangas = append(mangas, m...)
+	mangas, err := search(options)
+	if err != nil {
+		return err
 	}
 
 	if options.MangaPicker.IsAbsent() && options.ChaptersFilter.IsAbsent() {
@@ -31,7 +28,7 @@ func Run(options *Options) (err error) {
 			}
 		}
 
-		marshalled, err := asJson(mangas, options)
+		marshalled, err := asJson(mangas, nil, options)
 		if err != nil {
 			return err
 		}
@@ -42,14 +39,17 @@ func Run(options *Options) (err error) {
 
 	// manga picker can only be none if json is set
 	if options.MangaPicker.IsAbsent() {
+		isRead := history.IsRead()
+		progress := make(map[*source.Manga]*Progress, len(mangas))
+
 		// preload all chapters
 		for _, manga := range mangas {
-			if err = prepareManga(manga, options); err != nil {
+			if progress[manga], err = prepareManga(manga, isRead, options); err != nil {
 				return err
 			}
 		}
 
-		marshalled, err := asJson(mangas, options)
+		marshalled, err := asJson(mangas, progress, options)
 		if err != nil {
 			return err
 		}
@@ -62,7 +62,7 @@ func Run(options *Options) (err error) {
 
 	if len(mangas) == 0 {
 		if options.Json {
-			marshalled, err := asJson([]*source.Manga{}, options)
+			marshalled, err := asJson([]*source.Manga{}, nil, options)
 			if err != nil {
 				return err
 			}
@@ -78,7 +78,7 @@ func Run(options *Options) (err error) {
 
 	if manga == nil {
 		if options.Json {
-			marshalled, err := asJson([]*source.Manga{}, options)
+			marshalled, err := asJson([]*source.Manga{}, nil, options)
 			if err != nil {
 				return err
 			}
@@ -103,11 +103,12 @@ func Run(options *Options) (err error) {
 	}
 
 	if options.Json {
-		if err = prepareManga(manga, options); err != nil {
+		progress, err := prepareManga(manga, history.IsRead(), options)
+		if err != nil {
 			return err
 		}
 
-		marshalled, err := asJson([]*source.Manga{manga}, options)
+		marshalled, err := asJson([]*source.Manga{manga}, map[*source.Manga]*Progress{manga: progress}, options)
 		if err != nil {
 			return err
 		}
@@ -116,28 +117,95 @@ func Run(options *Options) (err error) {
 		return err
 	}
 
+	if options.Download {
+		return download(chapters, options)
+	}
+
 	for _, chapter := range chapters {
-		if options.Download {
-			path, err := downloader.Download(chapter, func(string) {})
+		err := downloader.Read(chapter, func(string) {})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// search returns the mangas found by the sources.
+// With the fallback, the next source is searched only if the previous one failed
+// or found nothing that the manga picker selects
+func search(options *Options) ([]*source.Manga, error) {
+	if !options.Fallback {
+		var mangas []*source.Manga
+		for _, src := range options.Sources {
+			m, err := src.Search(options.Query)
 			if err != nil {
-				if viper.GetBool(key.DownloaderStopOnError) {
-					return err
-				}
+				return nil, err
+			}
+
+			mangas = append(mangas, m...)
+		}
+
+		return mangas, nil
+	}
 
-				continue
+	var (
+		firstErr error
+		searched bool
+	)
+
+	for _, src := range options.Sources {
+		mangas, err := src.Search(options.Query)
+		if err != nil {
+			log.Source(src.Name()).Warnf("%s, falling back to the next source", err)
+			if firstErr == nil {
+				firstErr = err
 			}
 
-			_, err = options.Out.Write([]byte(path + "\n"))
-			if err != nil {
+			continue
+		}
+
+		searched = true
+		if picker, ok := options.MangaPicker.Get(); len(mangas) == 0 || ok && picker(mangas) == nil {
+			log.Source(src.Name()).Infof("%q is not found, falling back to the next source", options.Query)
+			continue
+		}
+
+		log.Infof("%q is served by %s", options.Query, src.Name())
+		return mangas, nil
+	}
+
+	// nothing is found, it is an error only if no source could search
+	if searched {
+		return nil, nil
+	}
+
+	return nil, firstErr
+}
+
+// download the chapters, continuing past the failed ones.
+// Paths of the downloaded chapters are written to the output as soon as they are saved
+func download(chapters []*source.Chapter, options *Options) error {
+	summary, err := downloader.Batch(chapters, downloader.BatchOptions{
+		Retries:     viper.GetInt(key.DownloaderRetryFailed),
+		StopOnError: viper.GetBool(key.DownloaderStopOnError),
+		Downloaded: func(_ *source.Chapter, path string) {
+			if _, err := options.Out.Write([]byte(path + "\n")); err != nil {
 				log.Warn(err)
 			}
-		} else {
-			err := downloader.Read(chapter, func(string) {})
-			if err != nil {
-				return err
-			}
+		},
+	})
+
+	// nothing is downloaded if the downloads path is unavailable
+	if options.Summary != nil && summary != nil {
+		if err := json.NewEncoder(options.Summary).Encode(summary); err != nil {
+			log.Warn(err)
 		}
 	}
 
-	return nil
+	if err != nil {
+		return err
+	}
+
+	return summary.Err()
 }
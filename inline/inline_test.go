@@ -0,0 +1,90 @@
+package inline
+
+import (
+	"errors"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// searchSource finds the mangas with the given names, or fails if it is down
+type searchSource struct {
+	name   string
+	mangas []string
+	down   bool
+}
+
+func (s *searchSource) Name() string { return s.name }
+func (s *searchSource) ID() string   { return s.name }
+
+func (s *searchSource) Search(string) ([]*source.Manga, error) {
+	if s.down {
+		return nil, errors.New(s.name + " is down")
+	}
+
+	return lo.Map(s.mangas, func(name string, _ int) *source.Manga {
+		return &source.Manga{Name: name, Source: s}
+	}), nil
+}
+
+func (s *searchSource) ChaptersOf(*source.Manga) ([]*source.Chapter, error) { return nil, nil }
+func (s *searchSource) PagesOf(*source.Chapter) ([]*source.Page, error)     { return nil, nil }
+
+func TestSearch(t *testing.T) {
+	Convey("Given the source that is down, the one without the manga and the one with it", t, func() {
+		var (
+			down     = &searchSource{name: "Down", down: true}
+			other    = &searchSource{name: "Other", mangas: []string{"Berserk of Gluttony"}}
+			found    = &searchSource{name: "Found", mangas: []string{"Berserk"}}
+			options  = &Options{Query: "Berserk", Sources: []source.Source{down, other, found}}
+			sourceOf = func(mangas []*source.Manga) []string {
+				return lo.Map(mangas, func(manga *source.Manga, _ int) string { return manga.Source.Name() })
+			}
+		)
+
+		Convey("When all of them are searched", func() {
+			_, err := search(options)
+
+			Convey("Then the failing one should fail the search", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When they are searched with the fallback", func() {
+			options.Fallback = true
+
+			Convey("Then the first source that finds anything should serve the manga", func() {
+				mangas, err := search(options)
+				So(err, ShouldBeNil)
+				So(sourceOf(mangas), ShouldResemble, []string{"Other"})
+			})
+
+			Convey("Then the sources should be searched until the picker selects the manga", func() {
+				picker, err := ParseMangaPicker(options.Query, exact)
+				So(err, ShouldBeNil)
+				options.MangaPicker = mo.Some(picker)
+
+				mangas, err := search(options)
+				So(err, ShouldBeNil)
+				So(sourceOf(mangas), ShouldResemble, []string{"Found"})
+			})
+
+			Convey("Then nothing should be found without an error if any source could search", func() {
+				options.Sources = []source.Source{down, &searchSource{name: "Empty"}}
+
+				mangas, err := search(options)
+				So(err, ShouldBeNil)
+				So(mangas, ShouldBeEmpty)
+			})
+
+			Convey("Then the error should be returned if every source failed", func() {
+				options.Sources = []source.Source{down}
+
+				_, err := search(options)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
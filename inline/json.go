@@ -1,9 +1,11 @@
 package inline
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/source"
 	"github.com/spf13/viper"
 )
@@ -13,8 +15,62 @@ type Manga struct {
 	Source string `json:"source" jsonschema:"description=Source that the manga belongs to."`
 	// Mangal variant of the manga
 	Mangal *source.Manga `json:"mangal" jsonschema:"description=Mangal variant of the manga"`
+	// Authors of the story given by the source and by Anilist
+	Authors []string `json:"authors" jsonschema:"description=Authors of the story given by the source and by Anilist"`
+	// Artists of the manga given by the source and by Anilist
+	Artists []string `json:"artists" jsonschema:"description=Artists of the manga given by the source and by Anilist"`
 	// Anilist is the closest anilist match to mangal manga
 	Anilist *anilist.Manga `json:"anilist" jsonschema:"description=Anilist is the closest anilist match to mangal manga"`
+	// Progress of reading the manga, present when the chapters are listed
+	Progress *Progress `json:"progress,omitempty" jsonschema:"description=Progress of reading the manga, present when the chapters are listed"`
+}
+
+// Progress of reading the manga joined from the history and the downloaded chapters
+type Progress struct {
+	// Total number of the chapters in the source
+	Total int `json:"total" jsonschema:"description=Total number of the chapters in the source"`
+	// Read is the number of the chapters up to the last read one
+	Read int `json:"read" jsonschema:"description=Number of the chapters up to the last read one"`
+	// Unread is the number of the chapters after the last read one
+	Unread int `json:"unread" jsonschema:"description=Number of the chapters after the last read one"`
+	// Chapters are the statuses of the listed chapters, in the same order
+	Chapters []*ChapterProgress `json:"chapters" jsonschema:"description=Statuses of the listed chapters, in the same order"`
+}
+
+// ChapterProgress is the status of the chapter
+type ChapterProgress struct {
+	// Index of the chapter in the manga
+	Index int `json:"index" jsonschema:"description=Index of the chapter in the manga"`
+	// Read is whether the chapter is not newer than the last read one
+	Read bool `json:"read" jsonschema:"description=Whether the chapter is not newer than the last read one"`
+	// Downloaded is whether the chapter is in the library
+	Downloaded bool `json:"downloaded" jsonschema:"description=Whether the chapter is in the library"`
+}
+
+// progressOf returns the progress of the manga given all of its chapters and the listed ones
+func progressOf(manga *source.Manga, all, listed []*source.Chapter, isRead func(string, int) bool) *Progress {
+	progress := &Progress{
+		Total:    len(all),
+		Chapters: make([]*ChapterProgress, len(listed)),
+	}
+
+	for _, chapter := range all {
+		if isRead(manga.Name, chapter.Index) {
+			progress.Read++
+		} else {
+			progress.Unread++
+		}
+	}
+
+	for i, chapter := range listed {
+		progress.Chapters[i] = &ChapterProgress{
+			Index:      chapter.Index,
+			Read:       isRead(manga.Name, chapter.Index),
+			Downloaded: chapter.IsDownloaded(),
+		}
+	}
+
+	return progress
 }
 
 type Output struct {
@@ -22,7 +78,7 @@ type Output struct {
 	Result []*Manga `json:"result" jsonschema:"description=Result of the search."`
 }
 
-func asJson(manga []*source.Manga, options *Options) (marshalled []byte, err error) {
+func asJson(manga []*source.Manga, progress map[*source.Manga]*Progress, options *Options) (marshalled []byte, err error) {
 	var m = make([]*Manga, len(manga))
 	for i, manga := range manga {
 		al := manga.Anilist.OrElse(nil)
@@ -31,9 +87,12 @@ func asJson(manga []*source.Manga, options *Options) (marshalled []byte, err err
 		}
 
 		m[i] = &Manga{
-			Mangal:  manga,
-			Anilist: al,
-			Source:  manga.Source.Name(),
+			Mangal:   manga,
+			Authors:  manga.Authors(),
+			Artists:  manga.Artists(),
+			Anilist:  al,
+			Source:   manga.Source.Name(),
+			Progress: progress[manga],
 		}
 	}
 
@@ -43,34 +102,54 @@ func asJson(manga []*source.Manga, options *Options) (marshalled []byte, err err
 	})
 }
 
-func prepareManga(manga *source.Manga, options *Options) error {
-	var err error
+// prepareManga loads the chapters and the metadata of the manga for the output.
+// The progress is returned when the chapters are loaded, nil otherwise
+func prepareManga(manga *source.Manga, isRead func(string, int) bool, options *Options) (*Progress, error) {
+	var (
+		progress *Progress
+		err      error
+	)
 
 	if options.IncludeAnilistManga {
 		err = manga.BindWithAnilist()
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if options.ChaptersFilter.IsPresent() {
-		chapters, err := manga.Source.ChaptersOf(manga)
+		all, err := manga.Source.ChaptersOf(manga)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		chapters, err = options.ChaptersFilter.MustGet()(chapters)
+		chapters, err := options.ChaptersFilter.MustGet()(all)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		manga.Chapters = chapters
+		progress = progressOf(manga, all, chapters, isRead)
 
 		if options.PopulatePages {
 			for _, chapter := range chapters {
 				_, err := chapter.Source().PagesOf(chapter)
 				if err != nil {
-					return err
+					return nil, err
+				}
+			}
+		}
+
+		if viper.GetBool(key.DownloaderEstimateSize) {
+			for _, chapter := range chapters {
+				// pages are fetched to estimate the size, but only listed if asked to
+				pages := chapter.Pages
+				if _, err := chapter.EstimateSize(context.Background()); err != nil {
+					log.Warn(err)
+				}
+
+				if !options.PopulatePages {
+					chapter.Pages = pages
 				}
 			}
 		}
@@ -83,5 +162,5 @@ func prepareManga(manga *source.Manga, options *Options) error {
 		_ = manga.PopulateMetadata(func(string) {})
 	}
 
-	return nil
+	return progress, nil
 }
@@ -0,0 +1,52 @@
+package inline
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"os"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+func TestProgressOf(t *testing.T) {
+	Convey("Given a manga read up to the second chapter", t, func() {
+		viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+		viper.Set(key.FormatsUse, constant.FormatCBZ)
+		manga := &source.Manga{Name: "Progress"}
+		for i := 1; i <= 4; i++ {
+			manga.Chapters = append(manga.Chapters, &source.Chapter{Name: fmt.Sprintf("Chapter %d", i), Index: i, Manga: manga})
+		}
+
+		isRead := func(name string, index int) bool {
+			return name == manga.Name && index <= 2
+		}
+
+		downloaded := manga.Chapters[2]
+		So(filesystem.Api().WriteFile(downloaded.PeekPath(), []byte{}, os.ModePerm), ShouldBeNil)
+
+		Convey("When the progress of the last two chapters is made", func() {
+			progress := progressOf(manga, manga.Chapters, manga.Chapters[2:], isRead)
+
+			Convey("It should count the chapters of the whole manga", func() {
+				So(progress.Total, ShouldEqual, 4)
+				So(progress.Read, ShouldEqual, 2)
+				So(progress.Unread, ShouldEqual, 2)
+			})
+
+			Convey("It should have the statuses of the listed chapters", func() {
+				So(progress.Chapters, ShouldResemble, []*ChapterProgress{
+					{Index: 3, Read: false, Downloaded: true},
+					{Index: 4, Read: false, Downloaded: false},
+				})
+			})
+		})
+	})
+}
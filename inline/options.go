@@ -1,6 +1,7 @@
 package inline
 
 import (
+	"errors"
 	"fmt"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
@@ -18,8 +19,13 @@ type (
 )
 
 type Options struct {
-	Out                 io.Writer
-	Sources             []source.Source
+	Out io.Writer
+	// Summary is where the JSON summary of the download is written to, if set
+	Summary io.Writer
+	Sources []source.Source
+	// Fallback searches the sources in order until one of them finds the manga, see key.SourcesPriority.
+	// All the sources are searched otherwise
+	Fallback            bool
 	IncludeAnilistManga bool
 	Download            bool
 	Json                bool
@@ -29,18 +35,62 @@ type Options struct {
 	ChaptersFilter      mo.Option[ChaptersFilter]
 }
 
-func ParseMangaPicker(query, description string) (MangaPicker, error) {
-	const (
-		first = "first"
-		last  = "last"
-		exact = "exact"
-	)
+// SelectorError is returned for the manga pickers and the chapter filters that can't be parsed
+type SelectorError struct {
+	// Kind of the selector, "manga picker" or "chapter filter"
+	Kind     string
+	Selector string
+	Reason   string
+}
 
-	pattern := fmt.Sprintf(`^(%s|%s|%s|\d+)$`, first, last, exact)
-	mangaPickerRegex := regexp.MustCompile(pattern)
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("invalid %s pattern %q: %s", e.Kind, e.Selector, e.Reason)
+}
+
+const (
+	first = "first"
+	last  = "last"
+	exact = "exact"
+	all   = "all"
+	from  = "From"
+	to    = "To"
+	sub   = "Sub"
+)
+
+var (
+	mangaPickerRegex    = regexp.MustCompile(fmt.Sprintf(`^(%s|%s|%s|\d+)$`, first, last, exact))
+	chaptersFilterRegex = regexp.MustCompile(fmt.Sprintf(`^(%s|%s|%s|(?P<%s>\d+)(-(?P<%s>\d+))?|@(?P<%s>.+)@)$`, first, last, all, from, to, sub))
+	// languageFilterRegex is the language prefix of the chapter filter, e.g. "lang:en,pt-br"
+	languageFilterRegex = regexp.MustCompile(`^lang:([a-zA-Z]+(?:-[a-zA-Z0-9]+)*(?:,[a-zA-Z]+(?:-[a-zA-Z0-9]+)*)*)(?:\s+|$)`)
+)
+
+// parseIndex parses the index of the selector, e.g. the one that is too big for an int
+func parseIndex(kind, selector, index string) (int, error) {
+	parsed, err := source.ParseIndex(index)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, &SelectorError{Kind: kind, Selector: selector, Reason: fmt.Sprintf("index %s is too big", index)}
+		}
+
+		return 0, &SelectorError{Kind: kind, Selector: selector, Reason: err.Error()}
+	}
+
+	return parsed, nil
+}
+
+func ParseMangaPicker(query, description string) (MangaPicker, error) {
+	const kind = "manga picker"
 
 	if !mangaPickerRegex.MatchString(description) {
-		return nil, fmt.Errorf("invalid manga picker pattern: %s", description)
+		return nil, &SelectorError{Kind: kind, Selector: description, Reason: "expected first, last, exact or the index"}
+	}
+
+	var index int
+	if !lo.Contains([]string{first, last, exact}, description) {
+		var err error
+		if index, err = parseIndex(kind, description, description); err != nil {
+			return nil, err
+		}
 	}
 
 	return func(mangas []*source.Manga) *source.Manga {
@@ -55,34 +105,81 @@ func ParseMangaPicker(query, description string) (MangaPicker, error) {
 			return mangas[len(mangas)-1]
 		case exact:
 			for _, manga := range mangas {
-				if manga.Name == query {
+				if manga.HasTitle(query) {
 					return manga
 				}
 			}
 
 			return nil
 		default:
-			index := lo.Must(strconv.ParseUint(description, 10, 16))
-			return mangas[util.Min(index, uint64(len(mangas)-1))]
+			return mangas[util.Min(index, len(mangas)-1)]
 		}
 	}, nil
 }
 
+// ParseChaptersFilter parses the chapter filter: first, last, all, the index, the range of indexes or @substring@.
+// It may be prefixed by the languages of the chapters, e.g. "lang:en 0-9", the filter is then applied
+// to the chapters in these languages only. Languages without a region match their regional variants too
 func ParseChaptersFilter(description string) (ChaptersFilter, error) {
-	const (
-		first = "first"
-		last  = "last"
-		all   = "all"
-		from  = "From"
-		to    = "To"
-		sub   = "Sub"
+	if match := languageFilterRegex.FindStringSubmatch(description); match != nil {
+		rest := strings.TrimSpace(description[len(match[0]):])
+		if rest == "" {
+			rest = all
+		}
+
+		filter, err := ParseChaptersFilter(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		languages := strings.Split(strings.ToLower(match[1]), ",")
+		return func(chapters []*source.Chapter) ([]*source.Chapter, error) {
+			return filter(lo.Filter(chapters, func(chapter *source.Chapter, _ int) bool {
+				return inLanguages(chapter, languages)
+			}))
+		}, nil
+	}
+
+	return parseChaptersFilter(description)
+}
+
+// inLanguages reports whether the chapter is in any of the languages, "pt" matches "pt-br" as well
+func inLanguages(chapter *source.Chapter, languages []string) bool {
+	language := strings.ToLower(chapter.TranslatedLanguage())
+	for _, l := range languages {
+		if language == l || strings.HasPrefix(language, l+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseChaptersFilter(description string) (ChaptersFilter, error) {
+	const kind = "chapter filter"
+
+	if !chaptersFilterRegex.MatchString(description) {
+		return nil, &SelectorError{Kind: kind, Selector: description, Reason: "expected first, last, all, the index, the range of indexes or @substring@, optionally prefixed by lang:code"}
+	}
+
+	var (
+		groups     = util.ReGroups(chaptersFilterRegex, description)
+		isRange    = groups[to] != ""
+		start, end int
+		err        error
 	)
 
-	pattern := fmt.Sprintf(`^(%s|%s|%s|(?P<%s>\d+)(-(?P<%s>\d+))?|@(?P<%s>.+)@)$`, first, last, all, from, to, sub)
-	mangaPickerRegex := regexp.MustCompile(pattern)
+	// indexes are parsed beforehand, so that the filter fails here instead of panicking later
+	if groups[from] != "" {
+		if start, err = parseIndex(kind, description, groups[from]); err != nil {
+			return nil, err
+		}
+	}
 
-	if !mangaPickerRegex.MatchString(description) {
-		return nil, fmt.Errorf("invalid chapter filter pattern: %s", description)
+	if isRange {
+		if end, err = parseIndex(kind, description, groups[to]); err != nil {
+			return nil, err
+		}
 	}
 
 	return func(chapters []*source.Chapter) ([]*source.Chapter, error) {
@@ -98,25 +195,18 @@ func ParseChaptersFilter(description string) (ChaptersFilter, error) {
 		case all:
 			return chapters, nil
 		default:
-			groups := util.ReGroups(mangaPickerRegex, description)
-
 			if sub, ok := groups[sub]; ok && sub != "" {
 				return lo.Filter(chapters, func(a *source.Chapter, _ int) bool {
 					return strings.Contains(a.Name, sub)
 				}), nil
 			}
 
-			from := lo.Must(strconv.ParseUint(groups[from], 10, 16))
-			from = util.Min(from, uint64(len(chapters)))
-
-			n := groups[to]
-			if n == "" {
+			from := util.Min(start, len(chapters)-1)
+			if !isRange {
 				return []*source.Chapter{chapters[from]}, nil
 			}
 
-			to := lo.Must(strconv.ParseUint(n, 10, 16))
-			to = util.Min(to, uint64(len(chapters)))
-
+			to := util.Min(end, len(chapters)-1)
 			if from > to {
 				from, to = to, from
 			}
@@ -0,0 +1,180 @@
+package inline
+
+import (
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func chaptersOf(count int) []*source.Chapter {
+	chapters := make([]*source.Chapter, count)
+	for i := range chapters {
+		chapters[i] = &source.Chapter{Name: fmt.Sprintf("Chapter %d", i+1), Index: i + 1}
+	}
+
+	return chapters
+}
+
+func TestParseChaptersFilter(t *testing.T) {
+	Convey("Given 5 chapters", t, func() {
+		chapters := chaptersOf(5)
+
+		Convey("When filtering them by the reversed range", func() {
+			filter, err := ParseChaptersFilter("3-1")
+			So(err, ShouldBeNil)
+
+			filtered, err := filter(chapters)
+
+			Convey("Then the chapters between the indexes should be returned", func() {
+				So(err, ShouldBeNil)
+				So(filtered, ShouldResemble, chapters[1:4])
+			})
+		})
+
+		Convey("When filtering them by the index after the last chapter", func() {
+			filter, err := ParseChaptersFilter("10")
+			So(err, ShouldBeNil)
+
+			filtered, err := filter(chapters)
+
+			Convey("Then the last chapter should be returned", func() {
+				So(err, ShouldBeNil)
+				So(filtered, ShouldResemble, chapters[4:])
+			})
+		})
+	})
+
+	Convey("Given the chapters in several languages", t, func() {
+		chapters := chaptersOf(6)
+		for i, language := range []string{"en", "fr", "pt-br", "en", "", "pt"} {
+			chapters[i].Language = language
+		}
+
+		chapters[4].Manga = &source.Manga{Name: "Berserk"}
+		chapters[4].Manga.Metadata.Language = "EN"
+
+		Convey("When filtering them by the language", func() {
+			filter, err := ParseChaptersFilter("lang:en")
+			So(err, ShouldBeNil)
+
+			filtered, err := filter(chapters)
+
+			Convey("Then the chapters in the language, or of the manga in it, should be returned", func() {
+				So(err, ShouldBeNil)
+				So(filtered, ShouldResemble, []*source.Chapter{chapters[0], chapters[3], chapters[4]})
+			})
+		})
+
+		Convey("When filtering them by the languages and the range", func() {
+			filter, err := ParseChaptersFilter("lang:pt,fr 1-2")
+			So(err, ShouldBeNil)
+
+			filtered, err := filter(chapters)
+
+			Convey("Then the range should be of the chapters in the languages, including the regional ones", func() {
+				So(err, ShouldBeNil)
+				So(filtered, ShouldResemble, []*source.Chapter{chapters[2], chapters[5]})
+			})
+		})
+
+		Convey("When the selector after the language is malformed", func() {
+			_, err := ParseChaptersFilter("lang:en 1-")
+
+			Convey("Then the selector error should be returned", func() {
+				var selectorErr *SelectorError
+				So(errors.As(err, &selectorErr), ShouldBeTrue)
+				So(selectorErr.Selector, ShouldEqual, "1-")
+			})
+		})
+	})
+
+	Convey("When parsing the filter with the index too big for an int", t, func() {
+		_, err := ParseChaptersFilter("1-99999999999999999999999")
+
+		Convey("Then the selector error should be returned instead of panicking later", func() {
+			var selectorErr *SelectorError
+			So(errors.As(err, &selectorErr), ShouldBeTrue)
+			So(selectorErr.Kind, ShouldEqual, "chapter filter")
+			So(selectorErr.Reason, ShouldContainSubstring, "too big")
+		})
+	})
+
+	Convey("When parsing the malformed filter", t, func() {
+		_, err := ParseChaptersFilter("1-")
+
+		Convey("Then the selector error should be returned", func() {
+			var selectorErr *SelectorError
+			So(errors.As(err, &selectorErr), ShouldBeTrue)
+			So(selectorErr.Selector, ShouldEqual, "1-")
+		})
+	})
+}
+
+func TestParseMangaPicker(t *testing.T) {
+	Convey("When parsing the picker with the index too big for an int", t, func() {
+		_, err := ParseMangaPicker("", "99999999999999999999999")
+
+		Convey("Then the selector error should be returned", func() {
+			var selectorErr *SelectorError
+			So(errors.As(err, &selectorErr), ShouldBeTrue)
+			So(selectorErr.Kind, ShouldEqual, "manga picker")
+		})
+	})
+}
+
+func FuzzParseChaptersFilter(f *testing.F) {
+	for _, seed := range []string{"first", "last", "all", "1", "2-4", "4-2", "@Chapter 1@", "@@", "1-", "-1", "99999999999999999999", "lang:en", "lang:en,pt-br 1-2", "lang: 1"} {
+		f.Add(seed, 3)
+	}
+
+	f.Fuzz(func(t *testing.T, description string, count int) {
+		filter, err := ParseChaptersFilter(description)
+		if err != nil {
+			var selectorErr *SelectorError
+			if !errors.As(err, &selectorErr) {
+				t.Fatalf("unexpected error %T: %s", err, err)
+			}
+
+			return
+		}
+
+		chapters := chaptersOf(((count % 10) + 10) % 10)
+		filtered, err := filter(chapters)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(filtered) > len(chapters) {
+			t.Fatalf("%d chapters filtered out of %d", len(filtered), len(chapters))
+		}
+	})
+}
+
+func FuzzParseMangaPicker(f *testing.F) {
+	for _, seed := range []string{"first", "last", "exact", "0", "5", "-1", "99999999999999999999"} {
+		f.Add(seed, 3)
+	}
+
+	f.Fuzz(func(t *testing.T, description string, count int) {
+		picker, err := ParseMangaPicker("Manga 1", description)
+		if err != nil {
+			var selectorErr *SelectorError
+			if !errors.As(err, &selectorErr) {
+				t.Fatalf("unexpected error %T: %s", err, err)
+			}
+
+			return
+		}
+
+		mangas := make([]*source.Manga, ((count%10)+10)%10)
+		for i := range mangas {
+			mangas[i] = &source.Manga{Name: fmt.Sprintf("Manga %d", i)}
+		}
+
+		if picked := picker(mangas); picked == nil && len(mangas) > 0 && description != "exact" {
+			t.Fatalf("nothing picked out of %d mangas", len(mangas))
+		}
+	})
+}
@@ -3,6 +3,7 @@ package installer
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/metafates/mangal/network"
 	"github.com/metafates/mangal/util"
 	"io"
 	"net/http"
@@ -29,7 +30,7 @@ func (g *githubFilesCollector) collect() error {
 	}
 
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees/%s?recursive=1", g.user, g.repo, g.branch)
-	res, err := http.Get(url)
+	res, err := network.Client.Get(url)
 	if err != nil {
 		return err
 	}
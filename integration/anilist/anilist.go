@@ -1,12 +1,15 @@
 package anilist
 
 import (
+	"github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/key"
 	"github.com/spf13/viper"
+	"sync"
 )
 
 type Anilist struct {
-	token string
+	// mutex guards the login, chapters are marked read concurrently
+	mutex sync.Mutex
 }
 
 // New cereates a new Anilist integration instance
@@ -19,7 +22,7 @@ func (a *Anilist) id() string {
 }
 
 func (a *Anilist) secret() string {
-	return viper.GetString(key.AnilistSecret)
+	return anilist.Secret()
 }
 
 func (a *Anilist) code() string {
@@ -28,5 +31,22 @@ func (a *Anilist) code() string {
 
 // AuthURL returns the URL to authenticate with Anilist
 func (a *Anilist) AuthURL() string {
-	return "https://anilist.co/api/v2/oauth/authorize?client_id=" + a.id() + "&response_type=code&redirect_uri=https://anilist.co/api/v2/oauth/pin"
+	return anilist.AuthURL(a.id())
+}
+
+// authorize logs in with the code of the config unless the token is stored already, see mangal anilist login
+func (a *Anilist) authorize() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if anilist.IsLoggedIn() {
+		return nil
+	}
+
+	if a.code() == "" {
+		return anilist.ErrNotLoggedIn
+	}
+
+	_, err := anilist.Login(a.id(), a.secret(), a.code())
+	return err
 }
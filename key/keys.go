@@ -3,11 +3,14 @@ package key
 // DefinedFieldsCount is the number of fields defined in this package.
 // You have to manually update this number when you add a new field
 // to check later if every field has a defined default value
-const DefinedFieldsCount = 53
+const DefinedFieldsCount = 122
 
 const (
 	DownloaderPath                = "downloader.path"
+	DownloaderFallbackPath        = "downloader.fallback_path"
 	DownloaderChapterNameTemplate = "downloader.chapter_name_template"
+	DownloaderChapterTitle        = "downloader.chapter_title"
+	DownloaderChapterTitleLength  = "downloader.chapter_title_length"
 	DownloaderAsync               = "downloader.async"
 	DownloaderCreateMangaDir      = "downloader.create_manga_dir"
 	DownloaderCreateVolumeDir     = "downloader.create_volume_dir"
@@ -16,11 +19,48 @@ const (
 	DownloaderDownloadCover       = "downloader.download_cover"
 	DownloaderRedownloadExisting  = "downloader.redownload_existing"
 	DownloaderReadDownloaded      = "downloader.read_downloaded"
+	DownloaderRetryFailed         = "downloader.retry_failed"
+	DownloaderContinuousNumbering = "downloader.continuous_numbering"
+	DownloaderEstimateSize        = "downloader.estimate_size"
+	DownloaderResume              = "downloader.resume"
+)
+
+const (
+	SourcesPriority = "sources.priority"
+)
+
+const (
+	NetworkTimeoutSearch     = "network.timeout_search"
+	NetworkTimeoutChapters   = "network.timeout_chapters"
+	NetworkTimeoutPages      = "network.timeout_pages"
+	NetworkTimeoutImages     = "network.timeout_images"
+	NetworkDoH               = "network.doh"
+	NetworkTLSBrowserCiphers = "network.tls_browser_ciphers"
+	NetworkIPVersion         = "network.ip_version"
+	NetworkBind              = "network.bind"
+	NetworkProxy             = "network.proxy"
 )
 
 const (
 	FormatsUse                   = "formats.use"
+	FormatsRules                 = "formats.rules"
+	FormatsFallback              = "formats.fallback"
 	FormatsSkipUnsupportedImages = "formats.skip_unsupported_images"
+	FormatsRawPassthrough        = "formats.raw_passthrough"
+	FormatsEncrypt               = "formats.encrypt"
+	FormatsVolumeBundles         = "formats.volume_bundles"
+	FormatsPageNameTemplate      = "formats.page_name_template"
+	FormatsPagePadding           = "formats.page_padding"
+	FormatsNormalizeExtensions   = "formats.normalize_extensions"
+	FormatsEPUBRightToLeft       = "formats.epub_right_to_left"
+	FormatsEPUBCover             = "formats.epub_cover"
+)
+
+const (
+	TranslatorEnable        = "translator.enable"
+	TranslatorEndpoint      = "translator.endpoint"
+	TranslatorLanguage      = "translator.language"
+	TranslatorKeepOriginals = "translator.keep_originals"
 )
 
 const (
@@ -30,12 +70,14 @@ const (
 	MetadataComicInfoXMLAlternativeDate       = "metadata.comic_info_xml_alternative_date"
 	MetadataComicInfoXMLTagRelevanceThreshold = "metadata.comic_info_xml_tag_relevance_threshold"
 	MetadataSeriesJSON                        = "metadata.series_json"
+	MetadataManifest                          = "metadata.manifest"
 )
 
 const (
 	ReaderPDF           = "reader.pdf"
 	ReaderCBZ           = "reader.cbz"
 	ReaderZIP           = "reader.zip"
+	ReaderEPUB          = "reader.epub"
 	RaderPlain          = "reader.plain"
 	ReaderBrowser       = "reader.browser"
 	ReaderFolder        = "reader.folder"
@@ -43,8 +85,11 @@ const (
 )
 
 const (
-	HistorySaveOnRead     = "history.save_on_read"
-	HistorySaveOnDownload = "history.save_on_download"
+	HistorySaveOnRead      = "history.save_on_read"
+	HistorySaveOnDownload  = "history.save_on_download"
+	HistoryRetentionMonths = "history.retention_months"
+	HistoryMaxEntries      = "history.max_entries"
+	HistoryDigestWebhook   = "history.digest_webhook"
 )
 
 const (
@@ -57,12 +102,34 @@ const (
 
 const (
 	IconsVariant = "icons.variant"
+	IconsCustom  = "icons.custom"
+)
+
+const (
+	I18nLocale = "i18n.locale"
 )
 
 const (
 	MangadexLanguage                = "mangadex.language"
 	MangadexNSFW                    = "mangadex.nsfw"
 	MangadexShowUnavailableChapters = "mangadex.show_unavailable_chapters"
+	MangadexDataSaver               = "mangadex.data_saver"
+	MangadexGroups                  = "mangadex.groups"
+)
+
+const (
+	TapasEmail    = "tapas.email"
+	TapasPassword = "tapas.password"
+)
+
+const (
+	LezhinEmail    = "lezhin.email"
+	LezhinPassword = "lezhin.password"
+	LezhinLanguage = "lezhin.language"
+)
+
+const (
+	MockEnable = "mock.enable"
 )
 
 const (
@@ -80,6 +147,11 @@ const (
 	TUIShowURLs           = "tui.show_urls"
 	TUIShowDownloadedPath = "tui.show_downloaded_path"
 	TUIReverseChapters    = "tui.reverse_chapters"
+	TUIConfirmCountPages  = "tui.confirm_count_pages"
+	TUISearchFilter       = "tui.search_filter"
+	TUIDetectClipboard    = "tui.detect_clipboard"
+	TUIAccessible         = "tui.accessible"
+	TUIAbsoluteTime       = "tui.absolute_time"
 )
 
 const (
@@ -93,12 +165,40 @@ const (
 )
 
 const (
-	LogsWrite = "logs.write"
-	LogsLevel = "logs.level"
-	LogsJson  = "logs.json"
+	LibraryQuota              = "library.quota"
+	LibraryEvictCompletedLast = "library.evict_completed_last"
+	LibraryUpdateConcurrency  = "library.update_concurrency"
+	LibraryUpdateRate         = "library.update_rate"
+	LibraryUpdateWebhook      = "library.update_webhook"
+	LibraryRemotes            = "library.remotes"
+)
+
+const (
+	ServerAddress = "server.address"
+	ServerPidFile = "server.pid_file"
+	ServerPprof   = "server.pprof"
+
+	ServerAuth           = "server.auth"
+	ServerAuthHeader     = "server.auth_header"
+	ServerTrustedProxies = "server.trusted_proxies"
+	ServerLibrary        = "server.library"
+)
+
+const (
+	LogsWrite     = "logs.write"
+	LogsLevel     = "logs.level"
+	LogsJson      = "logs.json"
+	LogsDumpPages = "logs.dump_pages"
+)
+
+const (
+	CrashSubmit   = "crash.submit"
+	CrashEndpoint = "crash.endpoint"
 )
 
 const (
 	CliColored      = "cli.colored"
 	CliVersionCheck = "cli.version_check"
+	CliHeadless     = "cli.headless"
+	CliAppearance   = "cli.appearance"
 )
@@ -0,0 +1,81 @@
+package library
+
+import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/update"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChapterFormats are the formats of the chapter files.
+// Plain chapters are directories and are not listed as chapters
+var ChapterFormats = []string{constant.FormatCBZ, constant.FormatZIP, constant.FormatPDF, constant.FormatEPUB}
+
+// Chapter is a downloaded chapter file
+type Chapter struct {
+	// Path relative to the manga directory
+	Path  string
+	Index int
+	Name  string
+	// URL of the chapter on its source, empty if it is not known from the manifest
+	URL string
+}
+
+// Chapters returns the chapter files of the downloaded manga.
+// Chapter indexes are taken from the manifest, if there is one, and guessed from the file names otherwise
+func (m *Manga) Chapters() ([]*Chapter, error) {
+	tracked, err := manifest.Load(m.Path)
+	if err != nil {
+		log.Warn(err)
+		tracked = &manifest.Manifest{}
+	}
+
+	var chapters []*Chapter
+	err = filesystem.Api().Walk(m.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// the volume bundles repeat the chapters
+		if info.IsDir() && info.Name() == source.BundlesDirname {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || !lo.Contains(ChapterFormats, strings.TrimPrefix(filepath.Ext(path), ".")) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(m.Path, path)
+		if err != nil {
+			return err
+		}
+
+		chapter := &Chapter{Path: relative}
+		chapter.Index, chapter.Name = update.ChapterFromFilename(path)
+		if file, ok := tracked.Files[filepath.ToSlash(relative)]; ok && file.Chapter != nil {
+			chapter.Index, chapter.Name, chapter.URL = file.Chapter.Index, file.Chapter.Name, file.Chapter.URL
+		}
+
+		chapters = append(chapters, chapter)
+
+		return nil
+	})
+
+	sort.Slice(chapters, func(i, j int) bool {
+		if chapters[i].Index != chapters[j].Index {
+			return chapters[i].Index < chapters[j].Index
+		}
+
+		return util.NaturalLess(chapters[i].Path, chapters[j].Path)
+	})
+
+	return chapters, err
+}
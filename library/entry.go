@@ -0,0 +1,147 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// EntryFilename is the name of the file inside the manga directory that marks it as tracked
+const EntryFilename = "mangal.json"
+
+// Entry is a tracked library manga.
+// It holds everything needed to find the manga on its source again
+type Entry struct {
+	Name     string    `json:"name"`
+	SourceID string    `json:"source_id"`
+	URL      string    `json:"url"`
+	ID       string    `json:"id"`
+	Added    time.Time `json:"added"`
+	// Subscription tells how new chapters are handled on update
+	Subscription Subscription `json:"subscription,omitempty"`
+	// Status is the publication status of the manga as of the last update, e.g. source.StatusFinished
+	Status string `json:"status,omitempty"`
+	// Completed is set once the manga is finished and every chapter is downloaded or read.
+	// Completed mangas are not checked for the new chapters on update
+	Completed bool `json:"completed,omitempty"`
+	// LastSeen is the highest index of the chapters on the source as of the last update,
+	// whether they were downloaded or not
+	LastSeen int `json:"last_seen,omitempty"`
+	// Seen are the chapters on the source as of the last updates, by their URLs, or by their IDs or names if they have none.
+	// Chapters are told new by them rather than by their indexes, which change when the source inserts or removes one
+	Seen []string `json:"seen,omitempty"`
+	// Checked is the time of the last update, zero if the manga was never checked
+	Checked time.Time `json:"checked,omitempty"`
+	// Overrides of the config for this manga
+	Overrides Overrides `json:"overrides,omitempty"`
+	// Sources the manga can be updated from
+	Sources Sources `json:"sources,omitempty"`
+	// Kept are the chapter files, relative to the manga directory, that are kept as they are
+	// even though they differ from the chapters on the source
+	Kept []string `json:"kept,omitempty"`
+}
+
+const (
+	// SubscriptionAll downloads every new chapter and keeps everything on disk
+	SubscriptionAll = "all"
+	// SubscriptionLatestOnly keeps only the newest chapters on disk,
+	// older ones are deleted once they are read
+	SubscriptionLatestOnly = "latest-only"
+	// SubscriptionNotifyOnly only reports the new chapters, nothing is downloaded unless asked for
+	SubscriptionNotifyOnly = "notify-only"
+)
+
+// Subscription is the update setting of the tracked manga
+type Subscription struct {
+	// Mode is one of the Subscription* constants, empty means SubscriptionAll
+	Mode string `json:"mode,omitempty"`
+	// Keep is the number of the newest chapters to keep in SubscriptionLatestOnly mode
+	Keep int `json:"keep,omitempty"`
+}
+
+// LatestOnly reports whether only the newest chapters should be kept
+func (s Subscription) LatestOnly() bool {
+	return s.Mode == SubscriptionLatestOnly && s.Keep > 0
+}
+
+// NotifyOnly reports whether the new chapters should be reported without downloading them
+func (s Subscription) NotifyOnly() bool {
+	return s.Mode == SubscriptionNotifyOnly
+}
+
+// String returns human-readable description of the subscription
+func (s Subscription) String() string {
+	if s.LatestOnly() {
+		return fmt.Sprintf("latest %d", s.Keep)
+	}
+
+	if s.NotifyOnly() {
+		return SubscriptionNotifyOnly
+	}
+
+	return SubscriptionAll
+}
+
+// Entry returns the tracked entry of the manga or nil if manga is not tracked
+func (m *Manga) Entry() (*Entry, error) {
+	contents, err := filesystem.Api().ReadFile(filepath.Join(m.Path, EntryFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var entry Entry
+	if err = json.Unmarshal(contents, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Track adds the entry to the library without downloading anything.
+// Manga directory is created if it doesn't exist
+func Track(entry *Entry) (*Manga, error) {
+	return TrackIn(where.Downloads(), entry)
+}
+
+// TrackIn adds the entry to the library at the given root, see Track
+func TrackIn(root string, entry *Entry) (*Manga, error) {
+	owner := &source.Owner{Name: entry.Name, SourceID: entry.SourceID, ID: entry.ID}
+	manga := &Manga{
+		Name: entry.Name,
+		Path: filepath.Join(root, source.ResolveDirname(root, owner)),
+	}
+
+	err := source.Claim(manga.Path, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Added.IsZero() {
+		entry.Added = time.Now().UTC()
+	}
+
+	if err = manga.SaveEntry(entry); err != nil {
+		return nil, err
+	}
+
+	return manga, nil
+}
+
+// SaveEntry overwrites the tracked entry of the manga
+func (m *Manga) SaveEntry(entry *Entry) error {
+	contents, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(filepath.Join(m.Path, EntryFilename), contents, os.ModePerm)
+}
@@ -0,0 +1,89 @@
+// Package library provides access to the mangas downloaded to the downloads directory.
+package library
+
+import (
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/update"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Manga is a downloaded manga
+type Manga struct {
+	// Name of the manga as it was on the source
+	Name string
+	// Path to the manga directory
+	Path string
+}
+
+// Mangas returns all the mangas found in the downloads directory, sorted by name.
+// Each manga is expected to be in its own directory.
+func Mangas() ([]*Manga, error) {
+	return In(where.Downloads())
+}
+
+// In returns all the mangas found in the given directory, sorted by name
+func In(root string) ([]*Manga, error) {
+	entries, err := filesystem.Api().ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var mangas []*Manga
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manga := &Manga{Path: filepath.Join(root, entry.Name())}
+
+		// tracked mangas may have nothing downloaded yet
+		if tracked, err := manga.Entry(); err == nil && tracked != nil {
+			manga.Name = tracked.Name
+			mangas = append(mangas, manga)
+			continue
+		}
+
+		// directory name is sanitized, prefer the original name from the metadata
+		name, err := update.GetName(manga.Path)
+		if err != nil || name == "" {
+			log.Warnf("could not get name of %s from metadata, using directory name", manga.Path)
+			name = entry.Name()
+		}
+
+		manga.Name = name
+		mangas = append(mangas, manga)
+	}
+
+	sort.Slice(mangas, func(i, j int) bool {
+		return util.NaturalLess(mangas[i].Name, mangas[j].Name)
+	})
+
+	return mangas, nil
+}
+
+// Named returns the mangas matching any of the given names, case-insensitive.
+// Both original and directory names are matched.
+// If no names are given, all the mangas are returned
+func Named(names ...string) ([]*Manga, error) {
+	mangas, err := Mangas()
+	if err != nil || len(names) == 0 {
+		return mangas, err
+	}
+
+	var matched []*Manga
+	for _, manga := range mangas {
+		for _, name := range names {
+			if strings.EqualFold(name, manga.Name) || strings.EqualFold(name, filepath.Base(manga.Path)) {
+				matched = append(matched, manga)
+				break
+			}
+		}
+	}
+
+	return matched, nil
+}
@@ -0,0 +1,95 @@
+package library
+
+import (
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+func TestMangas(t *testing.T) {
+	Convey("Given a library with two mangas and a stray file", t, func() {
+		for _, name := range []string{"Naruto", "Berserk"} {
+			lo.Must0(filesystem.Api().MkdirAll(filepath.Join(where.Downloads(), name), os.ModePerm))
+		}
+		lo.Must0(filesystem.Api().WriteFile(filepath.Join(where.Downloads(), "file.txt"), []byte{}, os.ModePerm))
+
+		Convey("When listing mangas", func() {
+			mangas, err := Mangas()
+			Convey("Then only directories should be returned sorted by name", func() {
+				So(err, ShouldBeNil)
+				So(len(mangas), ShouldEqual, 2)
+				So(mangas[0].Name, ShouldEqual, "Berserk")
+				So(mangas[1].Name, ShouldEqual, "Naruto")
+			})
+		})
+
+		Convey("When getting mangas by name", func() {
+			mangas, err := Named("naruto", "One Piece")
+			Convey("Then only matching ones should be returned", func() {
+				So(err, ShouldBeNil)
+				So(len(mangas), ShouldEqual, 1)
+				So(mangas[0].Name, ShouldEqual, "Naruto")
+			})
+		})
+	})
+}
+
+func TestTrack(t *testing.T) {
+	Convey("Given a manga that is not in the library", t, func() {
+		entry := &Entry{Name: "Vinland Saga: Prologue", SourceID: "mangadex", URL: "https://example.com/vinland"}
+
+		Convey("When it is tracked", func() {
+			manga, err := Track(entry)
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be listed with its original name", func() {
+				mangas := lo.Must(Mangas())
+				found, ok := lo.Find(mangas, func(m *Manga) bool {
+					return m.Path == manga.Path
+				})
+
+				So(ok, ShouldBeTrue)
+				So(found.Name, ShouldEqual, "Vinland Saga: Prologue")
+
+				tracked := lo.Must(found.Entry())
+				So(tracked, ShouldNotBeNil)
+				So(tracked.SourceID, ShouldEqual, "mangadex")
+				So(tracked.Added.IsZero(), ShouldBeFalse)
+				So(tracked.Subscription.String(), ShouldEqual, SubscriptionAll)
+			})
+
+			Convey("And its subscription is changed", func() {
+				entry.Subscription = Subscription{Mode: SubscriptionLatestOnly, Keep: 3}
+				So(manga.SaveEntry(entry), ShouldBeNil)
+
+				Convey("Then it should be saved", func() {
+					tracked := lo.Must(manga.Entry())
+					So(tracked.Subscription.LatestOnly(), ShouldBeTrue)
+					So(tracked.Subscription.Keep, ShouldEqual, 3)
+				})
+			})
+
+			Convey("And chapters are downloaded", func() {
+				lo.Must0(filesystem.Api().WriteFile(filepath.Join(manga.Path, "[0002] Chapter 2.cbz"), []byte{}, os.ModePerm))
+				lo.Must0(filesystem.Api().WriteFile(filepath.Join(manga.Path, "cover.png"), []byte{}, os.ModePerm))
+
+				Convey("Then only chapter files should be listed", func() {
+					chapters := lo.Must(manga.Chapters())
+					So(len(chapters), ShouldEqual, 1)
+					So(chapters[0].Index, ShouldEqual, 2)
+					So(chapters[0].Path, ShouldEqual, "[0002] Chapter 2.cbz")
+				})
+			})
+		})
+	})
+}
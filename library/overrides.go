@@ -0,0 +1,113 @@
+package library
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Overrides are the settings of the tracked manga that take precedence over the config,
+// e.g. to convert the webtoons differently from the paged mangas of the same library.
+// Empty fields keep the config
+type Overrides struct {
+	// Formats to download the chapters in, the same as formats.use
+	Formats string `json:"formats,omitempty"`
+	// DeviceFormat is the format to send the chapters to the devices in, instead of the one of the device profile
+	DeviceFormat string `json:"device_format,omitempty"`
+	// RawPassthrough is the same as formats.raw_passthrough
+	RawPassthrough *bool `json:"raw_passthrough,omitempty"`
+	// SkipUnsupportedImages is the same as formats.skip_unsupported_images
+	SkipUnsupportedImages *bool `json:"skip_unsupported_images,omitempty"`
+	// NormalizeExtensions is the same as formats.normalize_extensions
+	NormalizeExtensions *bool `json:"normalize_extensions,omitempty"`
+	// PagePadding is the same as formats.page_padding
+	PagePadding *int `json:"page_padding,omitempty"`
+}
+
+// IsEmpty reports whether nothing is overridden
+func (o Overrides) IsEmpty() bool {
+	return o == Overrides{}
+}
+
+// String returns human-readable description of the overrides
+func (o Overrides) String() string {
+	var fields []string
+	if o.Formats != "" {
+		fields = append(fields, "formats "+o.Formats)
+	}
+
+	if o.DeviceFormat != "" {
+		fields = append(fields, "device format "+o.DeviceFormat)
+	}
+
+	for name, value := range map[string]*bool{
+		"raw passthrough":         o.RawPassthrough,
+		"skip unsupported images": o.SkipUnsupportedImages,
+		"normalize extensions":    o.NormalizeExtensions,
+	} {
+		if value != nil {
+			fields = append(fields, fmt.Sprintf("%s %t", name, *value))
+		}
+	}
+
+	if o.PagePadding != nil {
+		fields = append(fields, fmt.Sprintf("page padding %d", *o.PagePadding))
+	}
+
+	if len(fields) == 0 {
+		return "none"
+	}
+
+	// map order is random
+	sort.Strings(fields)
+	return strings.Join(fields, ", ")
+}
+
+// Settings returns the settings of the download of the manga to the library at root, with the overridden fields
+func (o Overrides) Settings(root string) *source.Settings {
+	settings := &source.Settings{
+		Root:                  root,
+		RawPassthrough:        o.RawPassthrough,
+		SkipUnsupportedImages: o.SkipUnsupportedImages,
+		NormalizeExtensions:   o.NormalizeExtensions,
+		PagePadding:           o.PagePadding,
+	}
+
+	// rules are for the destinations, the manga in the library must get its own formats
+	if o.Formats != "" {
+		settings.Formats = source.ParseFormats(o.Formats)
+	}
+
+	return settings
+}
+
+// OverridesOf returns the overrides of the manga if it is tracked in the downloads directory
+func OverridesOf(manga *source.Manga) Overrides {
+	return OverridesIn(where.Downloads(), manga)
+}
+
+// OverridesIn returns the overrides of the manga if it is tracked in the library at root
+func OverridesIn(root string, manga *source.Manga) Overrides {
+	if manga == nil || !viper.GetBool(key.DownloaderCreateMangaDir) {
+		return Overrides{}
+	}
+
+	tracked := &Manga{Path: filepath.Join(root, manga.DirnameIn(root))}
+	entry, err := tracked.Entry()
+	if err != nil {
+		log.Warn(err)
+		return Overrides{}
+	}
+
+	if entry == nil {
+		return Overrides{}
+	}
+
+	return entry.Overrides
+}
@@ -0,0 +1,57 @@
+package library
+
+import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestOverrides(t *testing.T) {
+	Convey("Given the overrides of a webtoon", t, func() {
+		viper.Set(key.FormatsUse, constant.FormatCBZ)
+		viper.Set(key.FormatsRawPassthrough, false)
+
+		overrides := Overrides{Formats: constant.FormatEPUB, RawPassthrough: lo.ToPtr(true)}
+
+		Convey("When they are turned into the settings of the download", func() {
+			manga := &source.Manga{Name: "Tower of God", Settings: overrides.Settings("/library")}
+			chapter := &source.Chapter{Name: "Chapter 1", Manga: manga}
+
+			Convey("Then the chapters of the manga should be overridden", func() {
+				So(chapter.Formats(), ShouldResemble, []string{constant.FormatEPUB})
+				So(manga.Settings.Root, ShouldEqual, "/library")
+				So(*manga.Settings.RawPassthrough, ShouldBeTrue)
+			})
+
+			Convey("And the config should stay as it is", func() {
+				So(source.PrimaryFormat(), ShouldEqual, constant.FormatCBZ)
+				So(viper.GetBool(key.FormatsRawPassthrough), ShouldBeFalse)
+				So((&source.Chapter{Manga: &source.Manga{}}).Formats(), ShouldResemble, []string{constant.FormatCBZ})
+			})
+		})
+
+		Convey("Then they should be described", func() {
+			So(overrides.String(), ShouldEqual, "formats epub, raw passthrough true")
+		})
+	})
+
+	Convey("Given a tracked manga with the overrides", t, func() {
+		viper.Set(key.DownloaderCreateMangaDir, true)
+		manga := &source.Manga{Name: "Tower of God", ID: "tog"}
+
+		_, err := Track(&Entry{Name: manga.Name, ID: manga.ID, Overrides: Overrides{DeviceFormat: constant.FormatPDF}})
+		So(err, ShouldBeNil)
+
+		Convey("Then they should be found by the manga", func() {
+			So(OverridesOf(manga).DeviceFormat, ShouldEqual, constant.FormatPDF)
+		})
+
+		Convey("And an untracked manga should have none", func() {
+			So(OverridesOf(&source.Manga{Name: "Berserk"}).IsEmpty(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package library
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"strings"
+)
+
+// Sources limit the sources the tracked manga can be updated from,
+// so that it never moves to a low-quality mirror without being asked to
+type Sources struct {
+	// Pinned are the IDs of the only sources allowed, any source is allowed if empty
+	Pinned []string `json:"pinned,omitempty"`
+	// Blocked are the IDs of the sources that are never used
+	Blocked []string `json:"blocked,omitempty"`
+}
+
+// IsEmpty reports whether every source is allowed
+func (s Sources) IsEmpty() bool {
+	return len(s.Pinned) == 0 && len(s.Blocked) == 0
+}
+
+// Allows reports whether the source with the given ID can be used
+func (s Sources) Allows(id string) bool {
+	return s.Check(id) == nil
+}
+
+// Check returns an error telling why the source with the given ID can't be used, nil if it can
+func (s Sources) Check(id string) error {
+	if lo.Contains(s.Blocked, id) {
+		return fmt.Errorf("source %s is blocked", id)
+	}
+
+	if len(s.Pinned) > 0 && !lo.Contains(s.Pinned, id) {
+		return fmt.Errorf("source %s is not pinned, pinned sources are: %s", id, strings.Join(s.Pinned, ", "))
+	}
+
+	return nil
+}
+
+// Pin allows the sources with the given IDs, they are unblocked if needed
+func (s *Sources) Pin(ids ...string) {
+	s.Pinned = lo.Uniq(append(s.Pinned, ids...))
+	s.Blocked = lo.Without(s.Blocked, ids...)
+}
+
+// Block forbids the sources with the given IDs, they are unpinned if needed
+func (s *Sources) Block(ids ...string) {
+	s.Blocked = lo.Uniq(append(s.Blocked, ids...))
+	s.Pinned = lo.Without(s.Pinned, ids...)
+}
+
+// String returns human-readable description of the sources
+func (s Sources) String() string {
+	var fields []string
+	if len(s.Pinned) > 0 {
+		fields = append(fields, "pinned "+strings.Join(s.Pinned, ", "))
+	}
+
+	if len(s.Blocked) > 0 {
+		fields = append(fields, "blocked "+strings.Join(s.Blocked, ", "))
+	}
+
+	if len(fields) == 0 {
+		return "any source"
+	}
+
+	return strings.Join(fields, "; ")
+}
@@ -0,0 +1,53 @@
+package library
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSources(t *testing.T) {
+	Convey("Given the sources of a manga with a pinned and a blocked source", t, func() {
+		var sources Sources
+		sources.Pin("mangadex")
+		sources.Block("mirror")
+
+		Convey("Then only the pinned source should be allowed", func() {
+			So(sources.Allows("mangadex"), ShouldBeTrue)
+			So(sources.Allows("mirror"), ShouldBeFalse)
+			So(sources.Allows("manganelo"), ShouldBeFalse)
+		})
+
+		Convey("When the pinned source is blocked", func() {
+			sources.Block("mangadex")
+
+			Convey("Then it should be unpinned and the other sources should be allowed", func() {
+				So(sources.Pinned, ShouldBeEmpty)
+				So(sources.Allows("mangadex"), ShouldBeFalse)
+				So(sources.Allows("manganelo"), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the blocked source is pinned", func() {
+			sources.Pin("mirror")
+
+			Convey("Then it should be unblocked", func() {
+				So(sources.Blocked, ShouldBeEmpty)
+				So(sources.Allows("mirror"), ShouldBeTrue)
+			})
+		})
+
+		Convey("Then they should be described", func() {
+			So(sources.String(), ShouldEqual, "pinned mangadex; blocked mirror")
+		})
+	})
+
+	Convey("Given the empty sources", t, func() {
+		var sources Sources
+
+		Convey("Then any source should be allowed", func() {
+			So(sources.IsEmpty(), ShouldBeTrue)
+			So(sources.Allows("mirror"), ShouldBeTrue)
+			So(sources.String(), ShouldEqual, "any source")
+		})
+	})
+}
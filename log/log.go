@@ -17,6 +17,10 @@ import (
 var writeLogs bool
 
 func Setup() error {
+	if viper.GetBool(key.CliHeadless) {
+		return SetupHeadless()
+	}
+
 	writeLogs = viper.GetBool(key.LogsWrite)
 
 	if !writeLogs {
@@ -47,6 +51,28 @@ func Setup() error {
 		log.SetFormatter(&log.TextFormatter{})
 	}
 
+	setLevel()
+	return nil
+}
+
+// SetupHeadless writes logs to stderr as plain text (or json, if enabled),
+// so that they are collected by docker or journald
+func SetupHeadless() error {
+	writeLogs = true
+
+	log.SetOutput(os.Stderr)
+
+	if viper.GetBool(key.LogsJson) {
+		log.SetFormatter(&log.JSONFormatter{})
+	} else {
+		log.SetFormatter(&log.TextFormatter{DisableColors: true, FullTimestamp: true})
+	}
+
+	setLevel()
+	return nil
+}
+
+func setLevel() {
 	switch viper.GetString(key.LogsLevel) {
 	case "panic":
 		log.SetLevel(log.PanicLevel)
@@ -65,90 +91,88 @@ func Setup() error {
 	default:
 		log.SetLevel(log.InfoLevel)
 	}
-
-	return nil
 }
 
 func Panic(args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Panic(args...)
 	}
 }
 
 func Panicf(format string, args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Panicf(format, args...)
 	}
 }
 
 func Fatal(args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Fatal(args...)
 	}
 }
 
 func Fatalf(format string, args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Fatalf(format, args...)
 	}
 }
 
 func Error(args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Error(args...)
 	}
 }
 
 func Errorf(format string, args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Errorf(format, args...)
 	}
 }
 
 func Warn(args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Warn(args...)
 	}
 }
 
 func Warnf(format string, args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Warnf(format, args...)
 	}
 }
 
 func Info(args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Info(args...)
 	}
 }
 
 func Infof(format string, args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Infof(format, args...)
 	}
 }
 
 func Debug(args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Debug(args...)
 	}
 }
 
 func Debugf(format string, args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Debugf(format, args...)
 	}
 }
 
 func Trace(args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Trace(args...)
 	}
 }
 
 func Tracef(format string, args ...interface{}) {
-	if writeLogs {
+	if enabled() {
 		log.Tracef(format, args...)
 	}
 }
@@ -0,0 +1,63 @@
+package log
+
+import log "github.com/sirupsen/logrus"
+
+// sourceField is the field of the entries with the name of the source
+const sourceField = "source"
+
+// SourceLogger logs the messages about a source, see Source
+type SourceLogger struct {
+	name string
+}
+
+// Source returns the logger of the messages about the source,
+// so that they can be told apart in the log pane of the TUI and in the log file
+func Source(name string) SourceLogger {
+	return SourceLogger{name: name}
+}
+
+func (s SourceLogger) entry() *log.Entry {
+	return log.WithField(sourceField, s.name)
+}
+
+func (s SourceLogger) Error(args ...interface{}) {
+	if enabled() {
+		s.entry().Error(args...)
+	}
+}
+
+func (s SourceLogger) Errorf(format string, args ...interface{}) {
+	if enabled() {
+		s.entry().Errorf(format, args...)
+	}
+}
+
+func (s SourceLogger) Warn(args ...interface{}) {
+	if enabled() {
+		s.entry().Warn(args...)
+	}
+}
+
+func (s SourceLogger) Warnf(format string, args ...interface{}) {
+	if enabled() {
+		s.entry().Warnf(format, args...)
+	}
+}
+
+func (s SourceLogger) Info(args ...interface{}) {
+	if enabled() {
+		s.entry().Info(args...)
+	}
+}
+
+func (s SourceLogger) Infof(format string, args ...interface{}) {
+	if enabled() {
+		s.entry().Infof(format, args...)
+	}
+}
+
+func (s SourceLogger) Tracef(format string, args ...interface{}) {
+	if enabled() {
+		s.entry().Tracef(format, args...)
+	}
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tailSize is the number of the latest entries kept by Capture
+const tailSize = 500
+
+// Entry of the log kept in memory for the TUI, see Capture
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	// Source is the name of the source the entry is about, empty if it is not about one
+	Source string
+	// Important entries are the warnings and the errors
+	Important bool
+}
+
+// capturing is set once the entries are kept in memory
+var capturing int32
+
+var tail struct {
+	sync.Mutex
+	entries []Entry
+	// next is the index of the entry to overwrite, once the tail is full
+	next int
+}
+
+// enabled reports whether the messages are logged anywhere
+func enabled() bool {
+	return writeLogs || atomic.LoadInt32(&capturing) == 1
+}
+
+// tailHook keeps the entries in memory
+type tailHook struct{}
+
+func (tailHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (tailHook) Fire(entry *log.Entry) error {
+	e := Entry{
+		Time:      entry.Time,
+		Level:     entry.Level.String(),
+		Message:   entry.Message,
+		Important: entry.Level <= log.WarnLevel,
+	}
+
+	if source, ok := entry.Data[sourceField]; ok {
+		e.Source = fmt.Sprint(source)
+	}
+
+	tail.Lock()
+	defer tail.Unlock()
+
+	if len(tail.entries) < tailSize {
+		tail.entries = append(tail.entries, e)
+		return nil
+	}
+
+	tail.entries[tail.next] = e
+	tail.next = (tail.next + 1) % tailSize
+	return nil
+}
+
+// Capture keeps the latest entries of this run in memory, even if the logs are not written, see Entries
+func Capture() {
+	if !atomic.CompareAndSwapInt32(&capturing, 0, 1) {
+		return
+	}
+
+	if !writeLogs {
+		log.SetOutput(io.Discard)
+		setLevel()
+	}
+
+	log.AddHook(tailHook{})
+}
+
+// Entries returns the captured entries from the oldest to the newest,
+// only the important ones unless all are asked for
+func Entries(all bool) []Entry {
+	tail.Lock()
+	defer tail.Unlock()
+
+	entries := make([]Entry, 0, len(tail.entries))
+	for i := range tail.entries {
+		entry := tail.entries[(tail.next+i)%len(tail.entries)]
+		if all || entry.Important {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
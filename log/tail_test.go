@@ -0,0 +1,51 @@
+package log
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	Convey("Given the captured entries", t, func() {
+		tail.entries, tail.next = nil, 0
+
+		hook := tailHook{}
+		So(hook.Fire(&log.Entry{Level: log.InfoLevel, Message: "searching"}), ShouldBeNil)
+		So(hook.Fire(&log.Entry{Level: log.WarnLevel, Message: "no results", Data: log.Fields{sourceField: "Mangadex"}}), ShouldBeNil)
+
+		Convey("When all entries are asked for", func() {
+			entries := Entries(true)
+
+			Convey("Then every entry should be returned with its source", func() {
+				So(entries, ShouldHaveLength, 2)
+				So(entries[0].Source, ShouldBeEmpty)
+				So(entries[1].Source, ShouldEqual, "Mangadex")
+			})
+		})
+
+		Convey("When the important entries are asked for", func() {
+			entries := Entries(false)
+
+			Convey("Then only the warning should be returned", func() {
+				So(entries, ShouldHaveLength, 1)
+				So(entries[0].Message, ShouldEqual, "no results")
+			})
+		})
+
+		Convey("When the tail is overflown", func() {
+			for i := 0; i < tailSize; i++ {
+				So(hook.Fire(&log.Entry{Level: log.InfoLevel, Message: fmt.Sprint(i)}), ShouldBeNil)
+			}
+
+			entries := Entries(true)
+
+			Convey("Then the latest entries should be kept from the oldest to the newest", func() {
+				So(entries, ShouldHaveLength, tailSize)
+				So(entries[0].Message, ShouldEqual, "0")
+				So(entries[tailSize-1].Message, ShouldEqual, fmt.Sprint(tailSize-1))
+			})
+		})
+	})
+}
@@ -0,0 +1,434 @@
+// Package manifest maintains manifest.json in manga directories.
+// Manifest lists every downloaded file with its size and SHA256 checksum,
+// so that drift can be detected cheaply without re-downloading anything.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Filename of the manifest inside the manga directory
+const Filename = "manifest.json"
+
+// Version of the manifest format
+const Version = 1
+
+var mutex = &sync.Mutex{}
+
+func init() {
+	source.RegisterSubstitutes(isSubstituted)
+}
+
+// Chapter the file belongs to
+type Chapter struct {
+	Manga  string `json:"manga"`
+	Name   string `json:"name"`
+	Index  int    `json:"index"`
+	URL    string `json:"url,omitempty"`
+	Source string `json:"source,omitempty"`
+	// Format that the chapter is saved in
+	Format string `json:"format,omitempty"`
+	// Substitutes is the format that the chapter was saved in the Format instead of,
+	// since its converter has failed, see key.FormatsFallback
+	Substitutes string `json:"substitutes,omitempty"`
+	// Fingerprint of the downloaded pages of the chapter, see Fingerprint.
+	// It changes when the source re-uploads the chapter with the fixed pages
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// File is a file tracked by the manifest
+type File struct {
+	// Size of the file in bytes
+	Size int64 `json:"size"`
+	// SHA256 checksum of the file contents, hex encoded
+	SHA256 string `json:"sha256"`
+	// Modified is the modification time of the file when it was added
+	Modified time.Time `json:"modified"`
+	// Chapter is the chapter this file belongs to, may be nil for files added by Rebuild
+	Chapter *Chapter `json:"chapter,omitempty"`
+}
+
+// Manifest of the manga directory
+type Manifest struct {
+	Version int `json:"version"`
+	// Files by their slash separated paths relative to the manga directory
+	Files map[string]*File `json:"files"`
+}
+
+// ignored reports whether the file is manga metadata or a volume bundle, which are regenerated on every download
+// and are not tracked by the manifest
+func ignored(filePath string) bool {
+	name := filepath.Base(filePath)
+	return filepath.Base(filepath.Dir(filePath)) == source.BundlesDirname || name == Filename || name == "series.json" || name == "mangal.json" || name == source.NumberingFilename || name == source.OwnerFilename || util.FileStem(name) == "cover"
+}
+
+func path(mangaPath string) string {
+	return filepath.Join(mangaPath, Filename)
+}
+
+// Load reads the manifest of the manga directory.
+// Empty manifest is returned if it does not exist
+func Load(mangaPath string) (*Manifest, error) {
+	manifest := &Manifest{
+		Version: Version,
+		Files:   make(map[string]*File),
+	}
+
+	contents, err := filesystem.Api().ReadFile(path(mangaPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]*File)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest to the manga directory
+func (m *Manifest) Save(mangaPath string) error {
+	contents, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(path(mangaPath), contents, os.ModePerm)
+}
+
+// Add adds the file or every file inside the directory to the manifest.
+// If file was already tracked, it is replaced
+func (m *Manifest) Add(mangaPath, filePath string, chapter *Chapter) error {
+	return filesystem.Api().Walk(filePath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || ignored(filePath) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(mangaPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		checksum, err := Checksum(filePath)
+		if err != nil {
+			return err
+		}
+
+		m.Files[filepath.ToSlash(relative)] = &File{
+			Size:     info.Size(),
+			SHA256:   checksum,
+			Modified: info.ModTime(),
+			Chapter:  chapter,
+		}
+
+		return nil
+	})
+}
+
+// Checksum returns hex encoded SHA256 of the file contents
+func Checksum(filePath string) (string, error) {
+	file, err := filesystem.Api().Open(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	defer util.Ignore(file.Close)
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fingerprintPrefix marks the fingerprints of the page contents.
+// The older manifests have the fingerprints of the page links, which are not comparable
+const fingerprintPrefix = "sha256:"
+
+// Fingerprint returns the fingerprint of the downloaded pages by their contents, in order.
+// Links are not used, since the CDNs rotate them and some of the sources don't give them at all.
+// Empty if any of the pages is not downloaded
+func Fingerprint(pages []*source.Page) string {
+	if len(pages) == 0 {
+		return ""
+	}
+
+	hash := sha256.New()
+	for _, page := range pages {
+		if page.Contents == nil {
+			return ""
+		}
+
+		sum := sha256.Sum256(page.Contents.Bytes())
+		_, _ = hash.Write(sum[:])
+	}
+
+	return fingerprintPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+// FingerprintOf returns the recorded fingerprint of the chapter with the given URL
+func (m *Manifest) FingerprintOf(chapterURL string) (string, bool) {
+	for _, file := range m.Files {
+		if file.Chapter != nil && file.Chapter.URL == chapterURL && strings.HasPrefix(file.Chapter.Fingerprint, fingerprintPrefix) {
+			return file.Chapter.Fingerprint, true
+		}
+	}
+
+	return "", false
+}
+
+// Track adds the downloaded chapter, saved at the given path, to the manifest of its manga
+func Track(chapter *source.Chapter, chapterPath string) error {
+	return TrackSubstitute(chapter, chapterPath, "")
+}
+
+// TrackSubstitute adds the downloaded chapter like Track, recording that it was saved in its format
+// instead of the substituted one, so that it is considered downloaded in that format too
+func TrackSubstitute(chapter *source.Chapter, chapterPath, substitutes string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	mangaPath, err := chapter.Manga.Path(false)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := Load(mangaPath)
+	if err != nil {
+		return err
+	}
+
+	mapping := &Chapter{
+		Manga:       chapter.Manga.Name,
+		Name:        chapter.Name,
+		Index:       chapter.Index,
+		URL:         chapter.URL,
+		Format:      chapter.ResolvedFormat(),
+		Substitutes: substitutes,
+	}
+
+	if chapter.Source() != nil {
+		mapping.Source = chapter.Source().ID()
+	}
+
+	mapping.Fingerprint = Fingerprint(chapter.Pages)
+
+	err = manifest.Add(mangaPath, chapterPath, mapping)
+	if err != nil {
+		return err
+	}
+
+	return manifest.Save(mangaPath)
+}
+
+// isSubstituted reports whether the chapter is saved in a fallback format instead of its own one,
+// according to the manifest of its manga
+func isSubstituted(chapter *source.Chapter) bool {
+	if chapter.Manga == nil {
+		return false
+	}
+
+	mangaPath := chapter.Manga.PeekPath()
+	if exists, err := filesystem.Api().Exists(path(mangaPath)); err != nil || !exists {
+		return false
+	}
+
+	mutex.Lock()
+	manifest, err := Load(mangaPath)
+	mutex.Unlock()
+	if err != nil {
+		return false
+	}
+
+	format := chapter.ResolvedFormat()
+	for relative, file := range manifest.Files {
+		if file.Chapter == nil || file.Chapter.Substitutes != format || !sameChapter(file.Chapter, chapter) {
+			continue
+		}
+
+		if exists, _ := filesystem.Api().Exists(filepath.Join(mangaPath, filepath.FromSlash(relative))); exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameChapter reports whether the mapping is of the chapter, by its URL if both have one
+func sameChapter(mapping *Chapter, chapter *source.Chapter) bool {
+	if mapping.URL != "" && chapter.URL != "" {
+		return mapping.URL == chapter.URL
+	}
+
+	return mapping.Name == chapter.Name
+}
+
+// TrackFile adds the chapter file put into the manga directory by other means than downloading,
+// e.g. pulled from another mangal, to the manifest of the manga
+func TrackFile(mangaPath, filePath string, chapter *Chapter) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	manifest, err := Load(mangaPath)
+	if err != nil {
+		return err
+	}
+
+	err = manifest.Add(mangaPath, filePath, chapter)
+	if err != nil {
+		return err
+	}
+
+	return manifest.Save(mangaPath)
+}
+
+// Refresh updates size and checksum of the file modified in place, e.g. when its metadata is rewritten.
+// Chapter mapping is kept. Nothing is done if manga has no manifest
+func Refresh(mangaPath, filePath string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	exists, err := filesystem.Api().Exists(path(mangaPath))
+	if err != nil || !exists {
+		return err
+	}
+
+	manifest, err := Load(mangaPath)
+	if err != nil {
+		return err
+	}
+
+	var chapter *Chapter
+	if relative, err := filepath.Rel(mangaPath, filePath); err == nil {
+		if file, ok := manifest.Files[filepath.ToSlash(relative)]; ok {
+			chapter = file.Chapter
+		}
+	}
+
+	err = manifest.Add(mangaPath, filePath, chapter)
+	if err != nil {
+		return err
+	}
+
+	return manifest.Save(mangaPath)
+}
+
+// Forget removes the deleted file from the manifest of the manga, if there is one
+func Forget(mangaPath, filePath string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	exists, err := filesystem.Api().Exists(path(mangaPath))
+	if err != nil || !exists {
+		return err
+	}
+
+	relative, err := filepath.Rel(mangaPath, filePath)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := Load(mangaPath)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := manifest.Files[filepath.ToSlash(relative)]; !ok {
+		return nil
+	}
+
+	delete(manifest.Files, filepath.ToSlash(relative))
+	return manifest.Save(mangaPath)
+}
+
+// Relink records the file moved from one path to another as the given chapter.
+// The manifest is created if the manga has none
+func Relink(mangaPath, from, to string, chapter *Chapter) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	manifest, err := Load(mangaPath)
+	if err != nil {
+		return err
+	}
+
+	fromRelative, err := filepath.Rel(mangaPath, from)
+	if err != nil {
+		return err
+	}
+
+	toRelative, err := filepath.Rel(mangaPath, to)
+	if err != nil {
+		return err
+	}
+
+	file, ok := manifest.Files[filepath.ToSlash(fromRelative)]
+	if !ok {
+		if err = manifest.Add(mangaPath, to, chapter); err != nil {
+			return err
+		}
+
+		return manifest.Save(mangaPath)
+	}
+
+	// contents of the moved file are the same
+	delete(manifest.Files, filepath.ToSlash(fromRelative))
+	file.Chapter = chapter
+	manifest.Files[filepath.ToSlash(toRelative)] = file
+	return manifest.Save(mangaPath)
+}
+
+// Rebuild recomputes the manifest from the files in the manga directory.
+// Chapter mapping is kept for files that were tracked before
+func Rebuild(mangaPath string) (*Manifest, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	old, err := Load(mangaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version: Version,
+		Files:   make(map[string]*File),
+	}
+
+	err = manifest.Add(mangaPath, mangaPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, file := range manifest.Files {
+		if oldFile, ok := old.Files[name]; ok {
+			file.Chapter = oldFile.Chapter
+		}
+	}
+
+	return manifest, manifest.Save(mangaPath)
+}
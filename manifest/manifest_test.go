@@ -0,0 +1,163 @@
+package manifest
+
+import (
+	"bytes"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+func TestManifest(t *testing.T) {
+	Convey("Given a manga directory with a chapter", t, func() {
+		mangaPath := filepath.Join("library", "manga")
+		chapterPath := filepath.Join(mangaPath, "[0001] Chapter 1.cbz")
+		lo.Must0(filesystem.Api().MkdirAll(mangaPath, os.ModePerm))
+		lo.Must0(filesystem.Api().WriteFile(chapterPath, []byte("pages"), os.ModePerm))
+		defer func() {
+			_ = filesystem.Api().RemoveAll("library")
+		}()
+
+		manifest := lo.Must(Load(mangaPath))
+		So(manifest.Add(mangaPath, chapterPath, &Chapter{Name: "Chapter 1", Index: 1}), ShouldBeNil)
+		So(manifest.Save(mangaPath), ShouldBeNil)
+
+		Convey("When loading it back", func() {
+			loaded := lo.Must(Load(mangaPath))
+
+			Convey("Then the chapter should be tracked with its checksum", func() {
+				file, ok := loaded.Files["[0001] Chapter 1.cbz"]
+				So(ok, ShouldBeTrue)
+				So(file.Size, ShouldEqual, 5)
+				// sha256 of "pages"
+				So(file.SHA256, ShouldEqual, "bfa062de040f55a15ce910800757061ec3d2fc31d6b7c72d9fa02b75a9ad1133")
+				So(file.Chapter.Name, ShouldEqual, "Chapter 1")
+			})
+		})
+
+		Convey("When metadata files are added", func() {
+			lo.Must0(filesystem.Api().WriteFile(filepath.Join(mangaPath, "series.json"), []byte("{}"), os.ModePerm))
+			lo.Must0(filesystem.Api().WriteFile(filepath.Join(mangaPath, "cover.png"), []byte("cover"), os.ModePerm))
+
+			Convey("Then they should not be reported", func() {
+				So(lo.Must(Verify(mangaPath, false)).OK(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When nothing has changed", func() {
+			report := lo.Must(Verify(mangaPath, false))
+
+			Convey("Then no drift should be detected", func() {
+				So(report.OK(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the chapter is modified without changing its size", func() {
+			lo.Must0(filesystem.Api().WriteFile(chapterPath, []byte("PAGES"), os.ModePerm))
+
+			Convey("Then quick verification should not notice it", func() {
+				So(lo.Must(Verify(mangaPath, true)).OK(), ShouldBeTrue)
+			})
+
+			Convey("Then full verification should report it", func() {
+				So(lo.Must(Verify(mangaPath, false)).Changed, ShouldResemble, []string{"[0001] Chapter 1.cbz"})
+			})
+		})
+
+		Convey("When the chapter is deleted and forgotten", func() {
+			lo.Must0(filesystem.Api().Remove(chapterPath))
+			So(Forget(mangaPath, chapterPath), ShouldBeNil)
+
+			Convey("Then it should not be reported as missing", func() {
+				So(lo.Must(Load(mangaPath)).Files, ShouldBeEmpty)
+				So(lo.Must(Verify(mangaPath, true)).OK(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the chapter is removed and another file is added", func() {
+			lo.Must0(filesystem.Api().Remove(chapterPath))
+			lo.Must0(filesystem.Api().WriteFile(filepath.Join(mangaPath, "extra.cbz"), []byte("extra"), os.ModePerm))
+
+			report := lo.Must(Verify(mangaPath, true))
+
+			Convey("Then both should be reported", func() {
+				So(report.Missing, ShouldResemble, []string{"[0001] Chapter 1.cbz"})
+				So(report.Untracked, ShouldResemble, []string{"extra.cbz"})
+			})
+
+			Convey("When the manifest is rebuilt", func() {
+				rebuilt := lo.Must(Rebuild(mangaPath))
+
+				Convey("Then it should match the directory", func() {
+					So(rebuilt.Files, ShouldContainKey, "extra.cbz")
+					So(rebuilt.Files, ShouldNotContainKey, "[0001] Chapter 1.cbz")
+					So(lo.Must(Verify(mangaPath, false)).OK(), ShouldBeTrue)
+				})
+			})
+		})
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	Convey("Given the downloaded pages of a chapter", t, func() {
+		pageOf := func(link, contents string) *source.Page {
+			return &source.Page{URL: link, Contents: bytes.NewBufferString(contents)}
+		}
+
+		pages := []*source.Page{
+			pageOf("https://cdn.example.com/1.jpg?token=a", "first"),
+			pageOf("https://cdn.example.com/2.jpg?token=a", "second"),
+		}
+
+		Convey("Then the links of the pages should not change the fingerprint", func() {
+			rotated := []*source.Page{
+				pageOf("https://cdn2.example.com/1.jpg?token=b", "first"),
+				pageOf("", "second"),
+			}
+
+			So(Fingerprint(rotated), ShouldEqual, Fingerprint(pages))
+		})
+
+		Convey("Then re-uploaded pages should change it", func() {
+			fixed := []*source.Page{
+				pageOf("https://cdn.example.com/1.jpg", "first"),
+				pageOf("https://cdn.example.com/2.jpg", "second, fixed"),
+			}
+
+			So(Fingerprint(fixed), ShouldNotEqual, Fingerprint(pages))
+			So(Fingerprint(pages[:1]), ShouldNotEqual, Fingerprint(pages))
+		})
+
+		Convey("Then the pages that are not downloaded should have no fingerprint", func() {
+			So(Fingerprint([]*source.Page{{URL: "https://cdn.example.com/1.jpg"}}), ShouldBeEmpty)
+		})
+
+		Convey("When the chapter is tracked", func() {
+			manifest := &Manifest{Files: map[string]*File{
+				"[0001] Chapter 1.cbz": {Chapter: &Chapter{URL: "chapter-1", Fingerprint: Fingerprint(pages)}},
+				"[0002] Chapter 2.cbz": {Chapter: &Chapter{URL: "chapter-2"}},
+				// fingerprint of the page links, recorded by the older versions
+				"[0003] Chapter 3.cbz": {Chapter: &Chapter{URL: "chapter-3", Fingerprint: "0a1b2c"}},
+			}}
+
+			Convey("Then its fingerprint should be found by its URL", func() {
+				fingerprint, ok := manifest.FingerprintOf("chapter-1")
+				So(ok, ShouldBeTrue)
+				So(fingerprint, ShouldEqual, Fingerprint(pages))
+
+				_, ok = manifest.FingerprintOf("chapter-2")
+				So(ok, ShouldBeFalse)
+
+				_, ok = manifest.FingerprintOf("chapter-3")
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,95 @@
+package manifest
+
+import (
+	"github.com/metafates/mangal/filesystem"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Report of the manifest verification.
+// All paths are slash separated and relative to the manga directory
+type Report struct {
+	// Missing files are tracked, but don't exist
+	Missing []string
+	// Changed files differ in size or checksum
+	Changed []string
+	// Untracked files exist, but are not in the manifest
+	Untracked []string
+}
+
+// OK reports whether no drift was detected
+func (r *Report) OK() bool {
+	return len(r.Missing) == 0 && len(r.Changed) == 0 && len(r.Untracked) == 0
+}
+
+// Verify compares the files in the manga directory with its manifest.
+// If quick is true, only sizes are compared and checksums are not computed
+func Verify(mangaPath string, quick bool) (*Report, error) {
+	manifest, err := Load(mangaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for name, file := range manifest.Files {
+		filePath := filepath.Join(mangaPath, filepath.FromSlash(name))
+		info, err := filesystem.Api().Stat(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				report.Missing = append(report.Missing, name)
+				continue
+			}
+
+			return nil, err
+		}
+
+		if info.Size() != file.Size {
+			report.Changed = append(report.Changed, name)
+			continue
+		}
+
+		if quick {
+			continue
+		}
+
+		checksum, err := Checksum(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if checksum != file.SHA256 {
+			report.Changed = append(report.Changed, name)
+		}
+	}
+
+	err = filesystem.Api().Walk(mangaPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || ignored(filePath) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(mangaPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := manifest.Files[filepath.ToSlash(relative)]; !ok {
+			report.Untracked = append(report.Untracked, filepath.ToSlash(relative))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Changed)
+	sort.Strings(report.Untracked)
+
+	return report, nil
+}
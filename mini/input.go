@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/style"
 	"os"
 	"strconv"
@@ -35,7 +36,7 @@ func getInput(validator func(string) bool) (*input, error) {
 	}
 
 	if !validator(in) {
-		fmt.Println(style.Fg(color.Red)("Invalid choice entered"))
+		fmt.Println(style.Fg(color.Red)(i18n.T("Invalid choice entered")))
 		return getInput(validator)
 	}
 
@@ -2,6 +2,7 @@ package mini
 
 import (
 	"errors"
+	"github.com/metafates/mangal/profiling"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
 	"github.com/samber/lo"
@@ -113,6 +114,7 @@ func (m *mini) handleState() error {
 	case chaptersDownloadState:
 		return m.handleChaptersDownloadState()
 	case quitState:
+		profiling.Stop()
 		os.Exit(0)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/metafates/mangal/downloader"
 	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/provider"
 	"github.com/metafates/mangal/source"
@@ -51,7 +52,7 @@ func (m *mini) handleSourceSelectState() error {
 			return strings.Compare(a.String(), b.String()) < 0
 		})
 
-		title("Select Source")
+		title(i18n.T("Select Source"))
 		b, p, err := menu(providers)
 		if err != nil {
 			return err
@@ -62,7 +63,7 @@ func (m *mini) handleSourceSelectState() error {
 			return nil
 		}
 
-		erase := progress("Initializing Source..")
+		erase := progress(i18n.T("Initializing Source.."))
 		m.selectedSource, err = p.CreateSource()
 		if err != nil {
 			return err
@@ -76,7 +77,7 @@ func (m *mini) handleSourceSelectState() error {
 
 func (m *mini) handleMangaSearchState() error {
 	var searchLoop func() error
-	title("Search Manga")
+	title(i18n.T("Search Manga"))
 
 	searchLoop = func() error {
 		in, err := getInput(func(s string) bool {
@@ -89,14 +90,14 @@ func (m *mini) handleMangaSearchState() error {
 
 		query := in.value
 
-		erase := progress("Searching Query..")
+		erase := progress(i18n.T("Searching Query.."))
 		m.cachedMangas[query], err = m.selectedSource.Search(query)
 		max := lo.Min([]int{len(m.cachedMangas[query]), viper.GetInt(key.MiniSearchLimit)})
 		m.cachedMangas[query] = m.cachedMangas[query][:max]
 		erase()
 
 		if len(m.cachedMangas[query]) == 0 {
-			fail("No search results found")
+			fail(i18n.T("No search results found"))
 			return searchLoop()
 		}
 
@@ -110,7 +111,7 @@ func (m *mini) handleMangaSearchState() error {
 
 func (m *mini) handleMangaSelectState() error {
 	var err error
-	title("Query Results >>")
+	title(i18n.T("Query Results >>"))
 	b, p, err := menu(m.cachedMangas[m.query])
 	if err != nil {
 		return err
@@ -129,7 +130,7 @@ func (m *mini) handleMangaSelectState() error {
 func (m *mini) handleChapterSelectState() error {
 	var err error
 
-	erase := progress("Searching Chapters..")
+	erase := progress(i18n.T("Searching Chapters.."))
 	m.cachedChapters[m.selectedManga.URL], err = m.selectedSource.ChaptersOf(m.selectedManga)
 	erase()
 	if err != nil {
@@ -139,13 +140,13 @@ func (m *mini) handleChapterSelectState() error {
 	chapters := m.cachedChapters[m.selectedManga.URL]
 
 	if len(chapters) == 0 {
-		fail("No chapters found")
+		fail(i18n.T("No chapters found"))
 		m.selectedManga = nil
 		m.newState(mangaSelectState)
 		return nil
 	}
 
-	title(fmt.Sprintf("To specify a range, use: start_number end_number (Chapters: 1-%d)", len(chapters)))
+	title(i18n.T("To specify a range, use: start_number end_number (Chapters: 1-%d)", len(chapters)))
 	oneChapterInput := regexp.MustCompile(`^\d+$`)
 	rangeInput := regexp.MustCompile(`^\d+ \d+$`)
 	in, err := getInput(func(s string) bool {
@@ -240,7 +241,7 @@ func (m *mini) handleChapterReadState() error {
 
 		erase()
 
-		title(fmt.Sprintf("Currently reading %s", chapter.Name))
+		title(i18n.T("Currently reading %s", chapter.Name))
 
 		var options []*bind
 		if hasPrev {
@@ -307,39 +308,38 @@ func (m *mini) handleChapterReadState() error {
 
 func (m *mini) handleChaptersDownloadState() error {
 	var (
-		err          error
-		downloadLoop func(*source.Chapter) error
+		current *source.Chapter
+		erase   = func() {}
 	)
 
-	downloadLoop = func(chapter *source.Chapter) error {
-		util.ClearScreen()
-		var erase = func() {}
-
-		title(fmt.Sprintf("Currently downloading %s %s (%s)", chapter.Manga.Name, chapter.Name, m.selectedSource.Name()))
+	summary, err := downloader.Batch(m.selectedChapters, downloader.BatchOptions{
+		Retries:     viper.GetInt(key.DownloaderRetryFailed),
+		StopOnError: viper.GetBool(key.DownloaderStopOnError),
+		Progress: func(chapter *source.Chapter, s string) {
+			if chapter != current {
+				current = chapter
+				util.ClearScreen()
+				erase = func() {}
+				title(i18n.T("Currently downloading %s %s (%s)", chapter.Manga.Name, chapter.Name, m.selectedSource.Name()))
+			}
 
-		_, err := downloader.Download(chapter, func(s string) {
 			erase()
 			erase = progress(s)
-		})
-
-		erase()
-
-		if err != nil && viper.GetBool(key.DownloaderStopOnError) {
-			return err
-		}
+		},
+	})
 
-		return nil
-	}
+	erase()
 
-	for _, chapter := range m.selectedChapters {
-		err = downloadLoop(chapter)
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return err
 	}
 
 	util.ClearScreen()
-	title(fmt.Sprintf("%s downloaded.", util.Quantify(len(m.selectedChapters), "chapter", "chapters")))
+	if failed := len(summary.Failed); failed > 0 {
+		title(i18n.T("%s downloaded, %d failed.", i18n.N(len(summary.Downloaded), "chapter", "chapters"), failed))
+	} else {
+		title(i18n.T("%s downloaded.", i18n.N(len(summary.Downloaded), "chapter", "chapters")))
+	}
 	b, _, err := menu([]fmt.Stringer{}, back, search)
 	if err != nil {
 		return err
@@ -365,7 +365,7 @@ func (m *mini) handleHistorySelectState() error {
 
 	chapters := lo.Values(h)
 
-	title("History Results >>")
+	title(i18n.T("History Results >>"))
 	b, c, err := menu(chapters)
 	if err != nil {
 		return err
@@ -388,7 +388,7 @@ func (m *mini) handleHistorySelectState() error {
 		return p.ID == c.SourceID
 	})
 
-	erase := progress("Initializing Source..")
+	erase := progress(i18n.T("Initializing Source.."))
 	s, err := p.CreateSource()
 	if err != nil {
 		return err
@@ -396,7 +396,7 @@ func (m *mini) handleHistorySelectState() error {
 	m.selectedSource = s
 	erase()
 
-	erase = progress("Fetching Chapters..")
+	erase = progress(i18n.T("Fetching Chapters.."))
 	manga := &source.Manga{
 		Name:   c.MangaName,
 		URL:    c.MangaURL,
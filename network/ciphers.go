@@ -0,0 +1,65 @@
+package network
+
+import (
+	"crypto/tls"
+	"github.com/metafates/mangal/key"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"net/http"
+	"strings"
+)
+
+// browserTransport offers the cipher suites, curves and protocols of the browsers instead of the default ones of Go,
+// for the sources behind CDNs that reject the default TLS configuration.
+//
+// It does not impersonate the TLS fingerprint of a browser, as uTLS does:
+// crypto/tls doesn't allow to set the order of the extensions or the GREASE values, so the CDNs that match
+// the whole client hello (JA3) still tell it from a browser.
+var browserTransport = transport.Clone()
+
+func init() {
+	browserTransport.ForceAttemptHTTP2 = true
+	browserTransport.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+		NextProtos:       []string{"h2", "http/1.1"},
+	}
+
+	browserClient.Transport = browserTransport
+}
+
+// browserClient is the StageClient with the browserTransport
+var browserClient = &http.Client{}
+
+// usesBrowserCiphers reports whether the source with the given name must be connected to with the browserTransport
+func usesBrowserCiphers(source string) bool {
+	return lo.ContainsBy(viper.GetStringSlice(key.NetworkTLSBrowserCiphers), func(name string) bool {
+		return strings.EqualFold(name, source)
+	})
+}
+
+// TransportFor returns the transport to connect to the source with the given name
+func TransportFor(source string) http.RoundTripper {
+	if usesBrowserCiphers(source) {
+		return inSession(browserTransport)
+	}
+
+	return inSession(transport)
+}
+
+// StageClientFor returns the StageClient to connect to the source with the given name
+func StageClientFor(source string) *http.Client {
+	if usesBrowserCiphers(source) {
+		return browserClient
+	}
+
+	return StageClient
+}
@@ -0,0 +1,29 @@
+package network
+
+import (
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestTransportFor(t *testing.T) {
+	Convey("Given a source with the browser ciphers enabled", t, func() {
+		viper.Set(key.NetworkTLSBrowserCiphers, []string{"Mangapill"})
+		defer viper.Set(key.NetworkTLSBrowserCiphers, []string{})
+
+		Convey("When getting its transport", func() {
+			Convey("Then it should connect with the browser ciphers", func() {
+				So(TransportFor("mangapill"), ShouldEqual, browserTransport)
+				So(StageClientFor("Mangapill"), ShouldEqual, browserClient)
+			})
+		})
+
+		Convey("When getting the transport of another source", func() {
+			Convey("Then it should connect as usual", func() {
+				So(TransportFor("Manganato"), ShouldEqual, transport)
+				So(StageClientFor("Manganato"), ShouldEqual, StageClient)
+			})
+		})
+	})
+}
@@ -20,3 +20,20 @@ var Client = &http.Client{
 	Timeout:   time.Minute,
 	Transport: transport,
 }
+
+// StageClient has no timeout of its own,
+// its requests are limited by the timeouts of their stages instead, see Stage.Context
+var StageClient = &http.Client{
+	Transport: transport,
+}
+
+// RegisterProtocol makes every client of mangal handle the links with the given scheme with the round tripper,
+// e.g. to serve the pages of the sources that are not on the network
+func RegisterProtocol(scheme string, rt http.RoundTripper) {
+	transport.RegisterProtocol(scheme, rt)
+	browserTransport.RegisterProtocol(scheme, rt)
+
+	if t, ok := defaultTransport.(*http.Transport); ok {
+		t.RegisterProtocol(scheme, rt)
+	}
+}
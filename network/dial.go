@@ -0,0 +1,127 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ipVersions are the available IP version options, see dialer
+var ipVersions = map[string]struct {
+	family string
+	prefer bool
+}{
+	"any":         {},
+	"ipv4":        {family: "4"},
+	"ipv6":        {family: "6"},
+	"prefer_ipv4": {family: "4", prefer: true},
+	"prefer_ipv6": {family: "6", prefer: true},
+}
+
+// dialer makes the outbound connections with the IP version and from the local address set in the config
+type dialer struct {
+	net.Dialer
+
+	// family is "4" or "6" to dial with that IP version only, or empty to dial with any
+	family string
+
+	// prefer falls back to any IP version if dialing with the family fails
+	prefer bool
+}
+
+// outbound is the dialer of all the HTTP clients, see setupDial
+var outbound = &dialer{
+	Dialer: net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	},
+}
+
+func (d *dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if d.family == "" || (network != "tcp" && network != "udp") {
+		return d.Dialer.DialContext(ctx, network, address)
+	}
+
+	conn, err := d.Dialer.DialContext(ctx, network+d.family, address)
+	if err != nil && d.prefer && ctx.Err() == nil {
+		log.Warnf("dialing %s with IPv%s failed, falling back to any IP version: %s", address, d.family, err)
+		return d.Dialer.DialContext(ctx, network, address)
+	}
+
+	return conn, err
+}
+
+// setupDial applies the IP version and the bind address from the config to the connections of all the HTTP clients,
+// including http.DefaultTransport
+func setupDial() error {
+	version, ok := ipVersions[viper.GetString(key.NetworkIPVersion)]
+	if !ok {
+		return fmt.Errorf("invalid IP version %q: expected any, ipv4, ipv6, prefer_ipv4 or prefer_ipv6", viper.GetString(key.NetworkIPVersion))
+	}
+
+	outbound.family = version.family
+	outbound.prefer = version.prefer
+
+	if bind := viper.GetString(key.NetworkBind); bind != "" {
+		ip, err := bindAddress(bind, version.family)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("binding outbound connections to %s", ip)
+		outbound.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+
+	transport.DialContext = outbound.DialContext
+	browserTransport.DialContext = outbound.DialContext
+
+	// the libraries of the sources and the Lua sources use the default transport
+	if t, ok := defaultTransport.(*http.Transport); ok {
+		t.DialContext = outbound.DialContext
+	}
+
+	return nil
+}
+
+// bindAddress returns the IP to bind the connections to, given as is or by the name of a network interface.
+// IPs of the interface with the given family ("4" or "6") are preferred
+func bindAddress(bind, family string) (net.IP, error) {
+	if ip := net.ParseIP(bind); ip != nil {
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(bind)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bind address %q: expected an IP or the name of a network interface: %w", bind, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && (ipNet.IP.IsGlobalUnicast() || ipNet.IP.IsLoopback()) {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("network interface %s has no usable IP addresses", bind)
+	}
+
+	// IPv4 is preferred, unless IPv6 is asked for
+	for _, ip := range ips {
+		if (ip.To4() == nil) == (family == "6") {
+			return ip, nil
+		}
+	}
+
+	return ips[0], nil
+}
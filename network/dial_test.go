@@ -0,0 +1,88 @@
+package network
+
+import (
+	"context"
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDialer(t *testing.T) {
+	Convey("Given an IPv4 listener", t, func() {
+		listener, err := net.Listen("tcp4", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		defer listener.Close()
+
+		address := listener.Addr().String()
+
+		Convey("When dialing it with IPv6 only", func() {
+			d := &dialer{family: "6"}
+			_, err := d.DialContext(context.Background(), "tcp", address)
+
+			Convey("Then it should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When dialing it with IPv6 preferred", func() {
+			d := &dialer{family: "6", prefer: true}
+			conn, err := d.DialContext(context.Background(), "tcp", address)
+
+			Convey("Then it should fall back to IPv4", func() {
+				So(err, ShouldBeNil)
+				So(conn.Close(), ShouldBeNil)
+			})
+		})
+
+		Convey("When dialing it bound to the address of the loopback", func() {
+			ip, err := bindAddress("127.0.0.1", "")
+			So(err, ShouldBeNil)
+
+			d := &dialer{family: "4", Dialer: net.Dialer{LocalAddr: &net.TCPAddr{IP: ip}}}
+			conn, err := d.DialContext(context.Background(), "tcp", address)
+
+			Convey("Then the connection should be made from it", func() {
+				So(err, ShouldBeNil)
+				defer conn.Close()
+				So(conn.LocalAddr().(*net.TCPAddr).IP.String(), ShouldEqual, "127.0.0.1")
+			})
+		})
+	})
+
+	Convey("Given an unknown bind address", t, func() {
+		Convey("When getting its IP", func() {
+			_, err := bindAddress("not-an-interface", "")
+
+			Convey("Then it should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestSetupDial(t *testing.T) {
+	Convey("Given an IPv4 server", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer server.Close()
+
+		Convey("When the connections are made with IPv6 only", func() {
+			viper.Set(key.NetworkIPVersion, "ipv6")
+			So(setupDial(), ShouldBeNil)
+
+			Reset(func() {
+				viper.Set(key.NetworkIPVersion, "any")
+				_ = setupDial()
+			})
+
+			Convey("Then the default client of the libraries should make them too", func() {
+				// the IPv4 address is not dialed with IPv6
+				_, err := http.DefaultClient.Get(server.URL)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
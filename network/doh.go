@@ -0,0 +1,216 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/spf13/viper"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// dohProviders are the DNS-over-HTTPS endpoints by their names.
+// They are given by their IPs, so that the resolver itself doesn't need the system DNS
+var dohProviders = map[string]string{
+	"cloudflare": "https://1.1.1.1/dns-query",
+	"google":     "https://8.8.8.8/dns-query",
+	"quad9":      "https://9.9.9.9/dns-query",
+}
+
+// Setup applies the network config to every connection made by mangal, including the ones of the sources:
+// the IP version and the bind address of the connections, the proxy and the DNS resolver
+func Setup() error {
+	if err := setupDial(); err != nil {
+		return err
+	}
+
+	if err := setupProxy(); err != nil {
+		return err
+	}
+
+	return setupDoH()
+}
+
+// setupDoH replaces the system DNS resolver with the DNS-over-HTTPS one, if it is set in the config
+func setupDoH() error {
+	provider := viper.GetString(key.NetworkDoH)
+	if provider == "" {
+		return nil
+	}
+
+	endpoint, err := dohEndpoint(provider)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("resolving hosts with DNS-over-HTTPS %s", endpoint)
+	net.DefaultResolver = newDoHResolver(endpoint)
+	return nil
+}
+
+// dohEndpoint returns the URL of the DoH endpoint by the name of the provider or its URL
+func dohEndpoint(provider string) (string, error) {
+	if endpoint, ok := dohProviders[provider]; ok {
+		return endpoint, nil
+	}
+
+	endpoint, err := url.Parse(provider)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "https" && endpoint.Scheme != "http") {
+		return "", fmt.Errorf("invalid DNS-over-HTTPS provider %q: expected cloudflare, google, quad9 or an URL", provider)
+	}
+
+	return endpoint.String(), nil
+}
+
+// newDoHResolver returns the resolver that sends DNS queries to the DoH endpoint (RFC 8484)
+func newDoHResolver(endpoint string) *net.Resolver {
+	// the host of a custom endpoint is resolved by the system, not by this resolver
+	dialer := *outbound
+	dialer.Timeout = 10 * time.Second
+	dialer.Resolver = &net.Resolver{}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:       dialer.DialContext,
+			ForceAttemptHTTP2: true,
+			IdleConnTimeout:   30 * time.Second,
+		},
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, endpoint: endpoint, client: client}, nil
+		},
+	}
+}
+
+// dohConn is the connection to the DNS server as seen by the resolver.
+// It is a stream connection, so each message is prefixed with its length, as in DNS over TCP.
+// Each written query is sent to the DoH endpoint and its answer is read back
+type dohConn struct {
+	ctx      context.Context
+	endpoint string
+	client   *http.Client
+	deadline time.Time
+
+	queries, answers bytes.Buffer
+}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.queries.Write(b)
+
+	for c.queries.Len() >= 2 {
+		length := int(binary.BigEndian.Uint16(c.queries.Bytes()[:2]))
+		if c.queries.Len() < 2+length {
+			break
+		}
+
+		c.queries.Next(2)
+		answer, err := c.exchange(c.queries.Next(length))
+		if err != nil {
+			return 0, err
+		}
+
+		_ = binary.Write(&c.answers, binary.BigEndian, uint16(len(answer)))
+		c.answers.Write(answer)
+	}
+
+	return len(b), nil
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.answers.Len() == 0 {
+		return 0, io.EOF
+	}
+
+	return c.answers.Read(b)
+}
+
+// exchange sends the query to the DoH endpoint and returns the answer
+func (c *dohConn) exchange(query []byte) ([]byte, error) {
+	ctx := c.ctx
+	if !c.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, c.deadline)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DNS-over-HTTPS: %s", resp.Status)
+	}
+
+	// DNS messages can't be longer than that
+	answer, err := io.ReadAll(io.LimitReader(resp.Body, 65535))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(answer) == 0 {
+		return nil, errors.New("DNS-over-HTTPS: empty answer")
+	}
+
+	return answer, nil
+}
+
+func (c *dohConn) Close() error {
+	return nil
+}
+
+func (c *dohConn) LocalAddr() net.Addr {
+	return dohAddr{}
+}
+
+func (c *dohConn) RemoteAddr() net.Addr {
+	return dohAddr{c.endpoint}
+}
+
+func (c *dohConn) SetDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+func (c *dohConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *dohConn) SetWriteDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+// dohAddr is the address of the DoH endpoint
+type dohAddr struct {
+	endpoint string
+}
+
+func (dohAddr) Network() string {
+	return "doh"
+}
+
+func (a dohAddr) String() string {
+	return a.endpoint
+}
@@ -0,0 +1,85 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestDoHServer answers every A query with 127.0.0.7 and every other one with no records
+func newTestDoHServer(queries *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		query, _ := io.ReadAll(r.Body)
+		atomic.AddInt32(queries, 1)
+
+		// the question ends with its type and class, right after the header and the name
+		end := 12
+		for query[end] != 0 {
+			end += int(query[end]) + 1
+		}
+		end += 5
+		qtype := binary.BigEndian.Uint16(query[end-4 : end-2])
+
+		answer := append([]byte{}, query[:end]...)
+		// response, recursion available, no error
+		answer[2], answer[3] = 0x81, 0x80
+
+		if qtype == 1 {
+			binary.BigEndian.PutUint16(answer[6:8], 1)
+			// pointer to the name of the question, A, IN, TTL of 60 seconds, 4 bytes of the address
+			answer = append(answer, 0xC0, 0x0C, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 127, 0, 0, 7)
+		}
+
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(answer)
+	}))
+}
+
+func TestDoH(t *testing.T) {
+	Convey("Given a DoH endpoint", t, func() {
+		var queries int32
+		server := newTestDoHServer(&queries)
+		defer server.Close()
+
+		resolver := newDoHResolver(server.URL)
+
+		Convey("When looking up a host", func() {
+			addrs, err := resolver.LookupHost(context.Background(), "manga.example")
+
+			Convey("Then it should be resolved by the endpoint", func() {
+				So(err, ShouldBeNil)
+				So(addrs, ShouldResemble, []string{"127.0.0.7"})
+				So(atomic.LoadInt32(&queries), ShouldBeGreaterThan, 0)
+			})
+		})
+	})
+
+	Convey("Given the names of the providers", t, func() {
+		Convey("When getting their endpoints", func() {
+			Convey("Then known ones and URLs should be accepted", func() {
+				endpoint, err := dohEndpoint("cloudflare")
+				So(err, ShouldBeNil)
+				So(endpoint, ShouldEqual, dohProviders["cloudflare"])
+
+				endpoint, err = dohEndpoint("https://doh.example/dns-query")
+				So(err, ShouldBeNil)
+				So(endpoint, ShouldEqual, "https://doh.example/dns-query")
+			})
+
+			Convey("Then unknown ones should be rejected", func() {
+				_, err := dohEndpoint("opendns")
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket that also can be paused, e.g. when the server asks to retry after some time.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+	until    time.Time
+}
+
+// NewLimiter returns a limiter that allows the given number of requests per minute
+func NewLimiter(perMinute int) *Limiter {
+	return &Limiter{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    float64(perMinute),
+		tokens:   float64(perMinute),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a request can be made
+func (l *Limiter) Wait() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.until) {
+		time.Sleep(l.until.Sub(now))
+		now = time.Now()
+	}
+
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		wait := time.Duration((1 - l.tokens) * float64(l.interval))
+		time.Sleep(wait)
+		l.tokens = 1
+		l.last = time.Now()
+	}
+
+	l.tokens--
+}
+
+// Pause forbids any requests for the given duration
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.until) {
+		l.until = until
+	}
+
+	l.tokens = 0
+}
@@ -0,0 +1,25 @@
+package network
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	Convey("Given a limiter with 600 requests per minute", t, func() {
+		l := NewLimiter(600)
+		l.tokens = 0
+
+		Convey("When waiting for 3 requests", func() {
+			start := time.Now()
+			for i := 0; i < 3; i++ {
+				l.Wait()
+			}
+
+			Convey("Then they should be spaced by 100ms", func() {
+				So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 250*time.Millisecond)
+			})
+		})
+	})
+}
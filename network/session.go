@@ -0,0 +1,420 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/samber/lo"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exchange is a request of the recorded session with its response.
+// Sessions are saved as JSON lines, one exchange per line, so that the file is complete even if mangal crashes
+type Exchange struct {
+	Time          time.Time   `json:"time"`
+	Method        string      `json:"method"`
+	URL           string      `json:"url"`
+	RequestHeader http.Header `json:"request_header"`
+	Status        int         `json:"status,omitempty"`
+	Header        http.Header `json:"header,omitempty"`
+	Body          []byte      `json:"body,omitempty"`
+	// Error of the request that has failed without a response
+	Error string `json:"error,omitempty"`
+}
+
+// redacted replaces the sensitive values of the recorded sessions
+const redacted = "REDACTED"
+
+// sensitiveHeaders are never saved to the sessions
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveParams are the query parameters with the credentials, by the parts of their names
+var sensitiveParams = []string{"token", "secret", "password", "signature", "credential", "auth", "session"}
+
+// authPaths are the segments of the paths of the endpoints that sign in, whose bodies are never saved to the sessions.
+// Whole segments or their words are matched, e.g. /account/authenticate and /access_token, but not /authors
+var authPaths = []string{"login", "signin", "sign_in", "oauth", "oauth2", "auth", "authenticate", "token", "session"}
+
+// pathWords splits the segment of the path to its words, e.g. access_token.json to access, token and json
+var pathWords = regexp.MustCompile(`[^a-z0-9]+`)
+
+// signsIn reports whether the request is to the endpoint that signs in, see authPaths
+func signsIn(r *http.Request) bool {
+	for _, segment := range strings.Split(strings.ToLower(r.URL.Path), "/") {
+		if lo.Contains(authPaths, segment) || lo.Contains(authPaths, strings.TrimSuffix(segment, path.Ext(segment))) {
+			return true
+		}
+
+		if lo.ContainsBy(pathWords.Split(segment, -1), func(word string) bool {
+			return lo.Contains(authPaths, word)
+		}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sensitiveName reports whether the query parameter or the JSON field has the credentials
+func sensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	return name == "key" || name == "sig" || lo.ContainsBy(sensitiveParams, func(part string) bool {
+		return strings.Contains(name, part)
+	})
+}
+
+// sanitizeURL removes the credentials from the link, so that the sessions can be shared.
+// Query is re-encoded, so the same links are sanitized to the same strings
+func sanitizeURL(u *url.URL) string {
+	sanitized := *u
+	sanitized.User = nil
+
+	query := sanitized.Query()
+	for param := range query {
+		if sensitiveName(param) {
+			query.Set(param, redacted)
+		}
+	}
+
+	sanitized.RawQuery = query.Encode()
+	return sanitized.String()
+}
+
+// sanitizeHeader returns the copy of the header without the sensitive ones
+func sanitizeHeader(header http.Header) http.Header {
+	sanitized := header.Clone()
+	for _, name := range sensitiveHeaders {
+		sanitized.Del(name)
+	}
+
+	return sanitized
+}
+
+// secretsOf returns the credentials sent with the request and set by its response,
+// which the bodies may repeat, e.g. the token of the user in the pages of Lezhin
+func secretsOf(r *http.Request, response *http.Response) []string {
+	var secrets []string
+
+	for param, values := range r.URL.Query() {
+		if sensitiveName(param) {
+			secrets = append(secrets, values...)
+		}
+	}
+
+	for _, cookie := range append(r.Cookies(), response.Cookies()...) {
+		secrets = append(secrets, cookie.Value)
+	}
+
+	for _, name := range []string{"Authorization", "Proxy-Authorization"} {
+		if value := r.Header.Get(name); value != "" {
+			// the credentials without the scheme, e.g. Bearer
+			fields := strings.Fields(value)
+			secrets = append(secrets, fields[len(fields)-1])
+		}
+	}
+
+	// the short values would replace the unrelated parts of the body
+	return lo.Filter(lo.Uniq(secrets), func(secret string, _ int) bool {
+		return len(secret) >= 6
+	})
+}
+
+// sanitizeBody removes the credentials from the body of the response.
+// Bodies of the endpoints that sign in are not saved at all,
+// the sensitive fields of JSON are redacted, and so are the credentials of the exchange wherever they are repeated
+func sanitizeBody(r *http.Request, response *http.Response, body []byte) []byte {
+	if signsIn(r) {
+		return []byte(redacted)
+	}
+
+	var parsed any
+	if json.Unmarshal(body, &parsed) == nil {
+		if sanitized, err := json.Marshal(sanitizeJSON(parsed)); err == nil {
+			body = sanitized
+		}
+	}
+
+	for _, secret := range secretsOf(r, response) {
+		body = bytes.ReplaceAll(body, []byte(secret), []byte(redacted))
+	}
+
+	return body
+}
+
+// sanitizeJSON redacts the string values of the sensitive fields, at any depth
+func sanitizeJSON(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for name, field := range value {
+			if _, ok := field.(string); ok && sensitiveName(name) {
+				value[name] = redacted
+				continue
+			}
+
+			value[name] = sanitizeJSON(field)
+		}
+	case []any:
+		for i, element := range value {
+			value[i] = sanitizeJSON(element)
+		}
+	}
+
+	return value
+}
+
+// session handles the requests instead of the transports while it is active
+type session interface {
+	roundTrip(next http.RoundTripper, r *http.Request) (*http.Response, error)
+}
+
+// sessionTransport passes the requests of the transport to the session
+type sessionTransport struct {
+	session session
+	next    http.RoundTripper
+}
+
+func (t *sessionTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return t.session.roundTrip(t.next, r)
+}
+
+var (
+	// active is the session started by RecordSession or ReplaySession, if any
+	active session
+	// activeMutex guards active, since the requests check it while the session is started or stopped
+	activeMutex sync.RWMutex
+)
+
+// activeSession returns the session started by RecordSession or ReplaySession, nil if there is none
+func activeSession() session {
+	activeMutex.RLock()
+	defer activeMutex.RUnlock()
+
+	return active
+}
+
+// defaultTransport is the http.DefaultTransport before the session was started
+var defaultTransport = http.DefaultTransport
+
+// InSession reports whether the requests are recorded or replayed.
+// Responses must not be cached on disk then, since the cached ones are not recorded
+func InSession() bool {
+	return activeSession() != nil
+}
+
+// Replaying reports whether the requests are answered by the replayed session instead of the network, see Replay
+func Replaying() bool {
+	_, ok := activeSession().(*replayer)
+	return ok
+}
+
+// start makes every transport of mangal go through the session,
+// including http.DefaultTransport used by the libraries of the sources
+func start(s session) {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+
+	active = s
+	http.DefaultTransport = &sessionTransport{session: s, next: defaultTransport}
+	Client.Transport = &sessionTransport{session: s, next: transport}
+	StageClient.Transport = &sessionTransport{session: s, next: transport}
+	browserClient.Transport = &sessionTransport{session: s, next: browserTransport}
+}
+
+// stop returns the transports to the network
+func stop() {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+
+	active = nil
+	http.DefaultTransport = defaultTransport
+	Client.Transport = transport
+	StageClient.Transport = transport
+	browserClient.Transport = browserTransport
+}
+
+// inSession wraps the transport with the active session, if any
+func inSession(rt http.RoundTripper) http.RoundTripper {
+	s := activeSession()
+	if s == nil {
+		return rt
+	}
+
+	return &sessionTransport{session: s, next: rt}
+}
+
+// recorder passes the exchanges to save as they happen
+type recorder struct {
+	save func(*Exchange)
+}
+
+// RecordSession saves every request and response of this run to the file at the path,
+// without the cookies and the credentials, see ReplaySession.
+// Bodies of the endpoints that sign in are not saved, and the credentials are redacted from the others, see sanitizeBody
+func RecordSession(path string) error {
+	file, err := filesystem.Api().Create(path)
+	if err != nil {
+		return err
+	}
+
+	var mutex sync.Mutex
+	Record(func(exchange *Exchange) {
+		line, err := json.Marshal(exchange)
+		if err != nil {
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		_, _ = file.Write(append(line, '\n'))
+	})
+
+	return nil
+}
+
+// Record passes every request to the network and its exchange to save, without the cookies and the credentials.
+// It returns the function that ends the recording.
+// Save may be called concurrently
+func Record(save func(*Exchange)) func() {
+	start(&recorder{save: save})
+	return stop
+}
+
+func (s *recorder) roundTrip(next http.RoundTripper, r *http.Request) (*http.Response, error) {
+	exchange := &Exchange{
+		Time:          time.Now(),
+		Method:        r.Method,
+		URL:           sanitizeURL(r.URL),
+		RequestHeader: sanitizeHeader(r.Header),
+	}
+
+	response, err := next.RoundTrip(r)
+	if err != nil {
+		exchange.Error = err.Error()
+		s.save(exchange)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	exchange.Status = response.StatusCode
+	exchange.Header = sanitizeHeader(response.Header)
+	exchange.Body = sanitizeBody(r, response, body)
+	s.save(exchange)
+
+	return response, nil
+}
+
+// replayer responds with the recorded exchanges, nothing is sent to the network
+type replayer struct {
+	mutex sync.Mutex
+	// exchanges by the methods and the sanitized URLs of their requests, in the recorded order
+	exchanges map[string][]*Exchange
+}
+
+func exchangeKey(method, url string) string {
+	return method + " " + url
+}
+
+// ReplaySession responds to the requests with the ones recorded to the file at the path by RecordSession.
+// The same requests get their responses in the recorded order, the last one is repeated after that.
+// Requests that were not recorded fail
+func ReplaySession(path string) error {
+	file, err := filesystem.Api().Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	var exchanges []*Exchange
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 256<<20)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var exchange Exchange
+		if err = json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return fmt.Errorf("%s is not a recorded session: %w", path, err)
+		}
+
+		exchanges = append(exchanges, &exchange)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	Replay(exchanges)
+	return nil
+}
+
+// Replay responds to the requests with the given exchanges, like ReplaySession.
+// It returns the function that ends the replay
+func Replay(exchanges []*Exchange) func() {
+	s := &replayer{exchanges: make(map[string][]*Exchange)}
+	for _, exchange := range exchanges {
+		k := exchangeKey(exchange.Method, exchange.URL)
+		s.exchanges[k] = append(s.exchanges[k], exchange)
+	}
+
+	start(s)
+	return stop
+}
+
+func (s *replayer) roundTrip(_ http.RoundTripper, r *http.Request) (*http.Response, error) {
+	k := exchangeKey(r.Method, sanitizeURL(r.URL))
+
+	s.mutex.Lock()
+	recorded := s.exchanges[k]
+	if len(recorded) == 0 {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("%s is not recorded in the session", k)
+	}
+
+	exchange := recorded[0]
+	if len(recorded) > 1 {
+		s.exchanges[k] = recorded[1:]
+	}
+	s.mutex.Unlock()
+
+	if exchange.Error != "" {
+		return nil, errors.New(exchange.Error)
+	}
+
+	header := exchange.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(exchange.Body)),
+		ContentLength: int64(len(exchange.Body)),
+		Request:       r,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func get(link string) (string, error) {
+	resp, err := Client.Get(link)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return fmt.Sprintf("%d %s", resp.StatusCode, body), err
+}
+
+func TestSession(t *testing.T) {
+	Convey("Given a site that counts the requests", t, func() {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-cookie"})
+
+			switch r.URL.Path {
+			case "/login":
+				_, _ = fmt.Fprint(w, `{"user": "guts", "password": "secret-password"}`)
+			case "/viewer":
+				_, _ = fmt.Fprintf(w, `{"access_token": "secret-access", "page": "/page.jpg?t=%s"}`, r.URL.Query().Get("token"))
+			default:
+				_, _ = fmt.Fprintf(w, "%s #%d", r.URL.Query().Get("q"), requests)
+			}
+		}))
+		defer server.Close()
+
+		path := filepath.Join(t.TempDir(), "session.jsonl")
+		Reset(stop)
+
+		Convey("When the session is recorded", func() {
+			So(RecordSession(path), ShouldBeNil)
+			So(InSession(), ShouldBeTrue)
+
+			first, err := get(server.URL + "/search?q=berserk&token=secret-token")
+			So(err, ShouldBeNil)
+			So(first, ShouldEqual, "200 berserk #1")
+
+			second, err := get(server.URL + "/search?q=berserk&token=secret-token")
+			So(err, ShouldBeNil)
+
+			_, err = get(server.URL + "/login")
+			So(err, ShouldBeNil)
+
+			_, err = get(server.URL + "/viewer?token=secret-token")
+			So(err, ShouldBeNil)
+			stop()
+
+			Convey("Then it should not contain the credentials", func() {
+				contents, err := os.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(contents), ShouldNotContainSubstring, "secret-token")
+				So(string(contents), ShouldNotContainSubstring, "secret-cookie")
+
+				// bodies are encoded in the file
+				for _, line := range bytes.Split(bytes.TrimSpace(contents), []byte("\n")) {
+					var exchange Exchange
+					So(json.Unmarshal(line, &exchange), ShouldBeNil)
+					So(string(exchange.Body), ShouldNotContainSubstring, "secret-password")
+					So(string(exchange.Body), ShouldNotContainSubstring, "secret-access")
+					So(string(exchange.Body), ShouldNotContainSubstring, "secret-token")
+				}
+				So(string(contents), ShouldContainSubstring, redacted)
+			})
+
+			Convey("And replayed without the site", func() {
+				server.Close()
+				So(ReplaySession(path), ShouldBeNil)
+
+				Convey("Then the responses should be repeated in order", func() {
+					replayed, err := get(server.URL + "/search?q=berserk&token=other-token")
+					So(err, ShouldBeNil)
+					So(replayed, ShouldEqual, first)
+
+					replayed, err = get(server.URL + "/search?q=berserk&token=other-token")
+					So(err, ShouldBeNil)
+					So(replayed, ShouldEqual, second)
+
+					replayed, err = get(server.URL + "/search?q=berserk&token=other-token")
+					So(err, ShouldBeNil)
+					So(replayed, ShouldEqual, second)
+				})
+
+				Convey("Then the requests that were not recorded should fail", func() {
+					_, err := get(server.URL + "/search?q=vinland")
+					So(err, ShouldNotBeNil)
+				})
+			})
+		})
+	})
+}
+
+func TestSignsIn(t *testing.T) {
+	Convey("Given the requests to the sites", t, func() {
+		request := func(path string) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil)
+		}
+
+		Convey("Then the endpoints that sign in should be told by the segments of their paths", func() {
+			for _, path := range []string{"/login", "/ko/login/submit", "/account/authenticate", "/oauth2/access_token", "/api/token.json"} {
+				So(signsIn(request(path)), ShouldBeTrue)
+			}
+		})
+
+		Convey("Then the other endpoints should not, even if their paths contain the same letters", func() {
+			for _, path := range []string{"/authors", "/author/123/feed", "/manga/tokens-of-love", "/sessions-of-the-week"} {
+				So(signsIn(request(path)), ShouldBeFalse)
+			}
+		})
+	})
+}
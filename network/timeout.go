@@ -0,0 +1,48 @@
+package network
+
+import (
+	"context"
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// Stage of scraping a source, each one has its own timeout
+type Stage int
+
+const (
+	// StageSearch is searching for the mangas
+	StageSearch Stage = iota
+	// StageChapters is listing the chapters of a manga
+	StageChapters
+	// StagePages is fetching the pages of a chapter
+	StagePages
+	// StageImages is downloading a page image
+	StageImages
+)
+
+var timeoutKeys = map[Stage]string{
+	StageSearch:   key.NetworkTimeoutSearch,
+	StageChapters: key.NetworkTimeoutChapters,
+	StagePages:    key.NetworkTimeoutPages,
+	StageImages:   key.NetworkTimeoutImages,
+}
+
+// Timeout of the stage from the config. Zero means no timeout
+func (s Stage) Timeout() time.Duration {
+	timeout := viper.GetDuration(timeoutKeys[s])
+	if timeout < 0 {
+		return 0
+	}
+
+	return timeout
+}
+
+// Context returns the context that is canceled once the timeout of the stage has passed
+func (s Stage) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if timeout := s.Timeout(); timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+
+	return context.WithCancel(parent)
+}
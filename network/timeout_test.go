@@ -0,0 +1,46 @@
+package network
+
+import (
+	"context"
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"testing"
+	"time"
+)
+
+func TestStage(t *testing.T) {
+	Convey("Given distinct timeouts of the stages", t, func() {
+		viper.Set(key.NetworkTimeoutSearch, "5s")
+		viper.Set(key.NetworkTimeoutImages, "0")
+		defer viper.Set(key.NetworkTimeoutSearch, nil)
+		defer viper.Set(key.NetworkTimeoutImages, nil)
+
+		Convey("When getting the timeout of the search", func() {
+			Convey("Then it should be parsed from the config", func() {
+				So(StageSearch.Timeout(), ShouldEqual, 5*time.Second)
+			})
+		})
+
+		Convey("When getting the context of the search", func() {
+			ctx, cancel := StageSearch.Context(context.Background())
+			defer cancel()
+
+			Convey("Then it should have the deadline", func() {
+				deadline, ok := ctx.Deadline()
+				So(ok, ShouldBeTrue)
+				So(time.Until(deadline), ShouldBeBetweenOrEqual, 4*time.Second, 5*time.Second)
+			})
+		})
+
+		Convey("When getting the context of the images without a timeout", func() {
+			ctx, cancel := StageImages.Context(context.Background())
+			defer cancel()
+
+			Convey("Then it should have no deadline", func() {
+				_, ok := ctx.Deadline()
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
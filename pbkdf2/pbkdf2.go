@@ -0,0 +1,46 @@
+// Package pbkdf2 implements the key derivation function described in RFC 2898.
+// It mirrors golang.org/x/crypto/pbkdf2, which is not a dependency of mangal.
+package pbkdf2
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"hash"
+)
+
+// Key derives a key of the given length from the password and salt
+// using HMAC with the given hash function as the pseudorandom function
+func Key(password, salt []byte, iterations, length int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	hashSize := prf.Size()
+	blocks := (length + hashSize - 1) / hashSize
+
+	var (
+		derived = make([]byte, 0, blocks*hashSize)
+		buf     [4]byte
+		u       = make([]byte, hashSize)
+	)
+
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		derived = prf.Sum(derived)
+
+		t := derived[len(derived)-hashSize:]
+		copy(u, t)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+
+			for j := range u {
+				t[j] ^= u[j]
+			}
+		}
+	}
+
+	return derived[:length]
+}
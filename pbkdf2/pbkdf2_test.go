@@ -0,0 +1,29 @@
+package pbkdf2
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	Convey("Given RFC 6070 test vectors", t, func() {
+		Convey("Then derived keys should match", func() {
+			So(hex.EncodeToString(Key([]byte("password"), []byte("salt"), 1, 20, sha1.New)), ShouldEqual, "0c60c80f961f0e71f3a9b524af6012062fe037a6")
+			So(hex.EncodeToString(Key([]byte("password"), []byte("salt"), 4096, 20, sha1.New)), ShouldEqual, "4b007901b765489abead49d926f721d065a429c1")
+			So(
+				hex.EncodeToString(Key([]byte("passwordPASSWORDpassword"), []byte("saltSALTsaltSALTsaltSALTsaltSALTsalt"), 4096, 25, sha1.New)),
+				ShouldEqual,
+				"3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038",
+			)
+		})
+	})
+
+	Convey("Given SHA-256 as the hash function", t, func() {
+		Convey("Then derived key should match the known vector", func() {
+			So(hex.EncodeToString(Key([]byte("password"), []byte("salt"), 1, 32, sha256.New)), ShouldEqual, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b")
+		})
+	})
+}
@@ -0,0 +1,180 @@
+// Package profiling writes the CPU and the memory profiles of the run to the logs directory,
+// to diagnose the runs that are slow or take too much memory, e.g. the huge batches.
+// The profiles are read with go tool pprof
+package profiling
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/where"
+	"io"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// peakInterval is how often the heap is checked for the new peak
+const peakInterval = time.Second
+
+// peakGrowth is how much the heap must grow since the last peak profile to write it again
+const peakGrowth = 1.1
+
+// Profiles started by Start
+type Profiles struct {
+	// CPU profile of the whole run, empty if it is not written
+	CPU string
+	// Mem is the heap profile at the end of the run, empty if it is not written
+	Mem string
+	// MemPeak is the heap profile at its largest size during the run
+	MemPeak string
+}
+
+var running = struct {
+	mutex    sync.Mutex
+	profiles *Profiles
+	cpu      io.Closer
+	done     chan struct{}
+	stopped  sync.WaitGroup
+}{}
+
+// Start starts the CPU profile and the watching of the heap, if requested. They are written by Stop.
+// Profiles are named by the time the run started, e.g. cpu-2006-01-02T15-04-05.pprof
+func Start(cpu, mem bool) (*Profiles, error) {
+	running.mutex.Lock()
+	defer running.mutex.Unlock()
+
+	if running.profiles != nil {
+		return nil, fmt.Errorf("profiling is started already")
+	}
+
+	profiles := &Profiles{}
+	if !cpu && !mem {
+		return profiles, nil
+	}
+
+	var (
+		dir   = where.Logs()
+		stamp = time.Now().Format("2006-01-02T15-04-05")
+	)
+
+	if cpu {
+		profiles.CPU = filepath.Join(dir, fmt.Sprintf("cpu-%s.pprof", stamp))
+
+		file, err := filesystem.Api().Create(profiles.CPU)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = pprof.StartCPUProfile(file); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+
+		running.cpu = file
+	}
+
+	if mem {
+		profiles.Mem = filepath.Join(dir, fmt.Sprintf("mem-%s.pprof", stamp))
+		profiles.MemPeak = filepath.Join(dir, fmt.Sprintf("mem-peak-%s.pprof", stamp))
+
+		running.done = make(chan struct{})
+		running.stopped.Add(1)
+		go watchPeak(profiles.MemPeak, running.done)
+	}
+
+	running.profiles = profiles
+	return profiles, nil
+}
+
+// watchPeak writes the heap profile each time the heap grows beyond the previous peak,
+// since the usage at the end of the run may be far below it
+func watchPeak(path string, done <-chan struct{}) {
+	defer running.stopped.Done()
+
+	ticker := time.NewTicker(peakInterval)
+	defer ticker.Stop()
+
+	var (
+		stats runtime.MemStats
+		peak  uint64
+	)
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			runtime.ReadMemStats(&stats)
+			if float64(stats.HeapInuse) < float64(peak)*peakGrowth {
+				continue
+			}
+
+			peak = stats.HeapInuse
+			if err := writeHeap(path); err != nil {
+				log.Warn(err)
+				return
+			}
+
+			log.Debugf("heap peak of %d bytes is profiled", peak)
+		}
+	}
+}
+
+func writeHeap(path string) error {
+	file, err := filesystem.Api().Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = pprof.Lookup("heap").WriteTo(file, 0); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Stop writes the started profiles and returns them, nil if nothing was started.
+// It is safe to call it several times, e.g. before each exit
+func Stop() *Profiles {
+	running.mutex.Lock()
+	defer running.mutex.Unlock()
+
+	profiles := running.profiles
+	if profiles == nil {
+		return nil
+	}
+
+	running.profiles = nil
+
+	if running.cpu != nil {
+		pprof.StopCPUProfile()
+		if err := running.cpu.Close(); err != nil {
+			log.Warn(err)
+		}
+
+		running.cpu = nil
+	}
+
+	if running.done != nil {
+		close(running.done)
+		running.stopped.Wait()
+		running.done = nil
+
+		// the profile shows the memory that is still in use, not the garbage
+		runtime.GC()
+		if err := writeHeap(profiles.Mem); err != nil {
+			log.Warn(err)
+			profiles.Mem = ""
+		}
+
+		if exists, _ := filesystem.Api().Exists(profiles.MemPeak); !exists {
+			profiles.MemPeak = ""
+		}
+	}
+
+	return profiles
+}
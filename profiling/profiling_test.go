@@ -0,0 +1,49 @@
+package profiling
+
+import (
+	"github.com/metafates/mangal/filesystem"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+func TestProfiling(t *testing.T) {
+	Convey("Given the cpu and the memory profiles are started", t, func() {
+		profiles, err := Start(true, true)
+		So(err, ShouldBeNil)
+
+		Convey("When they are started again", func() {
+			_, err := Start(true, false)
+
+			Convey("Then it should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the run ends after the heap was checked", func() {
+			time.Sleep(peakInterval + 200*time.Millisecond)
+			stopped := Stop()
+
+			Convey("Then every profile should be written", func() {
+				So(stopped, ShouldResemble, profiles)
+				for _, path := range []string{profiles.CPU, profiles.Mem, profiles.MemPeak} {
+					info, err := filesystem.Api().Stat(path)
+					So(err, ShouldBeNil)
+					So(info.Size(), ShouldBeGreaterThan, 0)
+				}
+			})
+
+			Convey("Then stopping them again should do nothing", func() {
+				So(Stop(), ShouldBeNil)
+			})
+		})
+
+		Reset(func() {
+			Stop()
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package custom
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	lua "github.com/yuin/gopher-lua"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Versions of the API of the Lua sources, declared in the header of the source:
+//
+//	-- @api     2
+//
+// Sources without the declaration are loaded as APIv1
+const (
+	// APIv1 passes the URLs of the manga and the chapter to MangaChapters and ChapterPages
+	APIv1 = 1
+	// APIv2 passes the tables of the manga and the chapter to MangaChapters and ChapterPages
+	// and reads the ids, the staff, the status and the tags of the mangas, and the extensions of the pages
+	APIv2 = 2
+	// LatestAPI is the newest version of the API that this mangal supports
+	LatestAPI = APIv2
+)
+
+var apiTag = regexp.MustCompile(`^--\s*@api\s+(\S+)`)
+
+// APIVersion returns the version of the API declared in the header of the source.
+// It returns an error if the source requires a newer mangal
+func APIVersion(path string) (int, error) {
+	file, err := filesystem.Api().Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer util.Ignore(file.Close)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// the header ends with the first line of the code
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+
+		match := apiTag.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(strings.TrimPrefix(match[1], "v"))
+		if err != nil || version < APIv1 {
+			return 0, fmt.Errorf("source %s declares an invalid api version %q", util.FileStem(path), match[1])
+		}
+
+		if version > LatestAPI {
+			return 0, fmt.Errorf(
+				"source %s requires api v%d, but mangal %s supports up to v%d, update mangal to use it",
+				util.FileStem(path),
+				version,
+				constant.Version,
+				LatestAPI,
+			)
+		}
+
+		return version, nil
+	}
+
+	if err = scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return APIv1, nil
+}
+
+// mangaArg is the argument of MangaChapters
+func (s *luaSource) mangaArg(manga *source.Manga) lua.LValue {
+	if s.api < APIv2 {
+		return lua.LString(manga.URL)
+	}
+
+	return mangaToTable(s.state, manga)
+}
+
+// chapterArg is the argument of ChapterPages
+func (s *luaSource) chapterArg(chapter *source.Chapter) lua.LValue {
+	if s.api < APIv2 {
+		return lua.LString(chapter.URL)
+	}
+
+	table := s.state.NewTable()
+	table.RawSetString("name", lua.LString(chapter.Name))
+	table.RawSetString("url", lua.LString(chapter.URL))
+	table.RawSetString("id", lua.LString(chapter.ID))
+	table.RawSetString("index", lua.LNumber(chapter.Index))
+	table.RawSetString("volume", lua.LString(chapter.Volume))
+	table.RawSetString("title", lua.LString(chapter.Title))
+
+	if chapter.Manga != nil {
+		table.RawSetString("manga", mangaToTable(s.state, chapter.Manga))
+	}
+
+	return table
+}
+
+func mangaToTable(state *lua.LState, manga *source.Manga) *lua.LTable {
+	table := state.NewTable()
+	table.RawSetString("name", lua.LString(manga.Name))
+	table.RawSetString("url", lua.LString(manga.URL))
+	table.RawSetString("id", lua.LString(manga.ID))
+	table.RawSetString("index", lua.LNumber(manga.Index))
+	table.RawSetString("title", lua.LString(manga.Title()))
+	table.RawSetString("summary", lua.LString(manga.Metadata.Summary))
+	return table
+}
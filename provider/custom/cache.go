@@ -7,10 +7,14 @@ import (
 	"github.com/metafates/mangal/where"
 	"github.com/samber/mo"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
+// cacher is safe for concurrent use.
+// Map returned by gache is shared, so it is only accessed under the mutex
 type cacher[T any] struct {
+	mutex    sync.Mutex
 	internal *gache.Cache[map[string]T]
 }
 
@@ -25,6 +29,9 @@ func newCacher[T any](name string) *cacher[T] {
 }
 
 func (c *cacher[T]) Get(key string) mo.Option[T] {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	data, expired, err := c.internal.Get()
 	if err != nil || expired || data == nil {
 		return mo.None[T]()
@@ -38,6 +45,9 @@ func (c *cacher[T]) Get(key string) mo.Option[T] {
 }
 
 func (c *cacher[T]) Set(key string, t T) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	data, expired, err := c.internal.Get()
 
 	if err != nil {
@@ -2,22 +2,27 @@ package custom
 
 import (
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
 	lua "github.com/yuin/gopher-lua"
-	"strconv"
 )
 
 func (s *luaSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
-	if chapters := s.cache.chapters.Get(manga.URL); chapters.IsPresent() {
-		c := chapters.MustGet()
-		for _, chapter := range c {
-			chapter.Manga = manga
-		}
-
-		return c, nil
+	cacheKey := memo.MangaKey(s.ID(), manga)
+	if chapters := s.cache.chapters.Get(cacheKey); chapters.IsPresent() {
+		// cached chapters are shared, so copies are returned
+		return lo.Map(chapters.MustGet(), func(chapter *source.Chapter, _ int) *source.Chapter {
+			copied := *chapter
+			copied.Manga = manga
+			return &copied
+		}), nil
 	}
 
-	_, err := s.call(constant.MangaChaptersFn, lua.LTTable, lua.LString(manga.URL))
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.call(constant.MangaChaptersFn, lua.LTTable, s.mangaArg(manga))
 
 	if err != nil {
 		return nil, err
@@ -35,12 +40,12 @@ func (s *luaSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 			s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with tables as values, got " + v.Type().String() + " as a value")
 		}
 
-		index, err := strconv.ParseUint(k.String(), 10, 16)
+		index, err := source.ParseIndex(k.String())
 		if err != nil {
 			s.state.RaiseError(constant.MangaChaptersFn + " was expected to return a table with unsigned integers as keys. " + err.Error())
 		}
 
-		chapter, err := chapterFromTable(v.(*lua.LTable), manga, uint16(index))
+		chapter, err := chapterFromTable(v.(*lua.LTable), manga, index, s.api)
 
 		if err != nil {
 			s.state.RaiseError(err.Error())
@@ -49,6 +54,10 @@ func (s *luaSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 		chapters = append(chapters, chapter)
 	})
 
-	_ = s.cache.chapters.Set(manga.URL, chapters)
+	if len(chapters) == 0 {
+		s.nothingFound(constant.MangaChaptersFn, "chapters")
+	}
+
+	_ = s.cache.chapters.Set(cacheKey, chapters)
 	return chapters, nil
 }
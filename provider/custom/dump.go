@@ -0,0 +1,113 @@
+package custom
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/metafates/mangal-lua-libs/headless"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+	lua "github.com/yuin/gopher-lua"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// headlessPage is the last page opened by the headless browser of the source,
+// so that it can be saved when the source finds nothing
+type headlessPage struct {
+	page *rod.Page
+}
+
+// preloadHeadless replaces the headless module of the libs with the one
+// that remembers the pages opened with browser:page(url)
+func preloadHeadless(state *lua.LState) *headlessPage {
+	last := &headlessPage{}
+	loader := headless.Loader()
+
+	state.PreloadModule("headless", func(L *lua.LState) int {
+		returned := loader(L)
+
+		methods, ok := L.GetField(L.GetTypeMetatable("browser"), "__index").(*lua.LTable)
+		if !ok {
+			return returned
+		}
+
+		open, ok := L.GetField(methods, "page").(*lua.LFunction)
+		if !ok {
+			return returned
+		}
+
+		L.SetField(methods, "page", L.NewFunction(func(L *lua.LState) int {
+			args := make([]lua.LValue, L.GetTop())
+			for i := range args {
+				args[i] = L.Get(i + 1)
+			}
+
+			L.Push(open)
+			for _, arg := range args {
+				L.Push(arg)
+			}
+
+			L.Call(len(args), 1)
+
+			if data, ok := L.Get(-1).(*lua.LUserData); ok {
+				if page, ok := data.Value.(*rod.Page); ok {
+					last.page = page
+				}
+			}
+
+			return 1
+		}))
+
+		return returned
+	})
+
+	return last
+}
+
+// dump saves the HTML and the screenshot of the page to the logs directory, if enabled by the config.
+// The paths of the saved files are returned, none if the source opened no page
+func (h *headlessPage) dump(name, stage string) []string {
+	if h == nil || h.page == nil || !viper.GetBool(key.LogsDumpPages) {
+		return nil
+	}
+
+	var (
+		prefix = filepath.Join(where.Logs(), fmt.Sprintf("%s-%s-%s", util.SanitizeFilename(name), stage, time.Now().Format("2006-01-02T15-04-05.000")))
+		paths  []string
+	)
+
+	save := func(extension string, contents []byte, err error) {
+		if err == nil {
+			err = filesystem.Api().WriteFile(prefix+extension, contents, os.ModePerm)
+		}
+
+		if err != nil {
+			log.Warn(err)
+			return
+		}
+
+		paths = append(paths, prefix+extension)
+	}
+
+	html, err := h.page.HTML()
+	save(".html", []byte(html), err)
+
+	screenshot, err := h.page.Screenshot(true, nil)
+	save(".png", screenshot, err)
+
+	return paths
+}
+
+// nothingFound logs the saved page of the headless browser when the function of the source returns nothing.
+// The source is not failed, since nothing may be found
+func (s *luaSource) nothingFound(fn, stage string) {
+	if paths := s.headless.dump(s.name, stage); len(paths) > 0 {
+		log.Warnf("%s: %s returned nothing, the page is saved to %s", s.name, fn, strings.Join(paths, ", "))
+	}
+}
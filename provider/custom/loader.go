@@ -6,6 +6,7 @@ import (
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
 	lua "github.com/yuin/gopher-lua"
 	"github.com/yuin/gopher-lua/parse"
 )
@@ -14,7 +15,14 @@ func IDfromName(name string) string {
 	return name + " custom"
 }
 
+// LoadSource loads the Lua source at the given path.
+// Sources of the older api versions are called the way they expect, see APIVersion
 func LoadSource(path string, validate bool) (source.Source, error) {
+	api, err := APIVersion(path)
+	if err != nil {
+		return nil, err
+	}
+
 	proto, err := Compile(path)
 	if err != nil {
 		return nil, err
@@ -22,6 +30,7 @@ func LoadSource(path string, validate bool) (source.Source, error) {
 
 	state := lua.NewState()
 	libs.Preload(state)
+	headless := preloadHeadless(state)
 
 	lfunc := state.NewFunctionFromProto(proto)
 	state.Push(lfunc)
@@ -33,23 +42,39 @@ func LoadSource(path string, validate bool) (source.Source, error) {
 	name := util.FileStem(path)
 
 	if validate {
-		for _, fn := range mustHave {
-			defined := state.GetGlobal(fn)
-
-			if defined.Type() != lua.LTFunction {
-				return nil, fmt.Errorf("required function %s is not defined in the luaSource %s", fn, name)
-			}
+		if err = Validate(state, name); err != nil {
+			return nil, err
 		}
 	}
 
-	luaSource, err := newLuaSource(name, state)
+	luaSource, err := newLuaSource(name, state, api)
 	if err != nil {
 		return nil, err
 	}
 
+	luaSource.headless = headless
+
 	return luaSource, nil
 }
 
+// Validate returns an error if any of the required functions of the source is not defined in the state
+func Validate(state *lua.LState, name string) error {
+	for _, fn := range mustHave {
+		if state.GetGlobal(fn).Type() != lua.LTFunction {
+			return fmt.Errorf("required function %s is not defined in the luaSource %s", fn, name)
+		}
+	}
+
+	return nil
+}
+
+// IsSource reports whether any of the functions of the source is defined in the state
+func IsSource(state *lua.LState) bool {
+	return lo.SomeBy(mustHave, func(fn string) bool {
+		return state.GetGlobal(fn).Type() == lua.LTFunction
+	})
+}
+
 func Compile(path string) (*lua.FunctionProto, error) {
 	file, err := filesystem.Api().Open(path)
 
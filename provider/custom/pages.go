@@ -7,7 +7,10 @@ import (
 )
 
 func (s *luaSource) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
-	_, err := s.call(constant.ChapterPagesFn, lua.LTTable, lua.LString(chapter.URL))
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.call(constant.ChapterPagesFn, lua.LTTable, s.chapterArg(chapter))
 
 	if err != nil {
 		return nil, err
@@ -16,6 +19,9 @@ func (s *luaSource) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
 	table := s.state.CheckTable(-1)
 	pages := make([]*source.Page, 0)
 
+	// text blocks are appended to the chapter, the ones of the previous call would be repeated
+	chapter.Text = ""
+
 	table.ForEach(func(k lua.LValue, v lua.LValue) {
 		if k.Type() != lua.LTNumber {
 			s.state.RaiseError(constant.ChapterPagesFn + " was expected to return a table with numbers as keys, got " + k.Type().String() + " as a key")
@@ -25,14 +31,20 @@ func (s *luaSource) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
 			s.state.RaiseError(constant.ChapterPagesFn + " was expected to return a table with tables as values, got " + v.Type().String() + " as a value")
 		}
 
-		page, err := pageFromTable(v.(*lua.LTable), chapter)
+		page, err := pageFromTable(v.(*lua.LTable), chapter, s.api)
 
 		if err != nil {
 			s.state.RaiseError(err.Error())
 		}
 
-		pages = append(pages, page)
+		if page != nil {
+			pages = append(pages, page)
+		}
 	})
 
+	if len(pages) == 0 && chapter.Text == "" {
+		s.nothingFound(constant.ChapterPagesFn, "pages")
+	}
+
 	return pages, nil
 }
@@ -1,23 +1,34 @@
 package custom
 
 import (
+	"context"
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
 	lua "github.com/yuin/gopher-lua"
-	"strconv"
 )
 
 func (s *luaSource) Search(query string) ([]*source.Manga, error) {
-	if mangas := s.cache.mangas.Get(query); mangas.IsPresent() {
-		m := mangas.MustGet()
-		for _, manga := range m {
-			manga.Source = s
-		}
+	return s.SearchContext(context.Background(), query)
+}
 
-		return m, nil
+// SearchContext searches for mangas until the context is canceled
+func (s *luaSource) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
+	cacheKey := memo.SearchKey(s.ID(), query)
+	if mangas := s.cache.mangas.Get(cacheKey); mangas.IsPresent() {
+		// cached mangas are shared, so copies are returned
+		return lo.Map(mangas.MustGet(), func(manga *source.Manga, _ int) *source.Manga {
+			copied := *manga
+			copied.Source = s
+			return &copied
+		}), nil
 	}
 
-	_, err := s.call(constant.SearchMangaFn, lua.LTTable, lua.LString(query))
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.callContext(ctx, constant.SearchMangaFn, lua.LTTable, lua.LString(query))
 
 	if err != nil {
 		return nil, err
@@ -35,12 +46,12 @@ func (s *luaSource) Search(query string) ([]*source.Manga, error) {
 			s.state.RaiseError(constant.SearchMangaFn + " was expected to return a table with tables as values, got " + v.Type().String() + " as a value")
 		}
 
-		index, err := strconv.ParseUint(k.String(), 10, 16)
+		index, err := source.ParseIndex(k.String())
 		if err != nil {
 			s.state.RaiseError(constant.SearchMangaFn + " was expected to return a table with unsigned integers as keys. " + err.Error())
 		}
 
-		manga, err := mangaFromTable(v.(*lua.LTable), uint16(index))
+		manga, err := mangaFromTable(v.(*lua.LTable), index, s.api)
 
 		if err != nil {
 			s.state.RaiseError(err.Error())
@@ -50,6 +61,6 @@ func (s *luaSource) Search(query string) ([]*source.Manga, error) {
 		mangas = append(mangas, manga)
 	})
 
-	_ = s.cache.mangas.Set(query, mangas)
+	_ = s.cache.mangas.Set(cacheKey, mangas)
 	return mangas, nil
 }
@@ -1,15 +1,23 @@
 package custom
 
 import (
+	"context"
 	"fmt"
 	"github.com/metafates/mangal/source"
 	lua "github.com/yuin/gopher-lua"
+	"sync"
 )
 
 type luaSource struct {
-	name  string
+	name string
+	// api is the version of the API of the source
+	api int
+	// mutex guards the state, Lua VM can't be used from several goroutines at once
+	mutex sync.Mutex
 	state *lua.LState
-	cache struct {
+	// headless is the last page opened by the source, nil if the headless browser is not used
+	headless *headlessPage
+	cache    struct {
 		mangas   *cacher[[]*source.Manga]
 		chapters *cacher[[]*source.Chapter]
 	}
@@ -19,9 +27,10 @@ func (s *luaSource) Name() string {
 	return s.name
 }
 
-func newLuaSource(name string, state *lua.LState) (*luaSource, error) {
+func newLuaSource(name string, state *lua.LState, api int) (*luaSource, error) {
 	s := &luaSource{
 		name:  name,
+		api:   api,
 		state: state,
 	}
 
@@ -36,6 +45,17 @@ func newLuaSource(name string, state *lua.LState) (*luaSource, error) {
 }
 
 func (s *luaSource) call(fn string, ret lua.LValueType, args ...lua.LValue) (lua.LValue, error) {
+	return s.callContext(context.Background(), fn, ret, args...)
+}
+
+// callContext calls the function of the source until the context is canceled.
+// Lua VM stops at the next instruction, so the request that is being made by the script is finished first
+func (s *luaSource) callContext(ctx context.Context, fn string, ret lua.LValueType, args ...lua.LValue) (lua.LValue, error) {
+	if ctx.Done() != nil {
+		s.state.SetContext(ctx)
+		defer s.state.RemoveContext()
+	}
+
 	err := s.state.CallByParam(lua.P{
 		Fn:      s.state.GetGlobal(fn),
 		NRet:    1,
@@ -43,6 +63,10 @@ func (s *luaSource) call(fn string, ret lua.LValueType, args ...lua.LValue) (lua
 	}, args...)
 
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
 		return nil, err
 	}
 
@@ -0,0 +1,256 @@
+package custom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	libs "github.com/metafates/mangal-lua-libs"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	lua "github.com/yuin/gopher-lua"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+const testScript = `
+function SearchManga(query)
+	return { { name = query, url = "https://example.com/" .. query } }
+end
+
+function MangaChapters(mangaURL)
+	return { { name = "Chapter 1", url = mangaURL .. "/1" } }
+end
+
+function ChapterPages(chapterURL)
+	return { { url = chapterURL .. "/1.png", index = 1 } }
+end
+`
+
+func TestLuaSource(t *testing.T) {
+	Convey("Given a custom source", t, func() {
+		lo.Must0(filesystem.Api().WriteFile("Concurrent.lua", []byte(testScript), os.ModePerm))
+		src, err := LoadSource("Concurrent.lua", true)
+		So(err, ShouldBeNil)
+
+		Convey("When it is used from several goroutines", func() {
+			const workers = 10
+
+			var (
+				wg    sync.WaitGroup
+				mutex sync.Mutex
+				pages = make(map[string][]*source.Page)
+				errs  []error
+			)
+
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(query string) {
+					defer wg.Done()
+
+					var found []*source.Page
+					mangas, err := src.Search(query)
+					if err == nil && len(mangas) == 1 {
+						var chapters []*source.Chapter
+						chapters, err = src.ChaptersOf(mangas[0])
+						if err == nil && len(chapters) == 1 {
+							found, err = src.PagesOf(chapters[0])
+						}
+					}
+
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					if err != nil {
+						errs = append(errs, err)
+						return
+					}
+
+					pages[query] = found
+				}(fmt.Sprintf("manga%d", i))
+			}
+
+			wg.Wait()
+
+			Convey("Then every caller should get its own results", func() {
+				So(errs, ShouldBeEmpty)
+				So(len(pages), ShouldEqual, workers)
+
+				for query, found := range pages {
+					So(len(found), ShouldEqual, 1)
+					So(found[0].URL, ShouldEqual, "https://example.com/"+query+"/1/1.png")
+				}
+			})
+
+			Convey("Then cached results should be copies", func() {
+				first := lo.Must(src.Search("manga0"))
+				second := lo.Must(src.Search("manga0"))
+				So(first[0], ShouldNotPointTo, second[0])
+			})
+		})
+	})
+}
+
+const testScriptV2 = `
+-- @name    Versioned
+-- @api     2
+
+function SearchManga(query)
+	return { { name = query, url = "https://example.com/" .. query, id = query, author = "Kentaro Miura", status = "releasing", native_title = "ベルセルク", alt_titles = "Berserk: The Prototype, Berserk" } }
+end
+
+function MangaChapters(manga)
+	return { { name = "Chapter 1", url = manga.url .. "/1", id = manga.id .. "-1" } }
+end
+
+function ChapterPages(chapter)
+	return { { url = chapter.url .. "/1?token=abc", index = 1, extension = "webp" } }
+end
+`
+
+func TestAPIVersion(t *testing.T) {
+	Convey("Given a source without the api version", t, func() {
+		lo.Must0(filesystem.Api().WriteFile("Unversioned.lua", []byte(testScript), os.ModePerm))
+
+		Convey("Then it should be loaded as v1", func() {
+			version, err := APIVersion("Unversioned.lua")
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, APIv1)
+		})
+	})
+
+	Convey("Given a source of api v2", t, func() {
+		lo.Must0(filesystem.Api().WriteFile("Versioned.lua", []byte(testScriptV2), os.ModePerm))
+		src, err := LoadSource("Versioned.lua", true)
+		So(err, ShouldBeNil)
+
+		Convey("Then it should get the tables and return the richer fields", func() {
+			mangas, err := src.Search("berserk")
+			So(err, ShouldBeNil)
+			So(mangas[0].ID, ShouldEqual, "berserk")
+			So(mangas[0].Metadata.Staff.Story, ShouldResemble, []string{"Kentaro Miura"})
+			So(mangas[0].Metadata.Status, ShouldEqual, "RELEASING")
+			So(mangas[0].HasTitle("ベルセルク"), ShouldBeTrue)
+			So(mangas[0].Metadata.Synonyms, ShouldResemble, []string{"Berserk: The Prototype", "Berserk"})
+
+			chapters, err := src.ChaptersOf(mangas[0])
+			So(err, ShouldBeNil)
+			So(chapters[0].ID, ShouldEqual, "berserk-1")
+
+			pages, err := src.PagesOf(chapters[0])
+			So(err, ShouldBeNil)
+			So(pages[0].URL, ShouldEqual, "https://example.com/berserk/1/1?token=abc")
+			So(pages[0].Extension, ShouldEqual, ".webp")
+		})
+	})
+
+	Convey("Given a source that requires a newer mangal", t, func() {
+		script := strings.Replace(testScriptV2, "@api     2", "@api     99", 1)
+		lo.Must0(filesystem.Api().WriteFile("Future.lua", []byte(script), os.ModePerm))
+
+		Convey("Then it should be refused", func() {
+			_, err := LoadSource("Future.lua", true)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "requires api v99")
+		})
+	})
+}
+
+const testTextScript = `
+function SearchManga(query)
+	return { { name = query, url = "https://example.com/" .. query } }
+end
+
+function MangaChapters(mangaURL)
+	return { { name = "Chapter 1", url = mangaURL .. "/1" } }
+end
+
+function ChapterPages(chapterURL)
+	return { { text = "First block" }, { text = "Second block" } }
+end
+`
+
+func TestTextChapter(t *testing.T) {
+	Convey("Given a custom source of the text chapters", t, func() {
+		lo.Must0(filesystem.Api().WriteFile("Novel.lua", []byte(testTextScript), os.ModePerm))
+		src := lo.Must(LoadSource("Novel.lua", true))
+		chapter := lo.Must(src.ChaptersOf(lo.Must(src.Search("novel"))[0]))[0]
+
+		Convey("When the pages of the chapter are requested twice", func() {
+			lo.Must(src.PagesOf(chapter))
+			lo.Must(src.PagesOf(chapter))
+
+			Convey("Then the text should not be repeated", func() {
+				So(chapter.Text, ShouldEqual, "First block\n\nSecond block")
+			})
+		})
+	})
+}
+
+const testEndlessScript = `
+function SearchManga(query)
+	while true do end
+end
+
+function MangaChapters(mangaURL)
+	return {}
+end
+
+function ChapterPages(chapterURL)
+	return {}
+end
+`
+
+func TestSearchContext(t *testing.T) {
+	Convey("Given a custom source that never finishes the search", t, func() {
+		lo.Must0(filesystem.Api().WriteFile("Endless.lua", []byte(testEndlessScript), os.ModePerm))
+		src := lo.Must(LoadSource("Endless.lua", true))
+
+		Convey("When the search is canceled", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := source.SearchContext(ctx, src, "endless")
+
+			Convey("Then the script should be stopped", func() {
+				So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			})
+
+			Convey("And the source should still be usable", func() {
+				_, err = src.ChaptersOf(&source.Manga{URL: "https://example.com"})
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestPreloadHeadless(t *testing.T) {
+	Convey("Given a state with the headless module", t, func() {
+		state := lua.NewState()
+		defer state.Close()
+
+		libs.Preload(state)
+		last := preloadHeadless(state)
+
+		Convey("When the source requires it", func() {
+			err := state.DoString(`
+local headless = require("headless")
+assert(type(headless.browser) == "function")
+`)
+
+			Convey("Then the module should be loaded with the pages not opened yet", func() {
+				So(err, ShouldBeNil)
+				So(last.page, ShouldBeNil)
+				So(last.dump("Test", "pages"), ShouldBeEmpty)
+			})
+		})
+	})
+}
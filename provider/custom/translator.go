@@ -2,12 +2,12 @@ package custom
 
 import (
 	"fmt"
+	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/source"
 	"github.com/samber/lo"
 	lua "github.com/yuin/gopher-lua"
 	"net/url"
 	"path/filepath"
-	"strconv"
 	"strings"
 )
 
@@ -46,7 +46,17 @@ func translate(
 	return
 }
 
-func mangaFromTable(table *lua.LTable, index uint16) (manga *source.Manga, err error) {
+// statuses of the mangas that the sources of APIv2 can return
+var statuses = []string{"FINISHED", "RELEASING", "NOT_YET_RELEASED", "CANCELLED", "HIATUS"}
+
+// splitList splits the comma separated list, e.g. genres
+func splitList(list string) []string {
+	return lo.Map(strings.Split(list, ","), func(item string, _ int) string {
+		return strings.TrimSpace(item)
+	})
+}
+
+func mangaFromTable(table *lua.LTable, index int, api int) (manga *source.Manga, err error) {
 	manga = &source.Manga{
 		Index:    index,
 		Chapters: []*source.Chapter{},
@@ -70,18 +80,60 @@ func mangaFromTable(table *lua.LTable, index uint16) (manga *source.Manga, err e
 			return nil
 		}},
 		"genres": {A: lua.LTString, B: false, C: func(v string) error {
-			manga.Metadata.Genres = lo.Map(strings.Split(v, ","), func(genre string, _ int) string {
-				return strings.TrimSpace(genre)
-			})
+			manga.Metadata.Genres = splitList(v)
 			return nil
 		}},
 	}
 
+	if api >= APIv2 {
+		mappings["id"] = mapping{A: lua.LTString, B: false, C: func(v string) error { manga.ID = v; return nil }}
+		mappings["tags"] = mapping{A: lua.LTString, B: false, C: func(v string) error {
+			if v != "" {
+				manga.Metadata.Tags = splitList(v)
+			}
+			return nil
+		}}
+		mappings["author"] = mapping{A: lua.LTString, B: false, C: func(v string) error {
+			if v != "" {
+				manga.Metadata.Staff.Story = splitList(v)
+			}
+			return nil
+		}}
+		mappings["artist"] = mapping{A: lua.LTString, B: false, C: func(v string) error {
+			if v != "" {
+				manga.Metadata.Staff.Art = splitList(v)
+			}
+			return nil
+		}}
+		mappings["title"] = mapping{A: lua.LTString, B: false, C: func(v string) error { manga.Metadata.Title = v; return nil }}
+		mappings["native_title"] = mapping{A: lua.LTString, B: false, C: func(v string) error { manga.Metadata.NativeTitle = v; return nil }}
+		mappings["alt_titles"] = mapping{A: lua.LTString, B: false, C: func(v string) error {
+			if v != "" {
+				manga.Metadata.Synonyms = splitList(v)
+			}
+			return nil
+		}}
+		mappings["status"] = mapping{A: lua.LTString, B: false, C: func(v string) error {
+			if v == "" {
+				return nil
+			}
+
+			status := strings.ReplaceAll(strings.ToUpper(v), " ", "_")
+			if !lo.Contains(statuses, status) {
+				return fmt.Errorf(`field of "status" must be one of %s`, strings.Join(statuses, ", "))
+			}
+
+			// same as the statuses from anilist
+			manga.Metadata.Status = strings.ReplaceAll(status, "_", " ")
+			return nil
+		}}
+	}
+
 	err = translate(table, mappings)
 	return
 }
 
-func chapterFromTable(table *lua.LTable, manga *source.Manga, index uint16) (chapter *source.Chapter, err error) {
+func chapterFromTable(table *lua.LTable, manga *source.Manga, index int, api int) (chapter *source.Chapter, err error) {
 	chapter = &source.Chapter{
 		Manga: manga,
 		Index: index,
@@ -92,11 +144,11 @@ func chapterFromTable(table *lua.LTable, manga *source.Manga, index uint16) (cha
 		"name":          {A: lua.LTString, B: true, C: func(v string) error { chapter.Name = v; return nil }},
 		"url":           {A: lua.LTString, B: true, C: func(v string) error { chapter.URL = v; return nil }},
 		"volume":        {A: lua.LTString, B: false, C: func(v string) error { chapter.Volume = v; return nil }},
+		"title":         {A: lua.LTString, B: false, C: func(v string) error { chapter.Title = v; return nil }},
+		"notes":         {A: lua.LTString, B: false, C: func(v string) error { chapter.Notes = v; return nil }},
 		"manga_summary": {A: lua.LTString, B: false, C: func(v string) error { manga.Metadata.Summary = v; return nil }},
 		"manga_genres": {A: lua.LTString, B: false, C: func(v string) error {
-			manga.Metadata.Genres = lo.Map(strings.Split(v, ","), func(genre string, _ int) string {
-				return strings.TrimSpace(genre)
-			})
+			manga.Metadata.Genres = splitList(v)
 			return nil
 		}},
 		"manga_cover": {A: lua.LTString, B: false, C: func(v string) error {
@@ -113,12 +165,45 @@ func chapterFromTable(table *lua.LTable, manga *source.Manga, index uint16) (cha
 		}},
 	}
 
+	if api >= APIv2 {
+		mappings["id"] = mapping{A: lua.LTString, B: false, C: func(v string) error { chapter.ID = v; return nil }}
+	}
+
 	err = translate(table, mappings)
 	manga.Chapters = append(manga.Chapters, chapter)
 	return
 }
 
-func pageFromTable(table *lua.LTable, chapter *source.Chapter) (page *source.Page, err error) {
+// textFromTable appends text block to the chapter contents, making it a text chapter.
+func textFromTable(table *lua.LTable, chapter *source.Chapter) error {
+	mappings := map[string]mapping{
+		"text": {A: lua.LTString, B: true, C: func(v string) error {
+			if chapter.Text != "" {
+				chapter.Text += "\n\n"
+			}
+
+			chapter.Text += v
+			return nil
+		}},
+		"format": {A: lua.LTString, B: false, D: constant.TextHTML, C: func(v string) error {
+			if v != constant.TextHTML && v != constant.TextMarkdown {
+				return fmt.Errorf(`field of "format" must be either "%s" or "%s"`, constant.TextHTML, constant.TextMarkdown)
+			}
+
+			chapter.TextFormat = v
+			return nil
+		}},
+	}
+
+	return translate(table, mappings)
+}
+
+func pageFromTable(table *lua.LTable, chapter *source.Chapter, api int) (page *source.Page, err error) {
+	// text chapters (e.g. light novels) return text blocks instead of pages
+	if table.RawGetString("text").Type() != lua.LTNil {
+		return nil, textFromTable(table, chapter)
+	}
+
 	page = &source.Page{
 		Chapter: chapter,
 	}
@@ -126,22 +211,36 @@ func pageFromTable(table *lua.LTable, chapter *source.Chapter) (page *source.Pag
 	mappings := map[string]mapping{
 		"url": {A: lua.LTString, B: true, C: func(v string) error { page.URL = v; return nil }},
 		"index": {A: lua.LTNumber, B: true, C: func(v string) error {
-			num, err := strconv.ParseUint(v, 10, 16)
+			num, err := source.ParseIndex(v)
 			if err != nil {
 				return err
 			}
 
-			page.Index = uint16(num)
+			page.Index = num
 			return nil
 		}},
 	}
 
+	// the links of the images don't always end with the extension
+	if api >= APIv2 {
+		mappings["extension"] = mapping{A: lua.LTString, B: false, C: func(v string) error {
+			if v != "" && !strings.HasPrefix(v, ".") {
+				v = "." + v
+			}
+
+			page.Extension = v
+			return nil
+		}}
+	}
+
 	err = translate(table, mappings)
 	if err != nil {
 		return
 	}
 
-	page.Extension = filepath.Ext(page.URL)
+	if page.Extension == "" {
+		page.Extension = filepath.Ext(page.URL)
+	}
 	chapter.Pages = append(chapter.Pages, page)
 	return
 }
@@ -1,39 +1,43 @@
 package generic
 
 import (
-	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
 	"net/http"
 )
 
 // ChaptersOf given source.Manga
 func (s *Scraper) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
-	if chapters, ok := s.chapters[manga.URL]; ok {
+	cacheKey := memo.MangaKey(s.ID(), manga)
+	if chapters, ok := s.chapters.Get(cacheKey); ok {
 		return chapters, nil
 	}
 
-	ctx := colly.NewContext()
-	ctx.Put("manga", manga)
-	err := s.chaptersCollector.Request(http.MethodGet, manga.URL, nil, ctx, nil)
+	state := &request{manga: manga}
+	collector := s.chaptersCollector()
+	err := collector.Request(http.MethodGet, manga.URL, nil, state.context(), nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	s.chaptersCollector.Wait()
+	collector.Wait()
 
-	if s.config.ReverseChapters {
-		// reverse chapters
-		chapters := s.chapters[manga.URL]
-		reversed := make([]*source.Chapter, len(chapters))
-		for i, chapter := range chapters {
-			reversed[len(chapters)-i-1] = chapter
-			chapter.Index = uint16(len(chapters) - i - 1)
-			chapter.Index++
-		}
+	parsed, err := state.outcome()
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing is cached if the request has failed
+	if !parsed {
+		return nil, nil
+	}
 
-		s.chapters[manga.URL] = reversed
+	// the same manga may be fetched concurrently, the first result wins
+	chapters, loaded := s.chapters.LoadOrStore(cacheKey, state.chapters)
+	if !loaded {
+		manga.Chapters = chapters
 	}
 
-	return s.chapters[manga.URL], nil
+	return chapters, nil
 }
@@ -2,6 +2,7 @@ package generic
 
 import (
 	"github.com/PuerkitoBio/goquery"
+	"strings"
 	"time"
 )
 
@@ -15,6 +16,10 @@ type Extractor struct {
 	URL func(*goquery.Selection) string
 	// Volume function to get volume from element found by selector. Used by chapters extractor
 	Volume func(*goquery.Selection) string
+	// Title function to get chapter title from element found by selector. Optional, used by chapters extractor
+	Title func(*goquery.Selection) string
+	// Notes function to get translator notes from element found by selector. Optional, used by chapters extractor
+	Notes func(*goquery.Selection) string
 	// Cover function to get cover from element found by selector. Used by manga extractor
 	Cover func(*goquery.Selection) string
 }
@@ -31,6 +36,10 @@ type Configuration struct {
 	// ReverseChapters if true, chapters will be shown in reverse order
 	ReverseChapters bool
 
+	// Language of the mangas provided by the source.
+	// Use constant.LanguageRaw for the raw (untranslated) sources
+	Language string
+
 	// BaseURL of the source
 	BaseURL string
 	// GenerateSearchURL function to create search URL from the query.
@@ -48,3 +57,14 @@ type Configuration struct {
 func (c *Configuration) ID() string {
 	return c.Name + " built-in"
 }
+
+// TitleFromName returns the title from the chapter name like "Chapter 12: The Title".
+// Empty string is returned if the name has no title
+func TitleFromName(name string) string {
+	_, title, found := strings.Cut(name, ":")
+	if !found {
+		return ""
+	}
+
+	return strings.TrimSpace(title)
+}
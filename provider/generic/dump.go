@@ -0,0 +1,58 @@
+package generic
+
+import (
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dumpPage saves the fetched page to the logs directory, if enabled by the config,
+// so that it can be attached to the bug reports about the broken sources.
+// The path of the dump is returned, empty if it is not saved
+func (s *Scraper) dumpPage(r *colly.Response, stage string) string {
+	if !viper.GetBool(key.LogsDumpPages) {
+		return ""
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s.html", util.SanitizeFilename(s.config.Name), stage, time.Now().Format("2006-01-02T15-04-05.000"))
+	path := filepath.Join(where.Logs(), filename)
+
+	if err := filesystem.Api().WriteFile(path, r.Body, os.ModePerm); err != nil {
+		log.Warn(err)
+		return ""
+	}
+
+	return path
+}
+
+// nothingMatched handles the page where the selector of the stage matched nothing.
+// The error is kept by the state of the request, with the path of the page if it is dumped.
+// Searches are not failed, since nothing may be found, but the dump is logged
+func (s *Scraper) nothingMatched(e *colly.HTMLElement, selector, stage string) {
+	path := s.dumpPage(e.Response, stage)
+
+	err := fmt.Errorf("%s: selector %q matched nothing on %s", s.config.Name, selector, e.Request.URL)
+	if path != "" {
+		err = fmt.Errorf("%w, the page is saved to %s", err, path)
+	} else {
+		err = fmt.Errorf("%w, set %s to save the page", err, key.LogsDumpPages)
+	}
+
+	if stage == "search" {
+		if path != "" {
+			log.Warn(err)
+		}
+
+		return
+	}
+
+	abortWith(e.Request, err)
+}
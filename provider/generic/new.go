@@ -4,33 +4,64 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/where"
 	"path/filepath"
 	"strings"
-	"time"
 )
 
 // New generates a new scraper with given configuration
 func New(conf *Configuration) source.Source {
-	s := Scraper{
-		mangas:   make(map[string][]*source.Manga),
-		chapters: make(map[string][]*source.Chapter),
-		pages:    make(map[string][]*source.Page),
+	s := &Scraper{
+		mangas:   memo.New[[]*source.Manga](),
+		chapters: memo.New[[]*source.Chapter](),
+		pages:    memo.New[[]*source.Page](),
 		config:   conf,
 	}
 
 	collectorOptions := []colly.CollectorOption{
 		colly.AllowURLRevisit(),
 		colly.Async(true),
-		colly.CacheDir(where.Cache()),
 	}
 
-	baseCollector := colly.NewCollector(collectorOptions...)
-	baseCollector.SetRequestTimeout(20 * time.Second)
+	// cached responses would be missing from the recorded sessions
+	if !network.InSession() {
+		collectorOptions = append(collectorOptions, colly.CacheDir(where.Cache()))
+	}
+
+	s.collectors = make(map[network.Stage]*colly.Collector)
+	for _, stage := range []network.Stage{network.StageSearch, network.StageChapters, network.StagePages} {
+		// each stage has its own backend, since the timeout is set on it
+		collector := colly.NewCollector(collectorOptions...)
+		collector.SetRequestTimeout(stage.Timeout())
+		collector.WithTransport(network.TransportFor(conf.Name))
+
+		// clones share the backend of the base collector, so the limit applies to all of them
+		_ = collector.Limit(&colly.LimitRule{
+			Parallelism: int(s.config.Parallelism),
+			RandomDelay: s.config.Delay,
+			DomainGlob:  "*",
+		})
+
+		s.collectors[stage] = collector
+	}
+
+	return s
+}
 
-	mangasCollector := baseCollector.Clone()
-	mangasCollector.OnRequest(func(r *colly.Request) {
+// mangasCollector returns a new collector for a single search.
+// Each call gets its own collector, so that waiting for one request
+// does not wait for (or race with) the requests of others
+func (s *Scraper) mangasCollector() *colly.Collector {
+	collector := s.collectors[network.StageSearch].Clone()
+	collector.OnError(keepError)
+	collector.OnRequest(abortCanceled)
+	collector.OnResponseHeaders(func(r *colly.Response) {
+		abortCanceled(r.Request)
+	})
+	collector.OnRequest(func(r *colly.Request) {
 		r.Headers.Set("Referer", "https://google.com")
 		r.Headers.Set("accept-language", "en-US")
 		r.Headers.Set("Accept", "text/html")
@@ -39,10 +70,12 @@ func New(conf *Configuration) source.Source {
 	})
 
 	// Get mangas
-	mangasCollector.OnHTML("html", func(e *colly.HTMLElement) {
+	collector.OnHTML("html", func(e *colly.HTMLElement) {
 		elements := e.DOM.Find(s.config.MangaExtractor.Selector)
-		path := e.Request.URL.String()
-		s.mangas[path] = make([]*source.Manga, elements.Length())
+		if elements.Length() == 0 {
+			s.nothingMatched(e, s.config.MangaExtractor.Selector, "search")
+		}
+		mangas := make([]*source.Manga, elements.Length())
 
 		elements.Each(func(i int, selection *goquery.Selection) {
 			link := s.config.MangaExtractor.URL(selection)
@@ -50,26 +83,36 @@ func New(conf *Configuration) source.Source {
 			manga := source.Manga{
 				Name:     s.config.MangaExtractor.Name(selection),
 				URL:      url,
-				Index:    uint16(e.Index),
+				Index:    i,
 				Chapters: make([]*source.Chapter, 0),
 				ID:       filepath.Base(url),
-				Source:   &s,
+				Source:   s,
 			}
 			manga.Metadata.Cover.ExtraLarge = s.config.MangaExtractor.Cover(selection)
+			manga.Metadata.Language = s.config.Language
 
-			s.mangas[path][i] = &manga
+			mangas[i] = &manga
 		})
-	})
 
-	_ = mangasCollector.Limit(&colly.LimitRule{
-		Parallelism: int(s.config.Parallelism),
-		RandomDelay: s.config.Delay,
-		DomainGlob:  "*",
+		keepResult(e.Request, func(state *request) {
+			state.mangas = mangas
+		})
 	})
 
-	chaptersCollector := baseCollector.Clone()
-	chaptersCollector.OnRequest(func(r *colly.Request) {
-		r.Headers.Set("Referer", r.Ctx.GetAny("manga").(*source.Manga).URL)
+	return collector
+}
+
+// chaptersCollector returns a new collector for a single manga
+func (s *Scraper) chaptersCollector() *colly.Collector {
+	collector := s.collectors[network.StageChapters].Clone()
+	collector.OnError(keepError)
+	collector.OnRequest(func(r *colly.Request) {
+		manga, ok := mangaOf(r)
+		if !ok {
+			return
+		}
+
+		r.Headers.Set("Referer", manga.URL)
 		r.Headers.Set("accept-language", "en-US")
 		r.Headers.Set("Accept", "text/html")
 		r.Headers.Set("Host", s.config.BaseURL)
@@ -77,11 +120,17 @@ func New(conf *Configuration) source.Source {
 	})
 
 	// Get chapters
-	chaptersCollector.OnHTML("html", func(e *colly.HTMLElement) {
+	collector.OnHTML("html", func(e *colly.HTMLElement) {
 		elements := e.DOM.Find(s.config.ChapterExtractor.Selector)
-		path := e.Request.AbsoluteURL(e.Request.URL.Path)
-		s.chapters[path] = make([]*source.Chapter, elements.Length())
-		manga := e.Request.Ctx.GetAny("manga").(*source.Manga)
+		if elements.Length() == 0 {
+			s.nothingMatched(e, s.config.ChapterExtractor.Selector, "chapters")
+		}
+		manga, ok := mangaOf(e.Request)
+		if !ok {
+			return
+		}
+
+		chapters := make([]*source.Chapter, elements.Length())
 
 		elements.Each(func(i int, selection *goquery.Selection) {
 			link := s.config.ChapterExtractor.URL(selection)
@@ -90,36 +139,69 @@ func New(conf *Configuration) source.Source {
 			chapter := source.Chapter{
 				Name:   s.config.ChapterExtractor.Name(selection),
 				URL:    url,
-				Index:  uint16(e.Index),
+				Index:  i + 1,
 				Pages:  make([]*source.Page, 0),
 				ID:     filepath.Base(url),
 				Manga:  manga,
 				Volume: s.config.ChapterExtractor.Volume(selection),
 			}
-			s.chapters[path][i] = &chapter
+
+			if extract := s.config.ChapterExtractor.Title; extract != nil {
+				chapter.Title = strings.TrimSpace(extract(selection))
+			}
+
+			if extract := s.config.ChapterExtractor.Notes; extract != nil {
+				chapter.Notes = strings.TrimSpace(extract(selection))
+			}
+			chapters[i] = &chapter
+		})
+
+		if s.config.ReverseChapters {
+			reversed := make([]*source.Chapter, len(chapters))
+			for i, chapter := range chapters {
+				reversed[len(chapters)-i-1] = chapter
+			}
+
+			chapters = reversed
+			source.Reindex(chapters)
+		}
+
+		keepResult(e.Request, func(state *request) {
+			state.chapters = chapters
 		})
-		manga.Chapters = s.chapters[path]
-	})
-	_ = chaptersCollector.Limit(&colly.LimitRule{
-		Parallelism: int(s.config.Parallelism),
-		RandomDelay: s.config.Delay,
-		DomainGlob:  "*",
 	})
 
-	pagesCollector := baseCollector.Clone()
-	pagesCollector.OnRequest(func(r *colly.Request) {
-		r.Headers.Set("Referer", r.Ctx.GetAny("chapter").(*source.Chapter).URL)
+	return collector
+}
+
+// pagesCollector returns a new collector for a single chapter
+func (s *Scraper) pagesCollector() *colly.Collector {
+	collector := s.collectors[network.StagePages].Clone()
+	collector.OnError(keepError)
+	collector.OnRequest(func(r *colly.Request) {
+		chapter, ok := chapterOf(r)
+		if !ok {
+			return
+		}
+
+		r.Headers.Set("Referer", chapter.URL)
 		r.Headers.Set("accept-language", "en-US")
 		r.Headers.Set("Accept", "text/html")
 		r.Headers.Set("User-Agent", constant.UserAgent)
 	})
 
 	// Get pages
-	pagesCollector.OnHTML("html", func(e *colly.HTMLElement) {
+	collector.OnHTML("html", func(e *colly.HTMLElement) {
 		elements := e.DOM.Find(s.config.PageExtractor.Selector)
-		path := e.Request.AbsoluteURL(e.Request.URL.Path)
-		s.pages[path] = make([]*source.Page, elements.Length())
-		chapter := e.Request.Ctx.GetAny("chapter").(*source.Chapter)
+		if elements.Length() == 0 {
+			s.nothingMatched(e, s.config.PageExtractor.Selector, "pages")
+		}
+		chapter, ok := chapterOf(e.Request)
+		if !ok {
+			return
+		}
+
+		pages := make([]*source.Page, elements.Length())
 
 		elements.Each(func(i int, selection *goquery.Selection) {
 			link := s.config.PageExtractor.URL(selection)
@@ -129,23 +211,17 @@ func New(conf *Configuration) source.Source {
 
 			page := source.Page{
 				URL:       link,
-				Index:     uint16(i),
+				Index:     i,
 				Chapter:   chapter,
 				Extension: ext,
 			}
-			s.pages[path][i] = &page
+			pages[i] = &page
 		})
-		chapter.Pages = s.pages[path]
-	})
-	_ = pagesCollector.Limit(&colly.LimitRule{
-		Parallelism: int(s.config.Parallelism),
-		RandomDelay: s.config.Delay,
-		DomainGlob:  "*",
-	})
 
-	s.mangasCollector = mangasCollector
-	s.chaptersCollector = chaptersCollector
-	s.pagesCollector = pagesCollector
+		keepResult(e.Request, func(state *request) {
+			state.pages = pages
+		})
+	})
 
-	return &s
+	return collector
 }
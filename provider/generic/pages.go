@@ -1,26 +1,43 @@
 package generic
 
 import (
-	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
 	"net/http"
 )
 
 // PagesOf given source.Chapter
 func (s *Scraper) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
-	if pages, ok := s.pages[chapter.URL]; ok {
+	cacheKey := memo.ChapterKey(s.ID(), chapter)
+	if pages, ok := s.pages.Get(cacheKey); ok {
 		return pages, nil
 	}
 
-	ctx := colly.NewContext()
-	ctx.Put("chapter", chapter)
-	err := s.pagesCollector.Request(http.MethodGet, chapter.URL, nil, ctx, nil)
+	state := &request{chapter: chapter}
+	collector := s.pagesCollector()
+	err := collector.Request(http.MethodGet, chapter.URL, nil, state.context(), nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	s.pagesCollector.Wait()
+	collector.Wait()
 
-	return s.pages[chapter.URL], nil
+	parsed, err := state.outcome()
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing is cached if the request has failed
+	if !parsed {
+		return nil, nil
+	}
+
+	// the same chapter may be fetched concurrently, the first result wins
+	pages, loaded := s.pages.LoadOrStore(cacheKey, state.pages)
+	if !loaded {
+		chapter.Pages = pages
+	}
+
+	return pages, nil
 }
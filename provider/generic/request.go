@@ -0,0 +1,139 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"sync"
+)
+
+// requestKey is the key of the colly context with the state of the request.
+// Nothing else is put to the context, so that the callbacks don't need the type assertions
+const requestKey = "request"
+
+// errNoState is returned for the requests made without the state, e.g. by colly itself
+var errNoState = errors.New("request has no state")
+
+// request is the state of a single request of the scraper, carried by its colly context.
+// Retries and redirects of the request share its context, so they see the same state.
+// Results are passed through it, rather than shared by the URL,
+// so that concurrent requests can't see or overwrite each other's results
+type request struct {
+	// ctx of the search, the requests of the canceled searches are aborted
+	ctx context.Context
+	// manga the chapters are requested for
+	manga *source.Manga
+	// chapter the pages are requested for
+	chapter *source.Chapter
+
+	mutex    sync.Mutex
+	mangas   []*source.Manga
+	chapters []*source.Chapter
+	pages    []*source.Page
+	// parsed is true once the results are kept, they may be empty
+	parsed bool
+	err    error
+}
+
+// context returns the colly context carrying the state
+func (r *request) context() *colly.Context {
+	ctx := colly.NewContext()
+	ctx.Put(requestKey, r)
+	return ctx
+}
+
+// outcome returns whether the results were parsed and the error of the request, if it has failed.
+// The results are read after the collector is waited for
+func (r *request) outcome() (parsed bool, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.parsed, r.err
+}
+
+// fail keeps the first error of the request
+func (r *request) fail(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// requestOf returns the state carried by the colly context
+func requestOf(ctx *colly.Context) (*request, error) {
+	if ctx == nil {
+		return nil, errNoState
+	}
+
+	state, ok := ctx.GetAny(requestKey).(*request)
+	if !ok {
+		return nil, errNoState
+	}
+
+	return state, nil
+}
+
+// abortWith aborts the request because of the error.
+// The error is kept by the state, or logged if the request has none
+func abortWith(r *colly.Request, err error) {
+	r.Abort()
+
+	if state, stateErr := requestOf(r.Ctx); stateErr == nil {
+		state.fail(err)
+		return
+	}
+
+	log.Warn(fmt.Errorf("%s: %w", r.URL, err))
+}
+
+// mangaOf returns the manga the request is made for.
+// If it has none, the request is aborted and false is returned
+func mangaOf(r *colly.Request) (*source.Manga, bool) {
+	state, err := requestOf(r.Ctx)
+	if err == nil && state.manga == nil {
+		err = errors.New("request has no manga")
+	}
+
+	if err != nil {
+		abortWith(r, err)
+		return nil, false
+	}
+
+	return state.manga, true
+}
+
+// chapterOf returns the chapter the request is made for.
+// If it has none, the request is aborted and false is returned
+func chapterOf(r *colly.Request) (*source.Chapter, bool) {
+	state, err := requestOf(r.Ctx)
+	if err == nil && state.chapter == nil {
+		err = errors.New("request has no chapter")
+	}
+
+	if err != nil {
+		abortWith(r, err)
+		return nil, false
+	}
+
+	return state.chapter, true
+}
+
+// keepResult puts the parsed results to the state of the request
+func keepResult(r *colly.Request, keep func(state *request)) {
+	state, err := requestOf(r.Ctx)
+	if err != nil {
+		log.Warn(fmt.Errorf("%s: %w", r.URL, err))
+		return
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	keep(state)
+	state.parsed = true
+}
@@ -1,19 +1,40 @@
 package generic
 
 import (
+	"errors"
 	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
 )
 
+// keepError keeps the error of the request that has failed without any response, e.g. because of the timeout.
+// HTTP errors are not kept, since some sites respond with them when nothing is found
+func keepError(r *colly.Response, err error) {
+	if r.StatusCode != 0 || errors.Is(err, colly.ErrAbortedAfterHeaders) {
+		return
+	}
+
+	if state, stateErr := requestOf(r.Request.Ctx); stateErr == nil {
+		state.fail(err)
+	}
+}
+
+// abortCanceled aborts the request if its search was canceled
+func abortCanceled(r *colly.Request) {
+	if state, err := requestOf(r.Ctx); err == nil && state.ctx != nil && state.ctx.Err() != nil {
+		r.Abort()
+	}
+}
+
 // Scraper is a generic scraper downloads html pages and parses them
 type Scraper struct {
-	mangasCollector   *colly.Collector
-	chaptersCollector *colly.Collector
-	pagesCollector    *colly.Collector
+	// collectors are the bases the collectors of each request are cloned from, by their stages
+	collectors map[network.Stage]*colly.Collector
 
-	mangas   map[string][]*source.Manga
-	chapters map[string][]*source.Chapter
-	pages    map[string][]*source.Page
+	mangas   *memo.Memo[[]*source.Manga]
+	chapters *memo.Memo[[]*source.Chapter]
+	pages    *memo.Memo[[]*source.Page]
 
 	config *Configuration
 }
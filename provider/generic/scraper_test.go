@@ -0,0 +1,358 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer serves a site where every search returns a single manga named after the query,
+// every manga has 3 chapters and every chapter has 2 pages, except for the blank one
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		if query == "slow" {
+			time.Sleep(time.Second)
+		}
+
+		_, _ = fmt.Fprintf(w, `<html><body><a class="manga" href="/manga/%s">%s</a></body></html>`, url.PathEscape(query), query)
+	})
+	mux.HandleFunc("/manga/", func(w http.ResponseWriter, r *http.Request) {
+		var links strings.Builder
+		for i := 1; i <= 3; i++ {
+			_, _ = fmt.Fprintf(&links, `<a class="chapter" href="%s/%d">Chapter %d</a>`, r.URL.Path, i, i)
+		}
+
+		_, _ = fmt.Fprintf(w, `<html><body>%s</body></html>`, links.String())
+	})
+	mux.HandleFunc("/moved/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(r.URL.Path, "/moved/", "/manga/", 1), http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/blank", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body>Moved to another domain</body></html>`)
+	})
+	mux.HandleFunc("/chapter/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<html><body><img src="%[1]s/1.png"><img src="%[1]s/2.png"></body></html>`, r.URL.Path)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func newTestScraper(server *httptest.Server) source.Source {
+	text := func(selection *goquery.Selection) string {
+		return selection.Text()
+	}
+	href := func(selection *goquery.Selection) string {
+		return selection.AttrOr("href", "")
+	}
+
+	return New(&Configuration{
+		Name:            "test",
+		Parallelism:     10,
+		ReverseChapters: true,
+		BaseURL:         server.URL,
+		GenerateSearchURL: func(query string) string {
+			return server.URL + "/search?q=" + url.QueryEscape(query)
+		},
+		MangaExtractor: &Extractor{
+			Selector: "a.manga",
+			Name:     text,
+			URL:      href,
+			Cover:    text,
+		},
+		ChapterExtractor: &Extractor{
+			Selector: "a.chapter",
+			Name:     text,
+			URL: func(selection *goquery.Selection) string {
+				return strings.Replace(href(selection), "/manga/", "/chapter/", 1)
+			},
+			Volume: func(*goquery.Selection) string {
+				return ""
+			},
+		},
+		PageExtractor: &Extractor{
+			Selector: "img",
+			URL: func(selection *goquery.Selection) string {
+				return selection.AttrOr("src", "")
+			},
+		},
+	})
+}
+
+func TestScraper(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	Convey("Given a scraper of the test site", t, func() {
+		server := newTestServer()
+		defer server.Close()
+
+		scraper := newTestScraper(server)
+
+		Convey("When searching, getting chapters and pages concurrently", func() {
+			const workers = 20
+
+			var (
+				wg       sync.WaitGroup
+				mutex    sync.Mutex
+				mangas   = make(map[string]*source.Manga)
+				chapters = make(map[string][]*source.Chapter)
+				pages    = make(map[string][]*source.Page)
+				errs     []error
+			)
+
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(query string) {
+					defer wg.Done()
+
+					found, err := scraper.Search(query)
+					if err != nil || len(found) != 1 {
+						mutex.Lock()
+						errs = append(errs, fmt.Errorf("search %s: %v, found %d", query, err, len(found)))
+						mutex.Unlock()
+						return
+					}
+
+					manga := found[0]
+					mangaChapters, err := scraper.ChaptersOf(manga)
+					if err != nil || len(mangaChapters) == 0 {
+						mutex.Lock()
+						errs = append(errs, fmt.Errorf("chapters of %s: %v", query, err))
+						mutex.Unlock()
+						return
+					}
+
+					chapterPages, err := scraper.PagesOf(mangaChapters[0])
+
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					if err != nil {
+						errs = append(errs, err)
+						return
+					}
+
+					mangas[query] = manga
+					chapters[query] = mangaChapters
+					pages[query] = chapterPages
+				}(fmt.Sprintf("manga %d", i%(workers/2)))
+			}
+
+			wg.Wait()
+
+			Convey("Then every caller should get its own results", func() {
+				So(errs, ShouldBeEmpty)
+				So(len(mangas), ShouldEqual, workers/2)
+
+				for query, manga := range mangas {
+					So(manga.Name, ShouldEqual, query)
+					So(len(chapters[query]), ShouldEqual, 3)
+					So(len(pages[query]), ShouldEqual, 2)
+					So(pages[query][0].URL, ShouldContainSubstring, "/chapter/"+query+"/")
+				}
+			})
+
+			Convey("Then chapters should be reversed only once", func() {
+				for query := range mangas {
+					So(chapters[query][0].Name, ShouldEqual, "Chapter 3")
+					So(chapters[query][0].Index, ShouldEqual, 1)
+					So(chapters[query][2].Index, ShouldEqual, 3)
+				}
+			})
+		})
+	})
+}
+
+func TestScraperCancel(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	Convey("Given a scraper of the test site", t, func() {
+		server := newTestServer()
+		defer server.Close()
+
+		scraper := newTestScraper(server).(source.ContextSearcher)
+
+		Convey("When a slow search is canceled", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			started := time.Now()
+			mangas, err := scraper.SearchContext(ctx, "slow")
+
+			Convey("Then it should return without waiting for the response", func() {
+				So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+				So(mangas, ShouldBeNil)
+				So(time.Since(started), ShouldBeLessThan, 500*time.Millisecond)
+			})
+		})
+
+		Convey("When a search is canceled before it starts", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := scraper.SearchContext(ctx, "manga")
+
+			Convey("Then nothing should be cached", func() {
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+
+				mangas, err := scraper.SearchContext(context.Background(), "manga")
+				So(err, ShouldBeNil)
+				So(len(mangas), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestScraperRequestState(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	Convey("Given a scraper of the test site", t, func() {
+		server := newTestServer()
+		defer server.Close()
+
+		scraper := newTestScraper(server)
+
+		Convey("When getting the chapters of the manga that is redirected", func() {
+			manga := &source.Manga{Name: "moved", URL: server.URL + "/moved/moved"}
+			chapters, err := scraper.ChaptersOf(manga)
+
+			Convey("Then the chapters should belong to the manga", func() {
+				So(err, ShouldBeNil)
+				So(len(chapters), ShouldEqual, 3)
+				So(chapters[0].Manga, ShouldEqual, manga)
+			})
+		})
+
+		Convey("When the request is made without the state", func() {
+			collector := scraper.(*Scraper).chaptersCollector()
+
+			Convey("Then it should be aborted instead of panicking", func() {
+				So(func() {
+					_ = collector.Request(http.MethodGet, server.URL+"/manga/missing", nil, colly.NewContext(), nil)
+					collector.Wait()
+				}, ShouldNotPanic)
+			})
+		})
+
+		Convey("When the request is made without the manga", func() {
+			state := &request{}
+			collector := scraper.(*Scraper).chaptersCollector()
+			_ = collector.Request(http.MethodGet, server.URL+"/manga/missing", nil, state.context(), nil)
+			collector.Wait()
+
+			Convey("Then the error should be kept", func() {
+				parsed, err := state.outcome()
+				So(parsed, ShouldBeFalse)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "no manga")
+			})
+		})
+	})
+}
+
+func TestScraperTimeout(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	Convey("Given a scraper with a short search timeout", t, func() {
+		viper.Set(key.NetworkTimeoutSearch, "100ms")
+		defer viper.Set(key.NetworkTimeoutSearch, nil)
+
+		server := newTestServer()
+		defer server.Close()
+
+		scraper := newTestScraper(server)
+
+		Convey("When the search takes longer", func() {
+			started := time.Now()
+			mangas, err := scraper.Search("slow")
+
+			Convey("Then it should fail once the timeout has passed", func() {
+				So(err, ShouldNotBeNil)
+				So(mangas, ShouldBeNil)
+				So(time.Since(started), ShouldBeLessThan, 900*time.Millisecond)
+			})
+		})
+
+		Convey("When the search is fast enough", func() {
+			mangas, err := scraper.Search("manga")
+
+			Convey("Then it should succeed", func() {
+				So(err, ShouldBeNil)
+				So(len(mangas), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestScraperDump(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	Convey("Given a scraper that dumps the pages", t, func() {
+		t.Setenv(where.EnvConfigPath, t.TempDir())
+		viper.Set(key.LogsDumpPages, true)
+		defer viper.Set(key.LogsDumpPages, false)
+
+		server := newTestServer()
+		defer server.Close()
+
+		scraper := newTestScraper(server)
+
+		Convey("When the selector matches nothing on the page", func() {
+			pages, err := scraper.PagesOf(&source.Chapter{URL: server.URL + "/blank"})
+
+			Convey("Then the error should refer to the saved page", func() {
+				So(pages, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+
+				dumps, _ := filepath.Glob(filepath.Join(where.Logs(), "test-pages-*.html"))
+				So(dumps, ShouldHaveLength, 1)
+				So(err.Error(), ShouldContainSubstring, dumps[0])
+
+				contents, _ := os.ReadFile(dumps[0])
+				So(string(contents), ShouldContainSubstring, "Moved to another domain")
+			})
+		})
+
+		Convey("When the selector matches nothing on the page without the dumps", func() {
+			viper.Set(key.LogsDumpPages, false)
+			pages, err := scraper.PagesOf(&source.Chapter{URL: server.URL + "/blank"})
+
+			Convey("Then it should fail the same, with the key to save the page", func() {
+				So(pages, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, key.LogsDumpPages)
+
+				dumps, _ := filepath.Glob(filepath.Join(where.Logs(), "*.html"))
+				So(dumps, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the selector matches", func() {
+			_, err := scraper.PagesOf(&source.Chapter{URL: server.URL + "/chapter/1"})
+
+			Convey("Then nothing should be saved", func() {
+				So(err, ShouldBeNil)
+
+				dumps, _ := filepath.Glob(filepath.Join(where.Logs(), "*.html"))
+				So(dumps, ShouldBeEmpty)
+			})
+		})
+	})
+}
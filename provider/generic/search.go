@@ -1,23 +1,59 @@
 package generic
 
 import (
+	"context"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
+	"net/http"
 )
 
 // Search for mangas by given title
 func (s *Scraper) Search(query string) ([]*source.Manga, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchContext searches for mangas by given title until the context is canceled
+func (s *Scraper) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
 	address := s.config.GenerateSearchURL(query)
 
-	if urls, ok := s.mangas[address]; ok {
-		return urls, nil
+	cacheKey := memo.SearchKey(s.ID(), query)
+	if mangas, ok := s.mangas.Get(cacheKey); ok {
+		return mangas, nil
 	}
 
-	err := s.mangasCollector.Visit(address)
+	state := &request{ctx: ctx}
+	collector := s.mangasCollector()
+	err := collector.Request(http.MethodGet, address, nil, state.context(), nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	s.mangasCollector.Wait()
-	return s.mangas[address], nil
+	done := make(chan struct{})
+	go func() {
+		collector.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-done:
+		// the request could have been aborted before the select noticed the cancellation
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	parsed, err := state.outcome()
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing is cached if the request has failed
+	if parsed {
+		s.mangas.Set(cacheKey, state.mangas)
+	}
+
+	return state.mangas, nil
 }
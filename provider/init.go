@@ -1,36 +1,91 @@
 package provider
 
 import (
+	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/provider/generic"
+	"github.com/metafates/mangal/provider/lezhin"
 	"github.com/metafates/mangal/provider/mangadex"
 	"github.com/metafates/mangal/provider/manganato"
 	"github.com/metafates/mangal/provider/manganelo"
 	"github.com/metafates/mangal/provider/mangapill"
+	"github.com/metafates/mangal/provider/mock"
+	"github.com/metafates/mangal/provider/rawkuma"
+	"github.com/metafates/mangal/provider/tapas"
+	"github.com/metafates/mangal/provider/webtoons"
 	"github.com/metafates/mangal/source"
 )
 
 const CustomProviderExtension = ".lua"
 
-var builtinProviders = []*Provider{
-	{
-		ID:   mangadex.ID,
-		Name: mangadex.Name,
+func init() {
+	Register(&Provider{
+		ID:      mangadex.ID,
+		Name:    mangadex.Name,
+		BaseURL: mangadex.BaseURL,
+		Capabilities: Capabilities{
+			LanguageKey: key.MangadexLanguage,
+		},
 		CreateSource: func() (source.Source, error) {
 			return mangadex.New(), nil
 		},
-	},
-}
+	})
+
+	Register(&Provider{
+		ID:      webtoons.ID,
+		Name:    webtoons.Name,
+		BaseURL: webtoons.BaseURL,
+		CreateSource: func() (source.Source, error) {
+			return webtoons.New(), nil
+		},
+	})
+
+	Register(&Provider{
+		ID:      tapas.ID,
+		Name:    tapas.Name,
+		BaseURL: tapas.BaseURL,
+		Capabilities: Capabilities{
+			AuthKeys: []string{key.TapasEmail, key.TapasPassword},
+		},
+		CreateSource: func() (source.Source, error) {
+			return tapas.New(), nil
+		},
+	})
+
+	Register(&Provider{
+		ID:      lezhin.ID,
+		Name:    lezhin.Name,
+		BaseURL: lezhin.BaseURL,
+		Capabilities: Capabilities{
+			LanguageKey: key.LezhinLanguage,
+			Languages:   lezhin.Languages,
+			AuthKeys:    []string{key.LezhinEmail, key.LezhinPassword},
+		},
+		CreateSource: func() (source.Source, error) {
+			return lezhin.New(), nil
+		},
+	})
+
+	Register(&Provider{
+		ID:        mock.ID,
+		Name:      mock.Name,
+		BaseURL:   mock.BaseURL,
+		EnableKey: key.MockEnable,
+		CreateSource: func() (source.Source, error) {
+			return mock.New(), nil
+		},
+	})
 
-func init() {
 	for _, conf := range []*generic.Configuration{
 		manganelo.Config,
 		manganato.Config,
 		mangapill.Config,
+		rawkuma.Config,
 	} {
 		conf := conf
-		builtinProviders = append(builtinProviders, &Provider{
-			ID:   conf.ID(),
-			Name: conf.Name,
+		Register(&Provider{
+			ID:      conf.ID(),
+			Name:    conf.Name,
+			BaseURL: conf.BaseURL,
 			CreateSource: func() (source.Source, error) {
 				return generic.New(conf), nil
 			},
@@ -0,0 +1,83 @@
+package lezhin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"io"
+)
+
+var productMarker = []byte("__LZ_PRODUCT__ = ")
+
+type product struct {
+	All []struct {
+		Name    string `json:"name"`
+		Display struct {
+			Title       string `json:"title"`
+			DisplayName string `json:"displayName"`
+		} `json:"display"`
+	} `json:"all"`
+}
+
+// ChaptersOf given source.Manga.
+// Paid episodes are listed as well, downloading them will fail unless they are purchased.
+func (l *Lezhin) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	cacheKey := memo.MangaKey(l.ID(), manga, language())
+	if chapters, ok := l.chapters.Get(cacheKey); ok {
+		return chapters, nil
+	}
+
+	body, err := l.get(context.Background(), manga.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(body.Close)
+
+	page, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// episodes are embedded into the page as a js object literal
+	start := bytes.Index(page, productMarker)
+	if start == -1 {
+		return nil, errors.New("episode list not found")
+	}
+
+	var p product
+	if err = json.NewDecoder(bytes.NewReader(page[start+len(productMarker):])).Decode(&p); err != nil {
+		return nil, err
+	}
+
+	manga.Metadata.LongStrip = true
+
+	// episodes go from the newest to the oldest
+	chapters := make([]*source.Chapter, len(p.All))
+	for i, episode := range p.All {
+		index := len(p.All) - i
+		name := episode.Display.DisplayName
+		if episode.Display.Title != "" {
+			name = fmt.Sprintf("%s - %s", name, episode.Display.Title)
+		}
+
+		chapters[index-1] = &source.Chapter{
+			Name:  name,
+			URL:   fmt.Sprintf("%s/%s/comic/%s/%s", BaseURL, language(), manga.ID, episode.Name),
+			Index: index,
+			ID:    episode.Name,
+			Title: episode.Display.Title,
+			Manga: manga,
+			Pages: make([]*source.Page, 0),
+		}
+	}
+
+	manga.Chapters = chapters
+	l.chapters.Set(cacheKey, chapters)
+	return chapters, nil
+}
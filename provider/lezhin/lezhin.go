@@ -0,0 +1,183 @@
+package lezhin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/secrets"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	Name = "Lezhin"
+	ID   = Name + " built-in"
+)
+
+const (
+	BaseURL = "https://www.lezhin.com"
+	cdnURL  = "https://rcdn.lezhin.com"
+)
+
+// Languages of the Lezhin stores
+var Languages = []string{"en", "ko", "ja"}
+
+var tokenRegex = regexp.MustCompile(`token['"]?\s*:\s*['"]([\w-]+)['"]`)
+
+type Lezhin struct {
+	client *http.Client
+	// mutex guards the token, so that concurrent requests log in only once
+	mutex    sync.Mutex
+	token    string
+	mangas   *memo.Memo[[]*source.Manga]
+	chapters *memo.Memo[[]*source.Chapter]
+}
+
+func (*Lezhin) Name() string {
+	return Name
+}
+
+func (*Lezhin) ID() string {
+	return ID
+}
+
+func New() *Lezhin {
+	// cookiejar.New never returns an error with nil options
+	jar, _ := cookiejar.New(nil)
+
+	return &Lezhin{
+		client: &http.Client{
+			Timeout:   network.Client.Timeout,
+			Transport: network.TransportFor(Name),
+			Jar:       jar,
+		},
+		mangas:   memo.New[[]*source.Manga](),
+		chapters: memo.New[[]*source.Chapter](),
+	}
+}
+
+func language() string {
+	return viper.GetString(key.LezhinLanguage)
+}
+
+// password returns the password of the account.
+// The one in the config is used if it is not stored, it was kept there by the older versions
+func password() string {
+	if password, err := secrets.Get(secrets.LezhinPassword); err == nil && password != "" {
+		return password
+	}
+
+	return viper.GetString(key.LezhinPassword)
+}
+
+// login signs in with the credentials from the config and obtains the access token for the viewer api.
+// Without the token comics can be browsed, but not downloaded.
+func (l *Lezhin) login(ctx context.Context) (string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.token != "" {
+		return l.token, nil
+	}
+
+	email, password := viper.GetString(key.LezhinEmail), password()
+	if email == "" || password == "" {
+		return "", nil
+	}
+
+	log.Info("Logging in to " + Name)
+
+	form := url.Values{}
+	form.Set("redirect", "/"+language())
+	form.Set("username", email)
+	form.Set("password", password)
+	form.Set("remember_me", "false")
+
+	address := fmt.Sprintf("%s/%s/login/submit", BaseURL, language())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", BaseURL)
+	req.Header.Set("User-Agent", constant.UserAgent)
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer util.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("http error: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// the token is embedded in the page that login redirects to
+	match := tokenRegex.FindSubmatch(body)
+	if match == nil {
+		return "", errors.New("lezhin login failed: check your email and password")
+	}
+
+	l.token = string(match[1])
+	return l.token, nil
+}
+
+// get performs an authenticated GET request and returns the response body.
+// Caller must close it.
+func (l *Lezhin) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	token, err := l.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Referer", BaseURL)
+	req.Header.Set("User-Agent", constant.UserAgent)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("X-LZ-Locale", language())
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		util.Ignore(resp.Body.Close)
+
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+			return nil, errLocked
+		}
+
+		return nil, fmt.Errorf("http error: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+var errLocked = errors.New("episode is locked, purchase it on lezhin first")
@@ -0,0 +1,29 @@
+package lezhin
+
+import (
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider/providertest"
+	"github.com/metafates/mangal/secrets"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestLezhinFixtures(t *testing.T) {
+	// episodes are read with the account only
+	t.Setenv(secrets.EnvPrefix+"LEZHIN_PASSWORD", "hunter2")
+
+	Convey("Given the recorded responses of lezhin", t, func() {
+		viper.Set(key.LezhinEmail, "reader@example.com")
+		viper.Set(key.LezhinLanguage, "en")
+		defer viper.Set(key.LezhinEmail, nil)
+		defer viper.Set(key.LezhinLanguage, nil)
+
+		Convey("Then the mangas, the chapters and the pages should be extracted as before", func() {
+			So(providertest.Check("testdata", "Killing Stalking", func() source.Source {
+				return New()
+			}), ShouldBeNil)
+		})
+	})
+}
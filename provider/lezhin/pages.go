@@ -0,0 +1,112 @@
+package lezhin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/secrets"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"net/http"
+	"net/url"
+)
+
+type viewerResponse struct {
+	Data struct {
+		Extra struct {
+			Comic struct {
+				Metadata struct {
+					ImageShuffle bool `json:"imageShuffle"`
+				} `json:"metadata"`
+			} `json:"comic"`
+			Episode struct {
+				UpdatedAt   int64 `json:"updatedAt"`
+				ScrollsInfo []struct {
+					Path string `json:"path"`
+				} `json:"scrollsInfo"`
+				PagesInfo []struct {
+					Path string `json:"path"`
+				} `json:"pagesInfo"`
+			} `json:"episode"`
+		} `json:"extra"`
+	} `json:"data"`
+}
+
+// PagesOf given source.Chapter.
+// Only free and purchased episodes can be downloaded.
+// Episodes served as shuffled (scrambled) images are DRM protected and are not supported.
+func (l *Lezhin) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	ctx := context.Background()
+	token, err := l.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("lezhin requires an account to download episodes, set %s and the %s secret", key.LezhinEmail, secrets.LezhinPassword)
+	}
+
+	query := url.Values{}
+	query.Set("platform", "web")
+	query.Set("store", "web")
+	query.Set("alias", chapter.Manga.ID)
+	query.Set("name", chapter.ID)
+	query.Set("preload", "false")
+	query.Set("type", "comic_episode")
+
+	body, err := l.get(ctx, BaseURL+"/lz-api/v2/inventory_groups/comic_viewer_k?"+query.Encode())
+	if err != nil {
+		if errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("%q: %w", chapter.Name, err)
+		}
+
+		return nil, err
+	}
+
+	defer util.Ignore(body.Close)
+
+	var response viewerResponse
+	if err = json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	extra := response.Data.Extra
+	if extra.Comic.Metadata.ImageShuffle {
+		return nil, errors.New("scrambled episodes are DRM protected and not supported")
+	}
+
+	infos := extra.Episode.ScrollsInfo
+	if len(infos) == 0 {
+		infos = extra.Episode.PagesInfo
+	}
+
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("%q: %w", chapter.Name, errLocked)
+	}
+
+	// the token is sent with the requests of the pages instead of their URLs,
+	// which are shown to the user and written to the logs
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+token)
+
+	pages := make([]*source.Page, len(infos))
+	for i, info := range infos {
+		pages[i] = &source.Page{
+			URL: fmt.Sprintf(
+				"%s/v2%s.webp?purchased=true&q=30&updated=%d",
+				cdnURL,
+				info.Path,
+				extra.Episode.UpdatedAt,
+			),
+			Index:     i,
+			Chapter:   chapter,
+			Extension: ".webp",
+			Headers:   headers,
+		}
+	}
+
+	chapter.Pages = pages
+	return pages, nil
+}
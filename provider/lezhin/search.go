@@ -0,0 +1,66 @@
+package lezhin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"net/url"
+	"strings"
+)
+
+type searchResponse struct {
+	Data []struct {
+		ID    int    `json:"id"`
+		Alias string `json:"alias"`
+		Title string `json:"title"`
+	} `json:"data"`
+}
+
+// Search for comics by given title
+func (l *Lezhin) Search(query string) ([]*source.Manga, error) {
+	return l.SearchContext(context.Background(), query)
+}
+
+// SearchContext searches for comics by given title until the context is canceled
+func (l *Lezhin) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
+	query = strings.TrimSpace(query)
+
+	cacheKey := memo.SearchKey(l.ID(), query, language())
+	if mangas, ok := l.mangas.Get(cacheKey); ok {
+		return mangas, nil
+	}
+
+	body, err := l.get(ctx, fmt.Sprintf("%s/lz-api/v2/contents?menu=general&type=comic&q=%s", BaseURL, url.QueryEscape(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(body.Close)
+
+	var response searchResponse
+	if err = json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	mangas := make([]*source.Manga, len(response.Data))
+	for i, comic := range response.Data {
+		manga := &source.Manga{
+			Name:     comic.Title,
+			URL:      fmt.Sprintf("%s/%s/comic/%s", BaseURL, language(), comic.Alias),
+			Index:    i,
+			ID:       comic.Alias,
+			Chapters: make([]*source.Chapter, 0),
+			Source:   l,
+		}
+		manga.Metadata.Cover.ExtraLarge = fmt.Sprintf("%s/v2/comics/%d/images/wide.jpg", cdnURL, comic.ID)
+		manga.Metadata.LongStrip = true
+
+		mangas[i] = manga
+	}
+
+	l.mangas.Set(cacheKey, mangas)
+	return mangas, nil
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"net/url"
+	"strings"
+)
+
+// linkWords are the common words of the paths that are not part of the manga titles
+var linkWords = []string{"manga", "manhwa", "title", "series", "chapter", "chapters", "episode", "list", "viewer", "comic"}
+
+// QueryOf guesses the title of the manga from the link path,
+// e.g. "tower of god" for https://www.webtoons.com/en/fantasy/tower-of-god/list?title_no=95.
+// It is the longest path segment without the IDs and the common words
+func QueryOf(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	var query string
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		words := strings.FieldsFunc(segment, func(r rune) bool {
+			return r == '-' || r == '_' || r == '+' || r == ' '
+		})
+
+		words = lo.Filter(words, func(word string, _ int) bool {
+			return !strings.ContainsAny(word, "0123456789") && !lo.Contains(linkWords, strings.ToLower(word))
+		})
+
+		if joined := strings.Join(words, " "); len(joined) > len(query) {
+			query = joined
+		}
+	}
+
+	return query
+}
+
+// SameLink reports whether the links point to the same page, ignoring the scheme, www and the trailing slash
+func SameLink(a, b string) bool {
+	normalize := func(link string) string {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			return link
+		}
+
+		host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+		return fmt.Sprintf("%s%s?%s", host, strings.TrimSuffix(parsed.Path, "/"), parsed.RawQuery)
+	}
+
+	return normalize(a) == normalize(b)
+}
+
+// FindByLink searches the manga of the page with the title guessed from the link, see QueryOf
+func FindByLink(src source.Source, link string) (*source.Manga, error) {
+	query := QueryOf(link)
+	if query == "" {
+		return nil, fmt.Errorf("can't guess the title of the manga from %s", link)
+	}
+
+	mangas, err := src.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	manga, ok := lo.Find(mangas, func(manga *source.Manga) bool {
+		return SameLink(manga.URL, link)
+	})
+
+	if !ok {
+		return nil, fmt.Errorf("manga of %s not found on %s", link, src.Name())
+	}
+
+	return manga, nil
+}
+
+// linkCandidates is the number of the found mangas whose chapters are looked through for the chapter link
+const linkCandidates = 3
+
+// ChapterByLink returns the manga of the chapter page and its chapters, looking through the chapters of the found mangas.
+// Only the first few mangas are checked, since the chapter title rarely matches the others
+func ChapterByLink(mangas []*source.Manga, link string) (*source.Manga, []*source.Chapter, error) {
+	for _, manga := range mangas[:util.Min(len(mangas), linkCandidates)] {
+		chapters, err := manga.Source.ChaptersOf(manga)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if lo.ContainsBy(chapters, func(chapter *source.Chapter) bool {
+			return SameLink(chapter.URL, link)
+		}) {
+			return manga, chapters, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("chapter of %s not found", link)
+}
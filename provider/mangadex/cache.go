@@ -6,10 +6,14 @@ import (
 	"github.com/metafates/mangal/where"
 	"github.com/samber/mo"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
+// cacher is safe for concurrent use.
+// Map returned by gache is shared, so it is only accessed under the mutex
 type cacher[T any] struct {
+	mutex    sync.Mutex
 	internal *gache.Cache[map[string]T]
 }
 
@@ -26,6 +30,9 @@ func newCacher[T any](name string) *cacher[T] {
 }
 
 func (c *cacher[T]) Get(key string) mo.Option[T] {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	cached, expired, err := c.internal.Get()
 	if err != nil || expired || cached == nil {
 		return mo.None[T]()
@@ -39,6 +46,9 @@ func (c *cacher[T]) Get(key string) mo.Option[T] {
 }
 
 func (c *cacher[T]) Set(key string, value T) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	cached, expired, err := c.internal.Get()
 	if err != nil {
 		return err
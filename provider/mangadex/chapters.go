@@ -4,20 +4,33 @@ import (
 	"fmt"
 	"github.com/darylhjd/mangodex"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 func (m *Mangadex) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
-	if cached, ok := m.cache.chapters.Get(manga.URL).Get(); ok {
-		for _, chapter := range cached {
-			chapter.Manga = manga
-		}
+	cacheKey := memo.MangaKey(
+		m.ID(),
+		manga,
+		viper.GetString(key.MangadexLanguage),
+		strconv.FormatBool(viper.GetBool(key.MangadexNSFW)),
+		strconv.FormatBool(viper.GetBool(key.MangadexShowUnavailableChapters)),
+		strings.Join(viper.GetStringSlice(key.MangadexGroups), ","),
+	)
 
-		return cached, nil
+	if cached, ok := m.cache.chapters.Get(cacheKey).Get(); ok {
+		// cached chapters are shared, so copies are returned
+		return lo.Map(cached, func(chapter *source.Chapter, _ int) *source.Chapter {
+			copied := *chapter
+			copied.Manga = manga
+			return &copied
+		}), nil
 	}
 
 	params := url.Values{}
@@ -32,17 +45,20 @@ func (m *Mangadex) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 		params.Add("contentRating[]", mangodex.Erotica)
 	}
 
+	language := viper.GetString(key.MangadexLanguage)
+	if language != "any" {
+		params.Add("translatedLanguage[]", language)
+	}
+
 	// scanlation group for the chapter
 	params.Add("includes[]", mangodex.ScanlationGroupRel)
 	params.Set("order[chapter]", "asc")
 
 	var (
-		chapters   []*source.Chapter
+		listed     []*listedChapter
 		currOffset = 0
 	)
 
-	language := viper.GetString(key.MangadexLanguage)
-
 	for {
 		params.Set("offset", strconv.Itoa(currOffset))
 		list, err := m.client.Chapter.GetMangaChapters(manga.ID, params)
@@ -50,53 +66,54 @@ func (m *Mangadex) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
 			return nil, err
 		}
 
-		for i, chapter := range list.Data {
+		for _, chapter := range list.Data {
 			// Skip external chapters. Their pages cannot be downloaded.
 			if chapter.Attributes.ExternalURL != nil && !viper.GetBool(key.MangadexShowUnavailableChapters) {
 				continue
 			}
 
-			// skip chapters that are not in the current language
-			if language != "any" && chapter.Attributes.TranslatedLanguage != language {
-				currOffset += 500
-				continue
-			}
-
-			name := chapter.GetTitle()
-			if name == "" {
-				name = fmt.Sprintf("Chapter %s", chapter.GetChapterNum())
-			} else {
-				name = fmt.Sprintf("Chapter %s - %s", chapter.GetChapterNum(), name)
+			title := chapter.GetTitle()
+			name := fmt.Sprintf("Chapter %s", chapter.GetChapterNum())
+			if title != "" {
+				name = fmt.Sprintf("%s - %s", name, title)
 			}
 
 			var volume string
 			if chapter.Attributes.Volume != nil {
 				volume = fmt.Sprintf("Vol.%s", *chapter.Attributes.Volume)
 			}
-			chapters = append(chapters, &source.Chapter{
-				Name:   name,
-				Index:  uint16(i),
-				ID:     chapter.ID,
-				URL:    fmt.Sprintf("https://mangadex.org/chapter/%s", chapter.ID),
-				Manga:  manga,
-				Volume: volume,
+
+			groups, rank := groupsOf(chapter.Relationships)
+			listed = append(listed, &listedChapter{
+				number: chapter.GetChapterNum(),
+				rank:   rank,
+				chapter: &source.Chapter{
+					Name:       name,
+					ID:         chapter.ID,
+					URL:        fmt.Sprintf("%s/chapter/%s", BaseURL, chapter.ID),
+					Manga:      manga,
+					Volume:     volume,
+					Title:      title,
+					Scanlators: groups,
+					Language:   chapter.Attributes.TranslatedLanguage,
+				},
 			})
 		}
+
 		currOffset += 500
 		if currOffset >= list.Total {
 			break
 		}
+	}
 
-		if currOffset >= list.Total {
-			break
-		}
+	chapters := selectGroups(listed)
+	for i, chapter := range chapters {
+		chapter.Index = i + 1
 	}
 
-	slices.SortFunc(chapters, func(a, b *source.Chapter) bool {
-		return a.Index < b.Index
-	})
+	slices.SortFunc(chapters, source.ChapterLess)
 
 	manga.Chapters = chapters
-	_ = m.cache.chapters.Set(manga.URL, chapters)
+	_ = m.cache.chapters.Set(cacheKey, chapters)
 	return chapters, nil
 }
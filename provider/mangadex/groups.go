@@ -0,0 +1,91 @@
+package mangadex
+
+import (
+	"github.com/darylhjd/mangodex"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// listedChapter is the chapter returned by the API before the groups are selected
+type listedChapter struct {
+	chapter *source.Chapter
+	// number of the chapter given by the API, "-" if it has none, e.g. for the oneshots
+	number string
+	// rank of the group in mangadex.groups, -1 if none of the groups of the chapter is listed
+	rank int
+}
+
+// groupsOf returns the names of the scanlation groups of the chapter
+// and the rank of the first of them listed in mangadex.groups
+func groupsOf(relationships []mangodex.Relationship) (names []string, rank int) {
+	preferred := lo.Map(viper.GetStringSlice(key.MangadexGroups), func(group string, _ int) string {
+		return strings.ToLower(strings.TrimSpace(group))
+	})
+
+	rank = -1
+	for _, relationship := range relationships {
+		if relationship.Type != mangodex.ScanlationGroupRel {
+			continue
+		}
+
+		var name string
+		if attributes, ok := relationship.Attributes.(*mangodex.ScanlationGroupAttributes); ok {
+			name = attributes.Name
+		}
+
+		if name != "" {
+			names = append(names, name)
+		}
+
+		for i, group := range preferred {
+			if group != strings.ToLower(name) && group != strings.ToLower(relationship.ID) {
+				continue
+			}
+
+			if rank == -1 || i < rank {
+				rank = i
+			}
+		}
+	}
+
+	return
+}
+
+// selectGroups keeps the chapters of the groups listed in mangadex.groups, if any.
+// When several groups translated the same chapter to the same language, the one listed first is kept
+func selectGroups(listed []*listedChapter) []*source.Chapter {
+	if len(viper.GetStringSlice(key.MangadexGroups)) == 0 {
+		return lo.Map(listed, func(l *listedChapter, _ int) *source.Chapter {
+			return l.chapter
+		})
+	}
+
+	listed = lo.Filter(listed, func(l *listedChapter, _ int) bool {
+		return l.rank != -1
+	})
+
+	// translations of the chapter to the other languages are kept
+	numberOf := func(l *listedChapter) string {
+		return l.chapter.Language + "/" + l.number
+	}
+
+	best := make(map[string]*listedChapter)
+	for _, l := range listed {
+		if previous, ok := best[numberOf(l)]; !ok || l.rank < previous.rank {
+			best[numberOf(l)] = l
+		}
+	}
+
+	var chapters []*source.Chapter
+	for _, l := range listed {
+		// chapters without the numbers can't be told apart, so all of them are kept
+		if l.number == "-" || best[numberOf(l)] == l {
+			chapters = append(chapters, l.chapter)
+		}
+	}
+
+	return chapters
+}
@@ -0,0 +1,75 @@
+package mangadex
+
+import (
+	"github.com/darylhjd/mangodex"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func translatedBy(number string, groups ...string) *listedChapter {
+	var relationships []mangodex.Relationship
+	for _, group := range groups {
+		relationships = append(relationships, mangodex.Relationship{
+			ID:         group + "-id",
+			Type:       mangodex.ScanlationGroupRel,
+			Attributes: &mangodex.ScanlationGroupAttributes{Name: group},
+		})
+	}
+
+	names, rank := groupsOf(relationships)
+	return &listedChapter{
+		chapter: &source.Chapter{Name: "Chapter " + number, Scanlators: names},
+		number:  number,
+		rank:    rank,
+	}
+}
+
+func TestSelectGroups(t *testing.T) {
+	defer viper.Set(key.MangadexGroups, viper.GetStringSlice(key.MangadexGroups))
+
+	Convey("Given the chapters translated by several groups", t, func() {
+		Convey("When no groups are preferred", func() {
+			viper.Set(key.MangadexGroups, []string{})
+			chapters := selectGroups([]*listedChapter{translatedBy("1", "First"), translatedBy("1", "Second")})
+
+			Convey("Then all the chapters should be kept", func() {
+				So(len(chapters), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the groups are preferred by their names and IDs", func() {
+			viper.Set(key.MangadexGroups, []string{"second", "First-ID"})
+			chapters := selectGroups([]*listedChapter{
+				translatedBy("1", "First"),
+				translatedBy("1", "Second"),
+				translatedBy("2", "First"),
+				translatedBy("3", "Third"),
+				translatedBy("-", "First"),
+				translatedBy("-", "Second"),
+			})
+
+			Convey("Then the chapter of the group listed first should be kept for each number", func() {
+				So(len(chapters), ShouldEqual, 4)
+				So(chapters[0].Scanlators, ShouldResemble, []string{"Second"})
+				So(chapters[1].Name, ShouldEqual, "Chapter 2")
+				So(chapters[2].Name, ShouldEqual, "Chapter -")
+				So(chapters[3].Name, ShouldEqual, "Chapter -")
+			})
+		})
+
+		Convey("When the chapter is translated to several languages", func() {
+			viper.Set(key.MangadexGroups, []string{"First", "Second"})
+			english, french := translatedBy("1", "First"), translatedBy("1", "Second")
+			english.chapter.Language, french.chapter.Language = "en", "fr"
+
+			chapters := selectGroups([]*listedChapter{english, french})
+
+			Convey("Then the translation to each of them should be kept", func() {
+				So(chapters, ShouldHaveLength, 2)
+			})
+		})
+	})
+}
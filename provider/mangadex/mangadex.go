@@ -6,8 +6,9 @@ import (
 )
 
 const (
-	Name = "Mangadex"
-	ID   = Name + " built-in"
+	Name    = "Mangadex"
+	ID      = Name + " built-in"
+	BaseURL = "https://mangadex.org"
 )
 
 type Mangadex struct {
@@ -0,0 +1,25 @@
+package mangadex
+
+import (
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider/providertest"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestMangadexFixtures(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	Convey("Given the recorded responses of mangadex", t, func() {
+		viper.Set(key.MangadexLanguage, "en")
+		defer viper.Set(key.MangadexLanguage, nil)
+
+		Convey("Then the mangas, the chapters and the pages should be extracted as before", func() {
+			So(providertest.Check("testdata", "Death Note", func() source.Source {
+				return New()
+			}), ShouldBeNil)
+		})
+	})
+}
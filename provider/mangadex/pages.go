@@ -2,25 +2,64 @@ package mangadex
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"github.com/darylhjd/mangodex"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/network"
 	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/url"
 	"path/filepath"
+	"strings"
 )
 
 func (m *Mangadex) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
-	downloader, err := m.client.AtHome.NewMDHomeClient(chapter.ID, "data", false)
-	if err != nil {
-		return nil, err
+	quality := "data"
+	if viper.GetBool(key.MangadexDataSaver) {
+		quality = "data-saver"
+	}
+
+	var (
+		names []string
+		get   func(name string) ([]byte, error)
+	)
+
+	if network.Replaying() {
+		home, err := m.atHome(chapter.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		names = home.Chapter.Data
+		if quality == "data-saver" {
+			names = home.Chapter.DataSaver
+		}
+
+		get = func(name string) ([]byte, error) {
+			return getPage(strings.Join([]string{home.BaseURL, quality, home.Chapter.Hash, name}, "/"))
+		}
+	} else {
+		downloader, err := m.client.AtHome.NewMDHomeClient(chapter.ID, quality, false)
+		if err != nil {
+			return nil, err
+		}
+
+		names, get = downloader.Pages, downloader.GetChapterPage
 	}
 
-	if len(downloader.Pages) == 0 {
+	if len(names) == 0 {
 		return nil, errors.New("there were no pages for this chapter")
 	}
 
-	var pages = make([]*source.Page, len(downloader.Pages))
+	var pages = make([]*source.Page, len(names))
 
-	for i, name := range downloader.Pages {
-		image, err := downloader.GetChapterPage(name)
+	for i, name := range names {
+		image, err := get(name)
 		if err != nil {
 			return nil, err
 		}
@@ -30,7 +69,7 @@ func (m *Mangadex) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
 		}
 
 		page := source.Page{
-			Index:     uint16(i),
+			Index:     i,
 			Chapter:   chapter,
 			Extension: filepath.Ext(name),
 			Contents:  bytes.NewBuffer(image),
@@ -43,3 +82,36 @@ func (m *Mangadex) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
 	chapter.Pages = pages
 	return pages, nil
 }
+
+// atHome gets the MangaDex@Home server of the chapter the way mangodex does.
+// It is used instead of the mangodex client while the session is replayed,
+// since the client reports every page to MangaDex@Home in the background,
+// and the reports would outlive the replay and go to the network
+func (m *Mangadex) atHome(chapterID string) (*mangodex.MDHomeServerResponse, error) {
+	u, _ := url.Parse(mangodex.BaseAPI)
+	u.Path = fmt.Sprintf(mangodex.GetMDHomeURLPath, chapterID)
+	u.RawQuery = url.Values{"forcePort443": {"false"}}.Encode()
+
+	var home mangodex.MDHomeServerResponse
+	if err := m.client.RequestAndDecode(context.Background(), http.MethodGet, u.String(), nil, &home); err != nil {
+		return nil, err
+	}
+
+	return &home, nil
+}
+
+// getPage gets the image of the page without reporting it, see atHome
+func getPage(link string) ([]byte, error) {
+	response, err := http.Get(link)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(response.Body.Close)
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d status code", response.StatusCode)
+	}
+
+	return io.ReadAll(response.Body)
+}
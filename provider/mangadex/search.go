@@ -1,23 +1,34 @@
 package mangadex
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/darylhjd/mangodex"
 	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider/memo"
 	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
 	"github.com/spf13/viper"
-	"log"
+	"golang.org/x/exp/slices"
 	"net/url"
 	"strconv"
 )
 
 func (m *Mangadex) Search(query string) ([]*source.Manga, error) {
-	if cached, ok := m.cache.mangas.Get(query).Get(); ok {
-		for _, manga := range cached {
-			manga.Source = m
-		}
+	return m.SearchContext(context.Background(), query)
+}
 
-		return cached, nil
+// SearchContext searches for mangas by given title until the context is canceled
+func (m *Mangadex) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
+	cacheKey := memo.SearchKey(m.ID(), query, viper.GetString(key.MangadexLanguage), strconv.FormatBool(viper.GetBool(key.MangadexNSFW)))
+	if cached, ok := m.cache.mangas.Get(cacheKey).Get(); ok {
+		// cached mangas are shared, so copies are returned
+		return lo.Map(cached, func(manga *source.Manga, _ int) *source.Manga {
+			copied := *manga
+			copied.Source = m
+			return &copied
+		}), nil
 	}
 
 	params := url.Values{}
@@ -34,12 +45,13 @@ func (m *Mangadex) Search(query string) ([]*source.Manga, error) {
 		params.Add("contentRating[]", mangodex.Erotica)
 	}
 
+	params.Add("includes[]", mangodex.AuthorRel)
+	params.Add("includes[]", mangodex.ArtistRel)
 	params.Set("order[followedCount]", "desc")
 	params.Set("title", query)
 
-	mangaList, err := m.client.Manga.GetMangaList(params)
+	mangaList, err := m.client.Manga.GetMangaListContext(ctx, params)
 	if err != nil {
-		log.Fatalln(err)
 		return nil, err
 	}
 
@@ -48,15 +60,56 @@ func (m *Mangadex) Search(query string) ([]*source.Manga, error) {
 	for i, manga := range mangaList.Data {
 		m := source.Manga{
 			Name:   manga.GetTitle(viper.GetString(key.MangadexLanguage)),
-			URL:    fmt.Sprintf("https://mangadex.org/title/%s", manga.ID),
-			Index:  uint16(i),
+			URL:    fmt.Sprintf("%s/title/%s", BaseURL, manga.ID),
+			Index:  i,
 			ID:     manga.ID,
 			Source: m,
 		}
 
+		m.Metadata.Title = manga.Attributes.Title.GetLocalString("en")
+		m.Metadata.NativeTitle = manga.Attributes.AltTitles.Values[manga.Attributes.OriginalLanguage]
+		m.Metadata.Synonyms = altTitles(manga.Attributes.AltTitles)
+		m.Metadata.Staff.Story = staff(manga.Relationships, mangodex.AuthorRel)
+		m.Metadata.Staff.Art = staff(manga.Relationships, mangodex.ArtistRel)
+
 		mangas = append(mangas, &m)
 	}
 
-	_ = m.cache.mangas.Set(query, mangas)
+	_ = m.cache.mangas.Set(cacheKey, mangas)
 	return mangas, nil
 }
+
+// staff returns the names of the included relationships of the given type
+func staff(relationships []mangodex.Relationship, type_ string) []string {
+	names := make([]string, 0)
+	for _, relationship := range relationships {
+		if relationship.Type != type_ {
+			continue
+		}
+
+		switch attributes := relationship.Attributes.(type) {
+		case *mangodex.AuthorAttributes:
+			if attributes.Name != "" {
+				names = append(names, attributes.Name)
+			}
+		case *json.RawMessage:
+			// artists are not decoded by the client, though they have the same attributes
+			var artist mangodex.AuthorAttributes
+			if json.Unmarshal(*attributes, &artist) == nil && artist.Name != "" {
+				names = append(names, artist.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// altTitles returns the alternative titles ordered by their language
+func altTitles(titles mangodex.LocalisedStrings) []string {
+	languages := lo.Keys(titles.Values)
+	slices.Sort(languages)
+
+	return lo.Map(languages, func(language string, _ int) string {
+		return titles.Values[language]
+	})
+}
@@ -56,6 +56,9 @@ var Config = &generic.Configuration{
 			}
 			return ""
 		},
+		Title: func(selection *goquery.Selection) string {
+			return generic.TitleFromName(selection.Find("a").Text())
+		},
 	},
 	PageExtractor: &generic.Extractor{
 		Selector: ".container-chapter-reader img",
@@ -2,6 +2,8 @@ package manganato
 
 import (
 	"github.com/metafates/mangal/provider/generic"
+	"github.com/metafates/mangal/provider/providertest"
+	"github.com/metafates/mangal/source"
 	. "github.com/smartystreets/goconvey/convey"
 	"testing"
 )
@@ -65,3 +67,13 @@ func TestManganelo(t *testing.T) {
 		})
 	})
 }
+
+func TestManganatoFixtures(t *testing.T) {
+	Convey("Given the recorded responses of manganato", t, func() {
+		Convey("Then the mangas, the chapters and the pages should be extracted as before", func() {
+			So(providertest.Check("testdata", "Death Note", func() source.Source {
+				return generic.New(Config)
+			}), ShouldBeNil)
+		})
+	})
+}
@@ -55,6 +55,9 @@ var Config = &generic.Configuration{
 			}
 			return ""
 		},
+		Title: func(selection *goquery.Selection) string {
+			return generic.TitleFromName(selection.Find(".chapter-name").Text())
+		},
 	},
 	PageExtractor: &generic.Extractor{
 		Selector: ".container-chapter-reader img",
@@ -0,0 +1,82 @@
+package memo
+
+import (
+	"github.com/metafates/mangal/source"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are the query params that don't change the page, they are left out of the canonical links.
+// utm_ params are left out too
+var trackingParams = map[string]bool{"fbclid": true, "gclid": true, "ref": true}
+
+// SearchKey is the key of the search results of the source.
+// Queries that differ only by the case or the spaces share it,
+// variants are the settings that change the results, e.g. the language
+func SearchKey(sourceID, query string, variants ...string) string {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	return join(append([]string{sourceID, "search", query}, variants...))
+}
+
+// MangaKey is the key of the results for the manga, e.g. its chapters.
+// The manga is identified by its ID within the source, or by its canonical link if it has none,
+// so that the same manga found on another mirror of the site shares the key
+func MangaKey(sourceID string, manga *source.Manga, variants ...string) string {
+	return join(append([]string{sourceID, "manga", identify(manga.ID, manga.URL)}, variants...))
+}
+
+// ChapterKey is the key of the results for the chapter, e.g. its pages.
+// IDs of the chapters are often unique only within their manga, e.g. "chapter-1", so it is a part of the key
+func ChapterKey(sourceID string, chapter *source.Chapter, variants ...string) string {
+	var manga string
+	if chapter.Manga != nil {
+		manga = identify(chapter.Manga.ID, chapter.Manga.URL)
+	}
+
+	return join(append([]string{sourceID, "chapter", manga, identify(chapter.ID, chapter.URL)}, variants...))
+}
+
+func identify(id, link string) string {
+	if id != "" {
+		return id
+	}
+
+	return Canonical(link)
+}
+
+// Canonical returns the link without the parts that differ between the mirrors of the site
+// and the visits of the same page: the scheme, the host, the fragment, the trailing slash and the tracking params.
+// The remaining query params are sorted, e.g. https://www.example.com/manga/1/?utm_source=x&b=2&a=1 is /manga/1?a=1&b=2
+func Canonical(link string) string {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return strings.TrimSpace(link)
+	}
+
+	canonical := strings.TrimSuffix(parsed.EscapedPath(), "/")
+	if canonical == "" {
+		canonical = "/"
+	}
+
+	params := parsed.Query()
+	for param := range params {
+		if trackingParams[param] || strings.HasPrefix(param, "utm_") {
+			params.Del(param)
+		}
+	}
+
+	if len(params) > 0 {
+		canonical += "?" + params.Encode()
+	}
+
+	return canonical
+}
+
+// join escapes the parts, so that the keys of the different parts can't be equal
+func join(parts []string) string {
+	for i, part := range parts {
+		parts[i] = url.QueryEscape(part)
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,97 @@
+package memo
+
+import (
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSearchKey(t *testing.T) {
+	Convey("When making the keys of the queries that differ by the case and the spaces", t, func() {
+		first, second := SearchKey("source", "One  Piece"), SearchKey("source", " one piece ")
+
+		Convey("Then they should be equal", func() {
+			So(first, ShouldEqual, second)
+		})
+	})
+
+	Convey("When making the keys of the same query for the different sources or variants", t, func() {
+		keys := []string{
+			SearchKey("first", "query"),
+			SearchKey("second", "query"),
+			SearchKey("first", "query", "en"),
+			SearchKey("first", "query", "fr"),
+		}
+
+		Convey("Then they should be distinct", func() {
+			for i := range keys {
+				for j := i + 1; j < len(keys); j++ {
+					So(keys[i], ShouldNotEqual, keys[j])
+				}
+			}
+		})
+	})
+
+	Convey("When making the keys whose parts contain the separator", t, func() {
+		Convey("Then they should not collide", func() {
+			So(SearchKey("a/search", "b"), ShouldNotEqual, SearchKey("a", "search/b"))
+		})
+	})
+}
+
+func TestMangaKey(t *testing.T) {
+	Convey("Given the manga without the ID found on the mirrors of the site", t, func() {
+		first := &source.Manga{URL: "https://example.com/manga/1/?utm_source=feed#top"}
+		second := &source.Manga{URL: "http://www.example.org/manga/1"}
+
+		Convey("When making their keys", func() {
+			Convey("Then they should be equal", func() {
+				So(MangaKey("source", first), ShouldEqual, MangaKey("source", second))
+			})
+		})
+	})
+
+	Convey("Given the mangas with the same path on the different sources", t, func() {
+		manga := &source.Manga{URL: "https://example.com/manga/1"}
+
+		Convey("Then their keys should be distinct", func() {
+			So(MangaKey("first", manga), ShouldNotEqual, MangaKey("second", manga))
+		})
+	})
+
+	Convey("Given the mangas with the IDs", t, func() {
+		first := &source.Manga{ID: "1", URL: "https://example.com/en/manga/1"}
+		second := &source.Manga{ID: "1", URL: "https://example.com/fr/manga/1"}
+
+		Convey("Then the IDs should be used instead of the links", func() {
+			So(MangaKey("source", first), ShouldEqual, MangaKey("source", second))
+		})
+	})
+}
+
+func TestChapterKey(t *testing.T) {
+	Convey("Given the chapters with the same ID of the different mangas", t, func() {
+		first := &source.Chapter{ID: "chapter-1", Manga: &source.Manga{ID: "first"}}
+		second := &source.Chapter{ID: "chapter-1", Manga: &source.Manga{ID: "second"}}
+
+		Convey("Then their keys should be distinct", func() {
+			So(ChapterKey("source", first), ShouldNotEqual, ChapterKey("source", second))
+		})
+	})
+}
+
+func TestCanonical(t *testing.T) {
+	Convey("When canonicalizing the link with the query params", t, func() {
+		canonical := Canonical("https://www.example.com/manga/1/?utm_source=x&b=2&a=1&fbclid=y")
+
+		Convey("Then the tracking params should be left out and the others sorted", func() {
+			So(canonical, ShouldEqual, "/manga/1?a=1&b=2")
+		})
+	})
+
+	Convey("When canonicalizing the links that differ by the significant params", t, func() {
+		Convey("Then they should be distinct", func() {
+			So(Canonical("https://example.com/search?page=1"), ShouldNotEqual, Canonical("https://example.com/search?page=2"))
+		})
+	})
+}
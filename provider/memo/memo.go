@@ -0,0 +1,49 @@
+// Package memo provides a concurrency-safe in-memory cache for the results of the sources.
+// Sources are used from several goroutines at once, e.g. by the TUI searches and the server,
+// so their results must not be kept in plain maps.
+package memo
+
+import "sync"
+
+// Memo is a concurrency-safe map of the results by their keys, see SearchKey and MangaKey
+type Memo[T any] struct {
+	mutex  sync.RWMutex
+	values map[string]T
+}
+
+// New returns an empty memo
+func New[T any]() *Memo[T] {
+	return &Memo[T]{values: make(map[string]T)}
+}
+
+// Get returns the value by the key
+func (m *Memo[T]) Get(key string) (value T, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	value, ok = m.values[key]
+	return
+}
+
+// Set saves the value by the key, overwriting the previous one
+func (m *Memo[T]) Set(key string, value T) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.values[key] = value
+}
+
+// LoadOrStore returns the existing value by the key, if any.
+// Otherwise, it saves the given value and returns it.
+// The loaded result is true if the value was loaded, false if stored
+func (m *Memo[T]) LoadOrStore(key string, value T) (actual T, loaded bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if actual, loaded = m.values[key]; loaded {
+		return
+	}
+
+	m.values[key] = value
+	return value, false
+}
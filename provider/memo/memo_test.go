@@ -0,0 +1,58 @@
+package memo
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"sync"
+	"testing"
+)
+
+func TestMemo(t *testing.T) {
+	Convey("Given an empty memo", t, func() {
+		memo := New[int]()
+
+		Convey("When getting a missing key", func() {
+			_, ok := memo.Get("missing")
+
+			Convey("Then it should not be found", func() {
+				So(ok, ShouldBeFalse)
+			})
+		})
+
+		Convey("When loading or storing the same key twice", func() {
+			first, loaded := memo.LoadOrStore("key", 1)
+			So(loaded, ShouldBeFalse)
+			So(first, ShouldEqual, 1)
+
+			second, loaded := memo.LoadOrStore("key", 2)
+
+			Convey("Then the first value should be kept", func() {
+				So(loaded, ShouldBeTrue)
+				So(second, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When it is written and read concurrently", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 100; i++ {
+				wg.Add(2)
+				go func(i int) {
+					defer wg.Done()
+					memo.Set(fmt.Sprint(i%10), i)
+				}(i)
+				go func(i int) {
+					defer wg.Done()
+					memo.Get(fmt.Sprint(i % 10))
+				}(i)
+			}
+			wg.Wait()
+
+			Convey("Then every key should be saved", func() {
+				for i := 0; i < 10; i++ {
+					_, ok := memo.Get(fmt.Sprint(i))
+					So(ok, ShouldBeTrue)
+				}
+			})
+		})
+	})
+}
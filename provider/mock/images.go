@@ -0,0 +1,151 @@
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	pageWidth, pageHeight   = 720, 1024
+	coverWidth, coverHeight = 460, 650
+)
+
+// digits are the bitmaps 3x5 of the digits, a row per 3 bits, so that no font is needed
+var digits = [10][5]uint8{
+	{7, 5, 5, 5, 7},
+	{2, 6, 2, 2, 7},
+	{7, 1, 7, 4, 7},
+	{7, 1, 7, 1, 7},
+	{5, 5, 7, 1, 1},
+	{7, 4, 7, 1, 7},
+	{7, 4, 7, 5, 7},
+	{7, 1, 1, 1, 1},
+	{7, 5, 7, 5, 7},
+	{7, 5, 7, 1, 7},
+}
+
+// images serves the covers and the pages of the mock mangas:
+// /covers/<manga>.png and /pages/<manga>/<chapter>/<page>.png
+type images struct{}
+
+func (images) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return respond(req, http.StatusMethodNotAllowed, nil), nil
+	}
+
+	img, ok := imageOf(strings.Split(strings.Trim(req.URL.Path, "/"), "/"))
+	if !ok {
+		return respond(req, http.StatusNotFound, nil), nil
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return respond(req, http.StatusOK, buf.Bytes()), nil
+}
+
+func respond(req *http.Request, status int, body []byte) *http.Response {
+	response := &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+
+	if body != nil {
+		response.Header.Set("Content-Type", "image/png")
+	}
+
+	response.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	if req.Method == http.MethodHead {
+		body = nil
+	}
+
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	return response
+}
+
+// imageOf returns the image at the path split by the slashes
+func imageOf(path []string) (image.Image, bool) {
+	switch {
+	case len(path) == 2 && path[0] == "covers" && strings.HasSuffix(path[1], ".png"):
+		id := strings.TrimSuffix(path[1], ".png")
+		return placeholder(id, coverWidth, coverHeight, 0), true
+	case len(path) == 4 && path[0] == "pages" && strings.HasSuffix(path[3], ".png"):
+		page, err := strconv.Atoi(strings.TrimSuffix(path[3], ".png"))
+		if err != nil || page < 1 {
+			return nil, false
+		}
+
+		if _, err = strconv.Atoi(path[2]); err != nil {
+			return nil, false
+		}
+
+		return placeholder(path[1]+"/"+path[2], pageWidth, pageHeight, page), true
+	default:
+		return nil, false
+	}
+}
+
+// placeholder returns the image of the color of the seed with the number in the middle, no number if it is 0
+func placeholder(seed string, width, height, number int) image.Image {
+	r := random("image", seed)
+	background := color.RGBA{R: uint8(64 + r.Intn(128)), G: uint8(64 + r.Intn(128)), B: uint8(64 + r.Intn(128)), A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	if number > 0 {
+		drawNumber(img, strconv.Itoa(number), color.White)
+	}
+
+	return img
+}
+
+// drawNumber draws the digits in the middle of the image, scaled to a third of its width
+func drawNumber(img *image.RGBA, number string, c color.Color) {
+	bounds := img.Bounds()
+	// each digit is 3 cells wide with a cell of space after it
+	cells := len(number)*4 - 1
+	scale := bounds.Dx() / 3 / cells
+	if scale < 1 {
+		scale = 1
+	}
+
+	left := (bounds.Dx() - cells*scale) / 2
+	top := (bounds.Dy() - 5*scale) / 2
+	fill := &image.Uniform{C: c}
+
+	for i, digit := range number {
+		bitmap := digits[digit-'0']
+		for row, bits := range bitmap {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+
+				x := left + (i*4+col)*scale
+				y := top + row*scale
+				draw.Draw(img, image.Rect(x, y, x+scale, y+scale), fill, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
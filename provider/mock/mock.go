@@ -0,0 +1,188 @@
+// Package mock is the source of the fake mangas, generated from the queries without the network.
+// The same query always gives the same mangas, chapters and pages,
+// so that the TUI, the converters and the integrations can be developed and demoed offline and in CI.
+// Pages are the placeholder images with their numbers, served by the mock scheme, see Scheme
+package mock
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+const (
+	Name = "Mock"
+	ID   = Name + " built-in"
+)
+
+// Scheme of the links of the mock mangas, handled by every client of mangal
+const Scheme = "mock"
+
+// BaseURL is the address of the mock site
+const BaseURL = Scheme + "://mangal"
+
+func init() {
+	network.RegisterProtocol(Scheme, images{})
+}
+
+type Mock struct{}
+
+func New() *Mock {
+	return &Mock{}
+}
+
+func (*Mock) Name() string {
+	return Name
+}
+
+func (*Mock) ID() string {
+	return ID
+}
+
+var (
+	suffixes = []string{"Side Stories", "Remastered", "Full Color", "Anthology", "Zero", "After Story"}
+	genres   = []string{"Action", "Adventure", "Comedy", "Drama", "Fantasy", "Mystery", "Romance", "Sci-Fi", "Slice of Life", "Sports"}
+	people   = []string{"Aoi Tanaka", "Ren Kobayashi", "Mina Park", "Jun Seo", "Hana Mori", "Kai Ishida", "Yuna Choi", "Sora Fujita"}
+	words    = []string{"Beginning", "Storm", "Promise", "Shadow", "Journey", "Rival", "Secret", "Festival", "Tower", "Return", "Dawn", "Farewell"}
+)
+
+// random returns the generator seeded by the parts, so that the same parts give the same values
+func random(parts ...string) *rand.Rand {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(strings.Join(parts, "\x00")))
+	return rand.New(rand.NewSource(int64(hash.Sum64())))
+}
+
+func pick(r *rand.Rand, list []string, n int) []string {
+	picked := make([]string, 0, n)
+	for _, i := range r.Perm(len(list))[:n] {
+		picked = append(picked, list[i])
+	}
+
+	return picked
+}
+
+// slug returns the ID of the manga by its name, e.g. "one-piece-zero"
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// Search returns up to 4 mangas, the first one is named as the query
+func (m *Mock) Search(query string) ([]*source.Manga, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []*source.Manga{}, nil
+	}
+
+	r := random("search", strings.ToLower(query))
+	names := append([]string{query}, lo.Map(pick(r, suffixes, r.Intn(4)), func(suffix string, _ int) string {
+		return query + ": " + suffix
+	})...)
+
+	mangas := make([]*source.Manga, len(names))
+	for i, name := range names {
+		mangas[i] = m.manga(name, i)
+	}
+
+	return mangas, nil
+}
+
+func (m *Mock) manga(name string, index int) *source.Manga {
+	id := slug(name)
+	r := random("manga", id)
+
+	manga := &source.Manga{
+		Name:     name,
+		URL:      BaseURL + "/manga/" + id,
+		Index:    index,
+		ID:       id,
+		Chapters: make([]*source.Chapter, 0),
+		Source:   m,
+	}
+
+	metadata := &manga.Metadata
+	metadata.Summary = fmt.Sprintf("%s is the fake manga of the mock source.\nIts chapters and pages are generated offline.", name)
+	metadata.Genres = pick(r, genres, 2+r.Intn(2))
+	metadata.Staff.Story = pick(r, people, 1)
+	metadata.Staff.Art = pick(r, people, 1)
+	metadata.Cover.ExtraLarge = BaseURL + "/covers/" + id + ".png"
+	metadata.StartDate.Year = 2000 + r.Intn(24)
+	metadata.StartDate.Month = 1 + r.Intn(12)
+	metadata.StartDate.Day = 1 + r.Intn(28)
+	metadata.Language = "en"
+
+	metadata.Status = source.StatusReleasing
+	if r.Intn(3) == 0 {
+		metadata.Status = source.StatusFinished
+	}
+
+	return manga
+}
+
+// ChaptersOf returns from 5 to 50 chapters, 10 chapters per volume
+func (m *Mock) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	r := random("chapters", manga.ID)
+	chapters := make([]*source.Chapter, 5+r.Intn(46))
+
+	for i := range chapters {
+		index := i + 1
+		title := words[r.Intn(len(words))]
+		if r.Intn(2) == 0 {
+			title = "The " + title
+		}
+
+		chapters[i] = &source.Chapter{
+			Name:   fmt.Sprintf("Chapter %d: %s", index, title),
+			Title:  title,
+			URL:    fmt.Sprintf("%s/chapter/%s/%d", BaseURL, manga.ID, index),
+			Index:  index,
+			ID:     fmt.Sprintf("%s-%d", manga.ID, index),
+			Volume: fmt.Sprintf("Vol.%d", i/10+1),
+			Manga:  manga,
+			Pages:  make([]*source.Page, 0),
+		}
+	}
+
+	if manga.Metadata.Status == source.StatusFinished {
+		manga.Metadata.Chapters = len(chapters)
+	}
+
+	manga.Chapters = chapters
+	return chapters, nil
+}
+
+// PagesOf returns from 4 to 20 pages
+func (m *Mock) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	r := random("pages", chapter.URL)
+	pages := make([]*source.Page, 4+r.Intn(17))
+
+	for i := range pages {
+		pages[i] = &source.Page{
+			URL:       fmt.Sprintf("%s/pages/%s/%d/%d.png", BaseURL, chapter.Manga.ID, chapter.Index, i+1),
+			Index:     i,
+			Extension: ".png",
+			Chapter:   chapter,
+		}
+	}
+
+	chapter.Pages = pages
+	return pages, nil
+}
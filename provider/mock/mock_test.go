@@ -0,0 +1,101 @@
+package mock
+
+import (
+	"context"
+	. "github.com/smartystreets/goconvey/convey"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+func TestMock(t *testing.T) {
+	Convey("Given a mock instance", t, func() {
+		mock := New()
+
+		Convey("When searching for a manga", func() {
+			mangas, err := mock.Search("Chainsaw Man")
+			So(err, ShouldBeNil)
+
+			Convey("Then the first manga should be named as the query", func() {
+				So(len(mangas), ShouldBeBetweenOrEqual, 1, 4)
+				So(mangas[0].Name, ShouldEqual, "Chainsaw Man")
+				So(mangas[0].ID, ShouldEqual, "chainsaw-man")
+				So(mangas[0].Metadata.Cover.ExtraLarge, ShouldEqual, BaseURL+"/covers/chainsaw-man.png")
+			})
+
+			Convey("Then searching again should give the same mangas", func() {
+				again, err := mock.Search("chainsaw man")
+				So(err, ShouldBeNil)
+				So(len(again), ShouldEqual, len(mangas))
+				So(again[len(again)-1].ID, ShouldEqual, mangas[len(mangas)-1].ID)
+			})
+
+			Convey("When getting the chapters of the first manga", func() {
+				chapters, err := mock.ChaptersOf(mangas[0])
+				So(err, ShouldBeNil)
+
+				Convey("Then they should be numbered and grouped by volumes", func() {
+					So(len(chapters), ShouldBeBetweenOrEqual, 5, 50)
+					for i, chapter := range chapters {
+						So(chapter.Index, ShouldEqual, i+1)
+						So(chapter.Manga, ShouldEqual, mangas[0])
+					}
+
+					So(chapters[0].Volume, ShouldEqual, "Vol.1")
+				})
+
+				Convey("When getting the pages of the first chapter", func() {
+					pages, err := mock.PagesOf(chapters[0])
+					So(err, ShouldBeNil)
+					So(len(pages), ShouldBeBetweenOrEqual, 4, 20)
+
+					Convey("Then they should be downloaded without the network", func() {
+						So(pages[0].DownloadContext(context.Background()), ShouldBeNil)
+
+						img, err := png.Decode(pages[0].Contents)
+						So(err, ShouldBeNil)
+						So(img.Bounds().Dx(), ShouldEqual, pageWidth)
+						So(img.Bounds().Dy(), ShouldEqual, pageHeight)
+					})
+				})
+			})
+		})
+
+		Convey("When searching for nothing", func() {
+			mangas, err := mock.Search("  ")
+
+			Convey("Then nothing should be found", func() {
+				So(err, ShouldBeNil)
+				So(mangas, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestImages(t *testing.T) {
+	Convey("When requesting the link that is not an image", t, func() {
+		req, err := http.NewRequest(http.MethodGet, BaseURL+"/pages/kek/one/1.png", nil)
+		So(err, ShouldBeNil)
+
+		resp, err := images{}.RoundTrip(req)
+
+		Convey("Then it should not be found", func() {
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+	})
+
+	Convey("When asking for the size of the cover", t, func() {
+		req, err := http.NewRequest(http.MethodHead, BaseURL+"/covers/kek.png", nil)
+		So(err, ShouldBeNil)
+
+		resp, err := images{}.RoundTrip(req)
+
+		Convey("Then the size should be known", func() {
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(resp.ContentLength, ShouldBeGreaterThan, 0)
+			So(resp.Header.Get("Content-Type"), ShouldEqual, "image/png")
+		})
+	})
+}
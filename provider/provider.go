@@ -1,14 +1,19 @@
 package provider
 
 import (
+	"fmt"
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/provider/custom"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
 	"github.com/metafates/mangal/where"
 	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Provider struct {
@@ -16,6 +21,14 @@ type Provider struct {
 	Name         string
 	UsesHeadless bool
 	IsCustom     bool
+	// BaseURL of the site, used to recognize its links.
+	// Empty for the custom providers, since it is not known without loading them
+	BaseURL string
+	// Capabilities of the source, unknown for the custom providers
+	Capabilities Capabilities
+	// EnableKey is the config key that must be set for the provider to be listed by Builtins,
+	// empty if it is always listed. Get and GetByID find it anyway
+	EnableKey    string
 	CreateSource func() (source.Source, error)
 }
 
@@ -23,8 +36,11 @@ func (p Provider) String() string {
 	return p.Name
 }
 
+// Builtins returns the registered builtin providers that are enabled, see Register
 func Builtins() []*Provider {
-	return builtinProviders
+	return lo.Filter(builtins.providers, func(provider *Provider, _ int) bool {
+		return provider.EnableKey == "" || viper.GetBool(provider.EnableKey)
+	})
 }
 
 func Customs() []*Provider {
@@ -71,17 +87,83 @@ func Customs() []*Provider {
 }
 
 func Get(name string) (*Provider, bool) {
-	for _, provider := range Builtins() {
+	if provider, ok := builtins.byName[name]; ok {
+		return provider, true
+	}
+
+	for _, provider := range Customs() {
 		if provider.Name == name {
 			return provider, true
 		}
 	}
 
+	return nil, false
+}
+
+// Priority returns the providers of the sources to fall back to, in their order, see key.SourcesPriority.
+// Sources are given by their names or IDs
+func Priority() ([]*Provider, error) {
+	var providers []*Provider
+	for _, name := range viper.GetStringSlice(key.SourcesPriority) {
+		p, ok := Get(name)
+		if !ok {
+			if p, ok = GetByID(name); !ok {
+				return nil, fmt.Errorf("source not found: %s", name)
+			}
+		}
+
+		if !lo.Contains(providers, p) {
+			providers = append(providers, p)
+		}
+	}
+
+	return providers, nil
+}
+
+// GetByID returns the provider with the given source ID
+func GetByID(id string) (*Provider, bool) {
+	if provider, ok := builtins.byID[id]; ok {
+		return provider, true
+	}
+
 	for _, provider := range Customs() {
-		if provider.Name == name {
+		if provider.ID == id {
 			return provider, true
 		}
 	}
 
 	return nil, false
 }
+
+// ByURL returns the builtin provider of the site that the link belongs to.
+// Sites are matched by their domains, so that the links of the mirrors on the other subdomains are recognized too
+func ByURL(link string) (*Provider, bool) {
+	host := hostOf(link)
+	if host == "" {
+		return nil, false
+	}
+
+	return lo.Find(Builtins(), func(provider *Provider) bool {
+		site := hostOf(provider.BaseURL)
+		if site == "" {
+			return false
+		}
+
+		// keep the domain only, e.g. manganelo.tv for ww5.manganelo.tv
+		if labels := strings.Split(site, "."); len(labels) > 2 {
+			site = strings.Join(labels[len(labels)-2:], ".")
+		}
+
+		return host == site || strings.HasSuffix(host, "."+site)
+	})
+}
+
+// hostOf returns the lowercase host of the link, empty if it is not a link
+func hostOf(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(parsed.Hostname())
+}
@@ -1,8 +1,15 @@
 package provider
 
 import (
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider/lezhin"
+	"github.com/metafates/mangal/provider/mangadex"
 	"github.com/metafates/mangal/provider/manganelo"
+	"github.com/metafates/mangal/provider/mock"
+	"github.com/samber/lo"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
 	"testing"
 )
 
@@ -21,3 +28,151 @@ func TestGet(t *testing.T) {
 		})
 	})
 }
+
+func TestPriority(t *testing.T) {
+	Convey("Given the priority of the sources by their names and IDs", t, func() {
+		viper.Set(key.SourcesPriority, []string{manganelo.Config.Name, mangadex.ID, manganelo.Config.Name})
+		defer viper.Set(key.SourcesPriority, []string{})
+
+		Convey("Then the providers should be in its order, without the duplicates", func() {
+			providers, err := Priority()
+			So(err, ShouldBeNil)
+			So(providers, ShouldHaveLength, 2)
+			So(providers[0].Name, ShouldEqual, manganelo.Config.Name)
+			So(providers[1].ID, ShouldEqual, mangadex.ID)
+		})
+
+		Convey("Then the unknown sources should fail", func() {
+			viper.Set(key.SourcesPriority, []string{"kek"})
+			_, err := Priority()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestByURL(t *testing.T) {
+	Convey("When getting the provider of a link of its site", t, func() {
+		p, ok := ByURL("https://manganelo.tv/manga/berserk")
+		Convey("Then it should be found by the domain", func() {
+			So(ok, ShouldBeTrue)
+			So(p.Name, ShouldEqual, manganelo.Config.Name)
+		})
+	})
+
+	Convey("When getting the provider of a link of an unknown site", t, func() {
+		_, ok := ByURL("https://example.com/manga/berserk")
+		Convey("Then ok should be false", func() {
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("When getting the provider of a text that is not a link", t, func() {
+		_, ok := ByURL("berserk")
+		Convey("Then ok should be false", func() {
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestQueryOf(t *testing.T) {
+	Convey("When guessing the title from the links", t, func() {
+		Convey("Then the IDs and the common words should be skipped", func() {
+			So(QueryOf("https://www.webtoons.com/en/fantasy/tower-of-god/list?title_no=95"), ShouldEqual, "tower of god")
+			So(QueryOf("https://mangapill.com/manga/2/berserk"), ShouldEqual, "berserk")
+			So(QueryOf("https://mangadex.org/title/801513ba-a712-498c-8f57-cae55b38cc92/berserk"), ShouldEqual, "berserk")
+		})
+	})
+}
+
+func TestSameLink(t *testing.T) {
+	Convey("When comparing the links of the same page", t, func() {
+		Convey("Then the scheme, www and the trailing slash should be ignored", func() {
+			So(SameLink("https://www.mangapill.com/manga/2/berserk/", "http://mangapill.com/manga/2/berserk"), ShouldBeTrue)
+			So(SameLink("https://mangapill.com/manga/2/berserk", "https://mangapill.com/manga/3/berserk"), ShouldBeFalse)
+		})
+	})
+}
+
+func TestChapterByLink(t *testing.T) {
+	Convey("Given the mangas found by the chapter link", t, func() {
+		mangas := lo.Must(mock.New().Search("Berserk"))
+		link := mock.BaseURL + "/chapter/berserk/2"
+
+		Convey("When looking for the chapter", func() {
+			manga, chapters, err := ChapterByLink(mangas, link)
+
+			Convey("Then its manga and chapters should be returned", func() {
+				So(err, ShouldBeNil)
+				So(manga.Name, ShouldEqual, "Berserk")
+				So(chapters, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When the chapter is of none of them", func() {
+			_, _, err := ChapterByLink(mangas, mock.BaseURL+"/chapter/vagabond/2")
+
+			Convey("Then it should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	Convey("When getting the builtin providers", t, func() {
+		Convey("Then they should be found by their names and IDs", func() {
+			for _, builtin := range Builtins() {
+				byName, ok := Get(builtin.Name)
+				So(ok, ShouldBeTrue)
+				So(byName, ShouldEqual, builtin)
+
+				byID, ok := GetByID(builtin.ID)
+				So(ok, ShouldBeTrue)
+				So(byID, ShouldEqual, builtin)
+			}
+		})
+	})
+
+	Convey("Given the builtin provider with the languages", t, func() {
+		Convey("Then they should be allowed for its language key", func() {
+			So(config.Allowed[key.LezhinLanguage], ShouldResemble, lezhin.Languages)
+		})
+	})
+
+	Convey("When registering the provider with a taken name", t, func() {
+		Convey("Then it should panic", func() {
+			So(func() {
+				Register(&Provider{ID: "kek", Name: manganelo.Config.Name})
+			}, ShouldPanic)
+		})
+	})
+}
+
+func TestCapabilities(t *testing.T) {
+	Convey("Given the capabilities of a source with the languages", t, func() {
+		capabilities := Capabilities{
+			LanguageKey: "source.language",
+			Languages:   []string{"en", "ko"},
+			AuthKeys:    []string{"source.email", "source.password"},
+		}
+
+		Convey("Then only the listed languages should be supported", func() {
+			So(capabilities.SupportsLanguage("EN"), ShouldBeTrue)
+			So(capabilities.SupportsLanguage("ja"), ShouldBeFalse)
+		})
+
+		Convey("Then they should be listed", func() {
+			So(capabilities.String(), ShouldEqual, "languages (en, ko), sign in")
+		})
+	})
+
+	Convey("Given the capabilities of a source without the languages", t, func() {
+		capabilities := Capabilities{}
+
+		Convey("Then no language should be supported", func() {
+			So(capabilities.HasLanguages(), ShouldBeFalse)
+			So(capabilities.SupportsLanguage("en"), ShouldBeFalse)
+			So(capabilities.String(), ShouldBeEmpty)
+		})
+	})
+}
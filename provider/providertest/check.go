@@ -0,0 +1,261 @@
+package providertest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	record = flag.Bool("record", false, "record the fixtures of the providers from their sites")
+	update = flag.Bool("update", false, "rewrite the golden files of the providers from their fixtures")
+)
+
+// Check searches for the query with the source made by create, lists the chapters of the first manga found
+// and the pages of its first chapter, and compares them with the golden file of the testdata directory.
+// Nothing is sent to the network, the requests are answered with the fixtures.
+// The source is made after the fixtures are set up, so that its transports use them.
+//
+// With -record the fixtures are recorded from the site and the golden file is rewritten,
+// with -update only the golden file is rewritten
+func Check(dir, query string, create func() source.Source) error {
+	if *record {
+		return Record(dir, query, create)
+	}
+
+	golden, err := LoadGolden(dir)
+	if err != nil {
+		return err
+	}
+
+	exchanges, err := exchangesOf(dir, golden.Fixtures)
+	if err != nil {
+		return err
+	}
+
+	stop := network.Replay(exchanges)
+	defer stop()
+
+	actual := &Golden{Query: query, Fixtures: golden.Fixtures}
+	if err = run(actual, create()); err != nil {
+		return err
+	}
+
+	if *update {
+		return actual.Save(dir)
+	}
+
+	return compare(golden, actual)
+}
+
+// Record runs the source made by create against its site, see Check,
+// and saves the responses as the fixtures of the testdata directory with the golden file
+func Record(dir, query string, create func() source.Source) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	var (
+		mutex  sync.Mutex
+		step   string
+		count  int
+		failed error
+		golden = &Golden{Query: query}
+	)
+
+	stop := network.Record(func(exchange *network.Exchange) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if exchange.Error != "" {
+			failed = fmt.Errorf("%s %s: %s", exchange.Method, exchange.URL, exchange.Error)
+			return
+		}
+
+		count++
+		contentType := exchange.Header.Get("Content-Type")
+		fixture := &Fixture{
+			URL:         exchange.URL,
+			File:        fmt.Sprintf("%s-%d%s", step, count, extensionOf(contentType)),
+			ContentType: contentType,
+			Location:    exchange.Header.Get("Location"),
+		}
+
+		if exchange.Method != http.MethodGet {
+			fixture.Method = exchange.Method
+		}
+
+		if exchange.Status != http.StatusOK {
+			fixture.Status = exchange.Status
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, fixture.File), exchange.Body, os.ModePerm); err != nil {
+			failed = err
+		}
+
+		golden.Fixtures = append(golden.Fixtures, fixture)
+	})
+
+	defer stop()
+
+	golden.step = func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		step, count = name, 0
+	}
+
+	if err := run(golden, create()); err != nil {
+		return err
+	}
+
+	if failed != nil {
+		return failed
+	}
+
+	return golden.Save(dir)
+}
+
+func (g *Golden) startStep(name string) {
+	if g.step != nil {
+		g.step(name)
+	}
+}
+
+// run fills the golden with the results of the source
+func run(golden *Golden, src source.Source) error {
+	golden.startStep("search")
+	mangas, err := src.Search(golden.Query)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	if len(mangas) == 0 {
+		return fmt.Errorf("search: nothing found for %q", golden.Query)
+	}
+
+	golden.Mangas = lo.Map(mangas, func(manga *source.Manga, _ int) *Manga {
+		return mangaOf(manga)
+	})
+
+	golden.startStep("chapters")
+	chapters, err := src.ChaptersOf(mangas[0])
+	if err != nil {
+		return fmt.Errorf("chapters of %s: %w", mangas[0].Name, err)
+	}
+
+	golden.Chapters = lo.Map(chapters, func(chapter *source.Chapter, _ int) *Chapter {
+		return chapterOf(chapter)
+	})
+
+	if len(chapters) == 0 {
+		return nil
+	}
+
+	golden.startStep("pages")
+	pages, err := src.PagesOf(chapters[0])
+	if err != nil {
+		return fmt.Errorf("pages of %s: %w", chapters[0].Name, err)
+	}
+
+	golden.Pages = lo.Map(pages, func(page *source.Page, _ int) *Page {
+		return pageOf(page)
+	})
+
+	return nil
+}
+
+// exchangesOf reads the bodies of the fixtures
+func exchangesOf(dir string, fixtures []*Fixture) ([]*network.Exchange, error) {
+	exchanges := make([]*network.Exchange, len(fixtures))
+	for i, fixture := range fixtures {
+		exchange := &network.Exchange{
+			Method: lo.Ternary(fixture.Method == "", http.MethodGet, fixture.Method),
+			URL:    fixture.URL,
+			Status: lo.Ternary(fixture.Status == 0, http.StatusOK, fixture.Status),
+			Header: make(http.Header),
+		}
+
+		if fixture.File != "" {
+			body, err := os.ReadFile(filepath.Join(dir, fixture.File))
+			if err != nil {
+				return nil, err
+			}
+
+			exchange.Body = body
+		}
+
+		contentType := fixture.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(fixture.File))
+		}
+
+		exchange.Header.Set("Content-Type", contentType)
+		if fixture.Location != "" {
+			exchange.Header.Set("Location", fixture.Location)
+		}
+
+		exchanges[i] = exchange
+	}
+
+	return exchanges, nil
+}
+
+func extensionOf(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "html"):
+		return ".html"
+	case strings.Contains(contentType, "json"):
+		return ".json"
+	case strings.Contains(contentType, "xml"):
+		return ".xml"
+	default:
+		return ".txt"
+	}
+}
+
+// compare returns the error describing the first difference of the results, nil if they are the same
+func compare(expected, actual *Golden) error {
+	if err := compareAll("mangas", expected.Mangas, actual.Mangas); err != nil {
+		return err
+	}
+
+	if err := compareAll("chapters", expected.Chapters, actual.Chapters); err != nil {
+		return err
+	}
+
+	return compareAll("pages", expected.Pages, actual.Pages)
+}
+
+func compareAll[T any](what string, expected, actual []T) error {
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		if !reflect.DeepEqual(expected[i], actual[i]) {
+			return fmt.Errorf("%s[%d]: expected %s, got %s", what, i, asJSON(expected[i]), asJSON(actual[i]))
+		}
+	}
+
+	if len(expected) != len(actual) {
+		return fmt.Errorf("%s: expected %d, got %d", what, len(expected), len(actual))
+	}
+
+	return nil
+}
+
+func asJSON(v any) string {
+	contents, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(contents)
+}
@@ -0,0 +1,55 @@
+package providertest
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	Convey("Given the golden results of a provider", t, func() {
+		expected := &Golden{
+			Mangas:   []*Manga{{Name: "Death Note", URL: "https://example.com/death-note", ID: "death-note"}},
+			Chapters: []*Chapter{{Name: "Chapter 1", Index: 1}, {Name: "Chapter 2", Index: 2}},
+		}
+
+		Convey("When the results are the same", func() {
+			actual := &Golden{
+				Mangas:   []*Manga{{Name: "Death Note", URL: "https://example.com/death-note", ID: "death-note"}},
+				Chapters: []*Chapter{{Name: "Chapter 1", Index: 1}, {Name: "Chapter 2", Index: 2}},
+			}
+
+			Convey("Then there should be no difference", func() {
+				So(compare(expected, actual), ShouldBeNil)
+			})
+		})
+
+		Convey("When a chapter has changed", func() {
+			actual := &Golden{
+				Mangas:   expected.Mangas,
+				Chapters: []*Chapter{{Name: "Chapter 1", Index: 1}, {Name: "Chapter 2 ", Index: 2}},
+			}
+
+			Convey("Then the difference should point to it", func() {
+				err := compare(expected, actual)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "chapters[1]: ")
+			})
+		})
+
+		Convey("When the chapters are missing", func() {
+			actual := &Golden{Mangas: expected.Mangas}
+
+			Convey("Then the difference should be their number", func() {
+				So(compare(expected, actual), ShouldBeError, "chapters: expected 2, got 0")
+			})
+		})
+	})
+}
+
+func TestExtensionOf(t *testing.T) {
+	Convey("Then the fixtures should be named after their content types", t, func() {
+		So(extensionOf("text/html; charset=UTF-8"), ShouldEqual, ".html")
+		So(extensionOf("application/json"), ShouldEqual, ".json")
+		So(extensionOf(""), ShouldEqual, ".txt")
+	})
+}
@@ -0,0 +1,138 @@
+// Package providertest checks the providers against the recorded responses of their sites,
+// so that the broken selectors are caught by go test instead of by the users.
+//
+// Each provider keeps its fixtures in the testdata directory of its package:
+// the bodies of the responses and golden.json with the links they were recorded from
+// and the mangas, the chapters and the pages expected from them, see Check.
+//
+// To add the fixtures of a provider, call Check from its tests and run them once with -record.
+// After the provider is changed, run them with -update to rewrite the expected results from the fixtures
+package providertest
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/source"
+	"os"
+	"path/filepath"
+)
+
+// GoldenFilename is the name of the golden file inside the testdata directory
+const GoldenFilename = "golden.json"
+
+// Golden is the recorded run of the provider: the search, the chapters of the first manga
+// and the pages of the first chapter
+type Golden struct {
+	Query string `json:"query"`
+	// Fixtures are the recorded responses, in the order they were recorded
+	Fixtures []*Fixture `json:"fixtures"`
+	Mangas   []*Manga   `json:"mangas"`
+	Chapters []*Chapter `json:"chapters"`
+	Pages    []*Page    `json:"pages"`
+
+	// step is told which step of the run the requests are for, while recording
+	step func(name string)
+}
+
+// Fixture is the recorded response to the request
+type Fixture struct {
+	Method string `json:"method,omitempty"`
+	// URL of the request, without the credentials
+	URL string `json:"url"`
+	// File with the body of the response, relative to the testdata directory
+	File string `json:"file,omitempty"`
+	// Status of the response, 200 if not set
+	Status int `json:"status,omitempty"`
+	// ContentType of the response, guessed from the extension of the file if not set
+	ContentType string `json:"content_type,omitempty"`
+	// Location the response redirects to
+	Location string `json:"location,omitempty"`
+}
+
+// Manga is the part of source.Manga extracted by the providers
+type Manga struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+	Cover string `json:"cover,omitempty"`
+	// Language of the manga, if the provider sets it
+	Language string `json:"language,omitempty"`
+}
+
+// Chapter is the part of source.Chapter extracted by the providers
+type Chapter struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	ID     string `json:"id"`
+	Index  int    `json:"index"`
+	Volume string `json:"volume,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// Page is the part of source.Page extracted by the providers
+type Page struct {
+	URL       string `json:"url"`
+	Index     int    `json:"index"`
+	Extension string `json:"extension"`
+}
+
+func mangaOf(manga *source.Manga) *Manga {
+	return &Manga{
+		Name:     manga.Name,
+		URL:      manga.URL,
+		ID:       manga.ID,
+		Index:    manga.Index,
+		Cover:    manga.Metadata.Cover.ExtraLarge,
+		Language: manga.Metadata.Language,
+	}
+}
+
+func chapterOf(chapter *source.Chapter) *Chapter {
+	return &Chapter{
+		Name:   chapter.Name,
+		URL:    chapter.URL,
+		ID:     chapter.ID,
+		Index:  chapter.Index,
+		Volume: chapter.Volume,
+		Title:  chapter.Title,
+		Notes:  chapter.Notes,
+	}
+}
+
+func pageOf(page *source.Page) *Page {
+	return &Page{
+		URL:       page.URL,
+		Index:     page.Index,
+		Extension: page.Extension,
+	}
+}
+
+// LoadGolden reads the golden file of the testdata directory
+func LoadGolden(dir string) (*Golden, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, GoldenFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	var golden Golden
+	if err = json.Unmarshal(contents, &golden); err != nil {
+		return nil, err
+	}
+
+	return &golden, nil
+}
+
+// Save writes the golden file to the testdata directory
+func (g *Golden) Save(dir string) error {
+	contents, err := json.MarshalIndent(g, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, GoldenFilename), append(contents, '\n'), os.ModePerm)
+}
@@ -0,0 +1,58 @@
+package rawkuma
+
+import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/provider/generic"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var Config = &generic.Configuration{
+	Name:            "Rawkuma",
+	Delay:           50 * time.Millisecond,
+	Parallelism:     50,
+	ReverseChapters: true,
+	Language:        constant.LanguageRaw,
+	BaseURL:         "https://rawkuma.com",
+	GenerateSearchURL: func(query string) string {
+		query = strings.TrimSpace(query)
+		template := "https://rawkuma.com/?s=%s"
+		return fmt.Sprintf(template, url.QueryEscape(query))
+	},
+	MangaExtractor: &generic.Extractor{
+		Selector: "div.listupd div.bsx > a",
+		Name: func(selection *goquery.Selection) string {
+			return strings.TrimSpace(selection.AttrOr("title", selection.Find(".tt").Text()))
+		},
+		URL: func(selection *goquery.Selection) string {
+			return selection.AttrOr("href", "")
+		},
+		Volume: func(selection *goquery.Selection) string {
+			return ""
+		},
+		Cover: func(selection *goquery.Selection) string {
+			return selection.Find("img").AttrOr("src", "")
+		},
+	},
+	ChapterExtractor: &generic.Extractor{
+		Selector: "#chapterlist li .eph-num a",
+		Name: func(selection *goquery.Selection) string {
+			return strings.TrimSpace(selection.Find(".chapternum").Text())
+		},
+		URL: func(selection *goquery.Selection) string {
+			return selection.AttrOr("href", "")
+		},
+		Volume: func(selection *goquery.Selection) string {
+			return ""
+		},
+	},
+	PageExtractor: &generic.Extractor{
+		Selector: "#readerarea img",
+		URL: func(selection *goquery.Selection) string {
+			return strings.TrimSpace(selection.AttrOr("src", ""))
+		},
+	},
+}
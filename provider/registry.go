@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/config"
+	"strings"
+)
+
+// Capabilities of the source beyond searching and reading,
+// so that the TUI and the commands offer only what the source supports
+type Capabilities struct {
+	// LanguageKey is the config key with the language of the mangas, empty if it can't be chosen
+	LanguageKey string
+	// Languages that can be set to the LanguageKey, any language code if empty
+	Languages []string
+	// AuthKeys are the config keys of the account, needed to read the locked chapters
+	AuthKeys []string
+}
+
+// HasLanguages reports whether the language of the mangas can be chosen
+func (c Capabilities) HasLanguages() bool {
+	return c.LanguageKey != ""
+}
+
+// HasAuth reports whether the source can sign in to the account
+func (c Capabilities) HasAuth() bool {
+	return len(c.AuthKeys) > 0
+}
+
+// SupportsLanguage reports whether the language can be chosen for the source
+func (c Capabilities) SupportsLanguage(language string) bool {
+	if !c.HasLanguages() {
+		return false
+	}
+
+	if len(c.Languages) == 0 {
+		return true
+	}
+
+	for _, supported := range c.Languages {
+		if strings.EqualFold(supported, language) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String lists the capabilities, e.g. "languages (en, ko, ja), sign in"
+func (c Capabilities) String() string {
+	var capabilities []string
+
+	if c.HasLanguages() {
+		if len(c.Languages) == 0 {
+			capabilities = append(capabilities, "languages")
+		} else {
+			capabilities = append(capabilities, fmt.Sprintf("languages (%s)", strings.Join(c.Languages, ", ")))
+		}
+	}
+
+	if c.HasAuth() {
+		capabilities = append(capabilities, "sign in")
+	}
+
+	return strings.Join(capabilities, ", ")
+}
+
+// registry of the builtin providers, in the order they were registered
+type registry struct {
+	providers []*Provider
+	byName    map[string]*Provider
+	byID      map[string]*Provider
+}
+
+var builtins = &registry{
+	byName: make(map[string]*Provider),
+	byID:   make(map[string]*Provider),
+}
+
+// Register adds the builtin provider, the languages it supports become the allowed values of its language key.
+// It panics if the provider with the same name or ID is registered already
+func Register(provider *Provider) {
+	if _, ok := builtins.byName[provider.Name]; ok {
+		panic("provider " + provider.Name + " is registered twice")
+	}
+
+	if _, ok := builtins.byID[provider.ID]; ok {
+		panic("provider " + provider.ID + " is registered twice")
+	}
+
+	builtins.providers = append(builtins.providers, provider)
+	builtins.byName[provider.Name] = provider
+	builtins.byID[provider.ID] = provider
+
+	if c := provider.Capabilities; c.HasLanguages() && len(c.Languages) > 0 {
+		config.Allow(c.LanguageKey, c.Languages)
+	}
+}
@@ -0,0 +1,102 @@
+package tapas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"strings"
+)
+
+type episodesResponse struct {
+	Data struct {
+		Body       string `json:"body"`
+		Pagination struct {
+			HasNext bool `json:"has_next"`
+		} `json:"pagination"`
+	} `json:"data"`
+}
+
+// seriesID resolves the numeric id of the series, which is required by the episodes endpoint.
+func (t *Tapas) seriesID(manga *source.Manga) (string, error) {
+	if manga.ID != "" {
+		return manga.ID, nil
+	}
+
+	doc, err := t.document(context.Background(), manga.URL)
+	if err != nil {
+		return "", err
+	}
+
+	id := doc.Find("[data-series-id]").First().AttrOr("data-series-id", "")
+	if id == "" {
+		return "", errors.New("series id not found")
+	}
+
+	manga.ID = id
+	return id, nil
+}
+
+// ChaptersOf given source.Manga.
+// Locked episodes are listed as well, downloading them will fail unless they are unlocked.
+func (t *Tapas) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	cacheKey := memo.MangaKey(t.ID(), manga)
+	if chapters, ok := t.chapters.Get(cacheKey); ok {
+		return chapters, nil
+	}
+
+	id, err := t.seriesID(manga)
+	if err != nil {
+		return nil, err
+	}
+
+	manga.Metadata.LongStrip = true
+
+	var chapters []*source.Chapter
+
+	for page := 1; ; page++ {
+		address := fmt.Sprintf("%s/series/%s/episodes?page=%d&sort=OLDEST&max_limit=50", BaseURL, id, page)
+		body, err := t.get(context.Background(), address, map[string]string{
+			"Accept":           "application/json",
+			"X-Requested-With": "XMLHttpRequest",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var episodes episodesResponse
+		err = json.NewDecoder(body).Decode(&episodes)
+		util.Ignore(body.Close)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(episodes.Data.Body))
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Find("li[data-id]").Each(func(_ int, selection *goquery.Selection) {
+			chapters = append(chapters, &source.Chapter{
+				Name:  strings.TrimSpace(selection.Find(".info__title").First().Text()),
+				URL:   BaseURL + "/episode/" + selection.AttrOr("data-id", ""),
+				Index: len(chapters) + 1,
+				ID:    selection.AttrOr("data-id", ""),
+				Manga: manga,
+				Pages: make([]*source.Page, 0),
+			})
+		})
+
+		if !episodes.Data.Pagination.HasNext {
+			break
+		}
+	}
+
+	manga.Chapters = chapters
+	t.chapters.Set(cacheKey, chapters)
+	return chapters, nil
+}
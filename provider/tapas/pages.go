@@ -0,0 +1,56 @@
+package tapas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"strings"
+)
+
+// PagesOf given source.Chapter.
+// Only episodes that are free or unlocked by the logged-in user can be downloaded.
+func (t *Tapas) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	doc, err := t.document(context.Background(), chapter.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	images := doc.Find("img.content__img")
+	if images.Length() == 0 {
+		if doc.Find(".js-episode-unlock, .viewer-locked, .episode-unlock").Length() > 0 {
+			if viper.GetString(key.TapasEmail) == "" {
+				return nil, fmt.Errorf("episode %q is locked, set %s and %s to download unlocked episodes", chapter.Name, key.TapasEmail, key.TapasPassword)
+			}
+
+			return nil, fmt.Errorf("episode %q is locked, unlock it on tapas first", chapter.Name)
+		}
+
+		return nil, errors.New("there were no pages for this chapter")
+	}
+
+	pages := make([]*source.Page, 0, images.Length())
+	images.Each(func(i int, selection *goquery.Selection) {
+		link := selection.AttrOr("data-src", selection.AttrOr("src", ""))
+		if link == "" {
+			return
+		}
+
+		// remove some query params from the extension
+		ext := strings.Split(filepath.Ext(link), "?")[0]
+
+		pages = append(pages, &source.Page{
+			URL:       link,
+			Index:     i,
+			Chapter:   chapter,
+			Extension: ext,
+		})
+	})
+
+	chapter.Pages = pages
+	return pages, nil
+}
@@ -0,0 +1,61 @@
+package tapas
+
+import (
+	"context"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"net/url"
+	"strings"
+)
+
+// Search for comics by given title
+func (t *Tapas) Search(query string) ([]*source.Manga, error) {
+	return t.SearchContext(context.Background(), query)
+}
+
+// SearchContext searches for comics by given title until the context is canceled
+func (t *Tapas) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
+	query = strings.TrimSpace(query)
+
+	cacheKey := memo.SearchKey(t.ID(), query)
+	if mangas, ok := t.mangas.Get(cacheKey); ok {
+		return mangas, nil
+	}
+
+	doc, err := t.document(ctx, fmt.Sprintf("%s/search?q=%s&t=COMICS", BaseURL, url.QueryEscape(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	var mangas []*source.Manga
+
+	doc.Find(".search-item-wrap").Each(func(_ int, selection *goquery.Selection) {
+		title := selection.Find("a.title").First()
+		link, ok := title.Attr("href")
+		if !ok {
+			return
+		}
+
+		if strings.HasPrefix(link, "/") {
+			link = BaseURL + link
+		}
+
+		manga := &source.Manga{
+			Name:     strings.TrimSpace(title.Text()),
+			URL:      link,
+			Index:    len(mangas),
+			ID:       selection.AttrOr("data-series-id", ""),
+			Chapters: make([]*source.Chapter, 0),
+			Source:   t,
+		}
+		manga.Metadata.Cover.ExtraLarge = selection.Find("img").AttrOr("src", "")
+		manga.Metadata.LongStrip = true
+
+		mangas = append(mangas, manga)
+	})
+
+	t.mangas.Set(cacheKey, mangas)
+	return mangas, nil
+}
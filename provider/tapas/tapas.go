@@ -0,0 +1,154 @@
+package tapas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	Name = "Tapas"
+	ID   = Name + " built-in"
+)
+
+// BaseURL is the address of the site
+const BaseURL = "https://tapas.io"
+
+type Tapas struct {
+	client *http.Client
+	// mutex guards loggedIn, so that concurrent requests log in only once
+	mutex    sync.Mutex
+	loggedIn bool
+	mangas   *memo.Memo[[]*source.Manga]
+	chapters *memo.Memo[[]*source.Chapter]
+}
+
+func (*Tapas) Name() string {
+	return Name
+}
+
+func (*Tapas) ID() string {
+	return ID
+}
+
+func New() *Tapas {
+	// cookiejar.New never returns an error with nil options
+	jar, _ := cookiejar.New(nil)
+
+	return &Tapas{
+		client: &http.Client{
+			Timeout:   network.Client.Timeout,
+			Transport: network.TransportFor(Name),
+			Jar:       jar,
+		},
+		mangas:   memo.New[[]*source.Manga](),
+		chapters: memo.New[[]*source.Chapter](),
+	}
+}
+
+// login signs in with the credentials from the config, if any.
+// Session cookies are kept in the client jar, so it is done only once.
+func (t *Tapas) login(ctx context.Context) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.loggedIn {
+		return nil
+	}
+
+	email, password := viper.GetString(key.TapasEmail), viper.GetString(key.TapasPassword)
+	if email == "" || password == "" {
+		return nil
+	}
+
+	log.Info("Logging in to " + Name)
+
+	form := url.Values{}
+	form.Set("email", email)
+	form.Set("password", password)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, BaseURL+"/account/authenticate", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", BaseURL+"/account/signin")
+	req.Header.Set("User-Agent", constant.UserAgent)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer util.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http error: %s", resp.Status)
+	}
+
+	// failed sign in redirects back to the sign in page
+	if strings.Contains(resp.Request.URL.Path, "signin") {
+		return errors.New("tapas login failed: check your email and password")
+	}
+
+	t.loggedIn = true
+	return nil
+}
+
+// get performs an authenticated GET request and returns the response body.
+// Caller must close it.
+func (t *Tapas) get(ctx context.Context, url string, headers map[string]string) (io.ReadCloser, error) {
+	if err := t.login(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Referer", BaseURL)
+	req.Header.Set("User-Agent", constant.UserAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		util.Ignore(resp.Body.Close)
+		return nil, fmt.Errorf("http error: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// document fetches the given url and parses it as html.
+func (t *Tapas) document(ctx context.Context, url string) (*goquery.Document, error) {
+	body, err := t.get(ctx, url, map[string]string{"Accept": "text/html"})
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(body.Close)
+	return goquery.NewDocumentFromReader(body)
+}
@@ -0,0 +1,18 @@
+package tapas
+
+import (
+	"github.com/metafates/mangal/provider/providertest"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestTapasFixtures(t *testing.T) {
+	Convey("Given the recorded responses of tapas", t, func() {
+		Convey("Then the mangas, the chapters and the pages should be extracted as before", func() {
+			So(providertest.Check("testdata", "The Beginning After the End", func() source.Source {
+				return New()
+			}), ShouldBeNil)
+		})
+	})
+}
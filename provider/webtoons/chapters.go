@@ -0,0 +1,74 @@
+package webtoons
+
+import (
+	"context"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"strings"
+)
+
+// ChaptersOf given source.Manga.
+// Episode list is paginated and sorted from the newest to the oldest.
+func (w *Webtoons) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	cacheKey := memo.MangaKey(w.ID(), manga)
+	if chapters, ok := w.chapters.Get(cacheKey); ok {
+		return chapters, nil
+	}
+
+	manga.Metadata.LongStrip = true
+
+	var (
+		chapters []*source.Chapter
+		seen     = make(map[string]struct{})
+	)
+
+	for page := 1; ; page++ {
+		doc, err := document(context.Background(), network.StageChapters, fmt.Sprintf("%s&page=%d", manga.URL, page))
+		if err != nil {
+			return nil, err
+		}
+
+		var found int
+
+		doc.Find("ul#_listUl li").Each(func(_ int, selection *goquery.Selection) {
+			id := selection.AttrOr("data-episode-no", "")
+
+			// webtoons returns the last page for the out of range page numbers
+			if _, ok := seen[id]; ok || id == "" {
+				return
+			}
+
+			seen[id] = struct{}{}
+			found++
+
+			// episodes are named by their titles
+			title := strings.TrimSpace(selection.Find(".subj span").First().Text())
+			chapters = append(chapters, &source.Chapter{
+				Name:  title,
+				URL:   selection.Find("a").AttrOr("href", ""),
+				ID:    id,
+				Title: title,
+				Manga: manga,
+				Pages: make([]*source.Page, 0),
+			})
+		})
+
+		if found == 0 {
+			break
+		}
+	}
+
+	// reverse chapters, so that they go from the oldest to the newest
+	reversed := make([]*source.Chapter, len(chapters))
+	for i, chapter := range chapters {
+		reversed[len(chapters)-i-1] = chapter
+		chapter.Index = len(chapters) - i
+	}
+
+	manga.Chapters = reversed
+	w.chapters.Set(cacheKey, reversed)
+	return reversed, nil
+}
@@ -0,0 +1,50 @@
+package webtoons
+
+import (
+	"context"
+	"errors"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/source"
+	"path/filepath"
+	"strings"
+)
+
+// PagesOf given source.Chapter.
+// Motion toons (animated episodes) have no static images and are not supported.
+func (w *Webtoons) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	doc, err := document(context.Background(), network.StagePages, chapter.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	images := doc.Find("#_imageList img._images")
+	if images.Length() == 0 {
+		if doc.Find("#ozViewer, ._motiontoonViewer").Length() > 0 {
+			return nil, errors.New("motion toon episodes are not supported")
+		}
+
+		return nil, errors.New("there were no pages for this chapter")
+	}
+
+	pages := make([]*source.Page, 0, images.Length())
+	images.Each(func(i int, selection *goquery.Selection) {
+		link := selection.AttrOr("data-url", "")
+		if link == "" {
+			return
+		}
+
+		// remove some query params from the extension
+		ext := strings.Split(filepath.Ext(link), "?")[0]
+
+		pages = append(pages, &source.Page{
+			URL:       link,
+			Index:     i,
+			Chapter:   chapter,
+			Extension: ext,
+		})
+	})
+
+	chapter.Pages = pages
+	return pages, nil
+}
@@ -0,0 +1,70 @@
+package webtoons
+
+import (
+	"context"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"net/url"
+	"strings"
+)
+
+// Search for originals and canvas series by given title
+func (w *Webtoons) Search(query string) ([]*source.Manga, error) {
+	return w.SearchContext(context.Background(), query)
+}
+
+// SearchContext searches for originals and canvas series by given title until the context is canceled
+func (w *Webtoons) SearchContext(ctx context.Context, query string) ([]*source.Manga, error) {
+	query = strings.TrimSpace(query)
+
+	cacheKey := memo.SearchKey(w.ID(), query)
+	if mangas, ok := w.mangas.Get(cacheKey); ok {
+		return mangas, nil
+	}
+
+	var mangas []*source.Manga
+
+	// originals and canvas (challenge) series are listed separately
+	for _, searchType := range []string{"WEBTOON", "CHALLENGE"} {
+		address := fmt.Sprintf("%s/en/search?keyword=%s&searchType=%s", BaseURL, url.QueryEscape(query), searchType)
+		doc, err := document(ctx, network.StageSearch, address)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Find("ul.card_lst li a, .challenge_lst ul li a").Each(func(_ int, selection *goquery.Selection) {
+			link, ok := selection.Attr("href")
+			if !ok {
+				return
+			}
+
+			if strings.HasPrefix(link, "/") {
+				link = BaseURL + link
+			}
+
+			parsed, err := url.Parse(link)
+			if err != nil {
+				return
+			}
+
+			manga := &source.Manga{
+				Name:     strings.TrimSpace(selection.Find(".subj").First().Text()),
+				URL:      link,
+				Index:    len(mangas),
+				ID:       parsed.Query().Get("title_no"),
+				Chapters: make([]*source.Chapter, 0),
+				Source:   w,
+			}
+			manga.Metadata.Cover.ExtraLarge = selection.Find("img").AttrOr("src", "")
+			manga.Metadata.LongStrip = true
+
+			mangas = append(mangas, manga)
+		})
+	}
+
+	w.mangas.Set(cacheKey, mangas)
+	return mangas, nil
+}
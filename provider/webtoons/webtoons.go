@@ -0,0 +1,71 @@
+package webtoons
+
+import (
+	"context"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider/memo"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"net/http"
+)
+
+const (
+	Name = "Webtoons"
+	ID   = Name + " built-in"
+)
+
+// BaseURL is the address of the site
+const BaseURL = "https://www.webtoons.com"
+
+type Webtoons struct {
+	mangas   *memo.Memo[[]*source.Manga]
+	chapters *memo.Memo[[]*source.Chapter]
+}
+
+func (*Webtoons) Name() string {
+	return Name
+}
+
+func (*Webtoons) ID() string {
+	return ID
+}
+
+func New() *Webtoons {
+	return &Webtoons{
+		mangas:   memo.New[[]*source.Manga](),
+		chapters: memo.New[[]*source.Chapter](),
+	}
+}
+
+// document fetches the given url within the timeout of the stage and parses it as html.
+// Webtoons CDN and viewer pages check the referer, so it is always set to the site itself.
+func document(ctx context.Context, stage network.Stage, url string) (*goquery.Document, error) {
+	ctx, cancel := stage.Context(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Referer", BaseURL)
+	req.Header.Set("User-Agent", constant.UserAgent)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("accept-language", "en-US")
+
+	resp, err := network.StageClientFor(Name).Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %s", resp.Status)
+	}
+
+	return goquery.NewDocumentFromReader(resp.Body)
+}
@@ -0,0 +1,18 @@
+package webtoons
+
+import (
+	"github.com/metafates/mangal/provider/providertest"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestWebtoonsFixtures(t *testing.T) {
+	Convey("Given the recorded responses of webtoons", t, func() {
+		Convey("Then the mangas, the chapters and the pages should be extracted as before", func() {
+			So(providertest.Check("testdata", "Tower of God", func() source.Source {
+				return New()
+			}), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,292 @@
+// Package quota keeps the size of the library under the configured limit
+// by removing chapters that were already read.
+package quota
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/update"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Limit returns the configured maximum size of the library in bytes, 0 if there is no limit
+func Limit() (uint64, error) {
+	quota := strings.TrimSpace(viper.GetString(key.LibraryQuota))
+	if quota == "" {
+		return 0, nil
+	}
+
+	limit, err := humanize.ParseBytes(quota)
+	if err != nil {
+		return 0, fmt.Errorf("invalid library quota %q: %w", quota, err)
+	}
+
+	return limit, nil
+}
+
+// Eviction is a chapter to remove
+type Eviction struct {
+	Manga   *library.Manga
+	Chapter *library.Chapter
+	Size    uint64
+	// Read is when the manga was read last time, zero if unknown
+	Read time.Time
+	// Completed is whether the manga has ended
+	Completed bool
+}
+
+// Path returns the absolute path of the chapter
+func (e *Eviction) Path() string {
+	return filepath.Join(e.Manga.Path, e.Chapter.Path)
+}
+
+// Plan of the evictions needed to fit the library into the limit
+type Plan struct {
+	// Size of the library
+	Size uint64
+	// Limit of the library size, 0 if there is no limit
+	Limit uint64
+	// Evictions in the order they should be done, only the needed ones are included
+	Evictions []*Eviction
+}
+
+// Freed returns the total size of the evicted chapters
+func (p *Plan) Freed() (freed uint64) {
+	for _, eviction := range p.Evictions {
+		freed += eviction.Size
+	}
+
+	return
+}
+
+// Exceeded reports whether the library still exceeds the limit after the evictions
+func (p *Plan) Exceeded() bool {
+	return p.Limit > 0 && p.Size-p.Freed() > p.Limit
+}
+
+// Make plans the evictions for the library in the given directory.
+// Read chapters are taken from the given history stores, a chapter is read once it is read in every one of them,
+// e.g. by every user of the shared library of the server
+func Make(root string, stores ...*history.Store) (*Plan, error) {
+	limit, err := Limit()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Limit: limit}
+	plan.Size, err = sizeOf(root)
+	if err != nil || limit == 0 || plan.Size <= limit {
+		return plan, err
+	}
+
+	candidates, err := candidatesIn(root, stores)
+	if err != nil {
+		return nil, err
+	}
+
+	Sort(candidates, viper.GetBool(key.LibraryEvictCompletedLast))
+
+	for _, candidate := range candidates {
+		if !plan.Exceeded() {
+			break
+		}
+
+		plan.Evictions = append(plan.Evictions, candidate)
+	}
+
+	return plan, nil
+}
+
+// Apply removes the planned chapters.
+// Progress is called with the path of every removed chapter
+func (p *Plan) Apply(progress func(string)) error {
+	for _, eviction := range p.Evictions {
+		progress(eviction.Path())
+
+		err := filesystem.Api().Remove(eviction.Path())
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if err = manifest.Forget(eviction.Manga.Path, eviction.Path()); err != nil {
+			log.Warn(err)
+		}
+	}
+
+	return nil
+}
+
+// Enforce removes read chapters from the library in the given directory until it fits into the limit,
+// see Make for the chapters that are read. Does nothing if there is no limit
+func Enforce(root string, stores ...*history.Store) error {
+	if limit, err := Limit(); err != nil || limit == 0 {
+		return err
+	}
+
+	plan, err := Make(root, stores...)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Evictions) > 0 {
+		log.Infof("library exceeds the quota of %s, removing %d chapters", humanize.Bytes(plan.Limit), len(plan.Evictions))
+	}
+
+	err = plan.Apply(func(path string) {
+		log.Info("removing " + path)
+	})
+	if err != nil {
+		return err
+	}
+
+	if plan.Exceeded() {
+		log.Warnf("library still exceeds the quota of %s, there are no read chapters left to remove", humanize.Bytes(plan.Limit))
+	}
+
+	return nil
+}
+
+// Sort orders evictions so that chapters of mangas read the longest time ago go first.
+// Chapters of the same manga are ordered by index.
+// If completedLast is set, chapters of completed mangas go after all the others
+func Sort(evictions []*Eviction, completedLast bool) {
+	sort.SliceStable(evictions, func(i, j int) bool {
+		a, b := evictions[i], evictions[j]
+
+		if completedLast && a.Completed != b.Completed {
+			return !a.Completed
+		}
+
+		if !a.Read.Equal(b.Read) {
+			return a.Read.Before(b.Read)
+		}
+
+		if a.Manga.Path != b.Manga.Path {
+			return a.Manga.Path < b.Manga.Path
+		}
+
+		return a.Chapter.Index < b.Chapter.Index
+	})
+}
+
+// candidatesIn returns the chapters of the library that were read in every one of the stores
+func candidatesIn(root string, stores []*history.Store) ([]*Eviction, error) {
+	if len(stores) == 0 {
+		return nil, nil
+	}
+
+	saved := make([]map[string]*history.SavedChapter, len(stores))
+	for i, store := range stores {
+		var err error
+		if saved[i], err = store.Get(); err != nil {
+			return nil, err
+		}
+	}
+
+	mangas, err := library.In(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*Eviction
+	for _, manga := range mangas {
+		var read time.Time
+
+		// the chapters up to the one read last by everyone are read
+		lastRead := -1
+		for i, chapters := range saved {
+			last, at := lastReadOf(manga.Name, chapters)
+			if i == 0 || last < lastRead {
+				lastRead = last
+			}
+
+			if at.After(read) {
+				read = at
+			}
+		}
+
+		if lastRead < 0 {
+			continue
+		}
+
+		chapters, err := manga.Chapters()
+		if err != nil {
+			return nil, err
+		}
+
+		status, _ := update.GetStatus(manga.Path)
+
+		for _, chapter := range chapters {
+			if chapter.Index > lastRead {
+				continue
+			}
+
+			info, err := filesystem.Api().Stat(filepath.Join(manga.Path, chapter.Path))
+			if err != nil {
+				return nil, err
+			}
+
+			candidates = append(candidates, &Eviction{
+				Manga:     manga,
+				Chapter:   chapter,
+				Size:      uint64(info.Size()),
+				Read:      read,
+				Completed: status == "Ended",
+			})
+		}
+	}
+
+	return candidates, nil
+}
+
+// lastReadOf returns the highest read index of the manga and when it was read last time, -1 if it was not read
+func lastReadOf(manga string, saved map[string]*history.SavedChapter) (lastRead int, read time.Time) {
+	lastRead = -1
+	for _, chapter := range saved {
+		if !strings.EqualFold(chapter.MangaName, manga) {
+			continue
+		}
+
+		if chapter.Index > lastRead {
+			lastRead = chapter.Index
+		}
+
+		if chapter.Time.After(read) {
+			read = chapter.Time
+		}
+	}
+
+	return
+}
+
+func sizeOf(root string) (size uint64, err error) {
+	exists, err := filesystem.Api().Exists(root)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	err = filesystem.Api().Walk(root, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			size += uint64(info.Size())
+		}
+
+		return nil
+	})
+
+	return
+}
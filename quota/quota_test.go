@@ -0,0 +1,121 @@
+package quota
+
+import (
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/key"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+func TestQuota(t *testing.T) {
+	Convey("Given a library of 3 mangas with 3 chapters of 100 bytes each", t, func() {
+		root := filepath.Join("quota", "library")
+		for _, manga := range []string{"Berserk", "Naruto", "Monster"} {
+			for _, chapter := range []string{"[0001] Chapter 1", "[0002] Chapter 2", "[0003] Chapter 3"} {
+				lo.Must0(filesystem.Api().MkdirAll(filepath.Join(root, manga), os.ModePerm))
+				lo.Must0(filesystem.Api().WriteFile(filepath.Join(root, manga, chapter+".cbz"), []byte(strings.Repeat("a", 100)), os.ModePerm))
+			}
+		}
+
+		// Monster has ended
+		lo.Must0(filesystem.Api().WriteFile(filepath.Join(root, "Monster", "series.json"), []byte(`{"metadata":{"name":"Monster","status":"Ended"}}`), os.ModePerm))
+
+		// Naruto was read before Berserk, Monster was read first of all
+		store := history.NewStore(filepath.Join("quota", "history.json"))
+		now := time.Now()
+		lo.Must0(store.Put(&history.SavedChapter{MangaName: "Berserk", SourceID: "test", Index: 2, Time: now}))
+		lo.Must0(store.Put(&history.SavedChapter{MangaName: "Naruto", SourceID: "test", Index: 1, Time: now.Add(-time.Hour)}))
+		lo.Must0(store.Put(&history.SavedChapter{MangaName: "Monster", SourceID: "test", Index: 3, Time: now.Add(-2 * time.Hour)}))
+
+		defer func() {
+			_ = filesystem.Api().RemoveAll("quota")
+			viper.Set(key.LibraryQuota, "")
+			viper.Set(key.LibraryEvictCompletedLast, true)
+		}()
+
+		evicted := func(plan *Plan) []string {
+			return lo.Map(plan.Evictions, func(e *Eviction, _ int) string {
+				return filepath.Base(e.Manga.Path) + "/" + e.Chapter.Name
+			})
+		}
+
+		Convey("When there is no quota", func() {
+			plan := lo.Must(Make(root, store))
+
+			Convey("Then nothing should be evicted", func() {
+				So(plan.Evictions, ShouldBeEmpty)
+				So(plan.Exceeded(), ShouldBeFalse)
+			})
+		})
+
+		Convey("When the library exceeds the quota by 2 chapters", func() {
+			viper.Set(key.LibraryQuota, "750B")
+			plan := lo.Must(Make(root, store))
+
+			Convey("Then read chapters of the ongoing mangas read the longest time ago should go first", func() {
+				So(plan.Size, ShouldEqual, 900+len(`{"metadata":{"name":"Monster","status":"Ended"}}`))
+				So(evicted(plan), ShouldResemble, []string{"Naruto/Chapter 1", "Berserk/Chapter 1"})
+				So(plan.Exceeded(), ShouldBeFalse)
+			})
+
+			Convey("Then it should be applied", func() {
+				So(plan.Apply(func(string) {}), ShouldBeNil)
+				So(lo.Must(filesystem.Api().Exists(filepath.Join(root, "Naruto", "[0001] Chapter 1.cbz"))), ShouldBeFalse)
+				So(lo.Must(Make(root, store)).Evictions, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When completed mangas are not kept till the last", func() {
+			viper.Set(key.LibraryQuota, "750B")
+			viper.Set(key.LibraryEvictCompletedLast, false)
+			plan := lo.Must(Make(root, store))
+
+			Convey("Then the manga read the longest time ago should go first", func() {
+				So(evicted(plan), ShouldResemble, []string{"Monster/Chapter 1", "Monster/Chapter 2"})
+			})
+		})
+
+		Convey("When the quota can't be reached", func() {
+			viper.Set(key.LibraryQuota, "100B")
+			plan := lo.Must(Make(root, store))
+
+			Convey("Then all read chapters should be evicted, but not the unread ones", func() {
+				So(len(plan.Evictions), ShouldEqual, 6)
+				So(plan.Exceeded(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the library is shared with another reader", func() {
+			viper.Set(key.LibraryQuota, "100B")
+			other := history.NewStore(filepath.Join("quota", "other.json"))
+			lo.Must0(other.Put(&history.SavedChapter{MangaName: "Berserk", SourceID: "test", Index: 1, Time: now}))
+			plan := lo.Must(Make(root, store, other))
+
+			Convey("Then only the chapters read by both should be evicted", func() {
+				So(evicted(plan), ShouldResemble, []string{"Berserk/Chapter 1"})
+			})
+		})
+
+		Convey("When the quota is invalid", func() {
+			viper.Set(key.LibraryQuota, "a lot")
+			_, err := Make(root, store)
+
+			Convey("Then an error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
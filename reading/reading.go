@@ -0,0 +1,147 @@
+// Package reading implements the "plan to read" queue.
+// Queued mangas are kept separate from the library until they are promoted to it.
+package reading
+
+import (
+	"fmt"
+	"github.com/metafates/gache"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"sort"
+	"strings"
+	"time"
+)
+
+var cacher = gache.New[map[string]*Item](
+	&gache.Options{
+		Path:       where.Reading(),
+		FileSystem: &filesystem.GacheFs{},
+	},
+)
+
+// Item is a queued manga
+type Item struct {
+	Name     string    `json:"name"`
+	SourceID string    `json:"source_id"`
+	URL      string    `json:"url"`
+	ID       string    `json:"id"`
+	Added    time.Time `json:"added"`
+}
+
+func (i *Item) encode() string {
+	return fmt.Sprintf("%s (%s)", i.Name, i.SourceID)
+}
+
+func (i *Item) String() string {
+	return fmt.Sprintf("%s (%s)", i.Name, i.SourceID)
+}
+
+// NewItem creates a queue item from the search result
+func NewItem(manga *source.Manga) *Item {
+	item := &Item{
+		Name:  manga.Name,
+		URL:   manga.URL,
+		ID:    manga.ID,
+		Added: time.Now().UTC(),
+	}
+
+	if manga.Source != nil {
+		item.SourceID = manga.Source.ID()
+	}
+
+	return item
+}
+
+func get() (map[string]*Item, error) {
+	cached, expired, err := cacher.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if expired || cached == nil {
+		return make(map[string]*Item), nil
+	}
+
+	return cached, nil
+}
+
+// List returns queued mangas, oldest first
+func List() ([]*Item, error) {
+	queued, err := get()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(queued))
+	for _, item := range queued {
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Added.Before(items[j].Added)
+	})
+
+	return items, nil
+}
+
+// Add adds the item to the queue.
+// Adding the same manga again does nothing
+func Add(item *Item) error {
+	queued, err := get()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := queued[item.encode()]; ok {
+		return nil
+	}
+
+	queued[item.encode()] = item
+	return cacher.Set(queued)
+}
+
+// Remove removes the item from the queue
+func Remove(item *Item) error {
+	queued, err := get()
+	if err != nil {
+		return err
+	}
+
+	delete(queued, item.encode())
+	return cacher.Set(queued)
+}
+
+// Find returns queued mangas with the given name, case-insensitive
+func Find(name string) ([]*Item, error) {
+	items, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*Item
+	for _, item := range items {
+		if strings.EqualFold(item.Name, name) {
+			found = append(found, item)
+		}
+	}
+
+	return found, nil
+}
+
+// Promote moves the item from the queue to the library as a tracked entry.
+// Nothing is downloaded
+func Promote(item *Item) (*library.Manga, error) {
+	manga, err := library.Track(&library.Entry{
+		Name:     item.Name,
+		SourceID: item.SourceID,
+		URL:      item.URL,
+		ID:       item.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manga, Remove(item)
+}
@@ -0,0 +1,65 @@
+package reading
+
+import (
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+func TestQueue(t *testing.T) {
+	Convey("Given an empty queue", t, func() {
+		older := &Item{Name: "Berserk", SourceID: "mangadex", Added: time.Now().Add(-time.Hour)}
+		newer := &Item{Name: "Vagabond", SourceID: "manganato", Added: time.Now()}
+
+		Convey("When adding mangas", func() {
+			So(Add(newer), ShouldBeNil)
+			So(Add(older), ShouldBeNil)
+			So(Add(older), ShouldBeNil)
+
+			Convey("Then they should be listed oldest first without duplicates", func() {
+				items := lo.Must(List())
+				So(items, ShouldHaveLength, 2)
+				So(items[0].Name, ShouldEqual, "Berserk")
+				So(items[1].Name, ShouldEqual, "Vagabond")
+			})
+
+			Convey("And they should be found by name", func() {
+				So(lo.Must(Find("berserk")), ShouldHaveLength, 1)
+			})
+
+			Convey("When promoting one to the library", func() {
+				manga, err := Promote(older)
+				So(err, ShouldBeNil)
+
+				Convey("Then it should be tracked", func() {
+					entry := lo.Must(manga.Entry())
+					So(entry, ShouldNotBeNil)
+					So(entry.SourceID, ShouldEqual, "mangadex")
+
+					names := lo.Map(lo.Must(library.Mangas()), func(m *library.Manga, _ int) string {
+						return m.Name
+					})
+					So(names, ShouldContain, "Berserk")
+				})
+
+				Convey("And it should leave the queue", func() {
+					So(lo.Must(List()), ShouldHaveLength, 1)
+				})
+			})
+
+			Reset(func() {
+				_ = Remove(older)
+				_ = Remove(newer)
+			})
+		})
+	})
+}
@@ -0,0 +1,128 @@
+// Package redact removes the secrets and the name of the user from the diagnostics,
+// such as the crash reports and the support bundles, so that they can be shared
+package redact
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the secrets
+const Redacted = "REDACTED"
+
+// sensitiveFields are the config fields with the secrets, by the parts of their names
+var sensitiveFields = []string{"password", "secret", "token", "auth", "code", "email"}
+
+// Config returns the settings with the secrets redacted and the home directory replaced, see Home and Credentials
+func Config() map[string]any {
+	settings := make(map[string]any)
+
+	for _, field := range viper.AllKeys() {
+		value := viper.Get(field)
+
+		// the empty secrets are kept, to tell that they are not set
+		if Sensitive(field) {
+			if s := fmt.Sprint(value); s != "" && s != "[]" {
+				value = Redacted
+			}
+
+			settings[field] = value
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			value = Home(Credentials(v))
+		case []string:
+			value = lo.Map(v, func(s string, _ int) string {
+				return Home(Credentials(s))
+			})
+		}
+
+		settings[field] = value
+	}
+
+	return settings
+}
+
+// Sensitive reports whether the config field has a secret
+func Sensitive(field string) bool {
+	field = strings.ToLower(field)
+	return lo.ContainsBy(sensitiveFields, func(part string) bool {
+		return strings.Contains(field, part)
+	})
+}
+
+// Home replaces the home directory, which usually has the name of the user, with ~
+func Home(s string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == string(filepath.Separator) {
+		return s
+	}
+
+	return strings.ReplaceAll(s, home, "~")
+}
+
+// Credentials replaces the password of the URL, e.g. the one of the proxy
+func Credentials(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+
+	parsed, err := url.Parse(s)
+	if err != nil || parsed.User == nil {
+		return s
+	}
+
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), Redacted)
+	}
+
+	return parsed.String()
+}
+
+// sensitiveParams are the query parameters with the secrets, by the parts of their names
+var sensitiveParams = []string{"password", "secret", "token", "auth", "signature", "session", "credential"}
+
+// urlPattern matches the URLs in the text, up to the whitespace or the quotes
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+
+// URLs replaces the passwords and the sensitive query parameters of every URL in the text,
+// e.g. the tokens of the sources in the logs, see Credentials
+func URLs(s string) string {
+	return urlPattern.ReplaceAllStringFunc(s, func(link string) string {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			return link
+		}
+
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), Redacted)
+		}
+
+		query := parsed.Query()
+		changed := false
+		for param := range query {
+			name := strings.ToLower(param)
+			if name == "key" || name == "sig" || lo.ContainsBy(sensitiveParams, func(part string) bool {
+				return strings.Contains(name, part)
+			}) {
+				query.Set(param, Redacted)
+				changed = true
+			}
+		}
+
+		// the links without secrets are kept as they are
+		if changed {
+			parsed.RawQuery = query.Encode()
+		}
+
+		return parsed.String()
+	})
+}
@@ -0,0 +1,203 @@
+// Package remote is the client of the mangal server api,
+// used to control a mangal running on another host.
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/server"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client of the mangal server
+type Client struct {
+	base     *url.URL
+	user     string
+	password string
+	http     *http.Client
+	// files are pulled without the timeout, they may be large
+	files *http.Client
+}
+
+// New creates a client for the server at the given address,
+// e.g. nas:6969, alice@nas:6969 or https://alice@mangal.example.com
+func New(address string) (*Client, error) {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	base, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if base.Host == "" {
+		return nil, fmt.Errorf("invalid address %s", address)
+	}
+
+	client := &Client{
+		http:  &http.Client{Timeout: time.Minute},
+		files: &http.Client{},
+	}
+
+	if base.User != nil {
+		client.user = base.User.Username()
+		client.password, _ = base.User.Password()
+		base.User = nil
+	}
+
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	client.base = base
+
+	return client, nil
+}
+
+// User returns the name of the user to log in as, empty if not set
+func (c *Client) User() string {
+	return c.user
+}
+
+// SetPassword sets the password of the user
+func (c *Client) SetPassword(password string) {
+	c.password = password
+}
+
+// NeedsPassword reports whether the user is set without a password
+func (c *Client) NeedsPassword() bool {
+	return c.user != "" && c.password == ""
+}
+
+// String returns the address of the server, without the password
+func (c *Client) String() string {
+	return c.base.Host
+}
+
+func (c *Client) do(method, path string, query url.Values, body, response any) error {
+	resp, err := c.send(c.http, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// send sends the request with the client, the responses with the error statuses are returned as errors
+func (c *Client) send(client *http.Client, method, path string, query url.Values, body any) (*http.Response, error) {
+	endpoint := *c.base
+	endpoint.Path += path
+	endpoint.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		contents, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(contents)
+	}
+
+	request, err := http.NewRequest(method, endpoint.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.user != "" {
+		request.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+
+		var apiError struct {
+			Error string `json:"error"`
+		}
+
+		if json.NewDecoder(resp.Body).Decode(&apiError) == nil && apiError.Error != "" {
+			return nil, fmt.Errorf("%s: %s", c, apiError.Error)
+		}
+
+		return nil, fmt.Errorf("%s: %s", c, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// Me returns the logged-in user
+func (c *Client) Me() (*server.Me, error) {
+	var me server.Me
+	err := c.do(http.MethodGet, "/api/me", nil, nil, &me)
+	return &me, err
+}
+
+// Library returns the mangas of the server library
+func (c *Client) Library() ([]*server.LibraryManga, error) {
+	var mangas []*server.LibraryManga
+	err := c.do(http.MethodGet, "/api/library", nil, nil, &mangas)
+	return mangas, err
+}
+
+// LibraryChapters returns the mangas of the server library with their downloaded chapters
+func (c *Client) LibraryChapters() ([]*server.LibraryManga, error) {
+	var mangas []*server.LibraryManga
+	err := c.do(http.MethodGet, "/api/library", url.Values{"chapters": {"true"}}, nil, &mangas)
+	return mangas, err
+}
+
+// Pull writes the downloaded chapter at the path of the manga directory on the server to w
+func (c *Client) Pull(directory, path string, w io.Writer) error {
+	resp, err := c.send(c.files, http.MethodGet, "/api/files", url.Values{"directory": {directory}, "path": {path}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Chapters returns the chapters of the manga from its source
+func (c *Client) Chapters(manga *server.MangaRef) ([]*server.Chapter, error) {
+	query := url.Values{}
+	query.Set("source_id", manga.SourceID)
+	query.Set("name", manga.Name)
+	query.Set("url", manga.URL)
+	query.Set("id", manga.ID)
+
+	var chapters []*server.Chapter
+	err := c.do(http.MethodGet, "/api/chapters", query, nil, &chapters)
+	return chapters, err
+}
+
+// Downloads returns the download queue of the user
+func (c *Client) Downloads() ([]*server.Job, error) {
+	var jobs []*server.Job
+	err := c.do(http.MethodGet, "/api/downloads", nil, nil, &jobs)
+	return jobs, err
+}
+
+// Download queues the chapters to be downloaded by the server
+func (c *Client) Download(request *server.DownloadRequest) ([]*server.Job, error) {
+	var jobs []*server.Job
+	err := c.do(http.MethodPost, "/api/downloads", nil, request, &jobs)
+	return jobs, err
+}
@@ -0,0 +1,127 @@
+package remote
+
+import (
+	"bytes"
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/server"
+	"github.com/metafates/mangal/users"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+func TestNew(t *testing.T) {
+	Convey("Given an address with a user", t, func() {
+		client, err := New("alice@nas:6969")
+
+		Convey("Then the user should be parsed and the password asked for", func() {
+			So(err, ShouldBeNil)
+			So(client.User(), ShouldEqual, "alice")
+			So(client.NeedsPassword(), ShouldBeTrue)
+			So(client.String(), ShouldEqual, "nas:6969")
+			So(client.base.Scheme, ShouldEqual, "http")
+		})
+	})
+
+	Convey("Given an address without a host", t, func() {
+		_, err := New("http://")
+
+		Convey("Then it should fail", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestClient(t *testing.T) {
+	Convey("Given a server with basic auth", t, func() {
+		viper.Set(key.ServerAuth, server.AuthBasic)
+		lo.Must0(users.Add("alice", "secret"))
+		lo.Must0(filesystem.Api().MkdirAll(filepath.Join(where.Downloads(), "Berserk"), os.ModePerm))
+
+		Reset(func() {
+			viper.Set(key.ServerAuth, server.AuthNone)
+			_ = users.Remove("alice")
+		})
+
+		s, err := server.New()
+		So(err, ShouldBeNil)
+
+		httpServer := httptest.NewServer(s)
+		defer httpServer.Close()
+
+		address := strings.TrimPrefix(httpServer.URL, "http://")
+
+		Convey("When logged in with the right password", func() {
+			client := lo.Must(New("alice:secret@" + address))
+
+			Convey("Then the library should be listed", func() {
+				me, err := client.Me()
+				So(err, ShouldBeNil)
+				So(me.Name, ShouldEqual, "alice")
+
+				mangas, err := client.Library()
+				So(err, ShouldBeNil)
+				So(lo.Map(mangas, func(m *server.LibraryManga, _ int) string { return m.Name }), ShouldContain, "Berserk")
+
+				jobs, err := client.Downloads()
+				So(err, ShouldBeNil)
+				So(jobs, ShouldBeEmpty)
+			})
+
+			Convey("Then the downloaded chapters should be listed and pulled", func() {
+				path := filepath.Join(where.Downloads(), "Berserk", "[1] Chapter 1.cbz")
+				lo.Must0(filesystem.Api().WriteFile(path, []byte("pages"), os.ModePerm))
+
+				mangas, err := client.LibraryChapters()
+				So(err, ShouldBeNil)
+
+				berserk, ok := lo.Find(mangas, func(m *server.LibraryManga) bool { return m.Name == "Berserk" })
+				So(ok, ShouldBeTrue)
+				So(berserk.Chapters, ShouldHaveLength, 1)
+				So(berserk.Chapters[0].Index, ShouldEqual, 1)
+				So(berserk.Chapters[0].Size, ShouldEqual, 5)
+
+				var buf bytes.Buffer
+				So(client.Pull(berserk.Directory, berserk.Chapters[0].Path, &buf), ShouldBeNil)
+				So(buf.String(), ShouldEqual, "pages")
+
+				err = client.Pull(berserk.Directory, "../config.toml", &buf)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "not found")
+			})
+
+			Convey("Then downloads from unknown sources should be rejected", func() {
+				_, err := client.Download(&server.DownloadRequest{
+					Manga:    server.MangaRef{SourceID: "unknown", URL: "https://example.com"},
+					Chapters: []string{"https://example.com/1"},
+				})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "source unknown not found")
+			})
+		})
+
+		Convey("When logged in with a wrong password", func() {
+			client := lo.Must(New("alice@" + address))
+			client.SetPassword("guess")
+
+			Convey("Then requests should fail", func() {
+				_, err := client.Me()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, users.ErrInvalidCredentials.Error())
+			})
+		})
+	})
+}
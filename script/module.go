@@ -0,0 +1,350 @@
+package script
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/update"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	lua "github.com/yuin/gopher-lua"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	mangaType   = ModuleName + ".manga"
+	chapterType = ModuleName + ".chapter"
+)
+
+// module is the mangal module of the script.
+// Functions that can fail return nil and the error message, as io.open does
+type module struct {
+	// sources created by the script by their names and IDs
+	sources map[string]source.Source
+}
+
+func newModule() *module {
+	return &module{sources: make(map[string]source.Source)}
+}
+
+func (m *module) loader(L *lua.LState) int {
+	manga := L.NewTypeMetatable(mangaType)
+	L.SetField(manga, "__index", L.NewFunction(m.mangaIndex))
+
+	chapter := L.NewTypeMetatable(chapterType)
+	L.SetField(chapter, "__index", L.NewFunction(m.chapterIndex))
+
+	L.Push(L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"sources":  m.listSources,
+		"search":   m.search,
+		"manga":    m.manga,
+		"library":  m.library,
+		"download": m.download,
+		"convert":  m.convert,
+	}))
+
+	return 1
+}
+
+// fail pushes nil and the error message as the results of the function
+func fail(L *lua.LState, err error) int {
+	L.Push(lua.LNil)
+	L.Push(lua.LString(err.Error()))
+	return 2
+}
+
+// source returns the source by its name or ID, creating it once
+func (m *module) source(name string) (source.Source, error) {
+	if src, ok := m.sources[name]; ok {
+		return src, nil
+	}
+
+	p, ok := provider.Get(name)
+	if !ok {
+		if p, ok = provider.GetByID(name); !ok {
+			return nil, fmt.Errorf("source %s not found", name)
+		}
+	}
+
+	src, err := p.CreateSource()
+	if err != nil {
+		return nil, err
+	}
+
+	m.sources[name] = src
+	return src, nil
+}
+
+// listSources returns the names of the available sources
+func (m *module) listSources(L *lua.LState) int {
+	table := L.NewTable()
+	for _, p := range append(provider.Builtins(), provider.Customs()...) {
+		table.Append(lua.LString(p.Name))
+	}
+
+	L.Push(table)
+	return 1
+}
+
+// search(source, query) returns the mangas found by the source
+func (m *module) search(L *lua.LState) int {
+	src, err := m.source(L.CheckString(1))
+	if err != nil {
+		return fail(L, err)
+	}
+
+	mangas, err := src.Search(L.CheckString(2))
+	if err != nil {
+		return fail(L, err)
+	}
+
+	table := L.NewTable()
+	for _, manga := range mangas {
+		table.Append(newUserData(L, manga, mangaType))
+	}
+
+	L.Push(table)
+	return 1
+}
+
+// manga(source, url[, name]) returns the manga of the source by its URL, e.g. the one of the library entry
+func (m *module) manga(L *lua.LState) int {
+	src, err := m.source(L.CheckString(1))
+	if err != nil {
+		return fail(L, err)
+	}
+
+	manga := &source.Manga{
+		URL:    L.CheckString(2),
+		Name:   L.OptString(3, ""),
+		Source: src,
+	}
+
+	L.Push(newUserData(L, manga, mangaType))
+	return 1
+}
+
+// library returns the downloaded mangas with their chapters and the tracked entries
+func (m *module) library(L *lua.LState) int {
+	mangas, err := library.Mangas()
+	if err != nil {
+		return fail(L, err)
+	}
+
+	table := L.NewTable()
+	for _, manga := range mangas {
+		item := L.NewTable()
+		item.RawSetString("name", lua.LString(manga.Name))
+		item.RawSetString("path", lua.LString(manga.Path))
+
+		if entry, err := manga.Entry(); err == nil && entry != nil {
+			item.RawSetString("source", lua.LString(entry.SourceID))
+			item.RawSetString("url", lua.LString(entry.URL))
+			item.RawSetString("subscription", lua.LString(entry.Subscription.String()))
+		}
+
+		chapters := L.NewTable()
+		if downloaded, err := manga.Chapters(); err == nil {
+			for _, chapter := range downloaded {
+				c := L.NewTable()
+				c.RawSetString("name", lua.LString(chapter.Name))
+				c.RawSetString("index", lua.LNumber(chapter.Index))
+				c.RawSetString("path", lua.LString(filepath.Join(manga.Path, chapter.Path)))
+				chapters.Append(c)
+			}
+		}
+
+		item.RawSetString("chapters", chapters)
+		table.Append(item)
+	}
+
+	L.Push(table)
+	return 1
+}
+
+// download(chapter[, format]) downloads the chapter and returns its path.
+// The format, e.g. "cbz,pdf", overrides the config for this download
+func (m *module) download(L *lua.LState) int {
+	chapter := checkChapter(L, 1)
+
+	if format := L.OptString(2, ""); format != "" && chapter.Manga != nil {
+		root := where.Downloads()
+		settings := library.OverridesIn(root, chapter.Manga).Settings(root)
+		settings.Formats = source.ParseFormats(format)
+
+		defer func(previous *source.Settings) {
+			chapter.Manga.Settings = previous
+		}(chapter.Manga.Settings)
+		chapter.Manga.Settings = settings
+	}
+
+	if _, err := converter.GetAll(chapter.Formats()); err != nil {
+		return fail(L, err)
+	}
+
+	path, err := downloader.Download(chapter, func(string) {})
+	if err != nil {
+		return fail(L, err)
+	}
+
+	L.Push(lua.LString(path))
+	return 1
+}
+
+// convertable formats can be unpacked back to pages
+var convertable = []string{constant.FormatCBZ, constant.FormatZIP}
+
+// convert(path, format) converts the downloaded chapter file to the format
+// and saves it next to the original one. The path of the converted chapter is returned
+func (m *module) convert(L *lua.LState) int {
+	path, format := L.CheckString(1), L.CheckString(2)
+
+	if !lo.Contains(convertable, strings.TrimPrefix(filepath.Ext(path), ".")) {
+		return fail(L, fmt.Errorf("can't convert %s, only %s chapters can be converted", path, strings.Join(convertable, " and ")))
+	}
+
+	if format == constant.FormatPlain {
+		return fail(L, fmt.Errorf("can't convert %s to %s", path, format))
+	}
+
+	conv, err := converter.Get(format)
+	if err != nil {
+		return fail(L, err)
+	}
+
+	index, name := update.ChapterFromFilename(path)
+	chapter := &source.Chapter{
+		Name:   name,
+		Index:  index,
+		Manga:  &source.Manga{Name: filepath.Base(filepath.Dir(path))},
+		Format: format,
+	}
+	chapter.Manga.Chapters = []*source.Chapter{chapter}
+
+	if err = update.ReadPages(chapter, path); err != nil {
+		return fail(L, err)
+	}
+
+	temp, err := conv.SaveTemp(chapter)
+	if err != nil {
+		return fail(L, err)
+	}
+
+	defer func() {
+		_ = filesystem.Api().Remove(temp)
+	}()
+
+	contents, err := filesystem.Api().ReadFile(temp)
+	if err != nil {
+		return fail(L, err)
+	}
+
+	to := filepath.Join(filepath.Dir(path), util.FileStem(path)+"."+format)
+	if err = filesystem.Api().WriteFile(to, contents, os.ModePerm); err != nil {
+		return fail(L, err)
+	}
+
+	L.Push(lua.LString(to))
+	return 1
+}
+
+func newUserData(L *lua.LState, value any, typ string) *lua.LUserData {
+	ud := L.NewUserData()
+	ud.Value = value
+	L.SetMetatable(ud, L.GetTypeMetatable(typ))
+	return ud
+}
+
+func checkManga(L *lua.LState, n int) *source.Manga {
+	if manga, ok := L.CheckUserData(n).Value.(*source.Manga); ok {
+		return manga
+	}
+
+	L.ArgError(n, "manga expected")
+	return nil
+}
+
+func checkChapter(L *lua.LState, n int) *source.Chapter {
+	if chapter, ok := L.CheckUserData(n).Value.(*source.Chapter); ok {
+		return chapter
+	}
+
+	L.ArgError(n, "chapter expected")
+	return nil
+}
+
+// mangaIndex returns the fields of the manga and its chapters method
+func (m *module) mangaIndex(L *lua.LState) int {
+	manga := checkManga(L, 1)
+
+	switch field := L.CheckString(2); field {
+	case "name":
+		L.Push(lua.LString(manga.Name))
+	case "url":
+		L.Push(lua.LString(manga.URL))
+	case "index":
+		L.Push(lua.LNumber(manga.Index))
+	case "summary":
+		L.Push(lua.LString(manga.Metadata.Summary))
+	case "source":
+		L.Push(lua.LString(manga.Source.Name()))
+	case "chapters":
+		L.Push(L.NewFunction(m.chapters))
+	default:
+		L.Push(lua.LNil)
+	}
+
+	return 1
+}
+
+// chapters returns the chapters of the manga given as self
+func (m *module) chapters(L *lua.LState) int {
+	manga := checkManga(L, 1)
+
+	chapters, err := manga.Source.ChaptersOf(manga)
+	if err != nil {
+		return fail(L, err)
+	}
+
+	table := L.NewTable()
+	for _, chapter := range chapters {
+		table.Append(newUserData(L, chapter, chapterType))
+	}
+
+	L.Push(table)
+	return 1
+}
+
+// chapterIndex returns the fields of the chapter and its download method
+func (m *module) chapterIndex(L *lua.LState) int {
+	chapter := checkChapter(L, 1)
+
+	switch field := L.CheckString(2); field {
+	case "name":
+		L.Push(lua.LString(chapter.Name))
+	case "url":
+		L.Push(lua.LString(chapter.URL))
+	case "index":
+		L.Push(lua.LNumber(chapter.Index))
+	case "volume":
+		L.Push(lua.LString(chapter.Volume))
+	case "manga":
+		L.Push(newUserData(L, chapter.Manga, mangaType))
+	case "downloaded":
+		L.Push(lua.LBool(chapter.IsDownloaded()))
+	case "download":
+		L.Push(L.NewFunction(m.download))
+	default:
+		L.Push(lua.LNil)
+	}
+
+	return 1
+}
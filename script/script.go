@@ -0,0 +1,49 @@
+// Package script runs standalone Lua automation scripts, see mangal run.
+// Scripts get the mangal module that exposes searching, downloading, converting and the library:
+//
+//	local mangal = require("mangal")
+//	local mangas = mangal.search("Mangapill", "berserk")
+//	local chapters = mangas[1]:chapters()
+//	print(chapters[#chapters]:download())
+package script
+
+import (
+	libs "github.com/metafates/mangal-lua-libs"
+	"github.com/metafates/mangal/provider/custom"
+	"github.com/metafates/mangal/util"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ModuleName is the name of the module to require in the scripts
+const ModuleName = "mangal"
+
+// Run runs the Lua script at the given path.
+// Scripts that define any of the functions of the source are checked to define all of them,
+// so that custom sources can be debugged with it too, unless lenient is set
+func Run(path string, lenient bool) error {
+	proto, err := custom.Compile(path)
+	if err != nil {
+		return err
+	}
+
+	state := lua.NewState()
+	defer state.Close()
+
+	libs.Preload(state)
+	state.PreloadModule(ModuleName, newModule().loader)
+
+	state.Push(state.NewFunctionFromProto(proto))
+	if err = state.PCall(0, lua.MultRet, nil); err != nil {
+		return err
+	}
+
+	if !lenient && custom.IsSource(state) {
+		if _, err = custom.APIVersion(path); err != nil {
+			return err
+		}
+
+		return custom.Validate(state, util.FileStem(path))
+	}
+
+	return nil
+}
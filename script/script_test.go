@@ -0,0 +1,101 @@
+package script
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSource serves a single manga with a single page from the given URL
+const testSource = `
+function SearchManga(query)
+	return { { name = "Berserk", url = "%[1]s/berserk" } }
+end
+
+function MangaChapters(mangaURL)
+	return { { name = "Prologue", url = mangaURL .. "/1" } }
+end
+
+function ChapterPages(chapterURL)
+	return { { url = "%[1]s/1.png", index = 1 } }
+end
+`
+
+const testScript = `
+local mangal = require("mangal")
+
+local mangas = assert(mangal.search("Local", "berserk"))
+assert(mangas[1].name == "Berserk", "unexpected manga " .. tostring(mangas[1].name))
+
+local chapters = assert(mangas[1]:chapters())
+local path = assert(chapters[1]:download("zip"))
+assert(chapters[1].downloaded, "chapter is not downloaded")
+
+local converted = assert(mangal.convert(path, "cbz"))
+
+local library = mangal.library()
+assert(#library == 1 and library[1].name == "Berserk", "unexpected library")
+
+local _, err = mangal.search("Unknown", "berserk")
+assert(err ~= nil, "unknown source was found")
+
+result = converted
+`
+
+func TestRun(t *testing.T) {
+	Convey("Given a script using the mangal module", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("page"))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		t.Setenv(where.EnvConfigPath, filepath.Join(dir, "config"))
+		t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+		viper.Set(key.DownloaderPath, filepath.Join(dir, "downloads"))
+		viper.Set(key.DownloaderCreateMangaDir, true)
+		viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+		viper.Set(key.FormatsUse, constant.FormatPDF)
+		defer viper.Set(key.DownloaderPath, "")
+
+		lo.Must0(os.WriteFile(filepath.Join(where.Sources(), "Local.lua"), []byte(fmt.Sprintf(testSource, server.URL)), os.ModePerm))
+
+		path := filepath.Join(dir, "weekly.lua")
+		lo.Must0(os.WriteFile(path, []byte(testScript), os.ModePerm))
+
+		Convey("When it is run", func() {
+			err := Run(path, false)
+
+			Convey("Then the chapter should be downloaded and converted", func() {
+				So(err, ShouldBeNil)
+
+				for _, format := range []string{constant.FormatZIP, constant.FormatCBZ} {
+					_, err = os.Stat(filepath.Join(dir, "downloads", "Berserk", "Prologue."+format))
+					So(err, ShouldBeNil)
+				}
+			})
+		})
+	})
+
+	Convey("Given a custom source missing a function", t, func() {
+		path := filepath.Join(t.TempDir(), "broken.lua")
+		lo.Must0(os.WriteFile(path, []byte(`function SearchManga(query) return {} end`), os.ModePerm))
+
+		Convey("When it is run", func() {
+			Convey("Then the missing functions should be reported unless lenient", func() {
+				So(Run(path, false), ShouldNotBeNil)
+				So(Run(path, true), ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,99 @@
+// Package secrets stores sensitive values, such as passwords, outside the config file.
+// Environment variables take precedence over the stored values.
+package secrets
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/where"
+	"os"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables that override secrets,
+// e.g. MANGAL_SECRET_ARCHIVE_PASSWORD for ArchivePassword
+const EnvPrefix = "MANGAL_SECRET_"
+
+// ArchivePassword is used to encrypt archives
+const ArchivePassword = "archive_password"
+
+// RemotePassword is used to log in to the remote server
+const RemotePassword = "remote_password"
+
+// AnilistToken is the access token of the Anilist account, set by mangal anilist login
+const AnilistToken = "anilist_token"
+
+// AnilistSecret is the secret of the Anilist client, set by mangal anilist login
+const AnilistSecret = "anilist_secret"
+
+// LezhinPassword is the password of the Lezhin account
+const LezhinPassword = "lezhin_password"
+
+// Names of all known secrets
+var Names = []string{ArchivePassword, RemotePassword, AnilistToken, AnilistSecret, LezhinPassword}
+
+func envName(name string) string {
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+func load() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	contents, err := filesystem.Api().ReadFile(where.Secrets())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, &secrets)
+	return secrets, err
+}
+
+func save(secrets map[string]string) error {
+	contents, err := json.MarshalIndent(secrets, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// only the owner should be able to read secrets
+	return filesystem.Api().WriteFile(where.Secrets(), contents, 0600)
+}
+
+// Get returns the secret with the given name or empty string if it is not set
+func Get(name string) (string, error) {
+	if value, ok := os.LookupEnv(envName(name)); ok {
+		return value, nil
+	}
+
+	secrets, err := load()
+	if err != nil {
+		return "", err
+	}
+
+	return secrets[name], nil
+}
+
+// Set stores the secret with the given name
+func Set(name, value string) error {
+	secrets, err := load()
+	if err != nil {
+		return err
+	}
+
+	secrets[name] = value
+	return save(secrets)
+}
+
+// Delete removes the secret with the given name from the store
+func Delete(name string) error {
+	secrets, err := load()
+	if err != nil {
+		return err
+	}
+
+	delete(secrets, name)
+	return save(secrets)
+}
@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"github.com/metafates/mangal/filesystem"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+}
+
+func TestSecrets(t *testing.T) {
+	Convey("Given a secret that is not set", t, func() {
+		Convey("Then it should be empty", func() {
+			So(lo.Must(Get("test")), ShouldBeEmpty)
+		})
+
+		Convey("When it is set", func() {
+			So(Set("test", "hunter2"), ShouldBeNil)
+
+			Convey("Then it should be returned", func() {
+				So(lo.Must(Get("test")), ShouldEqual, "hunter2")
+			})
+
+			Convey("And environment variable should take precedence", func() {
+				lo.Must0(os.Setenv(EnvPrefix+"TEST", "from env"))
+				defer os.Unsetenv(EnvPrefix + "TEST")
+
+				So(lo.Must(Get("test")), ShouldEqual, "from env")
+			})
+
+			Convey("When it is deleted", func() {
+				So(Delete("test"), ShouldBeNil)
+
+				Convey("Then it should be empty again", func() {
+					So(lo.Must(Get("test")), ShouldBeEmpty)
+				})
+			})
+		})
+	})
+}
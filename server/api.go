@@ -0,0 +1,197 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/spf13/viper"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+}
+
+// api returns the handler of the /api/ endpoints
+func (s *Server) api() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/me", s.handleMe)
+	mux.HandleFunc("/api/library", s.handleLibrary)
+	mux.HandleFunc("/api/progress", s.handleProgress)
+	mux.HandleFunc("/api/search", s.handleSearch)
+	mux.HandleFunc("/api/chapters", s.handleChapters)
+	mux.HandleFunc("/api/downloads", s.handleDownloads)
+	mux.HandleFunc("/api/links", s.handleLinks)
+	mux.HandleFunc("/api/files", s.handleFiles)
+	return authenticate(mux)
+}
+
+// Me is the response of /api/me
+type Me struct {
+	Name    string `json:"name,omitempty"`
+	Library string `json:"library"`
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	response := Me{Library: LibraryShared}
+	if user := UserFrom(r); user != nil {
+		response.Name = user.Name
+		response.Library = viper.GetString(key.ServerLibrary)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// MangaRef is everything needed to find the manga on its source
+type MangaRef struct {
+	SourceID string `json:"source_id"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	ID       string `json:"id"`
+}
+
+// LibraryManga is a manga of the /api/library response
+type LibraryManga struct {
+	MangaRef
+	Directory string                `json:"directory"`
+	Progress  *history.SavedChapter `json:"progress"`
+	// Chapters are the downloaded chapters, listed with the chapters parameter only
+	Chapters []*LibraryChapter `json:"chapters,omitempty"`
+}
+
+func (s *Server) handleLibrary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	var (
+		user         = UserFrom(r)
+		withChapters = r.URL.Query().Get("chapters") == "true"
+	)
+
+	mangas, err := library.In(s.libraryOf(user))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	saved, err := progressOf(user).Get()
+	if err != nil {
+		log.Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	progress := make(map[string]*history.SavedChapter, len(saved))
+	for _, chapter := range saved {
+		progress[chapter.MangaName] = chapter
+	}
+
+	response := make([]*LibraryManga, 0, len(mangas))
+	for _, manga := range mangas {
+		libraryManga := &LibraryManga{
+			MangaRef:  MangaRef{Name: manga.Name},
+			Directory: filepath.Base(manga.Path),
+			Progress:  progress[manga.Name],
+		}
+
+		// tracked mangas know their source, otherwise guess it from the progress
+		if entry, err := manga.Entry(); err == nil && entry != nil {
+			libraryManga.SourceID = entry.SourceID
+			libraryManga.URL = entry.URL
+			libraryManga.ID = entry.ID
+		} else if chapter := libraryManga.Progress; chapter != nil {
+			libraryManga.SourceID = chapter.SourceID
+			libraryManga.URL = chapter.MangaURL
+			libraryManga.ID = chapter.MangaID
+		}
+
+		if withChapters {
+			if libraryManga.Chapters, err = libraryChaptersOf(manga); err != nil {
+				log.Error(err)
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		response = append(response, libraryManga)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	store := progressOf(UserFrom(r))
+
+	switch r.Method {
+	case http.MethodGet:
+		saved, err := store.Get()
+		if err != nil {
+			log.Error(err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, saved)
+	case http.MethodPut, http.MethodDelete:
+		var chapter history.SavedChapter
+		if err := json.NewDecoder(r.Body).Decode(&chapter); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if chapter.MangaName == "" || chapter.SourceID == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("manga_name and source_id are required"))
+			return
+		}
+
+		var err error
+		if r.Method == http.MethodPut {
+			if chapter.Time.IsZero() {
+				chapter.Time = time.Now()
+			}
+
+			err = store.Put(&chapter)
+		} else {
+			err = store.Remove(&chapter)
+		}
+
+		if err != nil {
+			log.Error(err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
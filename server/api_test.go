@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/users"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func request(s *Server, method, path string, body any, user, password string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		lo.Must0(json.NewEncoder(&buf).Encode(body))
+	}
+
+	r := httptest.NewRequest(method, path, &buf)
+	if user != "" {
+		r.SetBasicAuth(user, password)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, r)
+	return recorder
+}
+
+func TestMultiUser(t *testing.T) {
+	Convey("Given a server with basic auth and two users", t, func() {
+		viper.Set(key.ServerAuth, AuthBasic)
+		lo.Must0(users.Add("alice", "alice"))
+		lo.Must0(users.Add("bob", "bob"))
+
+		Reset(func() {
+			viper.Set(key.ServerAuth, AuthNone)
+			viper.Set(key.ServerLibrary, LibraryShared)
+			_ = users.Remove("alice")
+			_ = users.Remove("bob")
+		})
+
+		s, err := New()
+		So(err, ShouldBeNil)
+
+		Convey("Then requests without credentials should be rejected", func() {
+			recorder := request(s, http.MethodGet, "/api/library", nil, "", "")
+			So(recorder.Code, ShouldEqual, http.StatusUnauthorized)
+			So(recorder.Header().Get("WWW-Authenticate"), ShouldStartWith, "Basic")
+
+			So(request(s, http.MethodGet, "/api/library", nil, "alice", "bob").Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("Then health endpoints should not require credentials", func() {
+			So(request(s, http.MethodGet, "/healthz", nil, "", "").Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("When alice saves her progress", func() {
+			chapter := history.SavedChapter{MangaName: "Berserk", SourceID: "test", Name: "Chapter 10", Index: 10}
+			So(request(s, http.MethodPut, "/api/progress", chapter, "alice", "alice").Code, ShouldEqual, http.StatusNoContent)
+
+			Convey("Then only alice should see it", func() {
+				var progress map[string]*history.SavedChapter
+
+				recorder := request(s, http.MethodGet, "/api/progress", nil, "alice", "alice")
+				So(json.Unmarshal(recorder.Body.Bytes(), &progress), ShouldBeNil)
+				So(progress, ShouldHaveLength, 1)
+
+				var other map[string]*history.SavedChapter
+				recorder = request(s, http.MethodGet, "/api/progress", nil, "bob", "bob")
+				So(json.Unmarshal(recorder.Body.Bytes(), &other), ShouldBeNil)
+				So(other, ShouldBeEmpty)
+			})
+
+			Convey("And the shared library has the manga", func() {
+				downloads := where.Downloads()
+				lo.Must0(filesystem.Api().MkdirAll(filepath.Join(downloads, "Berserk"), os.ModePerm))
+
+				Convey("Then the library should include her progress", func() {
+					var mangas []*LibraryManga
+					recorder := request(s, http.MethodGet, "/api/library", nil, "alice", "alice")
+					So(json.Unmarshal(recorder.Body.Bytes(), &mangas), ShouldBeNil)
+
+					manga, ok := lo.Find(mangas, func(m *LibraryManga) bool { return m.Name == "Berserk" })
+					So(ok, ShouldBeTrue)
+					So(manga.Progress, ShouldNotBeNil)
+					So(manga.Progress.Index, ShouldEqual, 10)
+				})
+			})
+		})
+
+		Convey("When libraries are personal", func() {
+			viper.Set(key.ServerLibrary, LibraryPersonal)
+			lo.Must0(filesystem.Api().MkdirAll(filepath.Join(where.Downloads(), "alice", "Vagabond"), os.ModePerm))
+
+			Convey("Then each user should see only their own library", func() {
+				var mangas []*LibraryManga
+
+				recorder := request(s, http.MethodGet, "/api/library", nil, "alice", "alice")
+				So(json.Unmarshal(recorder.Body.Bytes(), &mangas), ShouldBeNil)
+				So(mangas, ShouldHaveLength, 1)
+				So(mangas[0].Name, ShouldEqual, "Vagabond")
+
+				var other []*LibraryManga
+				recorder = request(s, http.MethodGet, "/api/library", nil, "bob", "bob")
+				So(recorder.Code, ShouldEqual, http.StatusOK)
+				So(json.Unmarshal(recorder.Body.Bytes(), &other), ShouldBeNil)
+				So(other, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given personal libraries without authentication", t, func() {
+		viper.Set(key.ServerLibrary, LibraryPersonal)
+
+		Reset(func() {
+			viper.Set(key.ServerLibrary, LibraryShared)
+		})
+
+		Convey("Then the server should not start", func() {
+			_, err := New()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestHeaderAuth(t *testing.T) {
+	Convey("Given a server behind a reverse proxy that sets the username", t, func() {
+		viper.Set(key.ServerAuth, AuthHeader)
+		viper.Set(key.ServerTrustedProxies, []string{"10.0.0.0/8"})
+		lo.Must0(users.Add("alice", "alice"))
+
+		Reset(func() {
+			viper.Set(key.ServerAuth, AuthNone)
+			viper.Set(key.ServerTrustedProxies, []string{"127.0.0.1/32", "::1/128"})
+			_ = users.Remove("alice")
+		})
+
+		s, err := New()
+		So(err, ShouldBeNil)
+
+		requestFrom := func(address string) int {
+			r := httptest.NewRequest(http.MethodGet, "/api/progress", nil)
+			r.RemoteAddr = address
+			r.Header.Set(viper.GetString(key.ServerAuthHeader), "alice")
+
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, r)
+			return recorder.Code
+		}
+
+		Convey("Then the header should be trusted from the proxy", func() {
+			So(requestFrom("10.1.2.3:4567"), ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Then the header should be rejected from anyone else", func() {
+			So(requestFrom("192.0.2.1:4567"), ShouldEqual, http.StatusUnauthorized)
+		})
+	})
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/users"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	AuthNone   = "none"
+	AuthBasic  = "basic"
+	AuthHeader = "header"
+)
+
+const (
+	LibraryShared   = "shared"
+	LibraryPersonal = "personal"
+)
+
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// validate checks the server options from the config
+func validate() error {
+	switch mode := viper.GetString(key.ServerAuth); mode {
+	case AuthNone, AuthBasic, AuthHeader:
+	default:
+		return fmt.Errorf("unknown auth %q, available options are %s", mode, strings.Join([]string{AuthNone, AuthBasic, AuthHeader}, ", "))
+	}
+
+	switch mode := viper.GetString(key.ServerLibrary); mode {
+	case LibraryShared, LibraryPersonal:
+	default:
+		return fmt.Errorf("unknown library %q, available options are %s", mode, strings.Join([]string{LibraryShared, LibraryPersonal}, ", "))
+	}
+
+	if viper.GetString(key.ServerAuth) == AuthNone && viper.GetString(key.ServerLibrary) == LibraryPersonal {
+		return fmt.Errorf("personal libraries require authentication")
+	}
+
+	if _, err := trustedProxies(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// trustedProxies returns the networks of the reverse proxies that may set the header of the username
+func trustedProxies() ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, cidr := range viper.GetStringSlice(key.ServerTrustedProxies) {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// fromTrustedProxy reports whether the request was sent by one of the trusted proxies, see key.ServerTrustedProxies.
+// Anyone else could set the header of the username themselves
+func fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	networks, err := trustedProxies()
+	if err != nil {
+		log.Warn(err)
+		return false
+	}
+
+	return lo.ContainsBy(networks, func(network *net.IPNet) bool {
+		return network.Contains(ip)
+	})
+}
+
+// fromLoopback reports whether the request was sent from this machine
+func fromLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// UserFrom returns the user of the request.
+// It returns nil if authentication is disabled
+func UserFrom(r *http.Request) *users.User {
+	user, _ := r.Context().Value(userContextKey).(*users.User)
+	return user
+}
+
+// progressOf returns the reading progress of the user
+func progressOf(user *users.User) *history.Store {
+	if user == nil {
+		return history.Default()
+	}
+
+	return user.History()
+}
+
+// readersOf returns the reading progress of everyone who reads the library of the user.
+// Chapters of the shared library are evicted by the quota only once every user has read them
+func readersOf(user *users.User) ([]*history.Store, error) {
+	if user == nil || viper.GetString(key.ServerLibrary) == LibraryPersonal {
+		return []*history.Store{progressOf(user)}, nil
+	}
+
+	all, err := users.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(all, func(user *users.User, _ int) *history.Store {
+		return user.History()
+	}), nil
+}
+
+// libraryOf returns the path to the library of the user
+func (s *Server) libraryOf(user *users.User) string {
+	if user == nil || viper.GetString(key.ServerLibrary) != LibraryPersonal {
+		return s.root
+	}
+
+	return filepath.Join(s.root, user.Name)
+}
+
+// authenticate rejects the requests without a valid user
+func authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			user *users.User
+			err  error
+		)
+
+		switch viper.GetString(key.ServerAuth) {
+		case AuthNone:
+			next.ServeHTTP(w, r)
+			return
+		case AuthBasic:
+			name, password, ok := r.BasicAuth()
+			if !ok {
+				err = users.ErrInvalidCredentials
+				break
+			}
+
+			user, err = users.Authenticate(name, password)
+		case AuthHeader:
+			if !fromTrustedProxy(r) {
+				log.Warnf("rejected the username header from %s, which is not a trusted proxy", r.RemoteAddr)
+				err = users.ErrInvalidCredentials
+				break
+			}
+
+			name := r.Header.Get(viper.GetString(key.ServerAuthHeader))
+			if name == "" {
+				err = users.ErrInvalidCredentials
+				break
+			}
+
+			user, err = users.Get(name)
+		}
+
+		if err != nil {
+			if errors.Is(err, users.ErrInvalidCredentials) || errors.Is(err, users.ErrNotFound) {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", constant.Mangal))
+				writeError(w, http.StatusUnauthorized, users.ErrInvalidCredentials)
+				return
+			}
+
+			log.Error(err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey, user)))
+	})
+}
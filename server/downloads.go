@@ -0,0 +1,423 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/quota"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/users"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	JobQueued      = "queued"
+	JobDownloading = "downloading"
+	JobDone        = "done"
+	JobFailed      = "failed"
+)
+
+// Priorities of the jobs. Jobs of the higher priorities are downloaded first,
+// and the high priority jobs preempt the background chapter being downloaded
+const (
+	// PriorityHigh is for the chapters that are about to be read
+	PriorityHigh = "high"
+	// PriorityNormal is the default priority
+	PriorityNormal = "normal"
+	// PriorityBackground is for the batches that nobody waits for, e.g. archiving the whole series
+	PriorityBackground = "background"
+)
+
+var priorities = map[string]int{
+	PriorityHigh:       2,
+	PriorityNormal:     1,
+	PriorityBackground: 0,
+}
+
+// priorityOf returns the priority of the request, normal if not set
+func priorityOf(priority string) (string, error) {
+	if priority == "" {
+		return PriorityNormal, nil
+	}
+
+	if _, ok := priorities[priority]; !ok {
+		return "", fmt.Errorf("priority must be %s, %s or %s", PriorityHigh, PriorityNormal, PriorityBackground)
+	}
+
+	return priority, nil
+}
+
+// Chapter is a chapter of the /api/chapters response
+type Chapter struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	ID         string `json:"id"`
+	Index      int    `json:"index"`
+	Volume     string `json:"volume,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Notes      string `json:"notes,omitempty"`
+	Downloaded bool   `json:"downloaded"`
+}
+
+// DownloadRequest is the body of POST /api/downloads
+type DownloadRequest struct {
+	Manga MangaRef `json:"manga"`
+	// Chapters are the URLs of the chapters to download
+	Chapters []string `json:"chapters"`
+	// Priority of the downloads, normal if empty
+	Priority string `json:"priority,omitempty"`
+}
+
+// Job is a queued chapter download
+type Job struct {
+	ID       int        `json:"id"`
+	Manga    MangaRef   `json:"manga"`
+	Chapter  string     `json:"chapter"`
+	URL      string     `json:"url"`
+	Status   string     `json:"status"`
+	Priority string     `json:"priority"`
+	Progress string     `json:"progress,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Added    time.Time  `json:"added"`
+	Finished *time.Time `json:"finished,omitempty"`
+
+	user *users.User
+}
+
+// owner returns the name of the user that queued the job
+func (j *Job) owner() string {
+	if j.user == nil {
+		return ""
+	}
+
+	return j.user.Name
+}
+
+// queue downloads the jobs one by one in the background, the jobs of the higher priorities first
+type queue struct {
+	mutex sync.Mutex
+	// added is signaled when the jobs are added or the queue is closed
+	added   *sync.Cond
+	jobs    []*Job
+	pending []*Job
+	// current is the job being downloaded
+	current *Job
+	// preempted is set when the current job is skipped for the job of a higher priority
+	preempted bool
+	closed    bool
+	lastID    int
+	run       func(*Job, func(string)) error
+	// skip cancels the chapter being downloaded, see downloader.Skip
+	skip func() bool
+}
+
+func newQueue(run func(*Job, func(string)) error) *queue {
+	q := &queue{
+		run:  run,
+		skip: downloader.Skip,
+	}
+
+	q.added = sync.NewCond(&q.mutex)
+
+	go q.work()
+	return q
+}
+
+func (q *queue) work() {
+	for {
+		job, ok := q.take()
+		if !ok {
+			return
+		}
+
+		err := q.run(job, func(progress string) {
+			q.update(job, func(job *Job) {
+				job.Progress = progress
+			})
+		})
+
+		q.finish(job, err)
+	}
+}
+
+// take waits for the pending job of the highest priority, the oldest one of them.
+// It returns false once the queue is closed
+func (q *queue) take() (*Job, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for len(q.pending) == 0 && !q.closed {
+		q.added.Wait()
+	}
+
+	if q.closed {
+		return nil, false
+	}
+
+	next := 0
+	for i, job := range q.pending {
+		if priorities[job.Priority] > priorities[q.pending[next].Priority] {
+			next = i
+		}
+	}
+
+	job := q.pending[next]
+	q.pending = append(q.pending[:next], q.pending[next+1:]...)
+	q.current = job
+	q.preempted = false
+	job.Status = JobDownloading
+
+	return job, true
+}
+
+func (q *queue) finish(job *Job, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.current = nil
+	job.Progress = ""
+
+	// preempted job is downloaded again before the other jobs of its priority
+	if q.preempted && errors.Is(err, downloader.ErrSkipped) {
+		log.Infof("%s of %s was preempted, queued it again", job.Chapter, job.Manga.Name)
+		job.Status = JobQueued
+		q.pending = append([]*Job{job}, q.pending...)
+		return
+	}
+
+	now := time.Now()
+	job.Finished = &now
+
+	if err != nil {
+		log.Errorf("downloading %s of %s failed: %s", job.Chapter, job.Manga.Name, err)
+		job.Status = JobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = JobDone
+	}
+}
+
+func (q *queue) update(job *Job, fn func(*Job)) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	fn(job)
+}
+
+// add queues the jobs and returns their copies, the jobs themselves are updated by the worker.
+// Jobs without the priority are queued with the normal one
+func (q *queue) add(jobs ...*Job) []Job {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	added := make([]Job, len(jobs))
+	for i, job := range jobs {
+		q.lastID++
+		job.ID = q.lastID
+		job.Status = JobQueued
+		job.Added = time.Now()
+		if job.Priority == "" {
+			job.Priority = PriorityNormal
+		}
+
+		q.jobs = append(q.jobs, job)
+		q.pending = append(q.pending, job)
+		added[i] = *job
+
+		if job.Priority == PriorityHigh && q.current != nil && q.current.Priority == PriorityBackground && !q.preempted {
+			q.preempted = q.skip()
+		}
+	}
+
+	q.added.Broadcast()
+	return added
+}
+
+// list returns copies of the jobs queued by the user
+func (q *queue) list(user *users.User) []Job {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var name string
+	if user != nil {
+		name = user.Name
+	}
+
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		if job.owner() == name {
+			jobs = append(jobs, *job)
+		}
+	}
+
+	return jobs
+}
+
+func (q *queue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.closed = true
+	q.added.Broadcast()
+}
+
+// download the chapter of the job to the library of its user
+func (s *Server) download(job *Job, progress func(string)) error {
+	src, err := s.sources.get(job.Manga.SourceID)
+	if err != nil {
+		return err
+	}
+
+	progress(i18n.T("Getting chapters"))
+	chapters, err := chaptersOf(src, &job.Manga)
+	if err != nil {
+		return err
+	}
+
+	chapter, ok := lo.Find(chapters, func(chapter *source.Chapter) bool {
+		return chapter.URL == job.URL
+	})
+	if !ok {
+		return fmt.Errorf("chapter %s not found", job.URL)
+	}
+
+	s.queue.update(job, func(job *Job) {
+		job.Chapter = chapter.Name
+	})
+
+	if _, err = downloader.DownloadTo(chapter, s.libraryOf(job.user), progress); err != nil {
+		return err
+	}
+
+	stores, err := readersOf(job.user)
+	if err != nil {
+		log.Warn(err)
+		return nil
+	}
+
+	if err = quota.Enforce(s.libraryOf(job.user), stores...); err != nil {
+		log.Warn(err)
+	}
+
+	return nil
+}
+
+// isDownloadedTo reports whether the chapter is downloaded to the library.
+// Unlike source.Chapter.IsDownloaded it does not depend on the downloads path,
+// since the chapters of the users are downloaded to their personal libraries
+func isDownloadedTo(root string, chapter *source.Chapter) bool {
+	if viper.GetBool(key.DownloaderCreateMangaDir) {
+		root = filepath.Join(root, chapter.Manga.DirnameIn(root))
+	}
+
+	exists, _ := filesystem.Api().Exists(filepath.Join(root, chapter.Filename()))
+	return exists
+}
+
+func (s *Server) handleChapters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	ref := MangaRef{
+		SourceID: query.Get("source_id"),
+		Name:     query.Get("name"),
+		URL:      query.Get("url"),
+		ID:       query.Get("id"),
+	}
+
+	if ref.SourceID == "" || ref.URL == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("source_id and url are required"))
+		return
+	}
+
+	src, err := s.sources.get(ref.SourceID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	chapters, err := chaptersOf(src, &ref)
+
+	if err != nil {
+		log.Error(err)
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	root := s.libraryOf(UserFrom(r))
+
+	response := make([]*Chapter, len(chapters))
+	for i, chapter := range chapters {
+		response[i] = &Chapter{
+			Name:       chapter.Name,
+			URL:        chapter.URL,
+			ID:         chapter.ID,
+			Index:      chapter.Index,
+			Volume:     chapter.Volume,
+			Title:      chapter.Title,
+			Notes:      chapter.Notes,
+			Downloaded: isDownloadedTo(root, chapter),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleDownloads(w http.ResponseWriter, r *http.Request) {
+	user := UserFrom(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.queue.list(user))
+	case http.MethodPost:
+		var request DownloadRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if request.Manga.SourceID == "" || request.Manga.URL == "" || len(request.Chapters) == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("manga source_id, url and chapters are required"))
+			return
+		}
+
+		priority, err := priorityOf(request.Priority)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if _, err := s.sources.get(request.Manga.SourceID); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		jobs := make([]*Job, len(request.Chapters))
+		for i, url := range request.Chapters {
+			jobs[i] = &Job{
+				Manga:    request.Manga,
+				Chapter:  url,
+				URL:      url,
+				Priority: priority,
+				user:     user,
+			}
+		}
+
+		writeJSON(w, http.StatusAccepted, s.queue.add(jobs...))
+	default:
+		methodNotAllowed(w, r)
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/users"
+	. "github.com/smartystreets/goconvey/convey"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	Convey("Given a queue that fails one of the chapters", t, func() {
+		q := newQueue(func(job *Job, progress func(string)) error {
+			progress("Downloading")
+			if job.URL == "bad" {
+				return fmt.Errorf("source is down")
+			}
+
+			return nil
+		})
+		defer q.close()
+
+		alice := &users.User{Name: "alice"}
+
+		Convey("When jobs are added by different users", func() {
+			q.add(&Job{URL: "good", user: alice}, &Job{URL: "bad", user: alice})
+			q.add(&Job{URL: "good"})
+
+			Convey("Then they should be finished in order", func() {
+				So(func() bool {
+					deadline := time.Now().Add(time.Second)
+					for time.Now().Before(deadline) {
+						if jobs := q.list(nil); len(jobs) == 1 && jobs[0].Status == JobDone {
+							return true
+						}
+						time.Sleep(10 * time.Millisecond)
+					}
+					return false
+				}(), ShouldBeTrue)
+
+				jobs := q.list(alice)
+				So(jobs, ShouldHaveLength, 2)
+				So(jobs[0].ID, ShouldEqual, 1)
+				So(jobs[0].Status, ShouldEqual, JobDone)
+				So(jobs[1].Status, ShouldEqual, JobFailed)
+				So(jobs[1].Error, ShouldEqual, "source is down")
+				So(jobs[1].Finished, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestQueuePriorities(t *testing.T) {
+	Convey("Given a queue busy with a background chapter", t, func() {
+		var (
+			mutex      sync.Mutex
+			downloaded []string
+			attempts   = make(map[string]int)
+			started    = make(chan string, 16)
+			release    = make(chan struct{})
+			skipped    = make(chan struct{}, 1)
+		)
+
+		q := newQueue(func(job *Job, progress func(string)) error {
+			started <- job.URL
+
+			mutex.Lock()
+			attempts[job.URL]++
+			first := attempts[job.URL] == 1
+			mutex.Unlock()
+
+			if job.URL == "archive 1" && first {
+				select {
+				case <-release:
+				case <-skipped:
+					return downloader.ErrSkipped
+				}
+			}
+
+			mutex.Lock()
+			downloaded = append(downloaded, job.URL)
+			mutex.Unlock()
+			return nil
+		})
+		defer q.close()
+
+		q.skip = func() bool {
+			skipped <- struct{}{}
+			return true
+		}
+
+		q.add(&Job{URL: "archive 1", Priority: PriorityBackground}, &Job{URL: "archive 2", Priority: PriorityBackground})
+		So(<-started, ShouldEqual, "archive 1")
+
+		Convey("When chapters of the higher priorities are added", func() {
+			q.add(&Job{URL: "normal"})
+			q.add(&Job{URL: "read next", Priority: PriorityHigh})
+
+			Convey("Then the background chapter should be preempted and downloaded after them", func() {
+				So(func() bool {
+					deadline := time.Now().Add(time.Second)
+					for time.Now().Before(deadline) {
+						mutex.Lock()
+						count := len(downloaded)
+						mutex.Unlock()
+
+						if count == 4 {
+							return true
+						}
+						time.Sleep(10 * time.Millisecond)
+					}
+					return false
+				}(), ShouldBeTrue)
+
+				So(downloaded, ShouldResemble, []string{"read next", "normal", "archive 1", "archive 2"})
+
+				jobs := q.list(nil)
+				So(jobs[0].Status, ShouldEqual, JobDone)
+				So(jobs[0].Priority, ShouldEqual, PriorityBackground)
+				So(jobs[2].Priority, ShouldEqual, PriorityNormal)
+			})
+		})
+	})
+
+	Convey("When getting the priority of a request", t, func() {
+		Convey("Then it should be normal by default", func() {
+			priority, err := priorityOf("")
+			So(err, ShouldBeNil)
+			So(priority, ShouldEqual, PriorityNormal)
+
+			_, err = priorityOf("urgent")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// LibraryChapter is a downloaded chapter of the manga of the /api/library response
+type LibraryChapter struct {
+	Name  string `json:"name"`
+	Index int    `json:"index"`
+	// Path of the file relative to the manga directory, as given to /api/files
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// URL of the chapter on its source, if it is known
+	URL string `json:"url,omitempty"`
+}
+
+func libraryChaptersOf(manga *library.Manga) ([]*LibraryChapter, error) {
+	chapters, err := manga.Chapters()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return lo.Map(chapters, func(chapter *library.Chapter, _ int) *LibraryChapter {
+		libraryChapter := &LibraryChapter{
+			Name:  chapter.Name,
+			Index: chapter.Index,
+			Path:  filepath.ToSlash(chapter.Path),
+			URL:   chapter.URL,
+		}
+
+		if info, err := filesystem.Api().Stat(filepath.Join(manga.Path, chapter.Path)); err == nil {
+			libraryChapter.Size = info.Size()
+		}
+
+		return libraryChapter
+	}), nil
+}
+
+// handleFiles sends the downloaded chapter at the path of the manga directory, e.g. to pull it to another mangal.
+// Only the chapter files of the library of the user are sent
+func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	var (
+		directory = r.URL.Query().Get("directory")
+		path      = r.URL.Query().Get("path")
+	)
+
+	if directory == "" || path == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("directory and path are required"))
+		return
+	}
+
+	mangas, err := library.In(s.libraryOf(UserFrom(r)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	manga, ok := lo.Find(mangas, func(manga *library.Manga) bool {
+		return filepath.Base(manga.Path) == directory
+	})
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("manga %s not found", directory))
+		return
+	}
+
+	chapters, err := manga.Chapters()
+	if err != nil {
+		log.Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the path is one of the listed chapters, never anything else on the disk
+	chapter, ok := lo.Find(chapters, func(chapter *library.Chapter) bool {
+		return filepath.ToSlash(chapter.Path) == path
+	})
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("chapter %s of %s not found", path, directory))
+		return
+	}
+
+	file, err := filesystem.Api().Open(filepath.Join(manga.Path, chapter.Path))
+	if err != nil {
+		log.Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer util.Ignore(file.Close)
+
+	info, err := file.Stat()
+	if err != nil {
+		log.Error(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(chapter.Path)))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/spf13/viper"
+	"mime"
+	"net/http"
+	"path/filepath"
+)
+
+// LinkRequest is the body of POST /api/links, sent by the browser extensions and userscripts
+type LinkRequest struct {
+	// URL of the manga page opened in the browser
+	URL string `json:"url"`
+	// Latest queues the download of the latest chapter, unless it is downloaded already
+	Latest bool `json:"latest"`
+	// Priority of the download of the latest chapter, high if empty since it is about to be read
+	Priority string `json:"priority,omitempty"`
+}
+
+// LinkResponse is the response of POST /api/links
+type LinkResponse struct {
+	Manga     MangaRef `json:"manga"`
+	Directory string   `json:"directory"`
+	// Tracked is false if the manga was in the library already
+	Tracked bool `json:"tracked"`
+	Job     *Job `json:"job,omitempty"`
+}
+
+// handleLinks adds the manga of the link to the library of the user.
+// Only JSON bodies are accepted, so that web pages can't post the links
+// with plain forms without the CORS preflight, which the server never allows.
+// Without authentication the links are accepted from this machine only,
+// since anyone on the network could fill the library and queue the downloads otherwise
+func (s *Server) handleLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	if viper.GetString(key.ServerAuth) == AuthNone && !fromLoopback(r) {
+		writeError(w, http.StatusForbidden, fmt.Errorf("links are accepted from other machines only with authentication, set %s", key.ServerAuth))
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("content type must be application/json"))
+		return
+	}
+
+	var request LinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if request.URL == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("url is required"))
+		return
+	}
+
+	if request.Priority == "" {
+		request.Priority = PriorityHigh
+	}
+
+	priority, err := priorityOf(request.Priority)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	p, ok := provider.ByURL(request.URL)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no source for %s", request.URL))
+		return
+	}
+
+	src, err := s.sources.get(p.ID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	manga, err := provider.FindByLink(src, request.URL)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	user := UserFrom(r)
+	root := s.libraryOf(user)
+
+	// the directory of the same name may belong to another manga, see library.TrackIn
+	owner := &source.Owner{Name: manga.Name, SourceID: src.ID(), ID: manga.ID}
+	response := &LinkResponse{
+		Manga: MangaRef{
+			SourceID: src.ID(),
+			Name:     manga.Name,
+			URL:      manga.URL,
+			ID:       manga.ID,
+		},
+		Directory: source.ResolveDirname(root, owner),
+	}
+
+	// keep the subscription and the date of the mangas that are tracked already
+	tracked := &library.Manga{Name: manga.Name, Path: filepath.Join(root, response.Directory)}
+	if entry, err := tracked.Entry(); err != nil || entry == nil {
+		_, err = library.TrackIn(root, &library.Entry{
+			Name:     manga.Name,
+			SourceID: src.ID(),
+			URL:      manga.URL,
+			ID:       manga.ID,
+		})
+
+		if err != nil {
+			log.Error(err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Tracked = true
+	}
+
+	if request.Latest {
+		chapters, err := chaptersOf(src, &response.Manga)
+		if err != nil {
+			log.Error(err)
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+
+		if latest := latestOf(chapters); latest != nil && !isDownloadedTo(root, latest) {
+			job := &Job{
+				Manga:    response.Manga,
+				Chapter:  latest.Name,
+				URL:      latest.URL,
+				Priority: priority,
+				user:     user,
+			}
+
+			response.Job = &s.queue.add(job)[0]
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// latestOf returns the chapter with the highest index, nil if there are none
+func latestOf(chapters []*source.Chapter) *source.Chapter {
+	var latest *source.Chapter
+	for _, chapter := range chapters {
+		if latest == nil || chapter.Index > latest.Index {
+			latest = chapter
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/provider/mangadex"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const linkURL = "https://mangadex.org/title/801513ba-a712-498c-8f57-cae55b38cc92/berserk"
+
+// linkSource finds the manga of linkURL
+type linkSource struct{}
+
+func (linkSource) Name() string { return "MangaDex" }
+func (linkSource) ID() string   { return mangadex.ID }
+
+func (l linkSource) Search(string) ([]*source.Manga, error) {
+	return []*source.Manga{{Name: "Berserk", URL: linkURL, ID: "801513ba", Source: l}}, nil
+}
+
+func (linkSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	return []*source.Chapter{
+		{Name: "Chapter 1", URL: "chapter-1", Index: 1, Manga: manga},
+		{Name: "Chapter 2", URL: "chapter-2", Index: 2, Manga: manga},
+	}, nil
+}
+
+func (linkSource) PagesOf(*source.Chapter) ([]*source.Page, error) {
+	return nil, fmt.Errorf("pages are not available")
+}
+
+func postLink(s *Server, contentType string, body LinkRequest) *httptest.ResponseRecorder {
+	return postLinkFrom(s, "127.0.0.1:50000", contentType, body)
+}
+
+func postLinkFrom(s *Server, remoteAddr, contentType string, body LinkRequest) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	lo.Must0(json.NewEncoder(&buf).Encode(body))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/links", &buf)
+	r.RemoteAddr = remoteAddr
+	r.Header.Set("Content-Type", contentType)
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, r)
+	return recorder
+}
+
+func TestLinks(t *testing.T) {
+	Convey("Given a server with a source of the link", t, func() {
+		s, err := New()
+		So(err, ShouldBeNil)
+		s.sources.created[mangadex.ID] = linkSource{}
+
+		Convey("Then links posted as forms should be rejected", func() {
+			So(postLink(s, "text/plain", LinkRequest{URL: linkURL}).Code, ShouldEqual, http.StatusUnsupportedMediaType)
+		})
+
+		Convey("Then links from other machines should be rejected without authentication", func() {
+			So(postLinkFrom(s, "192.168.1.7:50000", "application/json", LinkRequest{URL: linkURL}).Code, ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Then links of unknown sites should not be found", func() {
+			So(postLink(s, "application/json", LinkRequest{URL: "https://example.com/berserk"}).Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("When the link is posted", func() {
+			recorder := postLink(s, "application/json; charset=utf-8", LinkRequest{URL: linkURL, Latest: true})
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+
+			var response LinkResponse
+			So(json.Unmarshal(recorder.Body.Bytes(), &response), ShouldBeNil)
+
+			Convey("Then the manga should be tracked", func() {
+				So(response.Manga.SourceID, ShouldEqual, mangadex.ID)
+				So(response.Manga.Name, ShouldEqual, "Berserk")
+
+				entry, err := (&library.Manga{Path: filepath.Join(s.root, response.Directory)}).Entry()
+				So(err, ShouldBeNil)
+				So(entry, ShouldNotBeNil)
+				So(entry.URL, ShouldEqual, linkURL)
+			})
+
+			Convey("Then the latest chapter should be queued", func() {
+				So(response.Job, ShouldNotBeNil)
+				So(response.Job.URL, ShouldEqual, "chapter-2")
+			})
+
+			Convey("And posted again", func() {
+				var again LinkResponse
+				recorder = postLink(s, "application/json", LinkRequest{URL: linkURL})
+				So(json.Unmarshal(recorder.Body.Bytes(), &again), ShouldBeNil)
+
+				Convey("Then the manga should not be tracked twice", func() {
+					So(again.Tracked, ShouldBeFalse)
+					So(again.Job, ShouldBeNil)
+				})
+			})
+		})
+	})
+}
+
+func TestLinksCollision(t *testing.T) {
+	Convey("Given a server whose library has another manga of the same name and source", t, func() {
+		root := filepath.Join(t.TempDir(), "library")
+		lo.Must0(filesystem.Api().MkdirAll(root, os.ModePerm))
+		viper.Set(key.DownloaderPath, root)
+		defer viper.Set(key.DownloaderPath, ".")
+
+		other := lo.Must(library.TrackIn(root, &library.Entry{Name: "Berserk", SourceID: mangadex.ID, URL: "other-url", ID: "another"}))
+
+		s, err := New()
+		So(err, ShouldBeNil)
+		s.sources.created[mangadex.ID] = linkSource{}
+
+		Convey("When the link is posted", func() {
+			recorder := postLink(s, "application/json", LinkRequest{URL: linkURL})
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+
+			var response LinkResponse
+			So(json.Unmarshal(recorder.Body.Bytes(), &response), ShouldBeNil)
+
+			Convey("Then the manga should be tracked in its own directory", func() {
+				So(response.Tracked, ShouldBeTrue)
+				So(filepath.Join(root, response.Directory), ShouldNotEqual, other.Path)
+
+				entry, err := (&library.Manga{Path: filepath.Join(root, response.Directory)}).Entry()
+				So(err, ShouldBeNil)
+				So(entry, ShouldNotBeNil)
+				So(entry.URL, ShouldEqual, linkURL)
+			})
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+const (
+	// listenFdsStart is the first file descriptor passed by systemd.
+	// See sd_listen_fds(3)
+	listenFdsStart = 3
+
+	envListenPid = "LISTEN_PID"
+	envListenFds = "LISTEN_FDS"
+)
+
+// Listen returns the socket passed by systemd socket activation if there is one,
+// otherwise it listens on the given tcp address
+func Listen(address string) (listener net.Listener, activated bool, err error) {
+	listener, err = activatedListener()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if listener != nil {
+		return listener, true, nil
+	}
+
+	listener, err = net.Listen("tcp", address)
+	return listener, false, err
+}
+
+// activatedListener returns the first socket passed by systemd.
+// It returns nil if mangal was not socket activated
+func activatedListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv(envListenPid))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	fds, err := strconv.Atoi(os.Getenv(envListenFds))
+	if err != nil || fds < 1 {
+		return nil, nil
+	}
+
+	// do not pass the sockets to the child processes
+	_ = os.Unsetenv(envListenPid)
+	_ = os.Unsetenv(envListenFds)
+
+	if fds > 1 {
+		return nil, fmt.Errorf("expected a single socket from systemd, got %d", fds)
+	}
+
+	file := os.NewFile(listenFdsStart, "LISTEN_FD_"+strconv.Itoa(listenFdsStart))
+	defer file.Close()
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("socket passed by systemd: %w", err)
+	}
+
+	return listener, nil
+}
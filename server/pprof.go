@@ -0,0 +1,18 @@
+package server
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// profiles returns the handler of the /debug/pprof/ endpoints, see key.ServerPprof.
+// They show the command line and the internals of the process, so they require the same users as the api
+func profiles() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return authenticate(mux)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/provider"
+	"github.com/samber/mo"
+	"github.com/spf13/viper"
+	"net/http"
+	"strings"
+)
+
+// handleSearch searches the sources for the manga and responds with the JSON of the inline mode, see inline.Output.
+// The sources are the default ones, unless given with the source_id parameters.
+// The manga and the chapters parameters are the selectors of the inline mode,
+// with the chapters selector the chapters and the progress of the mangas are included
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	options := &inline.Options{
+		Json:                true,
+		Query:               strings.TrimSpace(query.Get("query")),
+		IncludeAnilistManga: query.Get("anilist") == "true",
+		PopulatePages:       query.Get("pages") == "true",
+	}
+
+	if options.Query == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("query is required"))
+		return
+	}
+
+	ids := query["source_id"]
+	if len(ids) == 0 {
+		var err error
+		if ids, err = defaultSourceIDs(); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	for _, id := range ids {
+		src, err := s.sources.get(id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		options.Sources = append(options.Sources, src)
+	}
+
+	if picker := query.Get("manga"); picker != "" {
+		fn, err := inline.ParseMangaPicker(options.Query, picker)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		options.MangaPicker = mo.Some(fn)
+	}
+
+	if filter := query.Get("chapters"); filter != "" {
+		fn, err := inline.ParseChaptersFilter(filter)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		options.ChaptersFilter = mo.Some(fn)
+	}
+
+	var buf bytes.Buffer
+	options.Out = &buf
+
+	if err := inline.Run(options); err != nil {
+		var selectorErr *inline.SelectorError
+		if errors.As(err, &selectorErr) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		log.Error(err)
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// defaultSourceIDs returns the IDs of the default sources of the downloader
+func defaultSourceIDs() ([]string, error) {
+	var ids []string
+	for _, name := range viper.GetStringSlice(key.DownloaderDefaultSources) {
+		if name == "" {
+			continue
+		}
+
+		p, ok := provider.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("source not found: %s", name)
+		}
+
+		ids = append(ids, p.ID)
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("source not set")
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/inline"
+	"github.com/metafates/mangal/provider/mangadex"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func search(s *Server, values url.Values) *httptest.ResponseRecorder {
+	return request(s, http.MethodGet, "/api/search?"+values.Encode(), nil, "", "")
+}
+
+func TestSearch(t *testing.T) {
+	Convey("Given a server with a source", t, func() {
+		s, err := New()
+		So(err, ShouldBeNil)
+		s.sources.created[mangadex.ID] = linkSource{}
+
+		Convey("Then searching without the query should be rejected", func() {
+			So(search(s, url.Values{"source_id": {mangadex.ID}}).Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Then searching unknown sources should not be found", func() {
+			So(search(s, url.Values{"query": {"Berserk"}, "source_id": {"unknown"}}).Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("Then invalid selectors should be rejected", func() {
+			So(search(s, url.Values{"query": {"Berserk"}, "chapters": {"nope"}, "source_id": {mangadex.ID}}).Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When the manga is searched", func() {
+			recorder := search(s, url.Values{"query": {"Berserk"}, "source_id": {mangadex.ID}})
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+
+			var output inline.Output
+			So(json.Unmarshal(recorder.Body.Bytes(), &output), ShouldBeNil)
+
+			Convey("Then it should be found as in the inline mode", func() {
+				So(output.Query, ShouldEqual, "Berserk")
+				So(output.Result, ShouldHaveLength, 1)
+				So(output.Result[0].Source, ShouldEqual, "MangaDex")
+				So(output.Result[0].Mangal.URL, ShouldEqual, linkURL)
+				So(output.Result[0].Progress, ShouldBeNil)
+			})
+		})
+
+		Convey("When the chapters of the manga are selected", func() {
+			recorder := search(s, url.Values{"query": {"Berserk"}, "manga": {"first"}, "chapters": {"last"}, "source_id": {mangadex.ID}})
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+
+			var output inline.Output
+			So(json.Unmarshal(recorder.Body.Bytes(), &output), ShouldBeNil)
+
+			Convey("Then the selected chapters and the progress should be included", func() {
+				So(output.Result, ShouldHaveLength, 1)
+				So(output.Result[0].Mangal.Chapters, ShouldHaveLength, 1)
+				So(output.Result[0].Mangal.Chapters[0].Name, ShouldEqual, "Chapter 2")
+				So(output.Result[0].Progress.Total, ShouldEqual, 2)
+			})
+		})
+	})
+}
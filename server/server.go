@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether mangal is able to serve requests
+type Check func() error
+
+// Server is the http server of the serve mode
+type Server struct {
+	mux    *http.ServeMux
+	http   *http.Server
+	ready  int32
+	mutex  sync.RWMutex
+	checks map[string]Check
+
+	// root is the downloads directory at the time server was created,
+	// it stays the same while chapters are downloaded to personal libraries
+	root    string
+	sources *sources
+	queue   *queue
+}
+
+// New creates a server with /healthz and /readyz endpoints
+// and the /api/ endpoints for the users
+func New() (*Server, error) {
+	if err := validate(); err != nil {
+		return nil, err
+	}
+
+	if err := downloader.Preflight(); err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		mux:     http.NewServeMux(),
+		checks:  make(map[string]Check),
+		root:    where.Downloads(),
+		sources: newSources(),
+	}
+	s.queue = newQueue(s.download)
+
+	s.mux.HandleFunc("/healthz", s.healthz)
+	s.mux.HandleFunc("/readyz", s.readyz)
+	s.mux.Handle("/api/", s.api())
+
+	if viper.GetBool(key.ServerPprof) {
+		s.mux.Handle("/debug/pprof/", profiles())
+	}
+
+	s.http = &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s, nil
+}
+
+// Handle registers the handler for the given pattern
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// AddCheck adds a readiness check.
+// Server is not ready while any of the checks fails
+func (s *Server) AddCheck(name string, check Check) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.checks[name] = check
+}
+
+// SetReady marks the server as ready or not
+func (s *Server) SetReady(ready bool) {
+	var value int32
+	if ready {
+		value = 1
+	}
+
+	atomic.StoreInt32(&s.ready, value)
+}
+
+// Ready returns true if the server was marked as ready
+func (s *Server) Ready() bool {
+	return atomic.LoadInt32(&s.ready) == 1
+}
+
+// Serve accepts connections on the listener until the server is shut down.
+// The server is marked as ready once it starts serving
+func (s *Server) Serve(listener net.Listener) error {
+	log.Infof("serving on %s", listener.Addr())
+	s.SetReady(true)
+
+	err := s.http.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown marks the server as not ready and
+// waits for the active connections to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	log.Info("shutting down the server")
+	s.SetReady(false)
+	defer s.queue.close()
+	return s.http.Shutdown(ctx)
+}
+
+// ServeHTTP makes server usable as a handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// healthz reports that the process is alive
+func (s *Server) healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+type readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// readyz reports whether the server is ready to accept requests
+func (s *Server) readyz(w http.ResponseWriter, _ *http.Request) {
+	s.mutex.RLock()
+	names := make([]string, 0, len(s.checks))
+	for name := range s.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	response := readiness{
+		Ready:  s.Ready(),
+		Checks: make(map[string]string, len(names)),
+	}
+
+	for _, name := range names {
+		if err := s.checks[name](); err != nil {
+			log.Warnf("readiness check %s failed: %s", name, err)
+			response.Ready = false
+			response.Checks[name] = err.Error()
+		} else {
+			response.Checks[name] = "ok"
+		}
+	}
+	s.mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !response.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+
+	// the server does not start when the downloads path is missing
+	lo.Must0(filesystem.Api().MkdirAll(where.Downloads(), os.ModePerm))
+}
+
+func get(s *Server, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func TestServer(t *testing.T) {
+	Convey("Given a new server", t, func() {
+		s, err := New()
+		So(err, ShouldBeNil)
+
+		Convey("Then it should be healthy", func() {
+			So(get(s, "/healthz").Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Then it should not be ready until it starts serving", func() {
+			So(get(s, "/readyz").Code, ShouldEqual, http.StatusServiceUnavailable)
+		})
+
+		Convey("When it is marked as ready", func() {
+			s.SetReady(true)
+
+			Convey("Then it should be ready", func() {
+				So(get(s, "/readyz").Code, ShouldEqual, http.StatusOK)
+			})
+
+			Convey("And a check fails", func() {
+				s.AddCheck("downloads", func() error { return fmt.Errorf("not mounted") })
+				s.AddCheck("config", func() error { return nil })
+				recorder := get(s, "/readyz")
+
+				Convey("Then it should not be ready and report the failed check", func() {
+					So(recorder.Code, ShouldEqual, http.StatusServiceUnavailable)
+
+					var response readiness
+					So(json.Unmarshal(recorder.Body.Bytes(), &response), ShouldBeNil)
+					So(response.Ready, ShouldBeFalse)
+					So(response.Checks, ShouldResemble, map[string]string{
+						"downloads": "not mounted",
+						"config":    "ok",
+					})
+				})
+			})
+		})
+	})
+}
+
+func TestProfiles(t *testing.T) {
+	Convey("Given a new server", t, func() {
+		Convey("When the profiles are not enabled", func() {
+			s, err := New()
+			So(err, ShouldBeNil)
+
+			Convey("Then they should not be served", func() {
+				So(get(s, "/debug/pprof/").Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When the profiles are enabled", func() {
+			viper.Set(key.ServerPprof, true)
+			defer viper.Set(key.ServerPprof, false)
+
+			s, err := New()
+			So(err, ShouldBeNil)
+
+			Convey("Then they should be served", func() {
+				So(get(s, "/debug/pprof/cmdline").Code, ShouldEqual, http.StatusOK)
+			})
+
+			Convey("And the authentication is required", func() {
+				viper.Set(key.ServerAuth, AuthHeader)
+				defer viper.Set(key.ServerAuth, AuthNone)
+
+				Convey("Then they should not be served to the anonymous users", func() {
+					So(get(s, "/debug/pprof/heap").Code, ShouldEqual, http.StatusUnauthorized)
+				})
+			})
+		})
+	})
+}
+
+func TestActivatedListener(t *testing.T) {
+	Convey("Given mangal was not socket activated", t, func() {
+		So(os.Unsetenv(envListenPid), ShouldBeNil)
+		So(os.Unsetenv(envListenFds), ShouldBeNil)
+
+		Convey("Then no listener should be returned", func() {
+			listener, err := activatedListener()
+			So(err, ShouldBeNil)
+			So(listener, ShouldBeNil)
+		})
+	})
+
+	Convey("Given sockets were passed to another process", t, func() {
+		So(os.Setenv(envListenPid, strconv.Itoa(os.Getpid()+1)), ShouldBeNil)
+		So(os.Setenv(envListenFds, "1"), ShouldBeNil)
+
+		Convey("Then they should be ignored", func() {
+			listener, err := activatedListener()
+			So(err, ShouldBeNil)
+			So(listener, ShouldBeNil)
+		})
+
+		Reset(func() {
+			_ = os.Unsetenv(envListenPid)
+			_ = os.Unsetenv(envListenFds)
+		})
+	})
+
+	Convey("When listening without socket activation", t, func() {
+		listener, activated, err := Listen("127.0.0.1:0")
+		So(err, ShouldBeNil)
+		defer listener.Close()
+
+		Convey("Then it should listen on the address", func() {
+			So(activated, ShouldBeFalse)
+			So(listener.Addr().String(), ShouldStartWith, "127.0.0.1:")
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"sync"
+)
+
+// sources are created once and shared between the requests.
+// Sources are safe for concurrent use
+type sources struct {
+	mutex   sync.Mutex
+	created map[string]source.Source
+}
+
+func newSources() *sources {
+	return &sources{created: make(map[string]source.Source)}
+}
+
+// get returns the source with the given ID, creating it if needed
+func (s *sources) get(id string) (source.Source, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if src, ok := s.created[id]; ok {
+		return src, nil
+	}
+
+	p, ok := provider.GetByID(id)
+	if !ok {
+		return nil, fmt.Errorf("source %s not found", id)
+	}
+
+	src, err := p.CreateSource()
+	if err != nil {
+		return nil, err
+	}
+
+	s.created[id] = src
+	return src, nil
+}
+
+// chaptersOf returns the chapters of the referenced manga
+func chaptersOf(src source.Source, ref *MangaRef) ([]*source.Chapter, error) {
+	manga := &source.Manga{
+		Name:   ref.Name,
+		URL:    ref.URL,
+		ID:     ref.ID,
+		Source: src,
+	}
+
+	chapters, err := src.ChaptersOf(manga)
+	if err != nil {
+		return nil, err
+	}
+
+	manga.Chapters = chapters
+	return chapters, nil
+}
@@ -1,17 +1,22 @@
 package source
 
 import (
+	"context"
 	"fmt"
 	"github.com/dustin/go-humanize"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
 	"github.com/samber/mo"
 	"github.com/spf13/viper"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,15 +29,35 @@ type Chapter struct {
 	// URL of the chapter
 	URL string `json:"url" jsonschema:"description=URL of the chapter"`
 	// Index of the chapter in the manga.
-	Index uint16 `json:"index" jsonschema:"description=Index of the chapter in the manga"`
+	Index int `json:"index" jsonschema:"description=Index of the chapter in the manga"`
 	// ID of the chapter in the source.
 	ID string `json:"id" jsonschema:"description=ID of the chapter in the source"`
 	// Volume which the chapter belongs to.
 	Volume string `json:"volume" jsonschema:"description=Volume which the chapter belongs to"`
+	// Title of the chapter given by the source, if any.
+	// Name may already include it, e.g. "Chapter 1 - The Black Swordsman"
+	Title string `json:"title,omitempty" jsonschema:"description=Title of the chapter given by the source, if any"`
+	// Notes of the translators or the source about the chapter.
+	Notes string `json:"notes,omitempty" jsonschema:"description=Notes of the translators or the source about the chapter"`
+	// Language of the chapter, if the source has the chapters in several languages, e.g. "en" or "pt-br".
+	// See TranslatedLanguage for the one of the manga.
+	Language string `json:"language,omitempty" jsonschema:"description=Language of the chapter, if the source has the chapters in several languages"`
+	// Scanlators are the groups that translated the chapter, if known.
+	Scanlators []string `json:"scanlators,omitempty" jsonschema:"description=Groups that translated the chapter, if known"`
 	// Manga that the chapter belongs to.
 	Manga *Manga `json:"-"`
 	// Pages of the chapter.
 	Pages []*Page `json:"pages" jsonschema:"description=Pages of the chapter"`
+	// Text is the contents of the text chapter (e.g. light novel).
+	// Text chapters have no pages.
+	Text string `json:"text,omitempty" jsonschema:"description=Contents of the text chapter (e.g. light novel). Empty for image chapters"`
+	// TextFormat is the markup used by the Text.
+	TextFormat string `json:"textFormat,omitempty" jsonschema:"enum=html,enum=markdown,description=Markup used by the text chapter contents"`
+	// EstimatedSize of the chapter in bytes, see EstimateSize.
+	EstimatedSize uint64 `json:"estimatedSize,omitempty" jsonschema:"description=Estimated size of the chapter in bytes. Present if the size estimation is enabled"`
+	// Format that the chapter is saved in.
+	// Empty for the first of the formats in the config, see Formats
+	Format string `json:"-"`
 
 	isDownloaded mo.Option[bool]
 	size         uint64
@@ -42,36 +67,114 @@ func (c *Chapter) String() string {
 	return c.Name
 }
 
+// TranslatedLanguage returns the language of the chapter, the one of the manga if the source doesn't tell
+func (c *Chapter) TranslatedLanguage() string {
+	if c.Language != "" || c.Manga == nil {
+		return c.Language
+	}
+
+	return c.Manga.Metadata.Language
+}
+
+// ContentLanguage returns the language tag of the pages, "ja" for the raw mangas and the translated language otherwise.
+// It is empty if the language is unknown
+func (c *Chapter) ContentLanguage() string {
+	if c.Manga != nil && c.Manga.IsRaw() {
+		return "ja"
+	}
+
+	return c.TranslatedLanguage()
+}
+
+// ChapterLess reports whether the chapter a comes before b: by their indexes,
+// and by their names in the natural order if the source gives them the same index, see util.NaturalLess
+func ChapterLess(a, b *Chapter) bool {
+	if a.Index != b.Index {
+		return a.Index < b.Index
+	}
+
+	return util.NaturalLess(a.Name, b.Name)
+}
+
+// Passthrough reports whether pages of the chapter must be saved exactly as they were downloaded.
+// It is the case for the raw manga, so that translators get the original scans.
+func (c *Chapter) Passthrough() bool {
+	return c.Manga.IsRaw() && c.settings().rawPassthrough()
+}
+
+// IsText reports whether the chapter is a text chapter rather than a set of page images.
+func (c *Chapter) IsText() bool {
+	return c.Text != ""
+}
+
 // DownloadPages downloads the Pages contents of the Chapter.
 // Pages needs to be set before calling this function.
-func (c *Chapter) DownloadPages(temp bool, progress func(string)) (err error) {
+func (c *Chapter) DownloadPages(temp bool, progress func(string)) error {
+	return c.DownloadPagesContext(context.Background(), temp, progress)
+}
+
+// DownloadPagesContext downloads the Pages contents of the Chapter until the context is canceled.
+// Pages needs to be set before calling this function.
+func (c *Chapter) DownloadPagesContext(ctx context.Context, temp bool, progress func(string)) error {
+	return c.DownloadPagesWith(ctx, temp, progress, nil)
+}
+
+// DownloadPagesWith downloads the Pages contents of the Chapter, like DownloadPagesContext,
+// and calls downloaded with each page once it is downloaded, e.g. to save it for the resumed downloads.
+// Pages whose contents are set already, e.g. restored by the resumed download, are not downloaded again
+func (c *Chapter) DownloadPagesWith(ctx context.Context, temp bool, progress func(string), downloaded func(*Page)) (err error) {
 	c.size = 0
 	status := func() string {
-		return fmt.Sprintf(
+		return i18n.T(
 			"Downloading %s %s",
-			util.Quantify(len(c.Pages), "page", "pages"),
+			i18n.N(len(c.Pages), "page", "pages"),
 			style.Faint(c.SizeHuman()),
 		)
 	}
 
 	progress(status())
-	wg := sync.WaitGroup{}
-	wg.Add(len(c.Pages))
 
-	for _, page := range c.Pages {
+	for i, page := range c.Pages {
 		if page == nil {
-			return fmt.Errorf("page #%d is empty, aborting download", page.Index)
+			return fmt.Errorf("page #%d is empty, aborting download", i)
 		}
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+
+	wg.Add(len(c.Pages))
 
+	for _, page := range c.Pages {
 		d := func(page *Page) {
 			defer wg.Done()
 
 			// if at any point, an error is encountered, stop downloading other pages
-			if err != nil {
+			mutex.Lock()
+			failed := err != nil
+			mutex.Unlock()
+
+			if failed || ctx.Err() != nil {
 				return
 			}
 
-			err = page.Download()
+			var pageErr error
+			if page.Contents == nil {
+				pageErr = page.DownloadContext(ctx)
+				if pageErr == nil && downloaded != nil {
+					downloaded(page)
+				}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if pageErr != nil && err == nil {
+				err = pageErr
+			}
+
 			c.size += page.Size
 			progress(status())
 		}
@@ -85,7 +188,17 @@ func (c *Chapter) DownloadPages(temp bool, progress func(string)) (err error) {
 
 	wg.Wait()
 
+	// pages that were not started yet don't report the cancellation
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+
 	if err != nil {
+		// do not keep the pages of the failed chapter in memory for the rest of the batch
+		for _, page := range c.Pages {
+			page.Contents = nil
+		}
+
 		c.isDownloaded = mo.Some(false)
 		return err
 	}
@@ -94,28 +207,119 @@ func (c *Chapter) DownloadPages(temp bool, progress func(string)) (err error) {
 	return
 }
 
+// estimateWorkers is the number of the pages whose sizes are requested at once
+const estimateWorkers = 8
+
+// EstimateSize sets the EstimatedSize of the chapter from the sizes of its pages, reported by the servers.
+// Pages are fetched from the source if they are not set, the servers are not asked about the downloaded ones.
+// Pages of the unknown sizes are assumed to be of the average size of the others
+func (c *Chapter) EstimateSize(ctx context.Context) (uint64, error) {
+	if c.IsText() {
+		c.EstimatedSize = uint64(len(c.Text))
+		return c.EstimatedSize, nil
+	}
+
+	pages := c.Pages
+	if len(pages) == 0 {
+		var err error
+		if pages, err = c.Source().PagesOf(c); err != nil {
+			return 0, err
+		}
+	}
+
+	var (
+		wg          sync.WaitGroup
+		mutex       sync.Mutex
+		total       uint64
+		known       int
+		workers     = make(chan struct{}, estimateWorkers)
+		lastErr     error
+		pagesToSize = lo.Filter(pages, func(page *Page, _ int) bool { return page != nil })
+	)
+
+	for _, page := range pagesToSize {
+		wg.Add(1)
+		workers <- struct{}{}
+
+		go func(page *Page) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			size, ok := page.loadedSize()
+			var err error
+			if !ok {
+				size, ok, err = page.SizeContext(ctx)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				page.logger().Warnf("estimating the size of page #%d: %s", page.Index, err)
+				lastErr = err
+				return
+			}
+
+			if ok {
+				total += size
+				known++
+			}
+		}(page)
+	}
+
+	wg.Wait()
+
+	if known == 0 {
+		if lastErr != nil {
+			return 0, lastErr
+		}
+
+		if len(pagesToSize) > 0 {
+			return 0, fmt.Errorf("sizes of the pages of %s are unknown", c.Name)
+		}
+	} else {
+		total = total * uint64(len(pagesToSize)) / uint64(known)
+	}
+
+	c.EstimatedSize = total
+	return total, nil
+}
+
 // formattedName of the chapter according to the template in the config.
-func (c *Chapter) formattedName() (name string) {
-	name = viper.GetString(key.DownloaderChapterNameTemplate)
+func (c *Chapter) formattedName() string {
+	if viper.GetString(key.DownloaderChapterTitle) == "number" {
+		return fmt.Sprintf("%04d", c.LibraryIndex())
+	}
+
+	index := c.LibraryIndex()
 
 	var sourceName string
 	if c.Source() != nil {
 		sourceName = c.Source().Name()
 	}
 
-	for variable, value := range map[string]string{
+	return util.Render(viper.GetString(key.DownloaderChapterNameTemplate), map[string]string{
 		"manga":          c.Manga.Name,
-		"chapter":        c.Name,
-		"index":          fmt.Sprintf("%d", c.Index),
-		"padded-index":   fmt.Sprintf("%04d", c.Index),
+		"title":          c.Manga.Title(),
+		"author":         strings.Join(c.Manga.Authors(), ", "),
+		"artist":         strings.Join(c.Manga.Artists(), ", "),
+		"chapter":        c.title(),
+		"index":          fmt.Sprintf("%d", index),
+		"padded-index":   fmt.Sprintf("%04d", index),
 		"chapters-count": fmt.Sprintf("%d", len(c.Manga.Chapters)),
 		"volume":         c.Volume,
 		"source":         sourceName,
-	} {
-		name = strings.ReplaceAll(name, fmt.Sprintf("{%s}", variable), value)
-	}
+	})
+}
 
-	return
+// title of the chapter to use in the filenames, according to the config.
+func (c *Chapter) title() string {
+	switch viper.GetString(key.DownloaderChapterTitle) {
+	case "none", "number":
+		return ""
+	default:
+		return util.TruncateWords(c.Name, viper.GetInt(key.DownloaderChapterTitleLength))
+	}
 }
 
 // SizeHuman is the same as Size but returns a human-readable string.
@@ -128,24 +332,71 @@ func (c *Chapter) Filename() (filename string) {
 
 	// plain format assumes that chapter is a directory with images
 	// rather than a single file. So no need to add extension to it
-	if f := viper.GetString(key.FormatsUse); f != constant.FormatPlain {
+	if f := c.format(); f != constant.FormatPlain {
 		return filename + "." + f
 	}
 
 	return
 }
 
+// substituted reports whether the chapter was saved in a fallback format instead of its own one, see RegisterSubstitutes
+var substituted = func(*Chapter) bool { return false }
+
+// RegisterSubstitutes sets how the chapters saved in the fallback formats are found, see key.FormatsFallback.
+// They are recorded by the manifest, which depends on the sources, so it registers itself
+func RegisterSubstitutes(isSubstituted func(chapter *Chapter) bool) {
+	substituted = isSubstituted
+}
+
+// ResolvedFormat returns the format that the chapter is saved in, the primary one if Format is empty
+func (c *Chapter) ResolvedFormat() string {
+	return c.format()
+}
+
+// format that the chapter is saved in.
+func (c *Chapter) format() string {
+	if c.Format != "" {
+		return c.Format
+	}
+
+	if formats := c.Formats(); len(formats) > 0 {
+		return formats[0]
+	}
+
+	return ""
+}
+
+// As returns the copy of the chapter to be saved in the given format.
+func (c *Chapter) As(format string) *Chapter {
+	formatted := *c
+	formatted.Format = format
+	formatted.isDownloaded = mo.None[bool]()
+	return &formatted
+}
+
 func (c *Chapter) IsDownloaded() bool {
 	if c.isDownloaded.IsPresent() {
 		return c.isDownloaded.MustGet()
 	}
 
-	path, _ := c.path(c.Manga.peekPath(), false)
+	path, _ := c.path(c.Manga.PeekPath(), false)
 	exists, _ := filesystem.Api().Exists(path)
+
+	// the chapter saved in another format, since the converter of its own one has failed
+	if !exists {
+		exists = substituted(c)
+	}
+
 	c.isDownloaded = mo.Some(exists)
 	return exists
 }
 
+// PeekPath returns the path chapter would be downloaded to, without creating any directories
+func (c *Chapter) PeekPath() string {
+	path, _ := c.path(c.Manga.PeekPath(), false)
+	return path
+}
+
 func (c *Chapter) path(relativeTo string, createVolumeDir bool) (path string, err error) {
 	if createVolumeDir {
 		path = filepath.Join(path, util.SanitizeFilename(c.Volume))
@@ -173,6 +424,18 @@ func (c *Chapter) Source() Source {
 	return c.Manga.Source
 }
 
+var volumeNumberRegex = regexp.MustCompile(`\d+`)
+
+// volumeNumber returns the number of the volume, e.g. 3 for "Vol. 3". Zero if it has none
+func (c *Chapter) volumeNumber() int {
+	number, err := strconv.Atoi(volumeNumberRegex.FindString(c.Volume))
+	if err != nil {
+		return 0
+	}
+
+	return number
+}
+
 func (c *Chapter) ComicInfo() *ComicInfo {
 	var (
 		day, month, year int
@@ -192,28 +455,57 @@ func (c *Chapter) ComicInfo() *ComicInfo {
 		}
 	} // empty dates will be omitted
 
+	// webtoons are read from top to bottom, not from right to left
+	var format, manga = "", "YesAndRightToLeft"
+	if c.Manga.Metadata.LongStrip {
+		format, manga = "Webtoon", "Yes"
+	}
+
+	language := c.ContentLanguage()
+
+	title := c.Name
+	if c.Title != "" {
+		title = c.Title
+	}
+
+	// the name shown by the source is usually the translated one
+	var localizedSeries string
+	if series := c.Manga.Title(); series != c.Manga.Name {
+		localizedSeries = c.Manga.Name
+	}
+
+	notes := "Downloaded with Mangal. https://github.com/metafates/mangal"
+	if c.Notes != "" {
+		notes = c.Notes + "\n\n" + notes
+	}
+
 	return &ComicInfo{
 		XmlnsXsd: "http://www.w3.org/2001/XMLSchema",
 		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
 
-		Title:      c.Name,
-		Series:     c.Manga.Name,
-		Number:     int(c.Index),
-		Web:        c.URL,
-		Genre:      strings.Join(c.Manga.Metadata.Genres, ","),
-		PageCount:  len(c.Pages),
-		Summary:    c.Manga.Metadata.Summary,
-		Count:      c.Manga.Metadata.Chapters,
-		Characters: strings.Join(c.Manga.Metadata.Characters, ","),
-		Year:       year,
-		Month:      month,
-		Day:        day,
-		Writer:     strings.Join(c.Manga.Metadata.Staff.Story, ","),
-		Penciller:  strings.Join(c.Manga.Metadata.Staff.Art, ","),
-		Letterer:   strings.Join(c.Manga.Metadata.Staff.Lettering, ","),
-		Translator: strings.Join(c.Manga.Metadata.Staff.Translation, ","),
-		Tags:       strings.Join(c.Manga.Metadata.Tags, ","),
-		Notes:      "Downloaded with Mangal. https://github.com/metafates/mangal",
-		Manga:      "YesAndRightToLeft",
+		Title:           title,
+		Series:          c.Manga.Title(),
+		LocalizedSeries: localizedSeries,
+		Number:          c.LibraryIndex(),
+		Volume:          c.volumeNumber(),
+		Web:             c.URL,
+		Genre:           strings.Join(c.Manga.Metadata.Genres, ","),
+		PageCount:       len(c.Pages),
+		Summary:         c.Manga.Metadata.Summary,
+		Count:           c.Manga.Metadata.Chapters,
+		Characters:      strings.Join(c.Manga.Metadata.Characters, ","),
+		Year:            year,
+		Month:           month,
+		Day:             day,
+		Writer:          strings.Join(c.Manga.Authors(), ","),
+		Penciller:       strings.Join(c.Manga.Artists(), ","),
+		Letterer:        strings.Join(c.Manga.Metadata.Staff.Lettering, ","),
+		Translator:      strings.Join(c.Manga.Metadata.Staff.Translation, ","),
+		ScanInformation: strings.Join(c.Scanlators, ","),
+		Tags:            strings.Join(c.Manga.Metadata.Tags, ","),
+		Notes:           notes,
+		Format:          format,
+		Manga:           manga,
+		LanguageISO:     language,
 	}
 }
@@ -1,6 +1,8 @@
 package source
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/filesystem"
@@ -8,6 +10,9 @@ import (
 	"github.com/metafates/mangal/util"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"sort"
 	"testing"
 )
 
@@ -42,6 +47,79 @@ func TestChapter_Filename(t *testing.T) {
 	})
 }
 
+func TestChapter_FilenameStaff(t *testing.T) {
+	Convey("Given a chapter of the manga with the known staff", t, func() {
+		manga := testManga
+		manga.Metadata.Staff.Story = []string{"Tsugumi Ohba"}
+		manga.Metadata.Staff.Art = []string{"Takeshi Obata"}
+		chapter := testChapter
+		chapter.Manga = &manga
+
+		Convey("When the template has the staff", func() {
+			viper.Set(key.DownloaderChapterNameTemplate, "{author} - {artist} - {chapter}")
+
+			Convey("It should be in the filename", func() {
+				So(chapter.Filename(), ShouldEqual, "Tsugumi_Ohba_-_Takeshi_Obata_-_test_chapter.pdf")
+			})
+		})
+	})
+}
+
+func TestChapter_FilenameTitle(t *testing.T) {
+	Convey("Given a chapter with a long title", t, func() {
+		chapter := testChapter
+		chapter.Name = "The Black Swordsman returns"
+		viper.Set(key.DownloaderChapterNameTemplate, "[{padded-index}] {chapter}")
+		defer viper.Set(key.DownloaderChapterTitle, "full")
+		defer viper.Set(key.DownloaderChapterTitleLength, 0)
+
+		Convey("When the title is truncated", func() {
+			viper.Set(key.DownloaderChapterTitleLength, 20)
+			Convey("It should be cut at the word boundary", func() {
+				So(chapter.Filename(), ShouldEqual, "[0001]_The_Black_Swordsman.pdf")
+			})
+		})
+
+		Convey("When the title is excluded", func() {
+			viper.Set(key.DownloaderChapterTitle, "none")
+			Convey("It should keep the rest of the template", func() {
+				So(chapter.Filename(), ShouldEqual, "[0001].pdf")
+			})
+		})
+
+		Convey("When chapters are named by their numbers", func() {
+			viper.Set(key.DownloaderChapterTitle, "number")
+			viper.Set(key.DownloaderChapterNameTemplate, "{manga} {chapter}")
+			Convey("It should ignore the template", func() {
+				So(chapter.Filename(), ShouldEqual, "0001.pdf")
+			})
+		})
+	})
+}
+
+func TestFormats(t *testing.T) {
+	Convey("Given several formats in the config", t, func() {
+		viper.Set(key.FormatsUse, " cbz, PDF,,cbz")
+		defer viper.Set(key.FormatsUse, constant.FormatPDF)
+
+		Convey("When Formats is called", func() {
+			Convey("It should return each of them once", func() {
+				So(Formats(), ShouldResemble, []string{constant.FormatCBZ, constant.FormatPDF})
+				So(PrimaryFormat(), ShouldEqual, constant.FormatCBZ)
+			})
+		})
+
+		Convey("When the chapter is saved in another format", func() {
+			viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+			Convey("It should have the extension of that format", func() {
+				So(testChapter.Filename(), ShouldEqual, "test_chapter.cbz")
+				So(testChapter.As(constant.FormatPDF).Filename(), ShouldEqual, "test_chapter.pdf")
+				So(testChapter.As(constant.FormatPlain).Filename(), ShouldEqual, "test_chapter")
+			})
+		})
+	})
+}
+
 func TestChapter_ComicInfoXML(t *testing.T) {
 	Convey("Given a chapter", t, func() {
 		Convey("When ComicInfo is called", func() {
@@ -52,3 +130,155 @@ func TestChapter_ComicInfoXML(t *testing.T) {
 		})
 	})
 }
+
+func TestChapter_ComicInfoTitleAndNotes(t *testing.T) {
+	Convey("Given a chapter with a title and notes from the source", t, func() {
+		chapter := testChapter
+		chapter.Name = "Chapter 1 - The Black Swordsman"
+		chapter.Title = "The Black Swordsman"
+		chapter.Notes = "Thanks to the raw provider"
+
+		Convey("When ComicInfo is called", func() {
+			info := chapter.ComicInfo()
+
+			Convey("Then the title should be used instead of the name", func() {
+				So(info.Title, ShouldEqual, chapter.Title)
+			})
+
+			Convey("Then the notes should be kept", func() {
+				So(info.Notes, ShouldStartWith, chapter.Notes)
+				So(info.Notes, ShouldContainSubstring, "Downloaded with Mangal")
+			})
+		})
+	})
+
+	Convey("Given a chapter without a title", t, func() {
+		Convey("Then the name should be the title", func() {
+			So(testChapter.ComicInfo().Title, ShouldEqual, testChapter.Name)
+		})
+	})
+}
+
+func TestChapter_ComicInfoSeries(t *testing.T) {
+	Convey("Given a chapter of a manga with the canonical title", t, func() {
+		manga := testManga
+		manga.Name = "Attack on Titan"
+		manga.Metadata.Title = "Shingeki no Kyojin"
+		chapter := testChapter
+		chapter.Manga = &manga
+
+		Convey("Then the canonical title should be the series and the name the localized one", func() {
+			info := chapter.ComicInfo()
+			So(info.Series, ShouldEqual, "Shingeki no Kyojin")
+			So(info.LocalizedSeries, ShouldEqual, "Attack on Titan")
+		})
+	})
+}
+
+func TestChapter_ComicInfoVolumeAndLanguage(t *testing.T) {
+	Convey("Given a chapter of the named volume translated by the groups", t, func() {
+		manga := testManga
+		manga.Metadata.Language = "pt-br"
+		chapter := testChapter
+		chapter.Manga = &manga
+		chapter.Volume = "Vol. 12"
+		chapter.Scanlators = []string{"Lua", "Sol"}
+
+		Convey("When ComicInfo is called", func() {
+			info := chapter.ComicInfo()
+
+			Convey("Then the number of the volume should be used", func() {
+				So(info.Volume, ShouldEqual, 12)
+			})
+
+			Convey("Then the groups and the language should be kept", func() {
+				So(info.ScanInformation, ShouldEqual, "Lua,Sol")
+				So(info.LanguageISO, ShouldEqual, "pt-br")
+			})
+		})
+
+		Convey("When the volume has no number", func() {
+			chapter.Volume = "Extras"
+
+			Convey("Then it should be omitted", func() {
+				So(chapter.ComicInfo().Volume, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestChapter_EstimateSize(t *testing.T) {
+	Convey("Given a chapter whose pages are served differently", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/head.jpg":
+				w.Header().Set("Content-Length", "100")
+			case "/get.jpg":
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+
+				_, _ = w.Write(make([]byte, 300))
+			case "/chunked.jpg":
+				if r.Method == http.MethodGet {
+					w.(http.Flusher).Flush()
+					_, _ = w.Write(make([]byte, 1000))
+				}
+			}
+		}))
+		defer server.Close()
+
+		chapter := testChapter
+		chapter.Pages = []*Page{
+			{URL: server.URL + "/head.jpg", Index: 1, Chapter: &chapter},
+			{URL: server.URL + "/get.jpg", Index: 2, Chapter: &chapter},
+			{URL: server.URL + "/chunked.jpg", Index: 3, Chapter: &chapter},
+		}
+
+		Convey("When its size is estimated", func() {
+			size, err := chapter.EstimateSize(context.Background())
+
+			Convey("Then the unknown sizes should be extrapolated from the known ones", func() {
+				So(err, ShouldBeNil)
+				So(size, ShouldEqual, 600)
+				So(chapter.EstimatedSize, ShouldEqual, 600)
+			})
+		})
+
+		Convey("When some pages are downloaded already", func() {
+			chapter.Pages[0].Size = 150
+			chapter.Pages[1].Contents = bytes.NewBuffer(make([]byte, 50))
+			chapter.Pages[2].URL = server.URL + "/missing.jpg"
+
+			size, err := chapter.EstimateSize(context.Background())
+
+			Convey("Then their sizes should be used instead of asking the server", func() {
+				So(err, ShouldBeNil)
+				So(size, ShouldEqual, 300)
+			})
+		})
+	})
+}
+
+func TestChapterLess(t *testing.T) {
+	Convey("Given chapters with the same index", t, func() {
+		chapters := []*Chapter{
+			{Name: "Ch 10", Index: 1},
+			{Name: "Ch 2", Index: 1},
+			{Name: "Ch 1", Index: 0},
+		}
+
+		Convey("When they are sorted", func() {
+			sort.Slice(chapters, func(i, j int) bool {
+				return ChapterLess(chapters[i], chapters[j])
+			})
+
+			Convey("Then they should be ordered by the index and then by the name", func() {
+				So(chapters[0].Name, ShouldEqual, "Ch 1")
+				So(chapters[1].Name, ShouldEqual, "Ch 2")
+				So(chapters[2].Name, ShouldEqual, "Ch 10")
+			})
+		})
+	})
+}
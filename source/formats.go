@@ -0,0 +1,122 @@
+package source
+
+import (
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DestinationLibrary is the name of the download path in the format rules, see FormatsFor
+const DestinationLibrary = "library"
+
+// Formats returns the formats to save the chapters in, e.g. "cbz,pdf" in the config.
+// Chapters are converted to each of them from the same downloaded pages.
+// The first one is the primary format, used to read chapters and to check if they are downloaded.
+// Format rules for the download path take precedence over the config
+func Formats() []string {
+	return FormatsIn(where.Downloads())
+}
+
+// FormatsIn returns the formats to save the chapters in the library at root, see Formats
+func FormatsIn(root string) []string {
+	if len(viper.GetStringSlice(key.FormatsRules)) > 0 {
+		if formats := FormatsFor(DestinationLibrary, root); len(formats) > 0 {
+			return formats
+		}
+	}
+
+	return ParseFormats(viper.GetString(key.FormatsUse))
+}
+
+// ParseFormats parses the comma-separated formats, e.g. "cbz,pdf" of formats.use
+func ParseFormats(formats string) []string {
+	return parseFormats(formats, ",")
+}
+
+// PrimaryFormat returns the first of the formats, see Formats.
+func PrimaryFormat() string {
+	if formats := Formats(); len(formats) > 0 {
+		return formats[0]
+	}
+
+	return ""
+}
+
+// FormatsFor returns the formats selected by the first format rule that matches any of the destinations.
+// Rules are given in the config as "destination=format" or "destination=format+format", where destination is
+// the DestinationLibrary, the name of a device profile or a path that matches the paths inside it.
+// Nil is returned if no rule matches
+func FormatsFor(destinations ...string) []string {
+	for _, rule := range viper.GetStringSlice(key.FormatsRules) {
+		destination, formats, ok := strings.Cut(rule, "=")
+		if !ok || strings.TrimSpace(destination) == "" {
+			log.Warnf("invalid format rule %q, expected destination=format", rule)
+			continue
+		}
+
+		destination = strings.TrimSpace(destination)
+		if lo.ContainsBy(destinations, func(d string) bool {
+			return matchesDestination(destination, d)
+		}) {
+			return parseFormats(formats, "+")
+		}
+	}
+
+	return nil
+}
+
+// matchesDestination reports whether the destination of the rule matches the given one.
+// Paths match the paths inside them, names must be equal
+func matchesDestination(rule, destination string) bool {
+	if strings.EqualFold(rule, destination) {
+		return true
+	}
+
+	if !isPath(rule) || !isPath(destination) {
+		return false
+	}
+
+	if rule == "~" || strings.HasPrefix(rule, "~"+string(os.PathSeparator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+
+		rule = filepath.Join(home, strings.TrimPrefix(rule, "~"))
+	}
+
+	rule, err := filepath.Abs(os.ExpandEnv(rule))
+	if err != nil {
+		return false
+	}
+
+	destination, err = filepath.Abs(destination)
+	if err != nil {
+		return false
+	}
+
+	relative, err := filepath.Rel(rule, destination)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(os.PathSeparator))
+}
+
+// isPath reports whether the destination is a path rather than a name
+func isPath(destination string) bool {
+	return strings.ContainsRune(destination, os.PathSeparator) ||
+		strings.ContainsRune(destination, '/') ||
+		strings.HasPrefix(destination, "~") ||
+		strings.HasPrefix(destination, ".")
+}
+
+// parseFormats splits the formats by the separator, dropping the empty and repeated ones
+func parseFormats(formats, separator string) []string {
+	parsed := lo.Map(strings.Split(formats, separator), func(format string, _ int) string {
+		return strings.ToLower(strings.TrimSpace(format))
+	})
+
+	return lo.Uniq(lo.Compact(parsed))
+}
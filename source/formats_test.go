@@ -0,0 +1,51 @@
+package source
+
+import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatsFor(t *testing.T) {
+	Convey("Given format rules for the destinations", t, func() {
+		library := filepath.Join(t.TempDir(), "library")
+		viper.Set(key.DownloaderPath, library)
+		defer viper.Set(key.DownloaderPath, "")
+		viper.Set(key.FormatsRules, []string{
+			"broken",
+			"kindle=pdf",
+			filepath.Join(library, "epub") + "=epub",
+			"library=cbz+PDF",
+		})
+		defer viper.Set(key.FormatsRules, []string{})
+
+		Convey("When getting the formats of a device", func() {
+			Convey("Then the rule with its name should be used", func() {
+				So(FormatsFor("kindle", "/mnt/kindle"), ShouldResemble, []string{constant.FormatPDF})
+			})
+		})
+
+		Convey("When getting the formats of a path inside the ruled one", func() {
+			Convey("Then the rule with the path should be used", func() {
+				So(FormatsFor("kobo", filepath.Join(library, "epub", "device")), ShouldResemble, []string{constant.FormatEPUB})
+			})
+		})
+
+		Convey("When getting the formats of the unknown destination", func() {
+			Convey("Then none should be returned", func() {
+				So(FormatsFor("kobo", filepath.Join(library, "epubs")), ShouldBeNil)
+			})
+		})
+
+		Convey("When getting the formats of the downloads", func() {
+			Convey("Then the library rule should take precedence over the config", func() {
+				viper.Set(key.FormatsUse, constant.FormatZIP)
+				defer viper.Set(key.FormatsUse, constant.FormatPDF)
+				So(Formats(), ShouldResemble, []string{constant.FormatCBZ, constant.FormatPDF})
+			})
+		})
+	})
+}
@@ -0,0 +1,29 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ParseIndex parses the index of the manga, the chapter or the page,
+// e.g. the key of the table returned by the custom source
+func ParseIndex(s string) (int, error) {
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if index < 0 {
+		return 0, fmt.Errorf("index %d is negative", index)
+	}
+
+	return index, nil
+}
+
+// Reindex numbers the chapters from 1 in the order of the slice, from the oldest to the newest.
+// Sources that don't know the order of the chapters use their position on the page
+func Reindex(chapters []*Chapter) {
+	for i, chapter := range chapters {
+		chapter.Index = i + 1
+	}
+}
@@ -0,0 +1,73 @@
+package source
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"sort"
+	"testing"
+)
+
+// onePieceScale is more chapters than 16 bits can index
+const onePieceScale = 70_000
+
+func TestParseIndex(t *testing.T) {
+	Convey("Given an index beyond 16 bits", t, func() {
+		index, err := ParseIndex("70000")
+
+		Convey("Then it should be parsed", func() {
+			So(err, ShouldBeNil)
+			So(index, ShouldEqual, 70_000)
+		})
+	})
+
+	Convey("Given a negative index", t, func() {
+		_, err := ParseIndex("-1")
+
+		Convey("Then an error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given an index that is not a number", t, func() {
+		_, err := ParseIndex("first")
+
+		Convey("Then an error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestReindex(t *testing.T) {
+	Convey("Given a manga with more chapters than 16 bits can index", t, func() {
+		chapters := make([]*Chapter, onePieceScale)
+		for i := range chapters {
+			chapters[i] = &Chapter{Name: fmt.Sprintf("Chapter %d", i+1)}
+		}
+
+		Convey("When they are reindexed", func() {
+			Reindex(chapters)
+
+			Convey("Then every chapter should keep its own index", func() {
+				So(chapters[0].Index, ShouldEqual, 1)
+				So(chapters[65_535].Index, ShouldEqual, 65_536)
+				So(chapters[onePieceScale-1].Index, ShouldEqual, onePieceScale)
+			})
+
+			Convey("And the shuffled chapters should be sorted back", func() {
+				shuffled := make([]*Chapter, len(chapters))
+				for i, chapter := range chapters {
+					// 7919 is coprime with the size, so every chapter is moved
+					shuffled[(i*7919)%len(chapters)] = chapter
+				}
+
+				sort.Slice(shuffled, func(i, j int) bool {
+					return ChapterLess(shuffled[i], shuffled[j])
+				})
+
+				So(shuffled[0].Name, ShouldEqual, "Chapter 1")
+				So(shuffled[65_535].Name, ShouldEqual, "Chapter 65536")
+				So(shuffled[onePieceScale-1].Name, ShouldEqual, "Chapter 70000")
+			})
+		})
+	})
+}
@@ -3,9 +3,12 @@ package source
 import (
 	"fmt"
 	"github.com/metafates/mangal/anilist"
+	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
 	"github.com/metafates/mangal/util"
 	"github.com/metafates/mangal/where"
 	"github.com/samber/lo"
@@ -13,10 +16,12 @@ import (
 	"github.com/spf13/viper"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 type date struct {
@@ -25,6 +30,13 @@ type date struct {
 	Day   int `json:"day"`
 }
 
+// Publication statuses of the mangas, the same as on Anilist
+const (
+	StatusFinished  = "FINISHED"
+	StatusReleasing = "RELEASING"
+	StatusHiatus    = "HIATUS"
+)
+
 // Manga is a manga from a source.
 type Manga struct {
 	// Name of the manga
@@ -32,7 +44,7 @@ type Manga struct {
 	// URL of the manga
 	URL string `json:"url" jsonschema:"description=URL of the manga"`
 	// Index of the manga in the source.
-	Index uint16 `json:"index" jsonschema:"description=Index of the manga in the source"`
+	Index int `json:"index" jsonschema:"description=Index of the manga in the source"`
 	// ID of manga in the source.
 	ID string `json:"id" jsonschema:"description=ID of manga in the source"`
 	// Chapters of the manga
@@ -40,7 +52,9 @@ type Manga struct {
 	// Source that the manga belongs to.
 	Source Source `json:"-"`
 	// Anilist is the closest anilist match
-	Anilist  mo.Option[*anilist.Manga] `json:"-"`
+	Anilist mo.Option[*anilist.Manga] `json:"-"`
+	// Settings of the download of the manga, nil to use the config
+	Settings *Settings `json:"-"`
 	Metadata struct {
 		// Genres of the manga
 		Genres []string `json:"genres" jsonschema:"description=Genres of the manga"`
@@ -80,28 +94,48 @@ type Manga struct {
 		StartDate date `json:"startDate" jsonschema:"description=StartDate is the date when the manga started."`
 		// EndDate is the date when the manga ended.
 		EndDate date `json:"endDate" jsonschema:"description=EndDate is the date when the manga ended."`
-		// Synonyms other names of the manga.
-		Synonyms []string `json:"synonyms" jsonschema:"description=Synonyms other names of the manga."`
+		// Title is the canonical title of the manga, e.g. the one on Anilist. Name is the one shown by the source.
+		Title string `json:"title" jsonschema:"description=Canonical title of the manga, e.g. the one on Anilist. Name is the one shown by the source."`
+		// NativeTitle is the title in the original language of the manga. Usually in kanji.
+		NativeTitle string `json:"nativeTitle" jsonschema:"description=Title in the original language of the manga. Usually in kanji."`
+		// Synonyms are the alternative titles of the manga.
+		Synonyms []string `json:"synonyms" jsonschema:"description=Alternative titles of the manga."`
 		// Chapters is the amount of chapters the manga will have when completed.
 		Chapters int `json:"chapters" jsonschema:"description=The amount of chapters the manga will have when completed."`
 		// URLs external URLs of the manga.
 		URLs []string `json:"urls" jsonschema:"description=External URLs of the manga."`
+		// LongStrip is true if the manga is a vertical long strip (webtoon) rather than paged.
+		LongStrip bool `json:"longStrip" jsonschema:"description=True if the manga is a vertical long strip (webtoon) rather than paged."`
+		// Language of the manga. "raw" for untranslated scans.
+		Language string `json:"language" jsonschema:"description=Language of the manga. raw for untranslated scans."`
 	} `json:"metadata"`
 	cachedTempPath  string
 	populated       bool
 	coverDownloaded bool
+	cachedCover     []byte
 }
 
+// coverMutex guards Manga.cachedCover, the chapters of the same manga may be converted at once
+var coverMutex sync.Mutex
+
 func (m *Manga) String() string {
 	return m.Name
 }
 
+// IsRaw reports whether the manga is untranslated
+func (m *Manga) IsRaw() bool {
+	return m.Metadata.Language == constant.LanguageRaw
+}
+
+// Dirname returns the name of the directory of the manga inside the downloads path, see ResolveDirname.
+// The library of the Settings is used instead of the downloads path if it is set
 func (m *Manga) Dirname() string {
-	return util.SanitizeFilename(m.Name)
+	return m.DirnameIn(m.Settings.root())
 }
 
-func (m *Manga) peekPath() string {
-	path := where.Downloads()
+// PeekPath returns the path the manga would be downloaded to, without creating any directories
+func (m *Manga) PeekPath() string {
+	path := m.Settings.root()
 
 	if viper.GetBool(key.DownloaderCreateMangaDir) {
 		path = filepath.Join(path, m.Dirname())
@@ -110,6 +144,14 @@ func (m *Manga) peekPath() string {
 	return path
 }
 
+// BundlesDirname is the name of the directory inside the manga directory with the volume bundles, see key.FormatsVolumeBundles
+const BundlesDirname = "Volumes"
+
+// BundlePath returns the path of the cbz bundle of the volume, without creating any directories
+func (m *Manga) BundlePath(volume string) string {
+	return filepath.Join(m.PeekPath(), BundlesDirname, util.SanitizeFilename(m.Name+" "+volume)+".cbz")
+}
+
 func (m *Manga) Path(temp bool) (path string, err error) {
 	if temp {
 		if path = m.cachedTempPath; path != "" {
@@ -121,8 +163,14 @@ func (m *Manga) Path(temp bool) (path string, err error) {
 		return
 	}
 
-	path = m.peekPath()
-	_ = filesystem.Api().MkdirAll(path, os.ModePerm)
+	if !viper.GetBool(key.DownloaderCreateMangaDir) {
+		// the downloads path is not created by where.Downloads, it is checked before downloading instead
+		path = m.Settings.root()
+		err = filesystem.Api().MkdirAll(path, os.ModePerm)
+		return
+	}
+
+	path = m.claimDirIn(m.Settings.root())
 	return
 }
 
@@ -149,7 +197,7 @@ func (m *Manga) DownloadCover(overwrite bool, path string, progress func(string)
 	m.coverDownloaded = true
 
 	log.Info("Downloading cover for ", m.Name)
-	progress("Downloading cover")
+	progress(i18n.T("Downloading cover"))
 
 	cover, err := m.GetCover()
 	if err != nil {
@@ -157,12 +205,7 @@ func (m *Manga) DownloadCover(overwrite bool, path string, progress func(string)
 		return nil
 	}
 
-	var extension string
-	if extension = filepath.Ext(cover); extension == "" {
-		extension = ".jpg"
-	}
-
-	path = filepath.Join(path, "cover"+extension)
+	path = filepath.Join(path, "cover"+coverExtension(cover))
 
 	if !overwrite {
 		exists, err := filesystem.Api().Exists(path)
@@ -177,33 +220,68 @@ func (m *Manga) DownloadCover(overwrite bool, path string, progress func(string)
 		}
 	}
 
-	resp, err := http.Get(cover)
+	data, err := fetchCover(cover)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	defer util.Ignore(resp.Body.Close)
-
-	if resp.StatusCode != http.StatusOK {
+	err = filesystem.Api().WriteFile(path, data, os.ModePerm)
+	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	log.Info("Cover downloaded")
+	return nil
+}
+
+// CoverContents returns the cover image of the manga and its extension.
+// It is fetched once and kept for the other chapters
+func (m *Manga) CoverContents() ([]byte, string, error) {
+	cover, err := m.GetCover()
 	if err != nil {
-		log.Error(err)
-		return err
+		return nil, "", err
 	}
 
-	err = filesystem.Api().WriteFile(path, data, os.ModePerm)
+	coverMutex.Lock()
+	defer coverMutex.Unlock()
+
+	if m.cachedCover == nil {
+		if m.cachedCover, err = fetchCover(cover); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return m.cachedCover, coverExtension(cover), nil
+}
+
+// coverExtension returns the extension of the cover image, .jpg if the link has none
+func coverExtension(cover string) string {
+	if parsed, err := url.Parse(cover); err == nil {
+		cover = parsed.Path
+	}
+
+	if extension := filepath.Ext(cover); extension != "" {
+		return extension
+	}
+
+	return ".jpg"
+}
+
+func fetchCover(cover string) ([]byte, error) {
+	resp, err := network.Client.Get(cover)
 	if err != nil {
-		log.Error(err)
-		return err
+		return nil, err
 	}
 
-	log.Info("Cover downloaded")
-	return nil
+	defer util.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
 }
 
 func (m *Manga) BindWithAnilist() error {
@@ -214,6 +292,15 @@ func (m *Manga) BindWithAnilist() error {
 	log.Infof("binding %s with anilist", m.Name)
 
 	manga, err := anilist.FindClosest(m.Name)
+	if err != nil {
+		// the source may show the translated name, while anilist knows the original one
+		for _, title := range lo.Drop(m.Titles(), 1) {
+			if manga, err = anilist.FindClosest(title); err == nil {
+				break
+			}
+		}
+	}
+
 	if err != nil {
 		log.Error(err)
 		return err
@@ -229,10 +316,10 @@ func (m *Manga) PopulateMetadata(progress func(string)) error {
 	}
 	m.populated = true
 
-	progress("Fetching metadata from anilist")
+	progress(i18n.T("Fetching metadata from anilist"))
 	log.Infof("Populating metadata for %s", m.Name)
 	if err := m.BindWithAnilist(); err != nil {
-		progress("Failed to fetch metadata")
+		progress(i18n.T("Failed to fetch metadata"))
 		return err
 	}
 
@@ -280,29 +367,18 @@ func (m *Manga) PopulateMetadata(progress func(string)) error {
 	m.Metadata.EndDate = date(manga.EndDate)
 
 	m.Metadata.Status = strings.ReplaceAll(manga.Status, "_", " ")
-	m.Metadata.Synonyms = manga.Synonyms
+	m.Metadata.Title = manga.Name()
+	m.Metadata.NativeTitle = manga.Title.Native
+	m.Metadata.Synonyms = m.otherTitles(m.Metadata.Synonyms, []string{manga.Title.Romaji, manga.Title.English}, manga.Synonyms)
 
-	m.Metadata.Staff.Story = make([]string, 0)
-	m.Metadata.Staff.Art = make([]string, 0)
-	m.Metadata.Staff.Translation = make([]string, 0)
-	m.Metadata.Staff.Lettering = make([]string, 0)
+	// the authors and the artists given by the source are kept
+	m.Metadata.Staff.Story = m.Authors()
+	m.Metadata.Staff.Art = m.Artists()
+	m.Metadata.Staff.Translation = anilistStaff(manga, "translator")
+	m.Metadata.Staff.Lettering = anilistStaff(manga, "lettering")
 
 	m.Metadata.Chapters = manga.Chapters
 
-	for _, staff := range manga.Staff.Edges {
-		role := strings.ToLower(staff.Role)
-		switch {
-		case strings.Contains(role, "story"):
-			m.Metadata.Staff.Story = append(m.Metadata.Staff.Story, staff.Node.Name.Full)
-		case strings.Contains(role, "art"):
-			m.Metadata.Staff.Art = append(m.Metadata.Staff.Art, staff.Node.Name.Full)
-		case strings.Contains(role, "translator"):
-			m.Metadata.Staff.Translation = append(m.Metadata.Staff.Translation, staff.Node.Name.Full)
-		case strings.Contains(role, "lettering"):
-			m.Metadata.Staff.Lettering = append(m.Metadata.Staff.Lettering, staff.Node.Name.Full)
-		}
-	}
-
 	// Anilist & Myanimelist + external
 	urls := make([]string, 2+len(manga.External))
 	urls[0] = manga.SiteURL
@@ -323,9 +399,9 @@ func (m *Manga) PopulateMetadata(progress func(string)) error {
 func (m *Manga) SeriesJSON() *SeriesJSON {
 	var status string
 	switch m.Metadata.Status {
-	case "FINISHED":
+	case StatusFinished:
 		status = "Ended"
-	case "RELEASING":
+	case StatusReleasing:
 		status = "Continuing"
 	default:
 		status = "Unknown"
@@ -338,7 +414,7 @@ func (m *Manga) SeriesJSON() *SeriesJSON {
 
 	seriesJSON := &SeriesJSON{}
 	seriesJSON.Metadata.Type = "comicSeries"
-	seriesJSON.Metadata.Name = m.Name
+	seriesJSON.Metadata.Name = m.Title()
 	seriesJSON.Metadata.DescriptionFormatted = m.Metadata.Summary
 	seriesJSON.Metadata.DescriptionText = m.Metadata.Summary
 	seriesJSON.Metadata.Status = status
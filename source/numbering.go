@@ -0,0 +1,238 @@
+package source
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/spf13/viper"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// NumberingFilename is the name of the file inside the manga directory with the library numbering of its chapters.
+// It keeps the indexes continuous when the manga is continued from another source
+const NumberingFilename = "numbering.json"
+
+// numbering of the chapters of the manga in the library
+type numbering struct {
+	// Offsets of the chapter indexes of each source by its ID
+	Offsets map[string]int `json:"offsets"`
+	// Numbers are the library indexes of the downloaded chapters by their numbers, see chapterNumber
+	Numbers map[string]int `json:"numbers"`
+	// guesses are the offsets of the sources without the recorded ones, by their IDs.
+	// Guessing looks through all the chapters, so it is done once until another chapter is recorded
+	guesses map[string]int
+}
+
+// numberings are the loaded numberings by the paths of their mangas
+var numberings = struct {
+	mutex  sync.Mutex
+	loaded map[string]*numbering
+}{
+	loaded: make(map[string]*numbering),
+}
+
+var (
+	chapterNumberRegex = regexp.MustCompile(`(?i)(?:chapter|chap|ch|episode|ep|#)\.?\s*(\d+(?:\.\d+)?)`)
+	onlyNumberRegex    = regexp.MustCompile(`^\D*(\d+(?:\.\d+)?)\D*$`)
+)
+
+// chapterNumber returns the number of the chapter parsed from its name, e.g. "105.5" for "Ch. 105.5 - The End".
+// Sources may index chapters differently, but their numbers stay the same
+func chapterNumber(name string) (string, bool) {
+	groups := chapterNumberRegex.FindStringSubmatch(name)
+	if groups == nil {
+		if groups = onlyNumberRegex.FindStringSubmatch(name); groups == nil {
+			return "", false
+		}
+	}
+
+	number, err := strconv.ParseFloat(groups[1], 64)
+	if err != nil {
+		return "", false
+	}
+
+	return strconv.FormatFloat(number, 'f', -1, 64), true
+}
+
+// Number returns the number of the chapter parsed from its name, see chapterNumber.
+// It matches the chapters of the different sources, whose indexes may differ
+func (c *Chapter) Number() (string, bool) {
+	return chapterNumber(c.Name)
+}
+
+// loadNumbering returns the numbering of the manga at the path, numberings.mutex must be held
+func loadNumbering(mangaPath string) (*numbering, error) {
+	if n, ok := numberings.loaded[mangaPath]; ok {
+		return n, nil
+	}
+
+	n := &numbering{
+		Offsets: make(map[string]int),
+		Numbers: make(map[string]int),
+		guesses: make(map[string]int),
+	}
+
+	contents, err := filesystem.Api().ReadFile(filepath.Join(mangaPath, NumberingFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		if err = json.Unmarshal(contents, n); err != nil {
+			return nil, err
+		}
+	}
+
+	numberings.loaded[mangaPath] = n
+	return n, nil
+}
+
+func (n *numbering) save(mangaPath string) error {
+	contents, err := json.MarshalIndent(n, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(filepath.Join(mangaPath, NumberingFilename), contents, os.ModePerm)
+}
+
+// offsetOf returns the offset of the source indexes for the chapter.
+// The first source is not offset, the next ones are aligned with the downloaded chapters by the chapter numbers,
+// or continue after the last downloaded chapter if there are no common ones
+func (n *numbering) offsetOf(chapter *Chapter) int {
+	id := chapter.Source().ID()
+	if offset, ok := n.Offsets[id]; ok {
+		return offset
+	}
+
+	if len(n.Offsets) == 0 || len(n.Numbers) == 0 {
+		return 0
+	}
+
+	// the chapter alone is not enough to guess the offset of the whole source, so it is not saved
+	if len(chapter.Manga.Chapters) == 0 {
+		return n.guess([]*Chapter{chapter})
+	}
+
+	if offset, ok := n.guesses[id]; ok {
+		return offset
+	}
+
+	offset := n.guess(chapter.Manga.Chapters)
+	n.guesses[id] = offset
+	return offset
+}
+
+// guess returns the offset of the source with the given chapters, see offsetOf
+func (n *numbering) guess(chapters []*Chapter) int {
+	for _, c := range chapters {
+		if number, ok := chapterNumber(c.Name); ok {
+			if index, ok := n.Numbers[number]; ok {
+				return index - c.Index
+			}
+		}
+	}
+
+	var (
+		lastIndex  int
+		lastNumber = math.Inf(-1)
+	)
+
+	for number, index := range n.Numbers {
+		if index > lastIndex {
+			lastIndex = index
+		}
+
+		if parsed, err := strconv.ParseFloat(number, 64); err == nil && parsed > lastNumber {
+			lastNumber = parsed
+		}
+	}
+
+	sorted := make([]*Chapter, len(chapters))
+	copy(sorted, chapters)
+	sort.Slice(sorted, func(i, j int) bool {
+		return ChapterLess(sorted[i], sorted[j])
+	})
+
+	for _, c := range sorted {
+		number, ok := chapterNumber(c.Name)
+		if !ok {
+			continue
+		}
+
+		if parsed, _ := strconv.ParseFloat(number, 64); parsed > lastNumber {
+			return lastIndex + 1 - c.Index
+		}
+	}
+
+	return 0
+}
+
+// numbered reports whether the library numbering applies to the chapter
+func (c *Chapter) numbered() bool {
+	return viper.GetBool(key.DownloaderContinuousNumbering) && c.Manga != nil && c.Source() != nil
+}
+
+// LibraryIndex returns the index of the chapter in the library, used in the filenames and the metadata.
+// It is the Index, unless the continuous numbering is enabled and the manga was continued from another source
+func (c *Chapter) LibraryIndex() int {
+	if !c.numbered() {
+		return c.Index
+	}
+
+	numberings.mutex.Lock()
+	defer numberings.mutex.Unlock()
+
+	n, err := loadNumbering(c.Manga.PeekPath())
+	if err != nil {
+		log.Warn(err)
+		return c.Index
+	}
+
+	index := c.Index + n.offsetOf(c)
+	if index < 1 {
+		return c.Index
+	}
+
+	return index
+}
+
+// RecordNumbering saves the library index of the downloaded chapter,
+// so that the chapters of the other sources are numbered after it
+func (c *Chapter) RecordNumbering() error {
+	if !c.numbered() {
+		return nil
+	}
+
+	index := c.LibraryIndex()
+
+	numberings.mutex.Lock()
+	defer numberings.mutex.Unlock()
+
+	mangaPath, err := c.Manga.Path(false)
+	if err != nil {
+		return err
+	}
+
+	n, err := loadNumbering(mangaPath)
+	if err != nil {
+		return err
+	}
+
+	n.Offsets[c.Source().ID()] = index - c.Index
+	if number, ok := chapterNumber(c.Name); ok {
+		n.Numbers[number] = index
+	}
+
+	// the recorded chapter may change the offsets of the other sources
+	n.guesses = make(map[string]int)
+
+	return n.save(mangaPath)
+}
@@ -0,0 +1,133 @@
+package source
+
+import (
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"math"
+	"strconv"
+	"testing"
+)
+
+type otherSource struct {
+	testSource
+}
+
+func (o otherSource) ID() string {
+	return "other"
+}
+
+func TestChapterNumber(t *testing.T) {
+	Convey("Given chapter names", t, func() {
+		Convey("When chapterNumber is called", func() {
+			Convey("It should parse the number of the chapter", func() {
+				for name, expected := range map[string]string{
+					"Ch. 105.5 - The End":    "105.5",
+					"Chapter 012":            "12",
+					"Vol. 2 Episode 7: Home": "7",
+					"42":                     "42",
+				} {
+					number, ok := chapterNumber(name)
+					So(ok, ShouldBeTrue)
+					So(number, ShouldEqual, expected)
+				}
+			})
+
+			Convey("It should not guess with several numbers", func() {
+				_, ok := chapterNumber("Vol. 2 - 7")
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("It should be the number of the chapter with the name", func() {
+				number, ok := (&Chapter{Name: "Ch. 105.5 - The End"}).Number()
+				So(ok, ShouldBeTrue)
+				So(number, ShouldEqual, "105.5")
+			})
+		})
+	})
+}
+
+func FuzzChapterNumber(f *testing.F) {
+	for _, seed := range []string{"Ch. 105.5 - The End", "Chapter 012", "42", "Vol. 2 - 7", "#", "Chapter 99999999999999999999999999999", "ep.1.2.3"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, name string) {
+		number, ok := chapterNumber(name)
+		if !ok {
+			return
+		}
+
+		parsed, err := strconv.ParseFloat(number, 64)
+		if err != nil || math.IsInf(parsed, 0) || math.IsNaN(parsed) {
+			t.Fatalf("%q is parsed to the invalid number %q", name, number)
+		}
+
+		// numbers are the keys of the numbering, so they must stay the same when parsed again
+		if again, ok := chapterNumber(number); !ok || again != number {
+			t.Fatalf("%q is parsed to %q, but %q is parsed to %q", name, number, number, again)
+		}
+	})
+}
+
+func TestChapter_LibraryIndex(t *testing.T) {
+	Convey("Given a manga downloaded from a source", t, func() {
+		viper.Set(key.DownloaderContinuousNumbering, true)
+		defer viper.Set(key.DownloaderContinuousNumbering, false)
+
+		newManga := func(src Source, names ...string) *Manga {
+			manga := &Manga{Name: "Continued", Source: src}
+			for i, name := range names {
+				manga.Chapters = append(manga.Chapters, &Chapter{Name: name, Index: i + 1, Manga: manga})
+			}
+
+			return manga
+		}
+
+		first := newManga(&testSource{}, "Chapter 1", "Chapter 2", "Chapter 3")
+		for _, chapter := range first.Chapters {
+			So(chapter.RecordNumbering(), ShouldBeNil)
+		}
+
+		Convey("When the chapters of another source share the numbers", func() {
+			other := newManga(&otherSource{}, "Chapter 3", "Chapter 4")
+
+			Convey("It should align them by the numbers", func() {
+				So(other.Chapters[0].LibraryIndex(), ShouldEqual, 3)
+				So(other.Chapters[1].LibraryIndex(), ShouldEqual, 4)
+			})
+		})
+
+		Convey("When the chapters of another source start after the last one", func() {
+			other := newManga(&otherSource{}, "Chapter 5", "Chapter 6")
+
+			Convey("It should continue after the last downloaded chapter", func() {
+				So(other.Chapters[0].LibraryIndex(), ShouldEqual, 4)
+				So(other.Chapters[1].LibraryIndex(), ShouldEqual, 5)
+			})
+		})
+
+		Convey("When the offset of another source is guessed", func() {
+			other := newManga(&otherSource{}, "Chapter 3", "Chapter 4")
+			So(other.Chapters[0].LibraryIndex(), ShouldEqual, 3)
+
+			other.Chapters[0].Name, other.Chapters[1].Name = "Chapter 9", "Chapter 10"
+
+			Convey("It should be reused until another chapter is recorded", func() {
+				So(other.Chapters[1].LibraryIndex(), ShouldEqual, 4)
+
+				So(first.Chapters[0].RecordNumbering(), ShouldBeNil)
+				So(other.Chapters[1].LibraryIndex(), ShouldEqual, 5)
+			})
+		})
+
+		Convey("When the numbering is disabled", func() {
+			viper.Set(key.DownloaderContinuousNumbering, false)
+			other := newManga(&otherSource{}, "Chapter 5")
+
+			Convey("It should be the index of the source", func() {
+				So(other.Chapters[0].LibraryIndex(), ShouldEqual, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/util"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// OwnerFilename is the name of the file inside the manga directory with the manga it belongs to.
+// Different mangas whose names are sanitized to the same directory are told apart by it, see ResolveDirname
+const OwnerFilename = "owner.json"
+
+// Owner of the manga directory
+type Owner struct {
+	Name     string `json:"name"`
+	SourceID string `json:"source_id,omitempty"`
+	ID       string `json:"id,omitempty"`
+}
+
+// owns reports whether the other manga belongs to the directory of the owner.
+// The same manga continued from another source shares the directory (see numbering), so only the names are compared then
+func (o *Owner) owns(other *Owner) bool {
+	if o.SourceID == other.SourceID && o.ID != "" && other.ID != "" {
+		return o.ID == other.ID
+	}
+
+	normalize := func(name string) string {
+		return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	}
+
+	return normalize(o.Name) == normalize(other.Name)
+}
+
+// loadOwner returns the owner of the directory, nil if it has none
+func loadOwner(dir string) (*Owner, error) {
+	contents, err := filesystem.Api().ReadFile(filepath.Join(dir, OwnerFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var owner Owner
+	if err = json.Unmarshal(contents, &owner); err != nil {
+		return nil, err
+	}
+
+	return &owner, nil
+}
+
+// ResolveDirname returns the name of the directory of the manga inside the root.
+// If the directory of its sanitized name belongs to another manga, the name is suffixed with the source,
+// and then with the ID too. Directories without an owner are of the older versions and are used as they are
+func ResolveDirname(root string, owner *Owner) string {
+	base := util.SanitizeFilename(owner.Name)
+	candidates := []string{base}
+
+	if owner.SourceID != "" {
+		source := util.SanitizeFilename(owner.SourceID)
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", base, source))
+
+		if owner.ID != "" {
+			candidates = append(candidates, fmt.Sprintf("%s (%s %s)", base, source, util.SanitizeFilename(owner.ID)))
+		}
+	}
+
+	for _, name := range candidates {
+		other, err := loadOwner(filepath.Join(root, name))
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		if other == nil || other.owns(owner) {
+			return name
+		}
+	}
+
+	// every directory is taken, merging is better than not saving at all
+	return candidates[len(candidates)-1]
+}
+
+// Claim creates the directory and writes the owner to it, unless it has one already
+func Claim(dir string, owner *Owner) error {
+	if err := filesystem.Api().MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if other, err := loadOwner(dir); err != nil || other != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(owner, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return filesystem.Api().WriteFile(filepath.Join(dir, OwnerFilename), contents, os.ModePerm)
+}
+
+// dirnames are the resolved directories of the mangas by their roots and owners
+var dirnames = struct {
+	mutex    sync.Mutex
+	resolved map[string]string
+}{
+	resolved: make(map[string]string),
+}
+
+func (m *Manga) owner() *Owner {
+	owner := &Owner{Name: m.Name, ID: m.ID}
+	if m.Source != nil {
+		owner.SourceID = m.Source.ID()
+	}
+
+	return owner
+}
+
+// resolveDirnameIn resolves the directory of the manga and remembers it, dirnames.mutex must be held.
+// Directory is claimed if asked, so that the other mangas are not merged into it
+func (m *Manga) resolveDirnameIn(root string, claim bool) string {
+	owner := m.owner()
+	cacheKey := strings.Join([]string{root, owner.SourceID, owner.ID, owner.Name}, "\x00")
+
+	name, ok := dirnames.resolved[cacheKey]
+	if ok && !claim {
+		return name
+	}
+
+	resolved := ResolveDirname(root, owner)
+	if claim {
+		if err := Claim(filepath.Join(root, resolved), owner); err != nil {
+			log.Warn(err)
+		}
+	}
+
+	if base := util.SanitizeFilename(owner.Name); resolved != base && resolved != name {
+		logger := log.Source("")
+		if m.Source != nil {
+			logger = log.Source(m.Source.Name())
+		}
+
+		logger.Warnf("%s belongs to another manga with a similar name, %q is saved to %s instead", filepath.Join(root, base), m.Name, resolved)
+	}
+
+	dirnames.resolved[cacheKey] = resolved
+	return resolved
+}
+
+// DirnameIn returns the name of the directory of the manga inside the root, see ResolveDirname
+func (m *Manga) DirnameIn(root string) string {
+	dirnames.mutex.Lock()
+	defer dirnames.mutex.Unlock()
+
+	return m.resolveDirnameIn(root, false)
+}
+
+// claimDirIn creates the directory of the manga inside the root and returns its path
+func (m *Manga) claimDirIn(root string) string {
+	dirnames.mutex.Lock()
+	defer dirnames.mutex.Unlock()
+
+	return filepath.Join(root, m.resolveDirnameIn(root, true))
+}
@@ -0,0 +1,55 @@
+package source
+
+import (
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveDirname(t *testing.T) {
+	Convey("Given a directory of a manga", t, func() {
+		root := filepath.Join("owners", t.Name())
+		owner := &Owner{Name: "Fate/Zero", SourceID: "mangadex", ID: "1"}
+		lo.Must0(Claim(filepath.Join(root, ResolveDirname(root, owner)), owner))
+
+		Convey("When the same manga is resolved", func() {
+			name := ResolveDirname(root, &Owner{Name: "Fate/Zero", SourceID: "mangadex", ID: "1"})
+
+			Convey("Then its directory should be used", func() {
+				So(name, ShouldEqual, "Fate_Zero")
+			})
+		})
+
+		Convey("When the same manga from another source is resolved", func() {
+			name := ResolveDirname(root, &Owner{Name: "Fate/Zero", SourceID: "mangapill", ID: "fate-zero"})
+
+			Convey("Then the directory should be shared", func() {
+				So(name, ShouldEqual, "Fate_Zero")
+			})
+		})
+
+		Convey("When another manga with the same sanitized name is resolved", func() {
+			other := &Owner{Name: "Fate: Zero", SourceID: "mangapill", ID: "fate-zero"}
+			name := ResolveDirname(root, other)
+
+			Convey("Then the source should be added to the name", func() {
+				So(name, ShouldEqual, "Fate_Zero (mangapill)")
+
+				Convey("And another one from the same source should have the ID too", func() {
+					lo.Must0(Claim(filepath.Join(root, name), other))
+					name = ResolveDirname(root, &Owner{Name: "Fate Zero", SourceID: "mangapill", ID: "42"})
+					So(name, ShouldEqual, "Fate_Zero (mangapill 42)")
+				})
+			})
+		})
+
+		Convey("When the manga of the same source with another ID is resolved", func() {
+			name := ResolveDirname(root, &Owner{Name: "Fate/Zero", SourceID: "mangadex", ID: "2"})
+
+			Convey("Then the source should be added to the name", func() {
+				So(name, ShouldEqual, "Fate_Zero (mangadex)")
+			})
+		})
+	})
+}
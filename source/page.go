@@ -2,15 +2,19 @@ package source
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/network"
 	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
 	_ "image/gif"
 	"io"
 	"net/http"
+	"strings"
 )
 
 // Page represents a page in a chapter
@@ -18,7 +22,7 @@ type Page struct {
 	// URL of the page. Used to download the page.
 	URL string `json:"url" jsonschema:"description=URL of the page. Used to download the image."`
 	// Index of the page in the chapter.
-	Index uint16 `json:"index" jsonschema:"description=Index of the page in the chapter."`
+	Index int `json:"index" jsonschema:"description=Index of the page in the chapter."`
 	// Extension of the page image.
 	Extension string `json:"extension" jsonschema:"description=Extension of the page image."`
 	// Size of the page in bytes
@@ -27,37 +31,55 @@ type Page struct {
 	Contents *bytes.Buffer `json:"-"`
 	// Chapter that the page belongs to.
 	Chapter *Chapter `json:"-"`
+	// Headers to send with the requests of the page, e.g. the authorization that must not be in the URL
+	Headers http.Header `json:"-"`
 }
 
-func (p *Page) request() (*http.Request, error) {
-	req, err := http.NewRequest(http.MethodGet, p.URL, nil)
+func (p *Page) request(ctx context.Context) (*http.Request, error) {
+	return p.requestWith(ctx, http.MethodGet)
+}
+
+func (p *Page) requestWith(ctx context.Context, method string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.URL, nil)
 	if err != nil {
-		log.Error(err)
+		p.logger().Error(err)
 		return nil, err
 	}
 
 	req.Header.Set("Referer", p.Chapter.URL)
 	req.Header.Set("User-Agent", constant.UserAgent)
+	for name, values := range p.Headers {
+		req.Header[name] = values
+	}
+
 	return req, nil
 }
 
 // Download Page contents.
 func (p *Page) Download() error {
+	return p.DownloadContext(context.Background())
+}
+
+// DownloadContext downloads Page contents until the context is canceled.
+func (p *Page) DownloadContext(ctx context.Context) error {
 	if p.URL == "" {
-		log.Warnf("Page #%d has no URL", p.Index)
+		p.logger().Warnf("Page #%d has no URL", p.Index)
 		return nil
 	}
 
-	log.Tracef("Downloading page #%d (%s)", p.Index, p.URL)
+	p.logger().Tracef("Downloading page #%d (%s)", p.Index, p.URL)
+
+	ctx, cancel := network.StageImages.Context(ctx)
+	defer cancel()
 
-	req, err := p.request()
+	req, err := p.request(ctx)
 	if err != nil {
 		return err
 	}
 
-	resp, err := network.Client.Do(req)
+	resp, err := p.client().Do(req)
 	if err != nil {
-		log.Error(err)
+		p.logger().Error(err)
 		return err
 	}
 
@@ -65,13 +87,13 @@ func (p *Page) Download() error {
 
 	if resp.StatusCode != http.StatusOK {
 		err = errors.New("http error: " + resp.Status)
-		log.Error(err)
+		p.logger().Error(err)
 		return err
 	}
 
 	if resp.ContentLength == 0 {
 		err = errors.New("http error: nothing was returned")
-		log.Error(err)
+		p.logger().Error(err)
 		return err
 	}
 
@@ -97,10 +119,82 @@ func (p *Page) Download() error {
 	p.Contents = bytes.NewBuffer(buf)
 	p.Size = uint64(util.Max(contentLength, 0))
 
-	log.Tracef("Page #%d downloaded", p.Index)
+	p.logger().Tracef("Page #%d downloaded", p.Index)
 	return nil
 }
 
+// client returns the client of the images for the source of the page
+func (p *Page) client() *http.Client {
+	var sourceName string
+	if p.Chapter != nil && p.Chapter.Manga != nil && p.Source() != nil {
+		sourceName = p.Source().Name()
+	}
+
+	return network.StageClientFor(sourceName)
+}
+
+// loadedSize returns the size of the downloaded page, known is false if the page is not downloaded
+func (p *Page) loadedSize() (size uint64, known bool) {
+	if p.Size > 0 {
+		return p.Size, true
+	}
+
+	if p.Contents != nil && p.Contents.Len() > 0 {
+		return uint64(p.Contents.Len()), true
+	}
+
+	return 0, false
+}
+
+// SizeContext returns the size of the page without downloading it, with a HEAD request.
+// Servers that don't answer HEAD requests are asked with GET, whose body is closed once the headers are read.
+// Known is false if the servers don't report the size
+func (p *Page) SizeContext(ctx context.Context) (size uint64, known bool, err error) {
+	if p.URL == "" {
+		return 0, false, nil
+	}
+
+	ctx, cancel := network.StageImages.Context(ctx)
+	defer cancel()
+
+	length := func(method string) (int64, int, error) {
+		req, err := p.requestWith(ctx, method)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		resp, err := p.client().Do(req)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// the body of GET is not read, closing it drops the connection
+		util.Ignore(resp.Body.Close)
+		return resp.ContentLength, resp.StatusCode, nil
+	}
+
+	contentLength, status, err := length(http.MethodHead)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if status != http.StatusOK || contentLength <= 0 {
+		if contentLength, status, err = length(http.MethodGet); err != nil {
+			return 0, false, err
+		}
+
+		if status != http.StatusOK {
+			return 0, false, fmt.Errorf("http error: %d %s", status, http.StatusText(status))
+		}
+	}
+
+	if contentLength <= 0 {
+		return 0, false, nil
+	}
+
+	return uint64(contentLength), true, nil
+}
+
 // Close closes the page contents.
 func (p *Page) Close() error {
 	return nil
@@ -108,24 +202,71 @@ func (p *Page) Close() error {
 
 // Read reads from the page contents.
 func (p *Page) Read(b []byte) (int, error) {
-	log.Tracef("Reading page contents #%d", p.Index)
+	p.logger().Tracef("Reading page contents #%d", p.Index)
 	if p.Contents == nil {
 		err := errors.New("page not downloaded")
-		log.Error(err)
+		p.logger().Error(err)
 		return 0, err
 	}
 
 	return p.Contents.Read(b)
 }
 
-// Filename generates a filename for the page.
+// Filename generates a filename for the page according to the template in the config.
 func (p *Page) Filename() (filename string) {
-	filename = fmt.Sprintf("%d%s", p.Index, p.Extension)
-	filename = util.PadZero(filename, 10)
+	index := fmt.Sprintf("%d", p.Index)
+	paddedIndex := util.PadZero(index, p.Chapter.settings().pagePadding())
+
+	variables := map[string]string{
+		"index":        index,
+		"padded-index": paddedIndex,
+	}
+
+	if p.Chapter != nil {
+		variables["chapter"] = p.Chapter.title()
+		index := p.Chapter.LibraryIndex()
+		variables["chapter-index"] = fmt.Sprintf("%d", index)
+		variables["padded-chapter-index"] = fmt.Sprintf("%04d", index)
 
-	return
+		if p.Chapter.Manga != nil {
+			variables["manga"] = p.Chapter.Manga.Name
+			variables["title"] = p.Chapter.Manga.Title()
+			variables["author"] = strings.Join(p.Chapter.Manga.Authors(), ", ")
+			variables["artist"] = strings.Join(p.Chapter.Manga.Artists(), ", ")
+		}
+	}
+
+	// pages must have distinct names, so the index is kept if the template gives nothing
+	if filename = util.SanitizeFilename(util.Render(viper.GetString(key.FormatsPageNameTemplate), variables)); filename == "" {
+		filename = paddedIndex
+	}
+
+	return filename + p.extension()
+}
+
+// extension of the page image, normalized if it is set in the config.
+func (p *Page) extension() string {
+	if !p.Chapter.settings().normalizeExtensions() {
+		return p.Extension
+	}
+
+	switch extension := strings.ToLower(p.Extension); extension {
+	case ".jpeg", ".jpe", ".jfif":
+		return ".jpg"
+	default:
+		return extension
+	}
 }
 
 func (p *Page) Source() Source {
 	return p.Chapter.Source()
 }
+
+// logger of the messages about the page, marked with the name of its source
+func (p *Page) logger() log.SourceLogger {
+	if p.Chapter == nil || p.Chapter.Manga == nil || p.Chapter.Manga.Source == nil {
+		return log.Source("")
+	}
+
+	return log.Source(p.Source().Name())
+}
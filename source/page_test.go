@@ -1 +1,71 @@
 package source
+
+import (
+	"github.com/metafates/mangal/key"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPage_Filename(t *testing.T) {
+	Convey("Given a page", t, func() {
+		page := &Page{Index: 7, Extension: ".JPEG", Chapter: &testChapter}
+		viper.Set(key.FormatsPagePadding, 4)
+		defer viper.Set(key.FormatsPageNameTemplate, "")
+		defer viper.Set(key.FormatsNormalizeExtensions, false)
+
+		Convey("When Filename is called with the chapter prefix", func() {
+			viper.Set(key.FormatsPageNameTemplate, "{padded-chapter-index}-{padded-index}")
+			filename := page.Filename()
+
+			Convey("It should match the given template", func() {
+				So(filename, ShouldEqual, "0001-0007.JPEG")
+			})
+		})
+
+		Convey("When Filename is called with normalized extensions", func() {
+			viper.Set(key.FormatsPageNameTemplate, "{chapter} {index}")
+			viper.Set(key.FormatsNormalizeExtensions, true)
+			filename := page.Filename()
+
+			Convey("It should be sanitized and end with .jpg", func() {
+				So(filename, ShouldEqual, "test_chapter_7.jpg")
+			})
+		})
+
+		Convey("When the template gives an empty name", func() {
+			viper.Set(key.FormatsPageNameTemplate, "//")
+			filename := page.Filename()
+
+			Convey("It should fall back to the padded index", func() {
+				So(filename, ShouldEqual, "0007.JPEG")
+			})
+		})
+	})
+}
+
+func TestPage_Headers(t *testing.T) {
+	Convey("Given a page with headers", t, func() {
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte("image"))
+		}))
+		defer server.Close()
+
+		page := &Page{URL: server.URL + "/1.jpg", Index: 1, Chapter: &testChapter, Headers: http.Header{}}
+		page.Headers.Set("Authorization", "Bearer token")
+
+		Convey("When it is downloaded", func() {
+			err := page.Download()
+
+			Convey("Then the headers should be sent with the request", func() {
+				So(err, ShouldBeNil)
+				So(authorization, ShouldEqual, "Bearer token")
+				So(page.Contents.String(), ShouldEqual, "image")
+			})
+		})
+	})
+}
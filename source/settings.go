@@ -0,0 +1,100 @@
+package source
+
+import (
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+)
+
+// Settings of the download of the manga that take precedence over the config,
+// e.g. the personal library of a user of the server or the overrides of the tracked manga.
+// They are passed with the manga instead of changing the config, which is shared by the other downloads.
+// Empty fields keep the config
+type Settings struct {
+	// Root is the library to download the manga to instead of the downloads path
+	Root string
+	// Formats to save the chapters in, the same as formats.use without the format rules
+	Formats []string
+	// RawPassthrough is the same as formats.raw_passthrough
+	RawPassthrough *bool
+	// SkipUnsupportedImages is the same as formats.skip_unsupported_images
+	SkipUnsupportedImages *bool
+	// NormalizeExtensions is the same as formats.normalize_extensions
+	NormalizeExtensions *bool
+	// PagePadding is the same as formats.page_padding
+	PagePadding *int
+}
+
+// root returns the library to download to
+func (s *Settings) root() string {
+	if s == nil || s.Root == "" {
+		return where.Downloads()
+	}
+
+	return s.Root
+}
+
+// formats returns the formats to save the chapters in, see Formats
+func (s *Settings) formats() []string {
+	if s != nil && len(s.Formats) > 0 {
+		return s.Formats
+	}
+
+	if s != nil && s.Root != "" {
+		return FormatsIn(s.Root)
+	}
+
+	return Formats()
+}
+
+func (s *Settings) rawPassthrough() bool {
+	if s == nil || s.RawPassthrough == nil {
+		return viper.GetBool(key.FormatsRawPassthrough)
+	}
+
+	return *s.RawPassthrough
+}
+
+func (s *Settings) skipUnsupportedImages() bool {
+	if s == nil || s.SkipUnsupportedImages == nil {
+		return viper.GetBool(key.FormatsSkipUnsupportedImages)
+	}
+
+	return *s.SkipUnsupportedImages
+}
+
+func (s *Settings) normalizeExtensions() bool {
+	if s == nil || s.NormalizeExtensions == nil {
+		return viper.GetBool(key.FormatsNormalizeExtensions)
+	}
+
+	return *s.NormalizeExtensions
+}
+
+func (s *Settings) pagePadding() int {
+	if s == nil || s.PagePadding == nil {
+		return viper.GetInt(key.FormatsPagePadding)
+	}
+
+	return *s.PagePadding
+}
+
+// settings of the manga of the chapter, nil if there are none
+func (c *Chapter) settings() *Settings {
+	if c == nil || c.Manga == nil {
+		return nil
+	}
+
+	return c.Manga.Settings
+}
+
+// Formats returns the formats to save the chapter in, see Formats.
+// The first one is the primary format of the chapter
+func (c *Chapter) Formats() []string {
+	return c.settings().formats()
+}
+
+// SkipUnsupportedImages reports whether the pages that can't be converted are left out instead of failing the chapter
+func (c *Chapter) SkipUnsupportedImages() bool {
+	return c.settings().skipUnsupportedImages()
+}
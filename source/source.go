@@ -1,5 +1,7 @@
 package source
 
+import "context"
+
 // Source is the interface that all sources must implement.
 type Source interface {
 	Name() string
@@ -8,3 +10,37 @@ type Source interface {
 	PagesOf(chapter *Chapter) ([]*Page, error)
 	ID() string
 }
+
+// ContextSearcher is implemented by the sources that can stop searching
+// when the context is canceled, so that abandoned searches don't keep issuing requests
+type ContextSearcher interface {
+	SearchContext(ctx context.Context, query string) ([]*Manga, error)
+}
+
+// SearchContext searches the source until the context is canceled.
+// Sources that don't implement ContextSearcher finish the search in the background,
+// but their results are discarded
+func SearchContext(ctx context.Context, src Source, query string) ([]*Manga, error) {
+	if searcher, ok := src.(ContextSearcher); ok {
+		return searcher.SearchContext(ctx, query)
+	}
+
+	type result struct {
+		mangas []*Manga
+		err    error
+	}
+
+	// buffered, so that the search doesn't block forever if nobody waits for it
+	done := make(chan result, 1)
+	go func() {
+		mangas, err := src.Search(query)
+		done <- result{mangas, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.mangas, r.err
+	}
+}
@@ -0,0 +1,37 @@
+package source
+
+import (
+	"github.com/metafates/mangal/anilist"
+	"strings"
+)
+
+// Authors returns the story authors of the manga given by the source and by Anilist, if it is bound
+func (m *Manga) Authors() []string {
+	return m.staff(m.Metadata.Staff.Story, "story")
+}
+
+// Artists returns the art authors of the manga given by the source and by Anilist, if it is bound
+func (m *Manga) Artists() []string {
+	return m.staff(m.Metadata.Staff.Art, "art")
+}
+
+func (m *Manga) staff(known []string, role string) []string {
+	if manga, ok := m.Anilist.Get(); ok && manga != nil {
+		return mergeNames(known, anilistStaff(manga, role))
+	}
+
+	return mergeNames(known)
+}
+
+// anilistStaff returns the staff of the anilist manga with the given role.
+// Roles are like "Story & Art", so one member may have several
+func anilistStaff(manga *anilist.Manga, role string) []string {
+	names := make([]string, 0)
+	for _, staff := range manga.Staff.Edges {
+		if strings.Contains(strings.ToLower(staff.Role), role) {
+			names = append(names, staff.Node.Name.Full)
+		}
+	}
+
+	return names
+}
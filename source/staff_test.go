@@ -0,0 +1,35 @@
+package source
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/anilist"
+	"github.com/samber/mo"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestManga_Authors(t *testing.T) {
+	Convey("Given a manga with the authors from the source", t, func() {
+		manga := &Manga{Name: "Berserk"}
+		manga.Metadata.Staff.Story = []string{"Kentaro Miura"}
+
+		Convey("Then they should be the authors", func() {
+			So(manga.Authors(), ShouldResemble, []string{"Kentaro Miura"})
+			So(manga.Artists(), ShouldBeEmpty)
+		})
+
+		Convey("When it is bound with Anilist", func() {
+			var al *anilist.Manga
+			So(json.Unmarshal([]byte(`{"staff": {"edges": [
+				{"role": "Story & Art", "node": {"name": {"full": "Kentaro Miura"}}},
+				{"role": "Art", "node": {"name": {"full": "Kouji Mori"}}}
+			]}}`), &al), ShouldBeNil)
+			manga.Anilist = mo.Some(al)
+
+			Convey("Then the staff should be enriched without duplicates", func() {
+				So(manga.Authors(), ShouldResemble, []string{"Kentaro Miura"})
+				So(manga.Artists(), ShouldResemble, []string{"Kentaro Miura", "Kouji Mori"})
+			})
+		})
+	})
+}
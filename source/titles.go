@@ -0,0 +1,63 @@
+package source
+
+import (
+	"github.com/samber/lo"
+	"strings"
+)
+
+// Title returns the canonical title of the manga, or its name if it is unknown
+func (m *Manga) Title() string {
+	if m.Metadata.Title != "" {
+		return m.Metadata.Title
+	}
+
+	return m.Name
+}
+
+// Titles returns every known title of the manga without duplicates:
+// the name first, then the canonical, the native and the alternative ones
+func (m *Manga) Titles() []string {
+	return mergeNames([]string{m.Name, m.Metadata.Title, m.Metadata.NativeTitle}, m.Metadata.Synonyms)
+}
+
+// HasTitle reports whether any title of the manga is the given one, ignoring the case
+func (m *Manga) HasTitle(title string) bool {
+	title = strings.TrimSpace(title)
+	return lo.ContainsBy(m.Titles(), func(t string) bool {
+		return strings.EqualFold(t, title)
+	})
+}
+
+// mergeNames merges the lists of the titles or the names in the given order, skipping the empty ones and the duplicates
+func mergeNames(lists ...[]string) []string {
+	var (
+		merged = make([]string, 0)
+		seen   = make(map[string]struct{})
+	)
+
+	for _, list := range lists {
+		for _, title := range list {
+			title = strings.TrimSpace(title)
+			if title == "" {
+				continue
+			}
+
+			normalized := strings.ToLower(title)
+			if _, ok := seen[normalized]; ok {
+				continue
+			}
+
+			seen[normalized] = struct{}{}
+			merged = append(merged, title)
+		}
+	}
+
+	return merged
+}
+
+// otherTitles merges the titles that are not the name or the canonical title of the manga
+func (m *Manga) otherTitles(lists ...[]string) []string {
+	return lo.Filter(mergeNames(lists...), func(title string, _ int) bool {
+		return !strings.EqualFold(title, m.Name) && !strings.EqualFold(title, m.Metadata.Title)
+	})
+}
@@ -0,0 +1,41 @@
+package source
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestManga_Titles(t *testing.T) {
+	Convey("Given a manga with the alternative titles", t, func() {
+		manga := &Manga{Name: "Attack on Titan"}
+		manga.Metadata.Title = "Shingeki no Kyojin"
+		manga.Metadata.NativeTitle = "進撃の巨人"
+		manga.Metadata.Synonyms = []string{"attack on titan", "AoT", ""}
+
+		Convey("Then the canonical title should be used as the title", func() {
+			So(manga.Title(), ShouldEqual, "Shingeki no Kyojin")
+		})
+
+		Convey("Then every title should be listed once, the name first", func() {
+			So(manga.Titles(), ShouldResemble, []string{"Attack on Titan", "Shingeki no Kyojin", "進撃の巨人", "AoT"})
+		})
+
+		Convey("Then it should be matched by any of them", func() {
+			So(manga.HasTitle("aot"), ShouldBeTrue)
+			So(manga.HasTitle(" 進撃の巨人 "), ShouldBeTrue)
+			So(manga.HasTitle("Titan"), ShouldBeFalse)
+		})
+
+		Convey("Then the other titles should skip the name and the canonical title", func() {
+			So(manga.otherTitles([]string{"Shingeki no Kyojin", "AoT"}, []string{"ATTACK ON TITAN", "SnK"}), ShouldResemble, []string{"AoT", "SnK"})
+		})
+	})
+
+	Convey("Given a manga without the canonical title", t, func() {
+		manga := &Manga{Name: "Berserk"}
+
+		Convey("Then the name should be used as the title", func() {
+			So(manga.Title(), ShouldEqual, "Berserk")
+		})
+	})
+}
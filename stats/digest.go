@@ -0,0 +1,154 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// week is how often the digest is sent
+const week = 7 * 24 * time.Hour
+
+// Digest is the summary of the reading and the downloads of the days
+type Digest struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Chapters   int    `json:"chapters"`
+	Pages      int    `json:"pages"`
+	Downloaded int    `json:"downloaded"`
+	// Read are the mangas read, the most read first
+	Read []*MangaCount `json:"read"`
+	// Downloads are the mangas downloaded, the most downloaded first
+	Downloads []*MangaCount `json:"downloads"`
+	Days      []*Day        `json:"days"`
+}
+
+// MangaCount is the number of the chapters of the manga
+type MangaCount struct {
+	Manga    string `json:"manga"`
+	Chapters int    `json:"chapters"`
+}
+
+// Summarize returns the digest of the days
+func Summarize(days []*Day) *Digest {
+	digest := &Digest{Days: days}
+	if len(days) == 0 {
+		return digest
+	}
+
+	digest.From, digest.To = days[0].Date, days[len(days)-1].Date
+
+	var read, downloads = make(map[string]int), make(map[string]int)
+	for _, day := range days {
+		digest.Chapters += day.Chapters
+		digest.Pages += day.Pages
+		digest.Downloaded += day.Downloaded
+
+		for manga, chapters := range day.Read {
+			read[manga] += chapters
+		}
+
+		for manga, chapters := range day.Downloads {
+			downloads[manga] += chapters
+		}
+	}
+
+	digest.Read, digest.Downloads = ranked(read), ranked(downloads)
+	return digest
+}
+
+// String returns the digest in the plain text
+func (d *Digest) String() string {
+	return fmt.Sprintf(
+		"%s - %s: %s (%s) read, %s downloaded",
+		d.From,
+		d.To,
+		util.Quantify(d.Chapters, "chapter", "chapters"),
+		util.Quantify(d.Pages, "page", "pages"),
+		util.Quantify(d.Downloaded, "chapter", "chapters"),
+	)
+}
+
+// ranked returns the counts, the largest first
+func ranked(counts map[string]int) []*MangaCount {
+	list := make([]*MangaCount, 0, len(counts))
+	for manga, chapters := range counts {
+		list = append(list, &MangaCount{Manga: manga, Chapters: chapters})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Chapters != list[j].Chapters {
+			return list[i].Chapters > list[j].Chapters
+		}
+
+		return list[i].Manga < list[j].Manga
+	})
+
+	return list
+}
+
+// SendDigest posts the digest of the past week as JSON to the webhook in the config, see key.HistoryDigestWebhook.
+// Nothing is posted if the webhook is not set or a week hasn't passed since the previous digest
+func SendDigest() error {
+	return sendDigest(time.Now())
+}
+
+func sendDigest(now time.Time) error {
+	webhook := viper.GetString(key.HistoryDigestWebhook)
+	if webhook == "" {
+		return nil
+	}
+
+	mutex.Lock()
+	stats, err := get()
+	mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if now.Sub(stats.DigestSent) < week {
+		return nil
+	}
+
+	days, err := Days(now.AddDate(0, 0, -6), now)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(struct {
+		Digest *Digest `json:"digest"`
+	}{Summarize(days)})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(contents))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", constant.Mangal+"/"+constant.Version)
+
+	resp, err := network.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("digest was not accepted: %s", resp.Status)
+	}
+
+	return update(now, func(stats *saved) {
+		stats.DigestSent = now.UTC()
+	})
+}
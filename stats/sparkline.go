@@ -0,0 +1,29 @@
+package stats
+
+import "strings"
+
+// bars of the sparkline, from the lowest to the highest
+var bars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline returns the values as the line of the bars scaled to the largest one.
+// Zero values are shown as spaces, so that the days without the reading stand out
+func Sparkline(values []int) string {
+	var largest int
+	for _, value := range values {
+		if value > largest {
+			largest = value
+		}
+	}
+
+	var sb strings.Builder
+	for _, value := range values {
+		if value <= 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+
+		sb.WriteRune(bars[value*(len(bars)-1)/largest])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,175 @@
+// Package stats keeps the statistics of the reading and the downloads by day.
+// They are recorded as the chapters are saved to the history and downloaded, see Digest for the weekly summary.
+package stats
+
+import (
+	"github.com/metafates/gache"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"sync"
+	"time"
+)
+
+// retention is how long the statistics of the day are kept
+const retention = 366 * 24 * time.Hour
+
+// dayLayout is the format of Day.Date
+const dayLayout = "2006-01-02"
+
+var (
+	mutex  sync.Mutex
+	cacher = gache.New[*saved](
+		&gache.Options{
+			Path:       where.Stats(),
+			FileSystem: &filesystem.GacheFs{},
+		},
+	)
+)
+
+// Day statistics, days are in the local time
+type Day struct {
+	// Date of the day as YYYY-MM-DD
+	Date string `json:"date"`
+	// Chapters read
+	Chapters int `json:"chapters"`
+	// Pages of the chapters read
+	Pages int `json:"pages"`
+	// Downloaded chapters
+	Downloaded int `json:"downloaded"`
+	// Read is the number of the chapters read by manga
+	Read map[string]int `json:"read,omitempty"`
+	// Downloads is the number of the chapters downloaded by manga
+	Downloads map[string]int `json:"downloads,omitempty"`
+}
+
+type saved struct {
+	Days map[string]*Day `json:"days"`
+	// DigestSent is when the digest was sent the last time
+	DigestSent time.Time `json:"digest_sent"`
+}
+
+func get() (*saved, error) {
+	cached, expired, err := cacher.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if expired || cached == nil {
+		cached = &saved{}
+	}
+
+	if cached.Days == nil {
+		cached.Days = make(map[string]*Day)
+	}
+
+	return cached, nil
+}
+
+// update changes the statistics and drops the days older than the retention
+func update(now time.Time, change func(*saved)) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	stats, err := get()
+	if err != nil {
+		return err
+	}
+
+	change(stats)
+
+	oldest := now.Add(-retention).Format(dayLayout)
+	for date := range stats.Days {
+		if date < oldest {
+			delete(stats.Days, date)
+		}
+	}
+
+	return cacher.Set(stats)
+}
+
+// dayOf returns the statistics of the day of the given time, adding them if needed
+func (s *saved) dayOf(t time.Time) *Day {
+	date := t.Local().Format(dayLayout)
+
+	day, ok := s.Days[date]
+	if !ok {
+		day = &Day{Date: date}
+		s.Days[date] = day
+	}
+
+	if day.Read == nil {
+		day.Read = make(map[string]int)
+	}
+
+	if day.Downloads == nil {
+		day.Downloads = make(map[string]int)
+	}
+
+	return day
+}
+
+// RecordRead counts the chapter as read today
+func RecordRead(chapter *source.Chapter) error {
+	return recordRead(chapter, time.Now())
+}
+
+func recordRead(chapter *source.Chapter, now time.Time) error {
+	return update(now, func(stats *saved) {
+		day := stats.dayOf(now)
+		day.Chapters++
+		day.Pages += len(chapter.Pages)
+		day.Read[chapter.Manga.Name]++
+	})
+}
+
+// RecordDownload counts the chapter as downloaded today
+func RecordDownload(chapter *source.Chapter) error {
+	return recordDownload(chapter, time.Now())
+}
+
+func recordDownload(chapter *source.Chapter, now time.Time) error {
+	return update(now, func(stats *saved) {
+		day := stats.dayOf(now)
+		day.Downloaded++
+		day.Downloads[chapter.Manga.Name]++
+	})
+}
+
+// Days returns the statistics of each of the days from the first to the last one, inclusive.
+// Days without the statistics are returned empty
+func Days(from, to time.Time) ([]*Day, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	stats, err := get()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		days []*Day
+		last = to.Local().Format(dayLayout)
+	)
+
+	for t := from.Local(); t.Format(dayLayout) <= last; t = t.AddDate(0, 0, 1) {
+		date := t.Format(dayLayout)
+
+		day := &Day{Date: date}
+		if recorded, ok := stats.Days[date]; ok {
+			// the days are shared with the cache
+			copied := *recorded
+			day = &copied
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+// Last returns the statistics of the given number of days, ending today
+func Last(days int) ([]*Day, error) {
+	now := time.Now()
+	return Days(now.AddDate(0, 0, 1-days), now)
+}
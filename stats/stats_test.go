@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"encoding/json"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func chapterOf(manga string, pages int) *source.Chapter {
+	return &source.Chapter{
+		Manga: &source.Manga{Name: manga},
+		Pages: lo.Times(pages, func(i int) *source.Page { return &source.Page{Index: i + 1} }),
+	}
+}
+
+func TestStats(t *testing.T) {
+	Convey("Given the chapters read and downloaded on the different days", t, func() {
+		filesystem.SetMemMapFs()
+		lo.Must0(cacher.Set(&saved{}))
+
+		today := time.Now()
+		yesterday := today.AddDate(0, 0, -1)
+
+		So(recordRead(chapterOf("Berserk", 20), yesterday), ShouldBeNil)
+		So(recordRead(chapterOf("Berserk", 22), today), ShouldBeNil)
+		So(recordRead(chapterOf("Monster", 30), today), ShouldBeNil)
+		So(recordDownload(chapterOf("Pluto", 10), today), ShouldBeNil)
+
+		Convey("When the days are listed", func() {
+			days := lo.Must(Days(today.AddDate(0, 0, -2), today))
+
+			Convey("Then each of them should be present, the empty ones too", func() {
+				So(days, ShouldHaveLength, 3)
+				So(days[0].Chapters, ShouldEqual, 0)
+				So(days[1].Chapters, ShouldEqual, 1)
+				So(days[2].Chapters, ShouldEqual, 2)
+				So(days[2].Pages, ShouldEqual, 52)
+				So(days[2].Downloaded, ShouldEqual, 1)
+			})
+
+			Convey("Then they should be summarized, the most read manga first", func() {
+				digest := Summarize(days)
+				So(digest.Chapters, ShouldEqual, 3)
+				So(digest.Pages, ShouldEqual, 72)
+				So(digest.Read[0], ShouldResemble, &MangaCount{Manga: "Berserk", Chapters: 2})
+				So(digest.Downloads, ShouldResemble, []*MangaCount{{Manga: "Pluto", Chapters: 1}})
+				So(digest.To, ShouldEqual, today.Format(dayLayout))
+			})
+		})
+
+		Convey("When the days are older than the retention", func() {
+			So(recordRead(chapterOf("Vagabond", 1), today.Add(-2*retention)), ShouldBeNil)
+			So(recordRead(chapterOf("Vagabond", 1), today), ShouldBeNil)
+
+			Convey("Then they should be dropped", func() {
+				stats := lo.Must(get())
+				So(stats.Days, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When the digest webhook is set", func() {
+			var digests []*Digest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Digest *Digest `json:"digest"`
+				}
+
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				digests = append(digests, body.Digest)
+			}))
+			defer server.Close()
+
+			viper.Set(key.HistoryDigestWebhook, server.URL)
+			defer viper.Set(key.HistoryDigestWebhook, "")
+
+			So(sendDigest(today), ShouldBeNil)
+			So(sendDigest(today.Add(time.Hour)), ShouldBeNil)
+
+			Convey("Then the digest of the week should be posted once a week", func() {
+				So(digests, ShouldHaveLength, 1)
+				So(digests[0].Days, ShouldHaveLength, 7)
+				So(digests[0].Chapters, ShouldEqual, 3)
+
+				So(sendDigest(today.Add(week)), ShouldBeNil)
+				So(digests, ShouldHaveLength, 2)
+			})
+		})
+	})
+}
+
+func TestSparkline(t *testing.T) {
+	Convey("Given the values", t, func() {
+		values := []int{0, 1, 4, 8}
+
+		Convey("Then the sparkline should scale them to the largest one", func() {
+			So(Sparkline(values), ShouldEqual, " ▁▄█")
+		})
+
+		Convey("Then no values should give an empty sparkline", func() {
+			So(Sparkline(nil), ShouldBeEmpty)
+		})
+	})
+}
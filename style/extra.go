@@ -10,6 +10,6 @@ var (
 	ErrorTitle = NewColored(color.New("230"), color.Red).Padding(0, 1).Render
 )
 
-func Tag(foreground, background lipgloss.Color) func(string) string {
+func Tag(foreground, background lipgloss.TerminalColor) func(string) string {
 	return NewColored(foreground, background).Padding(0, 1).Render
 }
@@ -0,0 +1,130 @@
+package subscription
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+	"net/http"
+)
+
+// Delta are the chapters of the manga that appeared on the source since the previous update
+type Delta struct {
+	Manga    string        `json:"manga"`
+	Source   string        `json:"source"`
+	Chapters []*NewChapter `json:"chapters"`
+}
+
+// NewChapter is the chapter of the Delta
+type NewChapter struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+}
+
+// deltaOf returns the chapters that were not seen by the previous updates of the entry, oldest first.
+// Entries of the older versions have only the last seen index, the chapters above it are new then.
+// Nothing is new on the first check of the manga, its chapters are only marked seen
+func deltaOf(entry *library.Entry, remote *source.Manga) *Delta {
+	delta := &Delta{Manga: entry.Name, Chapters: make([]*NewChapter, 0)}
+	if remote.Source != nil {
+		delta.Source = remote.Source.Name()
+	}
+
+	if entry.Checked.IsZero() {
+		return delta
+	}
+
+	seen := lo.SliceToMap(entry.Seen, func(key string) (string, bool) {
+		return key, true
+	})
+
+	chapters := make([]*source.Chapter, 0)
+	for _, chapter := range remote.Chapters {
+		if len(seen) > 0 && !seen[seenKey(chapter)] || len(seen) == 0 && chapter.Index > entry.LastSeen {
+			chapters = append(chapters, chapter)
+		}
+	}
+
+	slices.SortFunc(chapters, source.ChapterLess)
+	for _, chapter := range chapters {
+		delta.Chapters = append(delta.Chapters, &NewChapter{Index: chapter.Index, Name: chapter.Name, URL: chapter.URL})
+	}
+
+	return delta
+}
+
+// lastSeen returns the highest index of the chapters, or the given one if it is higher.
+// Removed chapters don't make the old ones new again
+func lastSeen(seen int, chapters []*source.Chapter) int {
+	for _, chapter := range chapters {
+		if chapter.Index > seen {
+			seen = chapter.Index
+		}
+	}
+
+	return seen
+}
+
+// seenKey returns what the chapter is told seen by, see library.Entry.Seen
+func seenKey(chapter *source.Chapter) string {
+	switch {
+	case chapter.URL != "":
+		return chapter.URL
+	case chapter.ID != "":
+		return chapter.ID
+	default:
+		return chapter.Name
+	}
+}
+
+// seenOf adds the chapters to the seen ones.
+// Removed chapters are kept, so that they are not new again if the source brings them back
+func seenOf(seen []string, chapters []*source.Chapter) []string {
+	return lo.Uniq(append(seen, lo.Map(chapters, func(chapter *source.Chapter, _ int) string {
+		return seenKey(chapter)
+	})...))
+}
+
+// Notify posts the deltas as JSON to the webhook in the config, see key.LibraryUpdateWebhook.
+// Nothing is posted if the webhook is not set or there is nothing new
+func Notify(deltas []*Delta) error {
+	webhook := viper.GetString(key.LibraryUpdateWebhook)
+	if webhook == "" || len(deltas) == 0 {
+		return nil
+	}
+
+	contents, err := json.Marshal(struct {
+		New []*Delta `json:"new"`
+	}{deltas})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(contents))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", constant.Mangal+"/"+constant.Version)
+
+	resp, err := network.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("update notification was not accepted: %s", resp.Status)
+	}
+
+	return nil
+}
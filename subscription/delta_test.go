@@ -0,0 +1,88 @@
+package subscription
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeltaOf(t *testing.T) {
+	Convey("Given a manga with 5 chapters on the source", t, func() {
+		remote := &source.Manga{Name: "Chainsaw Man"}
+		remote.Chapters = lo.Map([]int{5, 4, 3, 2, 1}, func(index int, _ int) *source.Chapter {
+			return &source.Chapter{Index: index, Name: "Chapter", URL: fmt.Sprintf("/%d", index), Manga: remote}
+		})
+
+		Convey("When it was checked up to the third chapter", func() {
+			entry := &library.Entry{Name: "Chainsaw Man", LastSeen: 3, Checked: time.Now()}
+			delta := deltaOf(entry, remote)
+
+			Convey("Then the newer chapters should be new, oldest first", func() {
+				So(lo.Map(delta.Chapters, func(c *NewChapter, _ int) int { return c.Index }), ShouldResemble, []int{4, 5})
+			})
+
+			Convey("And the last seen chapter should be the newest", func() {
+				So(lastSeen(entry.LastSeen, remote.Chapters), ShouldEqual, 5)
+			})
+		})
+
+		Convey("When the source has inserted a chapter before the seen ones", func() {
+			entry := &library.Entry{Name: "Chainsaw Man", Checked: time.Now(), Seen: seenOf(nil, remote.Chapters)}
+			remote.Chapters = append(remote.Chapters, &source.Chapter{Index: 6, Name: "Chapter", URL: "/5"}, &source.Chapter{Index: 3, Name: "Extra", URL: "/2.5"})
+
+			Convey("Then only the inserted chapter should be new, despite the shifted indexes", func() {
+				So(lo.Map(deltaOf(entry, remote).Chapters, func(c *NewChapter, _ int) string { return c.URL }), ShouldResemble, []string{"/2.5"})
+			})
+		})
+
+		Convey("When it was never checked", func() {
+			entry := &library.Entry{Name: "Chainsaw Man"}
+
+			Convey("Then nothing should be new", func() {
+				So(deltaOf(entry, remote).Chapters, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the source has removed the newest chapters", func() {
+			Convey("Then the last seen chapter should be kept", func() {
+				So(lastSeen(7, remote.Chapters), ShouldEqual, 7)
+			})
+		})
+	})
+}
+
+func TestNotify(t *testing.T) {
+	Convey("Given a webhook", t, func() {
+		var received struct {
+			New []*Delta `json:"new"`
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&received)
+		}))
+		defer server.Close()
+
+		viper.Set(key.LibraryUpdateWebhook, server.URL)
+		defer viper.Set(key.LibraryUpdateWebhook, "")
+
+		Convey("When the new chapters are notified", func() {
+			deltas := []*Delta{{Manga: "Chainsaw Man", Chapters: []*NewChapter{{Index: 4, Name: "Chapter 4"}}}}
+			So(Notify(deltas), ShouldBeNil)
+
+			Convey("Then the webhook should get them", func() {
+				So(received.New, ShouldHaveLength, 1)
+				So(received.New[0].Manga, ShouldEqual, "Chainsaw Man")
+				So(received.New[0].Chapters[0].Index, ShouldEqual, 4)
+			})
+		})
+	})
+}
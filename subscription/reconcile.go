@@ -0,0 +1,314 @@
+package subscription
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ConflictRenumbered chapter is on the source under another index, so it is expected at another path
+	ConflictRenumbered = "renumbered"
+	// ConflictRemoved chapter is not on the source anymore.
+	// If another chapter is expected at its path, update takes it for downloaded
+	ConflictRemoved = "removed"
+	// ConflictUnmatched chapter file is not at the path of any chapter on the source,
+	// e.g. after the source renamed the chapters, and it is not known which one it is
+	ConflictUnmatched = "unmatched"
+)
+
+const (
+	// ActionRelink moves the local chapter to the path of the remote one and maps it to it in the manifest
+	ActionRelink = "relink"
+	// ActionIgnore keeps the local chapter as it is, it is not reported again
+	ActionIgnore = "ignore"
+	// ActionRedownload replaces the local chapter with the remote one
+	ActionRedownload = "redownload"
+)
+
+// Conflict is a downloaded chapter that doesn't match the chapters on the source anymore
+type Conflict struct {
+	// Kind is one of the Conflict* constants
+	Kind string
+	// Local chapter file
+	Local *library.Chapter
+	// Remote is the chapter on the source the local one corresponds to, nil if there is none
+	Remote *source.Chapter
+}
+
+// Actions returns the actions that can resolve the conflict
+func (c *Conflict) Actions() []string {
+	if c.Remote == nil {
+		return []string{ActionIgnore}
+	}
+
+	return []string{ActionRelink, ActionIgnore, ActionRedownload}
+}
+
+// Resolution of the conflict
+type Resolution struct {
+	Conflict *Conflict
+	// Action is one of the Action* constants
+	Action string
+}
+
+// Remote returns the manga of the entry on its source, with the chapters
+func Remote(entry *library.Entry) (*source.Manga, error) {
+	if err := entry.Sources.Check(entry.SourceID); err != nil {
+		return nil, err
+	}
+
+	src, err := loadSource(entry.SourceID, func(string) {})
+	if err != nil {
+		return nil, err
+	}
+
+	return listChapters(entry, src)
+}
+
+// Conflicts returns the downloaded chapters of the manga that don't match the chapters on the source,
+// except the ones kept before, see ActionIgnore
+func Conflicts(manga *library.Manga, entry *library.Entry, remote *source.Manga) ([]*Conflict, error) {
+	// paths of the chapters depend on the formats of the manga
+	remote.Settings = settingsOf(manga, entry)
+
+	local, err := manga.Chapters()
+	if err != nil {
+		return nil, err
+	}
+
+	return conflictsOf(entry, local, remote.Chapters), nil
+}
+
+func conflictsOf(entry *library.Entry, local []*library.Chapter, chapters []*source.Chapter) []*Conflict {
+	var (
+		byURL = lo.KeyBy(chapters, func(chapter *source.Chapter) string {
+			return chapter.URL
+		})
+		byIndex = lo.KeyBy(chapters, func(chapter *source.Chapter) int {
+			return chapter.Index
+		})
+		// chapters by their file names, for each format the local chapters are in
+		byFilename = make(map[string]map[string]*source.Chapter)
+		conflicts  []*Conflict
+	)
+
+	for _, chapter := range local {
+		if lo.Contains(entry.Kept, filepath.ToSlash(chapter.Path)) {
+			continue
+		}
+
+		format := formatOf(chapter)
+		if _, ok := byFilename[format]; !ok {
+			byFilename[format] = lo.KeyBy(chapters, func(chapter *source.Chapter) string {
+				return chapter.As(format).Filename()
+			})
+		}
+
+		expected := byFilename[format][filepath.Base(chapter.Path)]
+
+		// manifest knows which chapter the file is, the file name is only a guess
+		actual := expected
+		if chapter.URL != "" {
+			actual = byURL[chapter.URL]
+		}
+
+		switch {
+		case actual != nil && actual == expected:
+			continue
+		case actual != nil:
+			conflicts = append(conflicts, &Conflict{Kind: ConflictRenumbered, Local: chapter, Remote: actual})
+		case chapter.URL != "":
+			conflicts = append(conflicts, &Conflict{Kind: ConflictRemoved, Local: chapter, Remote: expected})
+		default:
+			conflicts = append(conflicts, &Conflict{Kind: ConflictUnmatched, Local: chapter, Remote: byIndex[chapter.Index]})
+		}
+	}
+
+	return conflicts
+}
+
+func formatOf(chapter *library.Chapter) string {
+	return strings.TrimPrefix(filepath.Ext(chapter.Path), ".")
+}
+
+// Resolve applies the resolutions of the conflicts.
+// Chapters are relinked all at once, so that they can take the paths of each other
+func Resolve(manga *library.Manga, entry *library.Entry, resolutions []*Resolution) error {
+	var relinks, redownloads []*Conflict
+	for _, resolution := range resolutions {
+		conflict := resolution.Conflict
+		if resolution.Action != ActionIgnore && conflict.Remote == nil {
+			return fmt.Errorf("%s has no chapter on the source to %s", conflict.Local.Path, resolution.Action)
+		}
+
+		if conflict.Remote != nil && conflict.Remote.Manga != nil {
+			conflict.Remote.Manga.Settings = settingsOf(manga, entry)
+		}
+
+		switch resolution.Action {
+		case ActionRelink:
+			relinks = append(relinks, conflict)
+		case ActionIgnore:
+			entry.Kept = lo.Uniq(append(entry.Kept, filepath.ToSlash(conflict.Local.Path)))
+		case ActionRedownload:
+			redownloads = append(redownloads, conflict)
+		default:
+			return fmt.Errorf("unknown action %q", resolution.Action)
+		}
+	}
+
+	if err := relink(manga, entry, relinks); err != nil {
+		return err
+	}
+
+	if err := redownload(manga, entry, redownloads); err != nil {
+		return err
+	}
+
+	return manga.SaveEntry(entry)
+}
+
+// relink moves the local chapters to the paths of the remote ones
+func relink(manga *library.Manga, entry *library.Entry, conflicts []*Conflict) error {
+	var (
+		from = make([]string, len(conflicts))
+		to   = make([]string, len(conflicts))
+	)
+
+	for i, conflict := range conflicts {
+		from[i] = filepath.Join(manga.Path, conflict.Local.Path)
+		// volume directories of the local chapters are kept
+		to[i] = filepath.Join(manga.Path, filepath.Dir(conflict.Local.Path), conflict.Remote.As(formatOf(conflict.Local)).Filename())
+	}
+
+	// targets may only be taken by the chapters that are moved away
+	moved := lo.SliceToMap(from, func(path string) (string, bool) {
+		return path, true
+	})
+
+	for i, path := range to {
+		if lo.Count(to, path) > 1 {
+			return fmt.Errorf("%s is relinked to %s along with another chapter", conflicts[i].Local.Path, filepath.Base(path))
+		}
+
+		exists, err := filesystem.Api().Exists(path)
+		if err != nil {
+			return err
+		}
+
+		if exists && !moved[path] {
+			return fmt.Errorf("%s can't be relinked, %s exists", conflicts[i].Local.Path, filepath.Base(path))
+		}
+	}
+
+	for _, path := range from {
+		if err := filesystem.Api().Rename(path, path+".relink"); err != nil {
+			return err
+		}
+	}
+
+	for i, conflict := range conflicts {
+		if err := filesystem.Api().Rename(from[i]+".relink", to[i]); err != nil {
+			return err
+		}
+
+		err := manifest.Relink(manga.Path, from[i], to[i], &manifest.Chapter{
+			Manga:  entry.Name,
+			Name:   conflict.Remote.Name,
+			Index:  conflict.Remote.Index,
+			URL:    conflict.Remote.URL,
+			Source: entry.SourceID,
+			Format: formatOf(conflict.Local),
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// redownload replaces the local chapters with the remote ones
+func redownload(manga *library.Manga, entry *library.Entry, conflicts []*Conflict) error {
+	for _, conflict := range conflicts {
+		if err := replace(manga, entry, conflict.Remote, conflict.Local.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replace downloads the chapter in the format of the local file at the relative path and puts it in its place.
+// Chapter is downloaded to a temporary library next to the manga first,
+// so that the local file is kept if the download fails
+func replace(manga *library.Manga, entry *library.Entry, chapter *source.Chapter, relative string) error {
+	root := filepath.Dir(manga.Path)
+	temp, err := filesystem.Api().TempDir(root, ".redownload-")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := filesystem.Api().RemoveAll(temp); err != nil {
+			log.Warn(err)
+		}
+	}()
+
+	format := strings.TrimPrefix(filepath.Ext(relative), ".")
+
+	// the manga is shared by the other chapters, which are still downloaded to the library
+	settings := *settingsOf(manga, entry)
+	settings.Root = temp
+	settings.Formats = []string{format}
+
+	tempManga := *chapter.Manga
+	tempManga.Settings = &settings
+
+	formatted := chapter.As(format)
+	formatted.Manga = &tempManga
+
+	saved, err := downloader.DownloadTo(formatted, temp, func(string) {})
+	if err != nil {
+		return err
+	}
+
+	var (
+		local = filepath.Join(manga.Path, relative)
+		// volume directories of the local chapters are kept
+		target = filepath.Join(manga.Path, filepath.Dir(relative), filepath.Base(saved))
+	)
+
+	if err = filesystem.Api().Rename(saved, target); err != nil {
+		return err
+	}
+
+	if local != target {
+		if err = filesystem.Api().Remove(local); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err = manifest.Forget(manga.Path, local); err != nil {
+		return err
+	}
+
+	return manifest.TrackFile(manga.Path, target, &manifest.Chapter{
+		Manga:  entry.Name,
+		Name:   chapter.Name,
+		Index:  chapter.Index,
+		URL:    chapter.URL,
+		Source: entry.SourceID,
+		Format: format,
+	})
+}
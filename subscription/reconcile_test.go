@@ -0,0 +1,198 @@
+package subscription
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// downloaded writes the chapter files of the manga, mapped to the chapters with the given URLs in the manifest
+func downloaded(manga *library.Manga, files map[string]string) {
+	tracked := lo.Must(manifest.Load(manga.Path))
+	for name, url := range files {
+		path := filepath.Join(manga.Path, name)
+		lo.Must0(filesystem.Api().WriteFile(path, []byte(name), os.ModePerm))
+
+		if url != "" {
+			lo.Must0(tracked.Add(manga.Path, path, &manifest.Chapter{Name: name, URL: url}))
+		}
+	}
+
+	lo.Must0(tracked.Save(manga.Path))
+}
+
+func remoteOf(name string, urls ...string) *source.Manga {
+	remote := &source.Manga{Name: name}
+	for i, url := range urls {
+		remote.Chapters = append(remote.Chapters, &source.Chapter{
+			Name:  fmt.Sprintf("Chapter %d", i+1),
+			Index: i + 1,
+			URL:   url,
+			Manga: remote,
+		})
+	}
+
+	return remote
+}
+
+func TestConflicts(t *testing.T) {
+	Convey("Given a manga whose source has removed the first chapter and renumbered the rest", t, func() {
+		manga := lo.Must(library.TrackIn("/reconcile", &library.Entry{Name: "Dorohedoro", SourceID: "test"}))
+		entry := lo.Must(manga.Entry())
+		downloaded(manga, map[string]string{
+			"Chapter_1.cbz": "/1",
+			"Chapter_2.cbz": "/2",
+			"Extra.cbz":     "",
+		})
+
+		remote := remoteOf("Dorohedoro", "/2", "/3")
+		conflicts := lo.Must(Conflicts(manga, entry, remote))
+		byPath := lo.KeyBy(conflicts, func(conflict *Conflict) string {
+			return conflict.Local.Path
+		})
+
+		Convey("Then every local chapter should conflict", func() {
+			So(conflicts, ShouldHaveLength, 3)
+			So(byPath["Chapter_1.cbz"].Kind, ShouldEqual, ConflictRemoved)
+			So(byPath["Chapter_1.cbz"].Remote, ShouldEqual, remote.Chapters[0])
+			So(byPath["Chapter_2.cbz"].Kind, ShouldEqual, ConflictRenumbered)
+			So(byPath["Chapter_2.cbz"].Remote, ShouldEqual, remote.Chapters[0])
+			So(byPath["Extra.cbz"].Kind, ShouldEqual, ConflictUnmatched)
+			So(byPath["Extra.cbz"].Actions(), ShouldResemble, []string{ActionIgnore})
+		})
+
+		Convey("When the renumbered chapter is relinked to the path of the removed one", func() {
+			err := Resolve(manga, entry, []*Resolution{{Conflict: byPath["Chapter_2.cbz"], Action: ActionRelink}})
+
+			Convey("Then it should fail without moving anything", func() {
+				So(err, ShouldNotBeNil)
+				So(lo.Must(filesystem.Api().Exists(filepath.Join(manga.Path, "Chapter_2.cbz"))), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the unmatched chapter is ignored", func() {
+			So(Resolve(manga, entry, []*Resolution{{Conflict: byPath["Extra.cbz"], Action: ActionIgnore}}), ShouldBeNil)
+
+			Convey("Then it should not be reported again", func() {
+				So(lo.Must(manga.Entry()).Kept, ShouldResemble, []string{"Extra.cbz"})
+				So(lo.Must(Conflicts(manga, lo.Must(manga.Entry()), remote)), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a manga whose source has swapped two chapters", t, func() {
+		manga := lo.Must(library.TrackIn("/swapped", &library.Entry{Name: "Blame!", SourceID: "test"}))
+		entry := lo.Must(manga.Entry())
+		downloaded(manga, map[string]string{
+			"Chapter_1.cbz": "/1",
+			"Chapter_2.cbz": "/2",
+		})
+
+		remote := remoteOf("Blame!", "/2", "/1")
+		conflicts := lo.Must(Conflicts(manga, entry, remote))
+		So(conflicts, ShouldHaveLength, 2)
+
+		Convey("When both are relinked", func() {
+			resolutions := lo.Map(conflicts, func(conflict *Conflict, _ int) *Resolution {
+				return &Resolution{Conflict: conflict, Action: ActionRelink}
+			})
+
+			So(Resolve(manga, entry, resolutions), ShouldBeNil)
+
+			Convey("Then they should take the paths of each other", func() {
+				contents := lo.Must(filesystem.Api().ReadFile(filepath.Join(manga.Path, "Chapter_1.cbz")))
+				So(string(contents), ShouldEqual, "Chapter_2.cbz")
+
+				tracked := lo.Must(manifest.Load(manga.Path))
+				So(tracked.Files["Chapter_1.cbz"].Chapter.URL, ShouldEqual, "/2")
+				So(tracked.Files["Chapter_1.cbz"].Chapter.Index, ShouldEqual, 1)
+			})
+
+			Convey("And no conflicts should be left", func() {
+				So(lo.Must(Conflicts(manga, entry, remote)), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+// brokenSource fails to list the pages of the chapters
+type brokenSource struct {
+	catalogSource
+}
+
+func (s *brokenSource) PagesOf(*source.Chapter) ([]*source.Page, error) {
+	return nil, fmt.Errorf("%s is down", s.id)
+}
+
+func TestResolveInVolumes(t *testing.T) {
+	Convey("Given a manga whose chapters are in the volume directories", t, func() {
+		viper.Set(key.MetadataFetchAnilist, false)
+		viper.Set(key.DownloaderCreateMangaDir, true)
+
+		manga := lo.Must(library.TrackIn("/volumes", &library.Entry{Name: "Vagabond", SourceID: "test"}))
+		entry := lo.Must(manga.Entry())
+		lo.Must0(filesystem.Api().MkdirAll(filepath.Join(manga.Path, "Vol. 1"), os.ModePerm))
+		downloaded(manga, map[string]string{filepath.Join("Vol. 1", "Chapter_2.cbz"): "/1"})
+
+		remote := remoteOf("Vagabond", "/1")
+		remote.Source = &catalogSource{id: "test"}
+		conflict := &Conflict{
+			Kind:   ConflictRenumbered,
+			Local:  &library.Chapter{Path: filepath.Join("Vol. 1", "Chapter_2.cbz"), URL: "/1"},
+			Remote: remote.Chapters[0],
+		}
+
+		exists := func(path string) bool {
+			return lo.Must(filesystem.Api().Exists(filepath.Join(manga.Path, path)))
+		}
+
+		Convey("When the chapter is relinked", func() {
+			So(Resolve(manga, entry, []*Resolution{{Conflict: conflict, Action: ActionRelink}}), ShouldBeNil)
+
+			Convey("Then it should stay in its volume directory", func() {
+				So(exists(filepath.Join("Vol. 1", "Chapter_1.cbz")), ShouldBeTrue)
+				So(exists(filepath.Join("Vol. 1", "Chapter_2.cbz")), ShouldBeFalse)
+			})
+		})
+
+		Convey("When the chapter is redownloaded", func() {
+			So(Resolve(manga, entry, []*Resolution{{Conflict: conflict, Action: ActionRedownload}}), ShouldBeNil)
+
+			Convey("Then it should replace the local one in its volume directory", func() {
+				So(exists(filepath.Join("Vol. 1", "Chapter_1.cbz")), ShouldBeTrue)
+				So(exists(filepath.Join("Vol. 1", "Chapter_2.cbz")), ShouldBeFalse)
+
+				tracked := lo.Must(manifest.Load(manga.Path))
+				So(tracked.Files, ShouldContainKey, "Vol. 1/Chapter_1.cbz")
+				So(tracked.Files, ShouldNotContainKey, "Vol. 1/Chapter_2.cbz")
+				So(tracked.Files["Vol. 1/Chapter_1.cbz"].Chapter.URL, ShouldEqual, "/1")
+			})
+
+			Convey("And the temporary library should be removed", func() {
+				names := lo.Map(lo.Must(filesystem.Api().ReadDir(filepath.Dir(manga.Path))), func(info os.FileInfo, _ int) string {
+					return info.Name()
+				})
+				So(lo.ContainsBy(names, func(name string) bool { return strings.HasPrefix(name, ".redownload-") }), ShouldBeFalse)
+			})
+		})
+
+		Convey("When the chapter fails to be redownloaded", func() {
+			remote.Source = &brokenSource{catalogSource{id: "test"}}
+			So(Resolve(manga, entry, []*Resolution{{Conflict: conflict, Action: ActionRedownload}}), ShouldNotBeNil)
+
+			Convey("Then the local one should be kept", func() {
+				So(exists(filepath.Join("Vol. 1", "Chapter_2.cbz")), ShouldBeTrue)
+			})
+		})
+	})
+}
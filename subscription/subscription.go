@@ -0,0 +1,741 @@
+// Package subscription updates tracked library mangas.
+// New chapters are downloaded and, depending on the subscription of the manga, old ones are removed.
+package subscription
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/quota"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/stats"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options of the update
+type Options struct {
+	// DryRun reports what would be done without downloading or removing anything
+	DryRun bool
+	// IsRead reports whether the chapter of the manga was read.
+	// Defaults to checking the history
+	IsRead func(manga string, index int) bool
+	// Progress is called with the status messages
+	Progress func(string)
+	// Reuploads checks whether the source has re-uploaded the downloaded chapters with the fixed pages,
+	// by comparing their pages with the fingerprints of the manifest
+	Reuploads bool
+	// Redownload is asked whether to download the re-uploaded chapters again, by their paths.
+	// If it is nil, they are only reported
+	Redownload func(paths []string) bool
+	// Completed checks the completed mangas too, they are skipped otherwise
+	Completed bool
+	// Download downloads the new chapters of the notify-only mangas too, see library.SubscriptionNotifyOnly
+	Download bool
+}
+
+// Report of the update, paths are relative to the downloads directory
+type Report struct {
+	Downloaded []string
+	Failed     []string
+	Removed    []string
+	// Reuploaded are the chapters that were re-uploaded by the source since they were downloaded
+	Reuploaded []string
+	// Redownloaded are the re-uploaded chapters that were downloaded again
+	Redownloaded []string
+	// Untracked are the mangas that were skipped because they are not tracked
+	Untracked []string
+	// Completed are the mangas that were found finished and fully downloaded by this update
+	Completed []string
+	// Skipped are the mangas that were skipped because they were completed before
+	Skipped []string
+	// New are the chapters that appeared on the sources since the previous update, by manga
+	New []*Delta
+	// Conflicts are the mangas whose downloaded chapters don't match the ones on the source, see Conflicts
+	Conflicts []string
+	// Fallbacks are the names of the sources that served the mangas instead of their own ones, by manga name.
+	// See key.SourcesPriority
+	Fallbacks map[string]string
+	// Served are the IDs of the sources that the chapters were downloaded from, by their paths.
+	// They are kept in the manifests of the mangas too, see manifest.Chapter.Source
+	Served map[string]string
+	// Errors of the mangas that could not be updated, by manga name
+	Errors map[string]error
+}
+
+// Update downloads new chapters of the tracked mangas and removes the old ones,
+// according to their subscriptions
+func Update(mangas []*library.Manga, options *Options) (*Report, error) {
+	if options.IsRead == nil {
+		options.IsRead = history.IsRead()
+	}
+
+	if options.Progress == nil {
+		options.Progress = func(string) {}
+	}
+
+	if !options.DryRun {
+		if err := downloader.Preflight(); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		report = &Report{
+			Errors:    make(map[string]error),
+			New:       make([]*Delta, 0),
+			Fallbacks: make(map[string]string),
+			Served:    make(map[string]string),
+		}
+		sources = make(map[string]source.Source)
+		checks  []*check
+	)
+
+	priority, err := provider.Priority()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, manga := range mangas {
+		entry, err := manga.Entry()
+		if err != nil {
+			return nil, err
+		}
+
+		if entry == nil {
+			report.Untracked = append(report.Untracked, manga.Name)
+			continue
+		}
+
+		if entry.Completed && !options.Completed {
+			report.Skipped = append(report.Skipped, manga.Name)
+			continue
+		}
+
+		// the mangas whose sources can't be used are checked on the fallback sources only
+		if err = entry.Sources.Check(entry.SourceID); err != nil {
+			checks = append(checks, &check{manga: manga, entry: entry, err: err})
+			continue
+		}
+
+		src, ok := sources[entry.SourceID]
+		if !ok {
+			src, err = loadSource(entry.SourceID, options.Progress)
+			if err != nil {
+				checks = append(checks, &check{manga: manga, entry: entry, err: err})
+				continue
+			}
+
+			sources[entry.SourceID] = src
+		}
+
+		checks = append(checks, &check{manga: manga, entry: entry, src: src})
+	}
+
+	checkAll(checks, options)
+
+	for _, c := range checks {
+		if c.err != nil || len(c.remote.Chapters) == 0 {
+			fallback(c, priority, sources, options)
+		}
+
+		if c.err == nil && c.remote.Source.ID() != c.entry.SourceID {
+			report.Fallbacks[c.manga.Name] = c.remote.Source.Name()
+		}
+	}
+
+	// downloads and removals are done one manga at a time, in the given order
+	for _, c := range checks {
+		err := c.err
+		if err == nil {
+			err = update(c.manga, c.entry, c.remote, options, report)
+		}
+
+		if err != nil {
+			log.Warn(err)
+			report.Errors[c.manga.Name] = err
+		}
+	}
+
+	if !options.DryRun {
+		if err := quota.Enforce(where.Downloads(), history.Default()); err != nil {
+			log.Warn(err)
+		}
+
+		if err := Notify(report.New); err != nil {
+			log.Warn(err)
+		}
+
+		if err := stats.SendDigest(); err != nil {
+			log.Warn(err)
+		}
+	}
+
+	return report, nil
+}
+
+// check of the tracked manga for the new chapters
+type check struct {
+	manga  *library.Manga
+	entry  *library.Entry
+	src    source.Source
+	remote *source.Manga
+	err    error
+}
+
+// checkAll lists the chapters of the mangas and their statuses.
+// Mangas of each source are checked by their own workers sharing the rate limit of the source,
+// see key.LibraryUpdateConcurrency and key.LibraryUpdateRate
+func checkAll(checks []*check, options *Options) {
+	// the ones that failed already have no source to check
+	checks = lo.Filter(checks, func(c *check, _ int) bool {
+		return c.err == nil
+	})
+
+	bySource := lo.GroupBy(checks, func(c *check) string {
+		return c.src.ID()
+	})
+
+	concurrency := util.Max(viper.GetInt(key.LibraryUpdateConcurrency), 1)
+	rate := viper.GetInt(key.LibraryUpdateRate)
+
+	// progress is reported by many workers at once
+	var mutex sync.Mutex
+	progress := func(status string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		options.Progress(status)
+	}
+
+	var wg sync.WaitGroup
+	for _, group := range bySource {
+		var limiter *network.Limiter
+		if rate > 0 {
+			limiter = network.NewLimiter(rate)
+		}
+
+		queue := make(chan *check, len(group))
+		for _, c := range group {
+			queue <- c
+		}
+		close(queue)
+
+		for i := 0; i < util.Min(concurrency, len(group)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := range queue {
+					if limiter != nil {
+						limiter.Wait()
+					}
+
+					progress("Checking " + c.manga.Name)
+					c.remote, c.err = listChapters(c.entry, c.src)
+				}
+			}()
+		}
+	}
+
+	wg.Wait()
+}
+
+// fallback looks for the manga of the check on the sources of the priority, in order,
+// when its own source fails or has no chapters. Sources that the entry doesn't allow are skipped,
+// and the check keeps its result if no source has the manga
+func fallback(c *check, priority []*provider.Provider, sources map[string]source.Source, options *Options) {
+	for _, p := range priority {
+		if p.ID == c.entry.SourceID || !c.entry.Sources.Allows(p.ID) {
+			continue
+		}
+
+		src, ok := sources[p.ID]
+		if !ok {
+			var err error
+			if src, err = loadSource(p.ID, options.Progress); err != nil {
+				log.Warn(err)
+				continue
+			}
+
+			sources[p.ID] = src
+		}
+
+		options.Progress("Checking " + c.manga.Name + " on " + p.Name)
+		remote, err := findChapters(c.entry, src)
+		if err != nil {
+			log.Source(p.Name).Warn(err)
+			continue
+		}
+
+		if c.err != nil {
+			log.Warnf("%s: %s, it is served by %s instead", c.manga.Name, c.err, p.Name)
+		} else {
+			log.Warnf("%s has no chapters on its source, it is served by %s instead", c.manga.Name, p.Name)
+		}
+
+		c.remote, c.err = remote, nil
+		return
+	}
+}
+
+// findChapters searches the source for the manga of the entry by its name and lists its chapters.
+// The manga keeps the name of the entry, so that its chapters are saved to the same directory
+func findChapters(entry *library.Entry, src source.Source) (*source.Manga, error) {
+	mangas, err := src.Search(entry.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	found, ok := lo.Find(mangas, func(manga *source.Manga) bool {
+		return manga.HasTitle(entry.Name)
+	})
+	if !ok {
+		return nil, fmt.Errorf("%s is not found on %s", entry.Name, src.Name())
+	}
+
+	chapters, err := src.ChaptersOf(found)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("%s has no chapters on %s", entry.Name, src.Name())
+	}
+
+	found.Name = entry.Name
+	found.Chapters = chapters
+	found.Metadata.Status = statusOf(found)
+	return found, nil
+}
+
+func loadSource(id string, progress func(string)) (source.Source, error) {
+	p, ok := provider.GetByID(id)
+	if !ok {
+		return nil, fmt.Errorf("source %s not found", id)
+	}
+
+	progress("Loading " + p.Name)
+	return p.CreateSource()
+}
+
+// listChapters returns the manga of the entry with its chapters and the publication status
+func listChapters(entry *library.Entry, src source.Source) (*source.Manga, error) {
+	remote := &source.Manga{
+		Name:   entry.Name,
+		URL:    entry.URL,
+		ID:     entry.ID,
+		Source: src,
+	}
+
+	chapters, err := src.ChaptersOf(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	remote.Chapters = chapters
+	remote.Metadata.Status = statusOf(remote)
+	return remote, nil
+}
+
+// coveredByNumber reports whether the chapter of the fallback source is in the library already or was read,
+// matching it with the local chapters by their numbers, see source.Chapter.Number.
+// Chapters without numbers can't be matched, they are left out rather than downloaded twice
+func coveredByNumber(manga string, local []*library.Chapter, chapter *source.Chapter, isRead func(string, int) bool) bool {
+	number, ok := numberOf(chapter.Name)
+	if !ok {
+		log.Warnf("%s has no number, it is not downloaded from the fallback source", chapter.Name)
+		return true
+	}
+
+	for _, localChapter := range local {
+		localNumber, ok := numberOf(localChapter.Name)
+		if !ok {
+			continue
+		}
+
+		// the chapters before the read one were read too
+		if localNumber == number || (localNumber > number && isRead(manga, localChapter.Index)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// numberOf returns the number of the chapter with the name, see source.Chapter.Number
+func numberOf(name string) (float64, bool) {
+	number, ok := (&source.Chapter{Name: name}).Number()
+	if !ok {
+		return 0, false
+	}
+
+	parsed, err := strconv.ParseFloat(number, 64)
+	return parsed, err == nil
+}
+
+// settingsOf returns the settings of the downloads of the tracked manga to its library, with its overrides
+func settingsOf(manga *library.Manga, entry *library.Entry) *source.Settings {
+	return entry.Overrides.Settings(filepath.Dir(manga.Path))
+}
+
+func update(manga *library.Manga, entry *library.Entry, remote *source.Manga, options *Options, report *Report) error {
+	// chapters are looked for in the formats of the manga too
+	remote.Settings = settingsOf(manga, entry)
+
+	var (
+		chapters = remote.Chapters
+		src      = remote.Source
+		err      error
+		// the indexes of the chapters of the fallback source don't match the ones of the tracked source,
+		// they are not compared with the marker and the downloaded chapters
+		fallback = src != nil && src.ID() != entry.SourceID
+	)
+
+	if report.Served == nil {
+		report.Served = make(map[string]string)
+	}
+
+	if !fallback {
+		if delta := deltaOf(entry, remote); len(delta.Chapters) > 0 {
+			report.New = append(report.New, delta)
+		}
+	}
+
+	// the marker is kept even if nothing gets downloaded
+	if !options.DryRun {
+		if !fallback {
+			entry.LastSeen = lastSeen(entry.LastSeen, remote.Chapters)
+			entry.Seen = seenOf(entry.Seen, remote.Chapters)
+		}
+		entry.Checked = time.Now().UTC()
+		if err = manga.SaveEntry(entry); err != nil {
+			return err
+		}
+	}
+
+	subscription := entry.Subscription
+	if subscription.NotifyOnly() && !options.Download {
+		return detectCompletion(manga, entry, remote, options, report)
+	}
+
+	if subscription.LatestOnly() {
+		chapters = Newest(chapters, subscription.Keep)
+	}
+
+	local, err := manga.Chapters()
+	if err != nil {
+		return err
+	}
+
+	// renumbered chapters are downloaded already under the other indexes, they are left for Resolve
+	var renumbered []*source.Chapter
+	if conflicts := conflictsOf(entry, local, remote.Chapters); !fallback && len(conflicts) > 0 {
+		report.Conflicts = append(report.Conflicts, manga.Name)
+		for _, conflict := range conflicts {
+			if conflict.Kind == ConflictRenumbered {
+				renumbered = append(renumbered, conflict.Remote)
+			}
+		}
+	}
+
+	for _, chapter := range chapters {
+		if chapter.IsDownloaded() {
+			continue
+		}
+
+		// the indexes of the fallback source differ, its chapters are matched with the local ones by their numbers
+		if fallback && coveredByNumber(manga.Name, local, chapter, options.IsRead) {
+			continue
+		}
+
+		// read chapters are not downloaded again, e.g. after they were removed
+		if !fallback && (options.IsRead(manga.Name, chapter.Index) || lo.Contains(renumbered, chapter)) {
+			continue
+		}
+
+		relative := relativeToDownloads(manga, chapter.PeekPath())
+		options.Progress("Downloading " + relative)
+
+		if options.DryRun {
+			local = append(local, &library.Chapter{Index: chapter.Index, Name: chapter.Name})
+			report.Downloaded = append(report.Downloaded, relative)
+			report.Served[relative] = src.ID()
+			continue
+		}
+
+		if _, err = downloader.DownloadTo(chapter, filepath.Dir(manga.Path), func(string) {}); err != nil {
+			log.Warn(err)
+			report.Failed = append(report.Failed, relative)
+			continue
+		}
+
+		local = append(local, &library.Chapter{Index: chapter.Index, Name: chapter.Name})
+		report.Downloaded = append(report.Downloaded, relative)
+		report.Served[relative] = src.ID()
+	}
+
+	if options.Reuploads && !fallback {
+		if err = reuploads(manga, entry, chapters, src, options, report); err != nil {
+			log.Warn(err)
+		}
+	}
+
+	if err = detectCompletion(manga, entry, remote, options, report); err != nil {
+		log.Warn(err)
+	}
+
+	if !subscription.LatestOnly() {
+		return nil
+	}
+
+	for _, chapter := range Evictable(manga.Name, local, subscription.Keep, options.IsRead) {
+		// chapters that would have been downloaded in a dry run
+		if chapter.Path == "" {
+			continue
+		}
+
+		path := filepath.Join(manga.Path, chapter.Path)
+		options.Progress("Removing " + relativeToDownloads(manga, path))
+
+		if !options.DryRun {
+			err = filesystem.Api().Remove(path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+
+			if err = manifest.Forget(manga.Path, path); err != nil {
+				log.Warn(err)
+			}
+		}
+
+		report.Removed = append(report.Removed, relativeToDownloads(manga, path))
+	}
+
+	return nil
+}
+
+// detectCompletion updates the publication status of the manga
+// and marks it completed once it is finished and every chapter is downloaded or read.
+// Notify-only mangas are completed once they are finished
+func detectCompletion(manga *library.Manga, entry *library.Entry, remote *source.Manga, options *Options, report *Report) error {
+	status := remote.Metadata.Status
+	if status == "" {
+		status = entry.Status
+	}
+
+	// notify-only mangas are read elsewhere, so there is nothing left to report once they are finished
+	completed := status == source.StatusFinished && len(remote.Chapters) > 0 && lo.EveryBy(remote.Chapters, func(chapter *source.Chapter) bool {
+		return entry.Subscription.NotifyOnly() || chapter.IsDownloaded() || options.IsRead(manga.Name, chapter.Index)
+	})
+
+	if completed && !entry.Completed {
+		report.Completed = append(report.Completed, manga.Name)
+	}
+
+	if options.DryRun || (status == entry.Status && completed == entry.Completed) {
+		return nil
+	}
+
+	entry.Status = status
+	entry.Completed = completed
+	return manga.SaveEntry(entry)
+}
+
+// statusOf returns the publication status of the manga given by the source,
+// or by Anilist if the source doesn't know it. Empty if it is unknown
+func statusOf(remote *source.Manga) string {
+	if remote.Metadata.Status != "" {
+		return remote.Metadata.Status
+	}
+
+	if !viper.GetBool(key.MetadataFetchAnilist) {
+		return ""
+	}
+
+	if err := remote.BindWithAnilist(); err != nil {
+		return ""
+	}
+
+	if manga, ok := remote.Anilist.Get(); ok && manga != nil {
+		return strings.ReplaceAll(manga.Status, "_", " ")
+	}
+
+	return ""
+}
+
+// reuploads finds the downloaded chapters whose pages have changed on the source
+// and downloads them again, if the user agrees.
+// Pages are downloaded to be compared with the fingerprints, since their links may change without them
+func reuploads(manga *library.Manga, entry *library.Entry, chapters []*source.Chapter, src source.Source, options *Options, report *Report) error {
+	recorded, err := manifest.Load(manga.Path)
+	if err != nil {
+		return err
+	}
+
+	var (
+		reuploaded []*source.Chapter
+		// local files of the re-uploaded chapters, relative to the manga directory
+		files []string
+		paths []string
+	)
+
+	for _, chapter := range chapters {
+		fingerprint, ok := recorded.FingerprintOf(chapter.URL)
+		if !ok || !chapter.IsDownloaded() {
+			continue
+		}
+
+		file, ok := fileOf(recorded, chapter.URL)
+		if !ok {
+			continue
+		}
+
+		options.Progress("Checking pages of " + chapter.Name)
+		changed, err := pagesChanged(chapter, src, fingerprint)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+
+		if !changed {
+			continue
+		}
+
+		reuploaded = append(reuploaded, chapter)
+		files = append(files, file)
+		paths = append(paths, relativeToDownloads(manga, filepath.Join(manga.Path, file)))
+	}
+
+	report.Reuploaded = append(report.Reuploaded, paths...)
+
+	if len(reuploaded) == 0 || options.DryRun || options.Redownload == nil || !options.Redownload(paths) {
+		return nil
+	}
+
+	for i, chapter := range reuploaded {
+		options.Progress("Downloading " + paths[i])
+
+		if err = replace(manga, entry, chapter, files[i]); err != nil {
+			log.Warn(err)
+			report.Failed = append(report.Failed, paths[i])
+			continue
+		}
+
+		report.Redownloaded = append(report.Redownloaded, paths[i])
+	}
+
+	return nil
+}
+
+// pagesChanged reports whether the pages of the chapter on the source differ from the fingerprint of the downloaded ones
+func pagesChanged(chapter *source.Chapter, src source.Source, fingerprint string) (bool, error) {
+	pages, err := src.PagesOf(chapter)
+	if err != nil {
+		return false, err
+	}
+
+	// no pages is a broken source rather than a re-upload
+	if len(pages) == 0 {
+		return false, nil
+	}
+
+	// pages are not kept in memory for the rest of the chapters
+	defer func() {
+		for _, page := range pages {
+			page.Contents = nil
+		}
+	}()
+
+	for _, page := range pages {
+		if err = page.Download(); err != nil {
+			return false, err
+		}
+	}
+
+	return manifest.Fingerprint(pages) != fingerprint, nil
+}
+
+// fileOf returns the path of the file of the chapter with the given URL, relative to the manga directory
+func fileOf(recorded *manifest.Manifest, chapterURL string) (string, bool) {
+	for path, file := range recorded.Files {
+		if file.Chapter != nil && file.Chapter.URL == chapterURL {
+			return filepath.FromSlash(path), true
+		}
+	}
+
+	return "", false
+}
+
+// Newest returns at most n chapters with the highest indexes
+func Newest(chapters []*source.Chapter, n int) []*source.Chapter {
+	sorted := make([]*source.Chapter, len(chapters))
+	copy(sorted, chapters)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Index > sorted[j].Index
+	})
+
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	return sorted
+}
+
+// Evictable returns the files of the chapters that are older than the newest keep ones and were read.
+// A chapter saved in several formats has a file per format, so the files are counted by their chapter indexes,
+// and every file of the evicted chapter is returned.
+// Unread chapters are never returned, even if they are old
+func Evictable(manga string, chapters []*library.Chapter, keep int, isRead func(string, int) bool) []*library.Chapter {
+	sorted := make([]*library.Chapter, len(chapters))
+	copy(sorted, chapters)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Index > sorted[j].Index
+	})
+
+	// files of the same chapter, newest chapters first
+	var groups [][]*library.Chapter
+	for i, chapter := range sorted {
+		if i == 0 || chapter.Index != sorted[i-1].Index {
+			groups = append(groups, nil)
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], chapter)
+	}
+
+	if len(groups) <= keep {
+		return nil
+	}
+
+	var evictable []*library.Chapter
+	for _, files := range groups[keep:] {
+		if isRead(manga, files[0].Index) {
+			evictable = append(evictable, files...)
+		}
+	}
+
+	return evictable
+}
+
+func relativeToDownloads(manga *library.Manga, path string) string {
+	relative, err := filepath.Rel(filepath.Dir(manga.Path), path)
+	if err != nil {
+		return path
+	}
+
+	return filepath.ToSlash(relative)
+}
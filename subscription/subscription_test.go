@@ -0,0 +1,430 @@
+package subscription
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/library"
+	"github.com/metafates/mangal/manifest"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	viper.Set(key.DownloaderChapterNameTemplate, "{chapter}")
+	viper.Set(key.FormatsUse, constant.FormatCBZ)
+}
+
+func TestNewest(t *testing.T) {
+	Convey("Given chapters in random order", t, func() {
+		chapters := lo.Map([]int{2, 5, 1, 4, 3}, func(index int, _ int) *source.Chapter {
+			return &source.Chapter{Index: index}
+		})
+
+		Convey("When getting the newest 2", func() {
+			newest := Newest(chapters, 2)
+
+			Convey("Then the ones with the highest indexes should be returned", func() {
+				So(lo.Map(newest, func(c *source.Chapter, _ int) int { return c.Index }), ShouldResemble, []int{5, 4})
+			})
+		})
+
+		Convey("When getting more than there are", func() {
+			Convey("Then all of them should be returned", func() {
+				So(len(Newest(chapters, 10)), ShouldEqual, 5)
+			})
+		})
+	})
+}
+
+func TestEvictable(t *testing.T) {
+	Convey("Given 5 downloaded chapters with the first 3 read", t, func() {
+		chapters := lo.Map([]int{1, 2, 3, 4, 5}, func(index int, _ int) *library.Chapter {
+			return &library.Chapter{Index: index, Path: "chapter.cbz"}
+		})
+
+		isRead := func(manga string, index int) bool {
+			return manga == "Berserk" && index <= 3
+		}
+
+		Convey("When keeping the latest 2", func() {
+			evictable := Evictable("Berserk", chapters, 2, isRead)
+
+			Convey("Then older read chapters should be evicted", func() {
+				So(lo.Map(evictable, func(c *library.Chapter, _ int) int { return c.Index }), ShouldResemble, []int{3, 2, 1})
+			})
+		})
+
+		Convey("When keeping only the latest one", func() {
+			evictable := Evictable("Berserk", chapters, 1, isRead)
+
+			Convey("Then unread chapters should be kept anyway", func() {
+				So(lo.Map(evictable, func(c *library.Chapter, _ int) int { return c.Index }), ShouldResemble, []int{3, 2, 1})
+			})
+		})
+
+		Convey("When nothing was read", func() {
+			evictable := Evictable("Naruto", chapters, 1, isRead)
+
+			Convey("Then nothing should be evicted", func() {
+				So(evictable, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestEvictableFormats(t *testing.T) {
+	Convey("Given 4 chapters downloaded as cbz and pdf with the first 3 read", t, func() {
+		var chapters []*library.Chapter
+		for _, index := range []int{1, 2, 3, 4} {
+			for _, format := range []string{"cbz", "pdf"} {
+				chapters = append(chapters, &library.Chapter{Index: index, Path: fmt.Sprintf("Chapter %d.%s", index, format)})
+			}
+		}
+
+		isRead := func(_ string, index int) bool {
+			return index <= 3
+		}
+
+		Convey("When keeping the latest 2", func() {
+			evictable := Evictable("Berserk", chapters, 2, isRead)
+
+			Convey("Then both files of the older read chapters should be evicted", func() {
+				So(lo.Map(evictable, func(c *library.Chapter, _ int) string { return c.Path }), ShouldResemble, []string{
+					"Chapter 2.cbz", "Chapter 2.pdf",
+					"Chapter 1.cbz", "Chapter 1.pdf",
+				})
+			})
+		})
+	})
+}
+
+func TestDetectCompletion(t *testing.T) {
+	Convey("Given a tracked manga read up to the last chapter", t, func() {
+		manga := lo.Must(library.TrackIn("/library", &library.Entry{Name: "Death Note", SourceID: "test"}))
+		entry := lo.Must(manga.Entry())
+		remote := &source.Manga{Name: "Death Note"}
+		remote.Chapters = lo.Map([]int{1, 2, 3}, func(index int, _ int) *source.Chapter {
+			return &source.Chapter{Name: fmt.Sprintf("Chapter %d", index), Index: index, Manga: remote}
+		})
+
+		options := &Options{IsRead: func(string, int) bool { return true }}
+		report := &Report{}
+
+		Convey("When the manga is finished", func() {
+			remote.Metadata.Status = source.StatusFinished
+			So(detectCompletion(manga, entry, remote, options, report), ShouldBeNil)
+
+			Convey("Then it should be marked completed", func() {
+				So(report.Completed, ShouldResemble, []string{"Death Note"})
+
+				saved := lo.Must(manga.Entry())
+				So(saved.Status, ShouldEqual, source.StatusFinished)
+				So(saved.Completed, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the manga is still releasing", func() {
+			remote.Metadata.Status = source.StatusReleasing
+			So(detectCompletion(manga, entry, remote, options, report), ShouldBeNil)
+
+			Convey("Then it should not be marked completed", func() {
+				So(report.Completed, ShouldBeEmpty)
+				So(lo.Must(manga.Entry()).Completed, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the manga is finished but the last chapter is unread", func() {
+			remote.Metadata.Status = source.StatusFinished
+			options.IsRead = func(_ string, index int) bool { return index < 3 }
+			So(detectCompletion(manga, entry, remote, options, report), ShouldBeNil)
+
+			Convey("Then it should not be marked completed", func() {
+				So(report.Completed, ShouldBeEmpty)
+				So(lo.Must(manga.Entry()).Completed, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+// countingSource lists no chapters and counts how many listings are made at once
+type countingSource struct {
+	id             string
+	running, limit int32
+}
+
+func (s *countingSource) Name() string                                    { return s.id }
+func (s *countingSource) ID() string                                      { return s.id }
+func (s *countingSource) Search(string) ([]*source.Manga, error)          { return nil, nil }
+func (s *countingSource) PagesOf(*source.Chapter) ([]*source.Page, error) { return nil, nil }
+
+func (s *countingSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	running := atomic.AddInt32(&s.running, 1)
+	defer atomic.AddInt32(&s.running, -1)
+
+	if running > s.limit {
+		return nil, fmt.Errorf("%d listings at once", running)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	return []*source.Chapter{{Name: "Chapter 1", Index: 1, Manga: manga}}, nil
+}
+
+func TestCheckAll(t *testing.T) {
+	Convey("Given many mangas of two sources", t, func() {
+		viper.Set(key.LibraryUpdateConcurrency, 3)
+		viper.Set(key.LibraryUpdateRate, 0)
+		viper.Set(key.MetadataFetchAnilist, false)
+
+		sources := []*countingSource{{id: "first", limit: 3}, {id: "second", limit: 3}}
+
+		var checks []*check
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprintf("Manga %d", i)
+			checks = append(checks, &check{
+				manga: &library.Manga{Name: name},
+				entry: &library.Entry{Name: name},
+				src:   sources[i%2],
+			})
+		}
+
+		Convey("When they are checked", func() {
+			checkAll(checks, &Options{Progress: func(string) {}})
+
+			Convey("Then every manga should be checked without exceeding the concurrency of its source", func() {
+				for _, c := range checks {
+					So(c.err, ShouldBeNil)
+					So(c.remote.Chapters, ShouldHaveLength, 1)
+				}
+			})
+		})
+	})
+}
+
+func TestUpdateNotifyOnly(t *testing.T) {
+	Convey("Given a notify-only manga checked before", t, func() {
+		manga := lo.Must(library.TrackIn("/notify", &library.Entry{
+			Name:         "Oshi no Ko",
+			SourceID:     "test",
+			Subscription: library.Subscription{Mode: library.SubscriptionNotifyOnly},
+			LastSeen:     1,
+			Checked:      time.Now(),
+		}))
+		entry := lo.Must(manga.Entry())
+
+		remote := &source.Manga{Name: "Oshi no Ko"}
+		remote.Chapters = lo.Map([]int{1, 2}, func(index int, _ int) *source.Chapter {
+			return &source.Chapter{Name: fmt.Sprintf("Chapter %d", index), Index: index, Manga: remote}
+		})
+
+		Convey("When it is updated", func() {
+			report := &Report{}
+			options := &Options{IsRead: func(string, int) bool { return false }, Progress: func(string) {}}
+			So(update(manga, entry, remote, options, report), ShouldBeNil)
+
+			Convey("Then the new chapter should be reported without downloading anything", func() {
+				So(report.New, ShouldHaveLength, 1)
+				So(report.New[0].Chapters[0].Index, ShouldEqual, 2)
+				So(report.Downloaded, ShouldBeEmpty)
+			})
+
+			Convey("And the new chapter should be marked seen", func() {
+				So(lo.Must(manga.Entry()).LastSeen, ShouldEqual, 2)
+			})
+		})
+	})
+}
+
+func TestUpdateBlockedSource(t *testing.T) {
+	Convey("Given a manga tracked from the blocked source", t, func() {
+		manga := lo.Must(library.TrackIn("/blocked", &library.Entry{
+			Name:     "Vinland Saga",
+			SourceID: "mirror",
+			Sources:  library.Sources{Blocked: []string{"mirror"}},
+		}))
+
+		Convey("When it is updated", func() {
+			options := &Options{IsRead: func(string, int) bool { return false }, Progress: func(string) {}, DryRun: true}
+			report, err := Update([]*library.Manga{manga}, options)
+
+			Convey("Then it should fail instead of being checked", func() {
+				So(err, ShouldBeNil)
+				So(report.Errors, ShouldContainKey, "Vinland Saga")
+				So(report.Errors["Vinland Saga"].Error(), ShouldContainSubstring, "blocked")
+			})
+		})
+	})
+}
+
+// catalogSource has the mangas with the given numbers of chapters
+type catalogSource struct {
+	id     string
+	mangas map[string]int
+}
+
+func (s *catalogSource) Name() string                                    { return s.id }
+func (s *catalogSource) ID() string                                      { return s.id }
+func (s *catalogSource) PagesOf(*source.Chapter) ([]*source.Page, error) { return nil, nil }
+
+func (s *catalogSource) Search(query string) ([]*source.Manga, error) {
+	var found []*source.Manga
+	for name := range s.mangas {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(query)) {
+			found = append(found, &source.Manga{Name: name, Source: s})
+		}
+	}
+
+	return found, nil
+}
+
+func (s *catalogSource) ChaptersOf(manga *source.Manga) ([]*source.Chapter, error) {
+	return lo.Times(s.mangas[manga.Name], func(i int) *source.Chapter {
+		return &source.Chapter{Name: fmt.Sprintf("Chapter %d", i+1), Index: i + 1, Manga: manga}
+	}), nil
+}
+
+func TestFallback(t *testing.T) {
+	Convey("Given a manga whose source fails and the sources of the priority", t, func() {
+		viper.Set(key.MetadataFetchAnilist, false)
+
+		sources := map[string]source.Source{
+			"tracked": &catalogSource{id: "tracked"},
+			"blocked": &catalogSource{id: "blocked", mangas: map[string]int{"Dorohedoro": 5}},
+			"missing": &catalogSource{id: "missing", mangas: map[string]int{"Dororo": 2}},
+			"mirror":  &catalogSource{id: "mirror", mangas: map[string]int{"dorohedoro": 3}},
+		}
+		priority := lo.Map([]string{"tracked", "blocked", "missing", "mirror"}, func(id string, _ int) *provider.Provider {
+			return &provider.Provider{ID: id, Name: id}
+		})
+
+		c := &check{
+			manga: &library.Manga{Name: "Dorohedoro"},
+			entry: &library.Entry{Name: "Dorohedoro", SourceID: "tracked", Sources: library.Sources{Blocked: []string{"blocked"}}},
+			err:   fmt.Errorf("source is down"),
+		}
+
+		Convey("When it falls back", func() {
+			fallback(c, priority, sources, &Options{Progress: func(string) {}})
+
+			Convey("Then the first allowed source that has it should serve it, under the tracked name", func() {
+				So(c.err, ShouldBeNil)
+				So(c.remote.Source.ID(), ShouldEqual, "mirror")
+				So(c.remote.Name, ShouldEqual, "Dorohedoro")
+				So(c.remote.Chapters, ShouldHaveLength, 3)
+			})
+		})
+
+		Convey("When no source has it", func() {
+			delete(sources, "mirror")
+			priority = priority[:3]
+			fallback(c, priority, sources, &Options{Progress: func(string) {}})
+
+			Convey("Then the error of its source should be kept", func() {
+				So(c.err, ShouldBeError, "source is down")
+				So(c.remote, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestUpdateFallback(t *testing.T) {
+	Convey("Given a manga served by the fallback source, whose chapters have other names and indexes", t, func() {
+		viper.Set(key.MetadataFetchAnilist, false)
+		viper.Set(key.MetadataManifest, true)
+		viper.Set(key.DownloaderCreateMangaDir, true)
+
+		manga := lo.Must(library.TrackIn("/fallback", &library.Entry{Name: "Dorohedoro", SourceID: "tracked"}))
+		entry := lo.Must(manga.Entry())
+
+		// the first chapters were read and removed
+		for _, name := range []string{"Chapter 3", "Chapter 4"} {
+			So(filesystem.Api().WriteFile(filepath.Join(manga.Path, name+".cbz"), []byte(name), os.ModePerm), ShouldBeNil)
+		}
+
+		mirror := &catalogSource{id: "mirror"}
+		remote := &source.Manga{Name: "Dorohedoro", Source: mirror}
+		remote.Chapters = lo.Map([]int{1, 2, 3, 4, 5}, func(number int, _ int) *source.Chapter {
+			return &source.Chapter{Name: fmt.Sprintf("Ch. %d", number), Index: number + 10, Manga: remote}
+		})
+
+		isRead := func(_ string, index int) bool { return index <= 3 }
+
+		Convey("When it is updated", func() {
+			report := &Report{}
+			So(update(manga, entry, remote, &Options{IsRead: isRead, Progress: func(string) {}}, report), ShouldBeNil)
+
+			Convey("Then only the chapter missing by its number should be downloaded", func() {
+				So(report.Downloaded, ShouldHaveLength, 1)
+				So(report.Downloaded[0], ShouldEndWith, "Ch._5.cbz")
+			})
+
+			Convey("And the fallback source should be recorded in the manifest", func() {
+				tracked := lo.Must(manifest.Load(manga.Path))
+				file, ok := tracked.Files["Ch._5.cbz"]
+				So(ok, ShouldBeTrue)
+				So(file.Chapter.Source, ShouldEqual, "mirror")
+			})
+		})
+	})
+}
+
+// pagedSource gives the pages at the links of the chapter
+type pagedSource struct {
+	catalogSource
+	links []string
+}
+
+func (s *pagedSource) PagesOf(chapter *source.Chapter) ([]*source.Page, error) {
+	return lo.Map(s.links, func(link string, i int) *source.Page {
+		return &source.Page{URL: link, Index: i + 1, Extension: ".jpg", Chapter: chapter}
+	}), nil
+}
+
+func TestPagesChanged(t *testing.T) {
+	Convey("Given a chapter downloaded from a CDN that rotates the links", t, func() {
+		contents := map[string]string{"/1.jpg": "first", "/2.jpg": "second"}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, contents[r.URL.Path])
+		}))
+		defer server.Close()
+
+		src := &pagedSource{catalogSource: catalogSource{id: "cdn"}, links: []string{server.URL + "/1.jpg?t=a", server.URL + "/2.jpg?t=a"}}
+		chapter := &source.Chapter{Name: "Chapter 1", Index: 1, Manga: &source.Manga{Name: "Blame!", Source: src}}
+
+		pages := lo.Must(src.PagesOf(chapter))
+		for _, page := range pages {
+			lo.Must0(page.Download())
+		}
+
+		fingerprint := manifest.Fingerprint(pages)
+
+		Convey("When the links change but the pages don't", func() {
+			src.links = []string{server.URL + "/1.jpg?t=b", server.URL + "/2.jpg?t=b"}
+
+			Convey("Then the chapter should not be re-uploaded", func() {
+				So(lo.Must(pagesChanged(chapter, src, fingerprint)), ShouldBeFalse)
+			})
+		})
+
+		Convey("When a page is fixed", func() {
+			contents["/2.jpg"] = "second, fixed"
+
+			Convey("Then the chapter should be re-uploaded", func() {
+				So(lo.Must(pagesChanged(chapter, src, fingerprint)), ShouldBeTrue)
+			})
+		})
+	})
+}
@@ -0,0 +1,157 @@
+// Package support collects the diagnostics of mangal into a support bundle to attach to the bug reports
+package support
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/redact"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// bundleLogs is the number of the newest files of the logs directory put into the bundle
+	bundleLogs = 10
+	// maxLogSize is the size of the end of the log file kept in the bundle
+	maxLogSize = 1 << 20
+)
+
+// Bundle writes the zip with the diagnostics (see Diagnose), the config without the secrets,
+// the sources with their versions, and the newest logs and crash reports.
+// Home directory is replaced with ~ everywhere, see redact. The checks of the diagnostics are returned
+func Bundle(path string) (checks []Check, err error) {
+	file, err := filesystem.Api().Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	archive := zip.NewWriter(file)
+
+	config, err := json.MarshalIndent(redact.Config(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	checks = Diagnose()
+	doctor := lo.Map(checks, func(c Check, _ int) string {
+		return c.String()
+	})
+
+	for _, entry := range []struct {
+		name     string
+		contents []byte
+	}{
+		{"doctor.txt", []byte(strings.Join(doctor, "\n") + "\n")},
+		{"config.json", config},
+		{"sources.txt", []byte(sources())},
+	} {
+		if err = add(archive, entry.name, entry.contents); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = addLogs(archive); err != nil {
+		return nil, err
+	}
+
+	return checks, archive.Close()
+}
+
+func add(archive *zip.Writer, name string, contents []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(contents)
+	return err
+}
+
+// sources lists the sources, the builtin ones have the version of mangal,
+// the custom ones have the version of their api and the checksum
+func sources() string {
+	var b strings.Builder
+
+	for _, p := range provider.Builtins() {
+		b.WriteString(fmt.Sprintf("%s\tbuiltin\t%s\n", p.Name, constant.Version))
+	}
+
+	for _, p := range provider.Customs() {
+		path := filepath.Join(where.Sources(), p.Name+provider.CustomProviderExtension)
+		contents, err := filesystem.Api().ReadFile(path)
+		if err != nil {
+			b.WriteString(fmt.Sprintf("%s\tcustom\t%s\n", p.Name, redact.Home(err.Error())))
+			continue
+		}
+
+		sum := sha256.Sum256(contents)
+		b.WriteString(fmt.Sprintf("%s\tcustom\tsha256:%s\n", p.Name, hex.EncodeToString(sum[:])[:12]))
+	}
+
+	return b.String()
+}
+
+// bundled reports whether the file of the logs directory is a log or a crash report, see crash.Save.
+// Anything else there, e.g. the recorded sessions, is left out
+func bundled(name string) bool {
+	if filepath.Ext(name) == ".log" {
+		return true
+	}
+
+	matched, _ := filepath.Match("crash-*.json", name)
+	return matched
+}
+
+// addLogs adds the newest logs and crash reports, the larger ones are cut to their ends.
+// The credentials of the URLs and the home directory are redacted from them
+func addLogs(archive *zip.Writer) error {
+	files, err := filesystem.Api().ReadDir(where.Logs())
+	if err != nil {
+		return err
+	}
+
+	files = lo.Filter(files, func(f os.FileInfo, _ int) bool {
+		return f.Mode().IsRegular() && bundled(f.Name())
+	})
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+
+	if len(files) > bundleLogs {
+		files = files[:bundleLogs]
+	}
+
+	for _, f := range files {
+		contents, err := filesystem.Api().ReadFile(filepath.Join(where.Logs(), f.Name()))
+		if err != nil {
+			return err
+		}
+
+		if len(contents) > maxLogSize {
+			contents = contents[len(contents)-maxLogSize:]
+		}
+
+		if err = add(archive, "logs/"+f.Name(), []byte(redact.Home(redact.URLs(string(contents))))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
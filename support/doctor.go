@@ -0,0 +1,132 @@
+package support
+
+import (
+	"fmt"
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/converter"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/provider/custom"
+	"github.com/metafates/mangal/redact"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/where"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Check is a diagnostic of the environment of mangal
+type Check struct {
+	Name    string
+	OK      bool
+	Details string
+}
+
+func (c Check) String() string {
+	status := "ok"
+	if !c.OK {
+		status = "fail"
+	}
+
+	return fmt.Sprintf("[%s] %s: %s", status, c.Name, c.Details)
+}
+
+// Diagnose checks the paths, the formats, the sources and the terminal, the failed checks tell what is wrong
+func Diagnose() []Check {
+	checks := []Check{
+		{
+			Name:    "version",
+			OK:      true,
+			Details: fmt.Sprintf("mangal %s (%s) %s/%s %s", constant.Version, constant.Revision, runtime.GOOS, runtime.GOARCH, runtime.Version()),
+		},
+		checkConfig(),
+		checkDownloads(),
+		checkLogs(),
+		checkFormats(),
+	}
+
+	checks = append(checks, checkSources()...)
+
+	return append(checks, Check{
+		Name: "terminal",
+		OK:   true,
+		Details: fmt.Sprintf(
+			"TERM=%q, locale %s, icons %s, appearance %s, headless %t",
+			os.Getenv("TERM"),
+			i18n.Locale(),
+			icon.Variant(),
+			viper.GetString(key.CliAppearance),
+			viper.GetBool(key.CliHeadless),
+		),
+	})
+}
+
+func checkConfig() Check {
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return Check{Name: "config", OK: true, Details: "no config file, the defaults are used"}
+	}
+
+	if _, err := os.Stat(file); err != nil {
+		return Check{Name: "config", OK: false, Details: redact.Home(err.Error())}
+	}
+
+	return Check{Name: "config", OK: true, Details: redact.Home(file)}
+}
+
+func checkDownloads() Check {
+	path, fallback, err := where.CheckDownloads()
+	if err != nil {
+		return Check{Name: "downloads", OK: false, Details: redact.Home(err.Error())}
+	}
+
+	details := redact.Home(path)
+	if fallback {
+		details += ", the fallback path is used"
+	}
+
+	return Check{Name: "downloads", OK: true, Details: details}
+}
+
+func checkLogs() Check {
+	if err := where.Writable(where.Logs()); err != nil {
+		return Check{Name: "logs", OK: false, Details: redact.Home(err.Error())}
+	}
+
+	return Check{
+		Name:    "logs",
+		OK:      true,
+		Details: fmt.Sprintf("%s, written %t, level %s", redact.Home(where.Logs()), viper.GetBool(key.LogsWrite), viper.GetString(key.LogsLevel)),
+	}
+}
+
+func checkFormats() Check {
+	formats := source.Formats()
+	if _, err := converter.GetAll(formats); err != nil {
+		return Check{Name: "formats", OK: false, Details: err.Error()}
+	}
+
+	return Check{Name: "formats", OK: true, Details: strings.Join(formats, ", ")}
+}
+
+// checkSources checks that the custom sources can be loaded by this mangal
+func checkSources() []Check {
+	var checks []Check
+
+	for _, p := range provider.Customs() {
+		path := filepath.Join(where.Sources(), p.Name+provider.CustomProviderExtension)
+		api, err := custom.APIVersion(path)
+		if err != nil {
+			checks = append(checks, Check{Name: "source " + p.Name, OK: false, Details: err.Error()})
+			continue
+		}
+
+		checks = append(checks, Check{Name: "source " + p.Name, OK: true, Details: fmt.Sprintf("api v%d", api)})
+	}
+
+	return checks
+}
@@ -0,0 +1,135 @@
+// Package translator pipes pages through an external manga translation tool,
+// such as manga-image-translator web API.
+// It is experimental and may change without notice.
+package translator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/network"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"github.com/spf13/viper"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// Suffix is appended to the names of the translated chapters,
+// so that they are saved alongside the originals
+const Suffix = " (translated)"
+
+// extensions of the images by their content types.
+// mime.ExtensionsByType is not used, since it depends on the system tables, e.g. gives ".jfif" for jpeg on some of them
+var extensions = map[string]string{
+	"image/avif": ".avif",
+	"image/bmp":  ".bmp",
+	"image/gif":  ".gif",
+	"image/heic": ".heic",
+	"image/jpeg": ".jpg",
+	"image/jxl":  ".jxl",
+	"image/png":  ".png",
+	"image/tiff": ".tiff",
+	"image/webp": ".webp",
+}
+
+// Chapter returns a copy of the given chapter with translated pages.
+// Pages of the given chapter must be downloaded and are left untouched.
+func Chapter(chapter *source.Chapter, progress func(string)) (*source.Chapter, error) {
+	translated := *chapter
+	translated.Name += Suffix
+	translated.Pages = make([]*source.Page, len(chapter.Pages))
+
+	for i, page := range chapter.Pages {
+		progress(i18n.T("Translating page %d of %d", i+1, len(chapter.Pages)))
+
+		t, err := Page(page)
+		if err != nil {
+			return nil, fmt.Errorf("translating page #%d: %w", page.Index, err)
+		}
+
+		t.Chapter = &translated
+		translated.Pages[i] = t
+	}
+
+	return &translated, nil
+}
+
+// Page sends the page image to the translator endpoint and returns a new page with the translated image.
+func Page(page *source.Page) (*source.Page, error) {
+	if page.Contents == nil {
+		return nil, errors.New("page not downloaded")
+	}
+
+	var (
+		body   bytes.Buffer
+		writer = multipart.NewWriter(&body)
+	)
+
+	part, err := writer.CreateFormFile("file", page.Filename())
+	if err != nil {
+		return nil, err
+	}
+
+	// do not consume the original contents, they might be saved too
+	if _, err = part.Write(page.Contents.Bytes()); err != nil {
+		return nil, err
+	}
+
+	if err = writer.WriteField("tgt_lang", viper.GetString(key.TranslatorLanguage)); err != nil {
+		return nil, err
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, viper.GetString(key.TranslatorEndpoint), &body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	log.Tracef("Translating page #%d", page.Index)
+	resp, err := network.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.Ignore(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("http error: " + resp.Status)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("translator returned %q instead of an image", contentType)
+	}
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	extension := page.Extension
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && extensions[mediaType] != "" {
+		extension = extensions[mediaType]
+	}
+
+	return &source.Page{
+		URL:       page.URL,
+		Index:     page.Index,
+		Extension: extension,
+		Size:      uint64(len(buf)),
+		Contents:  bytes.NewBuffer(buf),
+		Chapter:   page.Chapter,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package translator
+
+import (
+	"bytes"
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+func TestChapter(t *testing.T) {
+	Convey("Given a translation server", t, func() {
+		contentType := "image/png"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			contents := lo.Must(io.ReadAll(file))
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write(append([]byte("translated "), contents...))
+		}))
+		defer server.Close()
+
+		viper.Set(key.TranslatorEndpoint, server.URL)
+
+		chapter := &source.Chapter{Name: "chapter"}
+		chapter.Pages = []*source.Page{{
+			Index:     1,
+			Extension: ".jpg",
+			Contents:  bytes.NewBufferString("page"),
+			Chapter:   chapter,
+		}}
+
+		Convey("When translating a chapter", func() {
+			translated, err := Chapter(chapter, func(string) {})
+			Convey("Then the error should be nil", func() {
+				So(err, ShouldBeNil)
+
+				Convey("And the translated chapter should be a separate one", func() {
+					So(translated.Name, ShouldEqual, "chapter"+Suffix)
+					So(translated.Pages[0].Chapter, ShouldEqual, translated)
+					So(translated.Pages[0].Extension, ShouldEqual, ".png")
+					So(translated.Pages[0].Contents.String(), ShouldEqual, "translated page")
+				})
+
+				Convey("And the original pages should be untouched", func() {
+					So(chapter.Pages[0].Contents.String(), ShouldEqual, "page")
+				})
+			})
+		})
+
+		Convey("When the translator returns jpeg with the parameters", func() {
+			contentType = "image/JPEG; q=0.9"
+			translated, err := Chapter(chapter, func(string) {})
+
+			Convey("Then the extension should not depend on the system", func() {
+				So(err, ShouldBeNil)
+				So(translated.Pages[0].Extension, ShouldEqual, ".jpg")
+			})
+		})
+	})
+}
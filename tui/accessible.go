@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/source"
+	"strconv"
+	"time"
+)
+
+// In the accessible mode the TUI suits the screen readers:
+// nothing is animated, the layouts have no box-drawing characters,
+// and every change of the screen is announced with a line of plain text printed above the TUI,
+// so that the reader speaks them in order
+
+// staticSpinner replaces the animated spinners, the progress status is shown instead
+var staticSpinner = spinner.Spinner{
+	Frames: []string{""},
+	FPS:    time.Hour,
+}
+
+// accessibleBorder marks the selected item of the list with a character that the readers speak
+var accessibleBorder = lipgloss.Border{Left: ">"}
+
+// accessibleSeparator separates the keys in the help, read as a pause
+const accessibleSeparator = ", "
+
+// makeListAccessible drops the animations and the drawings of the list
+func makeListAccessible(l *list.Model) {
+	l.SetSpinner(staticSpinner)
+	l.Paginator.Type = paginator.Arabic
+	l.Help.ShortSeparator = accessibleSeparator
+}
+
+// progressView is the progress bar, or the percentage in the accessible mode
+func (b *statefulBubble) progressView() string {
+	if b.accessible {
+		return i18n.T("%d%% downloaded", int(b.progressC.Percent()*100))
+	}
+
+	return b.progressC.View()
+}
+
+// announcement of the screen the bubble is at, e.g. "Chapters: 12 chapters"
+func (b *statefulBubble) announcement() string {
+	count := func(l *list.Model, singular, plural string) string {
+		return l.Title + ": " + i18n.N(len(l.Items()), singular, plural)
+	}
+
+	switch b.state {
+	case scrapersInstallState:
+		return count(&b.scrapersInstallC, "scraper", "scrapers")
+	case loadingState:
+		return i18n.T("Loading")
+	case historyState:
+		return count(&b.historyC, "chapter", "chapters")
+	case sourcesState:
+		return count(&b.sourcesC, "source", "sources")
+	case searchState:
+		return i18n.T("Search Manga") + ": " + i18n.T("type the query and press %s", b.keymap.confirm.Help().Key)
+	case mangasState:
+		return count(&b.mangasC, "manga", "mangas")
+	case chaptersState:
+		return count(&b.chaptersC, "chapter", "chapters")
+	case anilistSelectState:
+		return count(&b.anilistC, "manga", "mangas")
+	case confirmState:
+		return i18n.T("Confirm") + ": " + i18n.T("Download %s?", i18n.N(len(b.selectedChapters), "chapter", "chapters"))
+	case readState, downloadState:
+		return b.chapterAnnouncement(b.currentDownloadingChapter)
+	case downloadDoneState:
+		return i18n.T("Finish") + ": " + i18n.T(
+			"%s downloaded, %s failed",
+			i18n.N(len(b.succededChapters), "chapter", "chapters"),
+			strconv.Itoa(len(b.failedChapters)),
+		)
+	case errorState:
+		return i18n.T("Error") + ": " + b.lastError.Error()
+	case remoteLibraryState:
+		return count(&b.remoteLibraryC, "manga", "mangas")
+	case remoteChaptersState:
+		return count(&b.remoteChaptersC, "chapter", "chapters")
+	case remoteQueueState:
+		return count(&b.remoteQueueC, "download", "downloads")
+	case clipboardState:
+		return i18n.T("Clipboard") + ": " + b.clipboard.url
+	case federatedLibraryState:
+		return count(&b.federatedMangasC, "manga", "mangas")
+	case federatedChaptersState:
+		return count(&b.federatedChaptersC, "chapter", "chapters")
+	}
+
+	return ""
+}
+
+func (b *statefulBubble) chapterAnnouncement(chapter *source.Chapter) string {
+	if chapter == nil {
+		return i18n.T("Downloading")
+	}
+
+	return i18n.T("Downloading %s", chapter.Name)
+}
+
+// announce prints the announcement of the screen if the state or the downloaded chapter have changed since
+func (b *statefulBubble) announce(previous state, chapter *source.Chapter) tea.Cmd {
+	if !b.accessible {
+		return nil
+	}
+
+	if b.state != previous {
+		return tea.Println(b.announcement())
+	}
+
+	if (b.state == readState || b.state == downloadState) && b.currentDownloadingChapter != chapter {
+		return tea.Println(b.chapterAnnouncement(b.currentDownloadingChapter))
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// When the terminal can't show Unicode, see icon.ASCII,
+// the braille spinners, the block progress bar and the box-drawing borders are replaced with ASCII
+
+// asciiBorder marks the selected item of the list
+var asciiBorder = lipgloss.Border{Left: "|"}
+
+// asciiSeparator separates the keys in the help instead of the bullet
+const asciiSeparator = " - "
+
+// asciiProgressFull and asciiProgressEmpty fill the progress bar
+const (
+	asciiProgressFull  = '#'
+	asciiProgressEmpty = '-'
+)
+
+// makeListASCII replaces the dots of the pagination and the bullets of the help of the list
+func makeListASCII(l *list.Model) {
+	l.Paginator.Type = paginator.Arabic
+	l.Help.ShortSeparator = asciiSeparator
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -11,10 +12,15 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/metafates/mangal/anilist"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/federation"
 	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
 	"github.com/metafates/mangal/installer"
 	key2 "github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/remote"
+	"github.com/metafates/mangal/server"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/util"
@@ -22,7 +28,6 @@ import (
 	"github.com/samber/mo"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
-	"strings"
 	"time"
 )
 
@@ -34,26 +39,31 @@ type statefulBubble struct {
 	keymap *statefulKeymap
 
 	// components
-	spinnerC         spinner.Model
-	inputC           textinput.Model
-	scrapersInstallC list.Model
-	historyC         list.Model
-	sourcesC         list.Model
-	mangasC          list.Model
-	chaptersC        list.Model
-	anilistC         list.Model
-	progressC        progress.Model
-	helpC            help.Model
+	spinnerC           spinner.Model
+	inputC             textinput.Model
+	scrapersInstallC   list.Model
+	historyC           list.Model
+	sourcesC           list.Model
+	mangasC            list.Model
+	chaptersC          list.Model
+	anilistC           list.Model
+	remoteLibraryC     list.Model
+	remoteChaptersC    list.Model
+	remoteQueueC       list.Model
+	federatedMangasC   list.Model
+	federatedChaptersC list.Model
+	progressC          progress.Model
+	helpC              help.Model
 
 	selectedProviders map[*provider.Provider]struct{}
 	selectedSources   []source.Source
 	selectedManga     *source.Manga
 	selectedChapters  map[*source.Chapter]struct{} // mathematical set
+	confirmation      *confirmation
 
 	scrapersLoadedChannel       chan []*installer.Scraper
 	scraperInstalledChannel     chan *installer.Scraper
 	sourcesLoadedChannel        chan []source.Source
-	foundMangasChannel          chan []*source.Manga
 	foundChaptersChannel        chan []*source.Chapter
 	fetchedAnilistMangasChannel chan []*anilist.Manga
 	closestAnilistMangaChannel  chan *anilist.Manga
@@ -75,6 +85,35 @@ type statefulBubble struct {
 	succededChapters []*source.Chapter
 
 	searchSuggestion mo.Option[string]
+
+	// searchID identifies the latest search, results of the previous ones are discarded
+	searchID     int
+	cancelSearch context.CancelFunc
+
+	// searchResults are the results of the last search among the sources.
+	// While typing a new query, they are filtered instead of searching again
+	searchResults []*source.Manga
+	searchMatches []*source.Manga
+
+	// remote is the server to control instead of the local mangal
+	remote      *remote.Client
+	remoteManga *server.LibraryManga
+	remoteTick  int
+
+	// federation is the local library merged with the libraries of the servers, shown instead of the sources
+	federation     *federation.Library
+	federatedManga *federation.Manga
+	pulling        bool
+
+	// clipboard is the link found in the clipboard on startup, until its manga is searched
+	clipboard *clipboardLink
+
+	// accessible mode for the screen readers, see announce
+	accessible bool
+
+	logPane logPane
+	// terminalWidth and terminalHeight are the size of the terminal, the screen is smaller by the log pane
+	terminalWidth, terminalHeight int
 }
 
 func (b *statefulBubble) raiseError(err error) {
@@ -119,6 +158,9 @@ func (b *statefulBubble) previousState() {
 }
 
 func (b *statefulBubble) resize(width, height int) {
+	b.terminalWidth, b.terminalHeight = width, height
+	height -= b.logPane.height()
+
 	x, y := paddingStyle.GetFrameSize()
 	xx, yy := listExtraPaddingStyle.GetFrameSize()
 
@@ -146,6 +188,21 @@ func (b *statefulBubble) resize(width, height int) {
 	b.anilistC.SetSize(listWidth, listHeight)
 	b.anilistC.Help.Width = listWidth
 
+	b.remoteLibraryC.SetSize(listWidth, listHeight)
+	b.remoteLibraryC.Help.Width = listWidth
+
+	b.remoteChaptersC.SetSize(listWidth, listHeight)
+	b.remoteChaptersC.Help.Width = listWidth
+
+	b.remoteQueueC.SetSize(listWidth, listHeight)
+	b.remoteQueueC.Help.Width = listWidth
+
+	b.federatedMangasC.SetSize(listWidth, listHeight)
+	b.federatedMangasC.Help.Width = listWidth
+
+	b.federatedChaptersC.SetSize(listWidth, listHeight)
+	b.federatedChaptersC.Help.Width = listWidth
+
 	b.progressC.Width = listWidth
 
 	b.width = styledWidth
@@ -174,7 +231,6 @@ func newBubble() *statefulBubble {
 		scrapersLoadedChannel:       make(chan []*installer.Scraper),
 		scraperInstalledChannel:     make(chan *installer.Scraper),
 		sourcesLoadedChannel:        make(chan []source.Source),
-		foundMangasChannel:          make(chan []*source.Manga),
 		foundChaptersChannel:        make(chan []*source.Chapter),
 		fetchedAnilistMangasChannel: make(chan []*anilist.Manga),
 		closestAnilistMangaChannel:  make(chan *anilist.Manga),
@@ -188,6 +244,8 @@ func newBubble() *statefulBubble {
 
 		failedChapters:   make([]*source.Chapter, 0),
 		succededChapters: make([]*source.Chapter, 0),
+
+		accessible: viper.GetBool(key2.TUIAccessible),
 	}
 
 	type listOptions struct {
@@ -200,10 +258,16 @@ func newBubble() *statefulBubble {
 		delegate.ShowDescription = description
 		delegate.Styles.SelectedTitle = lipgloss.NewStyle().
 			Border(lipgloss.ThickBorder(), false, false, false, true).
-			BorderForeground(lipgloss.Color("5")).
-			Foreground(lipgloss.Color("5")).
+			BorderForeground(color.Purple).
+			Foreground(color.Purple).
 			Padding(0, 0, 0, 1)
-		delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Copy().Foreground(lipgloss.Color("7"))
+		delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Copy().Foreground(color.White)
+
+		if bubble.accessible {
+			delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Border(accessibleBorder, false, false, false, true)
+		} else if icon.ASCII() {
+			delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Border(asciiBorder, false, false, false, true)
+		}
 
 		delegate.Styles.SelectedDesc = delegate.Styles.SelectedTitle.Copy()
 
@@ -214,11 +278,20 @@ func newBubble() *statefulBubble {
 			return bubble.keymap.FullHelp()[0]
 		}
 		listC.Title = title
+		listC.Filter = filterFunc()
 		listC.Styles.NoItems = paddingStyle
 		if titleStyle, ok := options.TitleStyle.Get(); ok {
 			listC.Styles.Title = titleStyle
 		}
 
+		if icon.ASCII() {
+			makeListASCII(&listC)
+		}
+
+		if bubble.accessible {
+			makeListAccessible(&listC)
+		}
+
 		//listC.StatusMessageLifetime = time.Second * 5
 		listC.StatusMessageLifetime = time.Hour * 999 // forever
 
@@ -229,53 +302,104 @@ func newBubble() *statefulBubble {
 
 	bubble.spinnerC = spinner.New()
 	bubble.spinnerC.Spinner = spinner.Dot
-	bubble.spinnerC.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("4"))
+	bubble.spinnerC.Style = lipgloss.NewStyle().Foreground(color.Blue)
+	if icon.ASCII() {
+		bubble.spinnerC.Spinner = spinner.Line
+		bubble.helpC.ShortSeparator = asciiSeparator
+	}
+
+	if bubble.accessible {
+		bubble.spinnerC.Spinner = staticSpinner
+		bubble.helpC.ShortSeparator = accessibleSeparator
+	}
 
 	bubble.inputC = textinput.New()
-	bubble.inputC.Placeholder = "Search"
+	bubble.inputC.Placeholder = i18n.T("Search")
 	bubble.inputC.CharLimit = 60
 	bubble.inputC.Prompt = viper.GetString(key2.TUISearchPromptString)
+	if bubble.accessible {
+		bubble.inputC.SetCursorMode(textinput.CursorStatic)
+	}
 
 	bubble.progressC = progress.New(progress.WithDefaultGradient())
+	if icon.ASCII() {
+		bubble.progressC.Full = asciiProgressFull
+		bubble.progressC.Empty = asciiProgressEmpty
+	}
 
-	bubble.scrapersInstallC = makeList("Install Scrapers", true, &listOptions{
+	bubble.scrapersInstallC = makeList(i18n.T("Install Scrapers"), true, &listOptions{
 		TitleStyle: mo.Some(
-			style.NewColored("#212529", "#ced4da").Padding(0, 1),
+			style.NewColored(color.New("#212529"), color.New("#ced4da")).Padding(0, 1),
 		),
 	})
-	bubble.scrapersInstallC.SetStatusBarItemName("scraper", "scrapers")
+	bubble.scrapersInstallC.SetStatusBarItemName(i18n.Words("scraper", "scrapers"))
 
-	bubble.historyC = makeList("History", true, &listOptions{})
-	bubble.sourcesC.SetStatusBarItemName("chapter", "chapters")
+	bubble.historyC = makeList(i18n.T("History"), true, &listOptions{})
+	bubble.sourcesC.SetStatusBarItemName(i18n.Words("chapter", "chapters"))
 
-	bubble.sourcesC = makeList("Select Source", true, &listOptions{
+	bubble.sourcesC = makeList(i18n.T("Select Source"), true, &listOptions{
 		TitleStyle: mo.Some(
-			style.NewColored("#fefae0", "#bc6c25").Padding(0, 1),
+			style.NewColored(color.New("#fefae0"), color.New("#bc6c25")).Padding(0, 1),
 		),
 	})
-	bubble.sourcesC.SetStatusBarItemName("source", "sources")
+	bubble.sourcesC.SetStatusBarItemName(i18n.Words("source", "sources"))
 
 	showURLs := viper.GetBool(key2.TUIShowURLs)
-	bubble.mangasC = makeList("Mangas", showURLs, &listOptions{
+	bubble.mangasC = makeList(i18n.T("Mangas"), showURLs, &listOptions{
+		TitleStyle: mo.Some(
+			style.NewColored(color.New("#f2e8cf"), color.New("#386641")).Padding(0, 1),
+		),
+	})
+	bubble.mangasC.SetStatusBarItemName(i18n.Words("manga", "mangas"))
+
+	bubble.chaptersC = makeList(i18n.T("Chapters"), showURLs, &listOptions{
+		TitleStyle: mo.Some(
+			style.NewColored(color.New("#000814"), color.Orange).Padding(0, 1),
+		),
+	})
+	bubble.chaptersC.SetStatusBarItemName(i18n.Words("chapter", "chapters"))
+
+	bubble.anilistC = makeList(i18n.T("Anilist Mangas"), showURLs, &listOptions{
+		TitleStyle: mo.Some(
+			style.NewColored(color.New("#bcbedc"), color.New("#2b2d42")).Padding(0, 1),
+		),
+	})
+	bubble.anilistC.SetStatusBarItemName(i18n.Words("manga", "mangas"))
+
+	bubble.remoteLibraryC = makeList(i18n.T("Library"), true, &listOptions{
+		TitleStyle: mo.Some(
+			style.NewColored(color.New("#f2e8cf"), color.New("#386641")).Padding(0, 1),
+		),
+	})
+	bubble.remoteLibraryC.SetStatusBarItemName(i18n.Words("manga", "mangas"))
+
+	bubble.remoteChaptersC = makeList(i18n.T("Chapters"), showURLs, &listOptions{
+		TitleStyle: mo.Some(
+			style.NewColored(color.New("#000814"), color.Orange).Padding(0, 1),
+		),
+	})
+	bubble.remoteChaptersC.SetStatusBarItemName(i18n.Words("chapter", "chapters"))
+
+	bubble.remoteQueueC = makeList(i18n.T("Downloads"), true, &listOptions{
 		TitleStyle: mo.Some(
-			style.NewColored("#f2e8cf", "#386641").Padding(0, 1),
+			style.NewColored(color.New("#fefae0"), color.New("#bc6c25")).Padding(0, 1),
 		),
 	})
-	bubble.mangasC.SetStatusBarItemName("manga", "mangas")
+	bubble.remoteQueueC.SetStatusBarItemName(i18n.Words("download", "downloads"))
 
-	bubble.chaptersC = makeList("Chapters", showURLs, &listOptions{
+	bubble.federatedMangasC = makeList(i18n.T("Library"), true, &listOptions{
 		TitleStyle: mo.Some(
-			style.NewColored("#000814", color.Orange).Padding(0, 1),
+			style.NewColored(color.New("#f2e8cf"), color.New("#386641")).Padding(0, 1),
 		),
 	})
-	bubble.chaptersC.SetStatusBarItemName("chapter", "chapters")
+	bubble.federatedMangasC.SetStatusBarItemName(i18n.Words("manga", "mangas"))
 
-	bubble.anilistC = makeList("Anilist Mangas", showURLs, &listOptions{
+	bubble.federatedChaptersC = makeList(i18n.T("Chapters"), true, &listOptions{
 		TitleStyle: mo.Some(
-			style.NewColored("#bcbedc", "#2b2d42").Padding(0, 1),
+			style.NewColored(color.New("#000814"), color.Orange).Padding(0, 1),
 		),
 	})
-	bubble.anilistC.SetStatusBarItemName("manga", "mangas")
+	bubble.federatedChaptersC.SetStatusBarItemName(i18n.Words("chapter", "chapters"))
 
 	if w, h, err := util.TerminalSize(); err == nil {
 		bubble.resize(w, h)
@@ -299,7 +423,7 @@ func (b *statefulBubble) loadProviders() tea.Cmd {
 	slices.SortFunc(items, func(a, b list.Item) bool {
 		// temporary workaround for placing mangadex second because it is not stable for now
 		// but, you know, there is nothing more permanent than a temporary solution
-		return strings.Compare(a.FilterValue(), b.FilterValue()) > 0
+		return util.NaturalLess(b.FilterValue(), a.FilterValue())
 	})
 
 	var customItems []list.Item
@@ -309,7 +433,7 @@ func (b *statefulBubble) loadProviders() tea.Cmd {
 		})
 	}
 	slices.SortFunc(customItems, func(a, b list.Item) bool {
-		return strings.Compare(a.FilterValue(), b.FilterValue()) < 0
+		return util.NaturalLess(a.FilterValue(), b.FilterValue())
 	})
 
 	// built-in providers should come first
@@ -325,9 +449,9 @@ func (b *statefulBubble) loadHistory() (tea.Cmd, error) {
 	chapters := lo.Values(saved)
 	slices.SortFunc(chapters, func(a, b *history.SavedChapter) bool {
 		if a.MangaName == b.MangaName {
-			return a.Name < b.Name
+			return util.NaturalLess(a.Name, b.Name)
 		}
-		return a.MangaName < b.MangaName
+		return util.NaturalLess(a.MangaName, b.MangaName)
 	})
 
 	var items []list.Item
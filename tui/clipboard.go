@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"strings"
+)
+
+// clipboardLink is the link of a provider found in the clipboard on startup
+type clipboardLink struct {
+	url      string
+	provider *provider.Provider
+	// query to search the manga of the link with, guessed from its path
+	query string
+}
+
+// clipboardChapterMsg is the manga of the chapter link from the clipboard with its chapters, no manga if it was not found
+type clipboardChapterMsg struct {
+	link     string
+	manga    *source.Manga
+	chapters []*source.Chapter
+}
+
+// detectClipboardLink returns the link of a builtin provider if the clipboard has one
+func detectClipboardLink() (*clipboardLink, bool) {
+	if clipboard.Unsupported {
+		return nil, false
+	}
+
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		log.Warn(err)
+		return nil, false
+	}
+
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "http://") && !strings.HasPrefix(text, "https://") {
+		return nil, false
+	}
+
+	p, ok := provider.ByURL(text)
+	if !ok {
+		return nil, false
+	}
+
+	query := provider.QueryOf(text)
+	if query == "" {
+		return nil, false
+	}
+
+	return &clipboardLink{url: text, provider: p, query: query}, true
+}
+
+func (b *statefulBubble) viewClipboard() string {
+	field := func(name, value string) string {
+		return style.Truncate(b.width)(fmt.Sprintf("%s %s", style.Faint(util.PadRight(name, 12)), value))
+	}
+
+	return b.renderLines(true, []string{
+		style.Title(i18n.T("Clipboard")),
+		"",
+		fmt.Sprintf("%s %s", icon.Get(icon.Question), i18n.T("Open the link from the clipboard?")),
+		"",
+		field(i18n.T("Source"), style.Fg(color.Purple)(b.clipboard.provider.Name)),
+		field(i18n.T("Link"), b.clipboard.url),
+		field(i18n.T("Search"), style.Fg(color.Orange)(b.clipboard.query)),
+	})
+}
+
+func (b *statefulBubble) updateClipboard(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, b.keymap.quit):
+			return b, tea.Quit
+		case key.Matches(msg, b.keymap.confirm):
+			// searched as if the source was selected from the list
+			b.previousState()
+			b.newState(loadingState)
+			return b, tea.Batch(b.startLoading(), b.loadSources([]*provider.Provider{b.clipboard.provider}), b.waitForSourcesLoaded())
+		}
+	}
+
+	return b, nil
+}
+
+// findClipboardChapter looks for the chapter link among the chapters of the found mangas,
+// since the link did not match any of the mangas themselves
+func (b *statefulBubble) findClipboardChapter(mangas []*source.Manga, link string) tea.Cmd {
+	return func() tea.Msg {
+		manga, chapters, err := provider.ChapterByLink(mangas, link)
+		if err != nil {
+			log.Warn(err)
+			return clipboardChapterMsg{link: link}
+		}
+
+		return clipboardChapterMsg{link: link, manga: manga, chapters: chapters}
+	}
+}
+
+// onClipboardChapter opens the chapters of the manga with the chapter of the link selected.
+// Nothing is opened if the user left the mangas already
+func (b *statefulBubble) onClipboardChapter(msg clipboardChapterMsg) tea.Cmd {
+	b.stopLoading()
+
+	if b.state != mangasState {
+		return nil
+	}
+
+	if msg.manga == nil {
+		return b.mangasC.NewStatusMessage(i18n.T("The link was not found among the results"))
+	}
+
+	for i, item := range b.mangasC.Items() {
+		if item.(*listItem).internal == msg.manga {
+			b.mangasC.Select(i)
+			break
+		}
+	}
+
+	b.selectedManga = msg.manga
+	b.newState(chaptersState)
+	return tea.Batch(b.chaptersC.SetItems(b.chapterItems(msg.chapters)), b.selectChapterBy(func(chapter *source.Chapter) bool {
+		return provider.SameLink(chapter.URL, msg.link)
+	}))
+}
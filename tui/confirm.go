@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"context"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/spf13/viper"
+	"path/filepath"
+)
+
+// confirmation describes what is going to be downloaded
+type confirmation struct {
+	// id of the confirmation, used to drop pages counted for the previous one
+	id int
+
+	chapters    []*source.Chapter
+	destination string
+
+	// downloaded chapters that will be skipped or redownloaded
+	downloaded int
+	// collisions are the file names shared by several selected chapters
+	collisions map[string]int
+
+	pages, counted int
+	countFailed    bool
+
+	// size is the estimated size of the chapters counted so far, see key.DownloaderEstimateSize
+	size           uint64
+	estimateFailed bool
+}
+
+type pagesCountedMsg struct {
+	id, pages int
+	err       error
+
+	size        uint64
+	estimateErr error
+}
+
+func newConfirmation(id int, chapters []*source.Chapter) *confirmation {
+	c := &confirmation{
+		id:         id,
+		chapters:   chapters,
+		collisions: make(map[string]int),
+	}
+
+	filenames := make(map[string]int)
+	for _, chapter := range chapters {
+		path := chapter.PeekPath()
+		if c.destination == "" {
+			c.destination = filepath.Dir(path)
+		}
+
+		if chapter.IsDownloaded() {
+			c.downloaded++
+		}
+
+		filenames[filepath.Base(path)]++
+	}
+
+	for filename, count := range filenames {
+		if count > 1 {
+			c.collisions[filename] = count
+		}
+	}
+
+	return c
+}
+
+// counting reports whether pages are still being counted
+func (c *confirmation) counting() bool {
+	return !c.countFailed && c.counted < len(c.chapters)
+}
+
+// countPages fetches pages of the next chapter and estimates its size if enabled.
+// Pages that are set already, e.g. of the local chapters, are not fetched.
+// Sources cache fetched pages, so they are not requested again on download
+func (c *confirmation) countPages() tea.Cmd {
+	if !viper.GetBool(key.TUIConfirmCountPages) && !viper.GetBool(key.DownloaderEstimateSize) || !c.counting() {
+		return nil
+	}
+
+	chapter := c.chapters[c.counted]
+	return func() tea.Msg {
+		pages := chapter.Pages
+		if len(pages) == 0 {
+			var err error
+			if pages, err = chapter.Source().PagesOf(chapter); err != nil {
+				log.Warn(err)
+				return pagesCountedMsg{id: c.id, err: err}
+			}
+		}
+
+		msg := pagesCountedMsg{id: c.id, pages: len(pages)}
+		if viper.GetBool(key.DownloaderEstimateSize) {
+			msg.size, msg.estimateErr = chapter.EstimateSize(context.Background())
+			if msg.estimateErr != nil {
+				log.Warn(msg.estimateErr)
+			}
+		}
+
+		return msg
+	}
+}
@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/federation"
+	"github.com/metafates/mangal/i18n"
+	key2 "github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/open"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/metafates/mangal/where"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// federatedPulledMsg is sent when the chapters are pulled to the local library.
+// Paths of the pulled chapters are by chapter, the failed ones are left out
+type federatedPulledMsg struct {
+	manga  *federation.Manga
+	paths  map[*federation.Chapter]string
+	failed []*federation.Chapter
+}
+
+// showFederation lists the merged library, with the servers that could not be reached in the status
+func (b *statefulBubble) showFederation() tea.Cmd {
+	items := make([]list.Item, len(b.federation.Mangas))
+	for i, manga := range b.federation.Mangas {
+		items[i] = &listItem{internal: manga}
+	}
+
+	cmds := []tea.Cmd{b.federatedMangasC.SetItems(items)}
+
+	if len(b.federation.Errors) > 0 {
+		locations := lo.Keys(b.federation.Errors)
+		sort.Strings(locations)
+		status := i18n.T("Could not reach %s", strings.Join(locations, ", "))
+		cmds = append(cmds, b.federatedMangasC.NewStatusMessage(style.Fg(color.Red)(status)))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (b *statefulBubble) pullFederated(manga *federation.Manga, chapters []*federation.Chapter) tea.Cmd {
+	l := b.federation
+	return func() tea.Msg {
+		msg := federatedPulledMsg{manga: manga, paths: make(map[*federation.Chapter]string)}
+		for _, chapter := range chapters {
+			path, err := l.Pull(manga, chapter)
+			if err != nil {
+				msg.failed = append(msg.failed, chapter)
+				continue
+			}
+
+			msg.paths[chapter] = path
+		}
+
+		return msg
+	}
+}
+
+// onFederatedPulled records the pulled chapters as local.
+// It is handled in any state, since the user may leave the chapters before the pull is done
+func (b *statefulBubble) onFederatedPulled(msg federatedPulledMsg) tea.Cmd {
+	b.pulling = false
+
+	// the library is changed here only, the pull itself runs in the background
+	for chapter, path := range msg.paths {
+		msg.manga.Pulled(chapter, path)
+	}
+
+	for _, item := range b.federatedChaptersC.Items() {
+		item.(*listItem).marked = false
+	}
+
+	pulled := i18n.N(len(msg.paths), "chapter", "chapters")
+	if len(msg.failed) == 0 {
+		return b.federatedChaptersC.NewStatusMessage(i18n.T("Pulled %s", pulled))
+	}
+
+	failed := strings.Join(lo.Map(msg.failed, func(chapter *federation.Chapter, _ int) string {
+		return chapter.Name
+	}), ", ")
+	return b.federatedChaptersC.NewStatusMessage(style.Fg(color.Red)(i18n.T("Pulled %s, failed to pull %s", pulled, failed)))
+}
+
+func (b *statefulBubble) updateFederatedLibrary(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case b.federatedMangasC.FilterState() == list.Filtering:
+			break
+		case key.Matches(msg, b.keymap.confirm):
+			if b.federatedMangasC.SelectedItem() == nil {
+				break
+			}
+
+			manga := b.federatedMangasC.SelectedItem().(*listItem).internal.(*federation.Manga)
+			items := make([]list.Item, len(manga.Chapters))
+			for i, chapter := range manga.Chapters {
+				items[i] = &listItem{internal: chapter}
+			}
+
+			b.federatedManga = manga
+			b.federatedChaptersC.Title = util.Truncate(manga.Name, titleWidth)
+			b.newState(federatedChaptersState)
+			return b, b.federatedChaptersC.SetItems(items)
+		}
+	}
+
+	b.federatedMangasC, cmd = b.federatedMangasC.Update(msg)
+	return b, cmd
+}
+
+func (b *statefulBubble) updateFederatedChapters(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	setMarked := func(marked bool) {
+		for _, item := range b.federatedChaptersC.Items() {
+			item.(*listItem).marked = marked
+		}
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case b.federatedChaptersC.FilterState() == list.Filtering:
+			break
+		case key.Matches(msg, b.keymap.openFolder):
+			directory, ok := b.federatedManga.Directories[federation.Local]
+			if !ok {
+				break
+			}
+
+			if err := open.StartWith(filepath.Join(where.Downloads(), directory), viper.GetString(key2.ReaderFolder)); err != nil {
+				b.raiseError(err)
+			}
+		case key.Matches(msg, b.keymap.selectOne):
+			if item := b.federatedChaptersC.SelectedItem(); item != nil {
+				item.(*listItem).toggleMark()
+			}
+		case key.Matches(msg, b.keymap.selectAll):
+			setMarked(true)
+		case key.Matches(msg, b.keymap.clearSelection):
+			setMarked(false)
+		case key.Matches(msg, b.keymap.confirm):
+			if b.pulling {
+				break
+			}
+
+			var chapters []*federation.Chapter
+			for _, item := range b.federatedChaptersC.Items() {
+				if item := item.(*listItem); item.marked {
+					chapters = append(chapters, item.internal.(*federation.Chapter))
+				}
+			}
+
+			// pull the chapter under cursor if nothing is selected
+			if len(chapters) == 0 && b.federatedChaptersC.SelectedItem() != nil {
+				chapters = append(chapters, b.federatedChaptersC.SelectedItem().(*listItem).internal.(*federation.Chapter))
+			}
+
+			chapters = lo.Filter(chapters, func(chapter *federation.Chapter, _ int) bool {
+				return !chapter.IsLocal()
+			})
+
+			if len(chapters) == 0 {
+				return b, b.federatedChaptersC.NewStatusMessage(i18n.T("Nothing to pull"))
+			}
+
+			b.pulling = true
+			return b, tea.Batch(
+				b.federatedChaptersC.NewStatusMessage(i18n.T("Pulling %s", i18n.N(len(chapters), "chapter", "chapters"))),
+				b.pullFederated(b.federatedManga, chapters),
+			)
+		}
+	}
+
+	b.federatedChaptersC, cmd = b.federatedChaptersC.Update(msg)
+	return b, cmd
+}
+
+func (b *statefulBubble) viewFederatedLibrary() string {
+	return listExtraPaddingStyle.Render(b.federatedMangasC.View())
+}
+
+func (b *statefulBubble) viewFederatedChapters() string {
+	return listExtraPaddingStyle.Render(b.federatedChaptersC.View())
+}
+
+// federatedLocations returns the locations joined, with the local one translated
+func federatedLocations(locations []string) string {
+	return strings.Join(lo.Map(locations, func(location string, _ int) string {
+		if location == federation.Local {
+			return i18n.T("local")
+		}
+
+		return location
+	}), ", ")
+}
+
+func federatedChapterDescription(chapter *federation.Chapter) string {
+	description := federatedLocations(chapter.Locations())
+	if chapter.Size > 0 {
+		description = fmt.Sprintf("%s, %s", description, humanize.Bytes(uint64(chapter.Size)))
+	}
+
+	return description
+}
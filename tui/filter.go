@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/source"
+	"github.com/spf13/viper"
+	"strings"
+	"unicode"
+)
+
+// filters are the available list filters by their names
+var filters = map[string]list.FilterFunc{
+	"fuzzy":       list.DefaultFilter,
+	"subsequence": subsequenceFilter,
+	"substring":   substringFilter,
+}
+
+// filterFunc returns the filter set in the config, fuzzy one by default
+func filterFunc() list.FilterFunc {
+	if filter, ok := filters[viper.GetString(key.TUISearchFilter)]; ok {
+		return filter
+	}
+
+	return list.DefaultFilter
+}
+
+// lowerRunes returns the lowercased runes of the string.
+// Runes are lowered one by one, so that their indexes match those of the original string
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+
+	return runes
+}
+
+// subsequenceFilter matches the targets containing all the characters of the term in the same order.
+// Unlike the default filter, matches are not sorted
+func subsequenceFilter(term string, targets []string) []list.Rank {
+	var (
+		ranks []list.Rank
+		runes = lowerRunes(term)
+	)
+
+	for i, target := range targets {
+		var (
+			matched   []int
+			remaining = runes
+		)
+
+		for j, r := range lowerRunes(target) {
+			if len(remaining) == 0 {
+				break
+			}
+
+			if r == remaining[0] {
+				matched = append(matched, j)
+				remaining = remaining[1:]
+			}
+		}
+
+		if len(remaining) == 0 {
+			ranks = append(ranks, list.Rank{Index: i, MatchedIndexes: matched})
+		}
+	}
+
+	return ranks
+}
+
+// substringFilter matches the targets containing the term, case-insensitive
+func substringFilter(term string, targets []string) []list.Rank {
+	var (
+		ranks []list.Rank
+		runes = lowerRunes(term)
+	)
+
+	for i, target := range targets {
+		lowered := lowerRunes(target)
+
+		for start := 0; start+len(runes) <= len(lowered); start++ {
+			if string(lowered[start:start+len(runes)]) != string(runes) {
+				continue
+			}
+
+			matched := make([]int, len(runes))
+			for j := range matched {
+				matched[j] = start + j
+			}
+
+			ranks = append(ranks, list.Rank{Index: i, MatchedIndexes: matched})
+			break
+		}
+	}
+
+	return ranks
+}
+
+// matchMangas filters the mangas by the query with the configured filter
+func matchMangas(query string, mangas []*source.Manga) []*source.Manga {
+	if strings.TrimSpace(query) == "" {
+		return mangas
+	}
+
+	names := make([]string, len(mangas))
+	for i, manga := range mangas {
+		names[i] = manga.Name
+	}
+
+	ranks := filterFunc()(query, names)
+	matched := make([]*source.Manga, len(ranks))
+	for i, rank := range ranks {
+		matched[i] = mangas[rank.Index]
+	}
+
+	return matched
+}
@@ -1,12 +1,15 @@
 package tui
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/metafates/mangal/anilist"
+	"github.com/metafates/mangal/bookmarks"
 	"github.com/metafates/mangal/color"
 	"github.com/metafates/mangal/downloader"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/installer"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/log"
@@ -16,23 +19,22 @@ import (
 	"github.com/metafates/mangal/util"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
-	"strings"
 	"sync"
 )
 
 func (b *statefulBubble) loadScrapers() tea.Cmd {
 	return func() tea.Msg {
-		b.progressStatus = "Loading scrapers"
+		b.progressStatus = i18n.T("Loading scrapers")
 		scrapers, err := installer.Scrapers()
 		if err != nil {
 			log.Error(err)
 			b.errorChannel <- err
 			return nil
 		}
-		b.progressStatus = "Scrapers Loaded"
+		b.progressStatus = i18n.T("Scrapers Loaded")
 
 		slices.SortFunc(scrapers, func(a, b *installer.Scraper) bool {
-			return strings.Compare(a.Name, b.Name) < 0
+			return util.NaturalLess(a.Name, b.Name)
 		})
 
 		var items = make([]list.Item, len(scrapers))
@@ -62,7 +64,7 @@ func (b *statefulBubble) waitForScrapersLoaded() tea.Cmd {
 
 func (b *statefulBubble) installScraper(s *installer.Scraper) tea.Cmd {
 	return func() tea.Msg {
-		b.progressStatus = fmt.Sprintf("Installing %s", s.Name)
+		b.progressStatus = i18n.T("Installing %s", s.Name)
 		err := s.Install()
 		if err != nil {
 			log.Error(err)
@@ -106,7 +108,7 @@ func (b *statefulBubble) loadSources(ps []*provider.Provider) tea.Cmd {
 				}
 
 				log.Info("loading source " + p.ID)
-				b.progressStatus = "Initializing source"
+				b.progressStatus = i18n.T("Initializing source")
 				var s source.Source
 				s, err = p.CreateSource()
 
@@ -141,49 +143,111 @@ func (b *statefulBubble) waitForSourcesLoaded() tea.Cmd {
 	}
 }
 
+// foundMangasMsg is the result of the search
+type foundMangasMsg struct {
+	searchID int
+	mangas   []*source.Manga
+}
+
+// showMangas lists the mangas found
+func (b *statefulBubble) showMangas(mangas []*source.Manga) tea.Cmd {
+	items := make([]list.Item, len(mangas))
+	for i, m := range mangas {
+		items[i] = &listItem{internal: m}
+	}
+
+	b.mangasC.ResetFilter()
+	cmd := b.mangasC.SetItems(items)
+	b.newState(mangasState)
+	return cmd
+}
+
+// chapterItems returns the list items of the chapters, in the order set by the config
+func (b *statefulBubble) chapterItems(chapters []*source.Chapter) []list.Item {
+	items := make([]list.Item, len(chapters))
+	bookmarked := bookmarks.IsBookmarked()
+
+	if viper.GetBool(key.TUIReverseChapters) {
+		for i, c := range chapters {
+			items[len(chapters)-i-1] = &listItem{internal: c, bookmarked: bookmarked(c)}
+		}
+	} else {
+		for i, c := range chapters {
+			items[i] = &listItem{internal: c, bookmarked: bookmarked(c)}
+		}
+	}
+
+	return items
+}
+
+// stopSearch cancels the running search, if any
+func (b *statefulBubble) stopSearch() {
+	if b.cancelSearch != nil {
+		b.cancelSearch()
+		b.cancelSearch = nil
+	}
+}
+
+// searchManga starts a new search, canceling the previous one
 func (b *statefulBubble) searchManga(query string) tea.Cmd {
+	b.stopSearch()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancelSearch = cancel
+	b.searchID++
+	searchID := b.searchID
+
+	sources := b.selectedSources
+	b.progressStatus = i18n.T("Searching among %s", i18n.N(len(sources), "source", "sources"))
+
 	return func() tea.Msg {
 		log.Info("searching for " + query)
-		b.progressStatus = fmt.Sprintf("Searching among %s", util.Quantify(len(b.selectedSources), "source", "sources"))
 
-		var mangas = make([]*source.Manga, 0)
+		var (
+			mangas   = make([]*source.Manga, 0)
+			firstErr error
+			mutex    sync.Mutex
+			wg       sync.WaitGroup
+		)
 
-		wg := sync.WaitGroup{}
-		wg.Add(len(b.selectedSources))
-		for _, s := range b.selectedSources {
+		wg.Add(len(sources))
+		for _, s := range sources {
 			go func(s source.Source) {
 				defer wg.Done()
-				sourceMangas, err := s.Search(query)
+				sourceMangas, err := source.SearchContext(ctx, s, query)
+
+				mutex.Lock()
+				defer mutex.Unlock()
 
 				if err != nil {
-					log.Error(err)
-					b.errorChannel <- err
+					if ctx.Err() == nil {
+						log.Source(s.Name()).Error(err)
+						if firstErr == nil {
+							firstErr = err
+						}
+					}
+
+					return
 				}
 
-				log.Infof("found %s from source %s", util.Quantify(len(sourceMangas), "manga", "mangas"), s.Name())
+				log.Source(s.Name()).Infof("found %s", util.Quantify(len(sourceMangas), "manga", "mangas"))
 				mangas = append(mangas, sourceMangas...)
 			}(s)
 		}
 
 		wg.Wait()
 
-		log.Infof("found %d mangas from %d sources", len(mangas), len(b.selectedSources))
-
-		b.foundMangasChannel <- mangas
-
-		return nil
-	}
-}
+		if ctx.Err() != nil {
+			log.Info("search for " + query + " was canceled")
+			return nil
+		}
 
-func (b *statefulBubble) waitForMangas() tea.Cmd {
-	return func() tea.Msg {
-		select {
-		case found := <-b.foundMangasChannel:
-			return found
-		case err := <-b.errorChannel:
-			b.lastError = err
-			return err
+		if firstErr != nil {
+			return firstErr
 		}
+
+		log.Infof("found %d mangas from %d sources", len(mangas), len(sources))
+		return foundMangasMsg{searchID: searchID, mangas: mangas}
 	}
 }
 
@@ -192,7 +256,7 @@ func (b *statefulBubble) getChapters(manga *source.Manga) tea.Cmd {
 		log.Info("getting chapters of " + manga.Name)
 		chapters, err := manga.Source.ChaptersOf(manga)
 		if err != nil {
-			log.Error(err)
+			log.Source(manga.Source.Name()).Error(err)
 			b.errorChannel <- err
 		} else {
 			log.Infof("found %s", util.Quantify(len(chapters), "chapter", "chapters"))
@@ -252,7 +316,8 @@ func (b *statefulBubble) downloadChapter(chapter *source.Chapter) tea.Cmd {
 		})
 
 		if err != nil {
-			if viper.GetBool(key.DownloaderStopOnError) {
+			// skipped chapters do not stop the download, but can be redownloaded as failed ones
+			if viper.GetBool(key.DownloaderStopOnError) && !errors.Is(err, downloader.ErrSkipped) {
 				b.errorChannel <- err
 			} else {
 				b.failedChapters = append(b.failedChapters, chapter)
@@ -302,7 +367,7 @@ func (b *statefulBubble) waitForAnilistFetchAndSet() tea.Cmd {
 func (b *statefulBubble) fetchAnilist(manga *source.Manga) tea.Cmd {
 	return func() tea.Msg {
 		log.Info("fetching anilist for " + manga.Name)
-		b.progressStatus = fmt.Sprintf("Fetching anilist for %s", style.Fg(color.Purple)(manga.Name))
+		b.progressStatus = i18n.T("Fetching anilist for %s", style.Fg(color.Purple)(manga.Name))
 		mangas, err := anilist.SearchByName(manga.Name)
 		if err != nil {
 			log.Error(err)
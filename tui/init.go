@@ -10,6 +10,32 @@ import (
 )
 
 func (b *statefulBubble) Init() tea.Cmd {
+	cmd := b.init()
+	if b.accessible {
+		return tea.Batch(cmd, tea.Println(b.announcement()))
+	}
+
+	return cmd
+}
+
+func (b *statefulBubble) init() tea.Cmd {
+	if b.federation != nil {
+		return b.showFederation()
+	}
+
+	if b.remote != nil {
+		return b.fetchRemoteLibrary()
+	}
+
+	if b.state == clipboardState {
+		return b.loadProviders()
+	}
+
+	return b.start()
+}
+
+// start loads the default sources to search with, or lists the providers to select them from
+func (b *statefulBubble) start() tea.Cmd {
 	if names := viper.GetStringSlice(key.DownloaderDefaultSources); b.state != historyState && len(names) != 0 {
 		var providers []*provider.Provider
 
@@ -3,18 +3,28 @@ package tui
 import (
 	"fmt"
 	"github.com/metafates/mangal/anilist"
+	"github.com/metafates/mangal/federation"
 	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/icon"
 	"github.com/metafates/mangal/installer"
 	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/server"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
 	"strings"
 )
 
+// titleWidth is the width of the chapter titles and the list titles, the longer ones are truncated,
+// so that the volumes and the icons after the chapter titles are not cut off
+const titleWidth = 50
+
 type listItem struct {
 	internal interface{}
 	marked   bool
+	// bookmarked chapter, see bookmarks.Toggle
+	bookmarked bool
 }
 
 func (t *listItem) toggleMark() {
@@ -29,6 +39,10 @@ func (t *listItem) getMark() string {
 		return icon.Get(icon.Link)
 	case *provider.Provider:
 		return icon.Get(icon.Search)
+	case *server.Chapter:
+		return style.Bold(icon.Get(icon.Mark))
+	case *federation.Chapter:
+		return style.Bold(icon.Get(icon.Mark))
 	default:
 		return ""
 	}
@@ -40,6 +54,11 @@ func (t *listItem) Title() (title string) {
 		var sb = strings.Builder{}
 
 		sb.WriteString(t.FilterValue())
+		if e.Title != "" && !strings.Contains(e.Name, e.Title) {
+			sb.WriteString(" ")
+			sb.WriteString(style.Italic(util.Truncate(e.Title, titleWidth)))
+		}
+
 		if e.Volume != "" {
 			sb.WriteString(" ")
 			sb.WriteString(style.Faint(e.Volume))
@@ -50,7 +69,54 @@ func (t *listItem) Title() (title string) {
 			sb.WriteString(icon.Get(icon.Downloaded))
 		}
 
+		if t.bookmarked {
+			sb.WriteString(" ")
+			sb.WriteString(icon.Get(icon.Bookmark))
+		}
+
 		title = sb.String()
+	case *server.Chapter:
+		var sb = strings.Builder{}
+
+		sb.WriteString(t.FilterValue())
+		if e.Title != "" && !strings.Contains(e.Name, e.Title) {
+			sb.WriteString(" ")
+			sb.WriteString(style.Italic(util.Truncate(e.Title, titleWidth)))
+		}
+
+		if e.Volume != "" {
+			sb.WriteString(" ")
+			sb.WriteString(style.Faint(e.Volume))
+		}
+
+		if e.Downloaded {
+			sb.WriteString(" ")
+			sb.WriteString(icon.Get(icon.Downloaded))
+		}
+
+		title = sb.String()
+	case *federation.Manga:
+		title = t.FilterValue()
+		if missing := e.Missing(); missing > 0 {
+			title = fmt.Sprintf("%s %s", title, style.Faint(i18n.T("%d not local", missing)))
+		}
+	case *federation.Chapter:
+		title = t.FilterValue()
+		if e.IsLocal() {
+			title = fmt.Sprintf("%s %s", title, icon.Get(icon.Downloaded))
+		}
+	case *server.Job:
+		var status icon.Icon
+		switch e.Status {
+		case server.JobDone:
+			status = icon.Success
+		case server.JobFailed:
+			status = icon.Fail
+		default:
+			status = icon.Progress
+		}
+
+		title = fmt.Sprintf("%s %s", icon.Get(status), t.FilterValue())
 	default:
 		title = t.FilterValue()
 	}
@@ -67,12 +133,18 @@ func (t *listItem) Description() (description string) {
 	switch e := t.internal.(type) {
 	case *source.Chapter:
 		description = e.URL
+		if e.Notes != "" {
+			description = e.Notes
+		}
 	case *source.Manga:
 		description = e.URL
 	case *installer.Scraper:
 		description = e.GithubURL()
 	case *history.SavedChapter:
 		description = fmt.Sprintf("%s : %d / %d", e.Name, e.Index, e.MangaChaptersTotal)
+		if read := formatTime(e.Time); read != "" {
+			description += ", " + read
+		}
 	case *provider.Provider:
 		sb := strings.Builder{}
 		if e.IsCustom {
@@ -85,9 +157,46 @@ func (t *listItem) Description() (description string) {
 			sb.WriteString(", uses headless chrome")
 		}
 
+		if capabilities := e.Capabilities.String(); capabilities != "" {
+			sb.WriteString(", ")
+			sb.WriteString(capabilities)
+		}
+
 		description = sb.String()
 	case *anilist.Manga:
 		description = e.SiteURL
+	case *server.LibraryManga:
+		switch {
+		case e.Progress != nil:
+			description = fmt.Sprintf("%s : %d", e.Progress.Name, e.Progress.Index)
+			if read := formatTime(e.Progress.Time); read != "" {
+				description += ", " + read
+			}
+		case e.SourceID == "":
+			description = "Unknown source"
+		default:
+			description = e.URL
+		}
+	case *server.Chapter:
+		description = e.URL
+		if e.Notes != "" {
+			description = e.Notes
+		}
+	case *federation.Manga:
+		description = federatedLocations(e.Locations())
+	case *federation.Chapter:
+		description = federatedChapterDescription(e)
+	case *server.Job:
+		switch {
+		case e.Error != "":
+			description = e.Error
+		case e.Progress != "":
+			description = e.Progress
+		case e.Status == server.JobQueued && e.Priority != "" && e.Priority != server.PriorityNormal:
+			description = fmt.Sprintf("%s, %s priority", e.Status, e.Priority)
+		default:
+			description = e.Status
+		}
 	}
 
 	return
@@ -107,6 +216,16 @@ func (t *listItem) FilterValue() string {
 		return e.Name
 	case *installer.Scraper:
 		return e.Name
+	case *server.LibraryManga:
+		return e.Name
+	case *server.Chapter:
+		return e.Name
+	case *federation.Manga:
+		return e.Name
+	case *federation.Chapter:
+		return e.Name
+	case *server.Job:
+		return fmt.Sprintf("%s %s", e.Manga.Name, e.Chapter)
 	default:
 		return ""
 	}
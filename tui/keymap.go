@@ -4,6 +4,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/style"
 )
 
@@ -13,11 +14,17 @@ type statefulKeymap struct {
 	quit, forceQuit,
 	selectOne, selectAll, selectVolume, clearSelection,
 	acceptSearchSuggestion,
+	searchSources,
 	anilistSelect,
 	remove,
 	redownloadFailed,
+	skipChapter,
 	confirm,
 	openURL,
+	planToRead,
+	bookmark,
+	switchSource,
+	showQueue,
 	read,
 	openFolder,
 	back,
@@ -25,6 +32,8 @@ type statefulKeymap struct {
 	up, down, left, right,
 	top, bottom,
 	showHelp key.Binding
+
+	toggleLogs key.Binding
 }
 
 func (k *statefulKeymap) setState(newState state) {
@@ -34,7 +43,9 @@ func (k *statefulKeymap) setState(newState state) {
 func newStatefulKeymap() *statefulKeymap {
 	k := key.NewBinding
 	keys := key.WithKeys
-	help := key.WithHelp
+	help := func(shortcut, description string) key.BindingOpt {
+		return key.WithHelp(shortcut, i18n.T(description))
+	}
 
 	return &statefulKeymap{
 		quit: k(
@@ -73,18 +84,42 @@ func newStatefulKeymap() *statefulKeymap {
 			keys("o"),
 			help("o", "open url"),
 		),
+		planToRead: k(
+			keys("p"),
+			help("p", "plan to read"),
+		),
+		bookmark: k(
+			keys("b"),
+			help("b", "bookmark"),
+		),
+		switchSource: k(
+			keys("s"),
+			help("s", "switch source"),
+		),
+		showQueue: k(
+			keys("tab"),
+			help("tab", "downloads"),
+		),
 		read: k(
 			keys("r"),
-			help(style.Fg(color.Orange)("r"), style.Fg(color.Orange)("read")),
+			key.WithHelp(style.Fg(color.Orange)("r"), style.Fg(color.Orange)(i18n.T("read"))),
 		),
 		acceptSearchSuggestion: k(
 			keys("tab"),
 			help("tab", "accept search suggestion"),
 		),
+		searchSources: k(
+			keys("ctrl+r"),
+			help("ctrl+r", "search sources"),
+		),
 		redownloadFailed: k(
 			keys("r"),
 			help("r", "redownload failed"),
 		),
+		skipChapter: k(
+			keys("s"),
+			help("s", "skip chapter"),
+		),
 		anilistSelect: k(
 			keys("a"),
 			help("a", "select anilist manga"),
@@ -129,6 +164,10 @@ func newStatefulKeymap() *statefulKeymap {
 			keys("?", "h"),
 			help("?", "help"),
 		),
+		toggleLogs: k(
+			keys("ctrl+l"),
+			help("ctrl+l", "logs"),
+		),
 	}
 }
 
@@ -155,12 +194,12 @@ func (k *statefulKeymap) help() ([]key.Binding, []key.Binding) {
 		search := withDescription(k.confirm, "search with selected")
 		return h(k.selectOne, k.selectAll, search), h(k.selectOne, k.selectAll, k.clearSelection, search)
 	case searchState:
-		return to2(h(k.confirm, k.acceptSearchSuggestion, k.forceQuit))
+		return to2(h(k.confirm, k.searchSources, k.acceptSearchSuggestion, k.forceQuit))
 	case mangasState:
-		return to2(h(k.confirm, k.back, k.openURL))
+		return to2(h(k.confirm, k.planToRead, k.back, k.openURL))
 	case chaptersState:
 		download := withDescription(k.confirm, "download selected")
-		return h(k.read, k.selectOne, k.selectAll, download, k.back), h(k.read, k.selectOne, k.selectAll, k.clearSelection, k.openURL, download, k.selectVolume, k.anilistSelect, k.back)
+		return h(k.read, k.selectOne, k.selectAll, download, k.back), h(k.read, k.selectOne, k.selectAll, k.clearSelection, k.openURL, download, k.selectVolume, k.anilistSelect, k.bookmark, k.switchSource, k.back)
 	case anilistSelectState:
 		return to2(h(k.confirm, k.openURL, k.back))
 	case confirmState:
@@ -168,11 +207,25 @@ func (k *statefulKeymap) help() ([]key.Binding, []key.Binding) {
 	case readState:
 		return to2(h(k.back, k.forceQuit))
 	case downloadState:
-		return to2(h(k.back, k.forceQuit))
+		return to2(h(k.skipChapter, k.back, k.forceQuit))
 	case downloadDoneState:
 		return to2(h(k.back, k.quit, k.openFolder, k.redownloadFailed))
 	case errorState:
 		return to2(h(k.back, k.quit))
+	case remoteLibraryState:
+		return to2(h(k.confirm, k.showQueue, k.quit))
+	case remoteChaptersState:
+		download := withDescription(k.confirm, "download selected")
+		return h(k.selectOne, k.selectAll, download, k.back), h(k.selectOne, k.selectAll, k.clearSelection, k.openURL, download, k.back)
+	case remoteQueueState:
+		return to2(h(k.back, k.quit))
+	case clipboardState:
+		return to2(h(withDescription(k.confirm, "open"), withDescription(k.back, "skip"), k.quit))
+	case federatedLibraryState:
+		return to2(h(k.confirm, k.quit))
+	case federatedChaptersState:
+		pull := withDescription(k.confirm, "pull selected")
+		return h(k.selectOne, k.selectAll, pull, k.back), h(k.selectOne, k.selectAll, k.clearSelection, pull, k.openFolder, k.back)
 	default:
 		return to2(h())
 	}
@@ -185,7 +238,7 @@ func (k *statefulKeymap) ShortHelp() []key.Binding {
 
 func (k *statefulKeymap) FullHelp() [][]key.Binding {
 	_, full := k.help()
-	return [][]key.Binding{full}
+	return [][]key.Binding{append(full, k.toggleLogs)}
 }
 
 func (k *statefulKeymap) forList() list.KeyMap {
@@ -210,6 +263,6 @@ func (k *statefulKeymap) forList() list.KeyMap {
 func withDescription(k key.Binding, description string) key.Binding {
 	return key.NewBinding(
 		key.WithKeys(k.Keys()...),
-		key.WithHelp(k.Help().Key, description),
+		key.WithHelp(k.Help().Key, i18n.T(description)),
 	)
 }
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/icon"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/style"
+	"github.com/muesli/reflow/truncate"
+	"strings"
+	"time"
+)
+
+// logPane shows the latest log entries of this run under the screen.
+// It is toggled through its modes with the keymap.toggleLogs
+type logPane int
+
+const (
+	logPaneHidden logPane = iota
+	// logPaneImportant shows the warnings and the errors
+	logPaneImportant
+	// logPaneAll shows every entry of the logs level
+	logPaneAll
+)
+
+const (
+	// logPaneEntries is the number of the entries shown
+	logPaneEntries = 5
+	// logPaneRefresh is how often the new entries are shown
+	logPaneRefresh = time.Second
+)
+
+// logPaneTickMsg refreshes the log pane
+type logPaneTickMsg struct{}
+
+// height of the pane, the screen is shrunk by it
+func (p logPane) height() int {
+	if p == logPaneHidden {
+		return 0
+	}
+
+	// title and the entries
+	return logPaneEntries + 1
+}
+
+func logPaneTick() tea.Cmd {
+	return tea.Tick(logPaneRefresh, func(time.Time) tea.Msg {
+		return logPaneTickMsg{}
+	})
+}
+
+// updateLogPane toggles and refreshes the pane, ok is false if the message is not for it
+func (b *statefulBubble) updateLogPane(msg tea.Msg) (cmd tea.Cmd, ok bool) {
+	switch msg := msg.(type) {
+	case logPaneTickMsg:
+		if b.logPane == logPaneHidden {
+			return nil, true
+		}
+
+		return logPaneTick(), true
+	case tea.KeyMsg:
+		if !key.Matches(msg, b.keymap.toggleLogs) {
+			return nil, false
+		}
+
+		wasHidden := b.logPane == logPaneHidden
+		b.logPane = (b.logPane + 1) % (logPaneAll + 1)
+		b.resize(b.terminalWidth, b.terminalHeight)
+
+		if wasHidden {
+			return logPaneTick(), true
+		}
+
+		return nil, true
+	}
+
+	return nil, false
+}
+
+func (b *statefulBubble) viewLogPane() string {
+	title := i18n.T("Logs: warnings and errors")
+	if b.logPane == logPaneAll {
+		title = i18n.T("Logs: everything")
+	}
+
+	entries := log.Entries(b.logPane == logPaneAll)
+	if len(entries) > logPaneEntries {
+		entries = entries[len(entries)-logPaneEntries:]
+	}
+
+	lines := make([]string, 0, logPaneEntries+1)
+	lines = append(lines, style.Faint(fmt.Sprintf("%s (%s)", title, b.keymap.toggleLogs.Help().Key)))
+
+	tail := "…"
+	if icon.ASCII() {
+		tail = "..."
+	}
+
+	for _, entry := range entries {
+		level := style.Faint(entry.Level)
+		switch entry.Level {
+		case "warning":
+			level = style.Fg(color.Yellow)(entry.Level)
+		case "error", "fatal", "panic":
+			level = style.Fg(color.Red)(entry.Level)
+		}
+
+		line := fmt.Sprintf("%s %s ", style.Faint(entry.Time.Format("15:04:05")), level)
+		if entry.Source != "" {
+			line += style.Fg(color.Purple)(entry.Source) + " "
+		}
+
+		lines = append(lines, truncate.StringWithTail(line+strings.ReplaceAll(entry.Message, "\n", " "), uint(b.width), tail))
+	}
+
+	if len(entries) == 0 {
+		lines = append(lines, style.Faint(i18n.T("Nothing yet")))
+	}
+
+	// keep the height of the pane while the entries come
+	for len(lines) < b.logPane.height() {
+		lines = append(lines, "")
+	}
+
+	return paddingStyle.Copy().PaddingTop(0).PaddingBottom(0).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
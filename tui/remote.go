@@ -0,0 +1,241 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/open"
+	"github.com/metafates/mangal/server"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"time"
+)
+
+// remoteQueueRefresh is how often the download queue of the server is refreshed
+const remoteQueueRefresh = 2 * time.Second
+
+type (
+	remoteLibraryMsg  []*server.LibraryManga
+	remoteChaptersMsg []*server.Chapter
+	remoteJobsMsg     []*server.Job
+	remoteQueuedMsg   []*server.Job
+
+	// remoteTickMsg refreshes the queue, id drops the ticks of the previous visits
+	remoteTickMsg struct{ id int }
+)
+
+func (b *statefulBubble) fetchRemoteLibrary() tea.Cmd {
+	client := b.remote
+	return tea.Batch(b.remoteLibraryC.StartSpinner(), func() tea.Msg {
+		mangas, err := client.Library()
+		if err != nil {
+			return err
+		}
+
+		return remoteLibraryMsg(mangas)
+	})
+}
+
+func (b *statefulBubble) fetchRemoteChapters(manga *server.LibraryManga) tea.Cmd {
+	client := b.remote
+	return tea.Batch(b.remoteChaptersC.StartSpinner(), func() tea.Msg {
+		chapters, err := client.Chapters(&manga.MangaRef)
+		if err != nil {
+			return err
+		}
+
+		return remoteChaptersMsg(chapters)
+	})
+}
+
+func (b *statefulBubble) fetchRemoteJobs() tea.Cmd {
+	client := b.remote
+	return func() tea.Msg {
+		jobs, err := client.Downloads()
+		if err != nil {
+			return err
+		}
+
+		return remoteJobsMsg(jobs)
+	}
+}
+
+func (b *statefulBubble) submitRemoteDownload(manga *server.LibraryManga, chapters []*server.Chapter) tea.Cmd {
+	client := b.remote
+	request := &server.DownloadRequest{Manga: manga.MangaRef}
+	for _, chapter := range chapters {
+		request.Chapters = append(request.Chapters, chapter.URL)
+	}
+
+	return func() tea.Msg {
+		jobs, err := client.Download(request)
+		if err != nil {
+			return err
+		}
+
+		return remoteQueuedMsg(jobs)
+	}
+}
+
+// showRemoteQueue switches to the download queue and keeps it refreshed
+func (b *statefulBubble) showRemoteQueue() tea.Cmd {
+	b.newState(remoteQueueState)
+	b.remoteTick++
+	return tea.Batch(b.fetchRemoteJobs(), b.remoteTickAfter(b.remoteTick))
+}
+
+func (b *statefulBubble) remoteTickAfter(id int) tea.Cmd {
+	return tea.Tick(remoteQueueRefresh, func(time.Time) tea.Msg {
+		return remoteTickMsg{id: id}
+	})
+}
+
+func (b *statefulBubble) updateRemoteLibrary(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case remoteLibraryMsg:
+		b.remoteLibraryC.StopSpinner()
+
+		items := make([]list.Item, len(msg))
+		for i, manga := range msg {
+			items[i] = &listItem{internal: manga}
+		}
+
+		return b, b.remoteLibraryC.SetItems(items)
+	case tea.KeyMsg:
+		switch {
+		case b.remoteLibraryC.FilterState() == list.Filtering:
+			break
+		case key.Matches(msg, b.keymap.showQueue):
+			return b, b.showRemoteQueue()
+		case key.Matches(msg, b.keymap.confirm):
+			if b.remoteLibraryC.SelectedItem() == nil {
+				break
+			}
+
+			manga := b.remoteLibraryC.SelectedItem().(*listItem).internal.(*server.LibraryManga)
+			if manga.SourceID == "" {
+				return b, b.remoteLibraryC.NewStatusMessage(style.Fg(color.Red)(fmt.Sprintf("Source of %s is unknown", manga.Name)))
+			}
+
+			b.remoteManga = manga
+			b.remoteChaptersC.Title = util.Truncate(manga.Name, titleWidth)
+			b.newState(remoteChaptersState)
+			return b, tea.Batch(b.remoteChaptersC.SetItems(nil), b.fetchRemoteChapters(manga))
+		}
+	}
+
+	b.remoteLibraryC, cmd = b.remoteLibraryC.Update(msg)
+	return b, cmd
+}
+
+func (b *statefulBubble) updateRemoteChapters(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	setMarked := func(marked bool) {
+		for _, item := range b.remoteChaptersC.Items() {
+			item.(*listItem).marked = marked
+		}
+	}
+
+	switch msg := msg.(type) {
+	case remoteChaptersMsg:
+		b.remoteChaptersC.StopSpinner()
+
+		items := make([]list.Item, len(msg))
+		for i, chapter := range msg {
+			items[i] = &listItem{internal: chapter}
+		}
+
+		return b, b.remoteChaptersC.SetItems(items)
+	case remoteQueuedMsg:
+		setMarked(false)
+		cmd = b.showRemoteQueue()
+		return b, tea.Batch(cmd, b.remoteQueueC.NewStatusMessage(i18n.T("Queued %s", i18n.N(len(msg), "chapter", "chapters"))))
+	case tea.KeyMsg:
+		switch {
+		case b.remoteChaptersC.FilterState() == list.Filtering:
+			break
+		case key.Matches(msg, b.keymap.openURL):
+			if b.remoteChaptersC.SelectedItem() == nil {
+				break
+			}
+
+			if err := open.Start(b.remoteChaptersC.SelectedItem().(*listItem).internal.(*server.Chapter).URL); err != nil {
+				b.raiseError(err)
+			}
+		case key.Matches(msg, b.keymap.selectOne):
+			if item := b.remoteChaptersC.SelectedItem(); item != nil {
+				item.(*listItem).toggleMark()
+			}
+		case key.Matches(msg, b.keymap.selectAll):
+			setMarked(true)
+		case key.Matches(msg, b.keymap.clearSelection):
+			setMarked(false)
+		case key.Matches(msg, b.keymap.confirm):
+			var chapters []*server.Chapter
+			for _, item := range b.remoteChaptersC.Items() {
+				if item := item.(*listItem); item.marked {
+					chapters = append(chapters, item.internal.(*server.Chapter))
+				}
+			}
+
+			// download the chapter under cursor if nothing is selected
+			if len(chapters) == 0 && b.remoteChaptersC.SelectedItem() != nil {
+				chapters = append(chapters, b.remoteChaptersC.SelectedItem().(*listItem).internal.(*server.Chapter))
+			}
+
+			if len(chapters) == 0 {
+				break
+			}
+
+			return b, tea.Batch(
+				b.remoteChaptersC.NewStatusMessage(i18n.T("Queueing %s", i18n.N(len(chapters), "chapter", "chapters"))),
+				b.submitRemoteDownload(b.remoteManga, chapters),
+			)
+		}
+	}
+
+	b.remoteChaptersC, cmd = b.remoteChaptersC.Update(msg)
+	return b, cmd
+}
+
+func (b *statefulBubble) updateRemoteQueue(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case remoteTickMsg:
+		if msg.id != b.remoteTick {
+			return b, nil
+		}
+
+		return b, tea.Batch(b.fetchRemoteJobs(), b.remoteTickAfter(msg.id))
+	case remoteJobsMsg:
+		// newest first
+		items := make([]list.Item, len(msg))
+		for i, job := range msg {
+			items[len(msg)-1-i] = &listItem{internal: job}
+		}
+
+		return b, b.remoteQueueC.SetItems(items)
+	}
+
+	b.remoteQueueC, cmd = b.remoteQueueC.Update(msg)
+	return b, cmd
+}
+
+func (b *statefulBubble) viewRemoteLibrary() string {
+	return listExtraPaddingStyle.Render(b.remoteLibraryC.View())
+}
+
+func (b *statefulBubble) viewRemoteChapters() string {
+	return listExtraPaddingStyle.Render(b.remoteChaptersC.View())
+}
+
+func (b *statefulBubble) viewRemoteQueue() string {
+	return listExtraPaddingStyle.Render(b.remoteQueueC.View())
+}
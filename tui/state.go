@@ -16,4 +16,10 @@ const (
 	readState
 	downloadState
 	downloadDoneState
+	remoteLibraryState
+	remoteChaptersState
+	remoteQueueState
+	clipboardState
+	federatedLibraryState
+	federatedChaptersState
 )
@@ -0,0 +1,194 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/provider"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/style"
+	"github.com/metafates/mangal/util"
+	"github.com/samber/lo"
+	"sync"
+)
+
+// switchedSourceMsg is the manga found on another source, with no manga if it was not found
+type switchedSourceMsg struct {
+	// from is the manga that was switched, the result is discarded if another one is viewed already
+	from     *source.Manga
+	manga    *source.Manga
+	chapters []*source.Chapter
+}
+
+// otherSources returns the sources to look for the manga on: the other sources selected for the search,
+// or the other listed ones if the manga was searched on its source only.
+// The listed ones are loaded lazily, see loadOtherSources
+func (b *statefulBubble) otherSources(current source.Source) (loaded []source.Source, listed []*provider.Provider) {
+	loaded = lo.Filter(b.selectedSources, func(s source.Source, _ int) bool {
+		return s != nil && s.ID() != current.ID()
+	})
+
+	if len(loaded) > 0 {
+		return loaded, nil
+	}
+
+	for _, item := range b.sourcesC.Items() {
+		if p := item.(*listItem).internal.(*provider.Provider); p.ID != current.ID() {
+			listed = append(listed, p)
+		}
+	}
+
+	return nil, listed
+}
+
+// loadOtherSources creates the sources of the providers, skipping the ones that fail to load
+func loadOtherSources(providers []*provider.Provider) []source.Source {
+	var (
+		sources = make([]source.Source, len(providers))
+		wg      sync.WaitGroup
+	)
+
+	wg.Add(len(providers))
+	for i, p := range providers {
+		go func(i int, p *provider.Provider) {
+			defer wg.Done()
+
+			s, err := p.CreateSource()
+			if err != nil {
+				log.Warn(err)
+				return
+			}
+
+			sources[i] = s
+		}(i, p)
+	}
+
+	wg.Wait()
+
+	return lo.Filter(sources, func(s source.Source, _ int) bool {
+		return s != nil
+	})
+}
+
+// switchSource searches the manga on the other sources and picks the one with the most chapters,
+// the first in the order of the sources if several have as many
+func (b *statefulBubble) switchSource(manga *source.Manga) tea.Cmd {
+	loaded, listed := b.otherSources(manga.Source)
+
+	return func() tea.Msg {
+		sources := append(loaded, loadOtherSources(listed)...)
+		log.Infof("searching %s among %s", manga.Name, util.Quantify(len(sources), "other source", "other sources"))
+
+		var (
+			found = make([]*switchedSourceMsg, len(sources))
+			wg    sync.WaitGroup
+		)
+
+		wg.Add(len(sources))
+		for i, s := range sources {
+			go func(i int, s source.Source) {
+				defer wg.Done()
+
+				mangas, err := s.Search(manga.Name)
+				if err != nil {
+					log.Source(s.Name()).Warn(err)
+					return
+				}
+
+				same, ok := lo.Find(mangas, func(m *source.Manga) bool {
+					return lo.ContainsBy(manga.Titles(), m.HasTitle)
+				})
+				if !ok {
+					return
+				}
+
+				chapters, err := s.ChaptersOf(same)
+				if err != nil {
+					log.Source(s.Name()).Warn(err)
+					return
+				}
+
+				log.Source(s.Name()).Infof("found %s with %s", same.Name, util.Quantify(len(chapters), "chapter", "chapters"))
+				found[i] = &switchedSourceMsg{from: manga, manga: same, chapters: chapters}
+			}(i, s)
+		}
+
+		wg.Wait()
+
+		best := switchedSourceMsg{from: manga}
+		for _, f := range found {
+			if f != nil && (best.manga == nil || len(f.chapters) > len(best.chapters)) {
+				best = *f
+			}
+		}
+
+		return best
+	}
+}
+
+// showSwitchedSource replaces the chapters with the ones of the manga on the other source.
+// The selected chapters and the one under the cursor are matched by their numbers, see source.Chapter.Number
+func (b *statefulBubble) showSwitchedSource(msg switchedSourceMsg) tea.Cmd {
+	var (
+		selected = lo.Keys(b.selectedChapters)
+		numbers  = make(map[string]struct{})
+		current  string
+	)
+
+	for _, chapter := range selected {
+		if number, ok := chapter.Number(); ok {
+			numbers[number] = struct{}{}
+		}
+	}
+
+	if item := b.chaptersC.SelectedItem(); item != nil {
+		current, _ = item.(*listItem).internal.(*source.Chapter).Number()
+	}
+
+	b.selectedManga = msg.manga
+	b.selectedChapters = make(map[*source.Chapter]struct{})
+
+	var (
+		items  = b.chapterItems(msg.chapters)
+		found  = make(map[string]struct{})
+		cursor int
+	)
+
+	for i, item := range items {
+		item := item.(*listItem)
+		chapter := item.internal.(*source.Chapter)
+
+		number, ok := chapter.Number()
+		if !ok {
+			continue
+		}
+
+		if _, ok := numbers[number]; ok {
+			item.marked = true
+			b.selectedChapters[chapter] = struct{}{}
+			found[number] = struct{}{}
+		}
+
+		if number == current && current != "" {
+			cursor = i
+		}
+	}
+
+	missing := lo.CountBy(selected, func(chapter *source.Chapter) bool {
+		number, ok := chapter.Number()
+		_, found := found[number]
+		return !ok || !found
+	})
+
+	b.chaptersC.ResetFilter()
+	cmd := b.chaptersC.SetItems(items)
+	b.chaptersC.Select(cursor)
+
+	name := style.Fg(color.Orange)(msg.manga.Source.Name())
+	if missing > 0 {
+		return tea.Batch(cmd, b.chaptersC.NewStatusMessage(i18n.T("Switched to %s, not found there: %s", name, i18n.N(missing, "chapter", "chapters"))))
+	}
+
+	return tea.Batch(cmd, b.chaptersC.NewStatusMessage(i18n.T("Switched to %s", name)))
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// absoluteTimeLayout shows the time in the local timezone with its name
+const absoluteTimeLayout = "2006-01-02 15:04 MST"
+
+// formatTime returns how long ago the time was, e.g. "2 hours ago",
+// or the local time if the absolute times are enabled by the config
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	if viper.GetBool(key.TUIAbsoluteTime) {
+		return t.Local().Format(absoluteTimeLayout)
+	}
+
+	return relativeTime(time.Since(t))
+}
+
+func relativeTime(elapsed time.Duration) string {
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+
+	ago := func(count time.Duration, singular, plural string) string {
+		return i18n.T("%s ago", i18n.N(int(count), singular, plural))
+	}
+
+	switch {
+	case elapsed < time.Minute:
+		// the clocks of the server may be ahead too
+		return i18n.T("just now")
+	case elapsed < time.Hour:
+		return ago(elapsed/time.Minute, "minute", "minutes")
+	case elapsed < day:
+		return ago(elapsed/time.Hour, "hour", "hours")
+	case elapsed < month:
+		return ago(elapsed/day, "day", "days")
+	case elapsed < year:
+		return ago(elapsed/month, "month", "months")
+	default:
+		return ago(elapsed/year, "year", "years")
+	}
+}
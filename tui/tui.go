@@ -2,18 +2,45 @@ package tui
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/metafates/mangal/federation"
+	"github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/remote"
+	"github.com/spf13/viper"
 )
 
 type Options struct {
 	Continue bool
 	Install  bool
+	// Remote is the server to control instead of the local mangal
+	Remote *remote.Client
+	// Federation is the local library merged with the libraries of the servers, to pull the chapters from
+	Federation *federation.Library
 }
 
 func Run(options *Options) error {
+	// the log pane shows the entries of this run
+	log.Capture()
 
 	bubble := newBubble()
 
-	if options.Install {
+	// the screen readers follow the output of the terminal, the announcements are printed above the TUI
+	// panics are reported by crash.Recover, see run
+	programOptions := []tea.ProgramOption{tea.WithoutCatchPanics()}
+	if !bubble.accessible {
+		programOptions = append(programOptions, tea.WithAltScreen())
+	}
+
+	if options.Federation != nil {
+		bubble.federation = options.Federation
+		bubble.newState(federatedLibraryState)
+		return run(tea.NewProgram(bubble, programOptions...))
+	} else if options.Remote != nil {
+		bubble.remote = options.Remote
+		bubble.remoteLibraryC.Title = "Library " + options.Remote.String()
+		bubble.newState(remoteLibraryState)
+		return run(tea.NewProgram(bubble, programOptions...))
+	} else if options.Install {
 		bubble.newState(scrapersInstallState)
 	} else if options.Continue {
 		_, err := bubble.loadHistory()
@@ -24,7 +51,27 @@ func Run(options *Options) error {
 		bubble.newState(historyState)
 	} else {
 		bubble.newState(sourcesState)
+
+		if viper.GetBool(key.TUIDetectClipboard) {
+			if link, ok := detectClipboardLink(); ok {
+				bubble.clipboard = link
+				bubble.newState(clipboardState)
+			}
+		}
 	}
 
-	return tea.NewProgram(bubble, tea.WithAltScreen()).Start()
+	return run(tea.NewProgram(bubble, programOptions...))
+}
+
+// run starts the program and restores the terminal if it panics,
+// the panic is passed on to be reported
+func run(program *tea.Program) error {
+	defer func() {
+		if r := recover(); r != nil {
+			_ = program.ReleaseTerminal()
+			panic(r)
+		}
+	}()
+
+	return program.Start()
 }
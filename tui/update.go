@@ -7,13 +7,18 @@ import (
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/metafates/mangal/anilist"
+	"github.com/metafates/mangal/bookmarks"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/downloader"
 	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/i18n"
 	"github.com/metafates/mangal/installer"
 	key2 "github.com/metafates/mangal/key"
+	"github.com/metafates/mangal/log"
 	"github.com/metafates/mangal/open"
 	"github.com/metafates/mangal/provider"
 	"github.com/metafates/mangal/query"
+	"github.com/metafates/mangal/reading"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/util"
@@ -25,11 +30,25 @@ import (
 )
 
 func (b *statefulBubble) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := b.updateLogPane(msg); ok {
+		return b, cmd
+	}
+
+	previous, chapter := b.state, b.currentDownloadingChapter
+	model, cmd := b.update(msg)
+	return model, tea.Batch(cmd, b.announce(previous, chapter))
+}
+
+func (b *statefulBubble) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
 	case error:
 		b.raiseError(msg)
+	case federatedPulledMsg:
+		return b, b.onFederatedPulled(msg)
+	case clipboardChapterMsg:
+		return b, b.onClipboardChapter(msg)
 	case tea.WindowSizeMsg:
 		b.resize(msg.Width, msg.Height)
 	case tea.KeyMsg:
@@ -90,10 +109,47 @@ func (b *statefulBubble) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				cmd = onListBack(&b.scrapersInstallC)
+			case remoteLibraryState:
+				if b.remoteLibraryC.FilterState() != list.Unfiltered {
+					b.remoteLibraryC, cmd = b.remoteLibraryC.Update(msg)
+					return b, cmd
+				}
+			case remoteChaptersState:
+				if b.remoteChaptersC.FilterState() != list.Unfiltered {
+					b.remoteChaptersC, cmd = b.remoteChaptersC.Update(msg)
+					return b, cmd
+				}
+
+				cmd = onListBack(&b.remoteChaptersC)
+			case remoteQueueState:
+				if b.remoteQueueC.FilterState() != list.Unfiltered {
+					b.remoteQueueC, cmd = b.remoteQueueC.Update(msg)
+					return b, cmd
+				}
+
+				cmd = onListBack(&b.remoteQueueC)
+			case federatedLibraryState:
+				if b.federatedMangasC.FilterState() != list.Unfiltered {
+					b.federatedMangasC, cmd = b.federatedMangasC.Update(msg)
+					return b, cmd
+				}
+			case federatedChaptersState:
+				if b.federatedChaptersC.FilterState() != list.Unfiltered {
+					b.federatedChaptersC, cmd = b.federatedChaptersC.Update(msg)
+					return b, cmd
+				}
+
+				cmd = onListBack(&b.federatedChaptersC)
+			case clipboardState:
+				// continue as if the clipboard was empty
+				b.clipboard = nil
+				b.previousState()
+				return b, b.start()
 			}
 
 			b.previousState()
 			b.stopLoading()
+			b.stopSearch()
 			b.failedChapters = make([]*source.Chapter, 0)
 			b.succededChapters = make([]*source.Chapter, 0)
 			return b, cmd
@@ -127,6 +183,18 @@ func (b *statefulBubble) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return b.updateScrapersInstall(msg)
 	case errorState:
 		return b.updateError(msg)
+	case remoteLibraryState:
+		return b.updateRemoteLibrary(msg)
+	case remoteChaptersState:
+		return b.updateRemoteChapters(msg)
+	case remoteQueueState:
+		return b.updateRemoteQueue(msg)
+	case clipboardState:
+		return b.updateClipboard(msg)
+	case federatedLibraryState:
+		return b.updateFederatedLibrary(msg)
+	case federatedChaptersState:
+		return b.updateFederatedChapters(msg)
 	}
 
 	panic("unreachable")
@@ -204,17 +272,37 @@ func (b *statefulBubble) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return b, b.stopLoading()
 	case *installer.Scraper:
 		b.newState(scrapersInstallState)
-		b.scrapersInstallC.NewStatusMessage(fmt.Sprintf("Installed %s", msg.Name))
+		b.scrapersInstallC.NewStatusMessage(i18n.T("Installed %s", msg.Name))
 		return b, b.stopLoading()
-	case []*source.Manga:
-		items := make([]list.Item, len(msg))
-		for i, m := range msg {
-			items[i] = &listItem{internal: m}
+	case foundMangasMsg:
+		if msg.searchID != b.searchID {
+			break
 		}
 
-		cmds = append(cmds, b.mangasC.SetItems(items))
-		b.newState(mangasState)
+		b.cancelSearch = nil
+		b.searchResults = msg.mangas
+		b.searchMatches = nil
+		cmds = append(cmds, b.showMangas(msg.mangas))
 		b.stopLoading()
+
+		if link := b.clipboard; link != nil {
+			b.clipboard = nil
+
+			// jump straight to the chapters if the link is the page of the found manga
+			manga, i, found := lo.FindIndexOf(msg.mangas, func(manga *source.Manga) bool {
+				return provider.SameLink(manga.URL, link.url)
+			})
+
+			switch {
+			case found:
+				b.mangasC.Select(i)
+				b.selectedManga = manga
+				cmds = append(cmds, b.getChapters(manga), b.waitForChapters(), b.startLoading())
+			case len(msg.mangas) > 0:
+				// otherwise it may be the page of a chapter of one of them
+				cmds = append(cmds, b.findClipboardChapter(msg.mangas, link.url), b.startLoading())
+			}
+		}
 	case []*source.Chapter:
 		if b.statesHistory.Peek() == historyState {
 			b.newState(historyState)
@@ -225,6 +313,8 @@ func (b *statefulBubble) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case []source.Source:
 		b.selectedSources = msg
+		b.searchResults = nil
+		b.searchMatches = nil
 
 		if b.statesHistory.Peek() == historyState {
 			b.newState(historyState)
@@ -235,6 +325,14 @@ func (b *statefulBubble) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			b.stopLoading()
 			b.newState(searchState)
+
+			if b.clipboard != nil {
+				// search the manga of the link right away
+				b.inputC.SetValue(b.clipboard.query)
+				b.startLoading()
+				b.newState(loadingState)
+				return b, tea.Batch(b.searchManga(b.clipboard.query), b.spinnerC.Tick)
+			}
 		}
 	}
 
@@ -268,9 +366,10 @@ func (b *statefulBubble) updateHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case []*source.Chapter:
 		items := make([]list.Item, len(msg))
 		selected := b.historyC.SelectedItem().(*listItem).internal.(*history.SavedChapter)
+		bookmarked := bookmarks.IsBookmarked()
 
 		for i, c := range msg {
-			items[i] = &listItem{internal: c}
+			items[i] = &listItem{internal: c, bookmarked: bookmarked(c)}
 		}
 
 		cmd = b.chaptersC.SetItems(items)
@@ -391,11 +490,15 @@ func (b *statefulBubble) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, b.keymap.confirm) && b.inputC.Value() != "":
+		case key.Matches(msg, b.keymap.confirm) && b.inputC.Value() != "" && len(b.searchMatches) > 0:
+			// filter the results of the previous search, without searching the sources again
+			go query.Remember(b.inputC.Value(), 1)
+			return b, b.showMangas(b.searchMatches)
+		case key.Matches(msg, b.keymap.confirm, b.keymap.searchSources) && b.inputC.Value() != "":
 			b.startLoading()
 			b.newState(loadingState)
 			go query.Remember(b.inputC.Value(), 1)
-			return b, tea.Batch(b.searchManga(b.inputC.Value()), b.waitForMangas(), b.spinnerC.Tick)
+			return b, tea.Batch(b.searchManga(b.inputC.Value()), b.spinnerC.Tick)
 		case key.Matches(msg, b.keymap.acceptSearchSuggestion) && b.searchSuggestion.IsPresent():
 			b.inputC.SetValue(b.searchSuggestion.MustGet())
 			b.searchSuggestion = mo.None[string]()
@@ -406,6 +509,12 @@ func (b *statefulBubble) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	b.inputC, cmd = b.inputC.Update(msg)
 
+	if len(b.searchResults) > 0 && b.inputC.Value() != "" {
+		b.searchMatches = matchMangas(b.inputC.Value(), b.searchResults)
+	} else {
+		b.searchMatches = nil
+	}
+
 	if b.inputC.Value() != "" {
 		if suggestion, ok := query.Suggest(b.inputC.Value()).Get(); ok && suggestion != b.inputC.Value() {
 			b.searchSuggestion = mo.Some(suggestion)
@@ -446,21 +555,23 @@ func (b *statefulBubble) updateMangas(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if err != nil {
 				b.raiseError(err)
 			}
-		}
-	case []*source.Chapter:
-		items := make([]list.Item, len(msg))
-
-		if viper.GetBool(key2.TUIReverseChapters) {
-			for i, c := range msg {
-				items[len(msg)-i-1] = &listItem{internal: c}
+		case key.Matches(msg, b.keymap.planToRead):
+			if b.mangasC.SelectedItem() == nil {
+				break
 			}
-		} else {
-			for i, c := range msg {
-				items[i] = &listItem{internal: c}
+
+			m, _ := b.mangasC.SelectedItem().(*listItem).internal.(*source.Manga)
+			err := reading.Add(reading.NewItem(m))
+			if err != nil {
+				b.raiseError(err)
+				break
 			}
-		}
 
-		cmd = b.chaptersC.SetItems(items)
+			cmd = b.mangasC.NewStatusMessage(i18n.T("Added %s to the reading queue", style.Fg(color.Orange)(m.Name)))
+			return b, cmd
+		}
+	case []*source.Chapter:
+		cmd = b.chaptersC.SetItems(b.chapterItems(msg))
 		b.newState(chaptersState)
 		b.stopLoading()
 
@@ -480,8 +591,21 @@ func (b *statefulBubble) updateChapters(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case *anilist.Manga:
-		cmd = b.chaptersC.NewStatusMessage(fmt.Sprintf(`Linked to %s %s`, style.Fg(color.Orange)(msg.Name()), style.Faint(msg.SiteURL)))
+		cmd = b.chaptersC.NewStatusMessage(i18n.T(`Linked to %s %s`, style.Fg(color.Orange)(msg.Name()), style.Faint(msg.SiteURL)))
 		return b, cmd
+	case switchedSourceMsg:
+		// the spinner is stopped even if another manga is selected already, nothing else stops it
+		b.stopLoading()
+		if msg.from != b.selectedManga {
+			break
+		}
+
+		if msg.manga == nil {
+			cmd = b.chaptersC.NewStatusMessage(i18n.T("%s was not found on the other sources", style.Fg(color.Orange)(msg.from.Name)))
+			return b, cmd
+		}
+
+		return b, b.showSwitchedSource(msg)
 	case tea.KeyMsg:
 		switch {
 		case b.chaptersC.FilterState() == list.Filtering:
@@ -496,6 +620,35 @@ func (b *statefulBubble) updateChapters(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if err != nil {
 				b.raiseError(err)
 			}
+		case key.Matches(msg, b.keymap.bookmark):
+			if b.chaptersC.SelectedItem() == nil {
+				break
+			}
+
+			item := b.chaptersC.SelectedItem().(*listItem)
+			chapter := item.internal.(*source.Chapter)
+
+			bookmarked, err := bookmarks.Toggle(chapter)
+			if err != nil {
+				b.raiseError(err)
+				break
+			}
+
+			item.bookmarked = bookmarked
+			if bookmarked {
+				cmd = b.chaptersC.NewStatusMessage(i18n.T("Bookmarked %s", style.Fg(color.Orange)(chapter.Name)))
+			} else {
+				cmd = b.chaptersC.NewStatusMessage(i18n.T("Removed the bookmark of %s", style.Fg(color.Orange)(chapter.Name)))
+			}
+
+			return b, cmd
+		case key.Matches(msg, b.keymap.switchSource):
+			if b.loading || b.selectedManga == nil {
+				break
+			}
+
+			cmd = b.chaptersC.NewStatusMessage(i18n.T("Searching %s on the other sources", style.Fg(color.Orange)(b.selectedManga.Name)))
+			return b, tea.Batch(cmd, b.startLoading(), b.switchSource(b.selectedManga))
 		case key.Matches(msg, b.keymap.anilistSelect):
 			b.newState(loadingState)
 			return b, tea.Batch(b.startLoading(), b.fetchAnilist(b.selectedManga), b.waitForAnilist())
@@ -567,7 +720,17 @@ func (b *statefulBubble) updateChapters(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return b, tea.Batch(b.readChapter(chapter), b.waitForChapterRead(), b.startLoading())
 		case key.Matches(msg, b.keymap.confirm):
 			if len(b.selectedChapters) != 0 {
+				chapters := lo.Keys(b.selectedChapters)
+				slices.SortFunc(chapters, source.ChapterLess)
+
+				id := 1
+				if b.confirmation != nil {
+					id = b.confirmation.id + 1
+				}
+
+				b.confirmation = newConfirmation(id, chapters)
 				b.newState(confirmState)
+				return b, b.confirmation.countPages()
 			} else if viper.GetBool(key2.TUIReadOnEnter) {
 				if b.chaptersC.SelectedItem() == nil {
 					break
@@ -615,7 +778,7 @@ func (b *statefulBubble) updateAnilistSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			b.previousState()
-			cmd = b.chaptersC.NewStatusMessage(fmt.Sprintf(`Linked to %s %s`, style.Fg(color.Orange)(manga.Name()), style.Faint(manga.SiteURL)))
+			cmd = b.chaptersC.NewStatusMessage(i18n.T(`Linked to %s %s`, style.Fg(color.Orange)(manga.Name()), style.Faint(manga.SiteURL)))
 			return b, cmd
 		}
 	}
@@ -628,16 +791,44 @@ func (b *statefulBubble) updateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case pagesCountedMsg:
+		if b.confirmation == nil || msg.id != b.confirmation.id {
+			break
+		}
+
+		if msg.err != nil {
+			b.confirmation.countFailed = true
+			break
+		}
+
+		if msg.estimateErr != nil {
+			b.confirmation.estimateFailed = true
+		}
+
+		b.confirmation.pages += msg.pages
+		b.confirmation.size += msg.size
+		b.confirmation.counted++
+		return b, b.confirmation.countPages()
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, b.keymap.quit):
 			return b, tea.Quit
 		case key.Matches(msg, b.keymap.confirm):
+			if err := downloader.Preflight(); err != nil {
+				b.raiseError(err)
+				return b, nil
+			}
+
 			chapters := lo.Keys(b.selectedChapters)
 			slices.SortFunc(chapters, func(a, b *source.Chapter) bool {
-				return a.Index > b.Index
+				return source.ChapterLess(b, a)
 			})
 
+			// the chapters that are not reached yet are resumed too, if the download is interrupted
+			if err := downloader.Enqueue(chapters...); err != nil {
+				log.Warn(err)
+			}
+
 			for _, chapter := range chapters {
 				b.chaptersToDownload.Push(chapter)
 			}
@@ -682,6 +873,11 @@ func (b *statefulBubble) updateDownload(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		return b, tea.Batch(b.progressC.IncrPercent(inc), b.downloadChapter(b.chaptersToDownload.Pop()), b.waitForChapterDownload())
+	case tea.KeyMsg:
+		if key.Matches(msg, b.keymap.skipChapter) {
+			downloader.Skip()
+			return b, nil
+		}
 	case progress.FrameMsg:
 		model, cmd := b.progressC.Update(msg)
 		b.progressC = model.(progress.Model)
@@ -3,19 +3,31 @@ package tui
 import (
 	"fmt"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
 	"github.com/metafates/mangal/color"
+	"github.com/metafates/mangal/i18n"
+
 	"github.com/metafates/mangal/icon"
 	"github.com/metafates/mangal/key"
 	"github.com/metafates/mangal/style"
 	"github.com/metafates/mangal/util"
-	"github.com/muesli/reflow/wrap"
+	"github.com/samber/lo"
 	"github.com/spf13/viper"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 func (b *statefulBubble) View() string {
+	if b.logPane == logPaneHidden {
+		return b.view()
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, b.view(), b.viewLogPane())
+}
+
+func (b *statefulBubble) view() string {
 	switch b.state {
 	case scrapersInstallState:
 		return b.viewScrapersInstallState()
@@ -43,6 +55,18 @@ func (b *statefulBubble) View() string {
 		return b.viewDownloadDone()
 	case errorState:
 		return b.viewError()
+	case remoteLibraryState:
+		return b.viewRemoteLibrary()
+	case remoteChaptersState:
+		return b.viewRemoteChapters()
+	case remoteQueueState:
+		return b.viewRemoteQueue()
+	case clipboardState:
+		return b.viewClipboard()
+	case federatedLibraryState:
+		return b.viewFederatedLibrary()
+	case federatedChaptersState:
+		return b.viewFederatedChapters()
 	}
 
 	panic("unknown state")
@@ -52,7 +76,7 @@ func (b *statefulBubble) viewLoading() string {
 	return b.renderLines(
 		true,
 		[]string{
-			style.Title("Loading"),
+			style.Title(i18n.T("Loading")),
 			"",
 			b.spinnerC.View() + b.progressStatus,
 		},
@@ -69,7 +93,7 @@ func (b *statefulBubble) viewSources() string {
 
 func (b *statefulBubble) viewSearch() string {
 	lines := []string{
-		style.Title("Search Manga"),
+		style.Title(i18n.T("Search Manga")),
 		"",
 		b.inputC.View(),
 	}
@@ -78,9 +102,35 @@ func (b *statefulBubble) viewSearch() string {
 		lines = append(
 			lines,
 			"",
-			fmt.Sprintf("Search %s ?", style.Fg(color.Orange)(b.searchSuggestion.MustGet())),
+			i18n.T("Search %s ?", style.Fg(color.Orange)(b.searchSuggestion.MustGet())),
 			"",
-			fmt.Sprintf("Press %s to accept", style.Bold(style.Faint(b.keymap.acceptSearchSuggestion.Help().Key))),
+			i18n.T("Press %s to accept", style.Bold(style.Faint(b.keymap.acceptSearchSuggestion.Help().Key))),
+		)
+	}
+
+	if b.inputC.Value() != "" && len(b.searchResults) > 0 {
+		// do not let the matches push the help out of the screen
+		const maxMatches = 5
+
+		lines = append(
+			lines,
+			"",
+			style.Faint(i18n.T("%d of %s from the previous search match", len(b.searchMatches), i18n.N(len(b.searchResults), "manga", "mangas"))),
+		)
+
+		for i, manga := range b.searchMatches {
+			if i == maxMatches {
+				lines = append(lines, style.Faint(i18n.T("and %d more", len(b.searchMatches)-maxMatches)))
+				break
+			}
+
+			lines = append(lines, style.Truncate(b.width)(fmt.Sprintf("%s %s", manga.Name, style.Faint(manga.Source.Name()))))
+		}
+
+		lines = append(
+			lines,
+			"",
+			i18n.T("Press %s to search the sources again", style.Bold(style.Faint(b.keymap.searchSources.Help().Key))),
 		)
 	}
 
@@ -103,30 +153,91 @@ func (b *statefulBubble) viewAniList() string {
 }
 
 func (b *statefulBubble) viewConfirm() string {
-	return b.renderLines(
-		true,
-		[]string{
-			style.Title("Confirm"),
+	lines := []string{
+		style.Title(i18n.T("Confirm")),
+		"",
+		icon.Get(icon.Question) + " " + i18n.T("Download %s?", i18n.N(len(b.selectedChapters), "chapter", "chapters")),
+	}
+
+	if c := b.confirmation; c != nil {
+		field := func(name, value string) string {
+			return style.Truncate(b.width)(fmt.Sprintf("%s %s", style.Faint(util.PadRight(i18n.T(name), 12)), value))
+		}
+
+		lines = append(
+			lines,
 			"",
-			fmt.Sprintf("%s Download %s?", icon.Get(icon.Question), util.Quantify(len(b.selectedChapters), "chapter", "chapters")),
-		},
-	)
+			field("Format", style.Fg(color.Purple)(viper.GetString(key.FormatsUse))),
+			field("Destination", c.destination),
+		)
+
+		if viper.GetBool(key.TUIConfirmCountPages) {
+			var pages string
+			switch {
+			case c.countFailed:
+				pages = fmt.Sprintf("%d+ %s", c.pages, style.Faint(i18n.T("(failed to count)")))
+			case c.counting():
+				pages = fmt.Sprintf("%d+ %s", c.pages, style.Faint(i18n.T("(counting %d/%d)", c.counted, len(c.chapters))))
+			default:
+				pages = strconv.Itoa(c.pages)
+			}
+
+			lines = append(lines, field("Pages", pages))
+		}
+
+		if viper.GetBool(key.DownloaderEstimateSize) {
+			size := "~" + humanize.Bytes(c.size)
+			switch {
+			case c.countFailed:
+				size = fmt.Sprintf("%s+ %s", size, style.Faint(i18n.T("(failed to estimate)")))
+			case c.counting():
+				size = fmt.Sprintf("%s+ %s", size, style.Faint(i18n.T("(estimating %d/%d)", c.counted, len(c.chapters))))
+			case c.estimateFailed:
+				size = fmt.Sprintf("%s+ %s", size, style.Faint(i18n.T("(some sizes are unknown)")))
+			}
+
+			lines = append(lines, field("Size", size))
+		}
+
+		if c.downloaded > 0 {
+			action := "%s will be skipped"
+			if viper.GetBool(key.DownloaderRedownloadExisting) {
+				action = "%s will be redownloaded"
+			}
+
+			lines = append(lines, field("Downloaded", style.Fg(color.Yellow)(i18n.T(action, i18n.N(c.downloaded, "chapter", "chapters")))))
+		}
+
+		// do not let collisions push the help out of the screen
+		const maxCollisions = 3
+		filenames := lo.Keys(c.collisions)
+		sort.Slice(filenames, func(i, j int) bool {
+			return util.NaturalLess(filenames[i], filenames[j])
+		})
+		for i, filename := range filenames {
+			if i == maxCollisions {
+				lines = append(lines, field("", style.Faint(i18n.T("and %d more", len(filenames)-maxCollisions))))
+				break
+			}
+
+			lines = append(lines, field("Collision", style.Fg(color.Red)(i18n.T("%s would be saved as %s", i18n.N(c.collisions[filename], "chapter", "chapters"), filename))))
+		}
+	}
+
+	return b.renderLines(true, lines)
 }
 
 func (b *statefulBubble) downloadingChapterMetainfo() string {
-	metainfo := strings.Builder{}
+	format := style.Fg(color.Purple)(viper.GetString(key.FormatsUse))
 
 	// Even though when this function is called chapter isn't supposed to be nil,
 	// it can be one for a brief moment.
 	// I assume that it's because View() is called before Update()
 	if b.currentDownloadingChapter != nil {
-		metainfo.WriteString("From ")
-		metainfo.WriteString(style.Fg(color.Orange)(b.currentDownloadingChapter.Source().Name()))
-		metainfo.WriteString(" as ")
+		return i18n.T("From %s as %s", style.Fg(color.Orange)(b.currentDownloadingChapter.Source().Name()), format)
 	}
 
-	metainfo.WriteString(style.Fg(color.Purple)(viper.GetString(key.FormatsUse)))
-	return metainfo.String()
+	return format
 }
 
 func (b *statefulBubble) viewRead() string {
@@ -140,9 +251,9 @@ func (b *statefulBubble) viewRead() string {
 	return b.renderLines(
 		true,
 		[]string{
-			style.Title("Reading"),
+			style.Title(i18n.T("Reading")),
 			"",
-			style.Truncate(b.width)(fmt.Sprintf(icon.Get(icon.Progress)+" Downloading %s", style.Fg(color.Purple)(chapterName))),
+			style.Truncate(b.width)(icon.Get(icon.Progress) + " " + i18n.T("Downloading %s", style.Fg(color.Purple)(chapterName))),
 			"",
 			style.Truncate(b.width)(b.spinnerC.View() + b.progressStatus),
 			"",
@@ -162,11 +273,11 @@ func (b *statefulBubble) viewDownload() string {
 	return b.renderLines(
 		true,
 		[]string{
-			style.Title("Downloading"),
+			style.Title(i18n.T("Downloading")),
 			"",
-			style.Truncate(b.width)(fmt.Sprintf(icon.Get(icon.Progress)+" Downloading %s", style.Fg(color.Purple)(chapterName))),
+			style.Truncate(b.width)(icon.Get(icon.Progress) + " " + i18n.T("Downloading %s", style.Fg(color.Purple)(chapterName))),
 			"",
-			b.progressC.View(),
+			b.progressView(),
 			"",
 			style.Truncate(b.width)(b.spinnerC.View() + b.progressStatus),
 			"",
@@ -182,16 +293,13 @@ func (b *statefulBubble) viewDownloadDone() string {
 	var msg string
 
 	{
-		temp := strings.Split(util.Quantify(succeded, "chapter", "chapters"), " ")
+		temp := strings.Split(i18n.N(succeded, "chapter", "chapters"), " ")
 		temp[0] = style.Fg(color.Green)(temp[0])
-		s := strings.Join(temp, " ") + " downloaded"
-		f := fmt.Sprintf("%s failed", style.Fg(color.Red)(strconv.Itoa(failed)))
-
-		msg = fmt.Sprintf("%s, %s", s, f)
+		msg = i18n.T("%s downloaded, %s failed", strings.Join(temp, " "), style.Fg(color.Red)(strconv.Itoa(failed)))
 	}
 
 	lines := []string{
-		style.Title("Finish"),
+		style.Title(i18n.T("Finish")),
 		"",
 		msg,
 	}
@@ -200,7 +308,7 @@ func (b *statefulBubble) viewDownloadDone() string {
 		path, err := b.selectedManga.Path(false)
 		if err == nil {
 			lines = append(lines, "")
-			lines = append(lines, fmt.Sprintf("Downloaded to %s", style.Faint(path)))
+			lines = append(lines, i18n.T("Downloaded to %s", style.Faint(path)))
 		}
 	}
 
@@ -211,16 +319,16 @@ func (b *statefulBubble) viewDownloadDone() string {
 }
 
 func (b *statefulBubble) viewError() string {
-	errorMsg := wrap.String(style.New().Italic(true).Foreground(color.Red).Render(b.lastError.Error()), b.width)
+	errorMsg := style.New().Italic(true).Foreground(color.Red).Render(util.Wrap(b.lastError.Error(), b.width))
 	return b.renderLines(
 		true,
 		append([]string{
-			style.ErrorTitle("Error"),
+			style.ErrorTitle(i18n.T("Error")),
 			"",
-			icon.Get(icon.Fail) + " Uggh, something went wrong. Maybe try again?",
+			icon.Get(icon.Fail) + " " + i18n.T("Uggh, something went wrong. Maybe try again?"),
 			"",
 		},
-			strings.Split(wrap.String(style.Italic(b.errorPlot), b.width)+"\n\n"+errorMsg, "\n")...,
+			strings.Split(style.Italic(util.Wrap(b.errorPlot, b.width))+"\n\n"+errorMsg, "\n")...,
 		),
 	)
 }
@@ -251,5 +359,5 @@ func randomPlot() string {
 		"I used to download stuff without any errors, then I took an arrow to the knee. By arrow I mean this:",
 	}
 
-	return plots[rand.Intn(len(plots))]
+	return i18n.T(plots[rand.Intn(len(plots))])
 }
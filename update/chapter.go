@@ -4,8 +4,10 @@ import (
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/util"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 type downloadedChapter struct {
@@ -36,5 +38,9 @@ func getChapters(manga string) ([]*downloadedChapter, error) {
 		return nil
 	})
 
+	sort.Slice(chapters, func(i, j int) bool {
+		return util.NaturalLess(chapters[i].path, chapters[j].path)
+	})
+
 	return chapters, err
 }
@@ -0,0 +1,116 @@
+package update
+
+import (
+	"github.com/metafates/mangal/constant"
+	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/source"
+	"github.com/metafates/mangal/util"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// matches chapter file names produced by the default name template, e.g. "[0001] Chapter 1"
+	indexedChapterRegex = regexp.MustCompile(`^\[(\d+)]\s*(.*)$`)
+	chapterNumberRegex  = regexp.MustCompile(`\d+`)
+)
+
+// ChapterFromFilename guesses index and name of the chapter from its file name
+func ChapterFromFilename(filename string) (index int, name string) {
+	name = strings.TrimSpace(util.FileStem(filepath.Base(filename)))
+
+	if groups := indexedChapterRegex.FindStringSubmatch(name); groups != nil {
+		i, _ := source.ParseIndex(groups[1])
+		if groups[2] != "" {
+			name = groups[2]
+		}
+
+		return i, name
+	}
+
+	// volume number usually goes before the chapter one, so take the last
+	if numbers := chapterNumberRegex.FindAllString(name, -1); len(numbers) > 0 {
+		i, _ := source.ParseIndex(numbers[len(numbers)-1])
+		return i, name
+	}
+
+	return 0, name
+}
+
+// Embed injects ComicInfo.xml into the cbz chapters of the downloaded manga that don't have one,
+// e.g. the ones downloaded before metadata support existed.
+// Metadata is fetched from Anilist by the given manga name,
+// chapter names and indexes are guessed from the file names.
+// Chapters that already have ComicInfo.xml are left untouched, use Metadata to refresh them.
+func Embed(mangaPath, name string) (*Report, error) {
+	report := &Report{Name: name}
+
+	chapters, err := getChapters(mangaPath)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	manga := &source.Manga{
+		Name: name,
+	}
+
+	var (
+		missing = make([]*source.Chapter, 0)
+		paths   = make(map[*source.Chapter]string)
+	)
+	for _, chapter := range chapters {
+		if chapter.format != constant.FormatCBZ {
+			continue
+		}
+
+		if _, err := getComicInfoXML(chapter.path); err == nil {
+			continue
+		}
+
+		index, chapterName := ChapterFromFilename(chapter.path)
+		chap := &source.Chapter{
+			Name:  chapterName,
+			Manga: manga,
+			Index: index,
+		}
+
+		missing = append(missing, chap)
+		paths[chap] = chapter.path
+	}
+
+	if len(missing) == 0 {
+		return report, nil
+	}
+
+	log.Infof("finding %s on anilist", name)
+	err = manga.PopulateMetadata(func(string) {})
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	manga.Chapters = missing
+
+	log.Infof("embedding ComicInfo.xml into %d chapters", len(missing))
+	for _, chapter := range missing {
+		path := paths[chapter]
+
+		err = ReadPages(chapter, path)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		err = rewrite(mangaPath, chapter, path)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+
+		report.Chapters = append(report.Chapters, chapter.Name)
+	}
+
+	return report, nil
+}
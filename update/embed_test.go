@@ -0,0 +1,44 @@
+package update
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestChapterFromFilename(t *testing.T) {
+	Convey("Given a chapter file name produced by the default template", t, func() {
+		index, name := ChapterFromFilename("/downloads/Manga/[0012] Chapter 12.cbz")
+
+		Convey("Then index and name should be extracted", func() {
+			So(index, ShouldEqual, 12)
+			So(name, ShouldEqual, "Chapter 12")
+		})
+	})
+
+	Convey("Given a chapter file name with a number", t, func() {
+		index, name := ChapterFromFilename("Vol.1 Ch.7.cbz")
+
+		Convey("Then the last number should be used as the index", func() {
+			So(index, ShouldEqual, 7)
+			So(name, ShouldEqual, "Vol.1 Ch.7")
+		})
+	})
+
+	Convey("Given a chapter file name with an index beyond 16 bits", t, func() {
+		index, name := ChapterFromFilename("[70000] Chapter 70000.cbz")
+
+		Convey("Then the index should not overflow", func() {
+			So(index, ShouldEqual, 70_000)
+			So(name, ShouldEqual, "Chapter 70000")
+		})
+	})
+
+	Convey("Given a chapter file name without numbers", t, func() {
+		index, name := ChapterFromFilename("Oneshot.cbz")
+
+		Convey("Then index should be zero", func() {
+			So(index, ShouldEqual, 0)
+			So(name, ShouldEqual, "Oneshot")
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package update
+
+import (
+	"encoding/xml"
+	"github.com/metafates/mangal/source"
+	"reflect"
+	"strings"
+)
+
+// Report describes what was changed by the metadata update
+type Report struct {
+	// Name of the manga
+	Name string
+	// SeriesFields are the json names of the series.json fields that were changed
+	SeriesFields []string
+	// Chapters which ComicInfo.xml was rewritten
+	Chapters []string
+	// Cover is true if the cover was downloaded again
+	Cover bool
+}
+
+// Changed reports whether anything except the cover was changed
+func (r *Report) Changed() bool {
+	return len(r.SeriesFields) > 0 || len(r.Chapters) > 0
+}
+
+// diffFields returns json names of the struct fields that differ
+func diffFields[T any](old, new T) (fields []string) {
+	oldValue, newValue := reflect.ValueOf(old), reflect.ValueOf(new)
+
+	for i := 0; i < oldValue.NumField(); i++ {
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+
+		name := strings.Split(oldValue.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = oldValue.Type().Field(i).Name
+		}
+
+		fields = append(fields, name)
+	}
+
+	return
+}
+
+// comicInfoChanged compares ComicInfo.xml contents ignoring the notes
+func comicInfoChanged(old, new *source.ComicInfo) bool {
+	if old == nil {
+		return true
+	}
+
+	o, n := *old, *new
+	o.Notes, n.Notes = "", ""
+
+	oldXML, err := xml.Marshal(o)
+	if err != nil {
+		return true
+	}
+
+	newXML, err := xml.Marshal(n)
+	if err != nil {
+		return true
+	}
+
+	return string(oldXML) != string(newXML)
+}
@@ -0,0 +1,61 @@
+package update
+
+import (
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestDiffFields(t *testing.T) {
+	Convey("Given two series.json metadata", t, func() {
+		old := source.SeriesJSON{}.Metadata
+		old.Name = "Chainsaw Man"
+		old.Year = 2018
+
+		Convey("When they are the same", func() {
+			Convey("Then no fields should be reported", func() {
+				So(diffFields(old, old), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When some fields are changed", func() {
+			new := old
+			new.Year = 2019
+			new.Status = "Ended"
+
+			Convey("Then their json names should be reported", func() {
+				So(diffFields(old, new), ShouldResemble, []string{"status", "year"})
+			})
+		})
+	})
+}
+
+func TestComicInfoChanged(t *testing.T) {
+	Convey("Given a ComicInfo", t, func() {
+		old := &source.ComicInfo{Title: "Chapter 1", Notes: "old note"}
+
+		Convey("When only notes differ", func() {
+			new := *old
+			new.Notes = "new note"
+
+			Convey("Then it should not be considered changed", func() {
+				So(comicInfoChanged(old, &new), ShouldBeFalse)
+			})
+		})
+
+		Convey("When title differs", func() {
+			new := *old
+			new.Title = "Chapter 2"
+
+			Convey("Then it should be considered changed", func() {
+				So(comicInfoChanged(old, &new), ShouldBeTrue)
+			})
+		})
+
+		Convey("When there was no ComicInfo before", func() {
+			Convey("Then it should be considered changed", func() {
+				So(comicInfoChanged(nil, old), ShouldBeTrue)
+			})
+		})
+	})
+}
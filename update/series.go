@@ -30,3 +30,13 @@ func getSeriesJSON(manga string) (*source.SeriesJSON, error) {
 	err = json.Unmarshal(contents, &seriesJSON)
 	return &seriesJSON, err
 }
+
+// GetStatus returns the publication status of the manga from its series.json, e.g. "Ended"
+func GetStatus(manga string) (string, error) {
+	seriesJSON, err := getSeriesJSON(manga)
+	if err != nil {
+		return "", err
+	}
+
+	return seriesJSON.Metadata.Status, nil
+}
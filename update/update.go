@@ -7,22 +7,27 @@ import (
 	"github.com/metafates/mangal/converter/cbz"
 	"github.com/metafates/mangal/filesystem"
 	"github.com/metafates/mangal/log"
+	"github.com/metafates/mangal/manifest"
 	"github.com/metafates/mangal/source"
 	"github.com/metafates/mangal/util"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
-func Metadata(mangaPath string) error {
+// Metadata refetches metadata of the downloaded manga from Anilist
+// and regenerates series.json and ComicInfo.xml of the cbz chapters in place.
+func Metadata(mangaPath string) (*Report, error) {
 	log.Infof("extracting series name from %s", mangaPath)
 	name, err := GetName(mangaPath)
 	if err != nil {
 		log.Error(err)
-		return err
+		return nil, err
 	}
 
 	log.Infof("extracted name: %s", name)
+	report := &Report{Name: name}
 	log.Infof("finding %s on anilist", name)
 	manga := &source.Manga{
 		Name: name,
@@ -32,17 +37,18 @@ func Metadata(mangaPath string) error {
 	err = manga.PopulateMetadata(func(string) {})
 	if err != nil {
 		log.Error()
-		return err
+		return nil, err
 	}
 
 	chapters, err := getChapters(mangaPath)
 	if err != nil {
 		log.Error(err)
-		return err
+		return nil, err
 	}
 
 	manga.Chapters = make([]*source.Chapter, 0)
 	chaptersPaths := make(map[*source.Chapter]string)
+	oldComicInfos := make(map[*source.Chapter]*source.ComicInfo)
 	for _, chapter := range chapters {
 		// since we are trying to update ComicInfo.xml here, we do not care about any other formats other than FormatCBZ
 		if chapter.format != constant.FormatCBZ {
@@ -60,10 +66,11 @@ func Metadata(mangaPath string) error {
 			Name:  comicInfo.Title,
 			Manga: manga,
 			URL:   comicInfo.Web,
-			Index: uint16(comicInfo.Number),
+			Index: comicInfo.Number,
 		}
 		manga.Chapters = append(manga.Chapters, chap)
 		chaptersPaths[chap] = chapter.path
+		oldComicInfos[chap] = comicInfo
 	}
 
 	// okay, we're ready to regenerate series.json and ComicInfo.xml now
@@ -71,7 +78,13 @@ func Metadata(mangaPath string) error {
 	buf, err := json.Marshal(seriesJSON)
 	if err != nil {
 		log.Error(err)
-		return err
+		return nil, err
+	}
+
+	if old, err := getSeriesJSON(mangaPath); err == nil {
+		report.SeriesFields = diffFields(old.Metadata, seriesJSON.Metadata)
+	} else {
+		report.SeriesFields = diffFields(source.SeriesJSON{}.Metadata, seriesJSON.Metadata)
 	}
 
 	// update series.json
@@ -79,7 +92,7 @@ func Metadata(mangaPath string) error {
 	err = filesystem.Api().WriteFile(filepath.Join(mangaPath, "series.json"), buf, os.ModePerm)
 	if err != nil {
 		log.Error(err)
-		return err
+		return nil, err
 	}
 
 	log.Info("downloading new cover")
@@ -98,80 +111,120 @@ func Metadata(mangaPath string) error {
 	err = manga.DownloadCover(true, mangaPath, func(string) {})
 	if err != nil {
 		log.Error(err)
+	} else {
+		report.Cover = true
 	}
 
 	log.Infof("updating ComicInfo.xml for %d chapters", len(manga.Chapters))
 	for _, chapter := range manga.Chapters {
 		path := chaptersPaths[chapter]
-		file, err := filesystem.Api().Open(path)
+
+		// we can not let some pages be gone
+		// so if we can't read any - chapter should be left as is
+		err = ReadPages(chapter, path)
 		if err != nil {
 			log.Error(err)
 			continue
 		}
 
-		stat, err := file.Stat()
-		if err != nil {
-			_ = file.Close()
+		if !comicInfoChanged(oldComicInfos[chapter], chapter.ComicInfo()) {
+			log.Debugf("ComicInfo.xml of %s is up to date", path)
 			continue
 		}
 
-		// go to memmap fs to unzip
-		filesystem.SetMemMapFs()
-		err = util.Unzip(file, stat.Size(), chapter.Name)
-		if err != nil {
-			log.Error(err)
-			_ = file.Close()
-			continue
-		}
+		report.Chapters = append(report.Chapters, chapter.Name)
 
-		// add pages before converting back to cbz
-		files, err := filesystem.Api().ReadDir(chapter.Name)
+		err = rewrite(mangaPath, chapter, path)
 		if err != nil {
 			log.Error(err)
-			_ = file.Close()
-			continue
+			return nil, err
 		}
+	}
 
-		for _, file := range files {
-			// skip ComicInfo.xml
-			if strings.HasSuffix(file.Name(), ".xml") {
-				continue
-			}
+	return report, nil
+}
 
-			image, err := filesystem.Api().ReadFile(filepath.Join(chapter.Name, file.Name()))
-			// we can not let some pages be gone
-			// so if we can't open any - whole process should stop
-			if err != nil {
-				log.Error(err)
-				return err
-			}
+// ReadPages reads pages of the cbz archive at the given path into the chapter.
+// ComicInfo.xml is skipped.
+func ReadPages(chapter *source.Chapter, path string) error {
+	file, err := filesystem.Api().Open(path)
+	if err != nil {
+		return err
+	}
 
-			chapter.Pages = append(chapter.Pages, &source.Page{
-				Chapter:   chapter,
-				Size:      uint64(file.Size()),
-				Index:     uint16(len(chapter.Pages)),
-				Extension: filepath.Ext(file.Name()),
-				Contents:  bytes.NewBuffer(image),
-			})
-		}
+	defer file.Close()
 
-		_ = file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
 
-		filesystem.SetOsFs()
+	// go to memmap fs to unzip
+	filesystem.SetMemMapFs()
+	defer filesystem.SetOsFs()
 
-		log.Debugf("removing old %s", path)
-		err = filesystem.Api().Remove(path)
-		if err != nil {
-			log.Error(err)
+	err = util.Unzip(file, stat.Size(), chapter.Name)
+	if err != nil {
+		return err
+	}
+
+	// add pages before converting back to cbz
+	files, err := filesystem.Api().ReadDir(chapter.Name)
+	if err != nil {
+		return err
+	}
+
+	// pages of the other tools may be unpadded, e.g. 1.jpg, 2.jpg ... 10.jpg
+	sort.Slice(files, func(i, j int) bool {
+		return util.NaturalLess(files[i].Name(), files[j].Name())
+	})
+
+	for _, file := range files {
+		// skip ComicInfo.xml
+		if strings.HasSuffix(file.Name(), ".xml") {
 			continue
 		}
 
-		log.Debugf("saving to %s", path)
-		err = cbz.SaveTo(chapter, path)
+		image, err := filesystem.Api().ReadFile(filepath.Join(chapter.Name, file.Name()))
 		if err != nil {
-			log.Error(err)
 			return err
 		}
+
+		chapter.Pages = append(chapter.Pages, &source.Page{
+			Chapter:   chapter,
+			Size:      uint64(file.Size()),
+			Index:     len(chapter.Pages),
+			Extension: filepath.Ext(file.Name()),
+			Contents:  bytes.NewBuffer(image),
+		})
+	}
+
+	return nil
+}
+
+// rewrite replaces the cbz archive at the given path with the chapter
+// and refreshes its checksum in the manga manifest
+func rewrite(mangaPath string, chapter *source.Chapter, path string) error {
+	// the archive is saved next to the old one and then moved over it,
+	// so that the old archive is left as is if the saving fails
+	partial := path + ".part"
+	log.Debugf("saving to %s", partial)
+	err := cbz.SaveTo(chapter, partial)
+	if err != nil {
+		_ = filesystem.Api().Remove(partial)
+		return err
+	}
+
+	log.Debugf("replacing %s", path)
+	err = filesystem.Api().Rename(partial, path)
+	if err != nil {
+		_ = filesystem.Api().Remove(partial)
+		return err
+	}
+
+	err = manifest.Refresh(mangaPath, path)
+	if err != nil {
+		log.Warn(err)
 	}
 
 	return nil
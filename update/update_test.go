@@ -0,0 +1,58 @@
+package update
+
+import (
+	"bytes"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/source"
+	. "github.com/smartystreets/goconvey/convey"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	Convey("Given a downloaded cbz archive", t, func() {
+		filesystem.SetMemMapFs()
+		defer filesystem.SetOsFs()
+
+		mangaPath := filepath.Join("downloads", "Manga")
+		path := filepath.Join(mangaPath, "Chapter 1.cbz")
+		So(filesystem.Api().MkdirAll(mangaPath, 0755), ShouldBeNil)
+		So(filesystem.Api().WriteFile(path, []byte("old"), 0644), ShouldBeNil)
+
+		Convey("When the chapter can not be saved", func() {
+			chapter := &source.Chapter{Name: "Chapter 1", Text: "text only", Manga: &source.Manga{Name: "Manga"}}
+			err := rewrite(mangaPath, chapter, path)
+
+			Convey("Then the old archive should be left as is", func() {
+				So(err, ShouldNotBeNil)
+				contents, err := filesystem.Api().ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(contents), ShouldEqual, "old")
+
+				exists, _ := filesystem.Api().Exists(path + ".part")
+				So(exists, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the chapter is saved", func() {
+			chapter := &source.Chapter{Name: "Chapter 1", Manga: &source.Manga{Name: "Manga"}}
+			chapter.Pages = []*source.Page{{
+				Index:     1,
+				Extension: ".jpg",
+				Chapter:   chapter,
+				Contents:  bytes.NewBufferString("image"),
+			}}
+			err := rewrite(mangaPath, chapter, path)
+
+			Convey("Then the archive should be replaced", func() {
+				So(err, ShouldBeNil)
+				contents, err := filesystem.Api().ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(contents), ShouldNotEqual, "old")
+
+				exists, _ := filesystem.Api().Exists(path + ".part")
+				So(exists, ShouldBeFalse)
+			})
+		})
+	})
+}
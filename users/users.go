@@ -0,0 +1,310 @@
+// Package users manages the accounts of the server,
+// so that several people can share one mangal instance.
+package users
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/history"
+	"github.com/metafates/mangal/pbkdf2"
+	"github.com/metafates/mangal/where"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	hashScheme     = "pbkdf2-sha256"
+	hashIterations = 100_000
+	hashSize       = 32
+	saltSize       = 16
+)
+
+var (
+	ErrExists             = errors.New("user already exists")
+	ErrNotFound           = errors.New("user not found")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidName        = errors.New("username may only contain letters, digits, dots, dashes and underscores")
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// mutex guards the users file
+var mutex sync.Mutex
+
+var (
+	// verified are the passwords checked by Authenticate, by the hashes they match,
+	// so that every request of the server doesn't derive the key again.
+	// Passwords are kept as their HMACs with the random key of the process
+	verified      = make(map[string][]byte)
+	verifiedMutex sync.Mutex
+	verifiedKey   = make([]byte, hashSize)
+)
+
+func init() {
+	_, _ = rand.Read(verifiedKey)
+}
+
+// passwordMAC returns the HMAC of the password to compare with the verified ones
+func passwordMAC(password string) []byte {
+	mac := hmac.New(sha256.New, verifiedKey)
+	_, _ = mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+// User is an account of the server
+type User struct {
+	// Name is used to log in
+	Name string `json:"name"`
+	// Hash of the password
+	Hash string `json:"hash"`
+	// Created is the time the user was added
+	Created time.Time `json:"created"`
+}
+
+// History returns the reading progress of the user
+func (u *User) History() *history.Store {
+	return history.NewStore(filepath.Join(where.User(u.Name), "history.json"))
+}
+
+func load() (map[string]*User, error) {
+	users := make(map[string]*User)
+
+	contents, err := filesystem.Api().ReadFile(where.Users())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return users, nil
+		}
+
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, &users)
+	return users, err
+}
+
+func save(users map[string]*User) error {
+	contents, err := json.MarshalIndent(users, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// hashes should not be readable by others
+	return filesystem.Api().WriteFile(where.Users(), contents, 0600)
+}
+
+// List returns all the users sorted by name
+func List() ([]*User, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	users, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*User, 0, len(users))
+	for _, user := range users {
+		list = append(list, user)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// Get returns the user with the given name
+func Get(name string) (*User, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	users, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	user, ok := users[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return user, nil
+}
+
+// Add creates a new user with the given password
+func Add(name, password string) error {
+	if !validName.MatchString(name) || strings.Trim(name, ".") == "" {
+		return ErrInvalidName
+	}
+
+	if password == "" {
+		return fmt.Errorf("password must not be empty")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	users, err := load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := users[name]; ok {
+		return ErrExists
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	users[name] = &User{
+		Name:    name,
+		Hash:    hash,
+		Created: time.Now(),
+	}
+
+	return save(users)
+}
+
+// SetPassword changes the password of the user
+func SetPassword(name, password string) error {
+	if password == "" {
+		return fmt.Errorf("password must not be empty")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	users, err := load()
+	if err != nil {
+		return err
+	}
+
+	user, ok := users[name]
+	if !ok {
+		return ErrNotFound
+	}
+
+	user.Hash, err = hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return save(users)
+}
+
+// Remove deletes the user. Progress and personal library are kept
+func Remove(name string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	users, err := load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := users[name]; !ok {
+		return ErrNotFound
+	}
+
+	delete(users, name)
+	return save(users)
+}
+
+// Authenticate returns the user if the password matches
+func Authenticate(name, password string) (*User, error) {
+	user, err := Get(name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if user == nil {
+		// take the same time as for existing users, so they can't be guessed
+		_, _ = hashPassword(password)
+		return nil, ErrInvalidCredentials
+	}
+
+	mac := passwordMAC(password)
+
+	verifiedMutex.Lock()
+	known, ok := verified[user.Hash]
+	verifiedMutex.Unlock()
+
+	// the hash changes with the password, so the old one is not accepted anymore
+	if ok && hmac.Equal(known, mac) {
+		return user, nil
+	}
+
+	ok, err = checkPassword(user.Hash, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	verifiedMutex.Lock()
+	verified[user.Hash] = mac
+	verifiedMutex.Unlock()
+
+	return user, nil
+}
+
+// hashPassword returns the hash of the password with a random salt
+// in the form of scheme$iterations$salt$key
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	derived := pbkdf2.Key([]byte(password), salt, hashIterations, hashSize, sha256.New)
+
+	return strings.Join([]string{
+		hashScheme,
+		strconv.Itoa(hashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(derived),
+	}, "$"), nil
+}
+
+// checkPassword reports whether the password matches the hash
+func checkPassword(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false, fmt.Errorf("unsupported password hash")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash: %w", err)
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, fmt.Errorf("invalid password hash: %w", err)
+	}
+
+	derived := pbkdf2.Key([]byte(password), salt, iterations, len(expected), sha256.New)
+	return hmac.Equal(derived, expected), nil
+}
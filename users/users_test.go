@@ -0,0 +1,101 @@
+package users
+
+import (
+	"github.com/metafates/mangal/config"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/samber/lo"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func init() {
+	filesystem.SetMemMapFs()
+	lo.Must0(config.Setup())
+}
+
+func TestUsers(t *testing.T) {
+	Convey("Given a user", t, func() {
+		So(Add("alice", "secret"), ShouldBeNil)
+
+		Reset(func() {
+			_ = Remove("alice")
+		})
+
+		Convey("Then adding it again should fail", func() {
+			So(Add("alice", "other"), ShouldEqual, ErrExists)
+		})
+
+		Convey("Then the password should not be stored as is", func() {
+			user, err := Get("alice")
+			So(err, ShouldBeNil)
+			So(user.Hash, ShouldStartWith, hashScheme+"$")
+			So(user.Hash, ShouldNotContainSubstring, "secret")
+		})
+
+		Convey("When authenticating with the right password", func() {
+			user, err := Authenticate("alice", "secret")
+
+			Convey("Then the user should be returned", func() {
+				So(err, ShouldBeNil)
+				So(user.Name, ShouldEqual, "alice")
+			})
+		})
+
+		Convey("When authenticating with a wrong password or name", func() {
+			_, wrongPassword := Authenticate("alice", "guess")
+			_, wrongName := Authenticate("bob", "secret")
+
+			Convey("Then both should fail the same way", func() {
+				So(wrongPassword, ShouldEqual, ErrInvalidCredentials)
+				So(wrongName, ShouldEqual, ErrInvalidCredentials)
+			})
+		})
+
+		Convey("When the password was verified before", func() {
+			_, err := Authenticate("alice", "secret")
+			So(err, ShouldBeNil)
+
+			Convey("Then the next requests should not derive the key again", func() {
+				start := time.Now()
+				for i := 0; i < 10; i++ {
+					_, err = Authenticate("alice", "secret")
+					So(err, ShouldBeNil)
+				}
+
+				_, err = Authenticate("alice", "guess")
+				So(err, ShouldEqual, ErrInvalidCredentials)
+				So(time.Since(start), ShouldBeLessThan, 10*hashDuration())
+			})
+		})
+
+		Convey("When the password is changed", func() {
+			_, err := Authenticate("alice", "secret")
+			So(err, ShouldBeNil)
+			So(SetPassword("alice", "new"), ShouldBeNil)
+
+			Convey("Then only the new password should work", func() {
+				_, err = Authenticate("alice", "secret")
+				So(err, ShouldEqual, ErrInvalidCredentials)
+
+				_, err = Authenticate("alice", "new")
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given invalid names", t, func() {
+		Convey("Then users should not be added", func() {
+			for _, name := range []string{"", "..", "a/b", "a b"} {
+				So(Add(name, "secret"), ShouldEqual, ErrInvalidName)
+			}
+		})
+	})
+}
+
+// hashDuration returns how long the key of one password takes to derive
+func hashDuration() time.Duration {
+	start := time.Now()
+	_, _ = hashPassword("password")
+	return time.Since(start)
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NaturalCompare compares the strings in the natural order, the numbers inside them are compared by their values,
+// e.g. "Ch 2" comes before "Ch 10". Letters are compared case-insensitively.
+// It returns -1 if a comes first, 1 if b comes first, and 0 if they are equal
+func NaturalCompare(a, b string) int {
+	x, y := a, b
+
+	for x != "" && y != "" {
+		if isASCIIDigit(x[0]) && isASCIIDigit(y[0]) {
+			var numberX, numberY string
+			numberX, x = leadingDigits(x)
+			numberY, y = leadingDigits(y)
+
+			// leading zeros do not change the value
+			numberX, numberY = strings.TrimLeft(numberX, "0"), strings.TrimLeft(numberY, "0")
+			if len(numberX) != len(numberY) {
+				return compare(len(numberX), len(numberY))
+			}
+
+			if c := strings.Compare(numberX, numberY); c != 0 {
+				return c
+			}
+
+			continue
+		}
+
+		runeX, sizeX := utf8.DecodeRuneInString(x)
+		runeY, sizeY := utf8.DecodeRuneInString(y)
+		if lowerX, lowerY := unicode.ToLower(runeX), unicode.ToLower(runeY); lowerX != lowerY {
+			return compare(lowerX, lowerY)
+		}
+
+		x, y = x[sizeX:], y[sizeY:]
+	}
+
+	if len(x) != len(y) {
+		return compare(len(x), len(y))
+	}
+
+	// the strings that differ only by the case or the zeros still have an order
+	return strings.Compare(a, b)
+}
+
+// NaturalLess reports whether a comes before b in the natural order, see NaturalCompare
+func NaturalLess(a, b string) bool {
+	return NaturalCompare(a, b) < 0
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// leadingDigits splits the string into the digits it starts with and the rest
+func leadingDigits(s string) (digits, rest string) {
+	i := 0
+	for i < len(s) && isASCIIDigit(s[i]) {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
+
+func compare[T int | rune](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"sort"
+	"testing"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	Convey("Given unpadded chapter names", t, func() {
+		names := []string{"Ch 10", "ch 2", "Ch 1", "Ch 2.5", "Ch 002", "Extra"}
+
+		Convey("When they are sorted in the natural order", func() {
+			sort.Slice(names, func(i, j int) bool {
+				return NaturalLess(names[i], names[j])
+			})
+
+			Convey("Then the numbers should be ordered by their values", func() {
+				So(names, ShouldResemble, []string{"Ch 1", "Ch 002", "ch 2", "Ch 2.5", "Ch 10", "Extra"})
+			})
+		})
+	})
+
+	Convey("Given page files", t, func() {
+		files := []string{"10.jpg", "9.jpg", "1.jpg", "100.jpg"}
+
+		Convey("When they are sorted in the natural order", func() {
+			sort.Slice(files, func(i, j int) bool {
+				return NaturalLess(files[i], files[j])
+			})
+
+			Convey("Then they should be ordered as pages", func() {
+				So(files, ShouldResemble, []string{"1.jpg", "9.jpg", "10.jpg", "100.jpg"})
+			})
+		})
+	})
+
+	Convey("Given equal strings", t, func() {
+		Convey("Then they should be equal", func() {
+			So(NaturalCompare("Ch 1", "Ch 1"), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given a prefix of a string", t, func() {
+		Convey("Then the prefix should come first", func() {
+			So(NaturalCompare("Ch", "Ch 1"), ShouldEqual, -1)
+		})
+	})
+}
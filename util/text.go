@@ -0,0 +1,99 @@
+package util
+
+import (
+	"github.com/mattn/go-runewidth"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ellipsis ends the truncated strings, see Truncate
+const ellipsis = "…"
+
+// Width returns the number of the terminal columns the string takes.
+// Wide characters, such as the CJK ones, take two columns
+func Width(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// Wrap breaks the string into lines at most width columns wide.
+// Lines are broken at the spaces, the words wider than the line are broken between their characters,
+// so the titles without spaces, such as the Japanese ones, are wrapped too.
+// Non-positive width leaves the string as is
+func Wrap(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+
+	var (
+		lines     []string
+		line      strings.Builder
+		lineWidth int
+	)
+
+	flush := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		lineWidth = 0
+	}
+
+	for _, paragraph := range strings.Split(s, "\n") {
+		for _, word := range strings.Fields(paragraph) {
+			wordWidth := Width(word)
+
+			if lineWidth > 0 && lineWidth+1+wordWidth > width {
+				flush()
+			}
+
+			if lineWidth > 0 {
+				line.WriteByte(' ')
+				lineWidth++
+			}
+
+			if lineWidth+wordWidth <= width {
+				line.WriteString(word)
+				lineWidth += wordWidth
+				continue
+			}
+
+			for _, r := range word {
+				runeWidth := runewidth.RuneWidth(r)
+				if lineWidth > 0 && lineWidth+runeWidth > width {
+					flush()
+				}
+
+				line.WriteRune(r)
+				lineWidth += runeWidth
+			}
+		}
+
+		flush()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Truncate shortens the string to at most width columns, ending it with an ellipsis if it is cut.
+// The string must be plain text, without the escape sequences of the styles
+func Truncate(s string, width int) string {
+	if width <= 0 || Width(s) <= width {
+		return s
+	}
+
+	return runewidth.Truncate(s, width, ellipsis)
+}
+
+// PadRight pads the string with spaces to take at least width columns
+func PadRight(s string, width int) string {
+	return runewidth.FillRight(s, width)
+}
+
+// Capitalize returns a string with the first letter capitalized.
+func Capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+
+	return string(unicode.ToTitle(r)) + s[size:]
+}
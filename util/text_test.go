@@ -0,0 +1,102 @@
+package util
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	Convey("Given a title with spaces", t, func() {
+		title := "The Golden Age Arc"
+
+		Convey("When it is wrapped", func() {
+			result := Wrap(title, 10)
+
+			Convey("Then it should be broken at the spaces", func() {
+				So(result, ShouldEqual, "The Golden\nAge Arc")
+			})
+		})
+	})
+
+	Convey("Given a Japanese title without spaces", t, func() {
+		title := "進撃の巨人進撃の巨人"
+
+		Convey("When it is wrapped", func() {
+			result := Wrap(title, 9)
+
+			Convey("Then every line should fit the width", func() {
+				for _, line := range strings.Split(result, "\n") {
+					So(Width(line), ShouldBeLessThanOrEqualTo, 9)
+				}
+
+				So(strings.ReplaceAll(result, "\n", ""), ShouldEqual, title)
+			})
+		})
+	})
+
+	Convey("Given a text with a blank line", t, func() {
+		text := "first\n\nsecond"
+
+		Convey("When it is wrapped", func() {
+			result := Wrap(text, 20)
+
+			Convey("Then the blank line should be kept", func() {
+				So(result, ShouldEqual, text)
+			})
+		})
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	Convey("Given a title of wide characters", t, func() {
+		title := "鋼の錬金術師"
+
+		Convey("When it is truncated", func() {
+			result := Truncate(title, 7)
+
+			Convey("Then it should fit the width with the ellipsis", func() {
+				So(result, ShouldEqual, "鋼の錬…")
+				So(Width(result), ShouldBeLessThanOrEqualTo, 7)
+			})
+		})
+
+		Convey("When it fits", func() {
+			result := Truncate(title, 12)
+
+			Convey("Then it should stay the same", func() {
+				So(result, ShouldEqual, title)
+			})
+		})
+	})
+}
+
+func TestPadRight(t *testing.T) {
+	Convey("Given a name of wide characters", t, func() {
+		Convey("When it is padded", func() {
+			result := PadRight("漫画", 6)
+
+			Convey("Then it should take the width", func() {
+				So(result, ShouldEqual, "漫画  ")
+			})
+		})
+	})
+}
+
+func TestCapitalize(t *testing.T) {
+	Convey("Given a word starting with a multi-byte letter", t, func() {
+		Convey("When it is capitalized", func() {
+			result := Capitalize("échec")
+
+			Convey("Then the whole letter should be capitalized", func() {
+				So(result, ShouldEqual, "Échec")
+			})
+		})
+	})
+
+	Convey("Given an empty string", t, func() {
+		Convey("Then it should stay empty", func() {
+			So(Capitalize(""), ShouldEqual, "")
+		})
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/metafates/mangal/constant"
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
 	"github.com/samber/lo"
+	"github.com/spf13/viper"
 	"golang.org/x/exp/constraints"
 	"golang.org/x/term"
 	"os"
@@ -13,6 +15,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"unicode"
 )
 
 // PadZero pads a number with leading zeros.
@@ -20,6 +23,18 @@ func PadZero(s string, l int) string {
 	return strings.Repeat("0", Max(l-len(s), 0)) + s
 }
 
+// Render replaces the {variable} placeholders of the template with their values.
+// It is done in one pass, so the placeholders inside the values, e.g. in the chapter names, are kept as they are.
+// Unknown placeholders are left in the template
+func Render(template string, variables map[string]string) string {
+	pairs := make([]string, 0, len(variables)*2)
+	for variable, value := range variables {
+		pairs = append(pairs, "{"+variable+"}", value)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
 // replacers is a list of regexp.Regexp pairs that will be used to sanitize filenames.
 var replacers = []lo.Tuple2[*regexp.Regexp, string]{
 	{regexp.MustCompile(`[\\/<>:;"'|?!*{}#%&^+,~\s]`), "_"},
@@ -36,6 +51,30 @@ func SanitizeFilename(filename string) string {
 	return filename
 }
 
+// TruncateWords shortens the string to at most the given number of characters,
+// cutting it at the last word boundary if there is one.
+// Non-positive lengths leave the string as is.
+func TruncateWords(s string, length int) string {
+	runes := []rune(s)
+	if length <= 0 || len(runes) <= length {
+		return s
+	}
+
+	truncated := runes[:length]
+
+	// the word is cut in the middle, drop what is left of it
+	if !unicode.IsSpace(runes[length]) {
+		for i := len(truncated) - 1; i > 0; i-- {
+			if unicode.IsSpace(truncated[i]) {
+				truncated = truncated[:i]
+				break
+			}
+		}
+	}
+
+	return strings.TrimRightFunc(string(truncated), unicode.IsSpace)
+}
+
 // Quantify returns a string with the given number and unit.
 func Quantify(count int, singular, plural string) string {
 	if count == 1 {
@@ -56,7 +95,12 @@ func FileStem(path string) string {
 }
 
 // ClearScreen clears the terminal screen.
+// Does nothing in headless mode.
 func ClearScreen() {
+	if viper.GetBool(key.CliHeadless) {
+		return
+	}
+
 	run := func(name string, args ...string) error {
 		command := exec.Command(name, args...)
 		command.Stdout = os.Stdout
@@ -124,19 +168,10 @@ func PrintErasable(msg string) (eraser func()) {
 	_, _ = fmt.Fprintf(os.Stdout, "\r%s", msg)
 
 	return func() {
-		_, _ = fmt.Fprintf(os.Stdout, "\r%s\r", strings.Repeat(" ", len(msg)))
+		_, _ = fmt.Fprintf(os.Stdout, "\r%s\r", strings.Repeat(" ", Width(msg)))
 	}
 }
 
-// Capitalize returns a string with the first letter capitalized.
-func Capitalize(s string) string {
-	if len(s) == 0 {
-		return s
-	}
-
-	return strings.ToUpper(s[:1]) + s[1:]
-}
-
 // Delete removes the given path from the filesystem.
 // It can handle both files and directories (recursively).
 func Delete(path string) error {
@@ -2,6 +2,7 @@ package util
 
 import (
 	. "github.com/smartystreets/goconvey/convey"
+	"strings"
 	"testing"
 )
 
@@ -108,6 +109,75 @@ func TestSanitizeFilename(t *testing.T) {
 	})
 }
 
+func TestTruncateWords(t *testing.T) {
+	Convey("Given a long chapter title", t, func() {
+		title := "The Golden Age Arc: Beginning"
+
+		Convey("When it is truncated in the middle of a word", func() {
+			result := TruncateWords(title, 17)
+			Convey("Then the word should be dropped", func() {
+				So(result, ShouldEqual, "The Golden Age")
+			})
+		})
+
+		Convey("When it is truncated at the word boundary", func() {
+			result := TruncateWords(title, 14)
+			Convey("Then the words should be kept", func() {
+				So(result, ShouldEqual, "The Golden Age")
+			})
+		})
+
+		Convey("When it is truncated inside the first word", func() {
+			result := TruncateWords(title, 2)
+			Convey("Then the word should be cut", func() {
+				So(result, ShouldEqual, "Th")
+			})
+		})
+
+		Convey("When the length is not limited", func() {
+			result := TruncateWords(title, 0)
+			Convey("Then it should stay the same", func() {
+				So(result, ShouldEqual, title)
+			})
+		})
+	})
+}
+
+func TestRender(t *testing.T) {
+	Convey("Given a template with the variables", t, func() {
+		template := "[{index}] {chapter} {unknown}"
+
+		Convey("When the value contains the placeholder of another variable", func() {
+			result := Render(template, map[string]string{
+				"index":   "1",
+				"chapter": "The {index} Chapter",
+			})
+
+			Convey("Then it should be kept as it is", func() {
+				So(result, ShouldEqual, "[1] The {index} Chapter {unknown}")
+			})
+		})
+	})
+}
+
+func FuzzRender(f *testing.F) {
+	f.Add("[{padded-index}] {chapter}", "{padded-index}")
+	f.Add("{", "}")
+	f.Add("{{chapter}}", "{chapter}")
+
+	f.Fuzz(func(t *testing.T, template, value string) {
+		if result := Render("{chapter}", map[string]string{"chapter": value, "index": template}); result != value {
+			t.Fatalf("{chapter} with %q is rendered as %q", value, result)
+		}
+
+		if !strings.Contains(template, "{") {
+			if result := Render(template, map[string]string{"chapter": value}); result != template {
+				t.Fatalf("%q without placeholders is rendered as %q", template, result)
+			}
+		}
+	})
+}
+
 func TestTerminalSize(t *testing.T) {
 	t.Skipf("Cannot test terminal size")
 }
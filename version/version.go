@@ -5,9 +5,9 @@ import (
 	"errors"
 	"github.com/metafates/gache"
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/network"
 	"github.com/metafates/mangal/util"
 	"github.com/metafates/mangal/where"
-	"net/http"
 	"path/filepath"
 	"time"
 )
@@ -30,7 +30,7 @@ func Latest() (version string, err error) {
 		return ver, nil
 	}
 
-	resp, err := http.Get("https://api.github.com/repos/metafates/mangal/releases/latest")
+	resp, err := network.Client.Get("https://api.github.com/repos/metafates/mangal/releases/latest")
 	if err != nil {
 		return
 	}
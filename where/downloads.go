@@ -0,0 +1,97 @@
+package where
+
+import (
+	"errors"
+	"fmt"
+	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/i18n"
+	"github.com/metafates/mangal/key"
+	"github.com/spf13/viper"
+	"os"
+	"sync/atomic"
+	"syscall"
+)
+
+// downloadsUnavailable is set once the downloads path is found unavailable and the fallback one is used
+var downloadsUnavailable int32
+
+// writable checks the paths in CheckDownloads, replaced in tests
+var writable = Writable
+
+// Writable returns an error if the files can't be created in the directory,
+// e.g. on a read-only mount or without the permissions.
+// The missing directory is not created, since it may be the mount point of the offline drive,
+// and the chapters would be saved to the disk under it
+func Writable(dir string) error {
+	info, err := filesystem.Api().Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	file, err := filesystem.Api().TempFile(dir, ".mangal-write-check-*")
+	if err != nil {
+		return err
+	}
+
+	name := file.Name()
+	_ = file.Close()
+	return filesystem.Api().Remove(name)
+}
+
+// CheckDownloads returns an error if the chapters can't be saved to the downloads path,
+// telling what to do about it. If the fallback path is set, it is used instead of the unavailable downloads path,
+// e.g. when the NAS is offline. Fallback is true then
+func CheckDownloads() (path string, fallback bool, err error) {
+	primary := abs(viper.GetString(key.DownloaderPath))
+	primaryErr := writable(primary)
+	if primaryErr == nil {
+		atomic.StoreInt32(&downloadsUnavailable, 0)
+		return primary, false, nil
+	}
+
+	alternative := viper.GetString(key.DownloaderFallbackPath)
+	if alternative == "" {
+		return "", false, unavailable(primary, primaryErr)
+	}
+
+	// the fallback path is on this machine, so it is created when needed
+	alternative = abs(alternative)
+	if err = filesystem.Api().MkdirAll(alternative, os.ModePerm); err == nil {
+		err = writable(alternative)
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf(i18n.T("%w, and the fallback path is unavailable too: %s"), unavailable(primary, primaryErr), err)
+	}
+
+	atomic.StoreInt32(&downloadsUnavailable, 1)
+	return alternative, true, nil
+}
+
+// unavailable describes why the downloads path can't be used and how to fix it
+func unavailable(path string, err error) error {
+	var hint string
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		hint = i18n.T("it is mounted read-only, remount it read-write")
+	case errors.Is(err, os.ErrNotExist):
+		hint = i18n.T("it does not exist, check that it is mounted or create it")
+	case errors.Is(err, os.ErrPermission):
+		hint = i18n.T("the current user has no permission to write there, change the owner or the permissions of the directory")
+	default:
+		hint = i18n.T("check that it is mounted")
+	}
+
+	return errors.New(i18n.T(
+		"can't save chapters to %s (%s): %s, or set %s to another directory, or %s to use when it is unavailable",
+		path,
+		err,
+		hint,
+		key.DownloaderPath,
+		key.DownloaderFallbackPath,
+	))
+}
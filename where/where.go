@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/viper"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 )
 
 const EnvConfigPath = "MANGAL_CONFIG_PATH"
@@ -39,10 +40,42 @@ func Sources() string {
 	return mkdir(filepath.Join(Config(), "sources"))
 }
 
+// Converters path of the Lua converters
+// Will create the directory if it doesn't exist
+func Converters() string {
+	return mkdir(filepath.Join(Config(), "converters"))
+}
+
 func AnilistBinds() string {
 	return filepath.Join(Config(), "anilist.json")
 }
 
+// Reading path to the read-later queue file
+func Reading() string {
+	return filepath.Join(Config(), "reading.json")
+}
+
+// Bookmarks path to the file with the bookmarked chapters
+func Bookmarks() string {
+	return filepath.Join(Config(), "bookmarks.json")
+}
+
+// Secrets path to the file
+func Secrets() string {
+	return filepath.Join(Config(), "secrets.json")
+}
+
+// Users path to the file with the server accounts
+func Users() string {
+	return filepath.Join(Config(), "users.json")
+}
+
+// User path to the directory with the data of the server account
+// Will create the directory if it doesn't exist
+func User(name string) string {
+	return mkdir(filepath.Join(Config(), "users", name))
+}
+
 // Logs path
 // Will create the directory if it doesn't exist
 func Logs() string {
@@ -55,16 +88,45 @@ func Queries() string {
 	return filepath.Join(Cache(), "queries.json")
 }
 
+// DownloadQueue path to the file with the chapters being downloaded
+func DownloadQueue() string {
+	return filepath.Join(Cache(), "downloads.json")
+}
+
+// Staging path to the directory with the pages of the queued chapters fetched so far
+// Will create the directory if it doesn't exist
+func Staging() string {
+	return mkdir(filepath.Join(Cache(), "staging"))
+}
+
 // History path to the file
 // Will create the directory if it doesn't exist
 func History() string {
 	return filepath.Join(Config(), "history.json")
 }
 
-// Downloads path
-// Will create the directory if it doesn't exist
+// Stats path to the file with the reading statistics by day
+func Stats() string {
+	return filepath.Join(Config(), "stats.json")
+}
+
+// Downloads path.
+// The fallback path is used instead once the downloads path is found unavailable, see CheckDownloads.
+// Only the fallback path is created if it doesn't exist, the downloads path may be the mount point of the offline drive
+// and creating it would make CheckDownloads pick it
 func Downloads() string {
-	path, err := filepath.Abs(viper.GetString(key.DownloaderPath))
+	if atomic.LoadInt32(&downloadsUnavailable) == 1 {
+		if fallback := viper.GetString(key.DownloaderFallbackPath); fallback != "" {
+			return mkdir(abs(fallback))
+		}
+	}
+
+	return abs(viper.GetString(key.DownloaderPath))
+}
+
+// abs returns the absolute path, or the working directory if it can't be resolved
+func abs(path string) string {
+	path, err := filepath.Abs(path)
 
 	if err != nil {
 		path, err = os.Getwd()
@@ -73,7 +135,7 @@ func Downloads() string {
 		}
 	}
 
-	return mkdir(path)
+	return path
 }
 
 // Cache path
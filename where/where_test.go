@@ -2,8 +2,13 @@ package where
 
 import (
 	"github.com/metafates/mangal/filesystem"
+	"github.com/metafates/mangal/key"
 	"github.com/samber/lo"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+	"syscall"
 	"testing"
 )
 
@@ -55,3 +60,92 @@ func TestLogs(t *testing.T) {
 		})
 	})
 }
+
+func TestCheckDownloads(t *testing.T) {
+	Convey("Given the read-only downloads path", t, func() {
+		writable = func(dir string) error {
+			if strings.HasPrefix(dir, "/nas") {
+				return &os.PathError{Op: "open", Path: dir, Err: syscall.EROFS}
+			}
+
+			return Writable(dir)
+		}
+		defer func() { writable = Writable }()
+
+		viper.Set(key.DownloaderPath, "/nas/manga")
+		defer viper.Set(key.DownloaderPath, ".")
+		defer viper.Set(key.DownloaderFallbackPath, "")
+
+		Convey("When there is no fallback path", func() {
+			viper.Set(key.DownloaderFallbackPath, "")
+			_, _, err := CheckDownloads()
+
+			Convey("Then it should fail with the keys to change", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "read-only")
+				So(err.Error(), ShouldContainSubstring, key.DownloaderFallbackPath)
+			})
+		})
+
+		Convey("When the fallback path is set", func() {
+			viper.Set(key.DownloaderFallbackPath, "/local/manga")
+			path, fallback, err := CheckDownloads()
+
+			Convey("Then the downloads should go there", func() {
+				So(err, ShouldBeNil)
+				So(fallback, ShouldBeTrue)
+				So(path, ShouldEqual, "/local/manga")
+				So(Downloads(), ShouldEqual, "/local/manga")
+			})
+
+			Convey("Then the downloads path should be used again once it is available", func() {
+				So(filesystem.Api().MkdirAll("/library", os.ModePerm), ShouldBeNil)
+				viper.Set(key.DownloaderPath, "/library")
+				_, fallback, err := CheckDownloads()
+				So(err, ShouldBeNil)
+				So(fallback, ShouldBeFalse)
+				So(Downloads(), ShouldEqual, "/library")
+			})
+		})
+	})
+}
+
+func TestWritable(t *testing.T) {
+	Convey("Given the downloads path that is not mounted", t, func() {
+		dir := "/mnt/offline/manga"
+
+		Convey("When it is checked", func() {
+			err := Writable(dir)
+
+			Convey("Then it should fail without creating it", func() {
+				So(os.IsNotExist(err), ShouldBeTrue)
+
+				exists := lo.Must(filesystem.Api().Exists(dir))
+				So(exists, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestDownloadsOffline(t *testing.T) {
+	Convey("Given the downloads path that is not mounted and the fallback path", t, func() {
+		viper.Set(key.DownloaderPath, "/nas/offline")
+		viper.Set(key.DownloaderFallbackPath, "/local/fallback")
+		defer viper.Set(key.DownloaderPath, ".")
+		defer viper.Set(key.DownloaderFallbackPath, "")
+
+		Convey("When the downloads path is asked for before it is checked", func() {
+			So(Downloads(), ShouldEqual, "/nas/offline")
+
+			Convey("Then it should not be created and the fallback path should be picked", func() {
+				exists := lo.Must(filesystem.Api().Exists("/nas/offline"))
+				So(exists, ShouldBeFalse)
+
+				path, fallback, err := CheckDownloads()
+				So(err, ShouldBeNil)
+				So(fallback, ShouldBeTrue)
+				So(path, ShouldEqual, "/local/fallback")
+			})
+		})
+	})
+}